@@ -0,0 +1,70 @@
+//go:build !js
+
+package gocube
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// OnBatteryLow sets a callback that fires once when the battery level
+// drops to the WithLowBattery threshold (20% by default) or below. It
+// fires again only after the level has risen above the threshold, e.g.
+// after charging. Levels arrive when the cube reports them, on
+// RequestBattery, and with WithBatteryPolling.
+func (g *GoCube) OnBatteryLow(cb func(level int)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onBatteryLow = cb
+}
+
+// RequestBattery asks the cube for its battery level. The answer updates
+// Battery and fires OnBattery (and OnBatteryLow if it crosses the
+// threshold). Returns ErrNotSupported for cubes that cannot report it.
+func (g *GoCube) RequestBattery() error {
+	if err := g.client.RequestBattery(); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return ErrNotSupported
+		}
+		return err
+	}
+	return nil
+}
+
+// crossedLowBatteryLocked records level against the low battery threshold
+// and returns the OnBatteryLow callback if level has just crossed it. The
+// caller must hold mu.
+func (g *GoCube) crossedLowBatteryLocked(level int) func(int) {
+	threshold := g.config.lowBattery
+	if threshold <= 0 || level < 0 {
+		return nil
+	}
+	if level > threshold {
+		g.batteryLow = false
+		return nil
+	}
+	if g.batteryLow {
+		return nil
+	}
+	g.batteryLow = true
+	return g.onBatteryLow
+}
+
+// pollBattery requests the battery level every interval until Close.
+// Requests made while the link is down fail and are retried next tick.
+func (g *GoCube) pollBattery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-ticker.C:
+			if g.client.IsConnected() {
+				g.client.RequestBattery()
+			}
+		}
+	}
+}
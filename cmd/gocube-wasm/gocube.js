@@ -0,0 +1,69 @@
+// gocube.js - loader and wrapper for gocube.wasm.
+//
+// Requires wasm_exec.js from the Go distribution to be loaded first
+// (browser: a <script> tag; Node: require it before this file).
+//
+//   const gc = await GoCube.load("gocube.wasm");
+//   const result = gc.analyzePlayback(playbackJsonText);
+//   const state = gc.stateAt(playbackJsonText, 42);
+//   const cube = gc.applyMoves("R U R' U'");
+//
+// Each call returns a plain object and throws an Error on failure.
+(function (root) {
+    "use strict";
+
+    function call(fn, args) {
+        const result = JSON.parse(fn.apply(null, args));
+        if (result && result.error) {
+            throw new Error(result.error);
+        }
+        return result;
+    }
+
+    // toText accepts playback data as a JSON string or an already parsed object.
+    function toText(playback) {
+        return typeof playback === "string" ? playback : JSON.stringify(playback);
+    }
+
+    async function instantiate(source, imports) {
+        if (typeof source !== "string") {
+            return WebAssembly.instantiate(source, imports); // bytes
+        }
+        if (typeof fetch === "function" && typeof window !== "undefined") {
+            if (WebAssembly.instantiateStreaming) {
+                return WebAssembly.instantiateStreaming(fetch(source), imports);
+            }
+            const resp = await fetch(source);
+            return WebAssembly.instantiate(await resp.arrayBuffer(), imports);
+        }
+        const fs = require("fs");
+        return WebAssembly.instantiate(fs.readFileSync(source), imports);
+    }
+
+    // load starts gocube.wasm from a URL, file path or bytes and resolves to the API.
+    async function load(source) {
+        const go = new Go();
+        const { instance } = await instantiate(source || "gocube.wasm", go.importObject);
+        go.run(instance);
+
+        const api = root.gocubeWasm;
+        if (!api) {
+            throw new Error("gocube.wasm did not register its API");
+        }
+        return {
+            // analyzePlayback runs the CLI's diagnostics and phase detection
+            // on playback.json data.
+            analyzePlayback: (playback) => call(api.analyzePlayback, [toText(playback)]),
+            // stateAt returns the cube state after the first n moves.
+            stateAt: (playback, n) => call(api.stateAt, [toText(playback), n]),
+            // applyMoves returns the state of a solved cube after a sequence.
+            applyMoves: (notation) => call(api.applyMoves, [notation]),
+        };
+    }
+
+    const GoCube = { load };
+    if (typeof module !== "undefined" && module.exports) {
+        module.exports = GoCube;
+    }
+    root.GoCube = GoCube;
+})(typeof globalThis !== "undefined" ? globalThis : this);
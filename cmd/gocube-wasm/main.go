@@ -0,0 +1,223 @@
+//go:build js && wasm
+
+// Package main builds gocube.wasm, the cube model and solve analysis
+// compiled to WebAssembly so a browser viewer runs the same phase
+// detection and diagnostics as the CLI against an exported playback.json.
+//
+// Build:
+//
+//	GOOS=js GOARCH=wasm go build -o gocube.wasm ./cmd/gocube-wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Load it with gocube.js from this directory:
+//
+//	<script src="wasm_exec.js"></script>
+//	<script src="gocube.js"></script>
+//	<script>
+//	  const gc = await GoCube.load("gocube.wasm");
+//	  const playback = await (await fetch("playback.json")).text();
+//	  const result = gc.analyzePlayback(playback);
+//	  console.log(result.diagnostics.overall.reversal_rate, result.phases);
+//	</script>
+//
+// All functions take and return JSON strings; gocube.js wraps them to take
+// and return objects. Failures are returned as {"error": "..."}.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// playback mirrors the fields of playback.json that analysis needs.
+type playback struct {
+	SolveID string `json:"solve_id"`
+	Phases  []struct {
+		PhaseKey   string  `json:"phase_key"`
+		StartTsMs  int64   `json:"start_ts_ms"`
+		EndTsMs    int64   `json:"end_ts_ms"`
+		DurationMs int64   `json:"duration_ms"`
+		MoveCount  int     `json:"move_count"`
+		TPS        float64 `json:"tps"`
+	} `json:"phases"`
+	Timeline []struct {
+		TsMs      int64  `json:"ts_ms"`
+		TsUs      int64  `json:"ts_us"`
+		Type      string `json:"type"`
+		Face      string `json:"face"`
+		Turn      int    `json:"turn"`
+		Notation  string `json:"notation"`
+		UpFace    string `json:"up_face"`
+		FrontFace string `json:"front_face"`
+	} `json:"timeline"`
+}
+
+// records converts the playback timeline into the storage records the
+// analysis package works on.
+func (p *playback) records() ([]storage.PhaseSegment, []storage.MoveRecord, []storage.OrientationRecord) {
+	segments := make([]storage.PhaseSegment, len(p.Phases))
+	for i, ph := range p.Phases {
+		segments[i] = storage.PhaseSegment{
+			SolveID:    p.SolveID,
+			PhaseKey:   ph.PhaseKey,
+			StartTsMs:  ph.StartTsMs,
+			EndTsMs:    ph.EndTsMs,
+			DurationMs: ph.DurationMs,
+			MoveCount:  ph.MoveCount,
+			TPS:        ph.TPS,
+		}
+	}
+
+	var moves []storage.MoveRecord
+	var orientations []storage.OrientationRecord
+	for _, e := range p.Timeline {
+		tsUs := e.TsUs
+		if tsUs == 0 {
+			tsUs = e.TsMs * 1000 // Exported before microsecond timestamps
+		}
+		switch e.Type {
+		case "move":
+			moves = append(moves, storage.MoveRecord{
+				SolveID:   p.SolveID,
+				MoveIndex: len(moves),
+				TsMs:      e.TsMs,
+				TsUs:      tsUs,
+				Face:      e.Face,
+				Turn:      e.Turn,
+				Notation:  e.Notation,
+			})
+		case "orientation":
+			orientations = append(orientations, storage.OrientationRecord{
+				SolveID:   p.SolveID,
+				TsMs:      e.TsMs,
+				TsUs:      tsUs,
+				UpFace:    e.UpFace,
+				FrontFace: e.FrontFace,
+			})
+		}
+	}
+	return segments, moves, orientations
+}
+
+// cubeState is the JSON form of a cube state.
+type cubeState struct {
+	Facelets [6][9]gocube.Color `json:"facelets"`
+	Phase    string             `json:"phase"`
+	Solved   bool               `json:"solved"`
+}
+
+func newCubeState(c *gocube.Cube) cubeState {
+	return cubeState{Facelets: c.Facelets, Phase: c.Phase().String(), Solved: c.IsSolved()}
+}
+
+// analysisResult is returned by analyzePlayback.
+type analysisResult struct {
+	Diagnostics     *analysis.SolveDiagnostics `json:"diagnostics"`
+	Phases          []string                   `json:"phases"` // Detected cube phase after each move
+	Final           cubeState                  `json:"final"`
+	LongestPauseMs  int64                      `json:"longest_pause_ms"`
+	AvgMoveMs       float64                    `json:"avg_move_duration_ms"`
+	MovementProfile *analysis.MovementProfile  `json:"movement_profile"`
+	Effort          analysis.EffortReport      `json:"effort"`
+}
+
+// analyzePlayback runs diagnostics and phase detection on a playback.json.
+func analyzePlayback(data string) (interface{}, error) {
+	var p playback
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return nil, fmt.Errorf("invalid playback JSON: %w", err)
+	}
+	segments, records, orientations := p.records()
+	moves := storage.ToMoves(records)
+
+	result := analysisResult{
+		Diagnostics:     analysis.AnalyzeDiagnosticsData(p.SolveID, segments, records, orientations),
+		Phases:          make([]string, len(moves)),
+		LongestPauseMs:  analysis.FindLongestPause(moves),
+		AvgMoveMs:       analysis.CalculateAvgMoveDuration(moves),
+		MovementProfile: analysis.AnalyzeMovementProfile(moves),
+		Effort:          analysis.EstimateEffort(records, len(orientations)),
+	}
+
+	cube := gocube.NewCube()
+	for i, m := range moves {
+		cube.Apply(m)
+		result.Phases[i] = cube.Phase().String()
+	}
+	result.Final = newCubeState(cube)
+	return result, nil
+}
+
+// stateAt returns the cube state after the first n moves of a playback.json.
+func stateAt(data string, n int) (interface{}, error) {
+	var p playback
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return nil, fmt.Errorf("invalid playback JSON: %w", err)
+	}
+	_, records, _ := p.records()
+	if n < 0 || n > len(records) {
+		return nil, fmt.Errorf("move %d out of range (0-%d)", n, len(records))
+	}
+
+	cube := gocube.NewCube()
+	cube.Apply(storage.ToMoves(records[:n])...)
+	return newCubeState(cube), nil
+}
+
+// applyMoves returns the state of a solved cube after a move sequence in
+// standard notation (e.g. "R U R' U'").
+func applyMoves(notation string) (interface{}, error) {
+	moves, err := analysis.ParseMovesStrict(notation)
+	if err != nil {
+		return nil, err
+	}
+	cube := gocube.NewCube()
+	cube.Apply(moves...)
+	return newCubeState(cube), nil
+}
+
+// export wraps fn as a JS function returning a JSON string.
+func export(fn func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		v, err := fn(args)
+		if err != nil {
+			v = map[string]string{"error": err.Error()}
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			data, _ = json.Marshal(map[string]string{"error": err.Error()})
+		}
+		return string(data)
+	})
+}
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("analyzePlayback", export(func(args []js.Value) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("analyzePlayback(playbackJSON) takes 1 argument")
+		}
+		return analyzePlayback(args[0].String())
+	}))
+	api.Set("stateAt", export(func(args []js.Value) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("stateAt(playbackJSON, moveIndex) takes 2 arguments")
+		}
+		return stateAt(args[0].String(), args[1].Int())
+	}))
+	api.Set("applyMoves", export(func(args []js.Value) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("applyMoves(notation) takes 1 argument")
+		}
+		return applyMoves(args[0].String())
+	}))
+	js.Global().Set("gocubeWasm", api)
+
+	// Keep the Go runtime alive for callbacks
+	select {}
+}
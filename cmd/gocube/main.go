@@ -2,6 +2,8 @@
 package main
 
 import (
+	_ "time/tzdata" // Config time zones must resolve on systems without zoneinfo, e.g. Windows
+
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/cli"
 )
 
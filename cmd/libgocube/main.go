@@ -0,0 +1,218 @@
+// Package main builds libgocube, a C shared library exposing the GoCube
+// connection layer (scan, connect, move events) to other languages.
+//
+// Build:
+//
+//	go build -buildmode=c-shared -o libgocube.so ./cmd/libgocube
+//
+// This produces libgocube.so (.dylib on macOS, .dll on Windows) and a
+// matching libgocube.h. Cubes are referred to by integer handles. Strings
+// returned by the library must be released with gocube_free_string.
+// Callbacks run on a library-owned thread.
+//
+// Python example (ctypes):
+//
+//	lib = ctypes.CDLL("./libgocube.so")
+//	MOVE_CB = ctypes.CFUNCTYPE(None, ctypes.c_char_p, ctypes.c_longlong, ctypes.c_void_p)
+//	h = lib.gocube_connect_first(10000)
+//	if h < 0:
+//	    raise RuntimeError(lib.gocube_last_error())
+//	cb = MOVE_CB(lambda n, ts, ud: print(n.decode(), ts))
+//	lib.gocube_on_move(h, cb, None)
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// gocube_move_cb receives the move in standard notation (e.g. "R'"), the
+// move time in Unix milliseconds and the user_data passed to gocube_on_move.
+typedef void (*gocube_move_cb)(const char* notation, int64_t time_ms, void* user_data);
+
+static inline void gocube_call_move_cb(gocube_move_cb cb, const char* notation, int64_t time_ms, void* user_data) {
+	cb(notation, time_ms, user_data);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+var (
+	mu         sync.Mutex
+	cubes      = make(map[C.int]*gocube.GoCube)
+	nextHandle C.int = 1
+	lastError  string
+)
+
+// setError records err for gocube_last_error and returns -1.
+func setError(err error) C.int {
+	mu.Lock()
+	defer mu.Unlock()
+	lastError = err.Error()
+	return -1
+}
+
+// register stores a connected cube and returns its handle.
+func register(cube *gocube.GoCube) C.int {
+	mu.Lock()
+	defer mu.Unlock()
+	h := nextHandle
+	nextHandle++
+	cubes[h] = cube
+	return h
+}
+
+func lookup(handle C.int) (*gocube.GoCube, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	cube, ok := cubes[handle]
+	return cube, ok
+}
+
+// gocube_last_error returns the message of the most recent failure, or an
+// empty string. Free the result with gocube_free_string.
+//
+//export gocube_last_error
+func gocube_last_error() *C.char {
+	mu.Lock()
+	defer mu.Unlock()
+	return C.CString(lastError)
+}
+
+// gocube_free_string releases a string returned by the library.
+//
+//export gocube_free_string
+func gocube_free_string(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// gocube_scan scans for timeout_ms and returns a JSON array of devices
+// ([{"name":...,"uuid":...,"rssi":...}]), or NULL on failure.
+// Free the result with gocube_free_string.
+//
+//export gocube_scan
+func gocube_scan(timeoutMs C.int) *C.char {
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	devices, err := gocube.Scan(ctx, timeout)
+	if err != nil {
+		setError(err)
+		return nil
+	}
+
+	type device struct {
+		Name string `json:"name"`
+		UUID string `json:"uuid"`
+		RSSI int16  `json:"rssi"`
+	}
+	out := make([]device, len(devices))
+	for i, d := range devices {
+		out[i] = device{Name: d.Name, UUID: d.UUID, RSSI: d.RSSI}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		setError(err)
+		return nil
+	}
+	return C.CString(string(data))
+}
+
+// gocube_connect connects to the device with the given UUID (from
+// gocube_scan) and returns a handle, or -1 on failure.
+//
+//export gocube_connect
+func gocube_connect(uuid *C.char, timeoutMs C.int) C.int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	cube, err := gocube.Connect(ctx, gocube.Device{UUID: C.GoString(uuid)})
+	if err != nil {
+		return setError(err)
+	}
+	return register(cube)
+}
+
+// gocube_connect_first scans and connects to the first cube found.
+// Returns a handle, or -1 on failure.
+//
+//export gocube_connect_first
+func gocube_connect_first(timeoutMs C.int) C.int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	cube, err := gocube.ConnectFirst(ctx)
+	if err != nil {
+		return setError(err)
+	}
+	return register(cube)
+}
+
+// gocube_on_move sets the move callback for a cube. Pass NULL to clear it.
+// Returns 0, or -1 if the handle is unknown.
+//
+//export gocube_on_move
+func gocube_on_move(handle C.int, cb C.gocube_move_cb, userData unsafe.Pointer) C.int {
+	cube, ok := lookup(handle)
+	if !ok {
+		return setError(fmt.Errorf("unknown handle %d", handle))
+	}
+	if cb == nil {
+		cube.OnMove(nil)
+		return 0
+	}
+
+	cube.OnMove(func(m gocube.Move) {
+		notation := C.CString(m.Notation())
+		defer C.free(unsafe.Pointer(notation))
+		C.gocube_call_move_cb(cb, notation, C.int64_t(m.Time.UnixMilli()), userData)
+	})
+	return 0
+}
+
+// gocube_is_solved returns 1 if the tracked cube state is solved, 0 if not,
+// or -1 if the handle is unknown.
+//
+//export gocube_is_solved
+func gocube_is_solved(handle C.int) C.int {
+	cube, ok := lookup(handle)
+	if !ok {
+		return setError(fmt.Errorf("unknown handle %d", handle))
+	}
+	if cube.IsSolved() {
+		return 1
+	}
+	return 0
+}
+
+// gocube_close disconnects a cube and releases its handle.
+// Returns 0, or -1 on failure.
+//
+//export gocube_close
+func gocube_close(handle C.int) C.int {
+	mu.Lock()
+	cube, ok := cubes[handle]
+	delete(cubes, handle)
+	mu.Unlock()
+
+	if !ok {
+		return setError(fmt.Errorf("unknown handle %d", handle))
+	}
+	if err := cube.Close(); err != nil {
+		return setError(err)
+	}
+	return 0
+}
+
+// main is required by buildmode=c-shared and never runs.
+func main() {}
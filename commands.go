@@ -0,0 +1,79 @@
+package gocube
+
+import (
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// Command is a GoCube command code. The cube receives it framed as
+// [0x2A] [0x01] [command] [checksum] [0x0D] [0x0A].
+//
+// GoCube methods such as FlashBacklight and SyncState cover the commands
+// most programs need; these constants are for SendRawCommand.
+type Command byte
+
+// Documented GoCube commands.
+const (
+	CommandRequestBattery          = Command(protocol.CmdRequestBattery)       // Answered with a battery message
+	CommandRequestState            = Command(protocol.CmdRequestState)         // Answered with the facelet state
+	CommandReboot                  = Command(protocol.CmdReboot)               // Restarts the cube, dropping the connection
+	CommandResetSolved             = Command(protocol.CmdResetSolved)          // Makes the current state the cube's solved state
+	CommandDisableOrientation      = Command(protocol.CmdDisableOrientation)   // Stops orientation messages
+	CommandEnableOrientation       = Command(protocol.CmdEnableOrientation)    // Starts orientation messages
+	CommandRequestOfflineStats     = Command(protocol.CmdRequestOfflineStats)  // Answered with move, time and solve counters
+	CommandFlashBacklight          = Command(protocol.CmdFlashBacklight)       // Flashes the backlight three times
+	CommandToggleAnimatedBacklight = Command(protocol.CmdToggleAnimatedBL)     // Turns the animated backlight on or off
+	CommandSlowFlashBacklight      = Command(protocol.CmdSlowFlashBacklight)   // Slowly flashes the backlight three times
+	CommandToggleBacklight         = Command(protocol.CmdToggleBacklight)      // Turns the backlight on or off
+	CommandRequestCubeType         = Command(protocol.CmdRequestCubeType)      // Answered with the cube model
+	CommandCalibrateOrientation    = Command(protocol.CmdCalibrateOrientation) // Takes the current orientation as home
+)
+
+// The block of codes GoCube commands are drawn from. SendRawCommand
+// refuses anything outside it; the bytes below it are message types the
+// cube sends, not commands it accepts.
+const (
+	minRawCommand Command = 0x30
+	maxRawCommand Command = 0x5F
+)
+
+var commandNames = map[Command]string{
+	CommandRequestBattery:          "request_battery",
+	CommandRequestState:            "request_state",
+	CommandReboot:                  "reboot",
+	CommandResetSolved:             "reset_solved",
+	CommandDisableOrientation:      "disable_orientation",
+	CommandEnableOrientation:       "enable_orientation",
+	CommandRequestOfflineStats:     "request_offline_stats",
+	CommandFlashBacklight:          "flash_backlight",
+	CommandToggleAnimatedBacklight: "toggle_animated_backlight",
+	CommandSlowFlashBacklight:      "slow_flash_backlight",
+	CommandToggleBacklight:         "toggle_backlight",
+	CommandRequestCubeType:         "request_cube_type",
+	CommandCalibrateOrientation:    "calibrate_orientation",
+}
+
+// String returns the command's name, or its hex code if it is undocumented.
+func (c Command) String() string {
+	if name, ok := commandNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02X", byte(c))
+}
+
+// IsDocumented returns true for the commands listed above.
+func (c Command) IsDocumented() bool {
+	_, ok := commandNames[c]
+	return ok
+}
+
+// ValidateRawCommand checks that cmd may be sent with SendRawCommand: it
+// must lie in the 0x30-0x5F block the documented commands come from.
+// Undocumented codes in the block are allowed so they can be probed.
+func ValidateRawCommand(cmd byte) error {
+	if Command(cmd) < minRawCommand || Command(cmd) > maxRawCommand {
+		return fmt.Errorf("%w: 0x%02X is outside 0x%02X-0x%02X", ErrInvalidCommand, cmd, byte(minRawCommand), byte(maxRawCommand))
+	}
+	return nil
+}
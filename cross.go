@@ -0,0 +1,159 @@
+package gocube
+
+import "sync"
+
+// The white cross is solved when the four U edges are home and oriented.
+// Their positions and orientations alone decide it, so a breadth-first
+// table over just those (24^4 entries, 190080 reachable) gives the exact
+// number of turns the cross needs from any state.
+
+// crossEdgeCount is the number of white cross edges: UR, UF, UL and UB.
+const crossEdgeCount = 4
+
+// crossCoords is the size of the cross coordinate: each cross edge has 12
+// positions times 2 orientations.
+const crossCoords = 24 * 24 * 24 * 24
+
+// crossTable holds, for one set of moves, the distance of every cross
+// coordinate from the solved cross and each move's effect on an edge.
+type crossTable struct {
+	moves []int
+	edge  [solverMoves][24]int8 // Edge code (position*2 + orientation) after each move
+	dist  []int8                // -1 where unreachable
+}
+
+var (
+	crossOnce  [2]sync.Once
+	crossTbls  [2]*crossTable
+	crossMoves = [2][]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}, // Outer turns (HTM)
+		{0, 2, 3, 5, 6, 8, 9, 11, 12, 14, 15, 17},                      // Quarter turns (QTM)
+	}
+)
+
+// crossTableFor returns the table for metric, building it on first use.
+// STM uses the outer turn table; a slice turn never shortens the cross
+// enough to matter in practice, so the result is an upper bound there.
+func crossTableFor(metric Metric) *crossTable {
+	i := 0
+	if metric == MetricQTM {
+		i = 1
+	}
+	crossOnce[i].Do(func() { crossTbls[i] = buildCrossTable(crossMoves[i]) })
+	return crossTbls[i]
+}
+
+func buildCrossTable(moves []int) *crossTable {
+	t := &crossTable{moves: moves, dist: make([]int8, crossCoords)}
+
+	// An edge at position q goes to the position i whose new cubie comes
+	// from q, picking up that move's flip at i
+	for m := 0; m < solverMoves; m++ {
+		mc := &moveCubes[m]
+		for i := 0; i < 12; i++ {
+			q := mc.ep[i]
+			for o := int8(0); o < 2; o++ {
+				t.edge[m][int(q)*2+int(o)] = int8(i*2) + (o+mc.eo[i])%2
+			}
+		}
+	}
+
+	for i := range t.dist {
+		t.dist[i] = -1
+	}
+	var solved [crossEdgeCount]int8
+	for e := range solved {
+		solved[e] = int8(e * 2)
+	}
+	start := crossIndex(solved)
+	t.dist[start] = 0
+	frontier := []int{start}
+	for depth := int8(1); len(frontier) > 0; depth++ {
+		var next []int
+		for _, idx := range frontier {
+			for _, m := range moves {
+				n := crossIndex(t.apply(crossCodes(idx), m))
+				if t.dist[n] < 0 {
+					t.dist[n] = depth
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return t
+}
+
+// apply returns the cross edge codes after move m.
+func (t *crossTable) apply(codes [crossEdgeCount]int8, m int) [crossEdgeCount]int8 {
+	for e := range codes {
+		codes[e] = t.edge[m][codes[e]]
+	}
+	return codes
+}
+
+func crossIndex(codes [crossEdgeCount]int8) int {
+	idx := 0
+	for _, c := range codes {
+		idx = idx*24 + int(c)
+	}
+	return idx
+}
+
+func crossCodes(idx int) [crossEdgeCount]int8 {
+	var codes [crossEdgeCount]int8
+	for e := crossEdgeCount - 1; e >= 0; e-- {
+		codes[e] = int8(idx % 24)
+		idx /= 24
+	}
+	return codes
+}
+
+// OptimalCross returns a shortest sequence of outer-face turns that
+// completes the white cross (on U, edges matching their centers) of a
+// solved cube scrambled by moves, with its length counted in metric. In
+// QTM only quarter turns are searched, so the result is optimal there too;
+// in HTM and STM it is optimal over outer turns. Slice and wide moves and
+// rotations in moves are expanded first. The first call for a metric
+// builds a lookup table (well under a second).
+func OptimalCross(moves []Move, metric Metric) ([]Move, int) {
+	c := solvedCubieCube
+	for _, m := range ExpandMoves(moves) {
+		c.multiply(&moveCubes[solverMoveIndex(m)])
+	}
+	var codes [crossEdgeCount]int8
+	for i := 0; i < 12; i++ {
+		if e := c.ep[i]; e < crossEdgeCount {
+			codes[e] = int8(i*2) + c.eo[i]
+		}
+	}
+
+	t := crossTableFor(metric)
+	var line []Move
+	for d := t.dist[crossIndex(codes)]; d > 0; d-- {
+		for _, m := range t.moves {
+			next := t.apply(codes, m)
+			if t.dist[crossIndex(next)] == d-1 {
+				line = append(line, toMove(m))
+				codes = next
+				break
+			}
+		}
+	}
+	line = mergeQuarterTurns(line)
+	return line, CountMoves(line, metric)
+}
+
+// mergeQuarterTurns writes two equal quarter turns of a face in a row as a
+// half turn, as QTM searches produce them.
+func mergeQuarterTurns(moves []Move) []Move {
+	merged := moves[:0:0]
+	for _, m := range moves {
+		if n := len(merged); n > 0 && merged[n-1].Face == m.Face && merged[n-1].Turn == m.Turn && m.Turn != Double {
+			merged[n-1].Turn = Double
+			continue
+		}
+		merged = append(merged, m)
+	}
+	return merged
+}
@@ -54,10 +54,21 @@ const (
 //	3 4 5
 //	6 7 8
 //
-// The center (index 4) defines the face color and never moves.
+// The center (index 4) defines the face color and never moves. Slice moves,
+// wide moves and rotations are applied as the outer-face turns they make
+// relative to the centers (see ExpandMoves), and moves after a rotation
+// turn the face now in the named position.
 type Cube struct {
 	// Facelets[face][position] = color
 	Facelets [6][9]Color
+
+	// Centers[face] is how far the center of each face has turned from
+	// solved, in clockwise quarter turns (0-3). It only shows on picture
+	// and logo cubes; IsSolved and Phase ignore it.
+	Centers [6]int
+
+	frame   moveFrame    // Rotation since Reset from slice and wide moves and x, y, z
+	history *cubeHistory // Nil unless TrackHistory is on
 }
 
 // NewCube creates a solved cube with standard orientation:
@@ -68,7 +79,8 @@ func NewCube() *Cube {
 	return c
 }
 
-// Reset resets the cube to the solved state.
+// Reset resets the cube to the solved state. With history tracking on,
+// the history starts again from there.
 func (c *Cube) Reset() {
 	for face := CubeFace(0); face < 6; face++ {
 		color := faceToSolvedColor(face)
@@ -76,6 +88,11 @@ func (c *Cube) Reset() {
 			c.Facelets[face][i] = color
 		}
 	}
+	c.Centers = [6]int{}
+	c.frame = moveFrame{}
+	if c.history != nil {
+		c.history = &cubeHistory{}
+	}
 }
 
 // faceToSolvedColor returns the color of a face when solved.
@@ -98,7 +115,7 @@ func faceToSolvedColor(f CubeFace) Color {
 	}
 }
 
-// Clone creates a deep copy of the cube.
+// Clone creates a deep copy of the cube, its history included.
 func (c *Cube) Clone() *Cube {
 	clone := &Cube{}
 	for f := 0; f < 6; f++ {
@@ -106,6 +123,13 @@ func (c *Cube) Clone() *Cube {
 			clone.Facelets[f][i] = c.Facelets[f][i]
 		}
 	}
+	clone.Centers = c.Centers
+	clone.frame = c.frame
+	if c.history != nil {
+		h := *c.history
+		h.entries = append([]historyEntry(nil), h.entries...)
+		clone.history = &h
+	}
 	return clone
 }
 
@@ -116,6 +140,9 @@ func (c *Cube) Clone() *Cube {
 //	cube.Apply(gocube.R, gocube.U, gocube.RPrime, gocube.UPrime)
 func (c *Cube) Apply(moves ...Move) {
 	for _, m := range moves {
+		if c.history != nil {
+			c.history.record(m, c.Centers, c.frame)
+		}
 		c.applyMove(m)
 	}
 }
@@ -136,9 +163,26 @@ func (c *Cube) ApplyNotation(notation string) error {
 
 // applyMove applies a single Move to the cube.
 func (c *Cube) applyMove(m Move) {
+	if m.Face.IsOuter() && c.frame == (moveFrame{}) {
+		c.turnFace(m)
+		return
+	}
+	for _, om := range c.frame.expand(m, nil) {
+		c.turnFace(om)
+	}
+}
+
+// turnFace applies an outer-face Move to the cube.
+func (c *Cube) turnFace(m Move) {
 	face := moveFaceToCubeFace(m.Face)
 	turn := int(m.Turn)
 	c.moveFace(face, turn)
+
+	if m.HasCenter {
+		c.Centers[face] = m.Center
+	} else {
+		c.Centers[face] = ((c.Centers[face]+turn)%4 + 4) % 4
+	}
 }
 
 // IsSolved returns true if the cube is in the solved state.
@@ -154,14 +198,21 @@ func (c *Cube) IsSolved() bool {
 	return true
 }
 
+// CentersSolved returns true if every center is in its solved orientation.
+// Together with IsSolved this is the solved check for picture cubes.
+func (c *Cube) CentersSolved() bool {
+	return c.Centers == [6]int{}
+}
+
 // Phase returns the current solving phase.
 func (c *Cube) Phase() Phase {
 	return c.detectPhase()
 }
 
-// GetProgress returns detailed progress through all phases.
+// GetProgress returns detailed progress through all phases, of the
+// layer-by-layer method and of Roux and ZZ.
 func (c *Cube) GetProgress() Progress {
-	return Progress{
+	p := Progress{
 		WhiteCross:     c.isWhiteCrossComplete(),
 		FirstLayer:     c.isTopLayerComplete(),
 		SecondLayer:    c.isMiddleLayerComplete(),
@@ -170,6 +221,9 @@ func (c *Cube) GetProgress() Progress {
 		YellowOriented: c.areBottomCornersOriented(),
 		Solved:         c.IsSolved(),
 	}
+	p.FirstBlock, p.SecondBlock, p.CMLL = c.rouxProgress()
+	p.EOLine, p.ZZF2L = c.zzProgress()
+	return p
 }
 
 // String returns an ASCII visualization of the cube.
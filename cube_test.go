@@ -1,7 +1,15 @@
 package gocube
 
 import (
+	"context"
+	"errors"
+	"math"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
 )
 
 func TestNewCubeIsSolved(t *testing.T) {
@@ -195,6 +203,49 @@ func TestGetProgress(t *testing.T) {
 	}
 }
 
+func TestMethodPhases(t *testing.T) {
+	tests := []struct {
+		moves    string
+		roux, zz Phase
+	}{
+		{"", PhaseSolved, PhaseSolved},
+		{"R", PhaseFirstBlock, PhaseEOLine},                  // Line UF UB, F/B edges oriented
+		{"F", PhaseFirstBlock, PhaseEOLine},                  // Line UL UR, L/R edges oriented
+		{"F R", PhaseScrambled, PhaseScrambled},              // No block, both lines broken
+		{"R D R' D R D2 R'", PhaseSecondBlock, PhaseZZF2L},   // Yellow corners twisted
+		{"B R D R' D' B'", PhaseSecondBlock, PhaseScrambled}, // Yellow edges flipped
+		{"R L' F", PhaseCMLL, PhaseScrambled},                // M slice and the last layer turned
+		{"U", PhaseScrambled, PhaseScrambled},
+	}
+	for _, tt := range tests {
+		c := NewCube()
+		if err := c.ApplyNotation(tt.moves); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := c.PhaseFor(MethodRoux); err != nil || got != tt.roux {
+			t.Errorf("%q: PhaseFor(roux) = %v, %v; want %v", tt.moves, got, err, tt.roux)
+		}
+		if got, err := c.PhaseFor("ZZ"); err != nil || got != tt.zz {
+			t.Errorf("%q: PhaseFor(ZZ) = %v, %v; want %v", tt.moves, got, err, tt.zz)
+		}
+	}
+
+	c := NewCube()
+	c.ApplyNotation("R")
+	if p := c.GetProgress(); !p.FirstBlock || p.SecondBlock || !p.EOLine || p.ZZF2L {
+		t.Errorf("GetProgress after R = %+v, want first block and EOLine only", p)
+	}
+	if _, err := c.PhaseFor("cfop"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("PhaseFor(cfop) error = %v, want ErrUnsupportedMethod", err)
+	}
+	if phases, _ := MethodPhases(MethodZZ); len(phases) != 4 || phases[3] != PhaseSolved {
+		t.Errorf("MethodPhases(zz) = %v", phases)
+	}
+	if !PhaseSolved.After(PhaseCMLL) || PhaseCMLL.After(PhaseSolved) || !PhaseCMLL.After(PhaseFirstBlock) {
+		t.Error("Phase.After does not order Roux phases")
+	}
+}
+
 func TestPhaseProgression(t *testing.T) {
 	c := NewCube()
 
@@ -280,3 +331,942 @@ func TestParseMoves(t *testing.T) {
 		}
 	}
 }
+
+func TestSolverMatchesCubeModel(t *testing.T) {
+	// A solution found on the solver's cubie model must solve the facelet model
+	scramble, _ := ParseMoves("R U2 F' L D B2 R' U F2 D' L2 B U' R2 F")
+	cc := solvedCubieCube
+	c := NewCube()
+	for _, m := range scramble {
+		for i, face := range solverFaces {
+			if face != m.Face {
+				continue
+			}
+			for p, turn := range solverTurns {
+				if turn == m.Turn {
+					cc.multiply(&moveCubes[3*i+p])
+				}
+			}
+		}
+		c.Apply(m)
+	}
+
+	solution, ok := solveCubie(cc, 23)
+	if !ok {
+		t.Fatal("solveCubie found no solution")
+	}
+	for _, m := range solution {
+		c.Apply(toMove(m))
+	}
+	if !c.IsSolved() {
+		t.Errorf("Solution %v does not solve the scramble", solution)
+		t.Log(c.String())
+	}
+}
+
+func TestGenerateScramble(t *testing.T) {
+	scramble := GenerateScramble(WithScrambleSeed(1))
+	if len(scramble) < 2 || len(scramble) > 25 {
+		t.Errorf("Random-state scramble has %d moves", len(scramble))
+	}
+	for i := 1; i < len(scramble); i++ {
+		if scramble[i].Face == scramble[i-1].Face {
+			t.Errorf("Scramble turns %s twice in a row: %s", scramble[i].Face, FormatMoves(scramble))
+		}
+	}
+
+	c := NewCube()
+	c.Apply(scramble...)
+	if c.IsSolved() {
+		t.Error("Cube should be scrambled")
+	}
+
+	again := GenerateScramble(WithScrambleSeed(1))
+	if FormatMoves(again) != FormatMoves(scramble) {
+		t.Errorf("Same seed gave %q and %q", FormatMoves(scramble), FormatMoves(again))
+	}
+}
+
+func TestGenerateScrambleLength(t *testing.T) {
+	scramble := GenerateScramble(WithScrambleLength(8), WithScrambleSeed(1))
+	if len(scramble) != 8 {
+		t.Errorf("Got %d moves, expected 8", len(scramble))
+	}
+}
+
+func TestCenterOrientation(t *testing.T) {
+	c := NewCube()
+	// Turns the U center half way without moving any other piece
+	c.ApplyNotation("U R L U2 R' L' U R L U2 R' L'")
+	if !c.IsSolved() {
+		t.Fatal("Cube should look solved with only a center turned")
+	}
+	if c.Centers[CubeFaceU] != 2 || c.CentersSolved() {
+		t.Errorf("U center = %d, expected 2 and not solved", c.Centers[CubeFaceU])
+	}
+
+	c.ApplyNotation("U U")
+	if !c.CentersSolved() {
+		t.Errorf("Centers should be solved after a full U turn, got %v", c.Centers)
+	}
+
+	// A reported center orientation overrides the tracked one
+	c.Apply(Move{Face: FaceF, Turn: CW, Center: 3, HasCenter: true})
+	if c.Centers[CubeFaceF] != 3 {
+		t.Errorf("F center = %d, expected reported 3", c.Centers[CubeFaceF])
+	}
+	if clone := c.Clone(); clone.Centers != c.Centers {
+		t.Error("Clone should copy center orientation")
+	}
+}
+
+func TestParseSliceWideRotation(t *testing.T) {
+	tests := []struct {
+		notation string
+		face     Face
+		turn     Turn
+		formats  string
+	}{
+		{"M", FaceM, CW, "M"},
+		{"E'", FaceE, CCW, "E'"},
+		{"S2", FaceS, Double, "S2"},
+		{"Rw", FaceRw, CW, "Rw"},
+		{"r'", FaceRw, CCW, "Rw'"},
+		{"Uw2", FaceUw, Double, "Uw2"},
+		{"x", FaceX, CW, "x"},
+		{"Y'", FaceY, CCW, "y'"},
+	}
+
+	for _, tc := range tests {
+		m, err := ParseMove(tc.notation)
+		if err != nil {
+			t.Errorf("ParseMove(%q) failed: %v", tc.notation, err)
+			continue
+		}
+		if m.Face != tc.face || m.Turn != tc.turn || m.Notation() != tc.formats {
+			t.Errorf("ParseMove(%q) = %s, expected %s", tc.notation, m.Notation(), tc.formats)
+		}
+	}
+
+	if _, err := ParseMove("Mw"); err == nil {
+		t.Error("ParseMove(\"Mw\") should fail")
+	}
+}
+
+func TestSliceWideRotationMoves(t *testing.T) {
+	// Moves after a rotation turn the face now in that position
+	for _, tc := range []struct{ with, equals string }{
+		{"x U x'", "F"},
+		{"y R y'", "B"},
+		{"z U z'", "L"},
+		{"r", "R M'"},
+		{"Fw'", "F' S'"},
+		{"Dw2", "D2 E2"},
+	} {
+		a, b := NewCube(), NewCube()
+		a.ApplyNotation(tc.with)
+		b.ApplyNotation(tc.equals)
+		if a.Facelets != b.Facelets {
+			t.Errorf("%q should have the same effect as %q", tc.with, tc.equals)
+		}
+	}
+
+	c := NewCube()
+	c.ApplyNotation("x y z")
+	if !c.IsSolved() {
+		t.Error("Rotations should leave the cube solved")
+	}
+
+	// The checkerboard: every face has its center and corners in its own
+	// color and its edges in the opposite one
+	c = NewCube()
+	c.ApplyNotation("M2 E2 S2")
+	opposite := map[Color]Color{White: Yellow, Yellow: White, Green: Blue, Blue: Green, Red: Orange, Orange: Red}
+	for face := CubeFace(0); face < 6; face++ {
+		own := faceToSolvedColor(face)
+		for i, color := range c.Facelets[face] {
+			want := own
+			if i%2 == 1 {
+				want = opposite[own]
+			}
+			if color != want {
+				t.Errorf("Face %d facelet %d = %s, expected %s", face, i, color, want)
+			}
+		}
+	}
+
+	c = NewCube()
+	moves, _ := ParseMoves("Rw U x' M2 y S' Lw2 z E")
+	c.Apply(moves...)
+	for i := len(moves) - 1; i >= 0; i-- {
+		c.Apply(moves[i].Inverse())
+	}
+	if !c.IsSolved() {
+		t.Error("Moves followed by their inverse should solve the cube")
+	}
+
+	if got := FormatMoves(ExpandMoves(mustParseMoves(t, "M2 U r"))); got != "R2 L2 D L" {
+		t.Errorf("ExpandMoves = %q, expected %q", got, "R2 L2 D L")
+	}
+}
+
+func mustParseMoves(t *testing.T, s string) []Move {
+	t.Helper()
+	moves, err := ParseMoves(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return moves
+}
+
+func TestNormalizeMove(t *testing.T) {
+	// After a rotation the cube reports the turn of the face now in each
+	// position by that face's color; normalizing gives the position back
+	faces := []Face{FaceR, FaceL, FaceU, FaceD, FaceF, FaceB}
+	for _, rotation := range []string{"", "x", "y'", "z2", "x y", "z' x2", "y2 x'"} {
+		reported := func(f Face) Move {
+			return ExpandMoves(mustParseMoves(t, rotation+" "+string(f)))[0]
+		}
+		o := Orientation{UpFace: reported(FaceU).Face, FrontFace: reported(FaceF).Face}
+		for _, f := range faces {
+			m := reported(f)
+			if got := NormalizeMove(m, o); got.Face != f || got.Turn != m.Turn {
+				t.Errorf("After %q: NormalizeMove(%s) = %s, expected %s", rotation, m.Notation(), got.Notation(), f)
+			}
+		}
+	}
+
+	if got := NormalizeMove(R, Orientation{}); got != R {
+		t.Errorf("NormalizeMove with no orientation = %s, expected R", got.Notation())
+	}
+	if got := NormalizeMove(R, Orientation{UpFace: FaceU, FrontFace: FaceD}); got != R {
+		t.Errorf("NormalizeMove with an invalid orientation = %s, expected R", got.Notation())
+	}
+}
+
+func TestSolveSequence(t *testing.T) {
+	scramble := mustParseMoves(t, "R U R' U' F2 D L' B")
+	solution := SolveSequence(scramble)
+	if len(solution) == 0 || len(solution) > len(scramble) {
+		t.Fatalf("Got %d moves, expected 1-%d", len(solution), len(scramble))
+	}
+	c := NewCube()
+	c.Apply(scramble...)
+	c.Apply(solution...)
+	if !c.IsSolved() {
+		t.Errorf("%s does not solve %s", FormatMoves(solution), FormatMoves(scramble))
+	}
+
+	// Moves that cancel out need no solution
+	if solution := SolveSequence(mustParseMoves(t, "R U U' R'")); len(solution) != 0 {
+		t.Errorf("Got %s for a solved cube", FormatMoves(solution))
+	}
+}
+
+func TestValidateRawCommand(t *testing.T) {
+	for _, cmd := range []byte{byte(CommandRequestState), byte(CommandFlashBacklight), byte(CommandCalibrateOrientation), 0x30, 0x5F, 0x50} {
+		if err := ValidateRawCommand(cmd); err != nil {
+			t.Errorf("ValidateRawCommand(0x%02X) = %v, want nil", cmd, err)
+		}
+	}
+	for _, cmd := range []byte{0x00, 0x01, 0x2A, 0x2F, 0x60, 0xFF} {
+		if err := ValidateRawCommand(cmd); !errors.Is(err, ErrInvalidCommand) {
+			t.Errorf("ValidateRawCommand(0x%02X) = %v, want ErrInvalidCommand", cmd, err)
+		}
+	}
+
+	if got := CommandFlashBacklight.String(); got != "flash_backlight" {
+		t.Errorf("CommandFlashBacklight.String() = %q", got)
+	}
+	if got := Command(0x50).String(); got != "0x50" {
+		t.Errorf("Command(0x50).String() = %q, want 0x50", got)
+	}
+	if Command(0x50).IsDocumented() || !CommandRequestCubeType.IsDocumented() {
+		t.Error("IsDocumented should be true only for the listed commands")
+	}
+}
+
+func TestTimer(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer := NewTimer()
+	timer.now = func() time.Time { return clock }
+
+	var events []string
+	timer.OnInspectionStart(func() { events = append(events, "inspection") })
+	timer.OnTimerStart(func() { events = append(events, "start") })
+	var result TimerResult
+	timer.OnTimerStop(func(r TimerResult) { result = r; events = append(events, "stop") })
+
+	// Moves before inspection (scrambling) are ignored
+	timer.HandleMove(R, false)
+	if timer.State() != TimerIdle {
+		t.Fatalf("State = %v before inspection, want idle", timer.State())
+	}
+
+	timer.StartInspection()
+	clock = clock.Add(10 * time.Second)
+	if got := timer.InspectionRemaining(); got != 5*time.Second {
+		t.Errorf("InspectionRemaining = %v, want 5s", got)
+	}
+
+	timer.HandleMove(U, false)
+	if timer.State() != TimerRunning {
+		t.Fatalf("State = %v after first move, want running", timer.State())
+	}
+	clock = clock.Add(12340 * time.Millisecond)
+	timer.HandleMove(UPrime, true)
+
+	if want := []string{"inspection", "start", "stop"}; strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if result.Time != 12340*time.Millisecond || result.Inspection != 10*time.Second || result.Penalty != PenaltyNone {
+		t.Errorf("result = %+v", result)
+	}
+	if result.String() != "12.34" {
+		t.Errorf("result.String() = %q, want 12.34", result.String())
+	}
+	if got, ok := timer.Result(); !ok || got != result {
+		t.Errorf("Result() = %+v, %v", got, ok)
+	}
+
+	// Later moves do not restart a stopped timer
+	timer.HandleMove(R, false)
+	if timer.State() != TimerStopped {
+		t.Errorf("State = %v after a move once stopped, want stopped", timer.State())
+	}
+}
+
+func TestTimerInspectionPenalties(t *testing.T) {
+	tests := []struct {
+		inspection time.Duration
+		want       Penalty
+		str        string
+	}{
+		{15 * time.Second, PenaltyNone, "10.00"},
+		{16 * time.Second, PenaltyPlus2, "12.00+"},
+		{17 * time.Second, PenaltyPlus2, "12.00+"},
+		{17*time.Second + time.Millisecond, PenaltyDNF, "DNF(10.00)"},
+	}
+	for _, tt := range tests {
+		clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		timer := NewTimer()
+		timer.now = func() time.Time { return clock }
+
+		timer.StartInspection()
+		clock = clock.Add(tt.inspection)
+		if got := timer.Penalty(); got != tt.want {
+			t.Errorf("Penalty() during %v inspection = %v, want %v", tt.inspection, got, tt.want)
+		}
+		timer.HandleMove(R, false)
+		clock = clock.Add(10 * time.Second)
+		timer.HandleMove(RPrime, true)
+
+		r, _ := timer.Result()
+		if r.Penalty != tt.want || r.String() != tt.str {
+			t.Errorf("%v inspection: result %v (%v), want %v (%s)", tt.inspection, r, r.Penalty, tt.want, tt.str)
+		}
+	}
+
+	// Without inspection there is no penalty however long it takes
+	timer := NewTimer()
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer.now = func() time.Time { return clock }
+	timer.SetInspection(0)
+	timer.StartInspection()
+	clock = clock.Add(time.Minute)
+	timer.HandleMove(R, true)
+	if r, _ := timer.Result(); r.Penalty != PenaltyNone {
+		t.Errorf("penalty with inspection disabled = %v, want none", r.Penalty)
+	}
+}
+
+func TestTimerPersonalBest(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer := NewTimer()
+	timer.now = func() time.Time { return clock }
+	timer.SetInspection(0)
+
+	var bests []PersonalBest
+	timer.OnPersonalBest(func(pb PersonalBest) { bests = append(bests, pb) })
+	solve := func(seconds int) {
+		timer.StartInspection()
+		timer.HandleMove(R, false)
+		clock = clock.Add(time.Duration(seconds) * time.Second)
+		timer.HandleMove(RPrime, true)
+	}
+
+	for _, s := range []int{30, 32, 28, 31, 29} {
+		solve(s)
+	}
+	// The first single and ao5 set the bests without firing
+	if len(bests) != 1 || bests[0] != (PersonalBest{Kind: PBSingle, Time: 28 * time.Second, Previous: 30 * time.Second}) {
+		t.Fatalf("bests after 5 solves = %+v, want one single of 28s", bests)
+	}
+
+	bests = nil
+	solve(20) // Window 32 28 31 29 20: ao5 29.33s, beating 30s
+	if len(bests) != 2 || bests[0].Kind != PBSingle || bests[1].Kind != PBAo5 {
+		t.Fatalf("bests = %+v, want single and ao5", bests)
+	}
+	if want := (29*time.Second + 333333333); bests[1].Time != want || bests[1].Previous != 30*time.Second {
+		t.Errorf("ao5 best = %+v, want %v from 30s", bests[1], want)
+	}
+
+	// A seeded best is the one to beat
+	timer.SetPersonalBest(PBSingle, 15*time.Second)
+	bests = nil
+	solve(18)
+	if len(bests) != 1 || bests[0].Kind != PBAo5 {
+		t.Errorf("bests = %+v after 18s against a 15s seed, want only ao5", bests)
+	}
+	if got := timer.PersonalBests()[PBSingle]; got != 15*time.Second {
+		t.Errorf("PersonalBests()[PBSingle] = %v, want 15s", got)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var events []ReplayEvent
+	moves, _ := ParseMoves("R U R' U' U R U' R'")
+	for i := range moves {
+		events = append(events, ReplayEvent{Time: start.Add(time.Duration(i) * time.Second), Move: &moves[i]})
+	}
+	o := Orientation{UpFace: FaceF, FrontFace: FaceD}
+	events = append(events, ReplayEvent{Time: start.Add(1500 * time.Millisecond), Orientation: &o})
+
+	r := NewReplayer(events)
+	r.SetSpeed(0)
+	var got, normalized []Move
+	var phases []Phase
+	solved := 0
+	r.OnMove(func(m Move) { got = append(got, m) })
+	r.OnNormalizedMove(func(m Move) { normalized = append(normalized, m) })
+	r.OnPhaseChange(func(p Phase) { phases = append(phases, p) })
+	r.OnSolved(func() { solved++ })
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(got) != len(moves) || r.Position() != r.Len() || r.Len() != 9 {
+		t.Fatalf("played %d moves, position %d of %d", len(got), r.Position(), r.Len())
+	}
+	if !r.Cube().IsSolved() || solved != 1 || phases[len(phases)-1] != PhaseSolved {
+		t.Errorf("solved = %v, OnSolved fired %d times, phases %v", r.Cube().IsSolved(), solved, phases)
+	}
+	if normalized[0] != got[0] || normalized[2] != NormalizeMove(got[2], o) {
+		t.Errorf("normalized moves %v do not follow the orientation change", normalized[:3])
+	}
+	if r.Duration() != 7*time.Second {
+		t.Errorf("Duration = %v, want 7s", r.Duration())
+	}
+
+	// Reset rewinds; Step plays one event at a time
+	r.Reset()
+	if !r.Step() || r.Position() != 1 || r.Cube().IsSolved() {
+		t.Errorf("after Reset and Step: position %d, solved %v", r.Position(), r.Cube().IsSolved())
+	}
+
+	// Run waits between events at the set speed and stops when cancelled
+	r.SetSpeed(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := r.Run(ctx); !errors.Is(err, context.DeadlineExceeded) || r.Position() != 1 {
+		t.Errorf("Run = %v at position %d, want deadline exceeded at 1", err, r.Position())
+	}
+}
+
+func TestHintSolves(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		c := NewCube()
+		c.Apply(GenerateScramble(WithScrambleSeed(seed))...)
+		for steps := 0; !c.IsSolved(); steps++ {
+			if steps > 40 {
+				t.Fatalf("seed %d: not solved after %d hints", seed, steps)
+			}
+			before := c.Phase()
+			h, err := c.Hint(MethodLayerByLayer)
+			if err != nil {
+				t.Fatalf("seed %d: Hint in phase %s: %v", seed, before, err)
+			}
+			if len(h.Moves) == 0 || h.Explanation == "" || h.Phase != before+1 {
+				t.Fatalf("seed %d: hint %+v in phase %s", seed, h, before)
+			}
+			c.Apply(h.Moves...)
+			if c.Phase() < before {
+				t.Fatalf("seed %d: hint %q went back from %s to %s", seed, h.Explanation, before, c.Phase())
+			}
+		}
+	}
+
+	c := NewCube()
+	if h, err := c.Hint("lbl"); err != nil || len(h.Moves) != 0 || h.Phase != PhaseSolved {
+		t.Errorf("solved cube: hint %+v, %v", h, err)
+	}
+	c.ApplyNotation("F")
+	h, _ := c.Hint("")
+	if FormatMoves(h.Moves) != "F'" || !strings.Contains(h.Explanation, "white-green edge") {
+		t.Errorf("hint after F = %q, want F' placing the white-green edge", h.Explanation)
+	}
+	if _, err := c.Hint("roux"); !errors.Is(err, ErrUnsupportedMethod) {
+		t.Errorf("Hint(roux) error = %v, want ErrUnsupportedMethod", err)
+	}
+}
+
+func TestStatsTracker(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tr := newStatsTracker()
+	if s := tr.stats(start); s.Moves != 0 || s.TPS != 0 || s.RollingTPS != 0 {
+		t.Fatalf("empty stats = %+v", s)
+	}
+
+	// 10 moves, 2 per second, then 20 moves, 10 per second
+	at := start
+	for i := 0; i < 30; i++ {
+		m := R
+		if i%3 == 0 {
+			m = U
+		}
+		m.Time = at
+		tr.add(m)
+		if i < 10 {
+			at = at.Add(500 * time.Millisecond)
+		} else {
+			at = at.Add(100 * time.Millisecond)
+		}
+	}
+	last := at.Add(-100 * time.Millisecond)
+
+	s := tr.stats(last)
+	if s.Moves != 30 || s.Duration != last.Sub(start) || s.FaceCounts[FaceU] != 10 || s.FaceCounts[FaceR] != 20 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if want := 30 / last.Sub(start).Seconds(); s.TPS != want {
+		t.Errorf("TPS = %.2f, want %.2f", s.TPS, want)
+	}
+	// The last 5s hold the 20 fast moves and the 6 slow ones before them
+	if s.RollingTPS < 5.19 || s.RollingTPS > 5.21 {
+		t.Errorf("RollingTPS = %.2f, want 5.2", s.RollingTPS)
+	}
+
+	// Idle: the rolling rate decays, the overall rate does not
+	idle := tr.stats(last.Add(RollingTPSWindow + time.Millisecond))
+	if idle.RollingTPS != 0 || idle.TPS != s.TPS {
+		t.Errorf("after idling: RollingTPS %.2f, TPS %.2f", idle.RollingTPS, idle.TPS)
+	}
+
+	// Early in a solve the rolling window is the time since the first move
+	early := newStatsTracker()
+	for i := 0; i < 3; i++ {
+		m := F
+		m.Time = start.Add(time.Duration(i) * 250 * time.Millisecond)
+		early.add(m)
+	}
+	if s := early.stats(start.Add(time.Second)); s.RollingTPS != 3 {
+		t.Errorf("early RollingTPS = %.2f, want 3", s.RollingTPS)
+	}
+}
+
+func TestCountMoves(t *testing.T) {
+	tests := []struct {
+		moves         string
+		htm, qtm, stm int
+	}{
+		{"R U R' U'", 4, 4, 4},
+		{"R2 U2 F", 3, 5, 3},
+		{"R L' U2", 3, 4, 2},    // R L' is M' with a rotation
+		{"R L U", 3, 3, 3},      // Same direction: not a slice
+		{"R2 L2 D U'", 4, 6, 2}, // M2 and E
+		{"M' U M U2 x y'", 6, 7, 4},
+	}
+	for _, tt := range tests {
+		moves, _ := ParseMoves(tt.moves)
+		for metric, want := range map[Metric]int{MetricHTM: tt.htm, MetricQTM: tt.qtm, MetricSTM: tt.stm} {
+			if got := CountMoves(moves, metric); got != want {
+				t.Errorf("CountMoves(%q, %s) = %d, want %d", tt.moves, metric, got, want)
+			}
+		}
+	}
+
+	if m, err := ParseMetric("QTM"); err != nil || m != MetricQTM {
+		t.Errorf("ParseMetric(QTM) = %v, %v", m, err)
+	}
+	if m, err := ParseMetric(""); err != nil || m != MetricHTM {
+		t.Errorf("ParseMetric(\"\") = %v, %v", m, err)
+	}
+	if _, err := ParseMetric("etm"); err == nil {
+		t.Error("ParseMetric(etm) should fail")
+	}
+}
+
+func TestSpreadBatchTimes(t *testing.T) {
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	batch := func() []Move {
+		moves := []Move{R, L.Inverse(), U}
+		for i := range moves {
+			moves[i].Time = at
+			moves[i].BatchIndex, moves[i].BatchSize = i, len(moves)
+		}
+		return moves
+	}
+
+	// Spread over the 30ms since the previous notification
+	moves := batch()
+	SpreadBatchTimes(moves, at.Add(-30*time.Millisecond), time.Second)
+	for i, want := range []time.Duration{-20, -10, 0} {
+		if got := moves[i].Time.Sub(at); got != want*time.Millisecond {
+			t.Errorf("move %d at %v, want %vms", i, got, want)
+		}
+		if !moves[i].Simultaneous() {
+			t.Errorf("move %d not simultaneous", i)
+		}
+	}
+
+	// Capped at maxSpan after a long gap, or without a previous notification
+	for _, prev := range []time.Time{at.Add(-time.Minute), {}} {
+		moves = batch()
+		SpreadBatchTimes(moves, prev, 60*time.Millisecond)
+		if got := moves[0].Time.Sub(at); got != -40*time.Millisecond {
+			t.Errorf("prev %v: first move at %v, want -40ms", prev, got)
+		}
+	}
+
+	// Disabled
+	moves = batch()
+	SpreadBatchTimes(moves, at.Add(-time.Second), 0)
+	if !moves[0].Time.Equal(at) {
+		t.Errorf("spread with zero maxSpan: first move at %v", moves[0].Time.Sub(at))
+	}
+	if R.Simultaneous() {
+		t.Error("single move reported as simultaneous")
+	}
+}
+
+func TestTimeBatch(t *testing.T) {
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// R then U' 40ms later, as a GAN cube reports them, with a time
+	// unknown for the first
+	payload := []byte{0x08, protocol.CenterUnknown, 0x05, protocol.CenterUnknown,
+		protocol.RotationTimesMarker, 2, 0xFF, 0xFF, 0x00, 40}
+	rotations, err := protocol.DecodeRotation(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotations[0].HasElapsed || !rotations[1].HasElapsed || rotations[1].Elapsed != 40*time.Millisecond {
+		t.Fatalf("decoded times %+v", rotations)
+	}
+	batch := func() []Move {
+		moves := []Move{R, U.Inverse()}
+		for i, rot := range rotations {
+			moves[i].Time = at
+			moves[i].BatchIndex, moves[i].BatchSize = i, len(moves)
+			moves[i].Elapsed, moves[i].HasElapsed = rot.Elapsed, rot.HasElapsed
+		}
+		return moves
+	}
+
+	moves := batch()
+	TimeBatch(moves, at.Add(-time.Second), 0)
+	if got := moves[0].Time.Sub(at); got != -40*time.Millisecond || moves[0].TimeSource != TimeDevice {
+		t.Errorf("first move at %v (%v), want -40ms by the device", got, moves[0].TimeSource)
+	}
+	if !moves[1].Time.Equal(at) || moves[1].TimeSource != TimeReceived {
+		t.Errorf("last move at %v (%v), want the notification time", moves[1].Time.Sub(at), moves[1].TimeSource)
+	}
+
+	// Never before the previous notification
+	moves = batch()
+	TimeBatch(moves, at.Add(-10*time.Millisecond), 0)
+	if got := moves[0].Time.Sub(at); got != -10*time.Millisecond {
+		t.Errorf("first move at %v, want -10ms", got)
+	}
+
+	// Untimed turns are interpolated
+	moves = batch()
+	moves[1].HasElapsed = false
+	TimeBatch(moves, at.Add(-30*time.Millisecond), time.Second)
+	if got := moves[0].Time.Sub(at); got != -15*time.Millisecond || moves[0].TimeSource != TimeInterpolated {
+		t.Errorf("first move at %v (%v), want -15ms interpolated", got, moves[0].TimeSource)
+	}
+	if src := ParseTimeSource(TimeInterpolated.String()); src != TimeInterpolated {
+		t.Errorf("ParseTimeSource round trip gave %v", src)
+	}
+}
+
+func TestScrambleTracker(t *testing.T) {
+	scramble, _ := ParseMoves("R U2 F")
+	apply := func(tr *ScrambleTracker, notation string) ScrambleProgress {
+		moves, err := ParseMoves(notation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var p ScrambleProgress
+		for _, m := range moves {
+			p = tr.Apply(m)
+		}
+		return p
+	}
+
+	// U2 made as two quarter turns either way
+	tr := NewScrambleTracker(scramble)
+	if p := apply(tr, "R U'"); !p.OnTrack || p.Done != 1 || p.Next.Notation() != "U'" {
+		t.Errorf("halfway through U2: %s", p)
+	}
+	if p := apply(tr, "U' F"); !p.Complete || p.String() != "scrambled" {
+		t.Errorf("after the scramble: %s", p)
+	}
+	if p := apply(tr, "D"); p.OnTrack || p.Complete || p.String() != "scramble done, you did D (fix: D')" {
+		t.Errorf("after an extra turn: %s", p)
+	}
+
+	// Leaving U2 half done
+	tr = NewScrambleTracker(scramble)
+	p := apply(tr, "R U F")
+	if p.OnTrack || p.String() != "expected U2, you did U (fix: F' U)" {
+		t.Errorf("after R U F: %s", p)
+	}
+	if p := apply(tr, "F' U"); !p.OnTrack || p.Done != 2 {
+		t.Errorf("after the fix: %s", p)
+	}
+
+	// A quarter turn made the wrong way
+	tr = NewScrambleTracker(scramble)
+	if p := apply(tr, "R'"); p.OnTrack || p.String() != "expected R, you did R' (fix: R2)" {
+		t.Errorf("after R': %s", p)
+	}
+	if p := apply(tr, "R2 U2 F"); !p.Complete {
+		t.Errorf("after the fix: %s", p)
+	}
+}
+
+func TestOptimalCross(t *testing.T) {
+	if line, n := OptimalCross(nil, MetricHTM); n != 0 || len(line) != 0 {
+		t.Errorf("solved cube: %d moves (%s)", n, FormatMoves(line))
+	}
+
+	// F R breaks two cross edges; undoing it is optimal
+	scramble, _ := ParseMoves("F R")
+	if _, n := OptimalCross(scramble, MetricHTM); n != 2 {
+		t.Errorf("F R: %d moves, want 2", n)
+	}
+	scramble, _ = ParseMoves("F2")
+	if line, n := OptimalCross(scramble, MetricQTM); n != 2 || FormatMoves(line) != "F2" {
+		t.Errorf("F2 in QTM: %d moves (%s), want 2 (F2)", n, FormatMoves(line))
+	}
+
+	scramble, _ = ParseMoves("R U2 F' L D B2 R' U L2 F D'")
+	line, n := OptimalCross(scramble, MetricHTM)
+	c := NewCube()
+	c.Apply(scramble...)
+	c.Apply(line...)
+	if c.Phase() < PhaseWhiteCross {
+		t.Errorf("line %s does not complete the cross", FormatMoves(line))
+	}
+	if n > 8 || n != len(line) {
+		t.Errorf("cross in %d moves (%s), want at most 8", n, FormatMoves(line))
+	}
+}
+
+func TestQuaternionSmoothing(t *testing.T) {
+	// A quarter turn about y, unnormalized as the cube reports it
+	quarter := Quaternion{Y: 2 * math.Sqrt(0.5), W: 2 * math.Sqrt(0.5)}
+	half := IdentityQuaternion.Slerp(quarter.Normalize(), 0.5)
+	if angle := 2 * math.Acos(half.W) * 180 / math.Pi; math.Abs(angle-45) > 1e-9 {
+		t.Errorf("halfway slerp turns %.3f degrees, want 45", angle)
+	}
+
+	// -q is the same rotation, so slerp must not swing the long way round
+	neg := Quaternion{Y: -math.Sqrt(0.5), W: -math.Sqrt(0.5)}
+	if d := math.Abs(IdentityQuaternion.Slerp(neg, 0.5).Dot(half)); math.Abs(d-1) > 1e-9 {
+		t.Errorf("slerp to -q differs from slerp to q (dot %.6f)", d)
+	}
+
+	f := attitudeFilter{smoothing: 0.5, interval: 100 * time.Millisecond}
+	start := time.Now()
+	if q, due := f.update(IdentityQuaternion, start); !due || q != IdentityQuaternion {
+		t.Errorf("first sample: %v, due %v", q, due)
+	}
+	if _, due := f.update(quarter, start.Add(50*time.Millisecond)); due {
+		t.Error("sample within the interval was passed on")
+	}
+	// Two samples at 0.5 smoothing leave the estimate 3/4 of the way
+	q, due := f.update(quarter, start.Add(100*time.Millisecond))
+	if angle := 2 * math.Acos(q.W) * 180 / math.Pi; !due || math.Abs(angle-67.5) > 1e-9 {
+		t.Errorf("smoothed estimate turns %.3f degrees (due %v), want 67.5", angle, due)
+	}
+}
+
+func TestCubeHistory(t *testing.T) {
+	c := NewCube()
+	if c.Undo() || c.HistoryLen() != 0 {
+		t.Fatal("history recorded with tracking off")
+	}
+	c.TrackHistory(true)
+	if err := c.ApplyNotation("R U M' x R' U'"); err != nil {
+		t.Fatal(err)
+	}
+	want := NewCube()
+	want.ApplyNotation("R U")
+	if err := c.Checkout(2); err != nil {
+		t.Fatal(err)
+	}
+	if c.Facelets != want.Facelets || c.frame != want.frame {
+		t.Errorf("checkout 2:\n%s\nwant\n%s", c, want)
+	}
+
+	if !c.Redo() || !c.Redo() || c.HistoryPos() != 4 {
+		t.Errorf("redo stopped at %d", c.HistoryPos())
+	}
+	for c.Undo() {
+	}
+	if !c.IsSolved() || c.frame != (moveFrame{}) {
+		t.Errorf("undoing everything left\n%s", c)
+	}
+
+	// Applying after going back branches the history there
+	c.Checkout(2)
+	c.ApplyNotation("F")
+	if got := FormatMoves(c.History()); got != "R U F" || c.Redo() {
+		t.Errorf("history after branching = %s", got)
+	}
+
+	// A reported center is absolute, so undo restores it from the history
+	c.Reset()
+	c.Apply(Move{Face: FaceU, Turn: CW, Center: 3, HasCenter: true})
+	c.Undo()
+	if c.Centers != [6]int{} || c.HistoryLen() != 1 {
+		t.Errorf("after undo: centers %v, history %d", c.Centers, c.HistoryLen())
+	}
+}
+
+func TestPatternMatches(t *testing.T) {
+	c := NewCube()
+	for _, s := range []string{"solved", "cross f2l D=Y", "U4=W, ufr", "!UF|DF"} {
+		if !c.Matches(MustParsePattern(s)) {
+			t.Errorf("solved cube does not match %q", s)
+		}
+	}
+
+	// R' D' R takes the front-right slot apart and leaves the cross alone
+	c.ApplyNotation("R' D' R")
+	if !c.Matches(MustParsePattern("cross !slot-FR slot-FL slot-BR")) {
+		t.Errorf("R' D' R does not match an empty FR slot:\n%s", c)
+	}
+	if c.Matches(MustParsePattern("first-layer")) || c.Matches(MustParsePattern("f2l")) {
+		t.Error("R' D' R matches a complete first layer")
+	}
+
+	for _, s := range []string{"", "UD", "U9=W", "U=X", "cross|", "X4=W"} {
+		if _, err := ParsePattern(s); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("ParsePattern(%q) error = %v", s, err)
+		}
+	}
+}
+
+func TestCubeRender(t *testing.T) {
+	c := NewCube()
+	c.ApplyNotation("R U")
+	if got := c.Render(render.Options{}); got != c.String() {
+		t.Errorf("Render with zero options = \n%s\nwant String()\n%s", got, c.String())
+	}
+	colored := c.Render(render.Options{Color: true, Style: render.Blocks})
+	if !strings.Contains(colored, "\x1b[38;5;") || !strings.Contains(colored, "██") {
+		t.Errorf("colored blocks missing escapes or blocks:\n%q", colored)
+	}
+
+	moves, err := ParseMoves("R U R' U'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := c.String()
+	frames := c.Animate(moves)
+	if c.String() != before {
+		t.Error("Animate changed the cube")
+	}
+	if len(frames) != len(moves)+1 {
+		t.Fatalf("Animate returned %d frames, want %d", len(frames), len(moves)+1)
+	}
+	if frames[2].Turning != "R" || frames[2].Caption != "R U [R'] U'  (3/4)" {
+		t.Errorf("frame 2 = %q, %q", frames[2].Turning, frames[2].Caption)
+	}
+	if frames[0].Facelets != c.renderFacelets() {
+		t.Error("first frame is not the starting state")
+	}
+	end := c.Clone()
+	end.Apply(moves...)
+	if last := frames[len(frames)-1]; last.Facelets != end.renderFacelets() || last.Turning != "" {
+		t.Error("last frame is not the result")
+	}
+}
+
+func TestLinkTracker(t *testing.T) {
+	t0 := time.Now()
+	link := newLinkTracker(t0)
+
+	// A steady 50ms orientation stream, then one sample held up 80ms
+	at := t0
+	for i := 0; i < 20; i++ {
+		at = at.Add(50 * time.Millisecond)
+		link.orientation(at)
+	}
+	at = at.Add(130 * time.Millisecond)
+	link.orientation(at)
+	s := link.stats(at)
+	if s.Interval < 50*time.Millisecond || s.Interval > 56*time.Millisecond || s.MaxGap != 130*time.Millisecond {
+		t.Errorf("interval %v, max gap %v; want about 50ms, 130ms", s.Interval, s.MaxGap)
+	}
+	if s.Delay < 60*time.Millisecond || s.Delay > 80*time.Millisecond || s.Jitter == 0 {
+		t.Errorf("delay %v, jitter %v; want about 75ms and some jitter", s.Delay, s.Jitter)
+	}
+
+	// A turn arriving with the held-up sample is smoothed back by the delay
+	got, repeated := link.rotation([]byte{1}, []Move{{Face: FaceR, Turn: CW, Center: 1, HasCenter: true}}, at, true)
+	if repeated || at.Sub(got) != s.Delay {
+		t.Errorf("smoothed time %v before arrival, repeated %v; want %v", at.Sub(got), repeated, s.Delay)
+	}
+	if got, _ := link.rotation([]byte{2}, []Move{{Face: FaceR, Turn: CW, Center: 2, HasCenter: true}}, at, false); !got.Equal(at) {
+		t.Errorf("unsmoothed time moved by %v", at.Sub(got))
+	}
+
+	// The same turn and center again is a repeat; a center two ahead of
+	// the expected one is two missed turns
+	if _, repeated := link.rotation([]byte{2}, []Move{{Face: FaceR, Turn: CW, Center: 2, HasCenter: true}}, at, false); !repeated {
+		t.Error("repeated notification not detected")
+	}
+	link.rotation([]byte{3}, []Move{{Face: FaceR, Turn: CW, Center: 1, HasCenter: true}}, at, false)
+	// Cubes without centers repeat turns legitimately
+	u := []Move{{Face: FaceU, Turn: CW}}
+	link.rotation([]byte{4}, u, at, false)
+	if _, repeated := link.rotation([]byte{4}, u, at, false); repeated {
+		t.Error("repeated turn without a center taken for a repeat")
+	}
+	if s := link.stats(at); s.Duplicates != 1 || s.MissedTurns != 2 {
+		t.Errorf("duplicates %d, missed %d; want 1, 2", s.Duplicates, s.MissedTurns)
+	}
+}
+
+func TestCubeFromState(t *testing.T) {
+	// Faces in protocol color order, each showing its own color, then the
+	// center turns: blue's a quarter turn
+	payload := make([]byte, 60)
+	for face := 0; face < 6; face++ {
+		for i := 0; i < 9; i++ {
+			payload[face*9+i] = byte(face)
+		}
+	}
+	payload[54] = 0x03
+
+	cube, err := CubeFromState(payload)
+	if err != nil {
+		t.Fatalf("CubeFromState: %v", err)
+	}
+	if !cube.IsSolved() {
+		t.Errorf("solved state decoded as\n%s", cube)
+	}
+	if cube.Centers[CubeFaceB] != 1 || cube.Centers[CubeFaceU] != 0 {
+		t.Errorf("centers = %v, want blue (B) turned once", cube.Centers)
+	}
+	if _, err := CubeFromState(payload[:53]); err == nil {
+		t.Error("CubeFromState of a short payload succeeded")
+	}
+}
@@ -1,21 +1,26 @@
+//go:build !js
+
 package gocube
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
 )
 
-// Device represents a discovered GoCube device.
+// Device represents a discovered smart cube.
 // Devices are returned by the Scan function and can be passed to Connect.
 type Device struct {
-	Name    string      // Device name (e.g., "GoCube_XXXX")
-	UUID    string      // Device UUID for connection
-	RSSI    int16       // Signal strength in dBm (higher = stronger, typical range -30 to -90)
-	address interface{} // Internal: platform-specific address
+	Name   string // Device name (e.g., "GoCube_XXXX")
+	Vendor string // Cube vendor: "GoCube", "GAN" or "MoYu"
+	UUID   string // Device UUID for connection
+	RSSI   int16  // Signal strength in dBm (higher = stronger, typical range -30 to -90)
 }
 
 // GoCube represents a connected GoCube smart cube.
@@ -35,32 +40,77 @@ type Device struct {
 //
 // GoCube maintains an internal Cube state that tracks the current cube state.
 // Access it with the Cube() method.
+//
+// Moves are applied on a dedicated goroutine, which also runs the move,
+// phase and solved callbacks. State accessors such as Cube and Moves read
+// the state published after the last applied move without locking, so they
+// never wait on a burst of notifications.
 type GoCube struct {
 	client *ble.Client
-	cube   *Cube
 	device Device
+	config *config
 
-	mu           sync.RWMutex
+	// Tracked state, written only under writeMu (by the ingestion goroutine,
+	// and by Reset and ClearHistory) and published to readers through snap
+	writeMu      sync.Mutex
+	cube         *Cube
 	moveHistory  []Move
 	highestPhase Phase
-	config       *config
+	stats        *statsTracker
+	lastRotation time.Time // When the last rotation notification arrived
+	snap         atomic.Pointer[snapshot]
+
+	ingest    chan ingestItem // Notifications waiting to be applied
+	done      chan struct{}   // Closed by Close to stop the ingestion goroutine
+	closeOnce sync.Once
+
+	mu           sync.RWMutex
+	stateWaiters []chan *protocol.StateEvent // SyncState calls awaiting a STATE message
+	offlineWaits []chan OfflineStats         // SyncOfflineStats calls awaiting offline stats
+	offline      *OfflineStats               // Last reported, nil if none
+	resyncState  bool                        // Adopt the next STATE message after a reconnect
+	cubeType     CubeType
+	orientation  Orientation   // Last reported, for OnNormalizedMove
+	batteryLow   bool          // OnBatteryLow has fired since the level was last above the threshold
+	statsDone    chan struct{} // Closed to stop the OnStats sampler
+	lights       Lights
+	attitude     attitudeFilter   // Smoothing and rate limit for OnOrientationQuaternion
+	link         *linkTracker     // Link quality, for LinkStats
+	scramble     *ScrambleTracker // Set by ExpectScramble until the scramble is applied
 
 	// Callbacks
 	onMove        func(Move)
+	onNormalized  func(Move)
 	onPhaseChange func(Phase)
 	onOrientation func(Orientation)
+	onAttitude    func(Quaternion)
 	onBattery     func(int)
+	onBatteryLow  func(int)
 	onDisconnect  func(error)
+	onReconnect   func()
+	onCubeType    func(CubeType)
 	onSolved      func()
-}
-
-// Orientation represents the cube's physical orientation in space.
-type Orientation struct {
-	UpFace    Face // Which face is pointing up
-	FrontFace Face // Which face is facing the user
-}
+	onSleep       func()
+	onWake        func()
+	onStats       func(Stats)
+	onOffline     func(OfflineStats)
+	onScramble    func(ScrambleProgress)
+}
+
+// CubeType is the cube model a GoCube reports after connecting. Both models
+// are 3x3 cubes with the same layout, so they share the Cube model and phase
+// detection; the type is informational, e.g. for recording which cube a
+// solve was done on.
+type CubeType string
+
+const (
+	CubeTypeUnknown  CubeType = ""         // Not reported (yet), or not a GoCube
+	CubeTypeStandard CubeType = "standard" // GoCube and GoCube X
+	CubeTypeEdge     CubeType = "edge"     // GoCube Edge
+)
 
-// Scan discovers nearby GoCube devices via Bluetooth Low Energy.
+// Scan discovers nearby smart cubes (GoCube, GAN and MoYu) via Bluetooth
+// Low Energy.
 // Returns all devices found within the timeout period.
 //
 // Typical usage:
@@ -90,24 +140,40 @@ func Scan(ctx context.Context, timeout time.Duration) ([]Device, error) {
 	devices := make([]Device, len(results))
 	for i, r := range results {
 		devices[i] = Device{
-			Name:    r.Name,
-			UUID:    r.UUID,
-			RSSI:    r.RSSI,
-			address: r.Address,
+			Name:   r.Name,
+			Vendor: r.Vendor,
+			UUID:   r.UUID,
+			RSSI:   r.RSSI,
 		}
 	}
 
 	return devices, nil
 }
 
-// Connect connects to a specific GoCube device.
+// Connect connects to a specific GoCube device. ctx bounds the search for
+// it (10 seconds if ctx has no deadline) and every stage of connecting;
+// cancelling it abandons the connection and returns ctx.Err().
 func Connect(ctx context.Context, device Device, opts ...Option) (*GoCube, error) {
 	cfg := defaultConfig()
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	method, err := methodKey(cfg.method)
+	if err != nil {
+		return nil, err
+	}
+	cfg.method = method
+
+	var clientOpts []ble.Option
+	if cfg.autoReconnect {
+		clientOpts = append(clientOpts, ble.WithAutoReconnect(ble.ReconnectPolicy{
+			Retries:    cfg.reconnectTries,
+			Backoff:    cfg.reconnectWait,
+			MaxBackoff: 30 * time.Second,
+		}))
+	}
 
-	client, err := ble.NewClient()
+	client, err := ble.NewClient(clientOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +181,12 @@ func Connect(ctx context.Context, device Device, opts ...Option) (*GoCube, error
 	if err := client.Connect(ctx, device.UUID); err != nil {
 		return nil, err
 	}
+	return newGoCube(client, device, cfg), nil
+}
 
+// newGoCube tracks the cube on a connected client: it starts ingestion,
+// takes over the client's callbacks and starts the polls cfg asks for.
+func newGoCube(client *ble.Client, device Device, cfg *config) *GoCube {
 	g := &GoCube{
 		client:       client,
 		cube:         NewCube(),
@@ -123,19 +194,45 @@ func Connect(ctx context.Context, device Device, opts ...Option) (*GoCube, error
 		moveHistory:  make([]Move, 0),
 		highestPhase: PhaseScrambled,
 		config:       cfg,
+		stats:        newStatsTracker(),
+		link:         newLinkTracker(time.Now()),
 	}
+	g.lights.g = g
+	g.attitude = attitudeFilter{smoothing: cfg.attitudeSmoothing, interval: cfg.attitudeInterval}
+	g.startIngest()
 
 	// Set up internal message handling
 	client.SetMessageCallback(g.handleMessage)
+	client.SetSleepCallback(g.handleSleep)
+	client.SetWakeCallback(g.handleWake)
+	client.SetDisconnectCallback(g.handleDisconnect)
+	client.SetReconnectCallback(g.handleReconnect)
+	client.StartKeepAlive(cfg.keepAlive)
+	if cfg.batteryPoll > 0 {
+		go g.pollBattery(cfg.batteryPoll)
+	}
+	if rssi := client.ScanRSSI(); rssi != 0 {
+		g.link.addRSSI(time.Now(), rssi)
+	}
+	if cfg.rssiPoll > 0 {
+		go g.pollRSSI(cfg.rssiPoll)
+	}
 
-	return g, nil
+	// Cubes that cannot report their type stay CubeTypeUnknown
+	client.RequestCubeType()
+	if cfg.offlineStats {
+		client.RequestOfflineStats()
+	}
+	return g
 }
 
 // ConnectFirst scans and connects to the first GoCube found.
 // This is a convenience function for quick prototyping and single-cube setups.
 //
-// It performs a 10-second scan and connects to the first device discovered.
-// For production use with multiple cubes, use Scan and Connect separately.
+// It performs a 10-second scan (see WithScanTimeout) and connects to the
+// first device discovered, or to the one chosen with WithPreferredDevice
+// if it is in range. For production use with multiple cubes, use Scan and
+// Connect separately.
 //
 // Example:
 //
@@ -145,7 +242,15 @@ func Connect(ctx context.Context, device Device, opts ...Option) (*GoCube, error
 //	}
 //	defer cube.Close()
 func ConnectFirst(ctx context.Context, opts ...Option) (*GoCube, error) {
-	devices, err := Scan(ctx, 10*time.Second)
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if _, err := methodKey(cfg.method); err != nil {
+		return nil, err
+	}
+
+	devices, err := Scan(ctx, cfg.scanTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +259,22 @@ func ConnectFirst(ctx context.Context, opts ...Option) (*GoCube, error) {
 		return nil, ErrDeviceNotFound
 	}
 
-	return Connect(ctx, devices[0], opts...)
+	target := devices[0]
+	for _, d := range devices {
+		if cfg.preferredDevice != "" && d.UUID == cfg.preferredDevice {
+			target = d
+			break
+		}
+	}
+	return Connect(ctx, target, opts...)
 }
 
-// Close disconnects from the cube and cleans up resources.
+// Close disconnects from the cube and cleans up resources. Callbacks may
+// call it: ingestion stops first, so no BLE notification handler is left
+// waiting on the queue while Disconnect waits for the handlers to return.
 func (g *GoCube) Close() error {
+	g.stopStats()
+	g.stopIngest()
 	return g.client.Disconnect()
 }
 
@@ -172,6 +288,12 @@ func (g *GoCube) DeviceName() string {
 	return g.client.DeviceName()
 }
 
+// DeviceUUID returns the connected device's address, which identifies the
+// cube in recorded solves.
+func (g *GoCube) DeviceUUID() string {
+	return g.device.UUID
+}
+
 // Event callbacks
 
 // OnMove sets a callback that fires for each move detected.
@@ -181,6 +303,21 @@ func (g *GoCube) OnMove(cb func(Move)) {
 	g.onMove = cb
 }
 
+// OnNormalizedMove sets a callback that fires for each move detected, with
+// its face remapped from the cube's colors to its position relative to the
+// solver (see NormalizeMove), using the last orientation the cube reported.
+// OnMove still receives the raw moves.
+//
+// Orientation must be enabled with EnableOrientation; until the cube reports
+// one, and on cubes that never do (GAN, MoYu), moves are passed unchanged.
+// The cube's sense of home drifts: hold it white up, green front and send
+// CommandCalibrateOrientation to reset it.
+func (g *GoCube) OnNormalizedMove(cb func(Move)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onNormalized = cb
+}
+
 // OnPhaseChange sets a callback that fires when a solving phase is completed.
 // The callback receives the newly completed phase.
 func (g *GoCube) OnPhaseChange(cb func(Phase)) {
@@ -196,6 +333,18 @@ func (g *GoCube) OnOrientationChange(cb func(Orientation)) {
 	g.onOrientation = cb
 }
 
+// OnOrientationQuaternion sets a callback for the cube's attitude as a
+// unit quaternion, for 3D views that show how the cube is held rather than
+// just which faces point up and front. It fires with each orientation
+// message, filtered by WithOrientationSmoothing and WithOrientationRate.
+// Orientation must be enabled with EnableOrientation; GAN and MoYu cubes
+// never report it.
+func (g *GoCube) OnOrientationQuaternion(cb func(Quaternion)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onAttitude = cb
+}
+
 // OnBattery sets a callback for battery level updates.
 func (g *GoCube) OnBattery(cb func(int)) {
 	g.mu.Lock()
@@ -203,13 +352,32 @@ func (g *GoCube) OnBattery(cb func(int)) {
 	g.onBattery = cb
 }
 
-// OnDisconnect sets a callback for disconnection events.
+// OnDisconnect sets a callback for disconnection events. It fires with
+// ErrConnectionLost when the link drops, or, with WithAutoReconnect, once
+// every reconnection attempt has failed. It does not fire for Close.
 func (g *GoCube) OnDisconnect(cb func(error)) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.onDisconnect = cb
 }
 
+// OnReconnect sets a callback that fires when WithAutoReconnect has restored
+// a dropped link. Move history is kept; the tracked cube state is then
+// resynced from the cube (GoCube only, like SyncState).
+func (g *GoCube) OnReconnect(cb func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onReconnect = cb
+}
+
+// OnCubeType sets a callback that fires when the cube reports its model,
+// shortly after connecting.
+func (g *GoCube) OnCubeType(cb func(CubeType)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onCubeType = cb
+}
+
 // OnSolved sets a callback that fires when the cube reaches the solved state.
 func (g *GoCube) OnSolved(cb func()) {
 	g.mu.Lock()
@@ -217,36 +385,78 @@ func (g *GoCube) OnSolved(cb func()) {
 	g.onSolved = cb
 }
 
+// OnSleep sets a callback that fires when the cube stops answering
+// keep-alive requests, usually because it went to sleep after inactivity.
+// Requires WithKeepAlive. Turning any face wakes the cube.
+func (g *GoCube) OnSleep(cb func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onSleep = cb
+}
+
+// OnWake sets a callback that fires when a sleeping cube responds again.
+func (g *GoCube) OnWake(cb func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onWake = cb
+}
+
+// OnScrambleProgress sets a callback that fires after each move made while
+// a scramble is expected (see ExpectScramble), with how far it has been
+// applied or how to get back to it after a wrong turn.
+func (g *GoCube) OnScrambleProgress(cb func(ScrambleProgress)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onScramble = cb
+}
+
 // State access
 
 // Cube returns the current cube state.
 // The returned cube can be inspected but modifications won't affect the GoCube.
 func (g *GoCube) Cube() *Cube {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.cube.Clone()
+	return g.snap.Load().cube.Clone()
 }
 
 // Phase returns the current solving phase.
 func (g *GoCube) Phase() Phase {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.cube.Phase()
+	return g.snap.Load().phase
+}
+
+// phase returns the cube's phase in the WithMethod method, which with
+// WithCenterOrientation is solved only once every center is in its solved
+// orientation too.
+func (g *GoCube) phase(c *Cube) Phase {
+	p := c.methodPhase(g.config.method)
+	if p == PhaseSolved && g.config.centerOrientation && !c.CentersSolved() {
+		phases := methodPhases[g.config.method]
+		return phases[len(phases)-2]
+	}
+	return p
 }
 
 // HighestPhase returns the highest phase reached since connection or last reset.
 // This is monotonic - it never goes backwards.
 func (g *GoCube) HighestPhase() Phase {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	return g.highestPhase
+	return g.snap.Load().highest
 }
 
 // IsSolved returns true if the cube is currently solved.
 func (g *GoCube) IsSolved() bool {
+	return g.snap.Load().phase == PhaseSolved
+}
+
+// CubeType returns the cube model, or CubeTypeUnknown until the cube has
+// reported it.
+func (g *GoCube) CubeType() CubeType {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	return g.cube.IsSolved()
+	return g.cubeType
+}
+
+// IsAsleep returns true if the cube stopped answering keep-alive requests.
+func (g *GoCube) IsAsleep() bool {
+	return g.client.IsAsleep()
 }
 
 // Battery returns the last known battery level (0-100), or -1 if unknown.
@@ -256,10 +466,9 @@ func (g *GoCube) Battery() int {
 
 // Moves returns the move history since connection or last clear.
 func (g *GoCube) Moves() []Move {
-	g.mu.RLock()
-	defer g.mu.RUnlock()
-	result := make([]Move, len(g.moveHistory))
-	copy(result, g.moveHistory)
+	moves := g.snap.Load().moves
+	result := make([]Move, len(moves))
+	copy(result, moves)
 	return result
 }
 
@@ -268,22 +477,90 @@ func (g *GoCube) Moves() []Move {
 // Reset resets the internal cube state to solved.
 // Does not affect the physical cube.
 func (g *GoCube) Reset() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
 	g.cube.Reset()
 	g.highestPhase = PhaseScrambled
+	g.publishLocked()
 }
 
-// ClearHistory clears the move history.
-func (g *GoCube) ClearHistory() {
+// ExpectScramble checks the moves made from now on against scramble,
+// applied to a solved cube, reporting each to the OnScrambleProgress
+// callback. Checking stops once the whole scramble has been applied, or
+// when ExpectScramble is called with nil.
+func (g *GoCube) ExpectScramble(scramble []Move) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	g.scramble = nil
+	if scramble != nil {
+		g.scramble = NewScrambleTracker(scramble)
+	}
+}
+
+// ClearHistory clears the move history and restarts Stats.
+func (g *GoCube) ClearHistory() {
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
 	g.moveHistory = make([]Move, 0)
+	g.stats = newStatsTracker()
+	g.publishLocked()
 }
 
-// FlashBacklight flashes the cube backlight.
+// stateSyncTimeout bounds how long SyncState waits for the cube to answer.
+const stateSyncTimeout = 5 * time.Second
+
+// SyncState requests the facelet state from the cube and resets the
+// internal Cube model to match it. Call it after connecting to a cube that
+// is not solved, e.g. when reconnecting mid-solve, since the model
+// otherwise starts from solved and every later phase is detected wrongly.
+//
+// The highest phase restarts from the synced state, so OnPhaseChange fires
+// only for phases completed after the sync. Returns ErrTimeout if the cube
+// does not answer within 5 seconds or before ctx is done, and
+// ErrNotSupported for cubes that cannot report their state.
+//
+// The state is applied in order with the moves, so SyncState must not be
+// called from a move, phase or solved callback; it would time out.
+func (g *GoCube) SyncState(ctx context.Context) error {
+	ch := make(chan *protocol.StateEvent, 1)
+	g.mu.Lock()
+	g.stateWaiters = append(g.stateWaiters, ch)
+	g.mu.Unlock()
+	defer g.removeStateWaiter(ch)
+
+	if err := g.client.RequestState(); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return ErrNotSupported
+		}
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, stateSyncTimeout)
+	defer cancel()
+
+	select {
+	case <-ch: // Adopted by the ingestion goroutine
+		return nil
+	case <-ctx.Done():
+		return ErrTimeout
+	}
+}
+
+func (g *GoCube) removeStateWaiter(ch chan *protocol.StateEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, w := range g.stateWaiters {
+		if w == ch {
+			g.stateWaiters = append(g.stateWaiters[:i], g.stateWaiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// FlashBacklight flashes the cube backlight. See Lights for the other
+// backlight controls.
 func (g *GoCube) FlashBacklight() error {
-	return g.client.FlashBacklight()
+	return g.lights.Flash()
 }
 
 // EnableOrientation enables orientation tracking.
@@ -296,9 +573,34 @@ func (g *GoCube) DisableOrientation() error {
 	return g.client.DisableOrientation()
 }
 
+// SendRawCommand sends a command code to the cube as is, for experimenting
+// with commands this package has no method for. The code is checked with
+// ValidateRawCommand first. Replies arrive through the usual callbacks if
+// the package decodes them; others are ignored.
+//
+// GAN and MoYu cubes only accept the commands their driver can translate
+// and return ErrNotSupported for the rest. After CommandResetSolved the
+// tracked state is reset to solved to match the cube.
+func (g *GoCube) SendRawCommand(cmd byte) error {
+	if err := ValidateRawCommand(cmd); err != nil {
+		return err
+	}
+	if err := g.client.SendCommand(cmd); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return ErrNotSupported
+		}
+		return err
+	}
+	if Command(cmd) == CommandResetSolved {
+		g.Reset()
+	}
+	return nil
+}
+
 // Internal message handling
 
 func (g *GoCube) handleMessage(msg *protocol.Message) {
+	g.link.message(time.Now())
 	switch msg.Type {
 	case protocol.MsgTypeRotation:
 		g.handleRotation(msg)
@@ -306,6 +608,12 @@ func (g *GoCube) handleMessage(msg *protocol.Message) {
 		g.handleBattery(msg)
 	case protocol.MsgTypeOrientation:
 		g.handleOrientation(msg)
+	case protocol.MsgTypeState:
+		g.handleState(msg)
+	case protocol.MsgTypeCubeType:
+		g.handleCubeType(msg)
+	case protocol.MsgTypeOfflineStats:
+		g.handleOfflineStats(msg)
 	}
 }
 
@@ -316,42 +624,19 @@ func (g *GoCube) handleRotation(msg *protocol.Message) {
 	}
 
 	now := time.Now()
-	for _, rot := range rotations {
-		move := rotationToMove(rot, now)
-
-		g.mu.Lock()
-		g.cube.Apply(move)
-		if g.config.moveHistory {
-			g.moveHistory = append(g.moveHistory, move)
-		}
-
-		// Check for phase transitions
-		currentPhase := g.cube.Phase()
-		phaseCallback := g.onPhaseChange
-		solvedCallback := g.onSolved
-		isSolved := currentPhase == PhaseSolved
-		phaseChanged := currentPhase > g.highestPhase
-		if phaseChanged {
-			g.highestPhase = currentPhase
-		}
-		g.mu.Unlock()
-
-		// Fire callbacks outside the lock
-		if phaseChanged && phaseCallback != nil {
-			phaseCallback(currentPhase)
-		}
-		if isSolved && phaseChanged && solvedCallback != nil {
-			solvedCallback()
-		}
-
-		// Call move callback
-		g.mu.RLock()
-		moveCallback := g.onMove
-		g.mu.RUnlock()
-		if moveCallback != nil {
-			moveCallback(move)
-		}
+	moves := make([]Move, len(rotations))
+	for i, rot := range rotations {
+		moves[i] = rotationToMove(rot, now)
+		moves[i].BatchIndex, moves[i].BatchSize = i, len(rotations)
 	}
+	at, repeated := g.link.rotation(msg.Payload, moves, now, g.config.smoothTimes)
+	if repeated {
+		return
+	}
+	for i := range moves {
+		moves[i].Time = at
+	}
+	g.enqueue(ingestItem{moves: moves, at: at})
 }
 
 func (g *GoCube) handleBattery(msg *protocol.Message) {
@@ -360,13 +645,41 @@ func (g *GoCube) handleBattery(msg *protocol.Message) {
 		return
 	}
 
-	g.mu.RLock()
+	g.mu.Lock()
 	cb := g.onBattery
-	g.mu.RUnlock()
+	lowCallback := g.crossedLowBatteryLocked(battery.Level)
+	g.mu.Unlock()
 
 	if cb != nil {
 		cb(battery.Level)
 	}
+	if lowCallback != nil {
+		lowCallback(battery.Level)
+	}
+}
+
+func (g *GoCube) handleCubeType(msg *protocol.Message) {
+	event, err := protocol.DecodeCubeType(msg.Payload)
+	if err != nil {
+		return
+	}
+
+	g.mu.Lock()
+	g.cubeType = CubeType(event.TypeName)
+	cb := g.onCubeType
+	g.mu.Unlock()
+
+	if cb != nil {
+		cb(CubeType(event.TypeName))
+	}
+}
+
+func (g *GoCube) handleState(msg *protocol.Message) {
+	state, err := protocol.DecodeState(msg.Payload)
+	if err != nil {
+		return
+	}
+	g.enqueue(ingestItem{state: state})
 }
 
 func (g *GoCube) handleOrientation(msg *protocol.Message) {
@@ -374,16 +687,83 @@ func (g *GoCube) handleOrientation(msg *protocol.Message) {
 	if err != nil {
 		return
 	}
+	g.link.orientation(time.Now())
+
+	o := Orientation{
+		UpFace:    Face(orient.UpFace),
+		FrontFace: Face(orient.FrontFace),
+	}
+
+	g.mu.Lock()
+	g.orientation = o
+	cb, attitudeCallback := g.onOrientation, g.onAttitude
+	q, due := g.attitude.update(Quaternion{orient.X, orient.Y, orient.Z, orient.W}, time.Now())
+	g.mu.Unlock()
+
+	if cb != nil {
+		cb(o)
+	}
+	if due && attitudeCallback != nil {
+		attitudeCallback(q)
+	}
+}
+
+func (g *GoCube) handleSleep() {
+	g.mu.RLock()
+	cb := g.onSleep
+	g.mu.RUnlock()
+
+	if cb != nil {
+		cb()
+	}
+}
+
+func (g *GoCube) handleDisconnect() {
+	if g.config.autoReconnect && g.config.reconnectTries > 0 {
+		return // Reported by handleReconnect if reconnecting fails
+	}
+
+	g.mu.RLock()
+	cb := g.onDisconnect
+	g.mu.RUnlock()
+
+	if cb != nil {
+		cb(ErrConnectionLost)
+	}
+}
+
+// handleReconnect runs before the client requests the cube state, so the
+// state is adopted as soon as it arrives.
+func (g *GoCube) handleReconnect(err error) {
+	if err == nil {
+		g.link.reconnected()
+	}
+
+	g.mu.Lock()
+	if err == nil {
+		g.resyncState = true
+	}
+	onReconnect, onDisconnect := g.onReconnect, g.onDisconnect
+	g.mu.Unlock()
+
+	if err != nil {
+		if onDisconnect != nil {
+			onDisconnect(fmt.Errorf("%w: gave up after %d reconnection attempts", ErrConnectionLost, g.config.reconnectTries))
+		}
+		return
+	}
+	if onReconnect != nil {
+		onReconnect()
+	}
+}
 
+func (g *GoCube) handleWake() {
 	g.mu.RLock()
-	cb := g.onOrientation
+	cb := g.onWake
 	g.mu.RUnlock()
 
 	if cb != nil {
-		cb(Orientation{
-			UpFace:    Face(orient.UpFace),
-			FrontFace: Face(orient.FrontFace),
-		})
+		cb()
 	}
 }
 
@@ -408,8 +788,12 @@ func rotationToMove(rot protocol.RotationEvent, t time.Time) Move {
 	}
 
 	return Move{
-		Face: face,
-		Turn: turn,
-		Time: t,
+		Face:       face,
+		Turn:       turn,
+		Time:       t,
+		Center:     protocol.CenterQuarterTurns(rot.CenterOrientation),
+		HasCenter:  rot.CenterOrientation != protocol.CenterUnknown,
+		Elapsed:    rot.Elapsed,
+		HasElapsed: rot.HasElapsed,
 	}
 }
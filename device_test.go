@@ -0,0 +1,67 @@
+//go:build !js
+
+package gocube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+func TestCloseFromCallback(t *testing.T) {
+	adv := ble.Advertisement{Name: "GoCube_1234", Address: "00:11:22:33:44:55"}
+	transport := ble.NewMockTransport(adv)
+	client, err := ble.NewClient(ble.WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.Connect(context.Background(), adv.Address); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	g := newGoCube(client, Device{Name: adv.Name, UUID: adv.Address}, defaultConfig())
+
+	// The first move's callback closes the cube once the queue is full and
+	// a notification handler is waiting on it
+	release := make(chan struct{})
+	closed := make(chan error, 1)
+	first := true
+	g.OnMove(func(Move) {
+		if first {
+			first = false
+			<-release
+			closed <- g.Close()
+		}
+	})
+
+	notified := make(chan struct{})
+	go func() {
+		defer close(notified)
+		for i := 0; i < ingestQueueSize+2; i++ {
+			payload := []byte{byte(i % 2), 0x00} // Alternate faces so none repeats
+			transport.Notify(protocol.TxCharUUID, protocol.BuildMessage(protocol.MsgTypeRotation, payload))
+			if i == ingestQueueSize {
+				time.AfterFunc(20*time.Millisecond, func() { close(release) })
+			}
+		}
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close from a callback deadlocked")
+	}
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a notification handler is still waiting after Close")
+	}
+	if transport.Connected() != "" {
+		t.Error("Close left the transport connected")
+	}
+}
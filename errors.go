@@ -10,10 +10,25 @@ var (
 	ErrDeviceNotFound   = errors.New("gocube: device not found")
 	ErrConnectionFailed = errors.New("gocube: connection failed")
 	ErrTimeout          = errors.New("gocube: operation timed out")
+	ErrConnectionLost   = errors.New("gocube: connection lost")
 
 	// Parsing errors
 	ErrInvalidNotation = errors.New("gocube: invalid move notation")
+	ErrInvalidPattern  = errors.New("gocube: invalid pattern")
 
 	// State errors
 	ErrCubeNotReady = errors.New("gocube: cube not ready")
+
+	// ErrInvalidCommand is returned by SendRawCommand for command codes
+	// outside the range GoCube commands use.
+	ErrInvalidCommand = errors.New("gocube: invalid command")
+
+	// ErrNotSupported is returned for requests the connected cube's
+	// protocol has no equivalent for, e.g. SyncState on GAN and MoYu cubes.
+	ErrNotSupported = errors.New("gocube: not supported by this cube")
+
+	// ErrUnsupportedMethod is returned by Cube.Hint for solving methods it
+	// has no hints for, and by MethodPhases, Cube.PhaseFor and WithMethod
+	// for methods whose phases are not detected.
+	ErrUnsupportedMethod = errors.New("gocube: unsupported solving method")
 )
@@ -115,6 +115,9 @@ func main() {
 	cube.OnBattery(func(level int) {
 		fmt.Printf("  Battery: %d%%\n", level)
 	})
+	cube.OnBatteryLow(func(level int) {
+		fmt.Printf("  Battery low (%d%%), charge the cube soon\n", level)
+	})
 
 	// Keep the program running until:
 	// - User presses Ctrl+C (SIGINT/SIGTERM)
@@ -1,11 +1,14 @@
 // Package gocube provides a Go library for interacting with GoCube smart
-// Rubik's cubes via Bluetooth Low Energy (BLE).
+// Rubik's cubes via Bluetooth Low Energy (BLE). GAN Gen2 cubes (GAN 356 i3,
+// GAN12 ui, Monster Go 3Ai) and MoYu AI cubes are supported through the same
+// API; they report moves and battery level but not orientation.
 //
 // # Features
 //
 //   - Device discovery and connection
 //   - Real-time move tracking with timestamps
 //   - Cube state simulation (works standalone without BLE)
+//   - WCA-style random-state scramble generation
 //   - Automatic solving phase detection
 //   - Orientation tracking
 //
@@ -67,4 +70,8 @@
 //   - PhaseYellowCorners: Yellow corners positioned
 //   - PhaseYellowOriented: Yellow corners oriented
 //   - PhaseSolved: Cube is solved
+//
+// WithMethod switches to the phases of Roux (PhaseFirstBlock,
+// PhaseSecondBlock, PhaseCMLL, then LSE to PhaseSolved) or ZZ
+// (PhaseEOLine, PhaseZZF2L, then the last layer to PhaseSolved).
 package gocube
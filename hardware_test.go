@@ -0,0 +1,249 @@
+//go:build hardware && !js
+
+package gocube
+
+// Hardware integration tests. These talk to a real cube over Bluetooth and
+// are excluded from normal test runs. With a solved cube nearby (and not
+// connected to a phone), run from the repository root:
+//
+//	go test -tags=hardware -v -run Hardware -timeout 5m .
+//
+// Set GOCUBE_DEVICE to a device name to pick a cube when several are in
+// range; otherwise the strongest signal is used. The test prompts for the
+// moves to make and ends with a compatibility report.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+const (
+	hardwareScanTimeout = 15 * time.Second
+	hardwareMoveTimeout = 30 * time.Second
+)
+
+// hardwareStep is one line of the compatibility report.
+type hardwareStep struct {
+	name   string
+	result string // "ok", "FAIL" or "n/a"
+	detail string
+}
+
+type hardwareReport struct {
+	device Device
+	steps  []hardwareStep
+}
+
+func (r *hardwareReport) add(name, result, detail string) {
+	r.steps = append(r.steps, hardwareStep{name, result, detail})
+}
+
+func (r *hardwareReport) print() {
+	fmt.Println()
+	fmt.Println("Hardware compatibility report")
+	fmt.Println("=============================")
+	if r.device.Name != "" {
+		fmt.Printf("Device: %s (%s, RSSI %d dBm)\n", r.device.Name, r.device.Vendor, r.device.RSSI)
+	}
+	for _, s := range r.steps {
+		fmt.Printf("  %-16s %-4s  %s\n", s.name, s.result, s.detail)
+	}
+	fmt.Println()
+}
+
+// prompt asks the tester to do something with the cube. go test streams
+// stdout when run on a single package directory, as above.
+func prompt(format string, args ...interface{}) {
+	fmt.Printf(">>> "+format+"\n", args...)
+}
+
+func TestHardwareEndToEnd(t *testing.T) {
+	report := &hardwareReport{}
+	defer report.print()
+
+	ctx := context.Background()
+
+	// Scan
+	prompt("Scanning for %s; wake the cube by turning a face...", hardwareScanTimeout)
+	devices, err := Scan(ctx, hardwareScanTimeout)
+	if err != nil {
+		report.add("scan", "FAIL", err.Error())
+		t.Fatalf("Scan failed: %v", err)
+	}
+	device, ok := pickHardwareDevice(devices, os.Getenv("GOCUBE_DEVICE"))
+	if !ok {
+		report.add("scan", "FAIL", fmt.Sprintf("%d devices found, none matched", len(devices)))
+		t.Fatalf("No cube found (GOCUBE_DEVICE=%q)", os.Getenv("GOCUBE_DEVICE"))
+	}
+	report.device = device
+	report.add("scan", "ok", fmt.Sprintf("%d device(s) found", len(devices)))
+
+	// Connect
+	moves := make(chan Move, 16)
+	cubeTypes := make(chan CubeType, 1)
+	battery := make(chan int, 1)
+
+	start := time.Now()
+	cube, err := Connect(ctx, device, WithAutoReconnect(false))
+	if err != nil {
+		report.add("connect", "FAIL", err.Error())
+		t.Fatalf("Connect failed: %v", err)
+	}
+	report.add("connect", "ok", fmt.Sprintf("in %s", time.Since(start).Round(time.Millisecond)))
+	closed := false
+	defer func() {
+		if !closed {
+			cube.Close()
+		}
+	}()
+
+	cube.OnMove(func(m Move) { moves <- m })
+	cube.OnCubeType(func(ct CubeType) {
+		select {
+		case cubeTypes <- ct:
+		default:
+		}
+	})
+	cube.OnBattery(func(level int) {
+		select {
+		case battery <- level:
+		default:
+		}
+	})
+
+	// Cube type and battery are informational; only GoCubes report them
+	select {
+	case ct := <-cubeTypes:
+		report.add("cube type", "ok", string(ct))
+	case <-time.After(3 * time.Second):
+		if ct := cube.CubeType(); ct != CubeTypeUnknown {
+			report.add("cube type", "ok", string(ct))
+		} else {
+			report.add("cube type", "n/a", "not reported")
+		}
+	}
+	select {
+	case level := <-battery:
+		report.add("battery", "ok", fmt.Sprintf("%d%%", level))
+	case <-time.After(2 * time.Second):
+		report.add("battery", "n/a", "not reported")
+	}
+
+	// Move echo: each prompted move must arrive as exactly that move. The
+	// sequence undoes itself so the cube ends solved for the state check.
+	expected := []Move{R, UPrime, U, RPrime}
+	echoed := 0
+	var latencies []time.Duration
+	for _, want := range expected {
+		prompt("Turn %s (hold the cube white up, green front)", describeMove(want))
+		asked := time.Now()
+		select {
+		case got := <-moves:
+			if got.Face != want.Face || got.Turn != want.Turn {
+				report.add("move echo", "FAIL", fmt.Sprintf("asked for %s, got %s", want.Notation(), got.Notation()))
+				t.Fatalf("Move echo: asked for %s, got %s", want.Notation(), got.Notation())
+			}
+			latencies = append(latencies, time.Since(asked))
+			echoed++
+		case <-time.After(hardwareMoveTimeout):
+			report.add("move echo", "FAIL", fmt.Sprintf("no move within %s after %d of %d", hardwareMoveTimeout, echoed, len(expected)))
+			t.Fatalf("Move echo: timed out waiting for %s", want.Notation())
+		}
+	}
+	report.add("move echo", "ok", fmt.Sprintf("%d/%d moves (%s)", echoed, len(expected), FormatMoves(expected)))
+
+	// Nothing else should have arrived, e.g. a half-turn split oddly
+	select {
+	case extra := <-moves:
+		report.add("stray moves", "FAIL", "unexpected "+extra.Notation())
+		t.Errorf("Unexpected extra move %s", extra.Notation())
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if !cube.IsSolved() {
+		report.add("model", "FAIL", "model not solved after R U' U R'")
+		t.Errorf("Cube model should be solved after a self-cancelling sequence")
+	} else {
+		report.add("model", "ok", "tracked moves return to solved")
+	}
+
+	// LED
+	if err := cube.FlashBacklight(); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			report.add("backlight", "n/a", "no backlight command")
+		} else {
+			report.add("backlight", "FAIL", err.Error())
+			t.Errorf("FlashBacklight failed: %v", err)
+		}
+	} else {
+		report.add("backlight", "ok", "command sent; the cube should flash three times")
+	}
+
+	// State request: the cube's own idea of its state must match the model
+	syncCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	err = cube.SyncState(syncCtx)
+	cancel()
+	switch {
+	case errors.Is(err, ErrNotSupported):
+		report.add("state request", "n/a", "cube cannot report its state")
+	case err != nil:
+		report.add("state request", "FAIL", err.Error())
+		t.Errorf("SyncState failed: %v", err)
+	case !cube.IsSolved():
+		report.add("state request", "FAIL", "cube reports unsolved; was it solved at the start?")
+		t.Errorf("Synced state should be solved")
+	default:
+		report.add("state request", "ok", "reported state matches the model")
+	}
+
+	// Disconnect
+	if err := cube.Close(); err != nil {
+		report.add("disconnect", "FAIL", err.Error())
+		t.Errorf("Close failed: %v", err)
+	} else if cube.IsConnected() {
+		report.add("disconnect", "FAIL", "still connected after Close")
+		t.Errorf("IsConnected should be false after Close")
+	} else {
+		report.add("disconnect", "ok", "")
+	}
+	closed = true
+}
+
+// pickHardwareDevice returns the device named name, or the one with the
+// strongest signal if name is empty.
+func pickHardwareDevice(devices []Device, name string) (Device, bool) {
+	var best Device
+	found := false
+	for _, d := range devices {
+		if name != "" {
+			if strings.EqualFold(d.Name, name) {
+				return d, true
+			}
+			continue
+		}
+		if !found || d.RSSI > best.RSSI {
+			best, found = d, true
+		}
+	}
+	return best, found
+}
+
+// describeMove spells a move out for the tester.
+func describeMove(m Move) string {
+	names := map[Face]string{
+		FaceR: "the right face", FaceL: "the left face", FaceU: "the top face",
+		FaceD: "the bottom face", FaceF: "the front face", FaceB: "the back face",
+	}
+	dir := "clockwise"
+	if m.Turn == CCW {
+		dir = "counter-clockwise"
+	}
+	return fmt.Sprintf("%s: %s %s", m.Notation(), names[m.Face], dir)
+}
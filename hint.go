@@ -0,0 +1,418 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MethodLayerByLayer is the beginner layer-by-layer method the phases
+// describe: white cross and corners on top, the middle layer, then the
+// yellow cross, corner positions, corner orientation and edges on the
+// bottom. It is the only method Hint supports.
+const MethodLayerByLayer = "layer-by-layer"
+
+// Hint is a suggested next step towards solving a cube.
+type Hint struct {
+	Moves       []Move // Empty when the cube is solved
+	Explanation string // e.g. "Insert the green-red edge with D' R' D R D F D' F'"
+	Phase       Phase  // The phase the step works towards
+}
+
+// hintMaxNodes bounds the cube states one hint search visits, so a hint is
+// always quick to compute.
+const hintMaxNodes = 2000000
+
+// Hint returns the next step towards solving c with method, as the moves of
+// one step and what they do: a cross edge, a first layer corner, a middle
+// layer edge, or an algorithm and its setup turns on the last layer. Each
+// step places at least one more piece, without undoing earlier phases, so
+// following hints from any state solves the cube.
+//
+// Moves are outer-face turns relative to the centers, as a smart cube
+// reports them: U turns the white face. Method is MethodLayerByLayer
+// (also "beginner" or "lbl"); other methods return ErrUnsupportedMethod.
+func (c *Cube) Hint(method string) (Hint, error) {
+	switch strings.ToLower(method) {
+	case MethodLayerByLayer, "beginner", "lbl", "":
+	default:
+		return Hint{}, fmt.Errorf("%w: no hints for %q", ErrUnsupportedMethod, method)
+	}
+
+	cube := *c
+	cube.frame = moveFrame{} // Hint moves are relative to the centers
+	cube.history = nil       // The copy shares it; the search must not record
+	phase := cube.Phase()
+	if phase == PhaseSolved {
+		return Hint{Phase: PhaseSolved, Explanation: "The cube is solved"}, nil
+	}
+
+	stage := hintStages[phase]
+	macros := stage.macros()
+	before := stage.count(&cube)
+	search := hintSearch{
+		macros: macros,
+		done: func(next *Cube) bool {
+			p := next.Phase()
+			return p > phase || (p == phase && stage.count(next) > before)
+		},
+	}
+	path, ok := search.run(cube, stage.depth)
+	if !ok {
+		return Hint{}, fmt.Errorf("gocube: no hint found for the %s phase", phase+1)
+	}
+
+	var moves []Move
+	for _, i := range path {
+		moves = append(moves, macros[i]...)
+	}
+	moves = mergeHintMoves(moves)
+
+	after := cube
+	after.Apply(moves...)
+	action := stage.progress
+	if after.Phase() > phase {
+		action = stage.complete
+	}
+	if stage.pieces != nil {
+		if piece := newlySolved(solvedPieces(&cube, stage.pieces), solvedPieces(&after, stage.pieces)); piece != "" {
+			action = fmt.Sprintf(stage.place, piece)
+		}
+	}
+	return Hint{
+		Moves:       moves,
+		Explanation: fmt.Sprintf("%s with %s", action, FormatMoves(moves)),
+		Phase:       phase + 1,
+	}, nil
+}
+
+// hintPiece is a cubie position as the facelets it shows, the first of them
+// on the U or D face.
+type hintPiece struct {
+	facelets [][2]int // {face, index}
+}
+
+// solvedAt reports whether the piece in position p is its own, turned the
+// right way.
+func (p hintPiece) solvedAt(c *Cube) bool {
+	for _, f := range p.facelets {
+		if c.Facelets[f[0]][f[1]] != c.Facelets[f[0]][4] {
+			return false
+		}
+	}
+	return true
+}
+
+// name returns the piece's colors, e.g. "white-green-red".
+func (p hintPiece) name() string {
+	names := make([]string, len(p.facelets))
+	for i, f := range p.facelets {
+		names[i] = colorName(faceToSolvedColor(CubeFace(f[0])))
+	}
+	return strings.Join(names, "-")
+}
+
+func colorName(c Color) string {
+	switch c {
+	case White:
+		return "white"
+	case Yellow:
+		return "yellow"
+	case Green:
+		return "green"
+	case Blue:
+		return "blue"
+	case Red:
+		return "red"
+	case Orange:
+		return "orange"
+	default:
+		return "?"
+	}
+}
+
+const (
+	fU = int(CubeFaceU)
+	fD = int(CubeFaceD)
+	fF = int(CubeFaceF)
+	fB = int(CubeFaceB)
+	fR = int(CubeFaceR)
+	fL = int(CubeFaceL)
+)
+
+var (
+	crossEdges = []hintPiece{
+		{[][2]int{{fU, 7}, {fF, 1}}},
+		{[][2]int{{fU, 5}, {fR, 1}}},
+		{[][2]int{{fU, 1}, {fB, 1}}},
+		{[][2]int{{fU, 3}, {fL, 1}}},
+	}
+	topCorners = []hintPiece{
+		{[][2]int{{fU, 8}, {fF, 2}, {fR, 0}}},
+		{[][2]int{{fU, 2}, {fR, 2}, {fB, 0}}},
+		{[][2]int{{fU, 0}, {fB, 2}, {fL, 0}}},
+		{[][2]int{{fU, 6}, {fL, 2}, {fF, 0}}},
+	}
+	middleEdges = []hintPiece{
+		{[][2]int{{fF, 5}, {fR, 3}}},
+		{[][2]int{{fR, 5}, {fB, 3}}},
+		{[][2]int{{fB, 5}, {fL, 3}}},
+		{[][2]int{{fL, 5}, {fF, 3}}},
+	}
+	bottomEdges = []hintPiece{
+		{[][2]int{{fD, 1}, {fF, 7}}},
+		{[][2]int{{fD, 5}, {fR, 7}}},
+		{[][2]int{{fD, 7}, {fB, 7}}},
+		{[][2]int{{fD, 3}, {fL, 7}}},
+	}
+	bottomCorners = []hintPiece{
+		{[][2]int{{fD, 2}, {fF, 8}, {fR, 6}}},
+		{[][2]int{{fD, 8}, {fR, 8}, {fB, 6}}},
+		{[][2]int{{fD, 6}, {fB, 8}, {fL, 6}}},
+		{[][2]int{{fD, 0}, {fL, 8}, {fF, 6}}},
+	}
+)
+
+// hintStage is how hints work towards the phase after the one the cube is
+// in: the steps it may take and what counts as progress.
+type hintStage struct {
+	steps    []string        // Algorithms, each tried from the four sides
+	turns    []Move          // Single turns tried besides the algorithms
+	depth    int             // Most steps and turns in one hint
+	pieces   []hintPiece     // Pieces placed in this stage, if placed one by one
+	count    func(*Cube) int // Progress; nil counts the solved pieces
+	place    string          // Action for placing a piece, with its name
+	progress string          // Action for progress short of the next phase
+	complete string          // Action completing the next phase
+
+	once     sync.Once
+	expanded [][]Move
+}
+
+// macros returns the stage's turns and algorithms, the algorithms seen
+// from each side of the cube with yellow on top and expanded to turns
+// relative to the centers.
+func (s *hintStage) macros() [][]Move {
+	s.once.Do(func() {
+		for _, t := range s.turns {
+			s.expanded = append(s.expanded, []Move{t})
+		}
+		for _, alg := range s.steps {
+			for _, view := range []string{"", "y", "y2", "y'"} {
+				moves, err := ParseMoves(strings.TrimSpace(view + " " + alg))
+				if err != nil {
+					panic("gocube: invalid hint algorithm " + alg)
+				}
+				s.expanded = append(s.expanded, ExpandMoves(moves))
+			}
+		}
+	})
+	return s.expanded
+}
+
+// solvedPieces returns which of pieces are solved.
+func solvedPieces(c *Cube, pieces []hintPiece) []string {
+	var solved []string
+	for _, p := range pieces {
+		if p.solvedAt(c) {
+			solved = append(solved, p.name())
+		}
+	}
+	return solved
+}
+
+// newlySolved returns the first piece solved in after but not before.
+func newlySolved(before, after []string) string {
+	for _, name := range after {
+		found := false
+		for _, b := range before {
+			found = found || b == name
+		}
+		if !found {
+			return name
+		}
+	}
+	return ""
+}
+
+var (
+	hintAllTurns = []Move{U, UPrime, U2, D, DPrime, D2, F, FPrime, F2, B, BPrime, B2, R, RPrime, R2, L, LPrime, L2}
+	hintDTurns   = []Move{D, DPrime, D2}
+)
+
+// hintStages holds the stage for each phase below solved. Last layer
+// algorithms are written with yellow on top (z2), as they are learned.
+var hintStages = map[Phase]*hintStage{
+	PhaseScrambled: {
+		turns: hintAllTurns, depth: 6, pieces: crossEdges,
+		place: "Solve the %s edge of the cross", progress: "Work on the white cross", complete: "Complete the white cross",
+	},
+	PhaseWhiteCross: {
+		turns: hintDTurns, depth: 4, pieces: topCorners,
+		steps: []string{"R' D' R", "R' D R", "F D F'", "F D' F'", "R' D2 R D R' D' R", "R' D' R D"},
+		place: "Insert the %s corner", progress: "Work on the first layer", complete: "Complete the first layer",
+	},
+	PhaseFirstLayer: {
+		turns: hintDTurns, depth: 4, pieces: middleEdges,
+		steps: []string{"z2 U R U' R' U' F' U F", "z2 U' L' U L U F U' F'"},
+		place: "Insert the %s edge into the middle layer", progress: "Work on the middle layer", complete: "Complete the middle layer",
+	},
+	PhaseSecondLayer: {
+		turns: hintDTurns, depth: 4,
+		count:    func(c *Cube) int { return countFacing(c, bottomEdges) },
+		steps:    []string{"z2 F R U R' U' F'", "z2 F U R U' R' F'"},
+		progress: "Turn more yellow edges to face down", complete: "Complete the yellow cross",
+	},
+	PhaseYellowCross: {
+		turns: hintDTurns, depth: 4,
+		count:    func(c *Cube) int { return countPositioned(c, bottomCorners) },
+		steps:    []string{"z2 U R U' L' U R' U' L"},
+		progress: "Put more yellow corners in place", complete: "Put every yellow corner in place",
+	},
+	PhaseYellowCorners: {
+		turns: hintDTurns, depth: 5,
+		count:    func(c *Cube) int { return countFacing(c, bottomCorners) },
+		steps:    []string{"z2 R U R' U R U2 R'", "z2 R U2 R' U' R U' R'"},
+		progress: "Twist more yellow corners to face down", complete: "Twist the last yellow corners",
+	},
+	PhaseYellowOriented: {
+		turns: hintDTurns, depth: 4,
+		count:    func(c *Cube) int { return countSolved(c, bottomEdges) },
+		steps:    []string{"z2 R2 U R U R' U' R' U' R' U R'", "z2 R U' R U R U R U' R' U' R2"},
+		progress: "Cycle the yellow edges", complete: "Solve the cube",
+	},
+}
+
+func init() {
+	// Stages placing pieces one by one measure progress by the pieces
+	for _, s := range hintStages {
+		if s.count == nil {
+			pieces := s.pieces
+			s.count = func(c *Cube) int { return countSolved(c, pieces) }
+		}
+	}
+}
+
+// countFacing counts the pieces showing their U or D color on that face.
+func countFacing(c *Cube, pieces []hintPiece) int {
+	n := 0
+	for _, p := range pieces {
+		f := p.facelets[0]
+		if c.Facelets[f[0]][f[1]] == c.Facelets[f[0]][4] {
+			n++
+		}
+	}
+	return n
+}
+
+// countPositioned counts the pieces in their own position, however they
+// are turned.
+func countPositioned(c *Cube, pieces []hintPiece) int {
+	n := 0
+	for _, p := range pieces {
+		var have, want []Color
+		for _, f := range p.facelets {
+			have = append(have, c.Facelets[f[0]][f[1]])
+			want = append(want, c.Facelets[f[0]][4])
+		}
+		if sameColors(have, want) {
+			n++
+		}
+	}
+	return n
+}
+
+// countSolved counts the solved pieces.
+func countSolved(c *Cube, pieces []hintPiece) int {
+	n := 0
+	for _, p := range pieces {
+		if p.solvedAt(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// hintSearch finds the fewest macros, then fewest moves, reaching a state
+// done accepts, by iterative deepening.
+type hintSearch struct {
+	macros [][]Move
+	done   func(*Cube) bool
+	nodes  int
+	path   []int
+}
+
+func (s *hintSearch) run(c Cube, maxDepth int) ([]int, bool) {
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.path = s.path[:0]
+		if s.dfs(&c, depth) {
+			return s.path, true
+		}
+		if s.nodes > hintMaxNodes {
+			break
+		}
+	}
+	return nil, false
+}
+
+func (s *hintSearch) dfs(c *Cube, togo int) bool {
+	if togo == 0 {
+		return s.done(c)
+	}
+	s.nodes++
+	if s.nodes > hintMaxNodes {
+		return false
+	}
+	for i, macro := range s.macros {
+		if len(s.path) > 0 && !hintFollows(s.macros[s.path[len(s.path)-1]], macro) {
+			continue
+		}
+		next := *c
+		for _, m := range macro {
+			next.turnFace(m)
+		}
+		s.path = append(s.path, i)
+		if s.dfs(&next, togo-1) {
+			return true
+		}
+		s.path = s.path[:len(s.path)-1]
+	}
+	return false
+}
+
+// hintFollows reports whether macro may follow prev: single turns of the
+// same face merge, and of opposite faces are tried in one order only.
+func hintFollows(prev, macro []Move) bool {
+	if len(prev) != 1 || len(macro) != 1 {
+		return true
+	}
+	a, b := moveFaceToCubeFace(prev[0].Face), moveFaceToCubeFace(macro[0].Face)
+	if a == b {
+		return false
+	}
+	return a^1 != b || a < b // U/D, F/B and R/L differ in the lowest bit
+}
+
+// mergeHintMoves merges consecutive turns of the same face, dropping those
+// that cancel.
+func mergeHintMoves(moves []Move) []Move {
+	var out []Move
+	for _, m := range moves {
+		if n := len(out); n > 0 && out[n-1].Face == m.Face {
+			turn := ((int(out[n-1].Turn)+int(m.Turn))%4 + 4) % 4
+			switch turn {
+			case 0:
+				out = out[:n-1]
+			case 1:
+				out[n-1].Turn = CW
+			case 2:
+				out[n-1].Turn = Double
+			case 3:
+				out[n-1].Turn = CCW
+			}
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
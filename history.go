@@ -0,0 +1,121 @@
+package gocube
+
+import "fmt"
+
+// cubeHistory is the move history of a Cube with history tracking on.
+type cubeHistory struct {
+	entries []historyEntry
+	pos     int // Entries before pos are applied; those from pos on are undone
+}
+
+// historyEntry is an applied move and what its inverse cannot restore.
+type historyEntry struct {
+	move    Move
+	centers [6]int    // Before the move; a reported center is absolute
+	frame   moveFrame // Before the move
+}
+
+// TrackHistory turns move history on or off. With it on, every move
+// applied with Apply or ApplyNotation is recorded so it can be stepped back
+// and forth with Undo, Redo and Checkout; history costs one entry per move
+// rather than a clone of the cube per step. Turning it on starts an empty
+// history at the current state; turning it off discards the history.
+// Tracking is off for a new cube.
+func (c *Cube) TrackHistory(enabled bool) {
+	switch {
+	case !enabled:
+		c.history = nil
+	case c.history == nil:
+		c.history = &cubeHistory{}
+	}
+}
+
+// record adds an applied move to the history, dropping any undone moves so
+// that the history branches from the current position.
+func (h *cubeHistory) record(m Move, centers [6]int, frame moveFrame) {
+	h.entries = append(h.entries[:h.pos], historyEntry{move: m, centers: centers, frame: frame})
+	h.pos++
+}
+
+// Undo takes back the last applied move in the history. It returns false
+// if there is none, or history tracking is off.
+func (c *Cube) Undo() bool {
+	h := c.history
+	if h == nil || h.pos == 0 {
+		return false
+	}
+	h.pos--
+	e := h.entries[h.pos]
+	c.applyMove(e.move.Inverse())
+	c.Centers = e.centers
+	c.frame = e.frame
+	return true
+}
+
+// Redo applies the move taken back by the last Undo. It returns false if
+// there is none: nothing has been undone, a move has been applied since,
+// or history tracking is off.
+func (c *Cube) Redo() bool {
+	h := c.history
+	if h == nil || h.pos == len(h.entries) {
+		return false
+	}
+	c.applyMove(h.entries[h.pos].move)
+	h.pos++
+	return true
+}
+
+// HistoryLen returns the number of moves in the history, undone moves
+// included, or 0 if history tracking is off.
+func (c *Cube) HistoryLen() int {
+	if c.history == nil {
+		return 0
+	}
+	return len(c.history.entries)
+}
+
+// HistoryPos returns how many moves of the history are applied: HistoryLen
+// unless moves have been undone.
+func (c *Cube) HistoryPos() int {
+	if c.history == nil {
+		return 0
+	}
+	return c.history.pos
+}
+
+// History returns the moves in the history, undone moves included. The
+// first HistoryPos of them are applied.
+func (c *Cube) History() []Move {
+	if c.history == nil {
+		return nil
+	}
+	moves := make([]Move, len(c.history.entries))
+	for i, e := range c.history.entries {
+		moves[i] = e.move
+	}
+	return moves
+}
+
+// Checkout undoes or redoes moves until the first n moves of the history
+// are applied; 0 is the state history tracking started from. Applying a
+// move after checking out an earlier point starts a new branch there,
+// discarding the moves after it.
+//
+// Example:
+//
+//	cube.TrackHistory(true)
+//	cube.ApplyNotation("R U R' U'")
+//	cube.Checkout(2)             // After R U
+//	cube.ApplyNotation("R2 F")   // History is now R U R2 F
+func (c *Cube) Checkout(n int) error {
+	if n < 0 || n > c.HistoryLen() {
+		return fmt.Errorf("gocube: history position %d out of range 0-%d", n, c.HistoryLen())
+	}
+	for c.history.pos > n {
+		c.Undo()
+	}
+	for c.history.pos < n {
+		c.Redo()
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+//go:build !js
+
+package gocube
+
+import (
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// ingestQueueSize bounds how many notifications may wait to be applied. A
+// burst beyond it blocks the BLE notification handler until the ingestion
+// goroutine catches up; moves are never dropped.
+const ingestQueueSize = 256
+
+// ingestItem is one notification for the ingestion goroutine: the moves of
+// a rotation notification, or a facelet state.
+type ingestItem struct {
+	moves []Move
+	at    time.Time // When the rotation notification arrived
+	state *protocol.StateEvent
+}
+
+// snapshot is the tracked state as of the last applied move. A published
+// snapshot is never modified, so reader methods load it without locking.
+type snapshot struct {
+	cube    *Cube
+	phase   Phase
+	highest Phase
+	moves   []Move // Capped at its length; the writer only appends past it
+}
+
+// startIngest publishes the initial state and starts the ingestion
+// goroutine. BLE notifications only queue work for it, so applying moves,
+// cloning state and running callbacks never hold up the BLE stack, and
+// readers never wait on a burst being applied.
+func (g *GoCube) startIngest() {
+	g.ingest = make(chan ingestItem, ingestQueueSize)
+	g.done = make(chan struct{})
+	g.writeMu.Lock()
+	g.publishLocked()
+	g.writeMu.Unlock()
+	go g.ingestLoop()
+}
+
+// stopIngest stops the ingestion goroutine. Queued notifications are
+// discarded.
+func (g *GoCube) stopIngest() {
+	g.closeOnce.Do(func() { close(g.done) })
+}
+
+// enqueue hands a notification to the ingestion goroutine, waiting while
+// the queue is full. It returns without queueing once the cube is closed.
+func (g *GoCube) enqueue(item ingestItem) {
+	select {
+	case g.ingest <- item:
+	case <-g.done:
+	}
+}
+
+// ingestLoop is the single writer of the tracked state: it applies queued
+// notifications in arrival order until stopIngest.
+func (g *GoCube) ingestLoop() {
+	for {
+		select {
+		case item := <-g.ingest:
+			select {
+			case <-g.done: // Closed, perhaps by the last callback
+				return
+			default:
+			}
+			if item.state != nil {
+				g.applyState(item.state)
+			} else {
+				g.applyRotation(item.moves, item.at)
+			}
+		case <-g.done:
+			return
+		}
+	}
+}
+
+// publishLocked publishes the current state for readers. The caller must
+// hold writeMu.
+func (g *GoCube) publishLocked() {
+	n := len(g.moveHistory)
+	g.snap.Store(&snapshot{
+		cube:    g.cube.Clone(),
+		phase:   g.phase(g.cube),
+		highest: g.highestPhase,
+		moves:   g.moveHistory[:n:n],
+	})
+}
+
+// applyRotation applies the moves of one rotation notification, firing
+// callbacks after each with no lock held.
+func (g *GoCube) applyRotation(moves []Move, at time.Time) {
+	g.writeMu.Lock()
+	TimeBatch(moves, g.lastRotation, g.config.batchSpread)
+	g.lastRotation = at
+	g.writeMu.Unlock()
+
+	for _, move := range moves {
+		g.writeMu.Lock()
+		g.cube.Apply(move)
+		if g.config.moveHistory {
+			g.moveHistory = append(g.moveHistory, move)
+		}
+		g.stats.add(move)
+
+		// Check for phase transitions
+		currentPhase := g.phase(g.cube)
+		isSolved := currentPhase == PhaseSolved
+		phaseChanged := currentPhase.After(g.highestPhase)
+		if phaseChanged {
+			g.highestPhase = currentPhase
+		}
+		g.publishLocked()
+		g.writeMu.Unlock()
+
+		if g.config.timer != nil {
+			g.config.timer.HandleMove(move, isSolved)
+		}
+
+		g.mu.Lock()
+		phaseCallback, solvedCallback := g.onPhaseChange, g.onSolved
+		moveCallback, normalizedCallback := g.onMove, g.onNormalized
+		orientation := g.orientation
+		scramble, scrambleCallback := g.scramble, g.onScramble
+		var progress ScrambleProgress
+		if scramble != nil {
+			progress = scramble.Apply(move)
+			if progress.Complete {
+				g.scramble = nil
+			}
+		}
+		g.mu.Unlock()
+
+		if phaseChanged {
+			g.playLEDPolicy(currentPhase)
+		}
+		if phaseChanged && phaseCallback != nil {
+			phaseCallback(currentPhase)
+		}
+		if isSolved && phaseChanged && solvedCallback != nil {
+			solvedCallback()
+		}
+		if scramble != nil && scrambleCallback != nil {
+			scrambleCallback(progress)
+		}
+		if moveCallback != nil {
+			moveCallback(move)
+		}
+		if normalizedCallback != nil {
+			normalizedCallback(NormalizeMove(move, orientation))
+		}
+	}
+}
+
+// applyState adopts a facelet state after a reconnect or for SyncState.
+// Going through the queue means the moves reported before it have been
+// applied first, and are not applied again on top of it.
+func (g *GoCube) applyState(state *protocol.StateEvent) {
+	g.mu.Lock()
+	adopt := g.resyncState || len(g.stateWaiters) > 0
+	g.resyncState = false // Moves made while disconnected were missed
+	waiters := append([]chan *protocol.StateEvent(nil), g.stateWaiters...)
+	g.mu.Unlock()
+
+	if adopt {
+		g.setCube(cubeFromState(state))
+	}
+	for _, ch := range waiters {
+		select {
+		case ch <- state:
+		default: // Already answered
+		}
+	}
+}
+
+// setCube replaces the tracked cube and restarts the highest phase from it.
+func (g *GoCube) setCube(cube *Cube) {
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
+	g.cube = cube
+	g.highestPhase = g.phase(cube)
+	g.publishLocked()
+}
@@ -0,0 +1,218 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Achievement is a milestone badge.
+type Achievement struct {
+	Key         string
+	Name        string
+	Description string
+}
+
+// Achievements lists every achievement in display order.
+var Achievements = []Achievement{
+	{Key: "sub_minute", Name: "Sub-Minute", Description: "Complete a solve in under a minute"},
+	{Key: "century_week", Name: "Century Week", Description: "Complete 100 solves within 7 days"},
+	{Key: "week_streak", Name: "Week Streak", Description: "Solve on 7 days in a row"},
+	{Key: "pll_coverage", Name: "PLL Collector", Description: "Meet all 21 PLL cases"},
+}
+
+// AchievementStatus is an achievement's state as of the stored solves.
+type AchievementStatus struct {
+	Achievement
+	Met      bool
+	SolveID  string    // Solve that first met it
+	MetAt    time.Time // When that solve ended
+	Progress string    // e.g. "63/100 in the last 7 days"
+}
+
+// achievementSolve is a completed solve as the achievement rules see it.
+type achievementSolve struct {
+	id      string
+	started time.Time
+	ended   time.Time
+	ms      int64
+	pllCase string
+}
+
+// EvaluateAchievements checks every achievement against the completed
+// solves. Days are counted in loc; now anchors the current week and streak.
+func EvaluateAchievements(solveRepo *storage.SolveRepository, pllRepo *storage.PLLCaseRepository, now time.Time, loc *time.Location) ([]AchievementStatus, error) {
+	all, err := solveRepo.List(-1)
+	if err != nil {
+		return nil, err
+	}
+	cases, err := pllRepo.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var solves []achievementSolve
+	for _, s := range all {
+		if s.DurationMs == nil || *s.DurationMs <= 0 {
+			continue
+		}
+		solves = append(solves, achievementSolve{
+			id:      s.SolveID,
+			started: s.StartedAt,
+			ended:   s.StartedAt.Add(time.Duration(*s.DurationMs) * time.Millisecond),
+			ms:      *s.DurationMs,
+			pllCase: cases[s.SolveID],
+		})
+	}
+	sort.Slice(solves, func(i, j int) bool { return solves[i].started.Before(solves[j].started) })
+
+	statuses := make([]AchievementStatus, len(Achievements))
+	for i, a := range Achievements {
+		st := AchievementStatus{Achievement: a}
+		var first *achievementSolve
+		switch a.Key {
+		case "sub_minute":
+			first, st.Progress = subMinute(solves)
+		case "century_week":
+			first, st.Progress = centuryWeek(solves, now)
+		case "week_streak":
+			first, st.Progress = weekStreak(solves, now, loc)
+		case "pll_coverage":
+			first, st.Progress = pllCoverage(solves)
+		}
+		if first != nil {
+			st.Met = true
+			st.SolveID = first.id
+			st.MetAt = first.ended
+		}
+		statuses[i] = st
+	}
+	return statuses, nil
+}
+
+// UpdateAchievements evaluates the achievements after a solve is stored and
+// records any newly met. It returns the achievements earned by this call.
+func UpdateAchievements(solveRepo *storage.SolveRepository, pllRepo *storage.PLLCaseRepository, achievementRepo *storage.AchievementRepository, now time.Time, loc *time.Location) ([]Achievement, error) {
+	statuses, err := EvaluateAchievements(solveRepo, pllRepo, now, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var earned []Achievement
+	for _, st := range statuses {
+		if !st.Met {
+			continue
+		}
+		isNew, err := achievementRepo.Earn(st.Key, st.SolveID, st.MetAt)
+		if err != nil {
+			return nil, err
+		}
+		if isNew {
+			earned = append(earned, st.Achievement)
+		}
+	}
+	return earned, nil
+}
+
+// subMinute returns the first solve under a minute; progress is the best time.
+func subMinute(solves []achievementSolve) (*achievementSolve, string) {
+	var best int64
+	for i := range solves {
+		if solves[i].ms < 60000 {
+			return &solves[i], ""
+		}
+		if best == 0 || solves[i].ms < best {
+			best = solves[i].ms
+		}
+	}
+	if best == 0 {
+		return nil, "no completed solves yet"
+	}
+	return nil, fmt.Sprintf("best %.2fs", float64(best)/1000)
+}
+
+// centuryWeek returns the 100th solve of the first 7-day window holding 100
+// solves; progress is the count over the 7 days up to now.
+func centuryWeek(solves []achievementSolve, now time.Time) (*achievementSolve, string) {
+	const target, window = 100, 7 * 24 * time.Hour
+
+	start := 0
+	for end := range solves {
+		for solves[end].started.Sub(solves[start].started) >= window {
+			start++
+		}
+		if end-start+1 >= target {
+			return &solves[end], ""
+		}
+	}
+
+	recent := 0
+	for _, s := range solves {
+		if now.Sub(s.started) < window {
+			recent++
+		}
+	}
+	return nil, fmt.Sprintf("%d/%d in the last 7 days", recent, target)
+}
+
+// weekStreak returns the first solve on the seventh day of the first run of
+// 7 consecutive days with solves; progress is the current run.
+func weekStreak(solves []achievementSolve, now time.Time, loc *time.Location) (*achievementSolve, string) {
+	const target = 7
+
+	day := func(t time.Time) time.Time {
+		y, m, d := t.In(loc).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	run := 0
+	var last time.Time
+	for i := range solves {
+		d := day(solves[i].started)
+		switch {
+		case d.Equal(last):
+			continue
+		case !last.IsZero() && d.Equal(last.AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		last = d
+		if run >= target {
+			return &solves[i], ""
+		}
+	}
+
+	// The current streak is alive until a full day passes without a solve
+	today := day(now)
+	if last.IsZero() || last.Before(today.AddDate(0, 0, -1)) {
+		run = 0
+	}
+	return nil, fmt.Sprintf("current streak %d/%d days", run, target)
+}
+
+// pllCoverage returns the solve that met the last of the 21 PLL cases;
+// progress lists the cases still missing.
+func pllCoverage(solves []achievementSolve) (*achievementSolve, string) {
+	seen := make(map[string]bool)
+	for i := range solves {
+		if solves[i].pllCase == "" || seen[solves[i].pllCase] {
+			continue
+		}
+		seen[solves[i].pllCase] = true
+		if len(seen) == len(pllAlgorithms) {
+			return &solves[i], ""
+		}
+	}
+
+	var missing []string
+	for _, name := range PLLCases() {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	return nil, fmt.Sprintf("%d/%d cases (missing %s)", len(seen), len(pllAlgorithms), strings.Join(missing, ", "))
+}
@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/notation"
+)
+
+// Algorithm categories used in annotated reconstructions.
+const (
+	CategoryF2L  = "F2L"
+	CategoryOLL  = "OLL"
+	CategoryPLL  = "PLL"
+	CategoryTool = "Tool" // Final phase tools, including the custom library
+	CategoryAUF  = "AUF"  // Adjustment of the last layer around an OLL or PLL
+)
+
+// ollAlgorithms are standard algorithms for the 57 OLL cases, written for
+// the last layer on U.
+var ollAlgorithms = []struct{ name, moves string }{
+	{"OLL 1", "R U2 R2 F R F' U2 R' F R F'"},
+	{"OLL 2", "F R U R' U' F' f R U R' U' f'"},
+	{"OLL 3", "f R U R' U' f' U' F R U R' U' F'"},
+	{"OLL 4", "f R U R' U' f' U F R U R' U' F'"},
+	{"OLL 5", "r' U2 R U R' U r"},
+	{"OLL 6", "r U2 R' U' R U' r'"},
+	{"OLL 7", "r U R' U R U2 r'"},
+	{"OLL 8", "l' U' L U' L' U2 l"},
+	{"OLL 9", "R U R' U' R' F R2 U R' U' F'"},
+	{"OLL 10", "R U R' U R' F R F' R U2 R'"},
+	{"OLL 11", "r U R' U R' F R F' R U2 r'"},
+	{"OLL 12", "M' R' U' R U' R' U2 R U' R r'"},
+	{"OLL 13", "F U R U' R2 F' R U R U' R'"},
+	{"OLL 14", "R' F R U R' F' R F U' F'"},
+	{"OLL 15", "l' U' l L' U' L U l' U l"},
+	{"OLL 16", "r U r' R U R' U' r U' r'"},
+	{"OLL 17", "R U R' U R' F R F' U2 R' F R F'"},
+	{"OLL 18", "r U R' U R U2 r2 U' R U' R' U2 r"},
+	{"OLL 19", "r' R U R U R' U' M' R' F R F'"},
+	{"OLL 20", "r U R' U' M2 U R U' R' U' M'"},
+	{"OLL 21", "R U2 R' U' R U R' U' R U' R'"},
+	{"OLL 22", "R U2 R2 U' R2 U' R2 U2 R"},
+	{"OLL 23", "R2 D R' U2 R D' R' U2 R'"},
+	{"OLL 24", "r U R' U' r' F R F'"},
+	{"OLL 25", "F' r U R' U' r' F R"},
+	{"OLL 26", "R U2 R' U' R U' R'"},
+	{"OLL 27", "R U R' U R U2 R'"},
+	{"OLL 28", "r U R' U' M U R U' R'"},
+	{"OLL 29", "R U R' U' R U' R' F' U' F R U R'"},
+	{"OLL 30", "F R' F R2 U' R' U' R U R' F2"},
+	{"OLL 31", "R' U' F U R U' R' F' R"},
+	{"OLL 32", "R U B' U' R' U R B R'"},
+	{"OLL 33", "R U R' U' R' F R F'"},
+	{"OLL 34", "R U R2 U' R' F R U R U' F'"},
+	{"OLL 35", "R U2 R2 F R F' R U2 R'"},
+	{"OLL 36", "L' U' L U' L' U L U L F' L' F"},
+	{"OLL 37", "F R' F' R U R U' R'"},
+	{"OLL 38", "R U R' U R U' R' U' R' F R F'"},
+	{"OLL 39", "L F' L' U' L U F U' L'"},
+	{"OLL 40", "R' F R U R' U' F' U R"},
+	{"OLL 41", "R U R' U R U2 R' F R U R' U' F'"},
+	{"OLL 42", "R' U' R U' R' U2 R F R U R' U' F'"},
+	{"OLL 43", "f' L' U' L U f"},
+	{"OLL 44", "f R U R' U' f'"},
+	{"OLL 45", "F R U R' U' F'"},
+	{"OLL 46", "R' U' R' F R F' U R"},
+	{"OLL 47", "R' U' R' F R F' R' F R F' U R"},
+	{"OLL 48", "F R U R' U' R U R' U' F'"},
+	{"OLL 49", "r U' r2 U r2 U r2 U' r"},
+	{"OLL 50", "r' U r2 U' r2 U' r2 U r'"},
+	{"OLL 51", "f R U R' U' R U R' U' f'"},
+	{"OLL 52", "R U R' U R U' B U' B' R'"},
+	{"OLL 53", "l' U2 L U L' U' L U L' U l"},
+	{"OLL 54", "r U2 R' U' R U R' U' R U' r'"},
+	{"OLL 55", "R' F R U R U' R2 F' R2 U' R' U R U R'"},
+	{"OLL 56", "r' U' r U' R' U R U' R' U R r' U r"},
+	{"OLL 57", "R U R' U' M' U R U' r'"},
+}
+
+// f2lAlgorithms are common ways of pairing and inserting an F2L corner and
+// edge into the front right slot, with the cross on D. Three and four move
+// inserts are left out: they are too short to tell apart from other moves.
+var f2lAlgorithms = []string{
+	"R U2 R' U' R U R'",
+	"U R U2 R' U R U' R'",
+	"U' R U' R' U R U R'",
+	"U' R U R' U2 R U' R'",
+	"U' R U2 R' U2 R U' R'",
+	"U' R U R' U R U R'",
+	"R U' R' U R U' R' U2 R U' R'",
+	"R U R' U2 R U R' U' R U R'",
+	"R U R' U' R U R' U' R U R'",
+	"R U' R' U' R U R' U2 R U' R'",
+	"R U' R' U2 F' U' F",
+	"U F' U' F U' F' U' F",
+	"U F' U2 F U' F' U F",
+	"U' F' U F U' F' U' F",
+	"F' U' F U2 F' U' F U F' U' F",
+}
+
+// knownAlgorithm is one orientation of a database algorithm, as the face
+// turns a smart cube reports for it, with same-face turns merged.
+type knownAlgorithm struct {
+	name     string
+	category string
+	auf      gocube.Face // Face of the last layer, for AUF labels
+	moves    []gocube.Move
+}
+
+// algorithmViews are the orientations algorithms are matched in: any y
+// rotation, with the last layer on U or, as the solver holds the cube,
+// turned over onto D.
+var algorithmViews = []string{"", "y ", "y2 ", "y' ", "z2 ", "z2 y ", "z2 y2 ", "z2 y' "}
+
+var (
+	algDBOnce sync.Once
+	algDB     []knownAlgorithm
+)
+
+// buildAlgorithmDB expands the PLL, OLL and F2L algorithms into every view.
+// Views that give the same turns, such as those of H-perm, are kept once.
+func buildAlgorithmDB() {
+	type source struct{ name, category, moves string }
+	var sources []source
+	for _, a := range pllAlgorithms {
+		sources = append(sources, source{a.name + "-perm", CategoryPLL, a.moves})
+	}
+	for _, a := range ollAlgorithms {
+		sources = append(sources, source{a.name, CategoryOLL, a.moves})
+	}
+	for _, moves := range f2lAlgorithms {
+		sources = append(sources, source{"F2L", CategoryF2L, moves})
+	}
+
+	seen := make(map[string]bool)
+	for _, s := range sources {
+		for _, view := range algorithmViews {
+			parsed, err := gocube.ParseMoves(view + s.moves)
+			if err != nil {
+				panic("analysis: invalid algorithm " + s.name)
+			}
+			moves := mergeTurns(gocube.ExpandMoves(parsed))
+			key := s.name + ":" + movesKey(moves)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			auf := gocube.FaceU
+			if strings.HasPrefix(view, "z2") {
+				auf = gocube.FaceD
+			}
+			algDB = append(algDB, knownAlgorithm{name: s.name, category: s.category, auf: auf, moves: moves})
+		}
+	}
+
+	// Longest first, so a PLL is not read as the trigger it starts with.
+	sort.SliceStable(algDB, func(i, j int) bool {
+		return len(algDB[i].moves) > len(algDB[j].moves)
+	})
+}
+
+// mergeTurns merges consecutive turns of the same face into their net turn,
+// as a reconstruction writes them: U U becomes U2. Turns that cancel are
+// kept apart.
+func mergeTurns(moves []gocube.Move) []gocube.Move {
+	display := notation.DisplayMoves(moves, true)
+	merged := make([]gocube.Move, len(display))
+	for i, d := range display {
+		merged[i] = d.Move
+	}
+	return merged
+}
+
+// movesKey returns the notation of moves, e.g. "R U R'".
+func movesKey(moves []gocube.Move) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = m.Notation()
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// AlgorithmMatch names the known algorithm a move sequence is, or is part
+// of.
+type AlgorithmMatch struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Partial  bool   `json:"partial,omitempty"` // The sequence is only part of the algorithm
+}
+
+// IdentifySequence returns the database algorithm or final phase tool that
+// moves are, in any of the orientations reconstructions recognize, or else
+// the shortest one they are a contiguous part of.
+func IdentifySequence(moves []gocube.Move) (AlgorithmMatch, bool) {
+	algDBOnce.Do(buildAlgorithmDB)
+	merged := mergeTurns(moves)
+	if len(merged) == 0 {
+		return AlgorithmMatch{}, false
+	}
+
+	candidates := make([]knownAlgorithm, 0, len(algDB))
+	for _, t := range Tools() {
+		if len(t.Sequence) > 0 {
+			candidates = append(candidates, knownAlgorithm{name: t.Name, category: CategoryTool, moves: mergeTurns(t.Sequence)})
+		}
+	}
+	candidates = append(candidates, algDB...)
+
+	var best knownAlgorithm
+	for _, a := range candidates {
+		if len(a.moves) == len(merged) && matchesTool(a.moves, 0, merged) {
+			return AlgorithmMatch{Name: a.name, Category: a.category}, true
+		}
+		if best.moves != nil && len(a.moves) >= len(best.moves) {
+			continue
+		}
+		for i := 0; i+len(merged) <= len(a.moves); i++ {
+			if matchesTool(a.moves, i, merged) {
+				best = a
+				break
+			}
+		}
+	}
+	if best.moves == nil {
+		return AlgorithmMatch{}, false
+	}
+	return AlgorithmMatch{Name: best.name, Category: best.category, Partial: true}, true
+}
+
+// IdentifyNGrams sets Algorithm on each n-gram of report that is, or is
+// part of, a known algorithm.
+func IdentifyNGrams(report *NGramReport) {
+	for _, ngrams := range report.TopNGrams {
+		for i := range ngrams {
+			moves := make([]gocube.Move, len(ngrams[i].Tokens))
+			for j, t := range ngrams[i].Tokens {
+				moves[j] = moveFromToken(t)
+			}
+			if match, ok := IdentifySequence(moves); ok {
+				ngrams[i].Algorithm = &match
+			}
+		}
+	}
+}
+
+// AlgorithmUsage is how often one known algorithm was used.
+type AlgorithmUsage struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	Solves   int    `json:"solves"` // Solves it was used in
+}
+
+// AlgorithmFingerprint is a solver's personal algorithm profile: the known
+// algorithms they use, most used first, and how much of their solving they
+// account for.
+type AlgorithmFingerprint struct {
+	Solves          int              `json:"solves"`
+	Moves           int              `json:"moves"`            // Stored solving moves
+	RecognizedMoves int              `json:"recognized_moves"` // Of them, in known algorithms
+	Categories      map[string]int   `json:"categories"`       // Uses per category
+	Algorithms      []AlgorithmUsage `json:"algorithms"`
+}
+
+// AlgorithmTally accumulates an AlgorithmFingerprint one solve at a time.
+type AlgorithmTally struct {
+	fp     AlgorithmFingerprint
+	usage  map[string]*AlgorithmUsage
+	solves map[string]int // Last solve each algorithm was counted in
+}
+
+// NewAlgorithmTally creates an empty tally.
+func NewAlgorithmTally() *AlgorithmTally {
+	return &AlgorithmTally{
+		fp:     AlgorithmFingerprint{Categories: make(map[string]int)},
+		usage:  make(map[string]*AlgorithmUsage),
+		solves: make(map[string]int),
+	}
+}
+
+// Add counts the labeled algorithms of one solve's reconstruction. AUFs
+// are not counted.
+func (t *AlgorithmTally) Add(r *Reconstruction) {
+	t.fp.Solves++
+	t.fp.Moves += r.TotalMoves
+	t.fp.RecognizedMoves += r.RecognizedMoves
+	for _, line := range r.Lines {
+		if line.Label == "" || line.Category == CategoryAUF {
+			continue
+		}
+		u, ok := t.usage[line.Label]
+		if !ok {
+			u = &AlgorithmUsage{Name: line.Label, Category: line.Category}
+			t.usage[line.Label] = u
+		}
+		u.Count++
+		if t.solves[line.Label] != t.fp.Solves {
+			t.solves[line.Label] = t.fp.Solves
+			u.Solves++
+		}
+		t.fp.Categories[line.Category]++
+	}
+}
+
+// Fingerprint returns the fingerprint of the solves added so far.
+func (t *AlgorithmTally) Fingerprint() AlgorithmFingerprint {
+	fp := t.fp
+	fp.Categories = make(map[string]int, len(t.fp.Categories))
+	for k, v := range t.fp.Categories {
+		fp.Categories[k] = v
+	}
+	fp.Algorithms = make([]AlgorithmUsage, 0, len(t.usage))
+	for _, u := range t.usage {
+		fp.Algorithms = append(fp.Algorithms, *u)
+	}
+	sort.Slice(fp.Algorithms, func(i, j int) bool {
+		a, b := fp.Algorithms[i], fp.Algorithms[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+	return fp
+}
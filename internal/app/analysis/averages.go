@@ -0,0 +1,75 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// DNF marks a solve without a time in the times given to AverageOf.
+const DNF int64 = -1
+
+// AverageSizes are the rolling averages sessions report.
+var AverageSizes = []int{5, 12, 100}
+
+// Average is a WCA-style average of n solves.
+type Average struct {
+	N   int     `json:"n"`
+	Ms  float64 `json:"ms,omitempty"`  // Unset for a DNF average
+	DNF bool    `json:"dnf,omitempty"` // More DNFs than are trimmed
+}
+
+// averageTrim returns how many times an average of n drops from each end:
+// 5% of n rounded up, so one for ao5 and ao12 and five for ao100.
+func averageTrim(n int) int {
+	return int(math.Ceil(float64(n) * 0.05))
+}
+
+// AverageOf returns the average of the last n of times (in ms, oldest
+// first) under WCA rules: the fastest and slowest 5% (at least one each)
+// are dropped and the rest averaged. A DNF counts as the slowest time, so
+// an average with more DNFs than are dropped is a DNF. It returns false
+// with fewer than n times.
+func AverageOf(times []int64, n int) (Average, bool) {
+	if n < 3 || len(times) < n {
+		return Average{}, false
+	}
+
+	window := make([]int64, n)
+	copy(window, times[len(times)-n:])
+	sort.Slice(window, func(i, j int) bool {
+		// DNFs sort last
+		if (window[i] == DNF) != (window[j] == DNF) {
+			return window[j] == DNF
+		}
+		return window[i] < window[j]
+	})
+
+	trim := averageTrim(n)
+	kept := window[trim : n-trim]
+	var sum int64
+	for _, t := range kept {
+		if t == DNF {
+			return Average{N: n, DNF: true}, true
+		}
+		sum += t
+	}
+	return Average{N: n, Ms: float64(sum) / float64(len(kept))}, true
+}
+
+// BestAverageOf returns the best average of n consecutive times, or false
+// with fewer than n times. A DNF average is only best when every average is
+// a DNF.
+func BestAverageOf(times []int64, n int) (Average, bool) {
+	var best Average
+	found := false
+	for end := n; end <= len(times); end++ {
+		avg, ok := AverageOf(times[:end], n)
+		if !ok {
+			continue
+		}
+		if !found || (best.DNF && !avg.DNF) || (!avg.DNF && avg.Ms < best.Ms) {
+			best, found = avg, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,175 @@
+package analysis
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+//go:embed reference/benchmarks.json
+var benchmarksJSON []byte
+
+// BenchmarkData is a bundled set of static reference distributions.
+type BenchmarkData struct {
+	Version  int                `json:"version"`
+	Source   string             `json:"source"`
+	Note     string             `json:"note"`
+	Brackets []BenchmarkBracket `json:"brackets"`
+}
+
+// BenchmarkBracket holds phase split distributions for solvers whose
+// overall average is below MaxAvgMs (0 = no upper bound).
+type BenchmarkBracket struct {
+	Key      string                       `json:"key"`
+	Label    string                       `json:"label"`
+	MaxAvgMs int64                        `json:"max_avg_ms,omitempty"`
+	Phases   map[string]PhaseDistribution `json:"phases"`
+}
+
+// PhaseDistribution is a phase duration distribution in milliseconds.
+type PhaseDistribution struct {
+	P10 int64 `json:"p10"`
+	P25 int64 `json:"p25"`
+	P50 int64 `json:"p50"`
+	P75 int64 `json:"p75"`
+	P90 int64 `json:"p90"`
+}
+
+// Percentile returns the percentage of reference solvers slower than
+// durationMs, in the range 1-99. Faster durations give higher percentiles.
+func (d PhaseDistribution) Percentile(durationMs int64) float64 {
+	// Duration quantile points paired with the resulting percentile.
+	points := []struct {
+		ms  float64
+		pct float64
+	}{
+		{0, 100},
+		{float64(d.P10), 90},
+		{float64(d.P25), 75},
+		{float64(d.P50), 50},
+		{float64(d.P75), 25},
+		{float64(d.P90), 10},
+		{float64(d.P90) * 2, 0},
+	}
+
+	x := float64(durationMs)
+	pct := 0.0
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]
+		if x <= hi.ms {
+			if hi.ms > lo.ms {
+				pct = lo.pct + (x-lo.ms)/(hi.ms-lo.ms)*(hi.pct-lo.pct)
+			} else {
+				pct = hi.pct
+			}
+			break
+		}
+	}
+
+	if pct < 1 {
+		pct = 1
+	}
+	if pct > 99 {
+		pct = 99
+	}
+	return pct
+}
+
+// PhaseBenchmark compares one phase against the reference distribution.
+type PhaseBenchmark struct {
+	PhaseKey   string  `json:"phase_key"`
+	DurationMs int64   `json:"duration_ms"`
+	MedianMs   int64   `json:"reference_median_ms"`
+	Percentile float64 `json:"percentile"`
+	Summary    string  `json:"summary"`
+}
+
+// BenchmarkReport places phase splits within a static reference bracket.
+type BenchmarkReport struct {
+	Source       string           `json:"source"`
+	Note         string           `json:"note"`
+	Bracket      string           `json:"bracket"`
+	BracketLabel string           `json:"bracket_label"`
+	AvgMs        int64            `json:"avg_ms"` // Overall average used to pick the bracket
+	Phases       []PhaseBenchmark `json:"phases"`
+}
+
+// LoadBenchmarks parses the bundled reference data.
+func LoadBenchmarks() (*BenchmarkData, error) {
+	var data BenchmarkData
+	if err := json.Unmarshal(benchmarksJSON, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse reference benchmarks: %w", err)
+	}
+	return &data, nil
+}
+
+// Bracket returns the reference bracket for an overall average.
+func (b *BenchmarkData) Bracket(avgMs int64) (BenchmarkBracket, bool) {
+	for _, br := range b.Brackets {
+		if br.MaxAvgMs == 0 || avgMs < br.MaxAvgMs {
+			return br, true
+		}
+	}
+	return BenchmarkBracket{}, false
+}
+
+// CompareToBenchmarks ranks phase durations against the bracket matching
+// avgMs. Phases without reference data are skipped.
+func CompareToBenchmarks(avgMs int64, phaseDurations map[string]int64) (*BenchmarkReport, error) {
+	data, err := LoadBenchmarks()
+	if err != nil {
+		return nil, err
+	}
+
+	bracket, ok := data.Bracket(avgMs)
+	if !ok {
+		return nil, fmt.Errorf("no reference bracket for %dms average", avgMs)
+	}
+
+	report := &BenchmarkReport{
+		Source:       data.Source,
+		Note:         data.Note,
+		Bracket:      bracket.Key,
+		BracketLabel: bracket.Label,
+		AvgMs:        avgMs,
+		Phases:       []PhaseBenchmark{},
+	}
+
+	for phaseKey, durationMs := range phaseDurations {
+		dist, ok := bracket.Phases[phaseKey]
+		if !ok || durationMs <= 0 {
+			continue
+		}
+		pct := dist.Percentile(durationMs)
+		report.Phases = append(report.Phases, PhaseBenchmark{
+			PhaseKey:   phaseKey,
+			DurationMs: durationMs,
+			MedianMs:   dist.P50,
+			Percentile: pct,
+			Summary:    fmt.Sprintf("%s is %s percentile for %s solvers", phaseKey, ordinal(int(pct+0.5)), bracket.Label),
+		})
+	}
+
+	sort.Slice(report.Phases, func(i, j int) bool {
+		return report.Phases[i].PhaseKey < report.Phases[j].PhaseKey
+	})
+
+	return report, nil
+}
+
+// ordinal formats n as "1st", "2nd", "68th", etc.
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,226 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// Significance hints for a metric difference between two periods.
+const (
+	SignificanceLikely   = "likely"   // Welch |t| >= 2
+	SignificancePossible = "possible" // Welch |t| >= 1
+	SignificanceNoise    = "noise"    // Within normal variation
+	SignificanceTooFew   = "too_few"  // Fewer than minCompareSamples in a period
+	SignificanceNone     = ""         // Aggregate metric; no per-solve samples
+)
+
+// minCompareSamples is the fewest solves per period for a significance hint.
+const minCompareSamples = 5
+
+// MetricDiff compares one metric between a baseline and a current period.
+type MetricDiff struct {
+	Metric       string  `json:"metric"`
+	Baseline     float64 `json:"baseline"`
+	Current      float64 `json:"current"`
+	BaselineN    int     `json:"baseline_n"` // Solves contributing; 0 = no value
+	CurrentN     int     `json:"current_n"`
+	Delta        float64 `json:"delta"`
+	DeltaPct     float64 `json:"delta_pct"`
+	Improved     bool    `json:"improved"`
+	LowerBetter  bool    `json:"lower_better"`
+	Significance string  `json:"significance,omitempty"`
+}
+
+// TrendComparison is a structured diff of trend metrics between two periods.
+type TrendComparison struct {
+	BaselineLabel  string       `json:"baseline_label"`
+	CurrentLabel   string       `json:"current_label"`
+	BaselineSolves int          `json:"baseline_solves"`
+	CurrentSolves  int          `json:"current_solves"`
+	Metrics        []MetricDiff `json:"metrics"`
+	Phases         []MetricDiff `json:"phases"`
+}
+
+// CompareTrends diffs trend metrics between a baseline and a current set of
+// solves. Averages, pauses and phase splits carry a significance hint from
+// Welch's t-test on the per-solve values; aggregates (best, ao5, ao12,
+// consistency) do not.
+func CompareTrends(baselineLabel string, baseline []SolveData, currentLabel string, current []SolveData) *TrendComparison {
+	base := completedOnly(baseline)
+	cur := completedOnly(current)
+
+	cmp := &TrendComparison{
+		BaselineLabel:  baselineLabel,
+		CurrentLabel:   currentLabel,
+		BaselineSolves: len(base),
+		CurrentSolves:  len(cur),
+	}
+
+	baseReport := AnalyzeTrends(base)
+	curReport := AnalyzeTrends(cur)
+
+	duration := func(s SolveData) (float64, bool) { return float64(s.DurationMs), true }
+	moves := func(s SolveData) (float64, bool) { return float64(s.MoveCount), !s.Manual }
+	tps := func(s SolveData) (float64, bool) { return s.TPS, !s.Manual }
+	effort := func(s SolveData) (float64, bool) {
+		if s.Effort == nil {
+			return 0, false
+		}
+		return s.Effort.Score, true
+	}
+	pauses := func(s SolveData) (float64, bool) { return float64(s.PauseCount), !s.Manual }
+	longestPause := func(s SolveData) (float64, bool) { return float64(s.LongestPauseMs), !s.Manual }
+
+	cmp.Metrics = []MetricDiff{
+		sampleDiff("avg_duration_ms", base, cur, duration, true),
+		aggregateDiff("best_ms", float64(baseReport.BestSolve.DurationMs), len(base), float64(curReport.BestSolve.DurationMs), len(cur), true),
+		lastMeanDiff("ao5_ms", base, cur, 5),
+		lastMeanDiff("ao12_ms", base, cur, 12),
+		aggregateDiff("consistency", baseReport.ConsistencyScore, len(base), curReport.ConsistencyScore, len(cur), false),
+		sampleDiff("avg_moves", base, cur, moves, true),
+		sampleDiff("avg_tps", base, cur, tps, false),
+		sampleDiff("avg_effort", base, cur, effort, true),
+		sampleDiff("pauses_over_1500ms", base, cur, pauses, true),
+		sampleDiff("longest_pause_ms", base, cur, longestPause, true),
+	}
+
+	// Phase splits present in either period
+	phaseKeys := make(map[string]bool)
+	for _, s := range append(append([]SolveData{}, base...), cur...) {
+		for key := range s.PhaseData {
+			phaseKeys[key] = true
+		}
+	}
+	keys := make([]string, 0, len(phaseKeys))
+	for key := range phaseKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		key := key
+		phase := func(s SolveData) (float64, bool) {
+			d, ok := s.PhaseData[key]
+			return float64(d.DurationMs), ok
+		}
+		cmp.Phases = append(cmp.Phases, sampleDiff(key, base, cur, phase, true))
+	}
+
+	return cmp
+}
+
+// completedOnly returns solves with a positive duration, oldest first.
+func completedOnly(solves []SolveData) []SolveData {
+	var out []SolveData
+	for _, s := range solves {
+		if s.DurationMs > 0 {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].StartedAt.Before(out[j].StartedAt)
+	})
+	return out
+}
+
+// lastMeanDiff compares the mean duration of the last n solves in each
+// period. A period with fewer than n solves has no value.
+func lastMeanDiff(metric string, base, cur []SolveData, n int) MetricDiff {
+	lastMean := func(solves []SolveData) (float64, int) {
+		if len(solves) < n {
+			return 0, 0
+		}
+		var sum int64
+		for _, s := range solves[len(solves)-n:] {
+			sum += s.DurationMs
+		}
+		return float64(sum) / float64(n), n
+	}
+	baseMean, baseN := lastMean(base)
+	curMean, curN := lastMean(cur)
+	return aggregateDiff(metric, baseMean, baseN, curMean, curN, true)
+}
+
+// sampleDiff compares the mean of a per-solve value, with a significance hint.
+func sampleDiff(metric string, base, cur []SolveData, value func(SolveData) (float64, bool), lowerBetter bool) MetricDiff {
+	collect := func(solves []SolveData) []float64 {
+		var out []float64
+		for _, s := range solves {
+			if v, ok := value(s); ok {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	a, b := collect(base), collect(cur)
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+
+	diff := aggregateDiff(metric, meanA, len(a), meanB, len(b), lowerBetter)
+	switch {
+	case len(a) < minCompareSamples || len(b) < minCompareSamples:
+		diff.Significance = SignificanceTooFew
+	default:
+		se := math.Sqrt(varA/float64(len(a)) + varB/float64(len(b)))
+		t := 0.0
+		if se > 0 {
+			t = math.Abs(meanB-meanA) / se
+		}
+		switch {
+		case t >= 2:
+			diff.Significance = SignificanceLikely
+		case t >= 1:
+			diff.Significance = SignificancePossible
+		default:
+			diff.Significance = SignificanceNoise
+		}
+	}
+	return diff
+}
+
+// aggregateDiff compares two precomputed values from baselineN and currentN
+// solves. The delta is only set when both periods have a value.
+func aggregateDiff(metric string, baseline float64, baselineN int, current float64, currentN int, lowerBetter bool) MetricDiff {
+	d := MetricDiff{
+		Metric:      metric,
+		Baseline:    baseline,
+		Current:     current,
+		BaselineN:   baselineN,
+		CurrentN:    currentN,
+		LowerBetter: lowerBetter,
+	}
+	if baselineN == 0 || currentN == 0 {
+		return d
+	}
+	d.Delta = current - baseline
+	if baseline != 0 {
+		d.DeltaPct = d.Delta / baseline * 100
+	}
+	if d.Delta != 0 {
+		if lowerBetter {
+			d.Improved = current < baseline
+		} else {
+			d.Improved = current > baseline
+		}
+	}
+	return d
+}
+
+// meanVariance returns the mean and sample variance of values.
+func meanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, sq / float64(len(values)-1)
+}
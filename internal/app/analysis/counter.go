@@ -0,0 +1,83 @@
+package analysis
+
+import (
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// counterDropTolerance is how many counted-but-unrecorded moves are
+// accepted before a solve is flagged. The end reading is requested after the
+// solve ends, so a turn or two made while it is in flight is expected.
+const counterDropTolerance = 2
+
+// MoveCounterDiagnostics reconciles the cube's own cumulative move counter
+// against the moves recorded locally. Experimental: the counter semantics
+// are inferred from offline stats messages and not documented by the vendor.
+type MoveCounterDiagnostics struct {
+	DeviceID      string `json:"device_id"`
+	CounterStart  int    `json:"counter_start"`
+	CounterEnd    int    `json:"counter_end"`
+	CounterDelta  int    `json:"counter_delta"`
+	RecordedMoves int    `json:"recorded_moves"`
+	MissingMoves  int    `json:"missing_moves"` // counted by the cube but not received
+
+	SuspectedDrops bool `json:"suspected_drops"`
+	CounterReset   bool `json:"counter_reset"` // counter went backwards (battery pull, firmware reset)
+
+	// Moves the cube counted since the previous solve's end reading on the
+	// same device, i.e. turns made while not recording.
+	MovesSincePrevious *int `json:"moves_since_previous,omitempty"`
+}
+
+// ReconcileMoveCounter compares the counter readings taken at the start and
+// end of a solve with its recorded moves. Returns nil if the solve does not
+// have both readings.
+func ReconcileMoveCounter(solveID string, counterRepo *storage.CounterRepository, moveRepo *storage.MoveRepository) (*MoveCounterDiagnostics, error) {
+	readings, err := counterRepo.GetBySolve(solveID)
+	if err != nil {
+		return nil, err
+	}
+	if len(readings) < 2 {
+		return nil, nil
+	}
+
+	recorded, err := moveRepo.Count(solveID)
+	if err != nil {
+		return nil, err
+	}
+
+	first := readings[0]
+	last := readings[len(readings)-1]
+
+	diag := &MoveCounterDiagnostics{
+		DeviceID:      first.DeviceID,
+		CounterStart:  first.Moves,
+		CounterEnd:    last.Moves,
+		CounterDelta:  last.Moves - first.Moves,
+		RecordedMoves: recorded,
+	}
+
+	if diag.CounterDelta < 0 {
+		diag.CounterReset = true
+	} else {
+		diag.MissingMoves = diag.CounterDelta - recorded
+		diag.SuspectedDrops = diag.MissingMoves > counterDropTolerance
+	}
+
+	// Track the counter across sessions on the same device
+	history, err := counterRepo.GetByDevice(first.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		h := history[i]
+		if h.ReadingID >= first.ReadingID || h.SolveID == nil || *h.SolveID == solveID {
+			continue
+		}
+		if since := first.Moves - h.Moves; since >= 0 {
+			diag.MovesSincePrevious = &since
+		}
+		break
+	}
+
+	return diag, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/SeamusWaldron/gocube_ble_library"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 )
 
@@ -19,6 +20,8 @@ type PhaseDiagnostics struct {
 	ImmediateReversals    int     `json:"immediate_reversals"`     // X X' patterns
 	ReversalRate          float64 `json:"reversal_rate"`           // reversals / moves
 	FullCycleWaste        int     `json:"full_cycle_waste"`        // X X X X patterns
+	SimultaneousMoves     int     `json:"simultaneous_moves"`      // Moves reported together in one notification
+	InterpolatedMoves     int     `json:"interpolated_moves"`      // Moves whose time was spread, not measured
 
 	// Base layer (D) metrics
 	BaseTurns      int     `json:"base_turns"`       // D and D' moves
@@ -64,6 +67,8 @@ type SolveDiagnostics struct {
 	Phases      []PhaseDiagnostics     `json:"phases"`
 	Overall     PhaseDiagnostics       `json:"overall"`
 	Orientation OrientationDiagnostics `json:"orientation"`
+
+	MoveCounter *MoveCounterDiagnostics `json:"move_counter,omitempty"`
 }
 
 // AnalyzeDiagnostics generates diagnostic metrics for a solve.
@@ -74,22 +79,37 @@ func AnalyzeDiagnostics(solveID string, moveRepo *storage.MoveRepository, phaseR
 		return nil, err
 	}
 
-	result := &SolveDiagnostics{
-		SolveID: solveID,
-		Phases:  make([]PhaseDiagnostics, 0, len(segments)),
-	}
-
 	// Get all moves for overall stats
 	allMoves, err := moveRepo.GetBySolve(solveID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Analyze each phase
+	// Analyze orientation if repository is provided
+	var orientations []storage.OrientationRecord
+	if orientRepo != nil {
+		orientations, _ = orientRepo.GetBySolve(solveID)
+	}
+
+	return AnalyzeDiagnosticsData(solveID, segments, allMoves, orientations), nil
+}
+
+// AnalyzeDiagnosticsData generates diagnostic metrics from already loaded
+// solve data. It needs no database, so it also runs in the WebAssembly
+// build against exported playback data.
+func AnalyzeDiagnosticsData(solveID string, segments []storage.PhaseSegment, allMoves []storage.MoveRecord, orientations []storage.OrientationRecord) *SolveDiagnostics {
+	result := &SolveDiagnostics{
+		SolveID: solveID,
+		Phases:  make([]PhaseDiagnostics, 0, len(segments)),
+	}
+
+	// Analyze each phase (inclusive start, exclusive end, as GetBySolveRange)
 	for _, seg := range segments {
-		moves, err := moveRepo.GetBySolveRange(solveID, seg.StartTsMs, seg.EndTsMs)
-		if err != nil {
-			continue
+		var moves []storage.MoveRecord
+		for _, m := range allMoves {
+			if m.TsMs >= seg.StartTsMs && m.TsMs < seg.EndTsMs {
+				moves = append(moves, m)
+			}
 		}
 
 		diag := analyzePhaseMoves(moves, seg)
@@ -112,15 +132,11 @@ func AnalyzeDiagnostics(solveID string, moveRepo *storage.MoveRepository, phaseR
 	result.Overall = analyzePhaseMoves(allMoves, overallSeg)
 	result.Overall.DisplayName = "Overall"
 
-	// Analyze orientation if repository is provided
-	if orientRepo != nil {
-		orientations, err := orientRepo.GetBySolve(solveID)
-		if err == nil && len(orientations) > 0 {
-			result.Orientation = analyzeOrientations(orientations, allMoves, overallSeg.DurationMs)
-		}
+	if len(orientations) > 0 {
+		result.Orientation = analyzeOrientations(orientations, allMoves, overallSeg.DurationMs)
 	}
 
-	return result, nil
+	return result
 }
 
 func analyzePhaseMoves(moves []storage.MoveRecord, seg storage.PhaseSegment) PhaseDiagnostics {
@@ -138,6 +154,8 @@ func analyzePhaseMoves(moves []storage.MoveRecord, seg storage.PhaseSegment) Pha
 
 	// Analyze reversals
 	diag.ImmediateReversals, diag.FullCycleWaste = countReversals(moves)
+	diag.SimultaneousMoves = countSimultaneous(moves)
+	diag.InterpolatedMoves = countInterpolated(moves)
 	if len(moves) > 0 {
 		diag.ReversalRate = float64(diag.ImmediateReversals) / float64(len(moves))
 	}
@@ -165,7 +183,9 @@ func analyzePhaseMoves(moves []storage.MoveRecord, seg storage.PhaseSegment) Pha
 	return diag
 }
 
-// countReversals counts immediate reversal patterns (X X', X' X) and full cycles (X X X X)
+// countReversals counts immediate reversal patterns (X X', X' X) and full cycles (X X X X).
+// A pair reported in one notification is not counted as a reversal: its order
+// is uncertain, so it is more likely a face wobbling than a move undone.
 func countReversals(moves []storage.MoveRecord) (reversals, fullCycles int) {
 	if len(moves) < 2 {
 		return 0, 0
@@ -174,6 +194,9 @@ func countReversals(moves []storage.MoveRecord) (reversals, fullCycles int) {
 	for i := 1; i < len(moves); i++ {
 		prev := moves[i-1]
 		curr := moves[i]
+		if sameBatch(curr) {
+			continue
+		}
 
 		// Check for X X' or X' X (same face, opposite direction)
 		if prev.Face == curr.Face && prev.Turn == -curr.Turn {
@@ -197,6 +220,42 @@ func countReversals(moves []storage.MoveRecord) (reversals, fullCycles int) {
 	return reversals, fullCycles
 }
 
+// sameBatch reports whether m was reported in one notification with the move
+// before it.
+func sameBatch(m storage.MoveRecord) bool {
+	return m.BatchSize > 1 && m.BatchIndex > 0
+}
+
+// countSimultaneous counts the moves reported together with other turns.
+func countSimultaneous(moves []storage.MoveRecord) int {
+	count := 0
+	for _, m := range moves {
+		if m.BatchSize > 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// countInterpolated counts the moves whose time was inferred by spreading
+// their notification's turns, not measured.
+func countInterpolated(moves []storage.MoveRecord) int {
+	count := 0
+	for _, m := range moves {
+		if gocube.ParseTimeSource(m.TimeSource) == gocube.TimeInterpolated {
+			count++
+		}
+	}
+	return count
+}
+
+// untimedGap reports whether the gap before m says nothing about how fast
+// it was turned: m was reported with the move before it and its time was
+// not placed by the cube's clock.
+func untimedGap(m storage.MoveRecord) bool {
+	return sameBatch(m) && gocube.ParseTimeSource(m.TimeSource) != gocube.TimeDevice
+}
+
 // analyzeBaseTurns counts D-face turns and finds the longest consecutive run
 func analyzeBaseTurns(moves []storage.MoveRecord) (count, longestRun int) {
 	currentRun := 0
@@ -216,39 +275,47 @@ func analyzeBaseTurns(moves []storage.MoveRecord) (count, longestRun int) {
 	return count, longestRun
 }
 
-// analyzeGaps analyzes inter-move timing gaps
+// analyzeGaps analyzes inter-move timing gaps. Gaps are measured in
+// microseconds so fast sequences are not quantized to whole milliseconds.
+// Gaps within a notification that the cube did not time are left out of
+// the minimum, being 0 or interpolated.
 func analyzeGaps(moves []storage.MoveRecord, diag *PhaseDiagnostics) {
 	if len(moves) < 2 {
 		return
 	}
 
-	var totalGap int64
-	diag.MinGapMs = moves[1].TsMs - moves[0].TsMs
-	diag.MaxGapMs = diag.MinGapMs
+	var totalGapUs int64
+	minGapUs := int64(-1)
+	maxGapUs := moves[1].TsUs - moves[0].TsUs
 
 	for i := 1; i < len(moves); i++ {
-		gap := moves[i].TsMs - moves[i-1].TsMs
+		gapUs := moves[i].TsUs - moves[i-1].TsUs
 
-		if gap < diag.MinGapMs {
-			diag.MinGapMs = gap
+		if !untimedGap(moves[i]) && (minGapUs < 0 || gapUs < minGapUs) {
+			minGapUs = gapUs
 		}
-		if gap > diag.MaxGapMs {
-			diag.MaxGapMs = gap
+		if gapUs > maxGapUs {
+			maxGapUs = gapUs
 		}
-		totalGap += gap
+		totalGapUs += gapUs
 
-		if gap > 750 {
+		if gapUs > 750_000 {
 			diag.GapsOver750ms++
 		}
-		if gap > 1500 {
+		if gapUs > 1_500_000 {
 			diag.GapsOver1500ms++
 		}
-		if gap > 3000 {
+		if gapUs > 3_000_000 {
 			diag.GapsOver3000ms++
 		}
 	}
 
-	diag.AvgGapMs = float64(totalGap) / float64(len(moves)-1)
+	if minGapUs < 0 {
+		minGapUs = 0
+	}
+	diag.MinGapMs = minGapUs / 1000
+	diag.MaxGapMs = maxGapUs / 1000
+	diag.AvgGapMs = float64(totalGapUs) / 1000 / float64(len(moves)-1)
 }
 
 // countShortLoops detects patterns like A B A', A B C A', A B A B'
@@ -523,23 +590,23 @@ func analyzeOrientations(orientations []storage.OrientationRecord, moves []stora
 
 	// Calculate average gap between orientation changes
 	if len(orientations) > 1 {
-		var totalGap int64
+		var totalGapUs int64
 		for i := 1; i < len(orientations); i++ {
-			totalGap += orientations[i].TsMs - orientations[i-1].TsMs
+			totalGapUs += orientations[i].TsUs - orientations[i-1].TsUs
 		}
-		diag.AvgChangeGapMs = float64(totalGap) / float64(len(orientations)-1)
+		diag.AvgChangeGapMs = float64(totalGapUs) / 1000 / float64(len(orientations)-1)
 	}
 
 	// Detect pauses (>750ms between moves) that coincide with orientation changes
 	const pauseThresholdMs = 750
 	for i := 1; i < len(moves); i++ {
-		gap := moves[i].TsMs - moves[i-1].TsMs
-		if gap > pauseThresholdMs {
+		gapUs := moves[i].TsUs - moves[i-1].TsUs
+		if gapUs > pauseThresholdMs*1000 {
 			// Check if any orientation change occurred during this pause
-			pauseStart := moves[i-1].TsMs
-			pauseEnd := moves[i].TsMs
+			pauseStart := moves[i-1].TsUs
+			pauseEnd := moves[i].TsUs
 			for _, o := range orientations {
-				if o.TsMs > pauseStart && o.TsMs < pauseEnd {
+				if o.TsUs > pauseStart && o.TsUs < pauseEnd {
 					diag.PauseWithRotation++
 					break
 				}
@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// EffortWeights assigns a physical cost to each kind of action. Turn costs
+// reflect typical finger-trick difficulty: U and R are the easiest flicks,
+// B usually needs an awkward reach or regrip.
+type EffortWeights struct {
+	Face     map[string]float64 // Cost of a quarter turn per face
+	Double   float64            // Multiplier for a half turn over a quarter
+	Regrip   float64            // Cost of re-gripping after the wrist runs out of travel
+	Rotation float64            // Cost of a whole-cube rotation
+}
+
+// DefaultEffortWeights are the weights used by EstimateEffort.
+var DefaultEffortWeights = EffortWeights{
+	Face: map[string]float64{
+		"U": 0.8,
+		"R": 1.0,
+		"L": 1.2,
+		"D": 1.3,
+		"F": 1.4,
+		"B": 2.0,
+	},
+	Double:   1.5,
+	Regrip:   1.5,
+	Rotation: 2.0,
+}
+
+// wristTravel is how many quarter turns one way a wrist can make before
+// the hand must regrip (R R2 is possible, R R R2 is not).
+const wristTravel = 2
+
+// EffortReport estimates the physical effort of a solve, separate from time.
+// Lower is more economical.
+type EffortReport struct {
+	Score      float64 `json:"score"`
+	TurnEffort float64 `json:"turn_effort"`
+	Turns      int     `json:"turns"` // Quarter-turn pairs merged into half turns
+	Regrips    int     `json:"regrips"`
+	Rotations  int     `json:"rotations"`
+	PerTurn    float64 `json:"effort_per_turn"`
+}
+
+// EstimateEffort estimates effort from a move stream and the number of
+// whole-cube rotations made during it.
+func EstimateEffort(moves []storage.MoveRecord, rotations int) EffortReport {
+	return EstimateEffortWeighted(moves, rotations, DefaultEffortWeights)
+}
+
+// EstimateEffortWeighted estimates effort using custom weights.
+func EstimateEffortWeighted(moves []storage.MoveRecord, rotations int, w EffortWeights) EffortReport {
+	report := EffortReport{Rotations: rotations}
+
+	// Wrist position for the faces turned by a whole-hand wrist motion
+	wrist := map[string]int{"R": 0, "L": 0}
+
+	for i := 0; i < len(moves); i++ {
+		m := moves[i]
+		quarters := m.Turn
+		cost := w.Face[m.Face]
+		if cost == 0 {
+			cost = 1
+		}
+
+		// The cube reports half turns as two quarters; merge them back
+		if i+1 < len(moves) && moves[i+1].Face == m.Face && moves[i+1].Turn == m.Turn && (m.Turn == 1 || m.Turn == -1) {
+			quarters = 2 * m.Turn
+			cost *= w.Double
+			i++
+		} else if m.Turn == 2 || m.Turn == -2 {
+			cost *= w.Double
+		}
+
+		report.Turns++
+		report.TurnEffort += cost
+
+		if pos, ok := wrist[m.Face]; ok {
+			pos += quarters
+			if pos > wristTravel || pos < -wristTravel {
+				report.Regrips++
+				pos = quarters
+			}
+			wrist[m.Face] = pos
+		}
+	}
+
+	report.Score = report.TurnEffort + float64(report.Regrips)*w.Regrip + float64(report.Rotations)*w.Rotation
+	if report.Turns > 0 {
+		report.PerTurn = report.Score / float64(report.Turns)
+	}
+
+	return report
+}
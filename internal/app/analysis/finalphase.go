@@ -88,6 +88,7 @@ type FinalPhaseReport struct {
 	RHSReverseCount     int          `json:"rhs_reverse_count"`
 	LHSForwardCount     int          `json:"lhs_forward_count"`
 	LHSReverseCount     int          `json:"lhs_reverse_count"`
+	ToolCounts          map[string]int `json:"tool_counts,omitempty"` // All tools, including custom library entries
 	TotalToolsUsed      int          `json:"total_tools_used"`
 	ToolMatches         []ToolMatch  `json:"tool_matches"`
 	ConsecutiveRepeats  int          `json:"consecutive_tool_repeats"`
@@ -102,6 +103,7 @@ func AnalyzeFinalPhase(moves []gocube.Move) *FinalPhaseReport {
 		FinalPhaseMoveCount: len(moves),
 		ToolMatches:         []ToolMatch{},
 		TimeBetweenToolsMs:  []int64{},
+		ToolCounts:          make(map[string]int),
 	}
 
 	if len(moves) == 0 {
@@ -117,13 +119,14 @@ func AnalyzeFinalPhase(moves []gocube.Move) *FinalPhaseReport {
 	matched := make([]bool, len(moves))
 	var lastMatchEnd int = -1
 	var lastMatchTs int64 = 0
+	tools := Tools()
 
 	for i := 0; i < len(moves); i++ {
 		if matched[i] {
 			continue
 		}
 
-		for _, tool := range AllTools {
+		for _, tool := range tools {
 			if matchesTool(moves, i, tool.Sequence) {
 				match := ToolMatch{
 					ToolName:   tool.Name,
@@ -153,6 +156,7 @@ func AnalyzeFinalPhase(moves []gocube.Move) *FinalPhaseReport {
 				lastMatchTs = moves[lastMatchEnd].Time.UnixMilli()
 
 				// Update counts
+				report.ToolCounts[tool.Name]++
 				switch tool.Name {
 				case "RHS Forward":
 					report.RHSForwardCount++
@@ -170,8 +174,7 @@ func AnalyzeFinalPhase(moves []gocube.Move) *FinalPhaseReport {
 		}
 	}
 
-	report.TotalToolsUsed = report.RHSForwardCount + report.RHSReverseCount +
-		report.LHSForwardCount + report.LHSReverseCount
+	report.TotalToolsUsed = len(report.ToolMatches)
 
 	// Count unmatched moves
 	for _, m := range matched {
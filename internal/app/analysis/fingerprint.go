@@ -0,0 +1,220 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// Transformation fingerprinting groups move sequences by their net effect on
+// the cube rather than by their literal notation.
+//
+// A window's fingerprint combines the cycle structure of the facelet
+// permutation it produces with the multiset of moves it contains. Both are
+// invariant under cyclic rotation of the sequence, so "R U R' U'" and
+// "U R' U' R" (the same loop entered at a different point) share a
+// fingerprint, as does the inverse "U R U' R'".
+
+// NGram modes reported in NGramReport.Mode.
+const (
+	NGramModeLiteral = "literal"
+	NGramModeEffect  = "effect"
+)
+
+// Fingerprint returns the transformation fingerprint of a move sequence.
+func Fingerprint(moves []gocube.Move) string {
+	tokens := make([]uint8, len(moves))
+	for i, m := range moves {
+		tokens[i] = moveToken(m)
+	}
+	return tokenFingerprint(tokens)
+}
+
+// tokenFingerprint computes the fingerprint for a token sequence.
+func tokenFingerprint(tokens []uint8) string {
+	// Label every facelet uniquely so the cube tracks a permutation.
+	cube := &gocube.Cube{}
+	for f := 0; f < 6; f++ {
+		for i := 0; i < 9; i++ {
+			cube.Facelets[f][i] = gocube.Color(f*9 + i)
+		}
+	}
+	for _, t := range tokens {
+		cube.Apply(moveFromToken(t))
+	}
+
+	var perm [54]int
+	for f := 0; f < 6; f++ {
+		for i := 0; i < 9; i++ {
+			perm[f*9+i] = int(cube.Facelets[f][i])
+		}
+	}
+
+	// Cycle lengths > 1, sorted descending
+	var cycles []int
+	var seen [54]bool
+	for start := 0; start < 54; start++ {
+		if seen[start] {
+			continue
+		}
+		length := 0
+		for p := start; !seen[p]; p = perm[p] {
+			seen[p] = true
+			length++
+		}
+		if length > 1 {
+			cycles = append(cycles, length)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(cycles)))
+
+	sorted := make([]uint8, len(tokens))
+	copy(sorted, tokens)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var b strings.Builder
+	for i, c := range cycles {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		fmt.Fprintf(&b, "%d", c)
+	}
+	if len(cycles) == 0 {
+		b.WriteString("id")
+	}
+	b.WriteByte('/')
+	b.WriteString(ngramKey(sorted))
+	return b.String()
+}
+
+// effectEntry tracks fingerprint occurrences during mining.
+type effectEntry struct {
+	tokens      []uint8 // First sequence seen with this fingerprint
+	count       int
+	variants    map[string]bool
+	occurrences []NGramOccurrence
+}
+
+// MineEffectNGrams finds the top-K most frequent n-grams for each n in
+// [minN, maxN], grouping windows by transformation fingerprint instead of
+// literal move sequence.
+func MineEffectNGrams(moves []gocube.Move, minN, maxN, topK int) *NGramReport {
+	report := &NGramReport{
+		Mode:      NGramModeEffect,
+		TopNGrams: make(map[int][]NGram),
+	}
+
+	if len(moves) < minN {
+		return report
+	}
+
+	tokens := make([]uint8, len(moves))
+	for i, m := range moves {
+		tokens[i] = moveToken(m)
+	}
+
+	for n := minN; n <= maxN && n <= len(moves); n++ {
+		ngrams := mineEffectNGramsForN(tokens, moves, n, topK)
+		if len(ngrams) > 0 {
+			report.TopNGrams[n] = ngrams
+		}
+	}
+
+	return report
+}
+
+// mineEffectNGramsForN mines fingerprint groups of a specific length.
+func mineEffectNGramsForN(tokens []uint8, moves []gocube.Move, n, topK int) []NGram {
+	groups := make(map[string]*effectEntry)
+	literal := make(map[string]string) // Literal key -> fingerprint cache
+
+	for start := 0; start+n <= len(tokens); start++ {
+		window := tokens[start : start+n]
+		key := ngramKey(window)
+
+		fp, ok := literal[key]
+		if !ok {
+			fp = tokenFingerprint(window)
+			literal[key] = fp
+		}
+
+		occ := NGramOccurrence{
+			StartIndex: start,
+			TsMs:       moves[start].Time.UnixMilli(),
+		}
+
+		entry, exists := groups[fp]
+		if !exists {
+			seq := make([]uint8, n)
+			copy(seq, window)
+			entry = &effectEntry{
+				tokens:   seq,
+				variants: make(map[string]bool),
+			}
+			groups[fp] = entry
+		}
+		entry.count++
+		entry.variants[tokensNotation(window)] = true
+		if len(entry.occurrences) < 10 {
+			entry.occurrences = append(entry.occurrences, occ)
+		}
+	}
+
+	fingerprints := make([]string, 0, len(groups))
+	for fp, entry := range groups {
+		if entry.count >= 2 {
+			fingerprints = append(fingerprints, fp)
+		}
+	}
+
+	sort.Slice(fingerprints, func(i, j int) bool {
+		ci, cj := groups[fingerprints[i]].count, groups[fingerprints[j]].count
+		if ci != cj {
+			return ci > cj
+		}
+		return fingerprints[i] < fingerprints[j]
+	})
+
+	if len(fingerprints) > topK {
+		fingerprints = fingerprints[:topK]
+	}
+
+	result := make([]NGram, len(fingerprints))
+	for i, fp := range fingerprints {
+		entry := groups[fp]
+
+		sequence := make([]string, len(entry.tokens))
+		for j, token := range entry.tokens {
+			sequence[j] = moveFromToken(token).Notation()
+		}
+
+		variants := make([]string, 0, len(entry.variants))
+		for v := range entry.variants {
+			variants = append(variants, v)
+		}
+		sort.Strings(variants)
+
+		result[i] = NGram{
+			N:           n,
+			Sequence:    sequence,
+			Tokens:      entry.tokens,
+			Count:       entry.count,
+			Fingerprint: fp,
+			Variants:    variants,
+			Occurrences: entry.occurrences,
+		}
+	}
+
+	return result
+}
+
+// tokensNotation formats a token sequence as space-separated notation.
+func tokensNotation(tokens []uint8) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = moveFromToken(t).Notation()
+	}
+	return strings.Join(parts, " ")
+}
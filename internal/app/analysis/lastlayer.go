@@ -0,0 +1,228 @@
+package analysis
+
+import (
+	"sync"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// LastLayerCase is the OLL or PLL case of a cube with its first two layers
+// solved. The solver's last layer is D, but algorithms and AUFs are given
+// as they are learned, with the last layer turned up (z2): a D turn of the
+// cube is the U turn of the same name then.
+type LastLayerCase struct {
+	Name      string `json:"name"`               // e.g. "OLL 45" or "T-perm"
+	Category  string `json:"category"`           // CategoryOLL or CategoryPLL
+	Algorithm string `json:"algorithm"`          // Standard algorithm, last layer on U
+	AUF       string `json:"auf,omitempty"`      // U turn before the algorithm, if any
+	PostAUF   string `json:"post_auf,omitempty"` // PLL: U turn after it, if any
+}
+
+var (
+	f2lPattern        = gocube.MustParsePattern("f2l")
+	orientedLLPattern = gocube.MustParsePattern("f2l D=Y")
+)
+
+// llStickers are the last layer's stickers: the D face and the bottom row
+// of each side.
+var llStickers = func() [][2]int {
+	var s [][2]int
+	for i := 0; i < 9; i++ {
+		s = append(s, [2]int{int(gocube.CubeFaceD), i})
+	}
+	for _, f := range []gocube.CubeFace{gocube.CubeFaceF, gocube.CubeFaceR, gocube.CubeFaceB, gocube.CubeFaceL} {
+		for i := 6; i < 9; i++ {
+			s = append(s, [2]int{int(f), i})
+		}
+	}
+	return s
+}()
+
+// ollMask returns which last-layer stickers are yellow, the whole of what
+// tells OLL cases apart.
+func ollMask(c *gocube.Cube) uint32 {
+	var mask uint32
+	for i, s := range llStickers {
+		if c.Facelets[s[0]][s[1]] == gocube.Yellow {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+var (
+	llOnce   sync.Once
+	ollCases map[uint32]LastLayerCase
+	pllCases map[[6][9]gocube.Color]LastLayerCase
+)
+
+// buildLastLayerTables maps every state each OLL and PLL case leaves, with
+// any AUF around it, to the case. Where symmetry gives several AUFs for one
+// state the fewest turns are kept.
+func buildLastLayerTables() {
+	ollCases = make(map[uint32]LastLayerCase)
+	pllCases = make(map[[6][9]gocube.Color]LastLayerCase)
+
+	for _, a := range ollAlgorithms {
+		inverse := llInverse(a.moves)
+		for pre := 0; pre < 4; pre++ {
+			c := gocube.NewCube()
+			c.Apply(inverse...)
+			turnD(c, pre)
+			mask := ollMask(c)
+			if _, ok := ollCases[mask]; !ok {
+				ollCases[mask] = LastLayerCase{Name: a.name, Category: CategoryOLL, Algorithm: a.moves, AUF: aufNotation(-pre)}
+			}
+		}
+	}
+
+	for _, a := range pllAlgorithms {
+		inverse := llInverse(a.moves)
+		for pre := 0; pre < 4; pre++ {
+			for post := 0; post < 4; post++ {
+				c := gocube.NewCube()
+				turnD(c, pre)
+				c.Apply(inverse...)
+				turnD(c, post)
+				k := LastLayerCase{Name: a.name + "-perm", Category: CategoryPLL, Algorithm: a.moves,
+					AUF: aufNotation(-post), PostAUF: aufNotation(-pre)}
+				if old, ok := pllCases[c.Facelets]; !ok || aufTurns(k) < aufTurns(old) {
+					pllCases[c.Facelets] = k
+				}
+			}
+		}
+	}
+}
+
+// llInverse returns the face turns undoing alg, written for the last layer
+// on U, on a cube with the last layer on D.
+func llInverse(alg string) []gocube.Move {
+	parsed, err := gocube.ParseMoves("z2 " + alg)
+	if err != nil {
+		panic("analysis: invalid last layer algorithm " + alg)
+	}
+	moves := gocube.ExpandMoves(parsed)
+	inverse := make([]gocube.Move, len(moves))
+	for i, m := range moves {
+		inverse[len(moves)-1-i] = m.Inverse()
+	}
+	return inverse
+}
+
+func turnD(c *gocube.Cube, quarters int) {
+	for i := 0; i < quarters; i++ {
+		c.Apply(gocube.Move{Face: gocube.FaceD, Turn: gocube.CW})
+	}
+}
+
+// aufNotation returns the U turn of quarters clockwise quarter turns, or
+// "" for none.
+func aufNotation(quarters int) string {
+	switch (quarters%4 + 4) % 4 {
+	case 1:
+		return "U"
+	case 2:
+		return "U2"
+	case 3:
+		return "U'"
+	}
+	return ""
+}
+
+func aufTurns(k LastLayerCase) int {
+	n := 0
+	if k.AUF != "" {
+		n++
+	}
+	if k.PostAUF != "" {
+		n++
+	}
+	return n
+}
+
+// RecognizeLastLayer returns the last-layer case of a cube whose first two
+// layers are solved: its OLL case while the last layer is not oriented,
+// then its PLL case. It returns false if the first two layers are not
+// solved, or only an AUF or nothing is left.
+func RecognizeLastLayer(c *gocube.Cube) (LastLayerCase, bool) {
+	if !c.Matches(f2lPattern) {
+		return LastLayerCase{}, false
+	}
+	llOnce.Do(buildLastLayerTables)
+	if !c.Matches(orientedLLPattern) {
+		k, ok := ollCases[ollMask(c)]
+		return k, ok
+	}
+	k, ok := pllCases[c.Facelets]
+	return k, ok
+}
+
+// LastLayerSplit is the time and moves from recognizing one step of the
+// last layer to completing it.
+type LastLayerSplit struct {
+	Case       *LastLayerCase `json:"case,omitempty"` // Nil for a skip
+	DurationMs int64          `json:"duration_ms"`
+	Moves      int            `json:"moves"`
+}
+
+// LastLayerReport splits a solve's last layer into OLL and PLL.
+type LastLayerReport struct {
+	OLL LastLayerSplit `json:"oll"`
+	PLL LastLayerSplit `json:"pll"`
+}
+
+// AnalyzeLastLayer replays a solve, given the moves before it (the
+// scramble), and returns its OLL and PLL: the case met when the first two
+// layers were first complete and, from there, when the last layer was
+// first oriented, with the time and moves until the next step. It returns
+// nil if the solve never completes the first two layers or never ends
+// solved.
+func AnalyzeLastLayer(before, solve []gocube.Move) *LastLayerReport {
+	if len(solve) == 0 {
+		return nil
+	}
+	c := gocube.NewCube()
+	c.Apply(before...)
+
+	var report LastLayerReport
+	f2lAt, orientedAt := -1, -1 // Moves into the solve
+	mark := func(i int) {
+		if f2lAt < 0 {
+			if !c.Matches(f2lPattern) {
+				return
+			}
+			f2lAt = i
+			if k, ok := RecognizeLastLayer(c); ok && k.Category == CategoryOLL {
+				report.OLL.Case = &k
+			}
+		}
+		if orientedAt < 0 && c.Matches(orientedLLPattern) {
+			orientedAt = i
+			if k, ok := RecognizeLastLayer(c); ok {
+				report.PLL.Case = &k
+			}
+		}
+	}
+
+	mark(0)
+	for i, m := range solve {
+		c.Apply(m)
+		mark(i + 1)
+	}
+	if f2lAt < 0 || orientedAt < 0 || !c.IsSolved() {
+		return nil
+	}
+
+	// Index n is the state after n moves; its time is that of move n-1
+	at := func(n int) int64 {
+		if n == 0 {
+			return solve[0].Time.UnixMilli()
+		}
+		return solve[n-1].Time.UnixMilli()
+	}
+	report.OLL.Moves = orientedAt - f2lAt
+	report.OLL.DurationMs = at(orientedAt) - at(f2lAt)
+	report.PLL.Moves = len(solve) - orientedAt
+	report.PLL.DurationMs = at(len(solve)) - at(orientedAt)
+	return &report
+}
@@ -0,0 +1,107 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// AlgorithmDef is a user-defined algorithm in the library file.
+type AlgorithmDef struct {
+	Name  string `json:"name"`
+	Moves string `json:"moves"` // Standard notation, e.g. "R U R' U'"
+}
+
+// AlgorithmLibrary is the on-disk format of the user's algorithm library.
+type AlgorithmLibrary struct {
+	Algorithms []AlgorithmDef `json:"algorithms"`
+}
+
+var (
+	customToolsMu sync.RWMutex
+	customTools   []Tool
+)
+
+// Tools returns the built-in tools followed by the active custom tools.
+func Tools() []Tool {
+	customToolsMu.RLock()
+	defer customToolsMu.RUnlock()
+
+	tools := make([]Tool, 0, len(AllTools)+len(customTools))
+	tools = append(tools, AllTools...)
+	tools = append(tools, customTools...)
+	return tools
+}
+
+// SetCustomTools replaces the active custom tools.
+func SetCustomTools(tools []Tool) {
+	customToolsMu.Lock()
+	defer customToolsMu.Unlock()
+	customTools = tools
+}
+
+// LoadAlgorithmLibrary reads and validates an algorithm library file.
+// A missing file yields an empty library. The active tools are not changed;
+// call SetCustomTools with the result to apply it.
+func LoadAlgorithmLibrary(path string) ([]Tool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read algorithm library: %w", err)
+	}
+
+	var lib AlgorithmLibrary
+	if err := json.Unmarshal(data, &lib); err != nil {
+		return nil, fmt.Errorf("failed to parse algorithm library %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range AllTools {
+		seen[t.Name] = true
+	}
+
+	tools := make([]Tool, 0, len(lib.Algorithms))
+	for i, def := range lib.Algorithms {
+		name := strings.TrimSpace(def.Name)
+		if name == "" {
+			return nil, fmt.Errorf("algorithm %d: name is required", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("algorithm %q: duplicate name", name)
+		}
+		seen[name] = true
+
+		moves, err := ParseMovesStrict(def.Moves)
+		if err != nil {
+			return nil, fmt.Errorf("algorithm %q: %w", name, err)
+		}
+		if len(moves) == 0 {
+			return nil, fmt.Errorf("algorithm %q: no moves", name)
+		}
+
+		// Recorded moves are outer-face turns only
+		tools = append(tools, Tool{Name: name, Sequence: gocube.ExpandMoves(moves)})
+	}
+
+	return tools, nil
+}
+
+// ParseMovesStrict parses notation, rejecting any invalid token.
+func ParseMovesStrict(s string) ([]gocube.Move, error) {
+	parts := strings.Fields(s)
+	moves := make([]gocube.Move, 0, len(parts))
+	for _, part := range parts {
+		m, err := gocube.ParseMove(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid move %q", part)
+		}
+		moves = append(moves, m)
+	}
+	return moves, nil
+}
@@ -0,0 +1,286 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// Kinds of wrong execution found by FindMirroredAlgorithms.
+const (
+	MistakeMirror  = "mirror"  // Left-right mirror: R U R' executed as L' U' L
+	MistakeInverse = "inverse" // The algorithm backwards
+)
+
+// minMirroredAttempt is the fewest moves of a wrong execution that count:
+// shorter attempts match too much by chance.
+const minMirroredAttempt = 3
+
+// MirroredAttempt is a mirrored or inverted OLL or PLL that was started,
+// undone, and followed by the intended algorithm, possibly after an AUF.
+type MirroredAttempt struct {
+	Case       string `json:"case"`     // Intended algorithm, e.g. "OLL 27"
+	Category   string `json:"category"` // CategoryOLL or CategoryPLL
+	Kind       string `json:"kind"`     // MistakeMirror or MistakeInverse
+	Attempt    string `json:"attempt"`  // Moves executed before undoing, e.g. "L' U' L"
+	Moves      int    `json:"moves"`    // Stored moves spent on the attempt and its undo
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"` // Last stored move of the undo
+}
+
+// MirrorCaseCount counts the mirrored algorithms corrected for one case.
+type MirrorCaseCount struct {
+	Case        string `json:"case"`
+	Category    string `json:"category"`
+	Mirror      int    `json:"mirror"`
+	Inverse     int    `json:"inverse"`
+	Total       int    `json:"total"`
+	WastedMoves int    `json:"wasted_moves"`
+}
+
+// mistakeAlgorithm is a wrong form of an OLL or PLL in one view, with the
+// algorithm in every view with the last layer on the same face.
+type mistakeAlgorithm struct {
+	name     string
+	category string
+	kind     string
+	auf      gocube.Face
+	wrong    []gocube.Move
+	intended [][]gocube.Move
+}
+
+var (
+	mistakeDBOnce sync.Once
+	mistakeDB     []mistakeAlgorithm
+)
+
+// buildMistakeDB pairs every view of the OLL and PLL algorithms with its
+// mirror and inverse in the same view. Wrong forms that are a view of the
+// algorithm itself, as for symmetric cases, are left out.
+func buildMistakeDB() {
+	type source struct{ name, category, moves string }
+	var sources []source
+	for _, a := range pllAlgorithms {
+		sources = append(sources, source{a.name + "-perm", CategoryPLL, a.moves})
+	}
+	for _, a := range ollAlgorithms {
+		sources = append(sources, source{a.name, CategoryOLL, a.moves})
+	}
+
+	for _, s := range sources {
+		parsed, err := gocube.ParseMoves(s.moves)
+		if err != nil {
+			panic("analysis: invalid algorithm " + s.name)
+		}
+		wrongForms := map[string][]gocube.Move{
+			MistakeMirror:  mirrorMoves(parsed),
+			MistakeInverse: invertMoves(parsed),
+		}
+
+		own := make(map[string]bool)
+		intended := make(map[gocube.Face][][]gocube.Move)
+		for _, view := range algorithmViews {
+			moves := viewMoves(view, parsed)
+			if key := movesKey(moves); !own[key] {
+				own[key] = true
+				auf := viewAUF(view)
+				intended[auf] = append(intended[auf], moves)
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, view := range algorithmViews {
+			for _, kind := range []string{MistakeMirror, MistakeInverse} {
+				wrong := viewMoves(view, wrongForms[kind])
+				key := movesKey(wrong)
+				if own[key] || seen[key] {
+					continue
+				}
+				seen[key] = true
+				auf := viewAUF(view)
+				mistakeDB = append(mistakeDB, mistakeAlgorithm{
+					name: s.name, category: s.category, kind: kind,
+					auf: auf, wrong: wrong, intended: intended[auf],
+				})
+			}
+		}
+	}
+}
+
+// viewMoves returns moves as the turns a smart cube reports when they are
+// executed after the rotation view, with same-face turns merged.
+func viewMoves(view string, moves []gocube.Move) []gocube.Move {
+	rotation, err := gocube.ParseMoves(view)
+	if err != nil {
+		panic("analysis: invalid view " + view)
+	}
+	return mergeTurns(gocube.ExpandMoves(append(rotation, moves...)))
+}
+
+// viewAUF returns the face of the last layer in a view.
+func viewAUF(view string) gocube.Face {
+	if strings.HasPrefix(view, "z2") {
+		return gocube.FaceD
+	}
+	return gocube.FaceU
+}
+
+// mirrorFaces swaps the faces a left-right mirror exchanges.
+var mirrorFaces = map[gocube.Face]gocube.Face{
+	gocube.FaceR:  gocube.FaceL,
+	gocube.FaceL:  gocube.FaceR,
+	gocube.FaceRw: gocube.FaceLw,
+	gocube.FaceLw: gocube.FaceRw,
+}
+
+// mirrorMoves returns the left-right mirror of moves. Every turn changes
+// direction except M and x, whose axis runs through the mirror.
+func mirrorMoves(moves []gocube.Move) []gocube.Move {
+	out := make([]gocube.Move, len(moves))
+	for i, m := range moves {
+		if m.Face != gocube.FaceM && m.Face != gocube.FaceX {
+			m = m.Inverse()
+		}
+		if f, ok := mirrorFaces[m.Face]; ok {
+			m.Face = f
+		}
+		out[i] = m
+	}
+	return out
+}
+
+// invertMoves returns moves backwards, each turn inverted.
+func invertMoves(moves []gocube.Move) []gocube.Move {
+	out := make([]gocube.Move, len(moves))
+	for i, m := range moves {
+		out[len(moves)-1-i] = m.Inverse()
+	}
+	return out
+}
+
+// FindMirroredAlgorithms finds where an OLL or PLL was executed mirrored or
+// inverted, at least in part, then undone move by move and followed by the
+// intended algorithm, possibly after an AUF. moves are the stored moves; the
+// turns of a half turn may be stored separately.
+func FindMirroredAlgorithms(moves []gocube.Move) []MirroredAttempt {
+	mistakeDBOnce.Do(buildMistakeDB)
+
+	attempts := []MirroredAttempt{}
+	for i := 0; i < len(moves); i++ {
+		if a, end, ok := matchMirroredAttempt(moves, i); ok {
+			a.StartIndex, a.EndIndex = i, end-1
+			a.Moves = end - i
+			attempts = append(attempts, a)
+			i = end - 1
+		}
+	}
+	return attempts
+}
+
+// matchMirroredAttempt returns the longest wrong attempt starting at
+// moves[i] that is undone and followed by its intended algorithm, and the
+// index just past the undo.
+func matchMirroredAttempt(moves []gocube.Move, i int) (MirroredAttempt, int, bool) {
+	var best MirroredAttempt
+	bestEnd, bestLen := 0, 0
+	for _, m := range mistakeDB {
+		for n := len(m.wrong); n >= minMirroredAttempt && n > bestLen; n-- {
+			attempt := m.wrong[:n]
+			if startsAny(m.intended, attempt) {
+				continue // Started right, perhaps at the wrong AUF
+			}
+			undoStart, ok := matchTurns(moves, i, attempt)
+			if !ok {
+				continue
+			}
+			undoEnd, ok := matchTurns(moves, undoStart, invertMoves(attempt))
+			if !ok {
+				continue
+			}
+			next := undoEnd
+			for next < len(moves) && moves[next].Face == m.auf {
+				next++
+			}
+			if !matchesAny(moves, next, m.intended) {
+				continue
+			}
+			best = MirroredAttempt{Case: m.name, Category: m.category, Kind: m.kind, Attempt: movesKey(attempt)}
+			bestEnd, bestLen = undoEnd, n
+			break
+		}
+	}
+	return best, bestEnd, bestLen > 0
+}
+
+// matchTurns matches merged turns against stored moves from moves[i],
+// where each turn may be stored as several turns of its face (R2 as R R).
+// It returns the index just past the match.
+func matchTurns(moves []gocube.Move, i int, turns []gocube.Move) (int, bool) {
+	for _, t := range turns {
+		net := 0
+		for {
+			if i >= len(moves) || moves[i].Face != t.Face {
+				return 0, false
+			}
+			net += int(moves[i].Turn)
+			i++
+			if (net-int(t.Turn))%4 == 0 {
+				break
+			}
+		}
+	}
+	return i, true
+}
+
+// matchesAny reports whether any of algs follows moves[i].
+func matchesAny(moves []gocube.Move, i int, algs [][]gocube.Move) bool {
+	for _, alg := range algs {
+		if _, ok := matchTurns(moves, i, alg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// startsAny reports whether any of algs starts with prefix.
+func startsAny(algs [][]gocube.Move, prefix []gocube.Move) bool {
+	for _, alg := range algs {
+		if len(alg) >= len(prefix) && matchesTool(alg, 0, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CountMirroredAlgorithms totals attempts per case, most frequent first.
+func CountMirroredAlgorithms(attempts []MirroredAttempt) []MirrorCaseCount {
+	byCase := make(map[string]*MirrorCaseCount)
+	for _, a := range attempts {
+		c, ok := byCase[a.Case]
+		if !ok {
+			c = &MirrorCaseCount{Case: a.Case, Category: a.Category}
+			byCase[a.Case] = c
+		}
+		if a.Kind == MistakeMirror {
+			c.Mirror++
+		} else {
+			c.Inverse++
+		}
+		c.Total++
+		c.WastedMoves += a.Moves
+	}
+
+	counts := make([]MirrorCaseCount, 0, len(byCase))
+	for _, c := range byCase {
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Total != counts[j].Total {
+			return counts[i].Total > counts[j].Total
+		}
+		return counts[i].Case < counts[j].Case
+	})
+	return counts
+}
@@ -12,6 +12,9 @@ type NGram struct {
 	Sequence    []string `json:"sequence"`
 	Tokens      []uint8  `json:"-"`
 	Count       int      `json:"count"`
+	Fingerprint string   `json:"fingerprint,omitempty"` // Set when mined by effect
+	Variants    []string `json:"variants,omitempty"`    // Literal sequences sharing the fingerprint
+	Algorithm   *AlgorithmMatch `json:"algorithm,omitempty"` // Known algorithm it is or is part of
 	Occurrences []NGramOccurrence `json:"occurrences,omitempty"`
 }
 
@@ -24,6 +27,7 @@ type NGramOccurrence struct {
 
 // NGramReport contains the results of n-gram mining.
 type NGramReport struct {
+	Mode      string          `json:"mode"` // "literal" or "effect"
 	TopNGrams map[int][]NGram `json:"top_ngrams"` // Keyed by n
 }
 
@@ -103,6 +107,7 @@ type ngramEntry struct {
 // MineNGrams finds the top-K most frequent n-grams for each n in [minN, maxN].
 func MineNGrams(moves []gocube.Move, minN, maxN, topK int) *NGramReport {
 	report := &NGramReport{
+		Mode:      NGramModeLiteral,
 		TopNGrams: make(map[int][]NGram),
 	}
 
@@ -232,6 +237,7 @@ func slicesEqual(a, b []uint8) bool {
 // MineNGramsAcrossSolves aggregates n-grams across multiple solves.
 func MineNGramsAcrossSolves(solveNGrams map[string]*NGramReport, topK int) *NGramReport {
 	report := &NGramReport{
+		Mode:      NGramModeLiteral,
 		TopNGrams: make(map[int][]NGram),
 	}
 
@@ -0,0 +1,259 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// DefaultNGramMemoryBudget is the memory NGramMiner uses unless told
+// otherwise: enough for the patterns of tens of thousands of solves.
+const DefaultNGramMemoryBudget = 64 << 20
+
+const (
+	// ngramCounterBytes is the estimated size of one exact counter: the map
+	// entry, key, struct and a few sample occurrences.
+	ngramCounterBytes = 256
+
+	// ngramSketchDepth is the number of hash rows in the count-min sketch.
+	ngramSketchDepth = 4
+
+	// ngramSampleOccurrences is how many occurrences each pattern keeps.
+	ngramSampleOccurrences = 10
+)
+
+// NGramMiner counts n-grams across many solves in bounded memory, for
+// cross-solve pattern reports where MineNGrams would hold every n-gram of
+// every length at once. Solves are added one at a time and their moves are
+// not kept.
+//
+// Every n-gram is counted in a count-min sketch, and gets an exact counter
+// once its sketch estimate reaches a floor. The counter starts from the
+// estimate. Until the counters first outgrow the memory budget the floor is
+// one, so every n-gram is counted exactly from its first occurrence, as
+// MineNGrams counts it. Then the least frequent half is dropped and the
+// floor rises to beat them; the sketch still remembers them, so a dropped
+// pattern that keeps recurring comes back with its count. Counts after that
+// are estimates: a pattern is never undercounted, and overcounted only by
+// sketch collisions, which the budget keeps rare.
+type NGramMiner struct {
+	minN, maxN  int
+	maxCounters int
+	floor       uint32 // Sketch estimate needed for a counter
+
+	sketch   [ngramSketchDepth][]uint32
+	counters map[string]*streamEntry
+
+	solves    int
+	moves     int
+	evictions int
+}
+
+// streamEntry is an exact counter, remembering the sketch estimate it
+// started from.
+type streamEntry struct {
+	ngramEntry
+	admitted int
+}
+
+// NGramMinerStats describes what a miner has seen and how it used memory.
+type NGramMinerStats struct {
+	Solves       int   `json:"solves"`
+	Moves        int   `json:"moves"`
+	Counters     int   `json:"counters"`      // Exact counters held now
+	MaxCounters  int   `json:"max_counters"`  // Counters the budget allows
+	Evictions    int   `json:"evictions"`     // Counters dropped to stay in budget
+	SketchWidth  int   `json:"sketch_width"`  // Cells per sketch row
+	MemoryBudget int64 `json:"memory_budget"` // Bytes
+}
+
+// NewNGramMiner creates a miner for n-grams of length minN to maxN using
+// about budget bytes (DefaultNGramMemoryBudget if budget <= 0). A quarter of
+// the budget goes to the sketch, the rest to exact counters.
+func NewNGramMiner(minN, maxN int, budget int64) *NGramMiner {
+	if budget <= 0 {
+		budget = DefaultNGramMemoryBudget
+	}
+	width := int(budget / 4 / ngramSketchDepth / 4) // 4-byte cells
+	if width < 1024 {
+		width = 1024
+	}
+	maxCounters := int(budget * 3 / 4 / ngramCounterBytes)
+	if maxCounters < 64 {
+		maxCounters = 64
+	}
+
+	m := &NGramMiner{
+		minN:        minN,
+		maxN:        maxN,
+		maxCounters: maxCounters,
+		floor:       1,
+		counters:    make(map[string]*streamEntry),
+	}
+	for i := range m.sketch {
+		m.sketch[i] = make([]uint32, width)
+	}
+	return m
+}
+
+// Add counts the n-grams of one solve's moves.
+func (m *NGramMiner) Add(solveID string, moves []gocube.Move) {
+	m.solves++
+	m.moves += len(moves)
+
+	tokens := make([]uint8, len(moves))
+	for i, mv := range moves {
+		tokens[i] = moveToken(mv)
+	}
+
+	for start := 0; start+m.minN <= len(tokens); start++ {
+		// FNV-1a over the window, extended one token per length
+		h := uint64(14695981039346656037)
+		for n := 1; n <= m.maxN && start+n <= len(tokens); n++ {
+			h ^= uint64(tokens[start+n-1])
+			h *= 1099511628211
+			if n < m.minN {
+				continue
+			}
+			m.count(solveID, tokens[start:start+n], h^uint64(n), moves[start])
+		}
+	}
+}
+
+// count records one occurrence of the n-gram window with hash h.
+func (m *NGramMiner) count(solveID string, window []uint8, h uint64, first gocube.Move) {
+	estimate := m.sketchAdd(h)
+	if estimate < m.floor {
+		return
+	}
+
+	occ := NGramOccurrence{SolveID: solveID, TsMs: first.Time.UnixMilli()}
+	key := string(window)
+	if entry, ok := m.counters[key]; ok {
+		entry.count++
+		if len(entry.occurrences) < ngramSampleOccurrences {
+			entry.occurrences = append(entry.occurrences, occ)
+		}
+		return
+	}
+
+	m.counters[key] = &streamEntry{
+		ngramEntry: ngramEntry{
+			tokens:      []uint8(key),
+			count:       int(estimate),
+			occurrences: []NGramOccurrence{occ},
+		},
+		admitted: int(estimate),
+	}
+	if len(m.counters) > m.maxCounters {
+		m.evict()
+	}
+}
+
+// sketchAdd counts h in the sketch with a conservative update (only the
+// smallest cells grow) and returns its new estimated count.
+func (m *NGramMiner) sketchAdd(h uint64) uint32 {
+	var cells [ngramSketchDepth]*uint32
+	min := ^uint32(0)
+	for i := range m.sketch {
+		row := m.sketch[i]
+		// Derive each row's index from the one hash (Kirsch-Mitzenmacher)
+		idx := (h + uint64(i)*(h>>32|1)) % uint64(len(row))
+		cells[i] = &row[idx]
+		if row[idx] < min {
+			min = row[idx]
+		}
+	}
+	min++
+	for _, c := range cells {
+		if *c < min {
+			*c = min
+		}
+	}
+	return min
+}
+
+// evict drops the less frequent half of the exact counters, and raises the
+// floor so that only n-grams counted more often than those get a counter
+// again.
+func (m *NGramMiner) evict() {
+	counts := make([]int, 0, len(m.counters))
+	for _, e := range m.counters {
+		counts = append(counts, e.count)
+	}
+	sort.Ints(counts)
+	median := counts[len(counts)/2]
+	if floor := uint32(median) + 1; floor > m.floor {
+		m.floor = floor
+	}
+
+	for key, e := range m.counters {
+		if e.count < median || (e.count == median && len(m.counters) > m.maxCounters/2) {
+			delete(m.counters, key)
+			m.evictions++
+		}
+	}
+}
+
+// Report returns the topK most frequent n-grams for each length seen at
+// least twice. N-grams not seen again since they got a counter are left
+// out, as their count may be all sketch collisions. Before any eviction a
+// counter starts at the first occurrence, so nothing seen twice is missed;
+// after one, an n-gram seen exactly twice can be.
+func (m *NGramMiner) Report(topK int) *NGramReport {
+	report := &NGramReport{
+		Mode:      NGramModeLiteral,
+		TopNGrams: make(map[int][]NGram),
+	}
+
+	byN := make(map[int][]*ngramEntry)
+	for _, e := range m.counters {
+		if e.count >= 2 && e.count > e.admitted {
+			byN[len(e.tokens)] = append(byN[len(e.tokens)], &e.ngramEntry)
+		}
+	}
+
+	for n, entries := range byN {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].count != entries[j].count {
+				return entries[i].count > entries[j].count
+			}
+			return ngramKey(entries[i].tokens) < ngramKey(entries[j].tokens)
+		})
+		if len(entries) > topK {
+			entries = entries[:topK]
+		}
+
+		ngrams := make([]NGram, len(entries))
+		for i, e := range entries {
+			sequence := make([]string, len(e.tokens))
+			for j, token := range e.tokens {
+				sequence[j] = moveFromToken(token).Notation()
+			}
+			ngrams[i] = NGram{
+				N:           n,
+				Sequence:    sequence,
+				Tokens:      e.tokens,
+				Count:       e.count,
+				Occurrences: e.occurrences,
+			}
+		}
+		report.TopNGrams[n] = ngrams
+	}
+
+	return report
+}
+
+// Stats returns what the miner has seen and how it used memory.
+func (m *NGramMiner) Stats() NGramMinerStats {
+	width := len(m.sketch[0])
+	return NGramMinerStats{
+		Solves:       m.solves,
+		Moves:        m.moves,
+		Counters:     len(m.counters),
+		MaxCounters:  m.maxCounters,
+		Evictions:    m.evictions,
+		SketchWidth:  width,
+		MemoryBudget: int64(width)*ngramSketchDepth*4 + int64(m.maxCounters)*ngramCounterBytes,
+	}
+}
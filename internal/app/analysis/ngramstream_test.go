@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+func mustParseMoves(t *testing.T, s string) []gocube.Move {
+	t.Helper()
+	moves, err := gocube.ParseMoves(s)
+	if err != nil {
+		t.Fatalf("ParseMoves(%q): %v", s, err)
+	}
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := range moves {
+		moves[i].Time = start.Add(time.Duration(i) * 300 * time.Millisecond)
+	}
+	return moves
+}
+
+// ngramCounts maps "n sequence" to count for each reported n-gram.
+func ngramCounts(report *NGramReport) map[string]int {
+	counts := make(map[string]int)
+	for _, ngrams := range report.TopNGrams {
+		for _, ng := range ngrams {
+			counts[strings.Join(ng.Sequence, " ")] = ng.Count
+		}
+	}
+	return counts
+}
+
+func TestNGramMinerMatchesMineNGrams(t *testing.T) {
+	// Sexy moves three times, a sledgehammer twice, filler in between
+	moves := mustParseMoves(t, "R U R' U' D2 R U R' U' F B R' F R F' L2 R U R' U' B' R' F R F' U2")
+
+	miner := NewNGramMiner(4, 8, 0)
+	miner.Add("s1", moves)
+	got := ngramCounts(miner.Report(1000))
+	want := ngramCounts(MineNGrams(moves, 4, 8, 1000))
+
+	if len(want) == 0 || want["R U R' U'"] != 3 || want["R' F R F'"] != 2 {
+		t.Fatalf("MineNGrams = %v, want the repeated triggers", want)
+	}
+	for seq, n := range want {
+		if got[seq] != n {
+			t.Errorf("%s: miner counted %d, MineNGrams %d", seq, got[seq], n)
+		}
+	}
+	for seq, n := range got {
+		if _, ok := want[seq]; !ok {
+			t.Errorf("%s: miner reported %d, MineNGrams nothing", seq, n)
+		}
+	}
+	if s := miner.Stats(); s.Evictions != 0 || s.Solves != 1 || s.Moves != len(moves) {
+		t.Errorf("Stats = %+v", s)
+	}
+
+	// Seen once in each of two solves is seen twice
+	miner = NewNGramMiner(4, 4, 0)
+	miner.Add("s1", mustParseMoves(t, "D R' F R F' L"))
+	miner.Add("s2", mustParseMoves(t, "B2 R' F R F'"))
+	top := miner.Report(10).TopNGrams[4]
+	if len(top) != 1 || strings.Join(top[0].Sequence, " ") != "R' F R F'" || top[0].Count != 2 || len(top[0].Occurrences) != 2 {
+		t.Errorf("across two solves = %+v, want R' F R F' twice", top)
+	}
+}
+
+func TestNGramMinerEvicts(t *testing.T) {
+	// A budget of 64 counters, far fewer than the distinct n-grams
+	rng := rand.New(rand.NewSource(1))
+	faces := []string{"R", "L", "U", "D", "F", "B"}
+	suffixes := []string{"", "'", "2"}
+	var seq []string
+	for i := 0; i < 40; i++ {
+		for j := 0; j < 30; j++ {
+			seq = append(seq, faces[rng.Intn(len(faces))]+suffixes[rng.Intn(len(suffixes))])
+		}
+		seq = append(seq, "R", "U", "R'", "U'", "R'", "F")
+	}
+	moves := mustParseMoves(t, strings.Join(seq, " "))
+
+	miner := NewNGramMiner(4, 6, 1)
+	miner.Add("s1", moves)
+	if s := miner.Stats(); s.Evictions == 0 || s.Counters > s.MaxCounters {
+		t.Fatalf("Stats = %+v, want evictions within %d counters", s, s.MaxCounters)
+	}
+
+	report := miner.Report(5)
+	exact := ngramCounts(MineNGrams(moves, 4, 6, 100000))
+	for n, ngrams := range report.TopNGrams {
+		for _, ng := range ngrams {
+			seq := strings.Join(ng.Sequence, " ")
+			if ng.Count < exact[seq] {
+				t.Errorf("%s counted %d, below the true %d", seq, ng.Count, exact[seq])
+			}
+		}
+		if n == 6 && (len(ngrams) == 0 || strings.Join(ngrams[0].Sequence, " ") != "R U R' U' R' F") {
+			t.Errorf("top 6-grams = %+v, want the inserted sequence first", ngrams)
+		}
+	}
+	if top := report.TopNGrams[6]; len(top) > 0 && top[0].Count < 40 {
+		t.Errorf("inserted sequence counted %d times, want at least 40", top[0].Count)
+	}
+}
@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// PhaseOptimality compares the moves spent on a phase with the fewest
+// that phase needed from the state it started in.
+type PhaseOptimality struct {
+	OptimalMoves int     `json:"optimal_moves"`
+	OptimalLine  string  `json:"optimal_line"`
+	Efficiency   float64 `json:"efficiency"` // Optimal / actual moves, 1 when optimal
+}
+
+// AnalyzePhaseOptimality returns how close the moves of a phase came to
+// optimal, given every move made before the phase started. Only the white
+// cross has an exact solver; other phases return nil, and their nearest
+// baseline is the solver line in the deep report. It also returns nil when
+// the phase has no moves.
+func AnalyzePhaseOptimality(phaseKey string, before, phase []gocube.Move, metric gocube.Metric) *PhaseOptimality {
+	if phaseKey != "white_cross" {
+		return nil
+	}
+	actual := gocube.CountMoves(phase, metric)
+	if actual == 0 {
+		return nil
+	}
+	line, optimal := gocube.OptimalCross(before, metric)
+	po := &PhaseOptimality{
+		OptimalMoves: optimal,
+		OptimalLine:  gocube.FormatMoves(line),
+		Efficiency:   float64(optimal) / float64(actual),
+	}
+	if po.Efficiency > 1 {
+		// Slice moves can beat an outer-turn optimum in STM
+		po.Efficiency = 1
+	}
+	return po
+}
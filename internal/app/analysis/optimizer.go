@@ -0,0 +1,99 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// LineSuggestion is a shorter move sequence with the same effect as a
+// phase's actual moves.
+type LineSuggestion struct {
+	OriginalMoves  int    `json:"original_moves"`
+	OptimizedMoves int    `json:"optimized_moves"`
+	Moves          string `json:"moves"`
+}
+
+// oppositeFace maps each face to the face across from it. Turns of opposite
+// faces commute, so U D and D U have the same effect.
+var oppositeFace = map[gocube.Face]gocube.Face{
+	gocube.FaceU: gocube.FaceD, gocube.FaceD: gocube.FaceU,
+	gocube.FaceF: gocube.FaceB, gocube.FaceB: gocube.FaceF,
+	gocube.FaceR: gocube.FaceL, gocube.FaceL: gocube.FaceR,
+}
+
+// ShortenLine rewrites moves into an equivalent sequence with cancellations
+// and merges applied, like OptimizeMoves, but also across a turn of the
+// opposite face: U D U' becomes D, and R L R becomes R2 L. Only opposite
+// face turns are commuted, so the result always has the same effect.
+func ShortenLine(moves []gocube.Move) []gocube.Move {
+	result := make([]gocube.Move, 0, len(moves))
+
+	for _, move := range moves {
+		// The move can combine with the last move, or with the one before
+		// it when the last move turns the opposite face
+		target := -1
+		if n := len(result); n > 0 {
+			switch {
+			case result[n-1].Face == move.Face:
+				target = n - 1
+			case n > 1 && result[n-1].Face == oppositeFace[move.Face] && result[n-2].Face == move.Face:
+				target = n - 2
+			}
+		}
+
+		if target < 0 {
+			result = append(result, move)
+			continue
+		}
+		if merged := mergeMoves(result[target], move); merged != nil {
+			result[target] = *merged
+		} else {
+			result = append(result[:target], result[target+1:]...)
+		}
+	}
+
+	return result
+}
+
+// SuggestShorterLine returns a shorter equivalent of a phase's moves, or nil
+// if ShortenLine finds nothing to remove.
+func SuggestShorterLine(moves []gocube.Move) *LineSuggestion {
+	shorter := ShortenLine(moves)
+	if len(shorter) >= len(moves) {
+		return nil
+	}
+
+	notations := make([]string, len(shorter))
+	for i, m := range shorter {
+		notations[i] = m.Notation()
+	}
+	return &LineSuggestion{
+		OriginalMoves:  len(moves),
+		OptimizedMoves: len(shorter),
+		Moves:          strings.Join(notations, " "),
+	}
+}
+
+// EquivalentLine returns the shortest line the solver finds with the same
+// effect on the whole cube as a phase's moves, or nil if it is no shorter.
+// Unlike SuggestShorterLine it works from the cube state, so it also finds
+// lines that share no moves with the original. It is slow; deep reports
+// only.
+func EquivalentLine(moves []gocube.Move) *LineSuggestion {
+	// The inverse of a solution of the phase's effect has the same effect
+	solution := gocube.SolveSequence(moves)
+	if len(solution) >= len(moves) {
+		return nil
+	}
+
+	notations := make([]string, len(solution))
+	for i, m := range solution {
+		notations[len(solution)-1-i] = m.Inverse().Notation()
+	}
+	return &LineSuggestion{
+		OriginalMoves:  len(moves),
+		OptimizedMoves: len(solution),
+		Moves:          strings.Join(notations, " "),
+	}
+}
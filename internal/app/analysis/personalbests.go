@@ -0,0 +1,218 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Personal best kinds, as stored.
+const (
+	PBSingle = "single"
+	PBAo5    = "ao5"
+	PBAo12   = "ao12"
+	PBPhase  = "phase" // Best split of one phase
+)
+
+// pbAverages maps the average kinds to their sizes.
+var pbAverages = []struct {
+	kind string
+	n    int
+}{{PBAo5, 5}, {PBAo12, 12}}
+
+// PersonalBestName returns a display name such as "PB ao5" or "Best White
+// Cross split".
+func PersonalBestName(kind, phaseKey string) string {
+	if kind == PBPhase {
+		return "Best " + storage.PhaseDisplayName(phaseKey) + " split"
+	}
+	return "PB " + kind
+}
+
+// PersonalBestStatus is where one kind of personal best stands: the best,
+// the latest value and their gap, so a slump shows as a growing gap.
+type PersonalBestStatus struct {
+	Kind     string
+	PhaseKey string
+	Best     storage.PersonalBest
+	Current  *float64 // Latest single, average or split; nil for a DNF average
+	Sets     int      // Times the best was improved, the first included
+}
+
+// GapMs returns how far the latest value is behind the best.
+func (s PersonalBestStatus) GapMs() (float64, bool) {
+	if s.Current == nil {
+		return 0, false
+	}
+	return *s.Current - s.Best.ValueMs, true
+}
+
+// pbTracker follows the running best of one kind through the solves.
+type pbTracker struct {
+	kind, phaseKey string
+	best           *storage.PersonalBest
+	current        *float64
+	sets           int
+}
+
+// offer records v as the latest value and returns the personal best it
+// sets, if any.
+func (t *pbTracker) offer(v float64, solveID string, at time.Time) *storage.PersonalBest {
+	t.current = &v
+	if t.best != nil && v >= t.best.ValueMs {
+		return nil
+	}
+	pb := &storage.PersonalBest{Kind: t.kind, PhaseKey: t.phaseKey, ValueMs: v, SolveID: solveID, AchievedAt: at}
+	if t.best != nil {
+		prev := t.best.ValueMs
+		pb.PreviousMs = &prev
+	}
+	t.best = pb
+	t.sets++
+	return pb
+}
+
+// EvaluatePersonalBests walks the finished solves in the order they ended
+// and returns every personal best they set, in order, and where each kind
+// stands now. Averages count a DNF as the slowest time; phase splits come
+// from the timed phases (IsTimedPhase) of solves with a time.
+func EvaluatePersonalBests(solveRepo *storage.SolveRepository, phaseRepo *storage.PhaseRepository) ([]storage.PersonalBest, []PersonalBestStatus, error) {
+	all, err := solveRepo.List(-1)
+	if err != nil {
+		return nil, nil, err
+	}
+	segments, err := phaseRepo.AllPhaseSegments()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var solves []storage.Solve
+	for _, s := range all {
+		if s.EndedAt != nil {
+			solves = append(solves, s)
+		}
+	}
+	sort.Slice(solves, func(i, j int) bool {
+		a, b := solves[i], solves[j]
+		if !a.EndedAt.Equal(*b.EndedAt) {
+			return a.EndedAt.Before(*b.EndedAt)
+		}
+		// Break ties the same way every time, so the history stays stable
+		if !a.StartedAt.Equal(b.StartedAt) {
+			return a.StartedAt.Before(b.StartedAt)
+		}
+		return a.SolveID < b.SolveID
+	})
+
+	trackers := map[string]*pbTracker{}
+	var order []string
+	tracker := func(kind, phaseKey string) *pbTracker {
+		key := kind + "/" + phaseKey
+		t, ok := trackers[key]
+		if !ok {
+			t = &pbTracker{kind: kind, phaseKey: phaseKey}
+			trackers[key] = t
+			order = append(order, key)
+		}
+		return t
+	}
+	// Keep the usual order however the first solves went
+	tracker(PBSingle, "")
+	for _, avg := range pbAverages {
+		tracker(avg.kind, "")
+	}
+
+	var history []storage.PersonalBest
+	var times []int64
+	for _, s := range solves {
+		at := *s.EndedAt
+		timed := s.DurationMs != nil && *s.DurationMs > 0
+		if timed {
+			times = append(times, *s.DurationMs)
+			if pb := tracker(PBSingle, "").offer(float64(*s.DurationMs), s.SolveID, at); pb != nil {
+				history = append(history, *pb)
+			}
+		} else {
+			times = append(times, DNF)
+		}
+
+		for _, avg := range pbAverages {
+			a, ok := AverageOf(times, avg.n)
+			if !ok {
+				continue
+			}
+			t := tracker(avg.kind, "")
+			if a.DNF {
+				t.current = nil
+				continue
+			}
+			if pb := t.offer(a.Ms, s.SolveID, at); pb != nil {
+				history = append(history, *pb)
+			}
+		}
+
+		if !timed {
+			continue
+		}
+		for _, seg := range segments[s.SolveID] {
+			if !storage.IsTimedPhase(seg.PhaseKey) || seg.DurationMs <= 0 {
+				continue
+			}
+			if pb := tracker(PBPhase, seg.PhaseKey).offer(float64(seg.DurationMs), s.SolveID, at); pb != nil {
+				history = append(history, *pb)
+			}
+		}
+	}
+
+	var statuses []PersonalBestStatus
+	for _, key := range order {
+		t := trackers[key]
+		if t.best == nil {
+			continue
+		}
+		statuses = append(statuses, PersonalBestStatus{
+			Kind: t.kind, PhaseKey: t.phaseKey, Best: *t.best, Current: t.current, Sets: t.sets,
+		})
+	}
+	return history, statuses, nil
+}
+
+// UpdatePersonalBests records the personal bests set by the stored solves
+// and returns those new to the history. When the history starts empty it
+// is filled silently, so upgrading does not announce every old best.
+func UpdatePersonalBests(solveRepo *storage.SolveRepository, phaseRepo *storage.PhaseRepository, pbRepo *storage.PersonalBestRepository) ([]storage.PersonalBest, error) {
+	stored, err := pbRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+	history, _, err := EvaluatePersonalBests(solveRepo, phaseRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []storage.PersonalBest
+	for _, pb := range history {
+		isNew, err := pbRepo.Record(pb)
+		if err != nil {
+			return nil, err
+		}
+		if isNew && stored > 0 {
+			added = append(added, pb)
+		}
+	}
+	return added, nil
+}
+
+// DescribePersonalBest formats a personal best for display, e.g. "PB ao5
+// 18.42s (-0.31s)".
+func DescribePersonalBest(pb storage.PersonalBest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %.2fs", PersonalBestName(pb.Kind, pb.PhaseKey), pb.ValueMs/1000)
+	if pb.PreviousMs != nil {
+		fmt.Fprintf(&b, " (-%.2fs)", (*pb.PreviousMs-pb.ValueMs)/1000)
+	}
+	return b.String()
+}
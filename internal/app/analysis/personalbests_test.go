@@ -0,0 +1,71 @@
+//go:build !js
+
+package analysis
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+func openTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	return db
+}
+
+func TestPersonalBestsSkipMarkerPhases(t *testing.T) {
+	db := openTestDB(t)
+	solveRepo := storage.NewSolveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	id, err := solveRepo.CreateAt(start, "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateAt: %v", err)
+	}
+	if err := solveRepo.EndAt(id, start.Add(9*time.Second)); err != nil {
+		t.Fatalf("EndAt: %v", err)
+	}
+
+	// A simulated solve: the complete mark a few milliseconds before the end
+	for _, seg := range []storage.PhaseSegment{
+		{PhaseKey: "scramble", StartTsMs: 0, EndTsMs: 3000, DurationMs: 3000},
+		{PhaseKey: "inspection", StartTsMs: 3000, EndTsMs: 5000, DurationMs: 2000},
+		{PhaseKey: "white_cross", StartTsMs: 5000, EndTsMs: 8995, DurationMs: 3995},
+		{PhaseKey: "complete", StartTsMs: 8995, EndTsMs: 9000, DurationMs: 5},
+	} {
+		seg.SolveID = id
+		if _, err := phaseRepo.CreatePhaseSegment(seg); err != nil {
+			t.Fatalf("CreatePhaseSegment(%s): %v", seg.PhaseKey, err)
+		}
+	}
+
+	history, statuses, err := EvaluatePersonalBests(solveRepo, phaseRepo)
+	if err != nil {
+		t.Fatalf("EvaluatePersonalBests: %v", err)
+	}
+	for _, pb := range history {
+		if pb.Kind == PBPhase && pb.PhaseKey != "white_cross" {
+			t.Errorf("personal best for marker phase %q: %s", pb.PhaseKey, DescribePersonalBest(pb))
+		}
+	}
+	var phases []string
+	for _, s := range statuses {
+		if s.Kind == PBPhase {
+			phases = append(phases, s.PhaseKey)
+		}
+	}
+	if len(phases) != 1 || phases[0] != "white_cross" {
+		t.Errorf("phase splits = %v, want only white_cross", phases)
+	}
+}
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// pllAlgorithms are standard algorithms for the 21 PLL cases, written for
+// the last layer on U. Slice and rotation moves are expanded to face turns.
+var pllAlgorithms = []struct{ name, moves string }{
+	{"Aa", "R' F R' B2 R F' R' B2 R2"},
+	{"Ab", "R2 B2 R F R' B2 R F' R"},
+	{"E", "R B' R' F R B R' F' R B R' F R B' R' F'"},
+	{"F", "R' U' F' R U R' U' R' F R2 U' R' U' R U R' U R"},
+	{"Ga", "R2 U R' U R' U' R U' R2 U' D R' U R D'"},
+	{"Gb", "R' U' R U D' R2 U R' U R U' R U' R2 D"},
+	{"Gc", "R2 U' R U' R U R' U R2 U D' R U' R' D"},
+	{"Gd", "R U R' U' D R2 U' R U' R' U R' U R2 D'"},
+	{"H", "R2 U2 R U2 R2 U2 R2 U2 R U2 R2"},
+	{"Ja", "L' U' L F L' U' L U L F' L2 U L"},
+	{"Jb", "R U R' F' R U R' U' R' F R2 U' R'"},
+	{"Na", "R U R' U R U R' F' R U R' U' R' F R2 U' R' U2 R U' R'"},
+	{"Nb", "R' U R U' R' F' U' F R U R' F R' F' R U' R"},
+	{"Ra", "R U' R' U' R U R D R' U' R D' R' U2 R'"},
+	{"Rb", "R2 F R U R U' R' F' R U2 R' U2 R"},
+	{"T", "R U R' U' R' F R2 U' R' U' R U R' F'"},
+	{"Ua", "R U' R U R U R U' R' U' R2"},
+	{"Ub", "R2 U R U R' U' R' U' R' U R'"},
+	{"V", "R' U R' U' B' R' B2 U' B' U B' R B R"},
+	{"Y", "F R U' R' U' R U R' F' R U R' U' R' F R F'"},
+	{"Z", "R' U' R U' R U R U' R' U R U R2 U' R'"},
+}
+
+// PLLCases returns the names of the 21 PLL cases.
+func PLLCases() []string {
+	names := make([]string, len(pllAlgorithms))
+	for i, a := range pllAlgorithms {
+		names[i] = a.name
+	}
+	return names
+}
+
+// pllFlip turns an algorithm for the last layer on U over with z2, for the
+// last layer on D.
+var pllFlip = strings.NewReplacer("U", "D", "D", "U", "R", "L", "L", "R")
+
+var (
+	pllOnce  sync.Once
+	pllTable map[[6][9]gocube.Color]string
+)
+
+// buildPLLTable maps every state that one PLL case, with any adjustment of
+// the last layer before and after it, leaves on a cube with the first two
+// layers solved to that case's name. The solver's last layer is D (yellow),
+// so the algorithms are turned over with z2 (U and D, R and L swapped).
+func buildPLLTable() {
+	pllTable = make(map[[6][9]gocube.Color]string)
+	auf := gocube.Move{Face: gocube.FaceD, Turn: gocube.CW}
+
+	for _, a := range pllAlgorithms {
+		moves, err := gocube.ParseMoves(pllFlip.Replace(a.moves))
+		if err != nil {
+			panic("analysis: invalid PLL algorithm " + a.name)
+		}
+		inverse := make([]gocube.Move, len(moves))
+		for i, m := range moves {
+			inverse[len(moves)-1-i] = m.Inverse()
+		}
+
+		for pre := 0; pre < 4; pre++ {
+			for post := 0; post < 4; post++ {
+				c := gocube.NewCube()
+				for i := 0; i < pre; i++ {
+					c.Apply(auf)
+				}
+				c.Apply(inverse...)
+				for i := 0; i < post; i++ {
+					c.Apply(auf)
+				}
+				pllTable[c.Facelets] = a.name
+			}
+		}
+	}
+}
+
+// RecognizePLL returns the PLL case of a cube whose first two layers are
+// solved and whose last layer is oriented, or false for any other state
+// (including a solved last layer).
+func RecognizePLL(c *gocube.Cube) (string, bool) {
+	pllOnce.Do(buildPLLTable)
+	name, ok := pllTable[c.Facelets]
+	return name, ok
+}
@@ -0,0 +1,189 @@
+package analysis
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// QuizKind is the kind of a quiz question.
+type QuizKind string
+
+const (
+	// QuizNextMove asks for the next step from a state, as Hint gives it.
+	QuizNextMove QuizKind = "next_move"
+
+	// QuizPLL asks which PLL case a state is.
+	QuizPLL QuizKind = "pll"
+)
+
+// quizChoices is how many answers a question offers, the right one
+// included.
+const quizChoices = 4
+
+// Quiz is a set of flashcard questions on states from the solver's own
+// solves.
+type Quiz struct {
+	GeneratedAt string         `json:"generated_at"`
+	Questions   []QuizQuestion `json:"questions"`
+}
+
+// QuizQuestion is one multiple-choice question on a cube state.
+type QuizQuestion struct {
+	Kind        QuizKind           `json:"kind"`
+	Prompt      string             `json:"prompt"`
+	State       [6][9]gocube.Color `json:"state"` // Facelets, as gocube.Cube holds them
+	Choices     []string           `json:"choices"`
+	Answer      int                `json:"answer"` // Index into Choices
+	Explanation string             `json:"explanation"`
+
+	// Where the state comes from: the solve, the moves made before it and
+	// how long the solver paused there.
+	SolveID   string `json:"solve_id"`
+	MoveIndex int    `json:"move_index"`
+	PauseMs   int64  `json:"pause_ms"`
+	YourMoves string `json:"your_moves,omitempty"` // What the solver did next
+}
+
+// Cube returns the question's cube state.
+func (q *QuizQuestion) Cube() *gocube.Cube {
+	return &gocube.Cube{Facelets: q.State}
+}
+
+// QuizPause is a pause before a move of a solve.
+type QuizPause struct {
+	MoveIndex int   // The move after the pause, so the state is after MoveIndex moves
+	PauseMs   int64 // Time since the previous move
+}
+
+// LongestPauses returns the n longest pauses of at least minPauseMs between
+// moves, longest first. records are the moves of one solve in order.
+func LongestPauses(records []storage.MoveRecord, minPauseMs int64, n int) []QuizPause {
+	var pauses []QuizPause
+	for i := 1; i < len(records); i++ {
+		gap := records[i].TsMs - records[i-1].TsMs
+		if gap >= minPauseMs {
+			pauses = append(pauses, QuizPause{MoveIndex: records[i].MoveIndex, PauseMs: gap})
+		}
+	}
+	sort.SliceStable(pauses, func(i, j int) bool {
+		return pauses[i].PauseMs > pauses[j].PauseMs
+	})
+	if len(pauses) > n {
+		pauses = pauses[:n]
+	}
+	return pauses
+}
+
+// NewQuizQuestion makes a question on the state c a solver paused at: which
+// PLL case it is, if it is one, or else the next step towards solving it.
+// next are the moves the solver made after the pause. It returns false for
+// states with nothing to ask, like a solved cube. rng shuffles the choices.
+func NewQuizQuestion(c *gocube.Cube, next []gocube.Move, pause QuizPause, solveID string, rng *rand.Rand) (QuizQuestion, bool) {
+	q := QuizQuestion{
+		State:     c.Facelets,
+		SolveID:   solveID,
+		MoveIndex: pause.MoveIndex,
+		PauseMs:   pause.PauseMs,
+	}
+
+	if name, ok := RecognizePLL(c); ok {
+		q.Kind = QuizPLL
+		q.Prompt = "Which PLL case is this?"
+		q.Choices = pllDistractors(name, rng)
+		alg := pllAlgorithm(name)
+		q.Explanation = fmt.Sprintf("%s perm, with yellow on D: %s", name, alg)
+		q.YourMoves = gocube.FormatMoves(firstTurns(next, len(strings.Fields(alg))))
+	} else {
+		hint, err := c.Hint(gocube.MethodLayerByLayer)
+		if err != nil || len(hint.Moves) == 0 {
+			return QuizQuestion{}, false
+		}
+		yours := firstTurns(next, len(hint.Moves))
+		q.Kind = QuizNextMove
+		q.Prompt = fmt.Sprintf("What's the next step? (%s)", hint.Phase.DisplayName())
+		q.Choices = append([]string{gocube.FormatMoves(hint.Moves)}, moveDistractors(hint.Moves, yours)...)
+		q.Explanation = hint.Explanation
+		q.YourMoves = gocube.FormatMoves(yours)
+	}
+
+	// The right answer is first until shuffled
+	order := rng.Perm(len(q.Choices))
+	shuffled := make([]string, len(q.Choices))
+	for i, j := range order {
+		shuffled[i] = q.Choices[j]
+		if j == 0 {
+			q.Answer = i
+		}
+	}
+	q.Choices = shuffled
+	return q, true
+}
+
+// firstTurns returns the first n turns of moves, with turns of the same
+// face merged as in a reconstruction.
+func firstTurns(moves []gocube.Move, n int) []gocube.Move {
+	merged := mergeTurns(moves)
+	if len(merged) > n {
+		merged = merged[:n]
+	}
+	return merged
+}
+
+// pllDistractors returns name followed by other PLL cases picked at random.
+func pllDistractors(name string, rng *rand.Rand) []string {
+	choices := []string{name}
+	for _, i := range rng.Perm(len(pllAlgorithms)) {
+		if len(choices) == quizChoices {
+			break
+		}
+		if other := pllAlgorithms[i].name; other != name {
+			choices = append(choices, other)
+		}
+	}
+	return choices
+}
+
+// pllAlgorithm returns the algorithm for a PLL case turned over for the
+// last layer on D, as RecognizePLL sees it.
+func pllAlgorithm(name string) string {
+	for _, a := range pllAlgorithms {
+		if a.name == name {
+			return pllFlip.Replace(a.moves)
+		}
+	}
+	return ""
+}
+
+// moveDistractors returns wrong answers that look like the step moves: the
+// moves the solver actually made, the step undone, the step with every turn
+// reversed, and the step on the opposite side.
+func moveDistractors(moves, yours []gocube.Move) []string {
+	undone := make([]gocube.Move, len(moves))
+	reversed := make([]gocube.Move, len(moves))
+	mirrored := make([]gocube.Move, len(moves))
+	for i, m := range moves {
+		undone[len(moves)-1-i] = m.Inverse()
+		reversed[i] = m.Inverse()
+		mirrored[i] = m
+		if face, ok := oppositeFace[m.Face]; ok {
+			mirrored[i].Face = face
+		}
+	}
+
+	seen := map[string]bool{gocube.FormatMoves(moves): true}
+	var distractors []string
+	for _, candidate := range [][]gocube.Move{yours, undone, reversed, mirrored} {
+		s := gocube.FormatMoves(candidate)
+		if s == "" || seen[s] || len(distractors) == quizChoices-1 {
+			continue
+		}
+		seen[s] = true
+		distractors = append(distractors, s)
+	}
+	return distractors
+}
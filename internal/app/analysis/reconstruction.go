@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/notation"
+)
+
+// ReconstructionLine is one line of an annotated reconstruction: a known
+// algorithm, an AUF, or the moves between them, which have no label.
+type ReconstructionLine struct {
+	Moves      string `json:"moves"`              // Same-face turns merged, e.g. "R U2 R'"
+	Label      string `json:"label,omitempty"`    // e.g. "T-perm", "OLL 27", "AUF"
+	Category   string `json:"category,omitempty"` // One of the Category constants
+	StartIndex int    `json:"start_index"`        // First stored move
+	EndIndex   int    `json:"end_index"`          // Last stored move
+}
+
+// Reconstruction is a solve written out line by line with the algorithms
+// in it labeled.
+type Reconstruction struct {
+	Lines           []ReconstructionLine `json:"lines"`
+	Recognized      int                  `json:"recognized"`       // Lines labeled with an algorithm
+	RecognizedMoves int                  `json:"recognized_moves"` // Stored moves in those lines
+	TotalMoves      int                  `json:"total_moves"`
+}
+
+// String formats the reconstruction one line per entry, with labels as
+// comments: "R U R' U' R' F R2 U' R' U' R U R' F' // T-perm".
+func (r *Reconstruction) String() string {
+	var b strings.Builder
+	for _, line := range r.Lines {
+		b.WriteString(line.Moves)
+		if line.Label != "" {
+			b.WriteString(" // ")
+			b.WriteString(line.Label)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// AnnotateReconstruction labels the PLL, OLL and F2L algorithms and final
+// phase tools in moves. Algorithms are recognized in any y orientation and
+// with the last layer on U or D; where two overlap, the longer wins. A turn
+// of the last layer next to an OLL or PLL is labeled as an AUF.
+//
+// Recognition is by sequence, so an algorithm is found only as written in
+// the database (or the custom library): a different algorithm for the same
+// case, or one interrupted by a regrip turn, is left unlabeled.
+func AnnotateReconstruction(moves []gocube.Move) *Reconstruction {
+	algDBOnce.Do(buildAlgorithmDB)
+
+	display := notation.DisplayMoves(moves, true)
+	merged := make([]gocube.Move, len(display))
+	for i, d := range display {
+		merged[i] = d.Move
+	}
+
+	// Final phase tools are matched as written, ahead of database
+	// algorithms of the same length, so the user's names are kept.
+	var tools []knownAlgorithm
+	for _, t := range Tools() {
+		if len(t.Sequence) > 0 {
+			tools = append(tools, knownAlgorithm{name: t.Name, category: CategoryTool, moves: mergeTurns(t.Sequence)})
+		}
+	}
+
+	r := &Reconstruction{Lines: []ReconstructionLine{}, TotalMoves: len(moves)}
+	addLine := func(from, to int, label, category string) {
+		if from >= to {
+			return
+		}
+		r.Lines = append(r.Lines, ReconstructionLine{
+			Moves:      movesKey(merged[from:to]),
+			Label:      label,
+			Category:   category,
+			StartIndex: display[from].First,
+			EndIndex:   display[to-1].First + display[to-1].Count - 1,
+		})
+		if label != "" && category != CategoryAUF {
+			r.Recognized++
+			r.RecognizedMoves += display[to-1].First + display[to-1].Count - display[from].First
+		}
+	}
+
+	var lastLayer gocube.Face // Set after an OLL or PLL, for the AUF after it
+	pending := 0              // Start of the unlabeled moves before i
+	for i := 0; i < len(merged); {
+		alg, ok := matchAlgorithm(merged, i, tools)
+		if !ok {
+			i++
+			continue
+		}
+
+		// An AUF may follow the previous algorithm and precede this one.
+		from, to := pending, i
+		if lastLayer != "" && from < to && merged[from].Face == lastLayer {
+			addLine(from, from+1, "AUF", CategoryAUF)
+			from++
+		}
+		var aufBefore bool
+		if isLastLayer(alg) && from < to && merged[to-1].Face == alg.auf {
+			to--
+			aufBefore = true
+		}
+		addLine(from, to, "", "")
+		if aufBefore {
+			addLine(to, to+1, "AUF", CategoryAUF)
+		}
+
+		addLine(i, i+len(alg.moves), alg.name, alg.category)
+		i += len(alg.moves)
+		pending = i
+		lastLayer = ""
+		if isLastLayer(alg) {
+			lastLayer = alg.auf
+		}
+	}
+
+	from := pending
+	if lastLayer != "" && from < len(merged) && merged[from].Face == lastLayer {
+		addLine(from, from+1, "AUF", CategoryAUF)
+		from++
+	}
+	addLine(from, len(merged), "", "")
+	return r
+}
+
+// matchAlgorithm returns the longest known algorithm starting at moves[i].
+func matchAlgorithm(moves []gocube.Move, i int, tools []knownAlgorithm) (knownAlgorithm, bool) {
+	var best knownAlgorithm
+	for _, t := range tools {
+		if len(t.moves) > len(best.moves) && matchesTool(moves, i, t.moves) {
+			best = t
+		}
+	}
+	for _, a := range algDB {
+		if len(a.moves) <= len(best.moves) {
+			break // Sorted longest first
+		}
+		if matchesTool(moves, i, a.moves) {
+			return a, true
+		}
+	}
+	return best, len(best.moves) > 0
+}
+
+// isLastLayer reports whether an algorithm is adjusted for with AUFs.
+func isLastLayer(a knownAlgorithm) bool {
+	return a.category == CategoryOLL || a.category == CategoryPLL
+}
@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+)
+
+// setDownMaxDegrees is how far the cube may turn from where it settled and
+// still count as lying still. In the hand it wobbles by more than this
+// even while the solver pauses.
+const setDownMaxDegrees = 1.0
+
+// OrientationSample is one orientation report from the cube, as the raw
+// quaternion.
+type OrientationSample struct {
+	TsMs       int64
+	X, Y, Z, W float64
+}
+
+// SetDown is an interval during a solve when the cube was put down: it lay
+// still, without moves, from StartMs until it was picked up at EndMs.
+type SetDown struct {
+	StartMs    int64 `json:"start_ms"`
+	EndMs      int64 `json:"end_ms"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// DetectSetDowns finds the intervals between startMs and endMs where the
+// cube lay still for at least minMs. The cube is put down once its
+// orientation stops changing and picked up at the first sample that turns
+// away from there or the next move, whichever comes first. moveTimesMs are
+// the times of the moves in the window.
+//
+// Cubes that report no orientation, like GAN and MoYu cubes, give no
+// samples and so never count as put down.
+func DetectSetDowns(samples []OrientationSample, moveTimesMs []int64, startMs, endMs, minMs int64) []SetDown {
+	var window []OrientationSample
+	for _, s := range samples {
+		if s.TsMs >= startMs && s.TsMs <= endMs {
+			window = append(window, s)
+		}
+	}
+	sort.Slice(window, func(i, j int) bool { return window[i].TsMs < window[j].TsMs })
+	moves := append([]int64(nil), moveTimesMs...)
+	sort.Slice(moves, func(i, j int) bool { return moves[i] < moves[j] })
+
+	setDowns := []SetDown{}
+	for i := 0; i < len(window); {
+		settled := window[i]
+
+		// Picked up at the next move after settling, or earlier at the
+		// first sample turned away from the settled orientation.
+		pickup := endMs
+		if m := sort.Search(len(moves), func(k int) bool { return moves[k] > settled.TsMs }); m < len(moves) && moves[m] < pickup {
+			pickup = moves[m]
+		}
+		j := i + 1
+		for ; j < len(window) && window[j].TsMs < pickup; j++ {
+			if quaternionDegrees(settled, window[j]) > setDownMaxDegrees {
+				pickup = window[j].TsMs
+				break
+			}
+		}
+
+		if pickup-settled.TsMs >= minMs {
+			setDowns = append(setDowns, SetDown{StartMs: settled.TsMs, EndMs: pickup, DurationMs: pickup - settled.TsMs})
+		}
+
+		// Settle again from the sample that ended this interval
+		for j < len(window) && window[j].TsMs < pickup {
+			j++
+		}
+		i = j
+	}
+	return setDowns
+}
+
+// SetDownTotalMs returns the total duration of setDowns.
+func SetDownTotalMs(setDowns []SetDown) int64 {
+	var total int64
+	for _, s := range setDowns {
+		total += s.DurationMs
+	}
+	return total
+}
+
+// quaternionDegrees returns the angle between two orientations.
+func quaternionDegrees(a, b OrientationSample) float64 {
+	na := math.Sqrt(a.X*a.X + a.Y*a.Y + a.Z*a.Z + a.W*a.W)
+	nb := math.Sqrt(b.X*b.X + b.Y*b.Y + b.Z*b.Z + b.W*b.W)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	dot := math.Abs(a.X*b.X+a.Y*b.Y+a.Z*b.Z+a.W*b.W) / (na * nb)
+	return 2 * math.Acos(math.Min(dot, 1)) * 180 / math.Pi
+}
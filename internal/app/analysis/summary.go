@@ -44,7 +44,7 @@ func AnalyzePauses(moves []gocube.Move, thresholdMs int64) []PauseInfo {
 	var pauses []PauseInfo
 
 	for i := 1; i < len(moves); i++ {
-		gap := moves[i].Time.UnixMilli() - moves[i-1].Time.UnixMilli()
+		gap := moves[i].Time.Sub(moves[i-1].Time).Milliseconds()
 		if gap >= thresholdMs {
 			pauses = append(pauses, PauseInfo{
 				AfterMoveIndex: i - 1,
@@ -71,8 +71,8 @@ func CalculateAvgMoveDuration(moves []gocube.Move) float64 {
 		return 0
 	}
 
-	totalGap := moves[len(moves)-1].Time.UnixMilli() - moves[0].Time.UnixMilli()
-	return float64(totalGap) / float64(len(moves)-1)
+	totalGap := moves[len(moves)-1].Time.Sub(moves[0].Time)
+	return float64(totalGap.Microseconds()) / 1000 / float64(len(moves)-1)
 }
 
 // FindLongestPause finds the longest pause in a move sequence.
@@ -80,7 +80,7 @@ func FindLongestPause(moves []gocube.Move) int64 {
 	var longest int64
 
 	for i := 1; i < len(moves); i++ {
-		gap := moves[i].Time.UnixMilli() - moves[i-1].Time.UnixMilli()
+		gap := moves[i].Time.Sub(moves[i-1].Time).Milliseconds()
 		if gap > longest {
 			longest = gap
 		}
@@ -93,7 +93,7 @@ func FindLongestPause(moves []gocube.Move) int64 {
 func CountPausesOver(moves []gocube.Move, thresholdMs int64) int {
 	count := 0
 	for i := 1; i < len(moves); i++ {
-		gap := moves[i].Time.UnixMilli() - moves[i-1].Time.UnixMilli()
+		gap := moves[i].Time.Sub(moves[i-1].Time).Milliseconds()
 		if gap > thresholdMs {
 			count++
 		}
@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SuperPhase groups consecutive phases for reporting, e.g. "f2l" covering
+// white_cross, top_corners and middle_layer.
+type SuperPhase struct {
+	Key         string   `json:"key"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Phases      []string `json:"phases"`
+}
+
+// Name returns the display name, falling back to the key.
+func (sp SuperPhase) Name() string {
+	if sp.DisplayName != "" {
+		return sp.DisplayName
+	}
+	return sp.Key
+}
+
+// Contains returns true if the super-phase includes phaseKey.
+func (sp SuperPhase) Contains(phaseKey string) bool {
+	for _, p := range sp.Phases {
+		if p == phaseKey {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSuperPhases checks that keys are unique and that each phase
+// belongs to at most one super-phase.
+func ValidateSuperPhases(supers []SuperPhase) error {
+	keys := make(map[string]bool)
+	owner := make(map[string]string)
+	for _, sp := range supers {
+		if sp.Key == "" {
+			return fmt.Errorf("super-phase key is required")
+		}
+		if keys[sp.Key] {
+			return fmt.Errorf("duplicate super-phase key %q", sp.Key)
+		}
+		keys[sp.Key] = true
+
+		if len(sp.Phases) == 0 {
+			return fmt.Errorf("super-phase %q has no phases", sp.Key)
+		}
+		for _, p := range sp.Phases {
+			if other, ok := owner[p]; ok {
+				return fmt.Errorf("phase %q is in both %q and %q", p, other, sp.Key)
+			}
+			owner[p] = sp.Key
+		}
+	}
+	return nil
+}
+
+// FindSuperPhase returns the super-phase containing phaseKey, if any.
+func FindSuperPhase(supers []SuperPhase, phaseKey string) (SuperPhase, bool) {
+	for _, sp := range supers {
+		if sp.Contains(phaseKey) {
+			return sp, true
+		}
+	}
+	return SuperPhase{}, false
+}
+
+// AggregateSuperPhases sums per-phase data into super-phases. Super-phases
+// with no member phases present are omitted.
+func AggregateSuperPhases(phaseData map[string]PhaseData, supers []SuperPhase) map[string]PhaseData {
+	result := make(map[string]PhaseData)
+	for _, sp := range supers {
+		var agg PhaseData
+		found := false
+		for _, p := range sp.Phases {
+			if d, ok := phaseData[p]; ok {
+				agg.DurationMs += d.DurationMs
+				agg.MoveCount += d.MoveCount
+				found = true
+			}
+		}
+		if !found {
+			continue
+		}
+		if agg.DurationMs > 0 {
+			agg.TPS = float64(agg.MoveCount) / (float64(agg.DurationMs) / 1000.0)
+		}
+		result[sp.Key] = agg
+	}
+	return result
+}
+
+// AnalyzeSuperPhaseTrends computes phase trends aggregated by super-phase.
+func AnalyzeSuperPhaseTrends(solves []SolveData, supers []SuperPhase) map[string]PhaseTrend {
+	if len(supers) == 0 {
+		return nil
+	}
+
+	aggregated := make([]SolveData, 0, len(solves))
+	for _, s := range solves {
+		if s.DurationMs <= 0 {
+			continue
+		}
+		s.PhaseData = AggregateSuperPhases(s.PhaseData, supers)
+		aggregated = append(aggregated, s)
+	}
+
+	sort.Slice(aggregated, func(i, j int) bool {
+		return aggregated[i].StartedAt.Before(aggregated[j].StartedAt)
+	})
+
+	return analyzePhasetrends(aggregated)
+}
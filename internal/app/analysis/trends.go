@@ -13,6 +13,16 @@ type SolveData struct {
 	MoveCount  int
 	TPS        float64
 	PhaseData  map[string]PhaseData
+	Manual     bool          // Manually timed; no moves or phases
+	Effort     *EffortReport // Physical effort estimate; nil if unknown
+
+	PauseCount     int   // Gaps over 1500ms between solving moves
+	LongestPauseMs int64 // Longest gap between solving moves
+
+	MirroredAlgorithms []MirroredAttempt // Mirrored or inverted algorithms corrected
+
+	SetDownMs         int64 // Time the cube was put down mid-solve
+	FocusedDurationMs int64 // DurationMs less SetDownMs; 0 if not reported
 }
 
 // PhaseData represents phase data for a single solve.
@@ -27,6 +37,7 @@ type TrendReport struct {
 	WindowSize       int              `json:"window_size"`
 	TotalSolves      int              `json:"total_solves"`
 	CompletedSolves  int              `json:"completed_solves"`
+	ManualSolves     int              `json:"manual_solves"`
 	DateRange        DateRange        `json:"date_range"`
 
 	// Overall trends
@@ -34,6 +45,11 @@ type TrendReport struct {
 	AvgMoves         float64          `json:"avg_moves"`
 	AvgTPS           float64          `json:"avg_tps"`
 
+	// Effort (economy of motion), over solves with an estimate
+	AvgEffort        float64          `json:"avg_effort,omitempty"`
+	AvgEffortPerTurn float64          `json:"avg_effort_per_turn,omitempty"`
+	EffortImprovePct float64          `json:"effort_improvement_pct,omitempty"`
+
 	// Best/worst
 	BestSolve        SolveStats       `json:"best_solve"`
 	WorstSolve       SolveStats       `json:"worst_solve"`
@@ -45,9 +61,20 @@ type TrendReport struct {
 	// Per-phase trends
 	PhaseTrends      map[string]PhaseTrend `json:"phase_trends"`
 
+	// Per-super-phase trends (set by callers with super-phases configured)
+	SuperPhaseTrends map[string]PhaseTrend `json:"super_phase_trends,omitempty"`
+
 	// Rolling averages (last 5, 10, 25, 50)
 	RollingAvgs      map[int]float64  `json:"rolling_averages"`
 
+	// Focused time: solve time less the time the cube was put down, over
+	// all solves (set only when some solve was put down)
+	AvgFocusedDurationMs float64 `json:"avg_focused_duration_ms,omitempty"`
+	SetDownSolves        int     `json:"set_down_solves,omitempty"` // Solves with the cube put down
+
+	// Mirrored or inverted OLLs and PLLs corrected, per case
+	MirroredAlgorithms []MirrorCaseCount `json:"mirrored_algorithms,omitempty"`
+
 	// Solve list
 	Solves           []SolveStats     `json:"solves"`
 }
@@ -65,6 +92,8 @@ type SolveStats struct {
 	DurationMs int64   `json:"duration_ms"`
 	MoveCount  int     `json:"move_count"`
 	TPS        float64 `json:"tps"`
+	Effort     float64 `json:"effort,omitempty"`
+	Manual     bool    `json:"manual,omitempty"`
 }
 
 // PhaseTrend represents trends for a specific phase.
@@ -108,6 +137,10 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 	var bestSolve, worstSolve *SolveData
 
 	completedSolves := []SolveData{}
+	var mirrored []MirroredAttempt
+	var focusedDuration int64
+	focusedSolves := 0 // Solves with a focused time below their duration
+	smartSolves := 0 // Solves with a move stream
 
 	for i := range solves {
 		s := &solves[i]
@@ -117,16 +150,36 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 
 		completedSolves = append(completedSolves, *s)
 		totalDuration += s.DurationMs
-		totalMoves += int64(s.MoveCount)
-		totalTPS += s.TPS
+		if s.Manual {
+			report.ManualSolves++
+		} else {
+			smartSolves++
+			totalMoves += int64(s.MoveCount)
+			totalTPS += s.TPS
+		}
 
-		report.Solves = append(report.Solves, SolveStats{
+		stats := SolveStats{
 			SolveID:    s.SolveID,
 			Timestamp:  s.StartedAt.Format(time.RFC3339),
 			DurationMs: s.DurationMs,
 			MoveCount:  s.MoveCount,
 			TPS:        s.TPS,
-		})
+			Manual:     s.Manual,
+		}
+		if s.Effort != nil {
+			stats.Effort = s.Effort.Score
+		}
+		report.Solves = append(report.Solves, stats)
+		if s.SetDownMs > 0 {
+			report.SetDownSolves++
+		}
+		if s.FocusedDurationMs > 0 {
+			focusedDuration += s.FocusedDurationMs
+			focusedSolves++
+		} else {
+			focusedDuration += s.DurationMs
+		}
+		mirrored = append(mirrored, s.MirroredAlgorithms...)
 
 		if bestDuration < 0 || s.DurationMs < bestDuration {
 			bestDuration = s.DurationMs
@@ -139,11 +192,19 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 	}
 
 	report.CompletedSolves = len(completedSolves)
+	if focusedSolves > 0 {
+		report.AvgFocusedDurationMs = float64(focusedDuration) / float64(len(completedSolves))
+	}
+	if len(mirrored) > 0 {
+		report.MirroredAlgorithms = CountMirroredAlgorithms(mirrored)
+	}
 
 	if len(completedSolves) > 0 {
 		report.AvgDurationMs = float64(totalDuration) / float64(len(completedSolves))
-		report.AvgMoves = float64(totalMoves) / float64(len(completedSolves))
-		report.AvgTPS = totalTPS / float64(len(completedSolves))
+		if smartSolves > 0 {
+			report.AvgMoves = float64(totalMoves) / float64(smartSolves)
+			report.AvgTPS = totalTPS / float64(smartSolves)
+		}
 
 		if bestSolve != nil {
 			report.BestSolve = SolveStats{
@@ -152,6 +213,7 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 				DurationMs: bestSolve.DurationMs,
 				MoveCount:  bestSolve.MoveCount,
 				TPS:        bestSolve.TPS,
+				Manual:     bestSolve.Manual,
 			}
 		}
 
@@ -162,6 +224,7 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 				DurationMs: worstSolve.DurationMs,
 				MoveCount:  worstSolve.MoveCount,
 				TPS:        worstSolve.TPS,
+				Manual:     worstSolve.Manual,
 			}
 		}
 	}
@@ -187,6 +250,9 @@ func AnalyzeTrends(solves []SolveData) *TrendReport {
 	// Phase trends
 	report.PhaseTrends = analyzePhasetrends(completedSolves)
 
+	// Effort trends
+	report.AvgEffort, report.AvgEffortPerTurn, report.EffortImprovePct = analyzeEffortTrend(completedSolves)
+
 	return report
 }
 
@@ -326,3 +392,40 @@ func analyzePhasetrends(solves []SolveData) map[string]PhaseTrend {
 
 	return trends
 }
+
+// analyzeEffortTrend averages effort over solves with an estimate and
+// compares the first quarter to the last (positive = less effort).
+func analyzeEffortTrend(solves []SolveData) (avg, avgPerTurn, improvementPct float64) {
+	var efforts []EffortReport
+	for _, s := range solves {
+		if s.Effort != nil && s.Effort.Turns > 0 {
+			efforts = append(efforts, *s.Effort)
+		}
+	}
+	if len(efforts) == 0 {
+		return 0, 0, 0
+	}
+
+	for _, e := range efforts {
+		avg += e.Score
+		avgPerTurn += e.PerTurn
+	}
+	avg /= float64(len(efforts))
+	avgPerTurn /= float64(len(efforts))
+
+	if len(efforts) >= 4 {
+		quarterSize := len(efforts) / 4
+		var firstSum, lastSum float64
+		for i := 0; i < quarterSize; i++ {
+			firstSum += efforts[i].Score
+		}
+		for i := len(efforts) - quarterSize; i < len(efforts); i++ {
+			lastSum += efforts[i].Score
+		}
+		if firstSum > 0 {
+			improvementPct = (firstSum - lastSum) / firstSum * 100
+		}
+	}
+
+	return avg, avgPerTurn, improvementPct
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Turn speed profiling limits.
+const (
+	// turnSpeedMaxGapUs excludes gaps that are recognition pauses rather
+	// than turn execution.
+	turnSpeedMaxGapUs = 1_000_000
+	// turnSpeedMinSamples is the fewest timed turns needed to rank a turn.
+	turnSpeedMinSamples = 10
+	// turnSpeedSlowPct is how much slower than the overall average a turn
+	// must be to get a practice recommendation.
+	turnSpeedSlowPct = 15.0
+	// turnSpeedMaxTips caps the number of recommendations.
+	turnSpeedMaxTips = 3
+)
+
+// fingerTricks suggests a drill for each face and direction.
+var fingerTricks = map[string]string{
+	"U":  "right index finger flicks",
+	"U'": "left index finger flicks",
+	"U2": "double flicks (right index then middle finger)",
+	"D":  "left ring finger pulls",
+	"D'": "right ring finger pulls",
+	"D2": "double ring finger pulls",
+	"R":  "right wrist turns without regripping",
+	"R'": "right wrist turns without regripping",
+	"R2": "right wrist double turns",
+	"L":  "left wrist turns without regripping",
+	"L'": "left wrist turns without regripping",
+	"L2": "left wrist double turns",
+	"F":  "right thumb pushes or index flicks after an x' grip",
+	"F'": "left index finger flicks",
+	"F2": "double thumb pushes",
+	"B":  "right ring finger flicks from the back",
+	"B'": "left ring finger flicks from the back",
+	"B2": "double ring finger flicks from the back",
+}
+
+// TurnSpeed is the average execution time of one face and direction.
+type TurnSpeed struct {
+	Notation     string  `json:"notation"`
+	Count        int     `json:"count"`
+	AvgMs        float64 `json:"avg_ms"`
+	VsOverallPct float64 `json:"vs_overall_pct"` // +25 = 25% slower than the average turn
+}
+
+// TurnSpeedReport profiles turn execution speed across solves.
+type TurnSpeedReport struct {
+	Solves          int         `json:"solves"`
+	TimedTurns      int         `json:"timed_turns"`
+	OverallAvgMs    float64     `json:"overall_avg_ms"`
+	Turns           []TurnSpeed `json:"turns"`   // Slowest first; turns with too few samples last
+	Slowest         []TurnSpeed `json:"slowest"` // Ranked turns notably slower than average
+	Recommendations []string    `json:"recommendations,omitempty"`
+}
+
+// ProfileTurnSpeed measures how long each face and direction takes to
+// execute, as the time from the previous move to this one. Each element of
+// solves is the solving window of one solve. Gaps over a second are
+// recognition pauses and are ignored, as are zero gaps (moves delivered in
+// the same BLE notification).
+func ProfileTurnSpeed(solves [][]storage.MoveRecord) *TurnSpeedReport {
+	report := &TurnSpeedReport{}
+
+	totals := make(map[string]int64)
+	counts := make(map[string]int)
+	var totalUs int64
+
+	for _, moves := range solves {
+		if len(moves) < 2 {
+			continue
+		}
+		report.Solves++
+		for i := 1; i < len(moves); i++ {
+			gapUs := moves[i].TsUs - moves[i-1].TsUs
+			if gapUs <= 0 || gapUs > turnSpeedMaxGapUs {
+				continue
+			}
+			totals[moves[i].Notation] += gapUs
+			counts[moves[i].Notation]++
+			totalUs += gapUs
+			report.TimedTurns++
+		}
+	}
+
+	if report.TimedTurns == 0 {
+		return report
+	}
+	report.OverallAvgMs = float64(totalUs) / 1000 / float64(report.TimedTurns)
+
+	for notation, n := range counts {
+		avg := float64(totals[notation]) / 1000 / float64(n)
+		report.Turns = append(report.Turns, TurnSpeed{
+			Notation:     notation,
+			Count:        n,
+			AvgMs:        avg,
+			VsOverallPct: (avg - report.OverallAvgMs) / report.OverallAvgMs * 100,
+		})
+	}
+	sort.Slice(report.Turns, func(i, j int) bool {
+		a, b := report.Turns[i], report.Turns[j]
+		if (a.Count >= turnSpeedMinSamples) != (b.Count >= turnSpeedMinSamples) {
+			return a.Count >= turnSpeedMinSamples
+		}
+		if a.AvgMs != b.AvgMs {
+			return a.AvgMs > b.AvgMs
+		}
+		return a.Notation < b.Notation
+	})
+
+	for _, t := range report.Turns {
+		if t.Count < turnSpeedMinSamples || t.VsOverallPct < turnSpeedSlowPct {
+			continue
+		}
+		report.Slowest = append(report.Slowest, t)
+		if len(report.Recommendations) < turnSpeedMaxTips {
+			report.Recommendations = append(report.Recommendations, turnSpeedTip(t))
+		}
+	}
+
+	return report
+}
+
+// turnSpeedTip recommends a finger-trick drill for a slow turn.
+func turnSpeedTip(t TurnSpeed) string {
+	tip := fmt.Sprintf("%s is %.0f%% slower than your average turn (%.0fms over %d turns)",
+		t.Notation, t.VsOverallPct, t.AvgMs, t.Count)
+	if drill, ok := fingerTricks[t.Notation]; ok {
+		tip += ": practise " + drill
+	}
+	return tip
+}
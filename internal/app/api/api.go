@@ -0,0 +1,396 @@
+// Package api serves recorded solves and recording control as a JSON REST
+// API, with live cube events as Server-Sent Events, so a web dashboard can
+// be built on the recorder without linking Go code.
+//
+// Routes:
+//
+//	GET  /api/solves?limit=N          recent solves, newest first
+//	GET  /api/solves/{id}             one solve
+//	GET  /api/solves/{id}/moves       its moves, scramble included
+//	GET  /api/solves/{id}/phases      its phase splits
+//	GET  /api/recording               whether a solve is being recorded
+//	POST /api/recording/start         start recording {"scramble": "..."}
+//	POST /api/recording/inspect       end the scramble; the next move starts the solve
+//	POST /api/recording/stop          end the solve early
+//	GET  /api/events                  live events (text/event-stream)
+//
+// Errors are returned as {"error": "..."} with a matching status code.
+//
+// Behind RequireScopes each token carries scopes: ScopeStatsRead for the
+// GET routes and live events, ScopeRecordingControl for the POST routes,
+// and ScopeAdmin, which grants both.
+//
+// Browsers may only call the API from pages of the server itself or of an
+// origin added with AllowOrigins, and POST requests must send
+// "Content-Type: application/json" or an Authorization header. A web page
+// the user visits can then neither read the API nor start and stop
+// recordings with a plain cross-site form post.
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/stream"
+	"github.com/SeamusWaldron/gocube_ble_library/storage"
+)
+
+// defaultLimit is how many solves GET /api/solves returns without ?limit.
+const defaultLimit = 50
+
+// Solve is a solve as returned by the API.
+type Solve struct {
+	ID               string    `json:"id"`
+	StartedAt        time.Time `json:"started_at"`
+	DurationMs       int64     `json:"duration_ms"` // 0 while recording
+	Scramble         string    `json:"scramble,omitempty"`
+	ScrambleVerified bool      `json:"scramble_verified"` // The moves before inspection matched the scramble
+	Device           string    `json:"device,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	Manual           bool      `json:"manual"`
+}
+
+// Move is a move of a solve as returned by the API.
+type Move struct {
+	Index    int    `json:"index"`
+	Notation string `json:"notation"`
+	AtMs     int64  `json:"at_ms"` // From the start of the solve
+}
+
+// Phase is a phase split as returned by the API.
+type Phase struct {
+	Key     string `json:"key"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+	Moves   int    `json:"moves"`
+}
+
+// RecordingStatus is returned by the recording routes.
+type RecordingStatus struct {
+	Recording bool   `json:"recording"`
+	SolveID   string `json:"solve_id,omitempty"` // Current or last solve
+}
+
+// Server handles the API routes. It implements http.Handler; mount it at
+// "/api/".
+type Server struct {
+	db      *storage.DB
+	mux     *http.ServeMux
+	origins []string // Browser origins allowed besides the server's own
+
+	mu      sync.Mutex
+	rec     *storage.Recorder // nil when no cube is connected
+	solveID string
+}
+
+// NewServer creates an API server reading solves from db and streaming
+// events from hub. The recording routes answer 503 until SetRecorder.
+func NewServer(db *storage.DB, hub *stream.Hub) *Server {
+	s := &Server{db: db, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /api/solves", s.listSolves)
+	s.mux.HandleFunc("GET /api/solves/{id}", s.getSolve)
+	s.mux.HandleFunc("GET /api/solves/{id}/moves", s.getMoves)
+	s.mux.HandleFunc("GET /api/solves/{id}/phases", s.getPhases)
+	s.mux.HandleFunc("GET /api/recording", s.recordingStatus)
+	s.mux.HandleFunc("POST /api/recording/start", s.startRecording)
+	s.mux.HandleFunc("POST /api/recording/inspect", s.startInspection)
+	s.mux.HandleFunc("POST /api/recording/stop", s.stopRecording)
+	s.mux.HandleFunc("GET /api/events", hub.ServeSSE)
+	return s
+}
+
+// SetRecorder sets the recorder for solves started through the API, once a
+// cube is connected. nil makes the recording routes answer 503 again.
+func (s *Server) SetRecorder(rec *storage.Recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec = rec
+}
+
+// AllowOrigins lets browser pages of other origins call the API, e.g. a
+// dashboard served from "http://localhost:3000". Call it before serving.
+// See stream.OriginAllowed.
+func (s *Server) AllowOrigins(origins ...string) {
+	s.origins = append(s.origins, origins...)
+}
+
+// ServeHTTP dispatches the request to its route, after checking its origin
+// and, for POST requests, that it is not a plain form post.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !stream.OriginAllowed(r, s.origins) {
+		writeError(w, http.StatusForbidden, errors.New("origin not allowed"))
+		return
+	}
+	if r.Method == http.MethodPost && r.Header.Get("Authorization") == "" {
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("POST requests must send Content-Type: application/json"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Token scopes.
+const (
+	ScopeStatsRead        = "stats:read"        // Read solves and live events
+	ScopeRecordingControl = "recording:control" // Start, inspect and stop recording
+	ScopeAdmin            = "admin"             // Everything
+)
+
+// Scopes are the known scopes.
+var Scopes = []string{ScopeStatsRead, ScopeRecordingControl, ScopeAdmin}
+
+// ValidScope reports whether scope is one of Scopes.
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredScope returns the scope a request needs: ScopeRecordingControl
+// to change anything, ScopeStatsRead to read.
+func RequiredScope(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return ScopeStatsRead
+	}
+	return ScopeRecordingControl
+}
+
+// hasScope reports whether granted includes want, ScopeAdmin including
+// every scope.
+func hasScope(granted []string, want string) bool {
+	for _, s := range granted {
+		if s == want || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticate returns the scopes of a token, or false if it is unknown.
+type Authenticate func(token string) (scopes []string, ok bool)
+
+// RequireScopes wraps next so every request must present a token that
+// auth knows, as an "Authorization: Bearer" header or, for EventSource and
+// WebSocket clients which cannot set headers, a ?token= query parameter,
+// and that carries the request's RequiredScope.
+func RequireScopes(auth Authenticate, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.URL.Query().Get("token")
+		if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			got = strings.TrimPrefix(h, "Bearer ")
+		}
+		scopes, ok := auth(got)
+		if got == "" || !ok {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		if want := RequiredScope(r); !hasScope(scopes, want) {
+			writeError(w, http.StatusForbidden, errors.New("token lacks the "+want+" scope"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireToken wraps next so every request must present token, which
+// carries ScopeAdmin. See RequireScopes.
+func RequireToken(token string, next http.Handler) http.Handler {
+	return RequireScopes(TokenAuth(token), next)
+}
+
+// TokenAuth authenticates a single shared token, which carries ScopeAdmin.
+func TokenAuth(token string) Authenticate {
+	return func(got string) ([]string, bool) {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return nil, false
+		}
+		return []string{ScopeAdmin}, true
+	}
+}
+
+func (s *Server) listSolves(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, errors.New("limit must be a positive number"))
+			return
+		}
+		limit = n
+	}
+
+	solves, err := s.db.Solves(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Solve, len(solves))
+	for i := range solves {
+		out[i] = toSolve(&solves[i])
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getSolve(w http.ResponseWriter, r *http.Request) {
+	solve, ok := s.findSolve(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toSolve(solve))
+}
+
+func (s *Server) getMoves(w http.ResponseWriter, r *http.Request) {
+	solve, ok := s.findSolve(w, r)
+	if !ok {
+		return
+	}
+	moves, err := s.db.Moves(solve.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Move, len(moves))
+	for i, m := range moves {
+		out[i] = Move{Index: i, Notation: m.Notation(), AtMs: m.Time.Sub(solve.StartedAt).Milliseconds()}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) getPhases(w http.ResponseWriter, r *http.Request) {
+	solve, ok := s.findSolve(w, r)
+	if !ok {
+		return
+	}
+	phases, err := s.db.Phases(solve.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]Phase, len(phases))
+	for i, p := range phases {
+		out[i] = Phase{Key: p.Key, StartMs: p.Start.Milliseconds(), EndMs: p.End.Milliseconds(), Moves: p.Moves}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) recordingStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no cube connected"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.statusLocked())
+}
+
+func (s *Server) startRecording(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Scramble string `json:"scramble"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("body must be JSON: {\"scramble\": \"...\"}"))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no cube connected"))
+		return
+	}
+	if s.rec.Recording() {
+		writeError(w, http.StatusConflict, errors.New("a solve is already being recorded"))
+		return
+	}
+	solveID, err := s.rec.Start(body.Scramble)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.solveID = solveID
+	writeJSON(w, http.StatusCreated, s.statusLocked())
+}
+
+func (s *Server) startInspection(w http.ResponseWriter, r *http.Request) {
+	s.control(w, func(rec *storage.Recorder) error { return rec.StartInspection() })
+}
+
+func (s *Server) stopRecording(w http.ResponseWriter, r *http.Request) {
+	s.control(w, func(rec *storage.Recorder) error { return rec.End() })
+}
+
+// control runs action on the recorder of a solve being recorded.
+func (s *Server) control(w http.ResponseWriter, action func(*storage.Recorder) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rec == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("no cube connected"))
+		return
+	}
+	if !s.rec.Recording() {
+		writeError(w, http.StatusConflict, errors.New("no solve is being recorded"))
+		return
+	}
+	if err := action(s.rec); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.statusLocked())
+}
+
+// statusLocked returns the recording status. The caller must hold mu.
+func (s *Server) statusLocked() RecordingStatus {
+	return RecordingStatus{Recording: s.rec.Recording(), SolveID: s.solveID}
+}
+
+// findSolve looks up the solve named in the path, answering 404 if there
+// is none.
+func (s *Server) findSolve(w http.ResponseWriter, r *http.Request) (*storage.Solve, bool) {
+	id := r.PathValue("id")
+	solve, err := s.db.Solve(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if solve == nil {
+		writeError(w, http.StatusNotFound, errors.New("solve not found: "+id))
+		return nil, false
+	}
+	return solve, true
+}
+
+func toSolve(s *storage.Solve) Solve {
+	return Solve{
+		ID:               s.ID,
+		StartedAt:        s.StartedAt,
+		DurationMs:       s.Duration.Milliseconds(),
+		Scramble:         s.Scramble,
+		ScrambleVerified: s.ScrambleVerified,
+		Device:           s.DeviceName,
+		Notes:            s.Notes,
+		Manual:           s.Manual,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,174 @@
+//go:build !js
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/stream"
+	"github.com/SeamusWaldron/gocube_ble_library/storage"
+)
+
+func newTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	hub := stream.NewHub()
+	t.Cleanup(hub.Close)
+
+	s := NewServer(db, hub)
+	s.AllowOrigins("http://localhost:3000")
+	var handler http.Handler = s
+	if token != "" {
+		handler = RequireToken(token, s)
+	}
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestServerRejectsCrossSiteRequests(t *testing.T) {
+	ts := newTestServer(t, "")
+	self := ts.URL // The server's own origin
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		contentType string
+		auth        string
+		want        int
+	}{
+		{"no origin", "GET", "/api/solves", "", "", "", http.StatusOK},
+		{"own origin", "GET", "/api/solves", self, "", "", http.StatusOK},
+		{"allowed origin", "GET", "/api/solves", "http://localhost:3000", "", "", http.StatusOK},
+		{"other origin", "GET", "/api/solves", "https://evil.example", "", "", http.StatusForbidden},
+		{"file page", "GET", "/api/solves", "null", "", "", http.StatusForbidden},
+		{"other origin events", "GET", "/api/events", "https://evil.example", "", "", http.StatusForbidden},
+		{"other origin post", "POST", "/api/recording/stop", "https://evil.example", "application/json", "", http.StatusForbidden},
+		{"form post", "POST", "/api/recording/start", "", "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"text post", "POST", "/api/recording/stop", self, "text/plain", "", http.StatusUnsupportedMediaType},
+		{"no content type", "POST", "/api/recording/inspect", "", "", "", http.StatusUnsupportedMediaType},
+		// Past the checks, the routes answer that no cube is connected
+		{"json post", "POST", "/api/recording/start", self, "application/json; charset=utf-8", "", http.StatusServiceUnavailable},
+		{"authorized post", "POST", "/api/recording/stop", "", "", "Bearer x", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+	ts := newTestServer(t, "s3cret")
+
+	tests := []struct {
+		name  string
+		query string
+		auth  string
+		want  int
+	}{
+		{"missing", "", "", http.StatusUnauthorized},
+		{"wrong header", "", "Bearer nope", http.StatusUnauthorized},
+		{"wrong query", "?token=nope", "", http.StatusUnauthorized},
+		{"header", "", "Bearer s3cret", http.StatusOK},
+		{"query", "?token=s3cret", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", ts.URL+"/api/solves"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	ts := newTestServer(t, "")
+	scoped := httptest.NewServer(RequireScopes(func(token string) ([]string, bool) {
+		switch token {
+		case "reader":
+			return []string{ScopeStatsRead}, true
+		case "controller":
+			return []string{ScopeRecordingControl}, true
+		case "admin":
+			return []string{ScopeAdmin}, true
+		}
+		return nil, false
+	}, ts.Config.Handler))
+	defer scoped.Close()
+
+	tests := []struct {
+		token  string
+		method string
+		path   string
+		want   int
+	}{
+		{"", "GET", "/api/solves", http.StatusUnauthorized},
+		{"stranger", "GET", "/api/solves", http.StatusUnauthorized},
+		{"reader", "GET", "/api/solves", http.StatusOK},
+		{"reader", "POST", "/api/recording/start", http.StatusForbidden},
+		{"controller", "GET", "/api/solves", http.StatusForbidden},
+		{"controller", "POST", "/api/recording/start", http.StatusServiceUnavailable}, // Allowed; no cube
+		{"admin", "GET", "/api/solves", http.StatusOK},
+		{"admin", "POST", "/api/recording/stop", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, scoped.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s with %q = %d, want %d", tt.method, tt.path, tt.token, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"sort"
+)
+
+// Peak detection and matching parameters.
+const (
+	// peakThreshold is how many times the median envelope a window must be
+	// to count as a clack.
+	peakThreshold = 4.0
+	// peakMinGapUs is the shortest gap between two clacks.
+	peakMinGapUs = 60_000
+	// matchBeforeUs and matchAfterUs bound how far before and after a move
+	// timestamp its clack may be. BLE delivery lags the physical turn, so
+	// the window reaches further back.
+	matchBeforeUs = 250_000
+	matchAfterUs  = 50_000
+	// alignCandidates is how many leading peaks and moves are paired up to
+	// find candidate offsets for automatic alignment.
+	alignCandidates = 20
+)
+
+// Peak is a loud, short event in the audio (usually a cube clack).
+type Peak struct {
+	AudioUs   int64   // Position in the recording
+	Amplitude float64 // Envelope value, 0..1 of full scale
+}
+
+// DetectPeaks returns local maxima of env that stand out from its median
+// level, at least peakMinGapUs apart.
+func DetectPeaks(env *Envelope) []Peak {
+	if env == nil || len(env.Values) == 0 {
+		return nil
+	}
+
+	sorted := append([]float64(nil), env.Values...)
+	sort.Float64s(sorted)
+	threshold := sorted[len(sorted)/2] * peakThreshold
+	if threshold <= 0 {
+		threshold = sorted[len(sorted)-1] / 2 // Digital silence between clacks
+	}
+
+	var peaks []Peak
+	for i, v := range env.Values {
+		if v < threshold || v == 0 {
+			continue
+		}
+		if i > 0 && env.Values[i-1] > v || i+1 < len(env.Values) && env.Values[i+1] >= v {
+			continue // Not a local maximum
+		}
+		at := int64(i) * env.WindowUs
+		if n := len(peaks); n > 0 && at-peaks[n-1].AudioUs < peakMinGapUs {
+			if v > peaks[n-1].Amplitude {
+				peaks[n-1] = Peak{AudioUs: at, Amplitude: v}
+			}
+			continue
+		}
+		peaks = append(peaks, Peak{AudioUs: at, Amplitude: v})
+	}
+	return peaks
+}
+
+// Marker is an audio peak placed on the solve timeline.
+type Marker struct {
+	TsMs      int64   `json:"ts_ms"` // Solve time of the clack
+	TsUs      int64   `json:"ts_us"`
+	Amplitude float64 `json:"amplitude"`  // Relative to the loudest peak, 0..1
+	MoveIndex int     `json:"move_index"` // Matched move, or -1
+	LatencyMs float64 `json:"latency_ms"` // Move timestamp minus clack time
+}
+
+// Alignment maps audio peaks onto a solve's moves.
+type Alignment struct {
+	OffsetUs        int64    `json:"offset_us"` // Audio position of solve time 0
+	Synced          bool     `json:"synced"`    // Offset came from a sync click
+	Peaks           int      `json:"peaks"`
+	Moves           int      `json:"moves"`
+	Matched         int      `json:"matched"`
+	MedianLatencyMs float64  `json:"median_latency_ms"`
+	P90LatencyMs    float64  `json:"p90_latency_ms"`
+	JitterMs        float64  `json:"jitter_ms"` // p90 - p10 latency
+	Markers         []Marker `json:"markers"`
+}
+
+// Align matches peaks to move timestamps (microseconds since solve start).
+// With a sync click, syncUs is the audio position of solve time 0 and the
+// latencies are absolute BLE delays. Without one (syncUs < 0) the offset
+// is fitted to match the most moves; that absorbs the average delay, so
+// only the jitter is meaningful.
+func Align(peaks []Peak, moveTsUs []int64, syncUs int64) *Alignment {
+	a := &Alignment{Peaks: len(peaks), Moves: len(moveTsUs)}
+	if syncUs >= 0 {
+		a.OffsetUs = syncUs
+		a.Synced = true
+	} else {
+		a.OffsetUs = fitOffset(peaks, moveTsUs)
+	}
+
+	matches := matchPeaks(peaks, moveTsUs, a.OffsetUs)
+
+	var loudest float64
+	for _, p := range peaks {
+		if p.Amplitude > loudest {
+			loudest = p.Amplitude
+		}
+	}
+
+	var latencies []float64
+	for i, p := range peaks {
+		ts := p.AudioUs - a.OffsetUs
+		m := Marker{TsMs: ts / 1000, TsUs: ts, MoveIndex: matches[i]}
+		if loudest > 0 {
+			m.Amplitude = p.Amplitude / loudest
+		}
+		if m.MoveIndex >= 0 {
+			m.LatencyMs = float64(moveTsUs[m.MoveIndex]-ts) / 1000
+			latencies = append(latencies, m.LatencyMs)
+		}
+		if ts >= 0 {
+			a.Markers = append(a.Markers, m)
+		}
+	}
+
+	a.Matched = len(latencies)
+	if len(latencies) > 0 {
+		sort.Float64s(latencies)
+		a.MedianLatencyMs = percentile(latencies, 50)
+		a.P90LatencyMs = percentile(latencies, 90)
+		a.JitterMs = a.P90LatencyMs - percentile(latencies, 10)
+	}
+	return a
+}
+
+// matchPeaks pairs each move with the nearest unused peak in its window and
+// returns the matched move index per peak (-1 if none).
+func matchPeaks(peaks []Peak, moveTsUs []int64, offsetUs int64) []int {
+	matches := make([]int, len(peaks))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	p := 0
+	for mi, ts := range moveTsUs {
+		at := ts + offsetUs // Move time on the audio clock
+		for p < len(peaks) && peaks[p].AudioUs < at-matchBeforeUs {
+			p++
+		}
+		best := -1
+		for j := p; j < len(peaks) && peaks[j].AudioUs <= at+matchAfterUs; j++ {
+			if matches[j] >= 0 {
+				continue
+			}
+			if best < 0 || abs(peaks[j].AudioUs-at) < abs(peaks[best].AudioUs-at) {
+				best = j
+			}
+		}
+		if best >= 0 {
+			matches[best] = mi
+		}
+	}
+	return matches
+}
+
+// fitOffset finds the audio offset that matches the most moves to peaks,
+// trying offsets that put one of the first peaks on one of the first moves.
+func fitOffset(peaks []Peak, moveTsUs []int64) int64 {
+	var best int64
+	bestScore := -1
+	for i := 0; i < len(peaks) && i < alignCandidates; i++ {
+		for j := 0; j < len(moveTsUs) && j < alignCandidates; j++ {
+			offset := peaks[i].AudioUs - moveTsUs[j]
+			score := 0
+			for _, m := range matchPeaks(peaks, moveTsUs, offset) {
+				if m >= 0 {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestScore = offset, score
+			}
+		}
+	}
+	return best
+}
+
+// percentile returns the p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(p / 100 * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+// Package audio extracts amplitude markers from externally recorded solve
+// audio and aligns cube "clack" peaks with recorded move timestamps.
+// Only amplitude envelopes are kept; no audio is stored.
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAV format codes.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// Envelope is the RMS amplitude of an audio signal in fixed windows.
+type Envelope struct {
+	WindowUs int64     // Length of each window in microseconds
+	Values   []float64 // RMS amplitude per window, 0..1 of full scale
+}
+
+// ReadWAVEnvelope decodes a PCM (8/16/24/32-bit) or 32-bit float WAV file
+// and returns its amplitude envelope in windows of windowMs. Channels are
+// mixed down to mono.
+func ReadWAVEnvelope(r io.Reader, windowMs int) (*Envelope, error) {
+	if windowMs <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+
+	var format, channels, bits int
+	var rate int
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil, fmt.Errorf("no audio data found: %w", err)
+		}
+		id := string(hdr[0:4])
+		size := int64(binary.LittleEndian.Uint32(hdr[4:8]))
+
+		switch id {
+		case "fmt ":
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return nil, fmt.Errorf("failed to read format chunk: %w", err)
+			}
+			if len(buf) < 16 {
+				return nil, fmt.Errorf("invalid format chunk")
+			}
+			format = int(binary.LittleEndian.Uint16(buf[0:2]))
+			channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+			rate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			bits = int(binary.LittleEndian.Uint16(buf[14:16]))
+			if format == wavFormatExtensible && len(buf) >= 26 {
+				format = int(binary.LittleEndian.Uint16(buf[24:26])) // Sub-format GUID starts with the code
+			}
+		case "data":
+			if rate == 0 {
+				return nil, fmt.Errorf("data chunk before format chunk")
+			}
+			decode, err := sampleDecoder(format, bits)
+			if err != nil {
+				return nil, err
+			}
+			if channels <= 0 {
+				return nil, fmt.Errorf("invalid channel count %d", channels)
+			}
+			return envelope(io.LimitReader(r, size), decode, bits/8, channels, rate, windowMs)
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("failed to skip %q chunk: %w", id, err)
+			}
+		}
+		if size%2 == 1 && id == "fmt " {
+			io.CopyN(io.Discard, r, 1) // Chunks are padded to even sizes
+		}
+	}
+}
+
+// sampleDecoder returns a function decoding one sample to -1..1.
+func sampleDecoder(format, bits int) (func([]byte) float64, error) {
+	switch {
+	case format == wavFormatPCM && bits == 8:
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case format == wavFormatPCM && bits == 16:
+		return func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) / 32768 }, nil
+	case format == wavFormatPCM && bits == 24:
+		return func(b []byte) float64 {
+			v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+			return float64(v) / 8388608
+		}, nil
+	case format == wavFormatPCM && bits == 32:
+		return func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648 }, nil
+	case format == wavFormatFloat && bits == 32:
+		return func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) }, nil
+	}
+	return nil, fmt.Errorf("unsupported WAV encoding (format %d, %d-bit); use PCM or 32-bit float", format, bits)
+}
+
+// envelope reads interleaved frames and computes the RMS per window.
+func envelope(r io.Reader, decode func([]byte) float64, sampleBytes, channels, rate, windowMs int) (*Envelope, error) {
+	frameBytes := sampleBytes * channels
+	windowFrames := rate * windowMs / 1000
+	if windowFrames < 1 {
+		windowFrames = 1
+	}
+
+	env := &Envelope{WindowUs: int64(windowFrames) * 1_000_000 / int64(rate)}
+	buf := make([]byte, frameBytes*4096)
+	var sum float64
+	n := 0
+	for {
+		read, err := io.ReadFull(r, buf)
+		for off := 0; off+frameBytes <= read; off += frameBytes {
+			var mono float64
+			for c := 0; c < channels; c++ {
+				mono += decode(buf[off+c*sampleBytes : off+(c+1)*sampleBytes])
+			}
+			mono /= float64(channels)
+			sum += mono * mono
+			n++
+			if n == windowFrames {
+				env.Values = append(env.Values, math.Sqrt(sum/float64(n)))
+				sum, n = 0, 0
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio data: %w", err)
+		}
+	}
+	if n > 0 {
+		env.Values = append(env.Values, math.Sqrt(sum/float64(n)))
+	}
+	return env, nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var achievementsCmd = &cobra.Command{
+	Use:   "achievements",
+	Short: "Show earned achievements and progress towards the rest",
+	Long: `Show the achievement badges and progress towards those not yet earned:
+
+  Sub-Minute     Complete a solve in under a minute
+  Century Week   Complete 100 solves within 7 days
+  Week Streak    Solve on 7 days in a row
+  PLL Collector  Meet all 21 PLL cases
+
+Achievements are updated as solves are stored. Days are counted in the
+configured time zone. PLL cases are recognized from the cube state when the
+last layer is first oriented with the first two layers solved; run
+'gocube db rebuild-derived' to recognize them in solves recorded earlier.`,
+	RunE: runAchievements,
+}
+
+func init() {
+	rootCmd.AddCommand(achievementsCmd)
+}
+
+func runAchievements(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Catch up first, e.g. after a rebuild recognized more PLL cases
+	if _, err := updateAchievements(db); err != nil {
+		return err
+	}
+
+	statuses, err := analysis.EvaluateAchievements(storage.NewSolveRepository(db),
+		storage.NewPLLCaseRepository(db), time.Now(), displayLocation())
+	if err != nil {
+		return err
+	}
+	earned, err := storage.NewAchievementRepository(db).List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Achievements")
+	fmt.Println("============")
+	fmt.Println()
+	for _, st := range statuses {
+		if e, ok := earned[st.Key]; ok {
+			fmt.Printf("  [x] %-14s %s\n", st.Name, st.Description)
+			line := "earned " + localTime(e.EarnedAt).Format("2006-01-02")
+			if e.SolveID != nil {
+				line += " (solve " + (*e.SolveID)[:8] + ")"
+			}
+			fmt.Printf("      %-14s %s\n", "", line)
+		} else {
+			fmt.Printf("  [ ] %-14s %s\n", st.Name, st.Description)
+			fmt.Printf("      %-14s %s\n", "", st.Progress)
+		}
+	}
+	return nil
+}
+
+// updateAchievements records the achievements met by the stored solves and
+// returns those earned for the first time.
+func updateAchievements(db *storage.DB) ([]analysis.Achievement, error) {
+	return analysis.UpdateAchievements(storage.NewSolveRepository(db), storage.NewPLLCaseRepository(db),
+		storage.NewAchievementRepository(db), time.Now(), displayLocation())
+}
+
+// achievementNotice formats newly earned achievements for display, or
+// returns "" if there are none.
+func achievementNotice(earned []analysis.Achievement) string {
+	if len(earned) == 0 {
+		return ""
+	}
+	names := make([]string, len(earned))
+	for i, a := range earned {
+		names[i] = a.Name
+	}
+	return "Achievement unlocked: " + strings.Join(names, ", ")
+}
+
+// printNewAchievements updates the achievements after a solve is stored and
+// prints any earned. Failures are reported but do not fail the command.
+func printNewAchievements(db *storage.DB) {
+	earned, err := updateAchievements(db)
+	if err != nil {
+		fmt.Printf("Warning: failed to update achievements: %v\n", err)
+		return
+	}
+	if notice := achievementNotice(earned); notice != "" {
+		fmt.Println()
+		fmt.Println(notice)
+	}
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var algorithmsCmd = &cobra.Command{
+	Use:   "algorithms",
+	Short: "Work with the algorithm library",
+}
+
+var algorithmsReanalyzeCmd = &cobra.Command{
+	Use:   "reanalyze",
+	Short: "Re-run algorithm detection across all solves",
+	Long: `Re-run final phase algorithm detection on every completed solve using the
+current algorithm library (~/.gocube_recorder/algorithms.json), update the
+stored case statistics and print what changed, e.g.:
+
+  T-perm now detected in 412 solves
+
+Run this after editing the library so statistics for older solves match it.
+Ctrl+C rolls back the solve being analyzed, stops and lists the changes
+so far.`,
+	RunE: runAlgorithmsReanalyze,
+}
+
+func init() {
+	rootCmd.AddCommand(algorithmsCmd)
+	algorithmsCmd.AddCommand(algorithmsReanalyzeCmd)
+}
+
+func runAlgorithmsReanalyze(cmd *cobra.Command, args []string) error {
+	algoPath, err := recorder.DefaultAlgorithmsPath()
+	if err != nil {
+		return err
+	}
+	if err := loadAlgorithmLibrary(algoPath); err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	detectionRepo := storage.NewToolDetectionRepository(db)
+
+	before, err := detectionRepo.SolvesByTool()
+	if err != nil {
+		return err
+	}
+
+	solves, err := solveRepo.List(-1) // All solves
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	bar := newProgress("Re-analyzing", len(solves))
+
+	analyzed := 0
+	var stopped error
+	for i, solve := range solves {
+		if ctx.Err() != nil {
+			stopped = interrupted(i, len(solves), "solves")
+			break
+		}
+		bar.Step()
+		if solve.EndedAt == nil {
+			continue
+		}
+		if err := updateToolDetections(ctx, db, solve.SolveID); err != nil {
+			if ctx.Err() != nil {
+				stopped = interrupted(i, len(solves), "solves")
+				break
+			}
+			bar.Done()
+			return err
+		}
+		analyzed++
+	}
+	bar.Done()
+
+	after, err := detectionRepo.SolvesByTool()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Re-analyzed %d solves\n", analyzed)
+	if len(before) == 0 {
+		fmt.Println("No earlier statistics stored; every detection is listed as new.")
+	}
+	changes := toolChangelog(before, after)
+	if len(changes) == 0 {
+		fmt.Println("No changes in detected algorithms.")
+		return stopped
+	}
+	fmt.Println()
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+	return stopped
+}
+
+// finalPhaseMoves returns the moves of a solve's bottom_orient phase, where
+// algorithm detection runs. It is empty if the phase was never marked.
+func finalPhaseMoves(moveRepo *storage.MoveRepository, phaseRepo *storage.PhaseRepository, solveID string) ([]gocube.Move, error) {
+	segments, err := phaseRepo.GetPhaseSegments(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phases: %w", err)
+	}
+	for _, seg := range segments {
+		if seg.PhaseKey == "bottom_orient" {
+			records, err := moveRepo.GetBySolveRange(solveID, seg.StartTsMs, seg.EndTsMs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get moves: %w", err)
+			}
+			return storage.ToMoves(records), nil
+		}
+	}
+	return nil, nil
+}
+
+// updateToolDetections re-runs algorithm detection on a solve's final phase
+// with the active tools and stores the result, in one transaction that an
+// error or ctx being cancelled rolls back.
+func updateToolDetections(ctx context.Context, db *storage.DB, solveID string) error {
+	return db.InTx(ctx, func(tx *storage.DB) error {
+		moves, err := finalPhaseMoves(storage.NewMoveRepository(tx), storage.NewPhaseRepository(tx), solveID)
+		if err != nil {
+			return err
+		}
+		counts := map[string]int{}
+		if len(moves) > 0 {
+			counts = analysis.AnalyzeFinalPhase(moves).ToolCounts
+		}
+		return storage.NewToolDetectionRepository(tx).Replace(solveID, counts)
+	})
+}
+
+// toolChangelog describes how the number of solves each algorithm is
+// detected in changed, sorted by algorithm name.
+func toolChangelog(before, after map[string]int) []string {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, name := range sorted {
+		was, now := before[name], after[name]
+		switch {
+		case was == now:
+		case was == 0:
+			changes = append(changes, fmt.Sprintf("%s now detected in %d solves", name, now))
+		case now == 0:
+			changes = append(changes, fmt.Sprintf("%s no longer detected (was in %d solves)", name, was))
+		default:
+			changes = append(changes, fmt.Sprintf("%s detected in %d solves (was %d)", name, now, was))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/audio"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	audioSolveID string
+	audioLast    bool
+	audioWAV     string
+	audioClickAt float64
+)
+
+var audioCmd = &cobra.Command{
+	Use:   "audio",
+	Short: "Align externally recorded audio with solves",
+}
+
+var audioAlignCmd = &cobra.Command{
+	Use:   "align",
+	Short: "Align cube clacks in a WAV recording with a solve's moves",
+	Long: `Detect cube "clack" peaks in a WAV recording of a solve and align them
+with the recorded move timestamps. Only amplitude markers are stored; the
+audio itself is not.
+
+Record the solve with any audio app, export it as WAV, then run:
+
+  gocube audio align --last --wav solve.wav --click-at 2.35
+
+--click-at is the position (seconds) in the recording of a sync click made
+as the solve recording started, e.g. the keypress that started it. With it,
+the report shows the absolute BLE latency (move timestamp minus clack).
+Without it the offset is fitted automatically, which absorbs the average
+latency, so only the jitter is meaningful.
+
+Regenerate the solve report afterwards to see the markers in the
+visualizer timeline.`,
+	RunE: runAudioAlign,
+}
+
+func init() {
+	rootCmd.AddCommand(audioCmd)
+
+	audioCmd.AddCommand(audioAlignCmd)
+	audioAlignCmd.Flags().StringVar(&audioSolveID, "id", "", "Solve ID to align")
+	audioAlignCmd.Flags().BoolVar(&audioLast, "last", false, "Align the last solve")
+	audioAlignCmd.Flags().StringVar(&audioWAV, "wav", "", "WAV recording of the solve")
+	audioAlignCmd.Flags().Float64Var(&audioClickAt, "click-at", -1, "Position in seconds of the sync click marking solve start")
+	audioAlignCmd.MarkFlagRequired("wav")
+}
+
+func runAudioAlign(cmd *cobra.Command, args []string) error {
+	if audioSolveID == "" && !audioLast {
+		return fmt.Errorf("specify --id or --last")
+	}
+
+	f, err := os.Open(audioWAV)
+	if err != nil {
+		return fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer f.Close()
+
+	env, err := audio.ReadWAVEnvelope(f, 5)
+	if err != nil {
+		return err
+	}
+	peaks := audio.DetectPeaks(env)
+	if len(peaks) == 0 {
+		return fmt.Errorf("no clacks found in %s", audioWAV)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solve *storage.Solve
+	if audioLast {
+		solve, err = solveRepo.GetLast()
+	} else {
+		solve, err = solveRepo.Get(audioSolveID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return fmt.Errorf("solve not found")
+	}
+
+	moves, err := storage.NewMoveRepository(db).GetBySolve(solve.SolveID)
+	if err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		return fmt.Errorf("solve %s has no moves", solve.SolveID[:8])
+	}
+	moveTs := make([]int64, len(moves))
+	for i, m := range moves {
+		moveTs[i] = m.TsUs
+	}
+
+	syncUs := int64(-1)
+	if audioClickAt >= 0 {
+		syncUs = int64(audioClickAt * 1_000_000)
+	}
+	alignment := audio.Align(peaks, moveTs, syncUs)
+
+	if err := recorder.SaveAudioAlignment(storage.NewEventRepository(db), solve.SolveID, alignment); err != nil {
+		return err
+	}
+
+	fmt.Printf("Aligned %s with solve %s\n", audioWAV, solve.SolveID[:8])
+	fmt.Printf("  Clacks:  %d detected, %d of %d moves matched (%.0f%%)\n",
+		alignment.Peaks, alignment.Matched, alignment.Moves, float64(alignment.Matched)/float64(alignment.Moves)*100)
+	if alignment.Synced {
+		fmt.Printf("  Latency: median %.0fms, p90 %.0fms (move timestamp after clack)\n", alignment.MedianLatencyMs, alignment.P90LatencyMs)
+	} else {
+		fmt.Printf("  Offset:  fitted at %.2fs (use --click-at for absolute latency)\n", float64(alignment.OffsetUs)/1_000_000)
+	}
+	fmt.Printf("  Jitter:  %.0fms (p10 to p90)\n", alignment.JitterMs)
+	fmt.Println()
+	fmt.Println("Run 'gocube report solve --id " + solve.SolveID + "' to see the markers in the visualizer.")
+	return nil
+}
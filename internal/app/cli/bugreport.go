@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Package logs and diagnostics into an archive for a bug report",
+	Long: `Collect what a maintainer needs to reproduce a problem into one zip
+archive:
+
+  ISSUE.md          A Markdown issue template with the environment filled in
+  environment.json  gocube and Go versions, OS and Bluetooth stack
+  doctor.txt        The output of 'gocube doctor'
+  session.jsonl     The last recorded session log (raw BLE messages)
+  solve.json        With --solve or --last-solve: the solve, its moves and
+                    phases
+
+Fill in ISSUE.md, paste it into a new issue and attach the archive. Nothing
+is uploaded. The session log and solve contain your moves and the cube's
+name; look through the archive before sharing it.
+
+Examples:
+  gocube bugreport
+  gocube bugreport --last-solve
+  gocube bugreport --solve 3f2a -o ~/Desktop/report.zip`,
+	RunE: runBugreport,
+}
+
+var (
+	bugreportSolveID   string
+	bugreportLastSolve bool
+	bugreportOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(bugreportCmd)
+	bugreportCmd.Flags().StringVar(&bugreportSolveID, "solve", "", "Include this solve's data")
+	bugreportCmd.Flags().BoolVar(&bugreportLastSolve, "last-solve", false, "Include the last solve's data")
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Archive path (default: gocube-bugreport-<time>.zip)")
+}
+
+// bugreportEnvironment is environment.json in a bug report archive.
+type bugreportEnvironment struct {
+	GocubeVersion  string `json:"gocube_version"`
+	ModuleVersion  string `json:"module_version,omitempty"` // From the build info
+	VCSRevision    string `json:"vcs_revision,omitempty"`
+	GoVersion      string `json:"go_version"`
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+	OSVersion      string `json:"os_version,omitempty"`
+	BluetoothStack string `json:"bluetooth_stack"`
+	Profile        string `json:"profile,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// bugreportSolve is solve.json in a bug report archive.
+type bugreportSolve struct {
+	Solve  *storage.Solve         `json:"solve"`
+	Moves  []storage.MoveRecord   `json:"moves"`
+	Phases []storage.PhaseSegment `json:"phases"`
+}
+
+func runBugreport(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	output := bugreportOutput
+	if output == "" {
+		output = fmt.Sprintf("gocube-bugreport-%s.zip", now.Format("20060102-150405"))
+	}
+
+	env := collectEnvironment(now)
+
+	fmt.Println("Running doctor checks...")
+	var doctor bytes.Buffer
+	if results, err := runDoctorChecks(); err != nil {
+		fmt.Fprintf(&doctor, "doctor failed: %v\n", err)
+	} else {
+		printDoctorResults(&doctor, results)
+	}
+
+	files := map[string][]byte{"doctor.txt": doctor.Bytes()}
+	var included []string
+
+	homeDir, _ := os.UserHomeDir()
+	if logPath := lastSessionLog(filepath.Join(homeDir, ".gocube_recorder", "logs")); logPath != "" {
+		data, err := os.ReadFile(logPath)
+		if err != nil {
+			return fmt.Errorf("failed to read session log: %w", err)
+		}
+		files["session.jsonl"] = data
+		included = append(included, fmt.Sprintf("session.jsonl (%s)", filepath.Base(logPath)))
+	} else {
+		fmt.Println("No session log found; record with 'gocube solve record' to capture one")
+	}
+
+	if bugreportSolveID != "" || bugreportLastSolve {
+		data, solveID, err := exportBugreportSolve()
+		if err != nil {
+			return err
+		}
+		files["solve.json"] = data
+		included = append(included, fmt.Sprintf("solve.json (solve %s)", solveID))
+	}
+
+	envJSON, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	files["environment.json"] = envJSON
+	files["ISSUE.md"] = []byte(issueTemplate(env, included))
+
+	if err := writeZip(output, files); err != nil {
+		return err
+	}
+
+	fmt.Printf("Bug report written to %s\n", output)
+	fmt.Println("Fill in ISSUE.md from the archive, open an issue with it and attach the archive.")
+	return nil
+}
+
+// collectEnvironment describes the gocube build and the system it runs on.
+func collectEnvironment(now time.Time) bugreportEnvironment {
+	env := bugreportEnvironment{
+		GocubeVersion:  version,
+		GoVersion:      runtime.Version(),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		OSVersion:      osVersion(),
+		BluetoothStack: bluetoothStack(),
+		Profile:        profileName,
+		CreatedAt:      now.Format(time.RFC3339),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		env.ModuleVersion = info.Main.Version
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				env.VCSRevision = s.Value
+			}
+		}
+	}
+	return env
+}
+
+// osVersion returns the OS release, or "" if it cannot be determined.
+func osVersion() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return commandOutput("sw_vers", "-productVersion")
+	case "windows":
+		return commandOutput("cmd", "/c", "ver")
+	default:
+		data, err := os.ReadFile("/etc/os-release")
+		if err != nil {
+			return commandOutput("uname", "-sr")
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+				return strings.Trim(v, `"`)
+			}
+		}
+		return ""
+	}
+}
+
+// bluetoothStack names the Bluetooth stack gocube talks to on this OS,
+// with its version where it can be read.
+func bluetoothStack() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "CoreBluetooth"
+	case "windows":
+		return "WinRT Bluetooth LE"
+	case "linux":
+		if v := commandOutput("bluetoothctl", "--version"); v != "" {
+			return "BlueZ (" + v + ")"
+		}
+		return "BlueZ (bluetoothctl not found)"
+	default:
+		return "unknown"
+	}
+}
+
+// commandOutput runs name and returns its trimmed output, or "" if it fails.
+func commandOutput(name string, args ...string) string {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// lastSessionLog returns the newest session log in logDir, or "".
+func lastSessionLog(logDir string) string {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return ""
+	}
+	var logs []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+			logs = append(logs, e.Name())
+		}
+	}
+	if len(logs) == 0 {
+		return ""
+	}
+	// Names carry the timestamp, so the newest sorts last
+	sort.Strings(logs)
+	return filepath.Join(logDir, logs[len(logs)-1])
+}
+
+// exportBugreportSolve returns solve.json for the solve chosen by the
+// flags, and its ID.
+func exportBugreportSolve() ([]byte, string, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, "", err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solve *storage.Solve
+	if bugreportLastSolve {
+		solve, err = solveRepo.GetLast()
+	} else {
+		solve, err = solveRepo.Get(bugreportSolveID)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return nil, "", fmt.Errorf("solve not found")
+	}
+
+	moves, err := storage.NewMoveRepository(db).GetBySolve(solve.SolveID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get moves: %w", err)
+	}
+	phases, err := storage.NewPhaseRepository(db).GetPhaseSegments(solve.SolveID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get phases: %w", err)
+	}
+	data, err := json.MarshalIndent(bugreportSolve{Solve: solve, Moves: moves, Phases: phases}, "", "  ")
+	return data, solve.SolveID, err
+}
+
+// issueTemplate returns ISSUE.md: the sections of a bug report to fill in,
+// with the environment and the archive's contents already written.
+func issueTemplate(env bugreportEnvironment, included []string) string {
+	var b strings.Builder
+	b.WriteString("## Describe the bug\n\n<!-- What went wrong? -->\n\n")
+	b.WriteString("## Steps to reproduce\n\n1. \n2. \n3. \n\n")
+	b.WriteString("## Expected behavior\n\n<!-- What did you expect to happen? -->\n\n")
+	b.WriteString("## Actual behavior\n\n<!-- What happened instead? Paste any error output. -->\n\n")
+	b.WriteString("## Environment\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	version := env.GocubeVersion
+	if env.VCSRevision != "" {
+		version += " (" + env.VCSRevision + ")"
+	}
+	fmt.Fprintf(&b, "| gocube | %s |\n", version)
+	fmt.Fprintf(&b, "| Go | %s |\n", env.GoVersion)
+	osName := env.OS + "/" + env.Arch
+	if env.OSVersion != "" {
+		osName += ", " + env.OSVersion
+	}
+	fmt.Fprintf(&b, "| OS | %s |\n", osName)
+	fmt.Fprintf(&b, "| Bluetooth | %s |\n", env.BluetoothStack)
+	b.WriteString("| Cube model | <!-- e.g. GoCube X, GoCube Edge --> |\n\n")
+	b.WriteString("## Attached\n\n")
+	b.WriteString("- environment.json\n- doctor.txt\n")
+	for _, f := range included {
+		fmt.Fprintf(&b, "- %s\n", f)
+	}
+	return b.String()
+}
+
+// writeZip writes files to a new zip archive at path, in name order.
+func writeZip(path string, files map[string][]byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer f.Close()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	zw := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := io.Copy(w, bytes.NewReader(files[name])); err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return f.Close()
+}
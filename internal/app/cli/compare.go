@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// period is a half-open time range [Start, End) selected on the command line.
+type period struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// parsePeriod parses a period spec relative to now:
+//
+//	last 30d      the 30 days up to now (units: d, w)
+//	prior 30d     the 30 days before that
+//	2026-01-01..2026-01-31   inclusive dates in now's time zone
+func parsePeriod(spec string, now time.Time) (period, error) {
+	spec = strings.TrimSpace(spec)
+
+	if from, to, ok := strings.Cut(spec, ".."); ok {
+		start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(from), now.Location())
+		if err != nil {
+			return period{}, fmt.Errorf("invalid start date in %q (want YYYY-MM-DD): %w", spec, err)
+		}
+		end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(to), now.Location())
+		if err != nil {
+			return period{}, fmt.Errorf("invalid end date in %q (want YYYY-MM-DD): %w", spec, err)
+		}
+		if end.Before(start) {
+			return period{}, fmt.Errorf("period %q ends before it starts", spec)
+		}
+		return period{Label: spec, Start: start, End: end.AddDate(0, 0, 1)}, nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 2 || (fields[0] != "last" && fields[0] != "prior") {
+		return period{}, fmt.Errorf("invalid period %q (want \"last 30d\", \"prior 30d\" or YYYY-MM-DD..YYYY-MM-DD)", spec)
+	}
+	days, err := parsePeriodLength(fields[1])
+	if err != nil {
+		return period{}, fmt.Errorf("invalid period %q: %w", spec, err)
+	}
+
+	end := now
+	if fields[0] == "prior" {
+		end = now.AddDate(0, 0, -days)
+	}
+	return period{Label: spec, Start: end.AddDate(0, 0, -days), End: end}, nil
+}
+
+// parsePeriodLength parses "30d" or "4w" into a number of calendar days.
+func parsePeriodLength(s string) (int, error) {
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid length %q", s)
+	}
+	switch s[len(s)-1] {
+	case 'd':
+		return n, nil
+	case 'w':
+		return n * 7, nil
+	}
+	return 0, fmt.Errorf("invalid length unit in %q (want d or w)", s)
+}
+
+// runTrendCompare implements `report trend --compare CURRENT BASELINE`.
+func runTrendCompare(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("--compare needs two periods, e.g. --compare \"last 30d\" \"prior 30d\"")
+	}
+	now := time.Now().In(displayLocation())
+	current, err := parsePeriod(args[0], now)
+	if err != nil {
+		return err
+	}
+	baseline, err := parsePeriod(args[1], now)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+	orientRepo := storage.NewOrientationRepository(db)
+
+	ctx, stop := interruptContext()
+	defer stop()
+	load := func(p period) ([]analysis.SolveData, error) {
+		solves, err := solveRepo.ListBetween(p.Start, p.End)
+		if err != nil {
+			return nil, err
+		}
+		solves = filterSolvesByDevice(solves, reportDevice)
+		solves, err = filterSolvesByContext(db, solves, trendContext)
+		if err != nil {
+			return nil, err
+		}
+		return buildSolveData(ctx, solves, moveRepo, phaseRepo, orientRepo, storage.NewEventRepository(db))
+	}
+	currentData, err := load(current)
+	if err != nil {
+		return err
+	}
+	baselineData, err := load(baseline)
+	if err != nil {
+		return err
+	}
+	if len(currentData) == 0 && len(baselineData) == 0 {
+		return fmt.Errorf("no completed solves in either period")
+	}
+
+	cmp := analysis.CompareTrends(baseline.Label, baselineData, current.Label, currentData)
+
+	outputDir := reportOutputDir
+	if outputDir == "" {
+		outputDir = reportsDir()
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	outputFile := filepath.Join(outputDir, "trend_compare.json")
+	if err := writeJSON(outputFile, cmp); err != nil {
+		return err
+	}
+
+	if trendTable.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cmp)
+	}
+
+	if len(trendContext) > 0 {
+		fmt.Printf("Filtering by context: %s\n", formatContext(trendContext))
+	}
+	fmt.Printf("Comparing %s (%d solves, %s to %s)\n", current.Label, cmp.CurrentSolves,
+		current.Start.Format("2006-01-02"), current.End.Add(-time.Second).Format("2006-01-02"))
+	fmt.Printf("     with %s (%d solves, %s to %s)\n", baseline.Label, cmp.BaselineSolves,
+		baseline.Start.Format("2006-01-02"), baseline.End.Add(-time.Second).Format("2006-01-02"))
+	fmt.Println()
+
+	compareTable(cmp.Metrics, baseline.Label, current.Label).Render(os.Stdout)
+	if len(cmp.Phases) > 0 {
+		fmt.Println()
+		fmt.Println("Phase splits:")
+		compareTable(cmp.Phases, baseline.Label, current.Label).Render(os.Stdout)
+	}
+
+	fmt.Println()
+	fmt.Println("Significance: likely = |t| >= 2, possible = |t| >= 1 (Welch's t-test);")
+	fmt.Printf("too_few = fewer than 5 solves in a period. Written to %s\n", outputFile)
+	return nil
+}
+
+// compareTable renders metric diffs as a table.
+func compareTable(diffs []analysis.MetricDiff, baselineLabel, currentLabel string) *table {
+	t := &table{
+		Columns: []tableColumn{
+			{Key: "metric", Title: "Metric"},
+			{Key: "baseline", Title: baselineLabel, Right: true, Format: formatFloat(1)},
+			{Key: "current", Title: currentLabel, Right: true, Format: formatFloat(1)},
+			{Key: "delta_pct", Title: "Change", Right: true, Format: func(v interface{}) string {
+				return fmt.Sprintf("%+.1f%%", v)
+			}},
+			{Key: "trend", Title: "Trend"},
+			{Key: "significance", Title: "Significance"},
+		},
+	}
+	for _, d := range diffs {
+		row := map[string]interface{}{"metric": d.Metric}
+		if d.BaselineN > 0 {
+			row["baseline"] = d.Baseline
+		}
+		if d.CurrentN > 0 {
+			row["current"] = d.Current
+		}
+		if d.BaselineN > 0 && d.CurrentN > 0 {
+			if d.Baseline != 0 {
+				row["delta_pct"] = d.DeltaPct
+			}
+			switch {
+			case d.Delta == 0:
+				row["trend"] = "="
+			case d.Improved:
+				row["trend"] = "better"
+			default:
+				row["trend"] = "worse"
+			}
+		}
+		if d.Significance != analysis.SignificanceNone {
+			row["significance"] = d.Significance
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
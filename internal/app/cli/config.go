@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings and profiles",
+	Long: `Show and change the settings in ~/.gocube_recorder/config.json. Profiles
+are kept there with the recorder's other settings.
+
+Profiles are named sets of settings, e.g. one per cube or solving method.
+Every command uses the profile given with --profile, or the config's
+default profile. Profile settings:
+
+  device        UUID of the preferred cube, used when it is in range
+                (gocube status shows the last device's UUID)
+  method        Solving method, recorded as the "method" context of solves
+  scan_timeout  Seconds to scan for cubes (default 5)
+  db            Database file, unless --db is given
+  report_dir    Base directory for reports (default ./reports)
+  led           "on" (default) or "off" to keep the backlight dark
+  metric        Turn metric for move counts, TPS and efficiency in reports,
+                trends and the record TUI: htm (default), qtm or stm
+
+Set "profile" to choose the default profile. Setting a profile setting
+creates the profile if needed; with no profile at all it creates one
+called "default".
+
+  gocube config set device 3F2A...
+  gocube --profile oh config set method roux
+  gocube config set profile oh
+  gocube config get`,
+	// Config commands must run even if --profile names a missing profile,
+	// so it can be created.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show the profile settings, or one of them",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a profile setting; an empty value unsets it",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+}
+
+// configProfileName returns the profile config get and set work on: the
+// one named with --profile, else the default profile.
+func configProfileName(cfg recorder.Config) string {
+	if profileName != "" {
+		return profileName
+	}
+	return cfg.Profile
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	name := configProfileName(cfg)
+	if len(args) == 1 && args[0] == "profile" {
+		fmt.Println(cfg.Profile)
+		return nil
+	}
+
+	p, ok := cfg.Profiles[name]
+	if name != "" && !ok {
+		return fmt.Errorf("no profile named %q", name)
+	}
+
+	if len(args) == 1 {
+		value, err := p.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	fmt.Printf("Config:  %s\n", path)
+	if name == "" {
+		fmt.Println("Profile: (none, using defaults)")
+	} else {
+		fmt.Printf("Profile: %s\n", name)
+	}
+	for _, key := range recorder.ProfileKeys() {
+		value, _ := p.Get(key)
+		if value == "" {
+			value = "(default)"
+		}
+		fmt.Printf("  %-13s %s\n", key, value)
+	}
+
+	if len(cfg.Profiles) > 0 {
+		names := make([]string, 0, len(cfg.Profiles))
+		for n := range cfg.Profiles {
+			if n == cfg.Profile {
+				n += " (default)"
+			}
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		fmt.Println()
+		fmt.Println("Profiles:")
+		for _, n := range names {
+			fmt.Printf("  %s\n", n)
+		}
+	}
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]recorder.Profile)
+	}
+
+	if key == "profile" {
+		if _, ok := cfg.Profiles[value]; value != "" && !ok {
+			cfg.Profiles[value] = recorder.Profile{}
+			fmt.Printf("Created profile %q\n", value)
+		}
+		cfg.Profile = value
+	} else {
+		name := configProfileName(cfg)
+		if name == "" {
+			name = recorder.DefaultProfileName
+			cfg.Profile = name
+		}
+		p, ok := cfg.Profiles[name]
+		if !ok {
+			fmt.Printf("Created profile %q\n", name)
+		}
+		if err := p.Set(key, value); err != nil {
+			return err
+		}
+		cfg.Profiles[name] = p
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := recorder.SaveConfig(path, cfg); err != nil {
+		return err
+	}
+
+	if key == "profile" && value == "" {
+		fmt.Println("Default profile cleared")
+	} else if key == "profile" {
+		fmt.Printf("Default profile: %s\n", value)
+	} else if value == "" {
+		fmt.Printf("%s unset\n", key)
+	} else {
+		fmt.Printf("%s = %s\n", key, value)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+//go:build !js
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+)
+
+func TestConfigSetProfiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	defer func(name string) { profileName = name }(profileName)
+	path, err := recorder.DefaultConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings already in the file survive editing profiles
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"pacing": {"enabled": true, "budgets_ms": {"cross": 8000}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	set := func(profile, key, value string) error {
+		profileName = profile
+		return runConfigSet(configSetCmd, []string{key, value})
+	}
+	if err := set("", "device", "3F2A-11"); err != nil {
+		t.Fatalf("config set device: %v", err)
+	}
+	if err := set("oh", "method", "roux"); err != nil {
+		t.Fatalf("config --profile oh set method: %v", err)
+	}
+	if err := set("", "profile", "oh"); err != nil {
+		t.Fatalf("config set profile: %v", err)
+	}
+	if err := set("", "led", "blink"); err == nil {
+		t.Error("config set led blink succeeded")
+	}
+
+	cfg, err := recorder.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Profile != "oh" || cfg.Profiles["oh"].Method != "roux" || cfg.Profiles["oh"].LED != "" {
+		t.Errorf("profile %q with %+v; want oh with method roux", cfg.Profile, cfg.Profiles["oh"])
+	}
+	if cfg.Profiles[recorder.DefaultProfileName].Device != "3F2A-11" {
+		t.Errorf("default profile = %+v, want device 3F2A-11", cfg.Profiles[recorder.DefaultProfileName])
+	}
+	if !cfg.Pacing.Enabled || cfg.Pacing.BudgetsMs["cross"] != 8000 {
+		t.Errorf("pacing = %+v, want the settings written before", cfg.Pacing)
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// safetyOptions are the flags shared by commands that destroy data.
+type safetyOptions struct {
+	DryRun bool
+	Yes    bool
+}
+
+// addSafetyFlags registers --dry-run and --yes on cmd.
+func addSafetyFlags(cmd *cobra.Command, opts *safetyOptions) {
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would be affected without changing anything")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Do not ask for confirmation (for scripts)")
+}
+
+// confirm asks the user to approve a destructive action unless --yes was
+// given. Anything but "y" or "yes", including end of input, declines.
+func confirm(opts safetyOptions, prompt string) error {
+	if opts.Yes {
+		return nil
+	}
+
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		return nil
+	}
+	if err != nil {
+		fmt.Println()
+		return fmt.Errorf("aborted: no confirmation (use --yes for non-interactive use)")
+	}
+	return fmt.Errorf("aborted")
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// parseContextPair parses "key=value". An empty value removes the key.
+func parseContextPair(s string) (key, value string, err error) {
+	idx := strings.Index(s, "=")
+	if idx < 0 {
+		return "", "", fmt.Errorf("expected key=value, got %q", s)
+	}
+	key = strings.TrimSpace(s[:idx])
+	value = strings.TrimSpace(s[idx+1:])
+	if key == "" {
+		return "", "", fmt.Errorf("context key must not be empty")
+	}
+	return key, value, nil
+}
+
+// mergeContext returns defaults overlaid with overrides.
+func mergeContext(defaults, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// formatContext renders context as "key=value, key=value" sorted by key.
+func formatContext(context map[string]string) string {
+	keys := make([]string, 0, len(context))
+	for k := range context {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+context[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// filterSolvesByContext keeps only solves whose context matches every pair
+// in filter.
+func filterSolvesByContext(db *storage.DB, solves []storage.Solve, filter map[string]string) ([]storage.Solve, error) {
+	if len(filter) == 0 {
+		return solves, nil
+	}
+
+	contextRepo := storage.NewContextRepository(db)
+	var filtered []storage.Solve
+	for _, s := range solves {
+		ok, err := contextRepo.Matches(s.SolveID, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
+
+// handleContextKey edits the solve context from the TUI prompt. Each
+// "key=value" line sets a value ("key=" removes it); an empty line or ESC
+// closes the prompt. Changes apply to the next solve, and to the current
+// one if recording.
+func (m *recordModel) handleContextKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.contextInput = false
+		m.contextBuf = ""
+
+	case tea.KeyEnter:
+		line := strings.TrimSpace(m.contextBuf)
+		m.contextBuf = ""
+		if line == "" {
+			m.contextInput = false
+			return
+		}
+
+		key, value, err := parseContextPair(line)
+		if err != nil {
+			m.notice = err.Error()
+			return
+		}
+		if value == "" {
+			delete(m.context, key)
+		} else {
+			m.context[key] = value
+		}
+		m.notice = ""
+
+		if m.recording && m.solveID != "" {
+			contextRepo := storage.NewContextRepository(m.db)
+			if value == "" {
+				err = contextRepo.Delete(m.solveID, key)
+			} else {
+				err = contextRepo.Set(m.solveID, key, value)
+			}
+			if err != nil {
+				m.err = err
+			}
+		}
+
+	case tea.KeyBackspace:
+		if len(m.contextBuf) > 0 {
+			runes := []rune(m.contextBuf)
+			m.contextBuf = string(runes[:len(runes)-1])
+		}
+
+	case tea.KeySpace:
+		m.contextBuf += " "
+
+	case tea.KeyRunes:
+		m.contextBuf += string(msg.Runes)
+	}
+}
+
+// renderContext renders the context line and, when open, the prompt.
+func (m *recordModel) renderContext() string {
+	var b strings.Builder
+	if len(m.context) > 0 {
+		b.WriteString(fmt.Sprintf("Context: %s\n", formatContext(m.context)))
+	}
+	if m.contextInput {
+		b.WriteString(phaseStyle.Render(fmt.Sprintf("Context (key=value, empty line to finish): %s_", m.contextBuf)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
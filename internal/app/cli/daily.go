@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	dailyDate         string
+	dailySolveReports bool
+	dailyTurnDays     int
+	dailyDashboard    bool
+	dailyDashboardDir string
+)
+
+var reportDailyCmd = &cobra.Command{
+	Use:   "daily",
+	Short: "Generate a summary report for one day",
+	Long: `Generate a summary of all solves recorded on a given day (default: today,
+local time) into reports/daily/YYYY-MM-DD/.
+
+Files created:
+  - daily_summary.json: Solve list, best, average and ao5 for the day
+  - trend_report.json:  Trend analysis restricted to the day
+  - summary.md:         Human-readable summary
+  - dashboard/:         The practice dashboard (see "report dashboard")
+
+The summary includes a turn speed profile over the --turn-days days up to
+the report date: average execution time per face and direction, the
+slowest turns and finger-trick practice recommendations.
+
+Per-solve reports are regenerated as well unless --solve-reports=false.
+The dashboard holds the last --window solves up to the end of the day; it
+is written to --dashboard-dir instead when set, for example the directory
+you host it from, and skipped with --dashboard=false.
+Exits successfully when there are no solves, so it is safe to run from cron:
+
+  55 23 * * * gocube report daily`,
+	RunE: runReportDaily,
+}
+
+func init() {
+	reportCmd.AddCommand(reportDailyCmd)
+	reportDailyCmd.Flags().StringVar(&dailyDate, "date", "", "Day to report (YYYY-MM-DD, default: today)")
+	reportDailyCmd.Flags().BoolVar(&dailySolveReports, "solve-reports", true, "Regenerate per-solve reports")
+	reportDailyCmd.Flags().IntVar(&dailyTurnDays, "turn-days", 30, "Days of solves in the turn speed profile (0 to skip)")
+	reportDailyCmd.Flags().BoolVar(&dailyDashboard, "dashboard", true, "Regenerate the dashboard")
+	reportDailyCmd.Flags().StringVar(&dailyDashboardDir, "dashboard-dir", "", "Dashboard directory (default: dashboard/ in the daily report)")
+	reportDailyCmd.Flags().IntVar(&dashboardWindow, "window", 200, "Number of recent solves in the dashboard")
+	reportDailyCmd.Flags().StringVarP(&reportOutputDir, "output", "o", "", "Base output directory (default: reports)")
+}
+
+// DailySummary is the JSON structure for daily_summary.json
+type DailySummary struct {
+	Date            string                    `json:"date"`
+	GeneratedAt     string                    `json:"generated_at"`
+	TotalSolves     int                       `json:"total_solves"`
+	CompletedSolves int                       `json:"completed_solves"`
+	ManualSolves    int                       `json:"manual_solves"`
+	TotalMoves      int                       `json:"total_moves"`
+	BestMs          int64                     `json:"best_ms,omitempty"`
+	AvgMs           float64                   `json:"avg_ms,omitempty"`
+	Ao5Ms           float64                   `json:"ao5_ms,omitempty"`
+	Solves          []DailySolveEntry         `json:"solves"`
+	TurnSpeed       *analysis.TurnSpeedReport `json:"turn_speed,omitempty"`
+}
+
+// DailySolveEntry is a single solve in the daily summary.
+type DailySolveEntry struct {
+	SolveID    string `json:"solve_id"`
+	StartedAt  string `json:"started_at"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	MoveCount  int    `json:"move_count"`
+	Manual     bool   `json:"manual,omitempty"`
+	ReportDir  string `json:"report_dir,omitempty"`
+}
+
+func runReportDaily(cmd *cobra.Command, args []string) error {
+	loc := displayLocation()
+	day := time.Now().In(loc)
+	if dailyDate != "" {
+		d, err := time.ParseInLocation("2006-01-02", dailyDate, loc)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q (want YYYY-MM-DD): %w", dailyDate, err)
+		}
+		day = d
+	}
+	// Calendar arithmetic, so a day is 23 or 25 hours across DST changes
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, 1)
+	dateStr := start.Format("2006-01-02")
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+	orientRepo := storage.NewOrientationRepository(db)
+
+	solves, err := solveRepo.ListBetween(start, end)
+	if err != nil {
+		return err
+	}
+	solves = filterSolvesByDevice(solves, reportDevice)
+
+	if len(solves) == 0 {
+		fmt.Printf("No solves recorded on %s\n", dateStr)
+		return nil
+	}
+
+	baseDir := reportOutputDir
+	if baseDir == "" {
+		baseDir = reportsDir()
+	}
+	outputDir := filepath.Join(baseDir, "daily", dateStr)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	fmt.Printf("Generating daily report for %s (%d solves)...\n", dateStr, len(solves))
+
+	summary := DailySummary{
+		Date:        dateStr,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		TotalSolves: len(solves),
+		Solves:      make([]DailySolveEntry, 0, len(solves)),
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	bar := newProgress("Summarizing", len(solves))
+	for i, s := range solves {
+		if ctx.Err() != nil {
+			bar.Done()
+			return interrupted(i, len(solves), "solves")
+		}
+		bar.Step()
+		entry := DailySolveEntry{
+			SolveID:   s.SolveID,
+			StartedAt: s.StartedAt.Format(time.RFC3339),
+			Manual:    s.IsManual(),
+		}
+		if s.DurationMs != nil {
+			entry.DurationMs = *s.DurationMs
+		}
+		if !entry.Manual {
+			entry.MoveCount = solveMoveCount(moveRepo, s.SolveID)
+			summary.TotalMoves += entry.MoveCount
+		}
+
+		if dailySolveReports && !entry.Manual && s.EndedAt != nil {
+			reportDir, err := GenerateReportForSolve(db, s.SolveID)
+			if err != nil {
+				bar.Done()
+				fmt.Printf("  Warning: report for %s failed: %v\n", s.SolveID[:8], err)
+			} else {
+				entry.ReportDir = reportDir
+			}
+		}
+
+		summary.Solves = append(summary.Solves, entry)
+	}
+
+	bar.Done()
+
+	solveData, err := buildSolveData(ctx, solves, moveRepo, phaseRepo, orientRepo, storage.NewEventRepository(db))
+	if err != nil {
+		return err
+	}
+	trendReport := analysis.AnalyzeTrends(solveData)
+	trendReport.SuperPhaseTrends = analysis.AnalyzeSuperPhaseTrends(solveData, loadSuperPhases())
+	summary.CompletedSolves = trendReport.CompletedSolves
+	summary.ManualSolves = trendReport.ManualSolves
+	if trendReport.CompletedSolves > 0 {
+		summary.BestMs = trendReport.BestSolve.DurationMs
+		summary.AvgMs = trendReport.AvgDurationMs
+		summary.Ao5Ms = trendReport.RollingAvgs[5]
+	}
+
+	if dailyTurnDays > 0 {
+		summary.TurnSpeed, err = profileTurnSpeed(solveRepo, moveRepo, phaseRepo, orientRepo, end.AddDate(0, 0, -dailyTurnDays), end)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(filepath.Join(outputDir, "daily_summary.json"), summary); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(outputDir, "trend_report.json"), trendReport); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "summary.md"), []byte(formatDailyMarkdown(summary)), 0644); err != nil {
+		return fmt.Errorf("failed to write summary.md: %w", err)
+	}
+
+	dashboardDir := ""
+	if dailyDashboard {
+		dashboardDir = dailyDashboardDir
+		if dashboardDir == "" {
+			dashboardDir = filepath.Join(outputDir, "dashboard")
+		}
+		if err := writeDailyDashboard(ctx, db, dashboardDir, end); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fmt.Printf("  Warning: dashboard failed: %v\n", err)
+			dashboardDir = ""
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Daily report generated: %s\n", outputDir)
+	fmt.Printf("  Solves: %d (completed: %d)\n", summary.TotalSolves, summary.CompletedSolves)
+	if summary.CompletedSolves > 0 {
+		fmt.Printf("  Best: %.2fs  Average: %.2fs\n", float64(summary.BestMs)/1000.0, summary.AvgMs/1000.0)
+	}
+	if ts := summary.TurnSpeed; ts != nil && len(ts.Recommendations) > 0 {
+		fmt.Printf("  Slowest turn: %s\n", ts.Recommendations[0])
+	}
+	if dashboardDir != "" {
+		fmt.Printf("  Dashboard: %s\n", dashboardDir)
+	}
+
+	return nil
+}
+
+// writeDailyDashboard regenerates the dashboard in dir from the last
+// --window solves started before end.
+func writeDailyDashboard(ctx context.Context, db *storage.DB, dir string, end time.Time) error {
+	solves, err := storage.NewSolveRepository(db).ListBetween(time.Time{}, end)
+	if err != nil {
+		return err
+	}
+	solves = filterSolvesByDevice(solves, reportDevice)
+	if len(solves) > dashboardWindow {
+		solves = solves[len(solves)-dashboardWindow:]
+	}
+
+	now := time.Now()
+	data, err := buildDashboardData(ctx, db, solves, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dashboard directory: %w", err)
+	}
+	return writeDashboard(dir, data, now.UTC().Format("20060102T150405Z"))
+}
+
+// profileTurnSpeed profiles turn speed over the solving windows of completed
+// recorded solves started in [start, end).
+func profileTurnSpeed(solveRepo *storage.SolveRepository, moveRepo *storage.MoveRepository, phaseRepo *storage.PhaseRepository, orientRepo *storage.OrientationRepository, start, end time.Time) (*analysis.TurnSpeedReport, error) {
+	solves, err := solveRepo.ListBetween(start, end)
+	if err != nil {
+		return nil, err
+	}
+	solves = filterSolvesByDevice(solves, reportDevice)
+
+	var windows [][]storage.MoveRecord
+	for _, s := range solves {
+		if s.IsManual() || s.EndedAt == nil {
+			continue
+		}
+		segments, _ := phaseRepo.GetPhaseSegments(s.SolveID)
+		moves, _ := loadSolveWindow(s.SolveID, segments, moveRepo, orientRepo)
+		windows = append(windows, moves)
+	}
+	return analysis.ProfileTurnSpeed(windows), nil
+}
+
+// formatDailyMarkdown renders the daily summary as Markdown.
+func formatDailyMarkdown(s DailySummary) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Daily Summary: %s\n\n", s.Date)
+	fmt.Fprintf(&b, "- Solves: %d (completed: %d, manual: %d)\n", s.TotalSolves, s.CompletedSolves, s.ManualSolves)
+	fmt.Fprintf(&b, "- Total moves: %d\n", s.TotalMoves)
+	if s.CompletedSolves > 0 {
+		fmt.Fprintf(&b, "- Best: %.2fs\n", float64(s.BestMs)/1000.0)
+		fmt.Fprintf(&b, "- Average: %.2fs\n", s.AvgMs/1000.0)
+	}
+	if s.Ao5Ms > 0 {
+		fmt.Fprintf(&b, "- ao5: %.2fs\n", s.Ao5Ms/1000.0)
+	}
+
+	b.WriteString("\n| Time | Duration | Moves | Report |\n")
+	b.WriteString("|------|----------|-------|--------|\n")
+	for _, e := range s.Solves {
+		started, _ := time.Parse(time.RFC3339, e.StartedAt)
+		duration := "-"
+		if e.DurationMs > 0 {
+			duration = formatDuration(time.Duration(e.DurationMs) * time.Millisecond)
+		}
+		moves := fmt.Sprintf("%d", e.MoveCount)
+		if e.Manual {
+			moves = "manual"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+			localTime(started).Format("15:04:05"), duration, moves, e.ReportDir)
+	}
+
+	if ts := s.TurnSpeed; ts != nil && ts.TimedTurns > 0 {
+		fmt.Fprintf(&b, "\n## Turn Speed (last %d days)\n\n", dailyTurnDays)
+		fmt.Fprintf(&b, "%d timed turns over %d solves, %.0fms per turn on average.\n", ts.TimedTurns, ts.Solves, ts.OverallAvgMs)
+		if len(ts.Slowest) > 0 {
+			b.WriteString("\n| Turn | Avg | vs average | Count |\n")
+			b.WriteString("|------|-----|------------|-------|\n")
+			for _, t := range ts.Slowest {
+				fmt.Fprintf(&b, "| %s | %.0fms | %+.0f%% | %d |\n", t.Notation, t.AvgMs, t.VsOverallPct, t.Count)
+			}
+			b.WriteString("\n### Practice\n\n")
+			for _, r := range ts.Recommendations {
+				fmt.Fprintf(&b, "- %s\n", r)
+			}
+		} else {
+			b.WriteString("\nNo turn is notably slower than the others.\n")
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,55 @@
+//go:build !js
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReportDailyDashboard(t *testing.T) {
+	simulateTestSolves(t, "R U F")
+	defer func(out, date, dir string, dashboard, solveReports bool) {
+		reportOutputDir, dailyDate, dailyDashboardDir, dailyDashboard, dailySolveReports = out, date, dir, dashboard, solveReports
+	}(reportOutputDir, dailyDate, dailyDashboardDir, dailyDashboard, dailySolveReports)
+	reportOutputDir = t.TempDir()
+	dailyDate = time.Now().In(displayLocation()).Format("2006-01-02")
+	dailySolveReports = false
+
+	dayDir := filepath.Join(reportOutputDir, "daily", dailyDate)
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	dailyDashboard, dailyDashboardDir = true, ""
+	if err := runReportDaily(reportDailyCmd, nil); err != nil {
+		t.Fatalf("runReportDaily: %v", err)
+	}
+	for _, name := range []string{"daily_summary.json", "summary.md", "dashboard/index.html", "dashboard/sw.js"} {
+		if !exists(filepath.Join(dayDir, name)) {
+			t.Errorf("%s missing from the daily report", name)
+		}
+	}
+
+	// A hosted dashboard directory is refreshed in place
+	hosted := filepath.Join(t.TempDir(), "site")
+	dailyDashboardDir = hosted
+	if err := runReportDaily(reportDailyCmd, nil); err != nil {
+		t.Fatalf("runReportDaily --dashboard-dir: %v", err)
+	}
+	if !exists(filepath.Join(hosted, "index.html")) {
+		t.Error("dashboard not written to --dashboard-dir")
+	}
+
+	os.RemoveAll(dayDir)
+	dailyDashboard = false
+	if err := runReportDaily(reportDailyCmd, nil); err != nil {
+		t.Fatalf("runReportDaily --dashboard=false: %v", err)
+	}
+	if !exists(filepath.Join(dayDir, "summary.md")) || exists(filepath.Join(dayDir, "dashboard")) {
+		t.Error("--dashboard=false should write the summary and no dashboard")
+	}
+}
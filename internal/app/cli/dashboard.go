@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+//go:embed dashboard_template.html
+var dashboardTemplate string
+
+var (
+	dashboardWindow  int
+	dashboardContext map[string]string
+)
+
+var reportDashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Generate an installable offline stats dashboard",
+	Long: `Generate a practice dashboard as a Progressive Web App: averages, a solve
+time chart, phase splits and recent solves, with the data bundled in.
+
+The output directory holds index.html, manifest.webmanifest, sw.js and
+icon.svg. Opening index.html works anywhere; to install it on a phone and
+browse it offline, put the directory on any HTTPS static host (service
+workers need HTTPS) and use "Add to Home Screen".
+
+Re-run the command and upload the directory again to refresh the data.
+The installed app picks up the new version the next time it is opened
+online and keeps the last version it saw when offline.
+
+  gocube report dashboard -o ~/Sites/cube`,
+	RunE: runReportDashboard,
+}
+
+func init() {
+	reportCmd.AddCommand(reportDashboardCmd)
+	reportDashboardCmd.Flags().IntVar(&dashboardWindow, "window", 200, "Number of recent solves to include")
+	reportDashboardCmd.Flags().StringToStringVar(&dashboardContext, "context", nil, "Only include solves with this context (e.g. lube=fresh)")
+	reportDashboardCmd.Flags().StringVarP(&reportOutputDir, "output", "o", "", "Output directory (default: ./reports/dashboard)")
+}
+
+// DashboardData is the data bundled into the dashboard page.
+type DashboardData struct {
+	GeneratedAt string                `json:"generated_at"`
+	AppVersion  string                `json:"app_version"`
+	TimeZone    string                `json:"time_zone"`
+	PhaseNames  map[string]string     `json:"phase_names"`
+	Trend       *analysis.TrendReport `json:"trend"`
+}
+
+func runReportDashboard(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solves, err := storage.NewSolveRepository(db).List(dashboardWindow)
+	if err != nil {
+		return fmt.Errorf("failed to get solves: %w", err)
+	}
+	solves = filterSolvesByDevice(solves, reportDevice)
+	solves, err = filterSolvesByContext(db, solves, dashboardContext)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	now := time.Now()
+	data, err := buildDashboardData(ctx, db, solves, now)
+	if err != nil {
+		return err
+	}
+
+	outputDir := reportOutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(reportsDir(), "dashboard")
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := writeDashboard(outputDir, data, now.UTC().Format("20060102T150405Z")); err != nil {
+		return err
+	}
+
+	fmt.Printf("Dashboard generated: %s (%d solves)\n", outputDir, data.Trend.CompletedSolves)
+	fmt.Println("Open index.html, or host the directory over HTTPS to install it as an app.")
+	return nil
+}
+
+// buildDashboardData analyzes solves into the data bundled into the page.
+func buildDashboardData(ctx context.Context, db *storage.DB, solves []storage.Solve, now time.Time) (DashboardData, error) {
+	solveData, err := buildSolveData(ctx, solves, storage.NewMoveRepository(db), storage.NewPhaseRepository(db), storage.NewOrientationRepository(db), storage.NewEventRepository(db))
+	if err != nil {
+		return DashboardData{}, err
+	}
+	if len(solveData) == 0 {
+		return DashboardData{}, fmt.Errorf("no completed solves found")
+	}
+	trend := analysis.AnalyzeTrends(solveData)
+	trend.SuperPhaseTrends = analysis.AnalyzeSuperPhaseTrends(solveData, loadSuperPhases())
+
+	data := DashboardData{
+		GeneratedAt: localTime(now).Format(time.RFC3339),
+		AppVersion:  version,
+		TimeZone:    displayLocation().String(),
+		PhaseNames:  make(map[string]string),
+		Trend:       trend,
+	}
+	for key := range trend.PhaseTrends {
+		data.PhaseNames[key] = storage.PhaseDisplayName(key)
+	}
+	for key := range trend.SuperPhaseTrends {
+		data.PhaseNames[key] = key
+	}
+	return data, nil
+}
+
+// writeDashboard writes the page and the PWA files. build names the
+// service worker cache, so each generation replaces the last.
+func writeDashboard(outputDir string, data DashboardData, build string) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshaling dashboard data: %w", err)
+	}
+	tmpl, err := template.New("dashboard").Parse(dashboardTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing dashboard template: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("creating dashboard file: %w", err)
+	}
+	defer f.Close()
+	if err := tmpl.Execute(f, map[string]template.JS{"DashboardJSON": template.JS(jsonData)}); err != nil {
+		return fmt.Errorf("executing dashboard template: %w", err)
+	}
+
+	files := map[string]string{
+		"manifest.webmanifest": dashboardManifest,
+		"sw.js":                fmt.Sprintf(dashboardServiceWorker, build),
+		"icon.svg":             dashboardIcon,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+const dashboardManifest = `{
+  "name": "GoCube Practice Dashboard",
+  "short_name": "GoCube",
+  "start_url": "./",
+  "scope": "./",
+  "display": "standalone",
+  "background_color": "#0f172a",
+  "theme_color": "#0f172a",
+  "icons": [
+    {"src": "icon.svg", "sizes": "any", "type": "image/svg+xml", "purpose": "any maskable"}
+  ]
+}
+`
+
+// dashboardServiceWorker serves the files network first, so a regenerated
+// dashboard shows up when online, and from the cache when offline. %s is
+// the build stamp; activating a new build drops the old caches.
+const dashboardServiceWorker = `const CACHE = "gocube-dashboard-%s";
+const FILES = ["./", "index.html", "manifest.webmanifest", "icon.svg"];
+
+self.addEventListener("install", (event) => {
+  event.waitUntil(caches.open(CACHE).then((cache) => cache.addAll(FILES)));
+  self.skipWaiting();
+});
+
+self.addEventListener("activate", (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((k) => k !== CACHE).map((k) => caches.delete(k))))
+      .then(() => self.clients.claim())
+  );
+});
+
+self.addEventListener("fetch", (event) => {
+  if (event.request.method !== "GET") return;
+  event.respondWith(
+    fetch(event.request)
+      .then((resp) => {
+        const copy = resp.clone();
+        caches.open(CACHE).then((cache) => cache.put(event.request, copy));
+        return resp;
+      })
+      .catch(() => caches.match(event.request).then((hit) => hit || caches.match("index.html")))
+  );
+});
+`
+
+const dashboardIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 96 96">
+  <rect width="96" height="96" rx="16" fill="#0f172a"/>
+  <g stroke="#0f172a" stroke-width="3">
+    <rect x="15" y="15" width="22" height="22" rx="3" fill="#ef4444"/>
+    <rect x="37" y="15" width="22" height="22" rx="3" fill="#f8fafc"/>
+    <rect x="59" y="15" width="22" height="22" rx="3" fill="#22c55e"/>
+    <rect x="15" y="37" width="22" height="22" rx="3" fill="#facc15"/>
+    <rect x="37" y="37" width="22" height="22" rx="3" fill="#3b82f6"/>
+    <rect x="59" y="37" width="22" height="22" rx="3" fill="#ef4444"/>
+    <rect x="15" y="59" width="22" height="22" rx="3" fill="#f97316"/>
+    <rect x="37" y="59" width="22" height="22" rx="3" fill="#22c55e"/>
+    <rect x="59" y="59" width="22" height="22" rx="3" fill="#f8fafc"/>
+  </g>
+</svg>
+`
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var rebuildSolveID string
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance",
+}
+
+var dbRebuildDerivedCmd = &cobra.Command{
+	Use:   "rebuild-derived",
+	Short: "Regenerate derived tables from moves and events",
+	Long: `Regenerate all derived data from the recorded source of truth (solves,
+moves, events and phase marks):
+
+  - derived_phase_segments, re-deriving state-based marks after resyncs
+  - checkpoints and pll_cases, replayed from the moves and resync states
+  - tool_detections, using the current algorithm library
+  - analysis_cache, dropped so analyses are recomputed on demand
+  - personal_bests, recomputed from every solve once the rebuild is done
+
+Use it after upgrading to a version that changes how derived data is
+computed, or to recover from a bug that stored bad derived rows. Solves
+still being recorded are skipped.
+
+Each solve is rebuilt in one transaction. Ctrl+C rolls back the solve
+being rebuilt and stops: solves already rebuilt keep their new data, and
+that solve and the rest keep their old data, as after an error. Personal
+bests are only recomputed once every solve is rebuilt.`,
+	RunE: runDBRebuildDerived,
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+
+	dbCmd.AddCommand(dbRebuildDerivedCmd)
+	dbRebuildDerivedCmd.Flags().StringVar(&rebuildSolveID, "id", "", "Only rebuild this solve")
+}
+
+func runDBRebuildDerived(cmd *cobra.Command, args []string) error {
+	if algoPath, err := recorder.DefaultAlgorithmsPath(); err == nil {
+		if err := loadAlgorithmLibrary(algoPath); err != nil {
+			fmt.Printf("Warning: %v (using built-in algorithms)\n", err)
+		}
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+
+	var solves []storage.Solve
+	if rebuildSolveID != "" {
+		solve, err := solveRepo.Get(rebuildSolveID)
+		if err != nil {
+			return fmt.Errorf("failed to get solve: %w", err)
+		}
+		if solve == nil {
+			return fmt.Errorf("solve not found: %s", rebuildSolveID)
+		}
+		solves = append(solves, *solve)
+	} else {
+		if solves, err = solveRepo.List(-1); err != nil { // All solves
+			return err
+		}
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	bar := newProgress("Rebuilding", len(solves))
+
+	var total recorder.RebuildStats
+	rebuilt, skipped, pllCases := 0, 0, 0
+	for i, solve := range solves {
+		if ctx.Err() != nil {
+			bar.Done()
+			printRebuildStats(rebuilt, skipped, pllCases, total)
+			return interrupted(i, len(solves), "solves")
+		}
+		bar.Step()
+		if solve.EndedAt == nil {
+			skipped++
+			continue
+		}
+		// Each solve is rebuilt in one transaction
+		var stats *recorder.RebuildStats
+		err := db.InTx(ctx, func(tx *storage.DB) error {
+			var err error
+			if stats, err = recorder.RebuildDerived(ctx, tx, solve.SolveID); err != nil {
+				return err
+			}
+			return updateToolDetections(ctx, tx, solve.SolveID)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				bar.Done()
+				printRebuildStats(rebuilt, skipped, pllCases, total)
+				return interrupted(i, len(solves), "solves")
+			}
+			return fmt.Errorf("solve %s: %w", solve.SolveID[:8], err)
+		}
+		total.Segments += stats.Segments
+		total.Checkpoints += stats.Checkpoints
+		total.CacheRows += stats.CacheRows
+		if stats.PLLCase != "" {
+			pllCases++
+		}
+		rebuilt++
+	}
+	bar.Done()
+
+	// Bests depend on every solve's segments, so even one rebuilt solve
+	// can change the history after it
+	pbs, err := recorder.RebuildPersonalBests(db)
+	if err != nil {
+		return err
+	}
+
+	printRebuildStats(rebuilt, skipped, pllCases, total)
+	fmt.Printf("  Personal bests:        %d\n", pbs)
+	printNewAchievements(db)
+	return nil
+}
+
+// printRebuildStats prints what rebuild-derived regenerated.
+func printRebuildStats(rebuilt, skipped, pllCases int, total recorder.RebuildStats) {
+	fmt.Printf("Rebuilt derived data for %d solves", rebuilt)
+	if skipped > 0 {
+		fmt.Printf(" (%d in progress skipped)", skipped)
+	}
+	fmt.Println()
+	fmt.Printf("  Phase segments:        %d\n", total.Segments)
+	fmt.Printf("  Checkpoints:           %d\n", total.Checkpoints)
+	fmt.Printf("  PLL cases recognized:  %d\n", pllCases)
+	fmt.Printf("  Cache entries dropped: %d\n", total.CacheRows)
+}
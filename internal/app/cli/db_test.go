@@ -0,0 +1,141 @@
+//go:build !js
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// simulateTestSolves records a simulated solve of each scramble into a
+// new database that openDB opens.
+func simulateTestSolves(t *testing.T, scrambles ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Cleanup(func(db, scramble, solve string, seed int64, reports, id string) func() {
+		return func() {
+			dbPath, simScramble, simSolve, simSeed, activeProfile.ReportDir, rebuildSolveID = db, scramble, solve, seed, reports, id
+		}
+	}(dbPath, simScramble, simSolve, simSeed, activeProfile.ReportDir, rebuildSolveID))
+	dbPath = filepath.Join(dir, "gocube.db")
+	activeProfile.ReportDir = filepath.Join(dir, "reports")
+	simSolve, simSeed, rebuildSolveID = "auto", 7, ""
+
+	for _, scramble := range scrambles {
+		simScramble = scramble
+		if err := runSimulate(simulateCmd, nil); err != nil {
+			t.Fatalf("runSimulate: %v", err)
+		}
+	}
+}
+
+func TestRebuildDerivedReplacesPersonalBests(t *testing.T) {
+	simulateTestSolves(t, "R U F", "L D' B2")
+
+	db, err := openDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	solves, err := storage.NewSolveRepository(db).List(-1)
+	if err != nil || len(solves) != 2 {
+		t.Fatalf("List = %d solves, %v; want 2", len(solves), err)
+	}
+
+	// A best recorded from a marker phase by an older release
+	pbRepo := storage.NewPersonalBestRepository(db)
+	stale := storage.PersonalBest{Kind: analysis.PBPhase, PhaseKey: "complete", ValueMs: 1,
+		SolveID: solves[0].SolveID, AchievedAt: time.Now()}
+	if _, err := pbRepo.Record(stale); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	if err := runDBRebuildDerived(dbRebuildDerivedCmd, nil); err != nil {
+		t.Fatalf("runDBRebuildDerived: %v", err)
+	}
+
+	history, err := pbRepo.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _, err := analysis.EvaluatePersonalBests(storage.NewSolveRepository(db), storage.NewPhaseRepository(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != len(want) || len(history) == 0 {
+		t.Errorf("%d personal bests after the rebuild, want the %d the solves give", len(history), len(want))
+	}
+	for _, pb := range history {
+		if pb.PhaseKey == "complete" {
+			t.Errorf("stale best %+v survived the rebuild", pb)
+		}
+	}
+}
+
+func TestRebuildDerivedRollsBackOnCancel(t *testing.T) {
+	simulateTestSolves(t, "R U F")
+	db, err := openDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	solve, err := storage.NewSolveRepository(db).GetLast()
+	if err != nil || solve == nil {
+		t.Fatalf("GetLast = %v, %v", solve, err)
+	}
+
+	// A derived row a bug stored, which a rebuild replaces
+	const bad = 987654
+	if _, err := db.Exec("UPDATE derived_phase_segments SET duration_ms = ? WHERE solve_id = ?", bad, solve.SolveID); err != nil {
+		t.Fatal(err)
+	}
+	durations := func() []int64 {
+		t.Helper()
+		segments, err := storage.NewPhaseRepository(db).GetPhaseSegments(solve.SolveID)
+		if err != nil || len(segments) == 0 {
+			t.Fatalf("GetPhaseSegments = %d segments, %v", len(segments), err)
+		}
+		var d []int64
+		for _, seg := range segments {
+			d = append(d, seg.DurationMs)
+		}
+		return d
+	}
+
+	// Ctrl+C once the solve's rows are replaced but before the commit
+	ctx, cancel := context.WithCancel(context.Background())
+	err = db.InTx(ctx, func(tx *storage.DB) error {
+		if _, err := recorder.RebuildDerived(ctx, tx, solve.SolveID); err != nil {
+			return err
+		}
+		if err := updateToolDetections(ctx, tx, solve.SolveID); err != nil {
+			return err
+		}
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled rebuild = %v, want context.Canceled", err)
+	}
+	for _, d := range durations() {
+		if d != bad {
+			t.Fatalf("segment durations %v after a cancelled rebuild, want them untouched", durations())
+		}
+	}
+
+	if _, err := recorder.RebuildDerived(context.Background(), db, solve.SolveID); err != nil {
+		t.Fatalf("RebuildDerived: %v", err)
+	}
+	for _, d := range durations() {
+		if d == bad {
+			t.Errorf("segment durations %v after a rebuild, want them recomputed", durations())
+		}
+	}
+}
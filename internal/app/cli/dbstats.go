@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// growthWindowDays is how many recent days growth rates are measured over.
+const growthWindowDays = 30
+
+var dbStatsJSON bool
+
+var dbStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show database size, growth rate and projected size",
+	Long: `Show how much space each table uses and how fast it grows.
+
+Growth is measured over the last 30 days (or the whole history, if
+shorter) from the solves' start dates, and projected 6 and 12 months ahead
+at that rate. Recommendations follow when the projection is large, raw
+events dominate, or the file has space to give back.`,
+	RunE: runDBStats,
+}
+
+func init() {
+	dbCmd.AddCommand(dbStatsCmd)
+	dbStatsCmd.Flags().BoolVar(&dbStatsJSON, "json", false, "Print the stats as JSON")
+}
+
+// DBTableStats is the size and growth of one table.
+type DBTableStats struct {
+	Name        string  `json:"name"`
+	Rows        int     `json:"rows"`
+	Bytes       int64   `json:"bytes"`
+	RowsPerDay  float64 `json:"rows_per_day"`
+	BytesPerDay float64 `json:"bytes_per_day"`
+}
+
+// DBStats is the output of db stats.
+type DBStats struct {
+	Path              string         `json:"path"`
+	FileBytes         int64          `json:"file_bytes"` // Database and WAL files
+	FreeBytes         int64          `json:"free_bytes"` // Unused pages VACUUM would give back
+	Solves            int            `json:"solves"`
+	FirstDay          string         `json:"first_day,omitempty"`
+	LastDay           string         `json:"last_day,omitempty"`
+	WindowDays        int            `json:"window_days"`
+	Tables            []DBTableStats `json:"tables"`
+	BytesPerDay       float64        `json:"bytes_per_day"`
+	Projected6Months  int64          `json:"projected_6_months_bytes"`
+	Projected12Months int64          `json:"projected_12_months_bytes"`
+	Recommendations   []string       `json:"recommendations"`
+}
+
+func runDBStats(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	statsRepo := storage.NewStatsRepository(db)
+	sizes, err := statsRepo.TableSizes()
+	if err != nil {
+		return err
+	}
+	daily, err := statsRepo.DailyRowCounts()
+	if err != nil {
+		return err
+	}
+	freeBytes, err := statsRepo.FreeBytes()
+	if err != nil {
+		return err
+	}
+
+	var fileBytes int64
+	for _, suffix := range []string{"", "-wal"} {
+		if info, err := os.Stat(db.Path() + suffix); err == nil {
+			fileBytes += info.Size()
+		}
+	}
+
+	stats := buildDBStats(sizes, daily, time.Now().UTC())
+	stats.Path = db.Path()
+	stats.FileBytes = fileBytes
+	stats.FreeBytes = freeBytes
+	stats.Projected6Months = fileBytes + int64(stats.BytesPerDay*365/2)
+	stats.Projected12Months = fileBytes + int64(stats.BytesPerDay*365)
+
+	diskBytes := int64(-1)
+	if free, err := diskFree(filepath.Dir(db.Path())); err == nil {
+		diskBytes = int64(free)
+	} else if !errors.Is(err, errors.ErrUnsupported) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	stats.Recommendations = dbRecommendations(stats, diskBytes)
+
+	if dbStatsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+	printDBStats(stats)
+	return nil
+}
+
+// buildDBStats works out each table's growth from its rows per solve start
+// day over the last growthWindowDays days of history up to now.
+func buildDBStats(sizes []storage.TableSize, daily map[string]map[string]int, now time.Time) *DBStats {
+	stats := &DBStats{Tables: []DBTableStats{}, Recommendations: []string{}}
+
+	var days []string
+	for day, count := range daily["solves"] {
+		days = append(days, day)
+		stats.Solves += count
+	}
+	sort.Strings(days)
+
+	var windowStart string
+	if len(days) > 0 {
+		stats.FirstDay, stats.LastDay = days[0], days[len(days)-1]
+		stats.WindowDays = growthWindowDays
+		if first, err := time.Parse("2006-01-02", stats.FirstDay); err == nil {
+			if history := int(now.Sub(first).Hours()/24) + 1; history < stats.WindowDays {
+				stats.WindowDays = history
+			}
+		}
+		if stats.WindowDays < 1 {
+			stats.WindowDays = 1
+		}
+		windowStart = now.AddDate(0, 0, 1-stats.WindowDays).Format("2006-01-02")
+	}
+
+	for _, size := range sizes {
+		t := DBTableStats{Name: size.Name, Rows: size.Rows, Bytes: size.Bytes}
+		if stats.WindowDays > 0 {
+			recent := 0
+			for day, count := range daily[size.Name] {
+				if day >= windowStart {
+					recent += count
+				}
+			}
+			t.RowsPerDay = float64(recent) / float64(stats.WindowDays)
+			if size.Rows > 0 {
+				t.BytesPerDay = t.RowsPerDay * float64(size.Bytes) / float64(size.Rows)
+			}
+		}
+		stats.BytesPerDay += t.BytesPerDay
+		stats.Tables = append(stats.Tables, t)
+	}
+	return stats
+}
+
+// Thresholds for db stats recommendations.
+const (
+	dbLargeBytes        = 250 << 20 // A 12 month projection worth acting on
+	dbEventsShare       = 0.5       // Share of the database where events dominate
+	dbEventRowBytes     = 150       // Average event size worth compressing
+	dbFreeShare         = 0.2       // Share of free pages worth a VACUUM
+	dbMinVacuumBytes    = 10 << 20
+	dbDiskHeadroomRatio = 2 // Projected growth to keep free on the disk, as a multiple
+)
+
+// dbRecommendations suggests ways to keep the database small. diskBytes is
+// the free space on its disk, or -1 if unknown.
+func dbRecommendations(stats *DBStats, diskBytes int64) []string {
+	recs := []string{}
+
+	var total int64
+	var events *DBTableStats
+	for i, t := range stats.Tables {
+		total += t.Bytes
+		if t.Name == "events" {
+			events = &stats.Tables[i]
+		}
+	}
+
+	growth := stats.Projected12Months - stats.FileBytes
+	if diskBytes >= 0 && growth*dbDiskHeadroomRatio > diskBytes {
+		recs = append(recs, fmt.Sprintf("The disk has %s free but the database is projected to grow %s in 12 months: move it with --db or free up space.",
+			formatSize(diskBytes), formatSize(growth)))
+	}
+
+	if events != nil && total > 0 && float64(events.Bytes) >= dbEventsShare*float64(total) {
+		share := float64(events.Bytes) / float64(total) * 100
+		if stats.Projected12Months >= dbLargeBytes {
+			recs = append(recs, fmt.Sprintf("Raw events are %.0f%% of the database and add %s a day; pruning the events of old solves would save the most. Moves, orientations and phases are stored separately, so old events are only read by db rebuild-derived.",
+				share, formatSize(int64(events.BytesPerDay))))
+		}
+		if events.Rows > 0 && events.Bytes/int64(events.Rows) >= dbEventRowBytes {
+			recs = append(recs, fmt.Sprintf("Events average %d bytes of JSON each; compressing event payloads would shrink the largest table several times over.",
+				events.Bytes/int64(events.Rows)))
+		}
+	}
+
+	if stats.FreeBytes >= dbMinVacuumBytes && float64(stats.FreeBytes) >= dbFreeShare*float64(stats.FileBytes) {
+		recs = append(recs, fmt.Sprintf("%s of the file is free pages left by deleted rows: run VACUUM on it (sqlite3 %s VACUUM) to give the space back.",
+			formatSize(stats.FreeBytes), stats.Path))
+	}
+	return recs
+}
+
+func printDBStats(stats *DBStats) {
+	fmt.Printf("Database: %s (%s", stats.Path, formatSize(stats.FileBytes))
+	if stats.FreeBytes > 0 {
+		fmt.Printf(", %s free", formatSize(stats.FreeBytes))
+	}
+	fmt.Println(")")
+	if stats.Solves == 0 {
+		fmt.Println("No solves recorded yet")
+	} else {
+		fmt.Printf("History:  %d solves, %s to %s\n", stats.Solves, stats.FirstDay, stats.LastDay)
+	}
+	fmt.Println()
+
+	t := &table{Columns: []tableColumn{
+		{Key: "table", Title: "Table"},
+		{Key: "rows", Title: "Rows", Right: true},
+		{Key: "size", Title: "Size", Right: true, Format: func(v interface{}) string { return formatSize(v.(int64)) }},
+		{Key: "rows_per_day", Title: "Rows/day", Right: true, Format: func(v interface{}) string { return fmt.Sprintf("%.1f", v) }},
+		{Key: "mb_per_day", Title: "MB/day", Right: true, Format: func(v interface{}) string { return fmt.Sprintf("%.3f", v) }},
+	}}
+	for _, tbl := range stats.Tables {
+		t.Rows = append(t.Rows, map[string]interface{}{
+			"table":        tbl.Name,
+			"rows":         tbl.Rows,
+			"size":         tbl.Bytes,
+			"rows_per_day": tbl.RowsPerDay,
+			"mb_per_day":   tbl.BytesPerDay / (1 << 20),
+		})
+	}
+	t.Render(os.Stdout)
+	fmt.Println()
+
+	if stats.WindowDays > 0 {
+		fmt.Printf("Growth:    %.3f MB/day (last %d days)\n", stats.BytesPerDay/(1<<20), stats.WindowDays)
+		fmt.Printf("Projected: %s in 6 months, %s in 12 months\n", formatSize(stats.Projected6Months), formatSize(stats.Projected12Months))
+	}
+
+	if len(stats.Recommendations) > 0 {
+		fmt.Println()
+		fmt.Println("Recommendations:")
+		for _, r := range stats.Recommendations {
+			fmt.Printf("  - %s\n", r)
+		}
+	}
+}
+
+// formatSize formats a byte count, e.g. "12.3 MB" or "48 KB".
+func formatSize(n int64) string {
+	if n < 1<<20 {
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+}
@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+var (
+	calibrateTimeout time.Duration
+	calibrateForce   bool
+	deviceListTable  tableOptions
+	reportDevice     string
+)
+
+var deviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "Manage connected cubes",
+}
+
+var deviceCalibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Calibrate the cube's orientation sensor",
+	Long: `Walk through orientation calibration for the nearest cube:
+
+  1. Hold the cube white-up, green-front and press Enter; the reported
+     orientation is taken as the home pose
+  2. Tilt the cube so each other face points up in turn, pressing Enter
+     each time; the corrected orientation must report that face up
+
+If every check passes, the correction is stored for this cube and applied
+to its orientation data in future recordings. Use --force to store it even
+when a check fails.`,
+	RunE: runDeviceCalibrate,
+}
+
+var deviceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cubes with their lifetime usage",
+	Long: `List every cube that has recorded a solve, most recently seen first, with
+the solves and moves recorded on it and the average battery used per solve.
+Lifetime is every move the cube has counted itself, app or not, as of its
+last 'gocube sync-offline'.
+
+Battery drain is averaged over solves with at least two battery readings;
+solves during which the cube was charging are left out.
+
+Reports can be limited to one cube with --device (its ID, an ID prefix, or
+its name), e.g. to compare hardware:
+
+  gocube report trend --device GoCube_1A2B`,
+	RunE: runDeviceList,
+}
+
+func init() {
+	rootCmd.AddCommand(deviceCmd)
+
+	deviceCmd.AddCommand(deviceListCmd)
+	addTableFlags(deviceListCmd, &deviceListTable, "")
+
+	reportCmd.PersistentFlags().StringVar(&reportDevice, "device", "", "Only include solves recorded on this cube (ID, ID prefix or name; see \"gocube device list\")")
+
+	deviceCmd.AddCommand(deviceCalibrateCmd)
+	deviceCalibrateCmd.Flags().DurationVar(&calibrateTimeout, "timeout", 5*time.Second, "How long to wait for an orientation reading")
+	deviceCalibrateCmd.Flags().BoolVar(&calibrateForce, "force", false, "Store the calibration even if a check fails")
+}
+
+func runDeviceList(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	devices, err := storage.NewDeviceRepository(db).List()
+	if err != nil {
+		return err
+	}
+
+	lifetimes, err := storage.NewOfflineStatsRepository(db).Lifetimes()
+	if err != nil {
+		return err
+	}
+	lifetimeMoves := make(map[string]int)
+	for _, l := range lifetimes {
+		lifetimeMoves[l.DeviceID] = l.Moves
+	}
+
+	t := &table{Columns: []tableColumn{
+		{Key: "id", Title: "ID"},
+		{Key: "name", Title: "Name"},
+		{Key: "solves", Title: "Solves", Right: true},
+		{Key: "moves", Title: "Moves", Right: true},
+		{Key: "lifetime_moves", Title: "Lifetime", Right: true},
+		{Key: "battery_per_solve", Title: "Battery/solve", Right: true, Format: func(v interface{}) string {
+			return fmt.Sprintf("%.1f%%", v.(float64))
+		}},
+		{Key: "last_seen", Title: "Last seen", Format: func(v interface{}) string {
+			return localTime(v.(time.Time)).Format("2006-01-02 15:04")
+		}},
+	}}
+	for _, d := range devices {
+		row := map[string]interface{}{
+			"id":        d.DeviceID,
+			"solves":    d.Solves,
+			"moves":     d.Moves,
+			"last_seen": d.LastSeen,
+		}
+		if d.DeviceName != nil {
+			row["name"] = *d.DeviceName
+		}
+		if moves, ok := lifetimeMoves[d.DeviceID]; ok {
+			row["lifetime_moves"] = moves
+		}
+		if d.BatteryDrainPerSolve != nil {
+			row["battery_per_solve"] = *d.BatteryDrainPerSolve
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	if err := t.Apply(deviceListTable); err != nil {
+		return err
+	}
+	if deviceListTable.JSON {
+		return t.WriteJSON(os.Stdout)
+	}
+
+	if len(devices) == 0 {
+		fmt.Println("No cubes have recorded a solve yet")
+		return nil
+	}
+	t.Render(os.Stdout)
+	return nil
+}
+
+// matchesDevice reports whether a solve was recorded on the cube named by
+// device: its full ID, an ID prefix, or its name (case-insensitive).
+func matchesDevice(s storage.Solve, device string) bool {
+	if s.DeviceID != nil && strings.HasPrefix(strings.ToLower(*s.DeviceID), strings.ToLower(device)) {
+		return true
+	}
+	return s.DeviceName != nil && strings.EqualFold(*s.DeviceName, device)
+}
+
+// filterSolvesByDevice keeps only solves recorded on the cube selected with
+// --device. An empty device keeps every solve.
+func filterSolvesByDevice(solves []storage.Solve, device string) []storage.Solve {
+	if device == "" {
+		return solves
+	}
+	var filtered []storage.Solve
+	for _, s := range solves {
+		if matchesDevice(s, device) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// calibrationChecks are the faces the cube is tilted to after the home pose,
+// with the color that should then be on top.
+var calibrationChecks = []struct {
+	face  string
+	color string
+}{
+	{"F", "green"},
+	{"R", "red"},
+	{"B", "blue"},
+	{"L", "orange"},
+	{"D", "yellow"},
+}
+
+func runDeviceCalibrate(cmd *cobra.Command, args []string) error {
+	client, results, err := ScanForGoCube()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no GoCube found (turn a face to wake it)")
+	}
+
+	msgs := make(chan *protocol.Message, 256)
+	client.SetMessageCallback(func(msg *protocol.Message) {
+		select {
+		case msgs <- msg:
+		default:
+		}
+	})
+	if err := client.ConnectToResult(context.Background(), results[0]); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer client.Disconnect()
+	if err := client.EnableOrientation(); err != nil {
+		return fmt.Errorf("failed to enable orientation: %w", err)
+	}
+	fmt.Printf("Connected to %s\n", client.DeviceName())
+
+	input := quickstartInput()
+
+	fmt.Println()
+	fmt.Println("Hold the cube WHITE up, GREEN facing you, then press Enter.")
+	home, err := readOrientation(input, msgs)
+	if err != nil {
+		return err
+	}
+	correction := protocol.OrientationCorrection{X: home.X, Y: home.Y, Z: home.Z, W: home.W}
+	fmt.Printf("  Raw reading: up %s, front %s\n", home.UpFace, home.FrontFace)
+
+	failed := 0
+	for _, check := range calibrationChecks {
+		fmt.Println()
+		fmt.Printf("Tilt the cube so %s is on top, then press Enter.\n", check.color)
+		ev, err := readOrientation(input, msgs)
+		if err != nil {
+			return err
+		}
+		raw := ev.UpFace
+		correction.Correct(ev)
+		if ev.UpFace == check.face {
+			fmt.Printf("  OK: up %s (raw %s)\n", ev.UpFace, raw)
+		} else {
+			fmt.Printf("  MISMATCH: expected up %s, got %s (raw %s)\n", check.face, ev.UpFace, raw)
+			failed++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 && !calibrateForce {
+		return fmt.Errorf("%d of %d checks failed; calibration not stored (rerun, or use --force)", failed, len(calibrationChecks))
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := storage.NewCalibrationRepository(db).Set(client.DeviceUUID(), correction); err != nil {
+		return err
+	}
+	fmt.Printf("Calibration stored for %s; it applies to new recordings on this cube.\n", client.DeviceName())
+	return nil
+}
+
+// readOrientation waits for Enter, then returns the next orientation
+// reported by the cube.
+func readOrientation(input <-chan struct{}, msgs <-chan *protocol.Message) (*protocol.OrientationEvent, error) {
+	if !quickstartWait(input, msgs) {
+		return nil, fmt.Errorf("input closed")
+	}
+
+	timeout := time.After(calibrateTimeout)
+	for {
+		select {
+		case msg := <-msgs:
+			if msg.Type != protocol.MsgTypeOrientation {
+				continue
+			}
+			if ev, err := protocol.DecodeOrientation(msg.Payload); err == nil {
+				return ev, nil
+			}
+		case <-timeout:
+			return nil, fmt.Errorf("no orientation reading within %s (hold the cube still and try again)", calibrateTimeout)
+		}
+	}
+}
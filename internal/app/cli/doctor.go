@@ -0,0 +1,367 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment and permissions gocube needs",
+	Long: `Run a series of self-checks and print how to fix any that fail:
+
+  Bluetooth adapter     The adapter is present and can be enabled
+  Bluetooth permission  macOS has granted this terminal Bluetooth access
+  Database integrity    SQLite's integrity check passes
+  Disk space            There is room left next to the database
+  State file            state.json is readable and points at a database
+  Locks                 No other process holds the database, and the
+                        active solve marker refers to a solve in progress
+
+The command exits with an error if any check fails.`,
+	RunE:         runDoctor,
+	SilenceUsage: true, // Failed checks are not usage errors
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorStatus is the outcome of a single check.
+type doctorStatus int
+
+const (
+	doctorOK doctorStatus = iota
+	doctorSkip
+	doctorWarn
+	doctorFail
+)
+
+func (s doctorStatus) String() string {
+	switch s {
+	case doctorOK:
+		return " OK "
+	case doctorSkip:
+		return "SKIP"
+	case doctorWarn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// doctorResult is a check's outcome with remediation steps for failures.
+type doctorResult struct {
+	name   string
+	status doctorStatus
+	detail string
+	fixes  []string
+}
+
+const (
+	// doctorBluetoothTimeout bounds enabling the adapter; on macOS it blocks
+	// while the permission prompt is unanswered.
+	doctorBluetoothTimeout = 10 * time.Second
+
+	// doctorMinFreeBytes is the free space below which recording may fail.
+	doctorMinFreeBytes = 100 << 20
+)
+
+var errBluetoothTimeout = errors.New("timed out waiting for the Bluetooth adapter")
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	fmt.Println("GoCube Doctor")
+	fmt.Println("=============")
+	fmt.Println()
+
+	results, err := runDoctorChecks()
+	if err != nil {
+		return err
+	}
+	failed := printDoctorResults(os.Stdout, results)
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// runDoctorChecks runs every check against the configured database.
+func runDoctorChecks() ([]doctorResult, error) {
+	dbFile := getDBPath()
+	if dbFile == "" {
+		var err error
+		if dbFile, err = storage.DefaultDBPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	btErr := enableBluetooth()
+	return []doctorResult{
+		checkBluetoothAdapter(btErr),
+		checkBluetoothPermission(btErr),
+		checkDatabaseIntegrity(dbFile),
+		checkDiskSpace(dbFile),
+		checkStateFile(),
+		checkLocks(dbFile),
+	}, nil
+}
+
+// printDoctorResults writes one line per check to w, with the fixes for
+// warnings and failures, and returns how many checks failed.
+func printDoctorResults(w io.Writer, results []doctorResult) int {
+	failed := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "  [%s] %-22s %s\n", r.status, r.name, r.detail)
+		if r.status == doctorWarn || r.status == doctorFail {
+			for _, f := range r.fixes {
+				fmt.Fprintf(w, "         - %s\n", f)
+			}
+		}
+		if r.status == doctorFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// enableBluetooth enables the default adapter, giving up after
+// doctorBluetoothTimeout.
+func enableBluetooth() error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := ble.NewClient()
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(doctorBluetoothTimeout):
+		return errBluetoothTimeout
+	}
+}
+
+func checkBluetoothAdapter(btErr error) doctorResult {
+	r := doctorResult{name: "Bluetooth adapter"}
+	if btErr != nil {
+		r.status = doctorFail
+		r.detail = btErr.Error()
+		r.fixes = bluetoothHints()
+		return r
+	}
+	r.detail = "enabled"
+	return r
+}
+
+// checkBluetoothPermission infers the macOS (TCC) Bluetooth permission from
+// enabling the adapter: the permission itself can only be read with Full
+// Disk Access.
+func checkBluetoothPermission(btErr error) doctorResult {
+	r := doctorResult{name: "Bluetooth permission"}
+	if runtime.GOOS != "darwin" {
+		r.status = doctorSkip
+		r.detail = "only needed on macOS"
+		return r
+	}
+
+	fixes := []string{
+		"Allow your terminal app in System Settings > Privacy & Security > Bluetooth",
+		"If it is not listed, reset the permission so macOS asks again: tccutil reset BluetoothAlways",
+		"Quit and reopen the terminal after granting permission",
+	}
+	switch {
+	case btErr == nil:
+		r.detail = "granted"
+	case errors.Is(btErr, errBluetoothTimeout):
+		r.status = doctorFail
+		r.detail = "not granted (the adapter did not respond; a permission prompt may be waiting)"
+		r.fixes = fixes
+	default:
+		r.status = doctorWarn
+		r.detail = "unknown, the adapter could not be enabled"
+		r.fixes = fixes
+	}
+	return r
+}
+
+func checkDatabaseIntegrity(dbFile string) doctorResult {
+	r := doctorResult{name: "Database integrity"}
+	if _, err := os.Stat(dbFile); os.IsNotExist(err) {
+		r.status = doctorSkip
+		r.detail = "no database yet at " + dbFile
+		return r
+	}
+
+	restore := []string{
+		fmt.Sprintf("Recover what SQLite can still read: sqlite3 %s .recover | sqlite3 recovered.db, then use --db recovered.db", dbFile),
+		fmt.Sprintf("Or move %s aside and restore it from a backup", dbFile),
+	}
+
+	db, err := storage.Open(dbFile)
+	if err != nil {
+		r.status = doctorFail
+		r.detail = err.Error()
+		r.fixes = restore
+		return r
+	}
+	defer db.Close()
+
+	problems, err := db.IntegrityCheck()
+	if err != nil {
+		r.status = doctorFail
+		r.detail = err.Error()
+		r.fixes = restore
+		return r
+	}
+	if len(problems) > 0 {
+		r.status = doctorFail
+		r.detail = fmt.Sprintf("%d problem(s), first: %s", len(problems), problems[0])
+		r.fixes = append([]string{
+			"Rebuild derived tables if only they are affected: gocube db rebuild-derived",
+		}, restore...)
+		return r
+	}
+	r.detail = "ok (" + dbFile + ")"
+	return r
+}
+
+func checkDiskSpace(dbFile string) doctorResult {
+	r := doctorResult{name: "Disk space"}
+	free, err := diskFree(filepath.Dir(dbFile))
+	if errors.Is(err, errors.ErrUnsupported) {
+		r.status = doctorSkip
+		r.detail = "not checked on " + runtime.GOOS
+		return r
+	}
+	if err != nil {
+		r.status = doctorWarn
+		r.detail = err.Error()
+		r.fixes = []string{"Check that " + filepath.Dir(dbFile) + " exists and is readable"}
+		return r
+	}
+
+	r.detail = fmt.Sprintf("%.1f GB free", float64(free)/(1<<30))
+	if free < doctorMinFreeBytes {
+		r.status = doctorFail
+		r.detail = fmt.Sprintf("%.0f MB free", float64(free)/(1<<20))
+		r.fixes = []string{
+			"Free up space on the disk holding " + filepath.Dir(dbFile),
+			"Or keep the database elsewhere: gocube --db /path/with/space/gocube.db ...",
+		}
+	}
+	return r
+}
+
+func checkStateFile() doctorResult {
+	r := doctorResult{name: "State file"}
+	path, err := recorder.DefaultStatePath()
+	if err != nil {
+		r.status = doctorFail
+		r.detail = err.Error()
+		r.fixes = []string{"Make sure your home directory is writable"}
+		return r
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		r.detail = "not created yet"
+		return r
+	}
+
+	sf, err := recorder.NewStateFile(path)
+	if err != nil {
+		r.status = doctorFail
+		r.detail = err.Error()
+		r.fixes = []string{
+			fmt.Sprintf("Delete %s; it only remembers the last device and active solve and is recreated on the next run", path),
+		}
+		return r
+	}
+
+	if p := sf.DBPath(); p != "" {
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			r.status = doctorWarn
+			r.detail = "points at a missing database: " + p
+			r.fixes = []string{fmt.Sprintf("Remove \"db_path\" from %s or restore the database", path)}
+			return r
+		}
+	}
+	r.detail = "ok (" + path + ")"
+	return r
+}
+
+func checkLocks(dbFile string) doctorResult {
+	r := doctorResult{name: "Locks"}
+	var found []string
+
+	if _, err := os.Stat(dbFile); err == nil {
+		db, err := storage.Open(dbFile)
+		if err != nil {
+			r.status = doctorFail
+			r.detail = err.Error()
+			return r
+		}
+		defer db.Close()
+
+		locked, err := db.WriteLocked()
+		if err != nil {
+			r.status = doctorFail
+			r.detail = err.Error()
+			return r
+		}
+		if locked {
+			r.status = doctorFail
+			found = append(found, "database is locked by another process")
+			r.fixes = append(r.fixes, "Close other gocube commands (e.g. a recording in another terminal) and try again")
+		}
+
+		if sf, err := recorder.NewDefaultStateFile(); err == nil && sf.HasActiveSolve() && !locked {
+			if stale := staleActiveSolve(db, sf.ActiveSolveID()); stale != "" {
+				if r.status < doctorWarn {
+					r.status = doctorWarn
+				}
+				found = append(found, stale)
+				statePath, _ := recorder.DefaultStatePath()
+				r.fixes = append(r.fixes, fmt.Sprintf("Remove \"active_solve_id\" from %s so new solves can start", statePath))
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		r.detail = "none"
+		return r
+	}
+	r.detail = strings.Join(found, "; ")
+	return r
+}
+
+// staleActiveSolve describes why the active solve marker is stale, or
+// returns "" if it refers to a solve still in progress.
+func staleActiveSolve(db *storage.DB, solveID string) string {
+	if err := db.MigrateUp(); err != nil {
+		return ""
+	}
+	solve, err := storage.NewSolveRepository(db).Get(solveID)
+	switch {
+	case err != nil:
+		return ""
+	case solve == nil:
+		return fmt.Sprintf("active solve %s does not exist", solveID)
+	case solve.EndedAt != nil:
+		return fmt.Sprintf("active solve %s has already ended", solveID)
+	}
+	return ""
+}
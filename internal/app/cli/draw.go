@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
+)
+
+var drawCmd = &cobra.Command{
+	Use:   "draw <moves>",
+	Short: "Draw the cube after a sequence of moves, or animate them",
+	Long: `Draw the net of a cube after applying moves to a solved cube (or to the
+state --from leaves it in), in color unless NO_COLOR is set.
+
+With --animate the moves are played one at a time, the face about to turn
+highlighted, e.g. to learn an algorithm:
+
+  gocube draw "R U R' U R U2 R'" --from "R U2 R' U' R U' R'" --animate
+
+The style (letters or blocks) defaults to the config's display.cube_style.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDraw,
+}
+
+var (
+	drawFrom     string
+	drawAnimate  bool
+	drawInterval time.Duration
+	drawStyle    string
+)
+
+func init() {
+	rootCmd.AddCommand(drawCmd)
+	drawCmd.Flags().StringVar(&drawFrom, "from", "", "Moves applied to a solved cube first, e.g. a scramble")
+	drawCmd.Flags().BoolVar(&drawAnimate, "animate", false, "Play the moves one at a time")
+	drawCmd.Flags().DurationVar(&drawInterval, "interval", 700*time.Millisecond, "Time between moves with --animate")
+	drawCmd.Flags().StringVar(&drawStyle, "style", "", "letters or blocks (default from the config)")
+}
+
+func runDraw(cmd *cobra.Command, args []string) error {
+	cube := gocube.NewCube()
+	if drawFrom != "" {
+		if err := cube.ApplyNotation(drawFrom); err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+	moves, err := gocube.ParseMoves(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := defaultCubeRenderOptions()
+	if drawStyle != "" {
+		if opts.Style, err = render.ParseStyle(drawStyle); err != nil {
+			return err
+		}
+	}
+
+	if !drawAnimate {
+		cube.Apply(moves...)
+		fmt.Print(cube.Render(opts))
+		return nil
+	}
+	if drawInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	return render.Play(cmd.Context(), os.Stdout, cube.Animate(moves), drawInterval, opts)
+}
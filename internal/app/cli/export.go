@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,37 +18,106 @@ var (
 	exportFormat  string
 	exportOutput  string
 	exportLast    bool
+
+	exportLimit        int
+	exportSolvesFormat string
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export solve data",
-	Long:  `Export solve data in various formats.`,
+	Long: `Export solve data in various formats.
+
+With --template, render a solve through a Go text/template. Bare names are
+looked up in ~/.gocube_recorder/templates, so custom report formats need no
+code changes. Templates receive the solve, summary, moves, diagnostics,
+context, bookmarks and audio alignment, plus the helpers seconds, notation,
+json, join, upper and lower. Without --template, the available templates
+are listed.
+
+Examples:
+  gocube export --template coach.md --last
+  gocube export --template ./weekly.txt --id <solve_id> -o out.txt
+
+A minimal template:
+  # Solve {{.Solve.SolveID}}
+  Time: {{seconds .Summary.SolveDurationMs}}s, {{.Summary.SolveMoves}} moves
+  {{range .Diagnostics.Phases}}- {{.DisplayName}}: {{seconds .DurationMs}}s
+  {{end}}`,
+	RunE: runExportTemplate,
 }
 
 var exportMovesCmd = &cobra.Command{
 	Use:   "moves",
 	Short: "Export moves from a solve",
-	Long: `Export the move sequence from a solve in text or JSON format.
+	Long: `Export the move sequence from a solve in text or JSON format, or moves
+as a table for data analysis (pandas, R, DuckDB) in CSV or Parquet.
+
+The csv and parquet formats hold one row per move, scramble included, of
+every solve (or of --id, --last or the --limit most recent), with the
+columns solve_id, move_index, ts_ms (since the recording started), face,
+turn, notation, phase_key (empty outside marked phases) and gap_ms (since
+the solve's previous move). Parquet needs -o.
 
 Examples:
   gocube export moves --last
   gocube export moves --id <solve_id> --format json
-  gocube export moves --id <solve_id> --format txt -o moves.txt`,
+  gocube export moves --id <solve_id> --format txt -o moves.txt
+  gocube export moves --format csv -o moves.csv
+  gocube export moves --format parquet --limit 100 -o moves.parquet`,
 	RunE: runExportMoves,
 }
 
+var exportSolvesCmd = &cobra.Command{
+	Use:   "solves",
+	Short: "Export solves for other timer tools",
+	Long: `Export completed solves so they can be imported into other timers or
+shared as reconstructions.
+
+Formats:
+  cstimer  A csTimer export file (import it with Options > Import), with
+           times, scrambles, dates and notes as comments
+  txt      Plain text reconstructions: time, scramble and the solution one
+           phase per line, with a link that plays it on alg.cubing.net
+
+Times exclude the scramble and inspection. All solves are exported unless
+--id, --last or --limit is given.
+
+Examples:
+  gocube export solves -o cstimer.json
+  gocube export solves --format txt --last
+  gocube export solves --format txt --limit 12 -o recon.txt`,
+	RunE: runExportSolves,
+}
+
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
+	exportCmd.Flags().StringVar(&exportTemplate, "template", "", "Template file or name in the templates directory")
+	exportCmd.Flags().StringVar(&exportSolveID, "id", "", "Solve ID to export")
+	exportCmd.Flags().BoolVar(&exportLast, "last", false, "Export the last solve")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
+
+	exportCmd.AddCommand(exportSolvesCmd)
+	exportSolvesCmd.Flags().StringVar(&exportSolveID, "id", "", "Solve ID to export")
+	exportSolvesCmd.Flags().BoolVar(&exportLast, "last", false, "Export the last solve")
+	exportSolvesCmd.Flags().IntVar(&exportLimit, "limit", 0, "Export the most recent N solves (default: all)")
+	exportSolvesCmd.Flags().StringVar(&exportSolvesFormat, "format", "cstimer", "Export format (cstimer, txt)")
+	exportSolvesCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
+
 	exportCmd.AddCommand(exportMovesCmd)
 	exportMovesCmd.Flags().StringVar(&exportSolveID, "id", "", "Solve ID to export")
 	exportMovesCmd.Flags().BoolVar(&exportLast, "last", false, "Export the last solve")
-	exportMovesCmd.Flags().StringVar(&exportFormat, "format", "txt", "Export format (txt, json)")
+	exportMovesCmd.Flags().IntVar(&exportLimit, "limit", 0, "csv, parquet: export the most recent N solves (default: all)")
+	exportMovesCmd.Flags().StringVar(&exportFormat, "format", "txt", "Export format (txt, json, csv, parquet)")
 	exportMovesCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
 }
 
 func runExportMoves(cmd *cobra.Command, args []string) error {
+	switch strings.ToLower(exportFormat) {
+	case "csv", "parquet":
+		return runExportMoveTable()
+	}
 	if exportSolveID == "" && !exportLast {
 		return fmt.Errorf("specify --id or --last")
 	}
@@ -99,6 +169,7 @@ func runExportMoves(cmd *cobra.Command, args []string) error {
 		type MoveJSON struct {
 			MoveIndex int    `json:"move_index"`
 			TsMs      int64  `json:"ts_ms"`
+			TsUs      int64  `json:"ts_us"`
 			Face      string `json:"face"`
 			Turn      int    `json:"turn"`
 			Notation  string `json:"notation"`
@@ -109,6 +180,7 @@ func runExportMoves(cmd *cobra.Command, args []string) error {
 			movesJSON = append(movesJSON, MoveJSON{
 				MoveIndex: m.MoveIndex,
 				TsMs:      m.TsMs,
+				TsUs:      m.TsUs,
 				Face:      m.Face,
 				Turn:      m.Turn,
 				Notation:  m.Notation,
@@ -122,7 +194,7 @@ func runExportMoves(cmd *cobra.Command, args []string) error {
 		output = string(data)
 
 	default:
-		return fmt.Errorf("unknown format: %s (use txt or json)", exportFormat)
+		return fmt.Errorf("unknown format: %s (use txt, json, csv or parquet)", exportFormat)
 	}
 
 	// Write output
@@ -146,3 +218,159 @@ func runExportMoves(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runExportMoveTable exports the moves of the selected solves, all by
+// default, as one CSV or Parquet table.
+func runExportMoveTable() error {
+	parquet := strings.EqualFold(exportFormat, "parquet")
+	if parquet && exportOutput == "" {
+		return fmt.Errorf("parquet is binary; write it to a file with -o")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solves []storage.Solve
+	switch {
+	case exportSolveID != "":
+		solve, err := solveRepo.Get(exportSolveID)
+		if err != nil {
+			return err
+		}
+		if solve == nil {
+			return fmt.Errorf("solve not found: %s", exportSolveID)
+		}
+		solves = []storage.Solve{*solve}
+	case exportLast:
+		solves, err = solveRepo.List(1)
+	case exportLimit > 0:
+		solves, err = solveRepo.List(exportLimit)
+	default:
+		solves, err = solveRepo.List(-1) // No limit
+	}
+	if err != nil {
+		return err
+	}
+
+	// Oldest first, so rows run in recording order
+	for i, j := 0, len(solves)-1; i < j; i, j = i+1, j-1 {
+		solves[i], solves[j] = solves[j], solves[i]
+	}
+	rows, err := storage.NewExporter(db).MoveRows(solves)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("no moves to export")
+	}
+
+	write := storage.WriteMovesCSV
+	if parquet {
+		write = storage.WriteMovesParquet
+	}
+	if exportOutput == "" {
+		return write(os.Stdout, rows)
+	}
+	if dir := filepath.Dir(exportOutput); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	f, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+	if err := write(f, rows); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	fmt.Printf("Exported %d moves from %d solves to %s\n", len(rows), len(solves), exportOutput)
+	return nil
+}
+
+func runExportSolves(cmd *cobra.Command, args []string) error {
+	write := storage.WriteReconstructions
+	switch strings.ToLower(exportSolvesFormat) {
+	case "cstimer":
+		write = func(w io.Writer, solves []storage.ExportedSolve) error {
+			return storage.WriteCSTimer(w, solves, "gocube")
+		}
+	case "txt":
+	default:
+		return fmt.Errorf("unknown format: %s (use cstimer or txt)", exportSolvesFormat)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solves []storage.Solve
+	switch {
+	case exportSolveID != "":
+		solve, err := solveRepo.Get(exportSolveID)
+		if err != nil {
+			return err
+		}
+		if solve == nil {
+			return fmt.Errorf("solve not found: %s", exportSolveID)
+		}
+		solves = []storage.Solve{*solve}
+	case exportLast:
+		solves, err = solveRepo.List(1)
+	case exportLimit > 0:
+		solves, err = solveRepo.List(exportLimit)
+	default:
+		solves, err = solveRepo.List(-1) // No limit
+	}
+	if err != nil {
+		return err
+	}
+
+	// Oldest first, as timers list them
+	for i, j := 0, len(solves)-1; i < j; i, j = i+1, j-1 {
+		solves[i], solves[j] = solves[j], solves[i]
+	}
+
+	exported, err := storage.NewExporter(db).Load(solves)
+	if err != nil {
+		return err
+	}
+	if len(exported) == 0 {
+		return fmt.Errorf("no completed solves to export")
+	}
+
+	if exportOutput == "" {
+		return write(os.Stdout, exported)
+	}
+
+	dir := filepath.Dir(exportOutput)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	f, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := write(f, exported); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	fmt.Printf("Exported %d solves to %s\n", len(exported), exportOutput)
+	return nil
+}
@@ -1,12 +1,31 @@
 package cli
 
 import (
+	"os"
+	"strings"
 	"time"
 
 	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/notation"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
 )
 
+// cubeRenderOptions returns how cube states are drawn: in the config's
+// display.cube_style, in color unless NO_COLOR is set or stdout is not a
+// terminal.
+func cubeRenderOptions(display recorder.DisplayConfig) render.Options {
+	style, _ := render.ParseStyle(display.CubeStyle)
+	return render.Options{Color: os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout), Style: style}
+}
+
+// defaultCubeRenderOptions is cubeRenderOptions for the config on disk.
+func defaultCubeRenderOptions() render.Options {
+	cfg, _ := recorder.LoadDefaultConfig()
+	return cubeRenderOptions(cfg.Display)
+}
+
 // colorToFace maps GoCube color names to Face constants.
 var colorToFace = map[string]gocube.Face{
 	"white":  gocube.FaceU,
@@ -17,6 +36,31 @@ var colorToFace = map[string]gocube.Face{
 	"orange": gocube.FaceL,
 }
 
+// recentMovesLimit is how many moves the TUIs show.
+const recentMovesLimit = 20
+
+// writeRecentMoves writes the "Moves:" line of the record and replay TUIs:
+// the last few moves, merged for display if merge is set.
+func writeRecentMoves(b *strings.Builder, moves []gocube.Move, merge bool) {
+	if len(moves) == 0 {
+		return
+	}
+	display := notation.DisplayMoves(moves, merge)
+
+	b.WriteString("Moves: ")
+	start := 0
+	if len(display) > recentMovesLimit {
+		start = len(display) - recentMovesLimit
+		b.WriteString("... ")
+	}
+	notations := make([]string, 0, len(display)-start)
+	for _, d := range display[start:] {
+		notations = append(notations, d.Notation())
+	}
+	b.WriteString(moveStyle.Render(strings.Join(notations, " ")))
+	b.WriteString("\n")
+}
+
 // rotationsToMoves converts rotation events to Move objects.
 func rotationsToMoves(rotations []protocol.RotationEvent, t time.Time) []gocube.Move {
 	moves := make([]gocube.Move, len(rotations))
@@ -28,7 +72,17 @@ func rotationsToMoves(rotations []protocol.RotationEvent, t time.Time) []gocube.
 		} else {
 			turn = gocube.CCW
 		}
-		moves[i] = gocube.Move{Face: face, Turn: turn, Time: t}
+		moves[i] = gocube.Move{
+			Face:       face,
+			Turn:       turn,
+			Time:       t,
+			Center:     protocol.CenterQuarterTurns(rot.CenterOrientation),
+			HasCenter:  rot.CenterOrientation != protocol.CenterUnknown,
+			BatchIndex: i,
+			BatchSize:  len(rotations),
+			Elapsed:    rot.Elapsed,
+			HasElapsed: rot.HasElapsed,
+		}
 	}
 	return moves
 }
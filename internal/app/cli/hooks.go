@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List the commands run on solve events",
+	Long: `List the hook commands configured in the config file. Hooks run on the
+solves recorded with 'gocube solve record' and 'gocube solve start/phase/end':
+
+  solve_start   A solve starts recording
+  phase_change  A phase is reached (scramble and inspection included)
+  solve_end     The solve ends; the event has its moves and phases
+
+Each command gets the event as JSON on stdin, with GOCUBE_EVENT,
+GOCUBE_SOLVE_ID and GOCUBE_PHASE in its environment. Hooks run one at a
+time in the background, so a slow one never holds up recording; a command
+that fails or runs past its timeout is reported. Add hooks to the config:
+
+  "hooks": [
+    {
+      "command": ["sh", "-c", "curl -s -H 'Content-Type: application/json' -d @- $WEBHOOK_URL"],
+      "events": ["solve_end"],
+      "timeout_seconds": 10
+    }
+  ]
+
+Try the hooks on a recorded solve with 'gocube hooks test'.`,
+	RunE: runHooks,
+}
+
+var hooksTestCmd = &cobra.Command{
+	Use:   "test [solve-id]",
+	Short: "Run the hooks on a recorded solve's solve_end event",
+	Long: `Run every hook that takes solve_end on a recorded solve, as if it had
+just ended, and report how each went.
+
+Examples:
+  gocube hooks test --last
+  gocube hooks test 3f2a`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runHooksTest,
+}
+
+var hooksTestLast bool
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksTestCmd)
+	hooksTestCmd.Flags().BoolVar(&hooksTestLast, "last", false, "Use the last solve")
+}
+
+func runHooks(cmd *cobra.Command, args []string) error {
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Hooks) == 0 {
+		fmt.Println("No hooks configured. See 'gocube hooks --help' to add one.")
+		return nil
+	}
+	for i, h := range cfg.Hooks {
+		events := "all events"
+		if len(h.Events) > 0 {
+			events = strings.Join(h.Events, ", ")
+		}
+		fmt.Printf("  %d. %s\n", i+1, strings.Join(h.Command, " "))
+		fmt.Printf("     on %s\n", events)
+	}
+	return nil
+}
+
+func runHooksTest(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && !hooksTestLast {
+		return fmt.Errorf("please provide a solve ID or use --last")
+	}
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Hooks) == 0 {
+		return fmt.Errorf("no hooks configured")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solve *storage.Solve
+	if hooksTestLast {
+		solve, err = solveRepo.GetLast()
+	} else {
+		solve, err = solveRepo.Get(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return fmt.Errorf("solve not found")
+	}
+
+	event, err := recorder.SolveEndEvent(db, solve.SolveID)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, h := range cfg.Hooks {
+		name := strings.Join(h.Command, " ")
+		if len(h.Events) > 0 && !containsEvent(h.Events, recorder.HookSolveEnd) {
+			fmt.Printf("  skip  %s (not on solve_end)\n", name)
+			continue
+		}
+		if err := recorder.NewCommandHook(h).HandleSolveEvent(event); err != nil {
+			fmt.Printf("  FAIL  %s\n        %v\n", name, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ok    %s\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d hook(s) failed", failed)
+	}
+	return nil
+}
+
+func containsEvent(events []string, event string) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// addConfiguredHooks registers the config's hook commands on a session.
+// onError is called with hook failures.
+func addConfiguredHooks(session *recorder.Session, cfg recorder.Config, onError func(error)) {
+	for _, h := range cfg.Hooks {
+		session.AddHook(recorder.NewCommandHook(h))
+	}
+	session.SetHookErrorCallback(onError)
+}
+
+// loadConfiguredHooks registers the hook commands of the config file on a
+// session run by a one-shot command, printing hook failures. Call
+// session.WaitHooks before the command returns.
+func loadConfiguredHooks(session *recorder.Session) {
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		fmt.Printf("Warning: hooks not run: %v\n", err)
+		return
+	}
+	addConfiguredHooks(session, cfg, func(err error) {
+		fmt.Printf("Warning: %v\n", err)
+	})
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var lightsCmd = &cobra.Command{
+	Use:   "lights [solve-id]",
+	Short: "Replay a solve's phases on the cube's backlight",
+	Long: `Play a recorded solve back in real time and mark its phase structure on
+the connected cube's backlight: a flash as each phase completes, slow at
+first and quicker toward the finish, and the animated backlight when the
+cube is solved.
+
+With --visualizer, the solve's visualizer opens in the browser (the report
+is generated if needed); press play there and then Enter to start the
+lights together with it.
+
+Examples:
+  gocube solve lights --last
+  gocube solve lights 3f2a --speed 2
+  gocube solve lights --last --visualizer`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSolveLights,
+}
+
+var (
+	lightsLast       bool
+	lightsSpeed      float64
+	lightsVisualizer bool
+)
+
+func init() {
+	solveCmd.AddCommand(lightsCmd)
+	lightsCmd.Flags().BoolVar(&lightsLast, "last", false, "Replay the last solve")
+	lightsCmd.Flags().Float64VarP(&lightsSpeed, "speed", "s", 1.0, "Playback speed multiplier")
+	lightsCmd.Flags().BoolVar(&lightsVisualizer, "visualizer", false, "Open the solve's visualizer and start on Enter")
+}
+
+// lightCue is a backlight effect played when the move at MoveIndex (into
+// the solve's moves) is replayed.
+type lightCue struct {
+	MoveIndex int
+	Phase     string
+	Patterns  []gocube.LightPattern // Played Gap apart
+	Gap       time.Duration
+}
+
+// lightChoreography returns a cue for the last move of each solve phase.
+// Early phases get a slow flash, later ones a flash and the last third
+// of the solve quicker double flashes; the final phase turns the animated
+// backlight for a celebration. segments must exclude the scramble and inspection.
+func lightChoreography(moveRecords []storage.MoveRecord, segments []storage.PhaseSegment) []lightCue {
+	var cues []lightCue
+	for i, seg := range segments {
+		last := -1
+		for j, m := range moveRecords {
+			// Segments end exclusive, except the last, as the recorder counts
+			if m.TsMs >= seg.StartTsMs && (m.TsMs < seg.EndTsMs || i == len(segments)-1 && m.TsMs == seg.EndTsMs) {
+				last = j
+			}
+		}
+		if last < 0 {
+			continue
+		}
+
+		cue := lightCue{MoveIndex: last, Phase: seg.PhaseKey}
+		switch progress := float64(i+1) / float64(len(segments)); {
+		case i == len(segments)-1:
+			cue.Patterns = []gocube.LightPattern{gocube.LightCelebrate}
+		case progress <= 1.0/3:
+			cue.Patterns = []gocube.LightPattern{gocube.LightSlowFlash}
+		case progress <= 2.0/3:
+			cue.Patterns = []gocube.LightPattern{gocube.LightFlash}
+		default:
+			cue.Patterns = []gocube.LightPattern{gocube.LightFlash, gocube.LightFlash}
+			cue.Gap = 400 * time.Millisecond
+		}
+		cues = append(cues, cue)
+	}
+	return cues
+}
+
+func runSolveLights(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && !lightsLast {
+		return fmt.Errorf("please provide a solve ID or use --last")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solve *storage.Solve
+	if lightsLast {
+		solve, err = solveRepo.GetLast()
+	} else {
+		solve, err = solveRepo.Get(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return fmt.Errorf("solve not found")
+	}
+
+	moveRecords, err := storage.NewMoveRepository(db).GetBySolve(solve.SolveID)
+	if err != nil {
+		return fmt.Errorf("failed to get moves: %w", err)
+	}
+	allSegments, err := storage.NewPhaseRepository(db).GetPhaseSegments(solve.SolveID)
+	if err != nil {
+		return fmt.Errorf("failed to get phases: %w", err)
+	}
+	var segments []storage.PhaseSegment
+	for _, seg := range allSegments {
+		if seg.PhaseKey != "scramble" && seg.PhaseKey != "inspection" {
+			segments = append(segments, seg)
+		}
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("solve %s has no phases to replay", solve.SolveID[:8])
+	}
+
+	// Replay from the first solve move; the scramble needs no lights
+	var solveRecords []storage.MoveRecord
+	for _, m := range moveRecords {
+		if m.TsMs >= segments[0].StartTsMs {
+			solveRecords = append(solveRecords, m)
+		}
+	}
+	cues := lightChoreography(solveRecords, segments)
+
+	var vizPath string
+	if lightsVisualizer {
+		reportDir, err := GenerateReportForSolve(db, solve.SolveID)
+		if err != nil {
+			return fmt.Errorf("report generation failed: %w", err)
+		}
+		vizPath = filepath.Join(reportDir, "visualizer.html")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("Scanning for a cube...")
+	connectCtx, cancel := context.WithTimeout(ctx, 20*time.Second+scanTimeout())
+	cube, err := gocube.ConnectFirst(connectCtx,
+		gocube.WithScanTimeout(scanTimeout()),
+		gocube.WithPreferredDevice(activeProfile.Device),
+	)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer cube.Close()
+	fmt.Printf("Connected to %s\n", cube.DeviceName())
+
+	if vizPath != "" {
+		if err := openInBrowser(vizPath); err != nil {
+			fmt.Printf("Could not open a browser (%v); open %s manually\n", err, vizPath)
+		}
+		fmt.Print("Press play in the visualizer, then Enter to start the lights...")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	}
+
+	events := make([]gocube.ReplayEvent, len(solveRecords))
+	moves := storage.ToMoves(solveRecords)
+	for i := range moves {
+		events[i] = gocube.ReplayEvent{Time: moves[i].Time, Move: &moves[i]}
+	}
+	replayer := gocube.NewReplayer(events)
+	replayer.SetSpeed(lightsSpeed)
+
+	// Cues run on their own goroutine so a double flash or the celebration
+	// does not hold up the replay clock
+	cueCh := make(chan lightCue, len(cues))
+	cueDone := make(chan struct{})
+	go func() {
+		defer close(cueDone)
+		for cue := range cueCh {
+			fmt.Printf("  %s\n", phaseDisplayName(cue.Phase))
+			for i, p := range cue.Patterns {
+				if i > 0 {
+					time.Sleep(cue.Gap)
+				}
+				if err := cube.Lights().Play(p); err != nil {
+					fmt.Printf("  Failed to play %s: %v\n", p, err)
+				}
+			}
+		}
+	}()
+
+	next, played := 0, 0
+	replayer.OnMove(func(gocube.Move) {
+		for next < len(cues) && cues[next].MoveIndex <= played {
+			cueCh <- cues[next]
+			next++
+		}
+		played++
+	})
+
+	fmt.Printf("Replaying solve %s (%d phases, %s)...\n",
+		solve.SolveID[:8], len(segments), formatDuration(replayer.Duration()))
+	err = replayer.Run(ctx)
+	close(cueCh)
+	<-cueDone
+	if err != nil {
+		return nil // Interrupted
+	}
+	fmt.Println("Done.")
+	return nil
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	manualTime     string
+	manualScramble string
+	manualNotes    string
+	manualContext  map[string]string
+)
+
+var solveManualCmd = &cobra.Command{
+	Use:   "manual",
+	Short: "Record a manually timed solve (no smart cube)",
+	Long: `Record a solve done on a regular cube, storing only the scramble and time.
+
+Manual solves are included in trend averages alongside smart-cube solves,
+but have no move stream, so move counts and TPS exclude them.
+
+Pass the time with --time (e.g. 23.45 or 1:05.32), or omit it to time the
+solve from the keyboard: press Enter to start and Enter again to stop.`,
+	RunE: runSolveManual,
+}
+
+func init() {
+	solveCmd.AddCommand(solveManualCmd)
+	solveManualCmd.Flags().StringVar(&manualTime, "time", "", "Solve time (seconds or m:ss.xx)")
+	solveManualCmd.Flags().StringVar(&manualScramble, "scramble", "", "Scramble sequence used")
+	solveManualCmd.Flags().StringVar(&manualNotes, "notes", "", "Notes for this solve")
+	solveManualCmd.Flags().StringToStringVar(&manualContext, "context", nil, "Context metadata (e.g. cube=gan12,lube=fresh)")
+}
+
+// parseSolveTime parses "23.45" or "1:05.32" into a duration.
+func parseSolveTime(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var mins int
+	secPart := s
+	if idx := strings.Index(s, ":"); idx >= 0 {
+		m, err := strconv.Atoi(s[:idx])
+		if err != nil || m < 0 {
+			return 0, fmt.Errorf("invalid minutes in time %q", s)
+		}
+		mins = m
+		secPart = s[idx+1:]
+	}
+
+	secs, err := strconv.ParseFloat(secPart, 64)
+	if err != nil || secs < 0 {
+		return 0, fmt.Errorf("invalid seconds in time %q", s)
+	}
+
+	d := time.Duration(mins)*time.Minute + time.Duration(secs*float64(time.Second))
+	if d <= 0 {
+		return 0, fmt.Errorf("solve time must be positive")
+	}
+	return d, nil
+}
+
+// timeSolveFromKeyboard times a solve between two Enter presses.
+func timeSolveFromKeyboard() (time.Duration, error) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Print("Press Enter to start the timer...")
+	if _, err := reader.ReadString('\n'); err != nil {
+		return 0, fmt.Errorf("failed to read input: %w", err)
+	}
+	start := time.Now()
+
+	fmt.Print("Timing... press Enter to stop.")
+	if _, err := reader.ReadString('\n'); err != nil {
+		return 0, fmt.Errorf("failed to read input: %w", err)
+	}
+	return time.Since(start), nil
+}
+
+func runSolveManual(cmd *cobra.Command, args []string) error {
+	var duration time.Duration
+	var err error
+	if manualTime != "" {
+		duration, err = parseSolveTime(manualTime)
+	} else {
+		duration, err = timeSolveFromKeyboard()
+	}
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	solveID, err := solveRepo.CreateManual(manualNotes, manualScramble, version, duration.Milliseconds(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Attach config default context plus any --context overrides
+	defaultContext := map[string]string{}
+	if cfg, err := recorder.LoadDefaultConfig(); err == nil {
+		defaultContext = profileContext(cfg)
+	}
+	context := mergeContext(defaultContext, manualContext)
+	if err := storage.NewContextRepository(db).SetAll(solveID, context); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Recorded manual solve: %s\n", solveID)
+	fmt.Printf("Time: %s\n", formatDuration(duration))
+	if manualScramble != "" {
+		fmt.Printf("Scramble: %s\n", manualScramble)
+	}
+	if len(context) > 0 {
+		fmt.Printf("Context: %s\n", formatContext(context))
+	}
+	printNewAchievements(db)
+	printNewPersonalBests(db)
+
+	return nil
+}
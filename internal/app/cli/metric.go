@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Stored move and phase counts are in HTM, one per recorded turn. The
+// helpers below recount them from the moves for the other metrics, so
+// commands show every count in the metric chosen with --metric or the
+// profile's metric setting.
+
+// solveMoveCount returns the number of moves recorded for a solve in the
+// turn metric.
+func solveMoveCount(moveRepo *storage.MoveRepository, solveID string) int {
+	if turnMetric() == gocube.MetricHTM {
+		n, _ := moveRepo.Count(solveID)
+		return n
+	}
+	moveRecords, err := moveRepo.GetBySolve(solveID)
+	if err != nil {
+		return 0
+	}
+	return countMoves(storage.ToMoves(moveRecords))
+}
+
+// metricSegments returns segments with MoveCount and TPS recounted in the
+// turn metric from moveRecords, all the moves of the solve.
+func metricSegments(segments []storage.PhaseSegment, moveRecords []storage.MoveRecord) []storage.PhaseSegment {
+	if turnMetric() == gocube.MetricHTM {
+		return segments
+	}
+
+	result := make([]storage.PhaseSegment, len(segments))
+	for i, seg := range segments {
+		// Segments end exclusive, except the last, as the recorder counts
+		endMs := seg.EndTsMs
+		if i == len(segments)-1 {
+			endMs++
+		}
+		var phaseRecords []storage.MoveRecord
+		for _, m := range moveRecords {
+			if m.TsMs >= seg.StartTsMs && m.TsMs < endMs {
+				phaseRecords = append(phaseRecords, m)
+			}
+		}
+
+		seg.MoveCount = countMoves(storage.ToMoves(phaseRecords))
+		seg.TPS = 0
+		if seg.DurationMs > 0 {
+			seg.TPS = float64(seg.MoveCount) / (float64(seg.DurationMs) / 1000.0)
+		}
+		result[i] = seg
+	}
+	return result
+}
+
+// metricEfficiency is the optimized line's length as a fraction of the
+// original's, in the turn metric.
+func metricEfficiency(original, optimized []gocube.Move) float64 {
+	n := countMoves(original)
+	if n == 0 {
+		return 1.0
+	}
+	return float64(countMoves(optimized)) / float64(n)
+}
+
+// metricLine recounts a suggested shorter line in the turn metric, returning
+// nil if it is no shorter there.
+func metricLine(line *analysis.LineSuggestion, original []gocube.Move) *analysis.LineSuggestion {
+	if line == nil || turnMetric() == gocube.MetricHTM {
+		return line
+	}
+	shorter, _ := gocube.ParseMoves(line.Moves)
+	counted := *line
+	counted.OriginalMoves = countMoves(original)
+	counted.OptimizedMoves = countMoves(shorter)
+	if counted.OptimizedMoves >= counted.OriginalMoves {
+		return nil
+	}
+	return &counted
+}
+
+// metricLabel names the turn metric after a move count, e.g. " (QTM)", or
+// is empty for HTM, the metric counts are assumed to be in.
+func metricLabel() string {
+	if turnMetric() == gocube.MetricHTM {
+		return ""
+	}
+	return " (" + turnMetric().String() + ")"
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+var syncOfflineCmd = &cobra.Command{
+	Use:   "sync-offline",
+	Short: "Import the cube's own move, time and solve counters",
+	Long: `Connect to the nearest cube, read its offline stats (the moves, turning
+time and solves it has counted itself, app or not) and store them.
+
+Each sync adds what the cube counted since the previous one to its
+lifetime totals, so turns made with the app closed are counted too and a
+counter reset (e.g. a flat battery) loses nothing already synced. The
+record TUI syncs as well each time it connects. 'gocube device list'
+shows the lifetime moves of each cube.
+
+Only GoCubes report offline stats.`,
+	RunE: runSyncOffline,
+}
+
+var syncOfflineTimeout time.Duration
+
+func init() {
+	rootCmd.AddCommand(syncOfflineCmd)
+	syncOfflineCmd.Flags().DurationVar(&syncOfflineTimeout, "timeout", 5*time.Second, "How long to wait for the cube to answer")
+}
+
+func runSyncOffline(cmd *cobra.Command, args []string) error {
+	client, results, err := ScanForGoCube()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no GoCube found (turn a face to wake it)")
+	}
+
+	answers := make(chan *protocol.OfflineStatsEvent, 1)
+	client.SetMessageCallback(func(msg *protocol.Message) {
+		if msg.Type != protocol.MsgTypeOfflineStats {
+			return
+		}
+		if ev, err := protocol.DecodeOfflineStats(msg.Payload); err == nil {
+			select {
+			case answers <- ev:
+			default:
+			}
+		}
+	})
+	if err := client.ConnectToResult(context.Background(), results[0]); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer client.Disconnect()
+
+	if err := client.RequestOfflineStats(); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return fmt.Errorf("%s does not report offline stats", client.DeviceName())
+		}
+		return fmt.Errorf("failed to request offline stats: %w", err)
+	}
+	var ev *protocol.OfflineStatsEvent
+	select {
+	case ev = <-answers:
+	case <-time.After(syncOfflineTimeout):
+		return fmt.Errorf("%s did not report its offline stats within %s", client.DeviceName(), syncOfflineTimeout)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	reading, lifetime, err := recordOfflineStats(db, client.DeviceUUID(), client.DeviceName(), ev)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("Synced %s\n", client.DeviceName())
+	fmt.Printf("  Cube counters:    %d moves, %s turning, %d solves\n",
+		reading.Moves, time.Duration(reading.TimeS)*time.Second, reading.Solves)
+	if lifetime.Syncs > 1 {
+		fmt.Printf("  Since last sync:  %d moves (%d away from the app), %d solves\n",
+			reading.DeltaMoves, reading.AwayMoves, reading.DeltaSolves)
+	}
+	if reading.CounterReset {
+		fmt.Println("  The cube's counters restarted since the last sync; they are added on top.")
+	}
+	fmt.Printf("  Lifetime:         %d moves (%d away from the app), %s turning, %d solves\n",
+		lifetime.Moves, lifetime.AwayMoves, time.Duration(lifetime.TimeS)*time.Second, lifetime.Solves)
+	return nil
+}
+
+// recordOfflineStats stores a reading of a cube's offline stats and returns
+// it with the cube's lifetime totals.
+func recordOfflineStats(db *storage.DB, deviceID, deviceName string, ev *protocol.OfflineStatsEvent) (*storage.OfflineSync, *storage.OfflineLifetime, error) {
+	repo := storage.NewOfflineStatsRepository(db)
+	reading, err := repo.Record(deviceID, deviceName, ev.Moves, ev.Time, ev.Solves)
+	if err != nil {
+		return nil, nil, err
+	}
+	lifetime, err := repo.Lifetime(deviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reading, lifetime, nil
+}
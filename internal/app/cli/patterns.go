@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	patternsWindow   int
+	patternsMinN     int
+	patternsMaxN     int
+	patternsTop      int
+	patternsMemoryMB int
+)
+
+var reportPatternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Find move sequences repeated across solves",
+	Long: `Mine the move sequences (n=4-14 by default) repeated across recent solves
+into reports/patterns/pattern_report.json, naming those that are (or are
+part of) a known PLL, OLL or F2L algorithm or final phase tool.
+
+The report also holds your algorithm fingerprint: every known algorithm
+found in the solving moves, how often and in how many solves, and the share
+of your moves they account for.
+
+Solves are streamed through a count-min sketch, so memory stays within
+--memory-mb however many solves are mined. Counts are exact until the
+budget forces evictions; after that they are estimates that are never too
+low and rarely too high, and a sequence seen exactly twice may be left
+out. Raise --memory-mb if the report shows many evictions.
+
+  gocube report patterns --window 0 --memory-mb 128`,
+	RunE: runReportPatterns,
+}
+
+func init() {
+	reportCmd.AddCommand(reportPatternsCmd)
+	reportPatternsCmd.Flags().IntVar(&patternsWindow, "window", 500, "Number of recent solves to mine (0 for all)")
+	reportPatternsCmd.Flags().IntVar(&patternsMinN, "min-n", 4, "Shortest sequence length")
+	reportPatternsCmd.Flags().IntVar(&patternsMaxN, "max-n", 14, "Longest sequence length")
+	reportPatternsCmd.Flags().IntVar(&patternsTop, "top", 10, "Sequences to report per length")
+	reportPatternsCmd.Flags().IntVar(&patternsMemoryMB, "memory-mb", analysis.DefaultNGramMemoryBudget>>20, "Memory budget for mining in MB")
+	reportPatternsCmd.Flags().StringVarP(&reportOutputDir, "output", "o", "", "Base output directory (default: reports)")
+}
+
+// PatternReport is the JSON structure for pattern_report.json
+type PatternReport struct {
+	GeneratedAt string                        `json:"generated_at"`
+	Miner       analysis.NGramMinerStats      `json:"miner"`
+	Fingerprint analysis.AlgorithmFingerprint `json:"fingerprint"`
+	*analysis.NGramReport
+}
+
+func runReportPatterns(cmd *cobra.Command, args []string) error {
+	if patternsMinN < 1 || patternsMaxN < patternsMinN {
+		return fmt.Errorf("invalid lengths: --min-n %d, --max-n %d", patternsMinN, patternsMaxN)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+
+	limit := patternsWindow
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+	solves, err := solveRepo.List(limit)
+	if err != nil {
+		return err
+	}
+	solves = filterSolvesByDevice(solves, reportDevice)
+	if len(solves) == 0 {
+		fmt.Println("No solves recorded")
+		return nil
+	}
+
+	miner := analysis.NewNGramMiner(patternsMinN, patternsMaxN, int64(patternsMemoryMB)<<20)
+	tally := analysis.NewAlgorithmTally()
+
+	ctx, stop := interruptContext()
+	defer stop()
+	bar := newProgress("Mining", len(solves))
+	for i, s := range solves {
+		if ctx.Err() != nil {
+			bar.Done()
+			return interrupted(i, len(solves), "solves")
+		}
+		bar.Step()
+		if s.IsManual() {
+			continue
+		}
+		moveRecords, err := moveRepo.GetBySolve(s.SolveID)
+		if err != nil {
+			bar.Done()
+			return err
+		}
+		miner.Add(s.SolveID, storage.ToMoves(moveRecords))
+
+		segments, err := phaseRepo.GetPhaseSegments(s.SolveID)
+		if err != nil {
+			bar.Done()
+			return err
+		}
+		tally.Add(buildReconstruction(moveRecords, segments))
+	}
+	bar.Done()
+
+	baseDir := reportOutputDir
+	if baseDir == "" {
+		baseDir = reportsDir()
+	}
+	outputDir := filepath.Join(baseDir, "patterns")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	report := PatternReport{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Miner:       miner.Stats(),
+		Fingerprint: tally.Fingerprint(),
+		NGramReport: miner.Report(patternsTop),
+	}
+	analysis.IdentifyNGrams(report.NGramReport)
+	if err := writeJSON(filepath.Join(outputDir, "pattern_report.json"), report); err != nil {
+		return err
+	}
+
+	stats := report.Miner
+	fmt.Printf("Mined %d moves from %d solves (%d counters, %d evicted)\n",
+		stats.Moves, stats.Solves, stats.Counters, stats.Evictions)
+
+	lengths := make([]int, 0, len(report.TopNGrams))
+	for n := range report.TopNGrams {
+		lengths = append(lengths, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(lengths)))
+	for _, n := range lengths {
+		top := report.TopNGrams[n][0]
+		fmt.Printf("  n=%-2d %4dx  %s%s\n", n, top.Count, strings.Join(top.Sequence, " "), algorithmNote(top.Algorithm))
+	}
+
+	printFingerprint(report.Fingerprint)
+
+	fmt.Println()
+	fmt.Printf("Pattern report generated: %s\n", outputDir)
+	return nil
+}
+
+// algorithmNote returns " (T-perm)" or " (part of T-perm)" for a mined
+// sequence matching a known algorithm, or "".
+func algorithmNote(match *analysis.AlgorithmMatch) string {
+	switch {
+	case match == nil:
+		return ""
+	case match.Partial:
+		return " (part of " + match.Name + ")"
+	default:
+		return " (" + match.Name + ")"
+	}
+}
+
+// printFingerprint prints the most used known algorithms and their share
+// of the solving moves.
+func printFingerprint(fp analysis.AlgorithmFingerprint) {
+	fmt.Println()
+	if len(fp.Algorithms) == 0 {
+		fmt.Println("Algorithm fingerprint: no known algorithms found")
+		return
+	}
+	share := 0.0
+	if fp.Moves > 0 {
+		share = float64(fp.RecognizedMoves) / float64(fp.Moves) * 100
+	}
+	fmt.Printf("Algorithm fingerprint (%.0f%% of solving moves in known algorithms):\n", share)
+	for i, a := range fp.Algorithms {
+		if i == patternsTop {
+			fmt.Printf("  ... %d more in pattern_report.json\n", len(fp.Algorithms)-i)
+			break
+		}
+		fmt.Printf("  %-14s %-5s %4dx in %d of %d solves\n", a.Name, a.Category, a.Count, a.Solves, fp.Solves)
+	}
+}
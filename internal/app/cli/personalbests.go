@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var pbCmd = &cobra.Command{
+	Use:   "pb",
+	Short: "Show personal bests and how recent solves compare",
+	Long: `Show the personal best single, ao5 and ao12 and the best split of each
+phase, with the latest value of each and how far it is behind the best.
+A gap that keeps growing is a slump worth looking into.
+
+Personal bests are checked as solves are stored, and a solve that sets
+one says so. Every best is kept in a history; --history lists it to show
+how each best came down over time.
+
+Examples:
+  gocube pb
+  gocube pb --history
+  gocube pb --json`,
+	RunE: runPB,
+}
+
+var (
+	pbHistory bool
+	pbJSON    bool
+)
+
+func init() {
+	rootCmd.AddCommand(pbCmd)
+	pbCmd.Flags().BoolVar(&pbHistory, "history", false, "List every personal best set, oldest first")
+	pbCmd.Flags().BoolVar(&pbJSON, "json", false, "Output as JSON")
+}
+
+// pbEntry is one personal best in gocube pb --json.
+type pbEntry struct {
+	Kind       string   `json:"kind"`
+	PhaseKey   string   `json:"phase_key,omitempty"`
+	Ms         float64  `json:"ms"`
+	PreviousMs *float64 `json:"previous_ms,omitempty"`
+	SolveID    string   `json:"solve_id"`
+	AchievedAt string   `json:"achieved_at"`
+	CurrentMs  *float64 `json:"current_ms,omitempty"` // Status only
+	Sets       int      `json:"sets,omitempty"`       // Status only
+}
+
+func newPBEntry(pb storage.PersonalBest) pbEntry {
+	return pbEntry{
+		Kind:       pb.Kind,
+		PhaseKey:   pb.PhaseKey,
+		Ms:         pb.ValueMs,
+		PreviousMs: pb.PreviousMs,
+		SolveID:    pb.SolveID,
+		AchievedAt: pb.AchievedAt.Format(time.RFC3339),
+	}
+}
+
+func runPB(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := updatePersonalBests(db); err != nil {
+		return err
+	}
+	_, statuses, err := analysis.EvaluatePersonalBests(storage.NewSolveRepository(db), storage.NewPhaseRepository(db))
+	if err != nil {
+		return err
+	}
+
+	if pbHistory {
+		history, err := storage.NewPersonalBestRepository(db).List()
+		if err != nil {
+			return err
+		}
+		if pbJSON {
+			entries := make([]pbEntry, len(history))
+			for i, pb := range history {
+				entries[i] = newPBEntry(pb)
+			}
+			return writePBJSON(entries)
+		}
+		printPBHistory(history)
+		return nil
+	}
+
+	if pbJSON {
+		entries := make([]pbEntry, len(statuses))
+		for i, st := range statuses {
+			entries[i] = newPBEntry(st.Best)
+			entries[i].CurrentMs = st.Current
+			entries[i].Sets = st.Sets
+		}
+		return writePBJSON(entries)
+	}
+	printPBStatuses(statuses)
+	return nil
+}
+
+func writePBJSON(entries []pbEntry) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// printPBStatuses prints each personal best beside the latest value.
+func printPBStatuses(statuses []analysis.PersonalBestStatus) {
+	if len(statuses) == 0 {
+		fmt.Println("No finished solves yet.")
+		return
+	}
+	fmt.Println("Personal Bests")
+	fmt.Println("==============")
+	fmt.Println()
+	fmt.Printf("  %-28s %9s  %-10s %-8s %9s %9s\n", "", "Best", "Set", "Solve", "Latest", "Gap")
+	for _, st := range statuses {
+		latest, gap := "DNF", ""
+		if g, ok := st.GapMs(); ok {
+			latest = fmt.Sprintf("%.2fs", *st.Current/1000)
+			gap = fmt.Sprintf("+%.2fs", g/1000)
+		}
+		fmt.Printf("  %-28s %8.2fs  %-10s %-8s %9s %9s\n",
+			analysis.PersonalBestName(st.Kind, st.PhaseKey), st.Best.ValueMs/1000,
+			localTime(st.Best.AchievedAt).Format("2006-01-02"), st.Best.SolveID[:8], latest, gap)
+	}
+}
+
+// printPBHistory prints every personal best set, oldest first.
+func printPBHistory(history []storage.PersonalBest) {
+	if len(history) == 0 {
+		fmt.Println("No personal bests yet.")
+		return
+	}
+	for _, pb := range history {
+		fmt.Printf("  %s  %-8s  %s\n", localTime(pb.AchievedAt).Format("2006-01-02 15:04"),
+			pb.SolveID[:8], analysis.DescribePersonalBest(pb))
+	}
+}
+
+// updatePersonalBests records the personal bests set by the stored solves
+// and returns those new to the history.
+func updatePersonalBests(db *storage.DB) ([]storage.PersonalBest, error) {
+	return analysis.UpdatePersonalBests(storage.NewSolveRepository(db), storage.NewPhaseRepository(db),
+		storage.NewPersonalBestRepository(db))
+}
+
+// personalBestNotice formats new personal bests for display, or returns ""
+// if there are none.
+func personalBestNotice(pbs []storage.PersonalBest) string {
+	if len(pbs) == 0 {
+		return ""
+	}
+	parts := make([]string, len(pbs))
+	for i, pb := range pbs {
+		parts[i] = analysis.DescribePersonalBest(pb)
+	}
+	return "New personal best: " + strings.Join(parts, ", ")
+}
+
+// printNewPersonalBests updates the personal bests after a solve is stored
+// and prints any set. Failures are reported but do not fail the command.
+func printNewPersonalBests(db *storage.DB) {
+	pbs, err := updatePersonalBests(db)
+	if err != nil {
+		fmt.Printf("Warning: failed to update personal bests: %v\n", err)
+		return
+	}
+	if notice := personalBestNotice(pbs); notice != "" {
+		fmt.Println()
+		fmt.Println(notice)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+)
+
+// defaultScanTimeout is how long commands scan for cubes without a profile
+// setting.
+const defaultScanTimeout = 5 * time.Second
+
+// activeProfile holds the settings of the profile in use, loaded before
+// every command runs. It is empty when no profile is configured.
+var activeProfile recorder.Profile
+
+// loadProfile loads the profile named with --profile, or the config's
+// default profile, with --metric applied. A config that fails to load is
+// left for the command to report, unless --profile asked for a profile from
+// it.
+func loadProfile() error {
+	if _, err := gocube.ParseMetric(metricName); err != nil {
+		return fmt.Errorf("invalid --metric %q (want htm, qtm or stm)", metricName)
+	}
+	defer func() {
+		if metricName != "" {
+			activeProfile.Metric = metricName
+		}
+	}()
+
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		if profileName != "" {
+			return err
+		}
+		return nil
+	}
+	p, err := cfg.ActiveProfile(profileName)
+	if err != nil {
+		return err
+	}
+	activeProfile = p
+	return nil
+}
+
+// turnMetric returns the metric move counts are shown in.
+func turnMetric() gocube.Metric {
+	return activeProfile.TurnMetric()
+}
+
+// countMoves counts moves in the turn metric.
+func countMoves(moves []gocube.Move) int {
+	return gocube.CountMoves(moves, turnMetric())
+}
+
+// scanTimeout returns how long to scan for cubes.
+func scanTimeout() time.Duration {
+	if activeProfile.ScanTimeoutSeconds > 0 {
+		return time.Duration(activeProfile.ScanTimeoutSeconds) * time.Second
+	}
+	return defaultScanTimeout
+}
+
+// reportsDir returns the base directory for generated reports.
+func reportsDir() string {
+	if activeProfile.ReportDir != "" {
+		return activeProfile.ReportDir
+	}
+	return "reports"
+}
+
+// preferDevice moves the profile's preferred cube to the front of results,
+// if it was found.
+func preferDevice(results []ble.ScanResult) {
+	for i, r := range results {
+		if activeProfile.Device != "" && r.UUID == activeProfile.Device {
+			copy(results[1:i+1], results[:i])
+			results[0] = r
+			return
+		}
+	}
+}
+
+// profileContext returns the default context of new solves: the config's
+// context plus the profile's solving method.
+func profileContext(cfg recorder.Config) map[string]string {
+	if activeProfile.Method == "" {
+		return cfg.Context
+	}
+	return mergeContext(cfg.Context, map[string]string{"method": activeProfile.Method})
+}
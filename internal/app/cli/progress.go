@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	progressBarWidth = 30
+	progressDelay    = 500 * time.Millisecond // Quick jobs finish without a bar
+	progressRedraw   = 100 * time.Millisecond
+)
+
+// progress draws a progress bar with an ETA on stderr for long operations
+// over a known number of units, usually solves. It draws nothing unless
+// stderr is a terminal, so piped and JSON output stay clean.
+type progress struct {
+	label string
+	total int
+	done  int
+	start time.Time
+	drawn time.Time
+	out   io.Writer
+}
+
+// newProgress starts a progress bar for total units.
+func newProgress(label string, total int) *progress {
+	p := &progress{label: label, total: total, start: time.Now()}
+	if isTerminal(os.Stderr) {
+		p.out = os.Stderr
+	}
+	return p
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Step records a finished unit and redraws the bar.
+func (p *progress) Step() {
+	p.done++
+	if p.out == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(p.start) < progressDelay || (now.Sub(p.drawn) < progressRedraw && p.done < p.total) {
+		return
+	}
+	p.drawn = now
+	fmt.Fprintf(p.out, "\r%s %s", p.label, progressLine(p.done, p.total, now.Sub(p.start)))
+}
+
+// Done clears the bar.
+func (p *progress) Done() {
+	if p.out != nil && !p.drawn.IsZero() {
+		fmt.Fprintf(p.out, "\r\033[K")
+	}
+}
+
+// progressLine formats the bar, count and ETA for done of total units
+// taking elapsed so far, e.g. "[=======>      ] 120/300  ETA 12s".
+func progressLine(done, total int, elapsed time.Duration) string {
+	if total <= 0 {
+		return ""
+	}
+	filled := done * progressBarWidth / total
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	line := fmt.Sprintf("[%s] %d/%d", bar, done, total)
+	if done > 0 && done < total {
+		eta := elapsed * time.Duration(total-done) / time.Duration(done)
+		line += "  ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+// interruptContext returns a context cancelled by Ctrl+C, for operations
+// that stop after the unit they are working on, or roll it back if it
+// writes (see storage.DB.InTx). A second Ctrl+C exits at once. Call stop
+// when done.
+func interruptContext() (ctx context.Context, stop context.CancelFunc) {
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// interrupted describes an operation stopped by Ctrl+C after done of total
+// units.
+func interrupted(done, total int, unit string) error {
+	return fmt.Errorf("interrupted after %d of %d %s", done, total, unit)
+}
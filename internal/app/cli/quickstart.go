@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+var (
+	quickstartMoveTimeout time.Duration
+	quickstartNoOpen      bool
+)
+
+var quickstartCmd = &cobra.Command{
+	Use:   "quickstart",
+	Short: "Guided first run: connect, record a solve and view the report",
+	Long: `Walk through the whole toolchain once, checking each step before moving on:
+
+  1. Check Bluetooth is available to this program
+  2. Scan for a GoCube
+  3. Connect to it
+  4. Verify moves arrive (turn any face)
+  5. Record one short solve (scramble a few moves, then undo them)
+  6. Generate a report and open the visualizer
+
+If a step fails, quickstart stops with a hint on how to fix it. Run it
+again once the problem is solved.`,
+	RunE: runQuickstart,
+}
+
+func init() {
+	rootCmd.AddCommand(quickstartCmd)
+	quickstartCmd.Flags().DurationVar(&quickstartMoveTimeout, "move-timeout", 30*time.Second, "How long to wait for the first move")
+	quickstartCmd.Flags().BoolVar(&quickstartNoOpen, "no-open", false, "Do not open the visualizer in a browser")
+}
+
+// quickstartSteps is the number of steps shown in step headers.
+const quickstartSteps = 6
+
+func quickstartStep(n int, title string) {
+	fmt.Println()
+	fmt.Printf("[%d/%d] %s\n", n, quickstartSteps, title)
+}
+
+// quickstartFail prints hints for a failed step and returns err.
+func quickstartFail(err error, hints ...string) error {
+	fmt.Println("  FAILED")
+	if len(hints) > 0 {
+		fmt.Println()
+		fmt.Println("  To fix:")
+		for _, h := range hints {
+			fmt.Printf("    - %s\n", h)
+		}
+	}
+	fmt.Println()
+	return err
+}
+
+// bluetoothHints returns platform-specific advice for Bluetooth access problems.
+func bluetoothHints() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"Turn Bluetooth on in System Settings > Bluetooth",
+			"Allow your terminal app in System Settings > Privacy & Security > Bluetooth",
+			"Quit and reopen the terminal after granting permission",
+		}
+	case "windows":
+		return []string{
+			"Turn Bluetooth on in Settings > Bluetooth & devices",
+			"Allow apps to access Bluetooth in Settings > Privacy & security > Radios",
+		}
+	default:
+		return []string{
+			"Check the Bluetooth service is running: systemctl status bluetooth",
+			"Check the adapter is not blocked: rfkill list",
+			"Make sure your user can reach BlueZ over D-Bus (e.g. is in the bluetooth group)",
+		}
+	}
+}
+
+// quickstartInput reads lines from stdin in the background so the flow can
+// wait for Enter and cube messages at the same time.
+func quickstartInput() <-chan struct{} {
+	lines := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- struct{}{}
+		}
+		close(lines)
+	}()
+	return lines
+}
+
+func runQuickstart(cmd *cobra.Command, args []string) error {
+	fmt.Println("GoCube Quickstart")
+	fmt.Println("=================")
+
+	// 1. Bluetooth
+	quickstartStep(1, "Checking Bluetooth...")
+	client, err := ble.NewClient()
+	if err != nil {
+		return quickstartFail(fmt.Errorf("bluetooth not available: %w", err), bluetoothHints()...)
+	}
+	fmt.Println("  OK")
+
+	// 2. Scan
+	quickstartStep(2, "Scanning for a GoCube (turn a face to wake it)...")
+	var results []ble.ScanResult
+	for attempt := 1; attempt <= 3 && len(results) == 0; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), scanTimeout())
+		results, err = client.Scan(ctx, scanTimeout())
+		cancel()
+		if err != nil {
+			return quickstartFail(fmt.Errorf("scan failed: %w", err), bluetoothHints()...)
+		}
+		if len(results) == 0 && attempt < 3 {
+			fmt.Printf("  Scan %d: no devices found, retrying...\n", attempt)
+		}
+	}
+	if len(results) == 0 {
+		return quickstartFail(fmt.Errorf("no GoCube found"),
+			"Make sure the cube is charged and turn any face to wake it",
+			"Disconnect the cube from the GoCube phone app (it only accepts one connection)",
+			"Move the cube closer to this computer",
+		)
+	}
+	preferDevice(results)
+	target := results[0]
+	fmt.Printf("  Found %s (RSSI %d)\n", target.Name, target.RSSI)
+
+	// 3. Connect
+	quickstartStep(3, "Connecting...")
+	msgs := make(chan *protocol.Message, 256)
+	client.SetMessageCallback(func(msg *protocol.Message) {
+		select {
+		case msgs <- msg:
+		default:
+		}
+	})
+	if err := client.ConnectToResult(context.Background(), target); err != nil {
+		return quickstartFail(fmt.Errorf("connection failed: %w", err),
+			"Turn a face to wake the cube and run quickstart again",
+			"Close any other app connected to the cube",
+		)
+	}
+	defer client.Disconnect()
+	client.RequestBattery()
+	fmt.Printf("  Connected to %s\n", client.DeviceName())
+
+	// 4. Verify moves
+	quickstartStep(4, "Turn any face of the cube...")
+	timeout := time.After(quickstartMoveTimeout)
+	var first []gocube.Move
+	for len(first) == 0 {
+		select {
+		case msg := <-msgs:
+			if msg.Type != protocol.MsgTypeRotation {
+				continue
+			}
+			if rotations, err := protocol.DecodeRotation(msg.Payload); err == nil {
+				first = rotationsToMoves(rotations, time.Now())
+			}
+		case <-timeout:
+			return quickstartFail(fmt.Errorf("no moves received within %s", quickstartMoveTimeout),
+				"Turn a face firmly until it clicks into place",
+				"If the cube went to sleep, turn a face to wake it and run quickstart again",
+			)
+		}
+	}
+	fmt.Printf("  Received %s\n", gocube.FormatMoves(first))
+	if battery := client.Battery(); battery >= 0 {
+		fmt.Printf("  Battery: %d%%\n", battery)
+	}
+
+	// 5. Record a short solve
+	quickstartStep(5, "Recording a short solve...")
+	fmt.Println("  Start from a SOLVED cube. Undo the move you just made if needed,")
+	fmt.Println("  then press Enter.")
+	input := quickstartInput()
+	if !quickstartWait(input, msgs) {
+		return fmt.Errorf("input closed")
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return quickstartFail(err, "Check the database path (--db) is writable")
+	}
+	defer db.Close()
+
+	session := recorder.NewSession(db, nil)
+	solveID, err := session.Start("", "", client.DeviceName(), client.DeviceUUID(), version)
+	if err != nil {
+		return quickstartFail(err, "Check the database path (--db) is writable")
+	}
+	if err := session.MarkPhase("scramble", nil); err != nil {
+		return err
+	}
+
+	fmt.Println("  Scramble with a few moves (e.g. R U F), then press Enter.")
+	tracker := gocube.NewCube()
+	var scramble []gocube.Move
+	if !quickstartFeed(session, tracker, msgs, input, nil, func(moves []gocube.Move) bool {
+		scramble = append(scramble, moves...)
+		return false
+	}) {
+		return fmt.Errorf("input closed")
+	}
+	if len(scramble) == 0 || tracker.IsSolved() {
+		session.End()
+		return quickstartFail(fmt.Errorf("cube was not scrambled"), "Turn a few faces before pressing Enter")
+	}
+	if err := session.MarkPhase("inspection", nil); err != nil {
+		return err
+	}
+
+	fmt.Println("  Now undo your scramble. The solve ends when the cube is solved")
+	fmt.Println("  (or press Enter to stop early).")
+	solveStarted := false
+	highest := tracker.Phase()
+	quickstartFeed(session, tracker, msgs, input, func() {
+		// Mark white_cross just before the first solving move is recorded
+		if !solveStarted {
+			solveStarted = true
+			ts := session.CurrentTimestamp() - 1
+			if ts < 0 {
+				ts = 0
+			}
+			session.MarkPhaseAt("white_cross", ts, nil)
+		}
+	}, func(moves []gocube.Move) bool {
+		phase := tracker.Phase()
+		if phase > highest && phase != gocube.PhaseScrambled && phase != gocube.PhaseWhiteCross {
+			session.MarkPhase(phaseToKey(phase), nil)
+			highest = phase
+		}
+		return tracker.IsSolved()
+	})
+	if err := session.End(); err != nil {
+		return err
+	}
+	if tracker.IsSolved() {
+		fmt.Println("  Solved!")
+	}
+	fmt.Printf("  Recorded solve %s\n", solveID[:8])
+	if earned, err := updateAchievements(db); err == nil && len(earned) > 0 {
+		fmt.Printf("  %s\n", achievementNotice(earned))
+	}
+	if pbs, err := updatePersonalBests(db); err == nil && len(pbs) > 0 {
+		fmt.Printf("  %s\n", personalBestNotice(pbs))
+	}
+
+	// 6. Report and visualizer
+	quickstartStep(6, "Generating report...")
+	reportDir, err := GenerateReportForSolve(db, solveID)
+	if err != nil {
+		return quickstartFail(fmt.Errorf("report generation failed: %w", err),
+			"Run 'gocube report solve "+solveID[:8]+"' to retry",
+		)
+	}
+	vizPath := filepath.Join(reportDir, "visualizer.html")
+	fmt.Printf("  Report: %s\n", reportDir)
+	if !quickstartNoOpen {
+		if err := openInBrowser(vizPath); err != nil {
+			fmt.Printf("  Could not open a browser (%v); open %s manually\n", err, vizPath)
+		} else {
+			fmt.Println("  Opened visualizer.html")
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("All done. Next steps:")
+	fmt.Println("  gocube record        Record solves with the live TUI")
+	fmt.Println("  gocube solve list    List recorded solves")
+	fmt.Println("  gocube report trend  Track progress across solves")
+	return nil
+}
+
+// quickstartWait waits for Enter, discarding cube messages meanwhile.
+// Returns false if stdin was closed.
+func quickstartWait(input <-chan struct{}, msgs <-chan *protocol.Message) bool {
+	for {
+		select {
+		case _, ok := <-input:
+			return ok
+		case <-msgs:
+		}
+	}
+}
+
+// quickstartFeed records cube messages into the session until Enter is
+// pressed or onMoves returns true. beforeMove (optional) runs ahead of each
+// rotation message being recorded. Returns false if stdin was closed.
+func quickstartFeed(session *recorder.Session, tracker *gocube.Cube, msgs <-chan *protocol.Message, input <-chan struct{}, beforeMove func(), onMoves func([]gocube.Move) bool) bool {
+	for {
+		select {
+		case _, ok := <-input:
+			return ok
+		case msg := <-msgs:
+			if msg.Type == protocol.MsgTypeRotation && beforeMove != nil {
+				beforeMove()
+			}
+			session.HandleMessage(msg)
+			if msg.Type != protocol.MsgTypeRotation {
+				continue
+			}
+			rotations, err := protocol.DecodeRotation(msg.Payload)
+			if err != nil {
+				continue
+			}
+			moves := rotationsToMoves(rotations, time.Now())
+			tracker.Apply(moves...)
+			for _, m := range moves {
+				fmt.Printf("  %s", m.Notation())
+			}
+			fmt.Println()
+			if onMoves(moves) {
+				return true
+			}
+		}
+	}
+}
+
+// openInBrowser opens a local file with the platform's default handler.
+func openInBrowser(path string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		c = exec.Command("open", path)
+	case "windows":
+		c = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		c = exec.Command("xdg-open", path)
+	}
+	return c.Start()
+}
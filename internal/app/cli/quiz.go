@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	quizWindow    int
+	quizCount     int
+	quizPerSolve  int
+	quizMinPause  time.Duration
+	quizOutputDir string
+)
+
+var quizCmd = &cobra.Command{
+	Use:   "quiz",
+	Short: "Flashcards from the states you paused at",
+}
+
+var quizGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a quiz from the longest pauses of recent solves",
+	Long: `Find where recent solves paused longest and ask about those states:
+which PLL case it is, or otherwise the next layer-by-layer step. The quiz
+is written to reports/quiz/quiz.json for "gocube quiz play".
+
+  gocube quiz generate --window 100 --count 20 --min-pause 1s`,
+	RunE: runQuizGenerate,
+}
+
+var quizPlayCmd = &cobra.Command{
+	Use:   "play [quiz-file]",
+	Short: "Play a generated quiz",
+	Long: `Play a quiz written by "gocube quiz generate" (reports/quiz/quiz.json by
+default). Press 1-4 to answer, then SPACE or ENTER for the next question.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runQuizPlay,
+}
+
+func init() {
+	rootCmd.AddCommand(quizCmd)
+
+	quizCmd.AddCommand(quizGenerateCmd)
+	quizGenerateCmd.Flags().IntVar(&quizWindow, "window", 50, "Number of recent solves to look at")
+	quizGenerateCmd.Flags().IntVar(&quizCount, "count", 10, "Number of questions")
+	quizGenerateCmd.Flags().IntVar(&quizPerSolve, "per-solve", 3, "Most questions from one solve")
+	quizGenerateCmd.Flags().DurationVar(&quizMinPause, "min-pause", 2*time.Second, "Shortest pause to ask about")
+	quizGenerateCmd.Flags().StringVarP(&quizOutputDir, "output", "o", "", "Base output directory (default: reports)")
+
+	quizCmd.AddCommand(quizPlayCmd)
+}
+
+// quizPath returns where quizzes are written and played from by default.
+func quizPath() string {
+	baseDir := quizOutputDir
+	if baseDir == "" {
+		baseDir = reportsDir()
+	}
+	return filepath.Join(baseDir, "quiz", "quiz.json")
+}
+
+// quizCandidate is a pause to ask about.
+type quizCandidate struct {
+	solveID string
+	pause   analysis.QuizPause
+	records []storage.MoveRecord
+}
+
+func runQuizGenerate(cmd *cobra.Command, args []string) error {
+	if quizCount < 1 || quizPerSolve < 1 {
+		return fmt.Errorf("invalid --count %d or --per-solve %d", quizCount, quizPerSolve)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+
+	solves, err := solveRepo.List(quizWindow)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var candidates []quizCandidate
+	for _, s := range solves {
+		if s.IsManual() {
+			continue
+		}
+		moveRecords, err := moveRepo.GetBySolve(s.SolveID)
+		if err != nil {
+			return err
+		}
+		for _, p := range analysis.LongestPauses(moveRecords, quizMinPause.Milliseconds(), quizPerSolve) {
+			candidates = append(candidates, quizCandidate{solveID: s.SolveID, pause: p, records: moveRecords})
+		}
+	}
+	if len(candidates) == 0 {
+		fmt.Printf("No pauses of %s or more in the last %d solves\n", quizMinPause, len(solves))
+		return nil
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].pause.PauseMs > candidates[j].pause.PauseMs
+	})
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	quiz := analysis.Quiz{GeneratedAt: time.Now().UTC().Format(time.RFC3339)}
+	seen := make(map[string]bool)
+	bar := newProgress("Generating", len(candidates))
+	for i, c := range candidates {
+		if ctx.Err() != nil {
+			bar.Done()
+			return interrupted(i, len(candidates), "pauses")
+		}
+		bar.Step()
+		if len(quiz.Questions) == quizCount {
+			continue
+		}
+
+		cube, err := recorder.StateAt(db, c.solveID, c.pause.MoveIndex)
+		if err != nil {
+			bar.Done()
+			return err
+		}
+		state := cube.String()
+		if seen[state] {
+			continue
+		}
+
+		var next []storage.MoveRecord
+		for _, r := range c.records {
+			if r.MoveIndex >= c.pause.MoveIndex {
+				next = append(next, r)
+			}
+		}
+		q, ok := analysis.NewQuizQuestion(cube, storage.ToMoves(next), c.pause, c.solveID, rng)
+		if !ok {
+			continue
+		}
+		seen[state] = true
+		quiz.Questions = append(quiz.Questions, q)
+	}
+	bar.Done()
+
+	if len(quiz.Questions) == 0 {
+		fmt.Println("No questions: the pauses were all at solved states")
+		return nil
+	}
+
+	path := quizPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := writeJSON(path, quiz); err != nil {
+		return err
+	}
+
+	kinds := make(map[analysis.QuizKind]int)
+	for _, q := range quiz.Questions {
+		kinds[q.Kind]++
+	}
+	fmt.Printf("Generated %d questions (%d PLL, %d next step) from %d solves\n",
+		len(quiz.Questions), kinds[analysis.QuizPLL], kinds[analysis.QuizNextMove], len(solves))
+	fmt.Printf("Quiz written: %s\n", path)
+	fmt.Println("Play it with: gocube quiz play")
+	return nil
+}
+
+func runQuizPlay(cmd *cobra.Command, args []string) error {
+	path := quizPath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && len(args) == 0 {
+		return fmt.Errorf("no quiz at %s: run \"gocube quiz generate\" first", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read quiz: %w", err)
+	}
+	var quiz analysis.Quiz
+	if err := json.Unmarshal(data, &quiz); err != nil {
+		return fmt.Errorf("failed to parse quiz: %w", err)
+	}
+	if len(quiz.Questions) == 0 {
+		fmt.Println("The quiz has no questions")
+		return nil
+	}
+
+	model := &quizModel{quiz: quiz, chosen: -1, style: defaultCubeRenderOptions()}
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("quiz error: %w", err)
+	}
+
+	fmt.Printf("Score: %d/%d\n", model.score, model.answered)
+	return nil
+}
+
+// quizModel is the TUI of a quiz, one question at a time.
+type quizModel struct {
+	quiz     analysis.Quiz
+	index    int // Question shown
+	chosen   int // Choice picked for the question, or -1 before answering
+	score    int
+	answered int
+	finished bool
+	style    render.Options // How the cube is drawn
+}
+
+func (m *quizModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *quizModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+
+	case " ", "enter", "n":
+		if m.finished {
+			return m, tea.Quit
+		}
+		if m.chosen < 0 {
+			return m, nil
+		}
+		m.chosen = -1
+		m.index++
+		if m.index == len(m.quiz.Questions) {
+			m.finished = true
+		}
+
+	default:
+		if m.finished || m.chosen >= 0 || len(key.String()) != 1 {
+			return m, nil
+		}
+		q := m.quiz.Questions[m.index]
+		choice := int(key.String()[0] - '1')
+		if choice < 0 || choice >= len(q.Choices) {
+			return m, nil
+		}
+		m.chosen = choice
+		m.answered++
+		if choice == q.Answer {
+			m.score++
+		}
+	}
+	return m, nil
+}
+
+func (m *quizModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("GoCube Quiz"))
+	b.WriteString("\n\n")
+
+	if m.finished {
+		b.WriteString(fmt.Sprintf("Score: %s\n\n", phaseStyle.Render(fmt.Sprintf("%d/%d", m.score, m.answered))))
+		b.WriteString(helpStyle.Render("SPACE/q=quit"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	q := m.quiz.Questions[m.index]
+	b.WriteString(statusStyle.Render(fmt.Sprintf("Question %d/%d  Score %d/%d",
+		m.index+1, len(m.quiz.Questions), m.score, m.answered)))
+	b.WriteString("\n")
+	b.WriteString(statusStyle.Render(fmt.Sprintf("You paused %.1fs here after move %d of solve %s",
+		float64(q.PauseMs)/1000, q.MoveIndex, q.SolveID)))
+	b.WriteString("\n\n")
+
+	b.WriteString(q.Cube().Render(m.style))
+	b.WriteString("\n")
+	b.WriteString(phaseStyle.Render(q.Prompt))
+	b.WriteString("\n\n")
+
+	for i, choice := range q.Choices {
+		line := fmt.Sprintf("  %d) %s", i+1, choice)
+		switch {
+		case m.chosen >= 0 && i == q.Answer:
+			line = moveStyle.Render(line + "  ✓")
+		case i == m.chosen:
+			line = errorStyle.Render(line + "  ✗")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	if m.chosen >= 0 {
+		b.WriteString("\n")
+		b.WriteString(q.Explanation)
+		b.WriteString("\n")
+		if q.YourMoves != "" {
+			b.WriteString(statusStyle.Render("You played: " + q.YourMoves))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	help := "1-4=answer  q=quit"
+	if m.chosen >= 0 {
+		help = "SPACE/n=next question  q=quit"
+	}
+	b.WriteString(helpStyle.Render(help))
+	b.WriteString("\n")
+
+	return b.String()
+}
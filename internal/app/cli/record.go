@@ -13,10 +13,12 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/notation"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
 )
 
 var recordCmd = &cobra.Command{
@@ -29,15 +31,52 @@ Keyboard shortcuts:
   s       - Start a new solve
   e       - End the current solve
   1-6     - Mark phase (1=inspection, 2=white_cross, 3=white_corners,
-            4=middle_layer, 5=bottom_perm, 6=bottom_orient)
+            4=middle_layer, 5=bottom_perm, 6=bottom_orient); with a
+            phase scheme, 0=inspection and 1-9 its phases in order
+  b       - Bookmark this moment (jump to it in replay and the visualizer)
+  m       - Toggle merged move display (R R shown as R2; storage keeps R R)
+  h       - Show a hint for the next step (layer-by-layer)
+  d       - Toggle debug cube state
+  v       - Toggle tracked vs device state comparison (polls cube STATE)
   q/Esc   - Quit
 
-The TUI will display moves in real-time as you solve the cube.`,
+The TUI will display moves in real-time as you solve the cube.
+
+If a cube dies mid-solve (e.g. flat battery), quit and run again with
+--continue on another cube. The unfinished solve carries on in the same
+record: the device change is annotated and the tracker is resynced to the
+new cube's reported state.
+
+Each solve shows a WCA-style random-state scramble to apply before pressing
+SPACE; it is stored with the solve. Use --scramble=false to scramble freely.
+
+Turns the cube reports together in one notification are tagged as
+simultaneous and stored at the notification's time. --batch-spread spreads
+them over the time since the previous notification (at most the given
+duration), keeping their reported order. Turns from cubes that time them
+(GAN Gen2, MoYu AI) are placed by the cube's clock instead. Each move is
+stored with how its time was obtained: received, device or interpolated.`,
 	RunE: runRecord,
 }
 
+var (
+	recordContinue    bool
+	recordScramble    bool
+	recordBatchSpread time.Duration
+)
+
+// The hint animation shows a move every hintFrameInterval and holds the
+// result for hintHoldFrames more before starting over.
+const (
+	hintFrameInterval = 700 * time.Millisecond
+	hintHoldFrames    = 2
+)
+
 func init() {
 	solveCmd.AddCommand(recordCmd)
+	recordCmd.Flags().BoolVar(&recordContinue, "continue", false, "Continue the unfinished solve on the connected cube")
+	recordCmd.Flags().BoolVar(&recordScramble, "scramble", true, "Show a random-state scramble before each solve")
+	recordCmd.Flags().DurationVar(&recordBatchSpread, "batch-spread", 0, "Spread turns reported together over up to this long (0 to keep the notification time)")
 }
 
 // Styles
@@ -67,6 +106,8 @@ var (
 type tickMsg time.Time
 type bleConnectedMsg struct{ name string }
 type bleDisconnectedMsg struct{}
+type bleReconnectedMsg struct{ err error } // Auto-reconnect finished; err if it gave up
+type hookFailedMsg struct{ err error }     // A solve hook command failed
 type bleMessageMsg struct{ msg *protocol.Message }
 type moveRecordedMsg struct{ move gocube.Move }
 type phaseMarkedMsg struct{ phase string }
@@ -84,8 +125,13 @@ type recordModel struct {
 	deviceName   string
 	battery      int
 	msgChan      chan *protocol.Message
+	linkChan     chan tea.Msg // Disconnect, reconnect and hook failure events
+	linkLost     bool         // Reconnecting failed; the session is kept for --continue
 	scanResults  []ble.ScanResult // Pre-scanned devices
 	prescanClient *ble.Client      // Client used for pre-scan
+	keepAlive     time.Duration    // Quiet time before pinging the cube (0 = off)
+	idleStop      time.Duration    // No-move time before a solve is auto-stopped (0 = off)
+	leds          bool             // Drive the backlight; the profile can turn it off
 
 	// Database
 	db        *storage.DB
@@ -95,14 +141,39 @@ type recordModel struct {
 	// Cube state tracking
 	tracker       *gocube.Cube
 	highestPhase  gocube.Phase // highest phase reached (monotonic)
+	scheme        *recorder.SchemeTracker // profile's phase scheme; nil for layer-by-layer
 	autoPhase     bool         // whether to auto-detect phases
 	detectedPhase string       // current detected phase from cube state
 	solveStarted  bool         // true once first move is made after inspection
 	inspecting    bool         // true after SPACE pressed, waiting for first move
 	debugMode     bool         // show detailed cube state for debugging
+	stateCompare  bool         // show tracked vs device-reported state panel
+	deviceState   *gocube.Cube // last state reported by the cube
+	deviceStateAt time.Time    // when deviceState was received
+	resyncPending bool         // apply the next device state to the tracker
+	bookmarks     int          // bookmarks dropped in the current solve
+	mergeMoves    bool         // show R R as R2; stored moves stay raw
+	hintAfter     time.Duration // pause before a hint is shown (0 = off)
+	hint          string        // hint for the next step, cleared by the next move
+	hintFrames    []render.Frame // the hint's moves animated on the tracked cube
+	hintAt        time.Time      // when the hint was shown
+	cubeStyle     render.Options // how cube states are drawn
+	lastMoveAt    time.Time     // when the last move arrived
 
 	// Timing
 	inspectStart  time.Time // when inspection started (SPACE pressed)
+	timer         *gocube.Timer // WCA inspection countdown and penalties
+	pacing        *recorder.PacingEngine
+
+	// Hot reload of config and algorithm library
+	watchers      []*recorder.FileWatcher
+	lastWatch     time.Time
+	notice        string // last reload result shown in the UI
+
+	// Solve context metadata (cube, lube, mood, ...)
+	context       map[string]string
+	contextInput  bool   // context prompt is open
+	contextBuf    string // text typed into the context prompt
 
 	// State
 	recording    bool
@@ -124,9 +195,16 @@ type recordModel struct {
 
 	// Report
 	reportPath string
+	summary    string // One-line summary of the last finished solve
+	scramble   string // Scramble shown for the current solve, if any
+	unlocked   string // Achievements earned by the last finished solve
+	newBests   string // Personal bests set by the last finished solve
+
+	// Unfinished solve to continue once the cube connects
+	continueSolveID string
 }
 
-func newRecordModel(db *storage.DB, stateFile *recorder.StateFile, prescanClient *ble.Client, scanResults []ble.ScanResult) *recordModel {
+func newRecordModel(db *storage.DB, stateFile *recorder.StateFile, cfg recorder.Config, prescanClient *ble.Client, scanResults []ble.ScanResult) *recordModel {
 	// Create logger and start logging
 	logger := NewSolveLogger()
 	homeDir, _ := os.UserHomeDir()
@@ -136,15 +214,28 @@ func newRecordModel(db *storage.DB, stateFile *recorder.StateFile, prescanClient
 		fmt.Printf("Warning: could not start logging: %v\n", err)
 	}
 
+	pacing := recorder.NewPacingEngine(cfg.Pacing)
+	pacing.SetSuperPhases(cfg.SuperPhases)
+
 	return &recordModel{
 		db:            db,
 		stateFile:     stateFile,
 		session:       recorder.NewSession(db, stateFile),
 		tracker:       gocube.NewCube(),
+		timer:         gocube.NewTimer(),
+		pacing:        pacing,
+		context:       mergeContext(profileContext(cfg), nil),
 		autoPhase:     true, // Enable auto phase detection
 		battery:       -1,
 		msgChan:       make(chan *protocol.Message, 100),
+		linkChan:      make(chan tea.Msg, 4),
 		prescanClient: prescanClient,
+		leds:          activeProfile.LEDsOn(),
+		keepAlive:     time.Duration(cfg.KeepAliveSeconds) * time.Second,
+		idleStop:      time.Duration(cfg.IdleStopMinutes) * time.Minute,
+		mergeMoves:    cfg.Display.MergeMoves,
+		hintAfter:     time.Duration(cfg.Display.HintAfterSeconds) * time.Second,
+		cubeStyle:     cubeRenderOptions(cfg.Display),
 		scanResults:   scanResults,
 		logger:        logger,
 	}
@@ -155,9 +246,16 @@ func (m *recordModel) Init() tea.Cmd {
 		m.connectBLE(),
 		m.tickCmd(),
 		m.listenForMessages(),
+		m.listenForLink(),
 	)
 }
 
+func (m *recordModel) listenForLink() tea.Cmd {
+	return func() tea.Msg {
+		return <-m.linkChan
+	}
+}
+
 func (m *recordModel) listenForMessages() tea.Cmd {
 	return func() tea.Msg {
 		msg := <-m.msgChan
@@ -206,21 +304,36 @@ func (m *recordModel) connectBLE() tea.Cmd {
 			}
 		})
 
+		// Reconnect if the link drops; the session and tracker carry on
+		client.SetReconnectPolicy(ble.DefaultReconnectPolicy)
+		client.SetDisconnectCallback(func() {
+			select {
+			case m.linkChan <- bleDisconnectedMsg{}:
+			default:
+			}
+		})
+		client.SetReconnectCallback(func(err error) {
+			select {
+			case m.linkChan <- bleReconnectedMsg{err: err}:
+			default:
+			}
+		})
+
 		ctx := context.Background()
 		state := m.stateFile.State()
 		results := m.scanResults
 
-		// Find the target device - prefer last known device if found in scan
+		// Find the target device - prefer the profile's device, then the
+		// last known device, if found in scan
 		var target *ble.ScanResult
-		if state.LastDeviceID != "" {
+		for _, id := range []string{activeProfile.Device, state.LastDeviceID} {
 			for i := range results {
-				if results[i].UUID == state.LastDeviceID {
+				if id != "" && target == nil && results[i].UUID == id {
 					target = &results[i]
-					break
 				}
 			}
 		}
-		// If last device not in scan results, use first found
+		// If neither is in the scan results, use first found
 		if target == nil {
 			target = &results[0]
 		}
@@ -236,6 +349,15 @@ func (m *recordModel) connectBLE() tea.Cmd {
 			// Log but don't fail - orientation is optional
 		}
 
+		// The session records the reported model on each solve
+		client.RequestCubeType()
+
+		// Sync the cube's own counters, turns made away from the app included
+		client.RequestOfflineStats()
+
+		// Keep the cube awake and detect when it has gone to sleep
+		client.StartKeepAlive(m.keepAlive)
+
 		return bleConnectedMsg{name: client.DeviceName()}
 	}
 }
@@ -248,6 +370,12 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.logger.LogKeyPress(msg.String())
 		}
 
+		// The context prompt captures all keys except ctrl+c
+		if m.contextInput && msg.String() != "ctrl+c" {
+			m.handleContextKey(msg)
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "esc", "ctrl+c":
 			m.quitting = true
@@ -270,36 +398,97 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.endSolve()
 			}
 
-		case "0", "1", "2", "3", "4", "5", "6", "7":
+		case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
 			if m.recording {
 				num := int(msg.String()[0] - '0')
 				phase := storage.NumberToPhaseKey(num)
+				if m.scheme != nil {
+					phase = m.scheme.NumberToPhaseKey(num)
+				}
 				if phase != "" {
 					return m, m.markPhase(phase)
 				}
 			}
 
 		case "r", "l":
-			if m.recording {
+			if m.recording && m.scheme == nil {
 				phase := storage.AlgoKeyToPhaseKey(msg.String())
 				if phase != "" {
 					return m, m.markPhase(phase)
 				}
 			}
 
+		case "c":
+			// Open the solve context prompt
+			m.contextInput = true
+			m.contextBuf = ""
+
 		case "d":
 			// Toggle debug mode
 			m.debugMode = !m.debugMode
 
+		case "m":
+			// Toggle merged move display
+			m.mergeMoves = !m.mergeMoves
+
+		case "h":
+			// Show a hint for the next step
+			if m.recording && m.solveStarted {
+				m.showHint()
+			}
+
+		case "v":
+			// Toggle state comparison panel
+			m.stateCompare = !m.stateCompare
+			if m.stateCompare {
+				if m.client != nil {
+					m.client.RequestState()
+				}
+				return m, m.scheduleStateRequest()
+			}
+
+		case "y":
+			// Resync the tracker to the device on the next fresh state
+			if m.stateCompare && m.recording && m.deviceState != nil &&
+				countStateDiffs(m.tracker, m.deviceState) > 0 {
+				m.resyncPending = true
+				m.notice = "Resyncing tracker to device state..."
+				if m.client != nil {
+					m.client.RequestState()
+				}
+			}
+
+		case "b":
+			// Drop a bookmark to find this moment in replay and the visualizer
+			if m.recording && m.session != nil {
+				bm, err := m.session.AddBookmark("")
+				if err != nil {
+					m.err = err
+				} else {
+					m.bookmarks++
+					// MoveIndex counts stored moves; show the position in the displayed moves
+					pos := notation.DisplayCount(notation.DisplayMoves(m.moves, m.mergeMoves), bm.MoveIndex)
+					m.notice = fmt.Sprintf("Bookmark %d at %.1fs (after move %d)", m.bookmarks, float64(bm.TsMs)/1000.0, pos)
+					if m.logger != nil {
+						m.logger.LogBookmark(bm.Note)
+					}
+				}
+			}
+
 		case " ", "enter":
 			// SPACE/ENTER ends scramble, starts inspection (before first move)
 			if m.recording && !m.solveStarted && !m.inspecting {
 				m.inspecting = true
 				m.inspectStart = time.Now()
+				m.timer.StartInspection()
 				m.currentPhase = "inspection"
+				m.pacing.EnterPhase("inspection", m.inspectStart)
 
-				// Mark inspection phase
+				// Mark inspection phase, which ends the scramble check
 				if m.autoPhase {
+					if p, ok := m.session.ScrambleProgress(); ok && !p.Complete {
+						m.notice = fmt.Sprintf("Scramble not verified: %s", p)
+					}
 					if err := m.session.MarkPhase("inspection", nil); err != nil {
 						m.err = err
 					}
@@ -311,7 +500,7 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				// Start slow flash during inspection and schedule repeating flash
-				if m.client != nil {
+				if m.ledsOn() {
 					m.client.SlowFlashBacklight()
 				}
 				return m, m.scheduleInspectionFlash()
@@ -330,6 +519,20 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.client != nil {
 			m.battery = m.client.Battery()
 		}
+		if m.recording {
+			m.firePacingCue(time.Time(msg))
+		}
+		if m.recording && m.solveStarted && m.hintAfter > 0 && m.hint == "" &&
+			time.Time(msg).Sub(m.lastMoveAt) >= m.hintAfter {
+			m.showHint()
+		}
+		if m.recording && m.idleStop > 0 && m.session.IdleFor() >= m.idleStop {
+			m.stopIdleSolve()
+		}
+		if time.Time(msg).Sub(m.lastWatch) >= reloadPollInterval {
+			m.lastWatch = time.Time(msg)
+			m.pollWatchers()
+		}
 		return m, m.tickCmd()
 
 	case bleConnectedMsg:
@@ -338,8 +541,12 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.stateFile != nil && m.client != nil {
 			m.stateFile.SetLastDevice(m.client.DeviceUUID(), m.deviceName)
 		}
+		if m.continueSolveID != "" {
+			m.continueSolve(m.continueSolveID)
+			m.continueSolveID = ""
+		}
 		// Flash LED on connect (with slight delay for BLE stack to settle)
-		if m.client != nil {
+		if m.ledsOn() {
 			go func() {
 				time.Sleep(500 * time.Millisecond)
 				m.client.FlashBacklight()
@@ -349,17 +556,49 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case bleDisconnectedMsg:
 		m.connected = false
 		m.deviceName = ""
+		return m, m.listenForLink()
+
+	case bleReconnectedMsg:
+		if msg.err != nil {
+			m.linkLost = true
+			m.err = msg.err
+			return m, m.listenForLink()
+		}
+		m.connected = true
+		m.deviceName = m.client.DeviceName()
+		m.client.EnableOrientation()
+		m.notice = "Reconnected"
+		if m.recording {
+			// Moves made while disconnected were missed; the client requests
+			// the cube state next, adopt it if it differs
+			m.resyncPending = true
+			m.notice = "Reconnected - resyncing cube state"
+		}
+		return m, m.listenForLink()
+
+	case hookFailedMsg:
+		m.notice = msg.err.Error()
+		return m, m.listenForLink()
 
 	case inspectionFlashMsg:
 		// Repeat slow flash while still in inspection mode
-		if m.inspecting && !m.solveStarted && m.client != nil {
+		if m.inspecting && !m.solveStarted && m.ledsOn() {
 			m.client.SlowFlashBacklight()
 			return m, m.scheduleInspectionFlash()
 		}
 
+	case stateRequestMsg:
+		// Keep polling while the comparison panel is open
+		if m.stateCompare {
+			if m.client != nil {
+				m.client.RequestState()
+			}
+			return m, m.scheduleStateRequest()
+		}
+
 	case solvedLedOffMsg:
 		// Turn off LED after solve celebration
-		if m.client != nil {
+		if m.ledsOn() {
 			m.client.ToggleBacklight()
 		}
 
@@ -380,6 +619,33 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.logger.LogBLEMessage(msg.msg, desc)
 		}
 
+		// Capture device-reported state for the comparison panel
+		if msg.msg.Type == protocol.MsgTypeState {
+			if state, err := gocube.CubeFromState(msg.msg.Payload); err == nil {
+				m.deviceState = state
+				m.deviceStateAt = time.Now()
+				if m.resyncPending {
+					m.resyncPending = false
+					if countStateDiffs(m.tracker, m.deviceState) > 0 {
+						m.resyncTracker()
+					}
+				}
+			} else if m.stateCompare {
+				m.err = fmt.Errorf("failed to decode state: %w", err)
+			}
+		}
+
+		// Record the cube's move counter against the current solve (experimental),
+		// and every reading as an offline stats sync
+		if msg.msg.Type == protocol.MsgTypeOfflineStats && m.client != nil {
+			if ev, err := protocol.DecodeOfflineStats(msg.msg.Payload); err == nil {
+				if m.solveID != "" {
+					storage.NewCounterRepository(m.db).Create(m.client.DeviceUUID(), m.solveID, ev.Moves, ev.Time, ev.Solves)
+				}
+				recordOfflineStats(m.db, m.client.DeviceUUID(), m.client.DeviceName(), ev)
+			}
+		}
+
 		// Check if this is the first move after inspection - mark phase BEFORE recording
 		if m.recording && m.inspecting && !m.solveStarted && msg.msg.Type == protocol.MsgTypeRotation {
 			m.solveStarted = true
@@ -387,21 +653,27 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.startTime = time.Now()
 			m.elapsed = 0
 
-			// Mark white_cross with a timestamp 1ms BEFORE the move will be recorded.
-			// This ensures the move falls into white_cross phase, not inspection.
+			// Mark white_cross (or the scheme's first phase) with a timestamp 1ms
+			// BEFORE the move will be recorded. This ensures the move falls into
+			// white_cross phase, not inspection.
 			// HandleMessage calculates its own timestamp, which will be >= currentTs.
 			if m.autoPhase {
+				firstPhase := "white_cross"
+				if m.scheme != nil {
+					firstPhase = m.scheme.Reset()
+				}
 				currentTs := m.session.CurrentTimestamp()
 				phaseTs := currentTs - 1
 				if phaseTs < 0 {
 					phaseTs = 0
 				}
-				if err := m.session.MarkPhaseAt("white_cross", phaseTs, nil); err != nil {
-					m.err = fmt.Errorf("failed to mark white_cross: %w", err)
+				if err := m.session.MarkPhaseAt(firstPhase, phaseTs, nil); err != nil {
+					m.err = fmt.Errorf("failed to mark %s: %w", firstPhase, err)
 				} else {
-					m.currentPhase = "white_cross"
+					m.currentPhase = firstPhase
+					m.pacing.EnterPhase(firstPhase, m.startTime)
 					if m.logger != nil {
-						m.logger.LogPhaseChange("white_cross")
+						m.logger.LogPhaseChange(firstPhase)
 					}
 				}
 			}
@@ -418,31 +690,52 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					moves := rotationsToMoves(rotations, time.Now())
 					for _, move := range moves {
 						m.moves = append(m.moves, move)
+						m.lastMoveAt = time.Now()
+						m.hint = ""
+						m.hintFrames = nil
 
 						// Update cube tracker
 						if m.tracker != nil {
 							m.tracker.Apply(move)
+							m.timer.HandleMove(move, m.tracker.IsSolved())
 							newPhase := m.tracker.Phase()
 
 							// Update detected phase display (shows current cube state)
 							m.detectedPhase = newPhase.String()
 
+							// A phase scheme detects its own phases from the cube state
+							if m.scheme != nil && m.autoPhase && m.solveStarted {
+								if phaseKey, ok := m.scheme.Update(m.tracker); ok {
+									if err := m.session.MarkPhase(phaseKey, nil); err == nil {
+										m.currentPhase = phaseKey
+										m.pacing.EnterPhase(phaseKey, time.Now())
+										if m.logger != nil {
+											m.logger.LogPhaseChange(phaseKey)
+										}
+										if m.ledsOn() {
+											m.client.ToggleBacklight()
+										}
+									}
+								}
+							}
+
 							// Handle phase transitions - only after solve started
 							// Only mark when reaching a NEW highest phase (monotonic progression)
 							// Skip: scrambled (not a real phase), white_cross (marked at solve start)
-							if m.autoPhase && m.solveStarted && newPhase > m.highestPhase &&
+							if m.scheme == nil && m.autoPhase && m.solveStarted && newPhase > m.highestPhase &&
 								newPhase != gocube.PhaseScrambled && newPhase != gocube.PhaseWhiteCross {
 								// Auto-mark phase completions during solving
 								phaseKey := phaseToKey(newPhase)
 								if err := m.session.MarkPhase(phaseKey, nil); err == nil {
 									m.highestPhase = newPhase
 									m.currentPhase = phaseKey
+									m.pacing.EnterPhase(phaseKey, time.Now())
 									// Log phase change
 									if m.logger != nil {
 										m.logger.LogPhaseChange(phaseKey)
 									}
 									// Flash LED on phase complete
-									if m.client != nil {
+									if m.ledsOn() {
 										m.client.ToggleBacklight()
 									}
 								}
@@ -452,20 +745,28 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							if m.solveStarted && m.tracker.IsSolved() {
 								m.session.End()
 								m.recording = false
+								if m.client != nil {
+									m.client.RequestOfflineStats()
+								}
+								m.pacing.Stop()
 								m.currentPhase = "complete"
 
 								// Generate report automatically
 								if m.solveID != "" {
-									reportDir, err := GenerateReportForSolve(m.db, m.solveID)
+									reportDir, err := GenerateReportAtLevel(m.db, m.solveID, ReportQuick)
 									if err != nil {
 										m.err = fmt.Errorf("report generation failed: %w", err)
 									} else {
 										m.reportPath = reportDir
 									}
 								}
+								m.summarizeSolve()
+								if r, ok := m.timer.Result(); ok && r.Penalty != gocube.PenaltyNone {
+									m.notice = fmt.Sprintf("Inspection penalty: %s (%.1fs inspection)", r, r.Inspection.Seconds())
+								}
 
 								// LED celebration: turn on for 5 seconds
-								if m.client != nil {
+								if m.ledsOn() {
 									m.client.ToggleBacklight()
 								}
 								return m, tea.Batch(
@@ -486,6 +787,10 @@ func (m *recordModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case phaseMarkedMsg:
 		m.currentPhase = msg.phase
+		if m.scheme != nil {
+			m.scheme.Mark(msg.phase)
+		}
+		m.pacing.EnterPhase(msg.phase, time.Now())
 
 	case phaseDetectedMsg:
 		m.detectedPhase = msg.phase
@@ -503,21 +808,41 @@ func (m *recordModel) startSolve() tea.Cmd {
 			deviceID = m.client.DeviceUUID()
 		}
 
-		solveID, err := m.session.Start("", "", deviceName, deviceID, "0.1.0")
+		scramble := ""
+		if recordScramble {
+			scramble = gocube.FormatMoves(gocube.GenerateScramble())
+		}
+
+		solveID, err := m.session.Start("", scramble, deviceName, deviceID, "0.1.0")
 		if err != nil {
 			m.err = err
 			return nil
 		}
+		m.scramble = scramble
+
+		if err := storage.NewContextRepository(m.db).SetAll(solveID, m.context); err != nil {
+			m.err = err
+		}
 
 		m.solveID = solveID
 		m.recording = true
+
+		// Counter reading at start, reconciled against recorded moves in diagnostics
+		if m.client != nil {
+			m.client.RequestOfflineStats()
+		}
 		m.startTime = time.Now()
 		m.moves = nil
 		m.currentPhase = "scramble"
 		m.detectedPhase = "complete" // Start assumes solved cube
 		m.solveStarted = false       // User must press SPACE after scrambling
 		m.inspecting = false         // Not yet in inspection
+		m.timer.Reset()
 		m.reportPath = ""            // Clear previous report path
+		m.summary = ""
+		m.unlocked = ""
+		m.newBests = ""
+		m.bookmarks = 0
 
 		// Reset tracker to solved state
 		if m.tracker != nil {
@@ -543,21 +868,183 @@ func (m *recordModel) endSolve() tea.Cmd {
 		}
 
 		m.recording = false
+		if m.client != nil {
+			m.client.RequestOfflineStats()
+		}
+		m.pacing.Stop()
 
 		// Generate report automatically
 		if m.solveID != "" {
-			reportDir, err := GenerateReportForSolve(m.db, m.solveID)
+			reportDir, err := GenerateReportAtLevel(m.db, m.solveID, ReportQuick)
 			if err != nil {
 				m.err = fmt.Errorf("report generation failed: %w", err)
 			} else {
 				m.reportPath = reportDir
 			}
 		}
+		m.summarizeSolve()
 
 		return nil
 	}
 }
 
+// summarizeSolve builds the summary line for the solve that just ended and
+// updates the achievements and personal bests.
+func (m *recordModel) summarizeSolve() {
+	if m.solveID == "" {
+		return
+	}
+	if earned, err := updateAchievements(m.db); err == nil {
+		m.unlocked = achievementNotice(earned)
+	}
+	if pbs, err := updatePersonalBests(m.db); err == nil {
+		m.newBests = personalBestNotice(pbs)
+	}
+	summary, err := solveSummaryLine(m.db, m.solveID, m.pacing.Budget)
+	if err != nil {
+		m.err = fmt.Errorf("solve summary failed: %w", err)
+		return
+	}
+	m.summary = summary
+}
+
+// continueSolve resumes an unfinished solve on the connected cube. A
+// device change is recorded if it is not the cube the solve was last
+// recorded on, and the tracker is resynced to the state the cube reports.
+func (m *recordModel) continueSolve(solveID string) {
+	if err := m.session.Resume(solveID); err != nil {
+		m.err = fmt.Errorf("failed to continue solve: %w", err)
+		return
+	}
+
+	solve, err := storage.NewSolveRepository(m.db).Get(solveID)
+	if err != nil {
+		m.err = err
+		return
+	}
+	records, err := storage.NewMoveRepository(m.db).GetBySolve(solveID)
+	if err != nil {
+		m.err = err
+		return
+	}
+	tracker, err := recorder.StateAt(m.db, solveID, len(records))
+	if err != nil {
+		m.err = err
+		return
+	}
+	marks, err := storage.NewPhaseRepository(m.db).GetPhaseMarks(solveID)
+	if err != nil {
+		m.err = err
+		return
+	}
+	bookmarks, err := recorder.LoadBookmarks(storage.NewEventRepository(m.db), solveID)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	m.solveID = solveID
+	m.recording = true
+	m.scramble = ""
+	if solve.ScrambleText != nil {
+		m.scramble = *solve.ScrambleText
+	}
+	m.moves = storage.ToMoves(records)
+	m.tracker = tracker
+	m.detectedPhase = tracker.Phase().String()
+	m.bookmarks = len(bookmarks)
+	m.startTime = solve.StartedAt
+	m.solveStarted = false
+	m.inspecting = false
+
+	// Pick up the workflow where the marks left it
+	firstPhase := "white_cross"
+	if m.scheme != nil {
+		firstPhase = m.scheme.Reset()
+	}
+	for _, mark := range marks {
+		m.currentPhase = mark.PhaseKey
+		switch mark.PhaseKey {
+		case "inspection":
+			m.inspecting = true
+		case firstPhase:
+			m.solveStarted = true
+			m.inspecting = false
+			m.startTime = solve.StartedAt.Add(time.Duration(mark.TsMs) * time.Millisecond)
+		}
+		if m.scheme != nil {
+			m.scheme.Mark(mark.PhaseKey)
+		}
+	}
+	if m.solveStarted {
+		m.highestPhase = tracker.Phase()
+		m.elapsed = time.Since(m.startTime)
+		m.pacing.EnterPhase(m.currentPhase, time.Now())
+	}
+
+	m.notice = fmt.Sprintf("Continuing solve %s after %d moves", solveID[:8], len(records))
+	if m.client != nil {
+		change, err := m.session.ChangeDevice(m.client.DeviceName(), m.client.DeviceUUID())
+		if err != nil {
+			m.err = err
+			return
+		}
+		if change != nil {
+			from := change.FromName
+			if from == "" {
+				from = "previous cube"
+			}
+			m.notice = fmt.Sprintf("Continuing solve %s from %s on %s after %d moves",
+				solveID[:8], from, change.ToName, change.MoveIndex)
+		}
+
+		// The new connection reports its own state; adopt it if it differs
+		m.resyncPending = true
+		m.client.RequestState()
+	}
+}
+
+// stopIdleSolve ends a solve that has had no moves for m.idleStop. The solve
+// is trimmed to its last move and annotated; no report is generated.
+func (m *recordModel) stopIdleSolve() {
+	idle := m.session.IdleFor()
+	if err := m.session.EndIdle(); err != nil {
+		m.err = err
+		return
+	}
+
+	m.recording = false
+	m.solveStarted = false
+	m.inspecting = false
+	m.pacing.Stop()
+	m.currentPhase = ""
+	m.notice = fmt.Sprintf("Solve auto-stopped after %s without moves (press s to start a new one)", idle.Round(time.Second))
+}
+
+// showHint sets the hint for the next step from the tracked cube state,
+// with the profile's solving method.
+func (m *recordModel) showHint() {
+	if m.tracker == nil || m.tracker.IsSolved() {
+		return
+	}
+	h, err := m.tracker.Hint(activeProfile.Method)
+	if err != nil {
+		m.hint = err.Error()
+		return
+	}
+	m.hint = h.Explanation
+	m.hintFrames = m.tracker.Animate(h.Moves)
+	m.hintAt = time.Now()
+}
+
+// hintFrame returns the frame of the hint animation to show now. The
+// animation loops, holding the result a little longer.
+func (m *recordModel) hintFrame() render.Frame {
+	n := len(m.hintFrames) + hintHoldFrames
+	i := int(time.Since(m.hintAt)/hintFrameInterval) % n
+	return m.hintFrames[min(i, len(m.hintFrames)-1)]
+}
+
 func (m *recordModel) markPhase(phase string) tea.Cmd {
 	return func() tea.Msg {
 		if err := m.session.MarkPhase(phase, nil); err != nil {
@@ -590,6 +1077,17 @@ func (m *recordModel) View() string {
 			status += fmt.Sprintf(" (Battery: %d%%)", m.battery)
 		}
 		b.WriteString(statusStyle.Render(status))
+		if m.client != nil && m.client.IsAsleep() {
+			quiet := time.Since(m.client.LastActivity()).Round(time.Second)
+			b.WriteString("\n")
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Cube not responding for %s - it may be asleep", quiet)))
+			b.WriteString("\n")
+			b.WriteString("Turn any face to wake it. If it stays silent, press 'q' and run again to reconnect.")
+		}
+	} else if m.linkLost {
+		b.WriteString(errorStyle.Render("Connection lost - press 'q' and run 'gocube solve record --continue' to pick the solve up again"))
+	} else if m.client != nil && m.client.IsReconnecting() {
+		b.WriteString(errorStyle.Render("Connection lost - reconnecting..."))
 	} else if len(m.scanResults) == 0 {
 		b.WriteString(errorStyle.Render("No device found - run again to retry"))
 	} else {
@@ -608,6 +1106,12 @@ func (m *recordModel) View() string {
 			if m.inspecting {
 				// After SPACE, waiting for first move
 				b.WriteString(fmt.Sprintf("State: %s - make first move to start timer\n", phaseStyle.Render("INSPECTION")))
+				if remaining := m.timer.InspectionRemaining(); remaining > 0 {
+					b.WriteString(fmt.Sprintf("Inspection: %ds\n", int(remaining.Seconds()+0.999)))
+				} else if p := m.timer.Penalty(); p != gocube.PenaltyNone {
+					b.WriteString(errorStyle.Render(fmt.Sprintf("Inspection over: %s", p)))
+					b.WriteString("\n")
+				}
 			} else if m.tracker != nil && m.tracker.IsSolved() {
 				// Still scrambling
 				b.WriteString(fmt.Sprintf("State: %s\n", phaseStyle.Render("SCRAMBLE THE CUBE")))
@@ -615,11 +1119,27 @@ func (m *recordModel) View() string {
 				// Cube is scrambled, ready for SPACE
 				b.WriteString(fmt.Sprintf("State: %s - press SPACE when ready\n", phaseStyle.Render("READY")))
 			}
+			if !m.inspecting && m.scramble != "" {
+				b.WriteString(fmt.Sprintf("Scramble: %s\n", moveStyle.Render(m.scramble)))
+				if p, ok := m.session.ScrambleProgress(); ok {
+					switch {
+					case p.Complete:
+						b.WriteString(fmt.Sprintf("Scramble check: %s\n", statusStyle.Render("verified")))
+					case p.OnTrack:
+						b.WriteString(fmt.Sprintf("Scramble check: %s\n", statusStyle.Render(p.String())))
+					default:
+						b.WriteString(errorStyle.Render("Scramble check: " + p.String()))
+						b.WriteString("\n")
+					}
+				}
+			}
 		} else {
 			// Solving - show current working phase (monotonic, never goes backwards)
 			if m.tracker != nil {
 				if m.tracker.IsSolved() {
 					b.WriteString(fmt.Sprintf("Cube State: %s\n", phaseStyle.Render("SOLVED!")))
+				} else if m.scheme != nil {
+					b.WriteString(fmt.Sprintf("Working on: %s\n", phaseStyle.Render(m.phaseName(m.scheme.Current()))))
 				} else {
 					// Show the NEXT phase to work on based on highest phase reached (monotonic)
 					workingOn := getNextPhase(m.highestPhase)
@@ -637,40 +1157,74 @@ func (m *recordModel) View() string {
 			b.WriteString("\n")
 			b.WriteString(statusStyle.Render("DEBUG - Cube State:"))
 			b.WriteString("\n")
-			b.WriteString(m.tracker.String())
+			b.WriteString(m.tracker.Render(m.cubeStyle))
 		}
 
 		// Show last completed phase (only if we've completed at least one phase)
 		if m.currentPhase != "" && m.currentPhase != "inspection" {
-			b.WriteString(fmt.Sprintf("Last completed: %s\n", statusStyle.Render(phaseDisplayName(m.currentPhase))))
+			b.WriteString(fmt.Sprintf("Last completed: %s\n", statusStyle.Render(m.phaseName(m.currentPhase))))
 		}
 
-		b.WriteString(fmt.Sprintf("Moves: %d\n", len(m.moves)))
-		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("Moves: %d%s\n", countMoves(m.moves), metricLabel()))
 
-		// Recent moves
-		if len(m.moves) > 0 {
-			b.WriteString("Moves: ")
-			start := 0
-			if len(m.moves) > 20 {
-				start = len(m.moves) - 20
-				b.WriteString("... ")
-			}
-			var notations []string
-			for i := start; i < len(m.moves); i++ {
-				notations = append(notations, m.moves[i].Notation())
-			}
-			b.WriteString(moveStyle.Render(strings.Join(notations, " ")))
+		if m.hint != "" {
+			b.WriteString(phaseStyle.Render("Hint: " + m.hint))
 			b.WriteString("\n")
+			if len(m.hintFrames) > 1 {
+				b.WriteString(m.hintFrame().Render(m.cubeStyle))
+			}
+		}
+
+		// Pacing against the current phase and super-phase budgets
+		if m.pacing.Enabled() {
+			var paces []string
+			if budget, ok := m.pacing.Budget(m.pacing.Phase()); ok {
+				paces = append(paces, fmt.Sprintf("%.1fs / %.1fs (%s)",
+					m.pacing.Elapsed(time.Now()).Seconds(), budget.Seconds(), m.phaseName(m.pacing.Phase())))
+			}
+			if super := m.pacing.SuperPhase(); super != "" {
+				if budget, ok := m.pacing.Budget(super); ok {
+					paces = append(paces, fmt.Sprintf("%.1fs / %.1fs (%s)",
+						m.pacing.SuperElapsed(time.Now()).Seconds(), budget.Seconds(), super))
+				}
+			}
+			if len(paces) > 0 {
+				pace := "Pace: " + strings.Join(paces, ", ")
+				switch m.pacing.Level() {
+				case recorder.CueOverBudget:
+					b.WriteString(errorStyle.Render(pace + " OVER BUDGET"))
+				case recorder.CueWarning:
+					b.WriteString(phaseStyle.Render(pace))
+				default:
+					b.WriteString(statusStyle.Render(pace))
+				}
+				b.WriteString("\n")
+			}
 		}
+		b.WriteString("\n")
+
+		// Recent moves
+		writeRecentMoves(&b, m.moves, m.mergeMoves)
 	} else {
 		if m.solveID != "" {
 			// Just finished
 			b.WriteString(fmt.Sprintf("Solve complete: %s\n", m.solveID))
+			if m.summary != "" {
+				b.WriteString(phaseStyle.Render(m.summary))
+				b.WriteString("\n")
+			}
+			if m.unlocked != "" {
+				b.WriteString(phaseStyle.Render(m.unlocked))
+				b.WriteString("\n")
+			}
+			if m.newBests != "" {
+				b.WriteString(phaseStyle.Render(m.newBests))
+				b.WriteString("\n")
+			}
 			b.WriteString(fmt.Sprintf("Duration: %s\n", m.formatElapsed()))
-			b.WriteString(fmt.Sprintf("Total moves: %d\n", len(m.moves)))
+			b.WriteString(fmt.Sprintf("Total moves: %d%s\n", countMoves(m.moves), metricLabel()))
 			if m.elapsed.Seconds() > 0 {
-				tps := float64(len(m.moves)) / m.elapsed.Seconds()
+				tps := float64(countMoves(m.moves)) / m.elapsed.Seconds()
 				b.WriteString(fmt.Sprintf("TPS: %.2f\n", tps))
 			}
 			if m.reportPath != "" {
@@ -684,6 +1238,39 @@ func (m *recordModel) View() string {
 		}
 	}
 
+	// State comparison panel
+	if m.stateCompare && m.tracker != nil {
+		b.WriteString("\n")
+		if m.deviceState == nil {
+			b.WriteString(statusStyle.Render("STATE COMPARE - waiting for device state..."))
+			b.WriteString("\n")
+		} else {
+			diffs := countStateDiffs(m.tracker, m.deviceState)
+			header := fmt.Sprintf("STATE COMPARE - %d facelet(s) differ (updated %.0fs ago)",
+				diffs, time.Since(m.deviceStateAt).Seconds())
+			if diffs > 0 && m.recording {
+				header += " - y=resync"
+			}
+			if diffs > 0 {
+				b.WriteString(errorStyle.Render(header))
+			} else {
+				b.WriteString(statusStyle.Render(header))
+			}
+			b.WriteString("\n")
+			b.WriteString(renderStateComparison(m.tracker, m.deviceState))
+		}
+	}
+
+	// Solve context
+	b.WriteString(m.renderContext())
+
+	// Reload notice
+	if m.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(statusStyle.Render(m.notice))
+		b.WriteString("\n")
+	}
+
 	// Error
 	if m.err != nil {
 		b.WriteString("\n")
@@ -694,12 +1281,16 @@ func (m *recordModel) View() string {
 	b.WriteString("\n")
 
 	// Help
-	help := "Keys: s=start  d=debug  q=quit"
+	help := "Keys: s=start  c=context  d=debug  v=compare  q=quit"
 	if m.recording {
 		if !m.solveStarted {
-			help = "Scramble cube, then SPACE=start solve | d=debug e=end q=quit"
+			help = "Scramble cube, then SPACE=start solve | b=bookmark c=context d=debug m=merge v=compare e=end q=quit"
 		} else {
-			help = "Phases: 1-7 | r=RHS l=LHS | d=debug e=end q=quit"
+			help = "Phases: 1-7 | r=RHS l=LHS | b=bookmark c=context d=debug m=merge v=compare e=end q=quit"
+			if m.scheme != nil {
+				help = fmt.Sprintf("Phases: 1-%d (%s) | b=bookmark c=context d=debug m=merge v=compare e=end q=quit",
+					len(m.scheme.Scheme().Phases), m.scheme.Scheme().Name)
+			}
 		}
 	}
 	b.WriteString(helpStyle.Render(help))
@@ -708,6 +1299,34 @@ func (m *recordModel) View() string {
 	return b.String()
 }
 
+// ledsOn reports whether backlight commands may be sent.
+func (m *recordModel) ledsOn() bool {
+	return m.client != nil && m.leds
+}
+
+// firePacingCue emits the LED (and optional bell) cue for the current
+// pacing level: slow flash when approaching budget, fast flash once over.
+func (m *recordModel) firePacingCue(now time.Time) {
+	prev := m.pacing.Level()
+	level, fire := m.pacing.Update(now)
+	if !fire {
+		return
+	}
+
+	if m.ledsOn() {
+		switch level {
+		case recorder.CueWarning:
+			m.client.SlowFlashBacklight()
+		case recorder.CueOverBudget:
+			m.client.FlashBacklight()
+		}
+	}
+
+	if level > prev && m.pacing.Bell() {
+		fmt.Fprint(os.Stderr, "\a")
+	}
+}
+
 func (m *recordModel) formatElapsed() string {
 	if m.elapsed < time.Minute {
 		return fmt.Sprintf("%.1fs", m.elapsed.Seconds())
@@ -721,6 +1340,37 @@ func phaseDisplayName(key string) string {
 	return storage.PhaseDisplayName(key)
 }
 
+// phaseName returns the display name of a phase, as the phase scheme names
+// it if one is in use.
+func (m *recordModel) phaseName(key string) string {
+	if m.scheme != nil {
+		if p, ok := m.scheme.Phase(key); ok {
+			return p.DisplayName()
+		}
+	}
+	return phaseDisplayName(key)
+}
+
+// setPhaseScheme follows the profile's phase scheme, if it names one, and
+// registers the scheme's phases so reports can name them. Solves record
+// the scheme as their "phase_scheme" context.
+func (m *recordModel) setPhaseScheme(cfg recorder.Config) error {
+	scheme, err := cfg.PhaseScheme(activeProfile.PhaseScheme)
+	if err != nil || scheme == nil {
+		return err
+	}
+	tracker, err := recorder.NewSchemeTracker(*scheme)
+	if err != nil {
+		return fmt.Errorf("phase scheme %s: %w", scheme.Name, err)
+	}
+	if err := scheme.RegisterPhaseDefs(m.db); err != nil {
+		return err
+	}
+	m.scheme = tracker
+	m.context = mergeContext(m.context, map[string]string{"phase_scheme": scheme.Name})
+	return nil
+}
+
 // getNextPhaseFromProgress returns the name of the next phase to work on based on progress
 func getNextPhaseFromProgress(progress gocube.Progress) string {
 	if !progress.WhiteCross {
@@ -781,6 +1431,15 @@ func runRecord(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load state: %w", err)
 	}
 
+	if recordContinue && !stateFile.HasActiveSolve() {
+		return fmt.Errorf("no unfinished solve to continue")
+	}
+
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		return err
+	}
+
 	// Pre-scan for GoCube devices BEFORE starting TUI
 	// Uses the same scanning logic as 'gocube status'
 	prescanClient, scanResults, err := ScanForGoCube()
@@ -798,17 +1457,38 @@ func runRecord(cmd *cobra.Command, args []string) error {
 		return nil // Exit without entering TUI
 	}
 
+	model := newRecordModel(db, stateFile, cfg, prescanClient, scanResults)
+	model.session.SetBatchSpread(recordBatchSpread)
+	if err := model.setPhaseScheme(cfg); err != nil {
+		return err
+	}
+	addConfiguredHooks(model.session, cfg, func(err error) {
+		select {
+		case model.linkChan <- hookFailedMsg{err: err}:
+		default:
+		}
+	})
+
 	// Check for existing active solve
 	if stateFile.HasActiveSolve() {
-		fmt.Printf("Resuming active solve: %s\n", stateFile.ActiveSolveID())
+		if recordContinue {
+			fmt.Printf("Continuing active solve: %s\n", stateFile.ActiveSolveID())
+			model.continueSolveID = stateFile.ActiveSolveID()
+		} else {
+			fmt.Printf("Unfinished solve %s: use --continue to carry it on\n", stateFile.ActiveSolveID())
+		}
 	}
 
-	model := newRecordModel(db, stateFile, prescanClient, scanResults)
+	if err := model.watchFiles(); err != nil {
+		return err
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
+	// Let hooks for the last solve finish before the process exits
+	model.session.WaitHooks()
 	return nil
 }
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+)
+
+// reloadPollInterval is how often the TUI checks watched files for changes.
+const reloadPollInterval = 2 * time.Second
+
+// loadAlgorithmLibrary loads and applies the algorithm library at path.
+// On error the previously active library is kept.
+func loadAlgorithmLibrary(path string) error {
+	tools, err := analysis.LoadAlgorithmLibrary(path)
+	if err != nil {
+		return err
+	}
+	analysis.SetCustomTools(tools)
+	return nil
+}
+
+// watchFiles loads the algorithm library and starts watching it and the
+// config file for changes.
+func (m *recordModel) watchFiles() error {
+	configPath, err := recorder.DefaultConfigPath()
+	if err != nil {
+		return err
+	}
+	algoPath, err := recorder.DefaultAlgorithmsPath()
+	if err != nil {
+		return err
+	}
+
+	if err := loadAlgorithmLibrary(algoPath); err != nil {
+		fmt.Printf("Warning: %v (using built-in algorithms)\n", err)
+	}
+
+	m.watchers = []*recorder.FileWatcher{
+		recorder.NewFileWatcher(configPath, func(path string) error {
+			cfg, err := recorder.LoadConfig(path)
+			if err != nil {
+				return err
+			}
+			m.pacing.SetConfig(cfg.Pacing)
+			m.pacing.SetSuperPhases(cfg.SuperPhases)
+			m.keepAlive = time.Duration(cfg.KeepAliveSeconds) * time.Second
+			m.idleStop = time.Duration(cfg.IdleStopMinutes) * time.Minute
+			m.mergeMoves = cfg.Display.MergeMoves
+			m.hintAfter = time.Duration(cfg.Display.HintAfterSeconds) * time.Second
+			m.cubeStyle = cubeRenderOptions(cfg.Display)
+			if m.client != nil && m.connected {
+				m.client.StartKeepAlive(m.keepAlive)
+			}
+			return nil
+		}),
+		recorder.NewFileWatcher(algoPath, loadAlgorithmLibrary),
+	}
+	return nil
+}
+
+// pollWatchers reloads any changed watched files and records the outcome.
+func (m *recordModel) pollWatchers() {
+	for _, w := range m.watchers {
+		reloaded, err := w.Poll()
+		name := filepath.Base(w.Path())
+		switch {
+		case err != nil:
+			m.notice = fmt.Sprintf("%s rejected, keeping previous: %v", name, err)
+		case reloaded:
+			m.notice = fmt.Sprintf("Reloaded %s at %s", name, time.Now().Format("15:04:05"))
+			if algoPath, err := recorder.DefaultAlgorithmsPath(); err == nil && w.Path() == algoPath {
+				m.notice += " - run 'gocube algorithms reanalyze' to update past solves"
+			}
+		}
+	}
+}
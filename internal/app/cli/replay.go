@@ -18,6 +18,7 @@ const (
 	LogEventBLEMessage LogEventType = "ble_message"
 	LogEventKeyPress   LogEventType = "key_press"
 	LogEventPhase      LogEventType = "phase_change"
+	LogEventBookmark   LogEventType = "bookmark"
 )
 
 // LogEvent represents a single logged event
@@ -155,6 +156,23 @@ func (l *SolveLogger) LogPhaseChange(phase string) {
 	l.writeJSON(event)
 }
 
+// LogBookmark logs a user bookmark
+func (l *SolveLogger) LogBookmark(note string) {
+	if !l.enabled || l.file == nil {
+		return
+	}
+
+	event := LogEvent{
+		Timestamp:   time.Now(),
+		ElapsedMs:   time.Since(l.startTime).Milliseconds(),
+		EventType:   LogEventBookmark,
+		Description: note,
+	}
+
+	l.log.Events = append(l.log.Events, event)
+	l.writeJSON(event)
+}
+
 func (l *SolveLogger) writeJSON(v interface{}) error {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -12,7 +12,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
 )
 
 var replayCmd = &cobra.Command{
@@ -27,7 +29,10 @@ Usage:
   gocube solve replay                    # List available logs
   gocube solve replay <log-file>         # Replay specific log
   gocube solve replay --speed 2.0        # Replay at 2x speed
-  gocube solve replay --step             # Step through events manually`,
+  gocube solve replay --step             # Step through events manually
+
+Press ] and [ during replay to jump to the next and previous bookmark, and m
+to toggle merged move display (R R shown as R2).`,
 	RunE: runReplay,
 }
 
@@ -70,6 +75,10 @@ func runReplay(cmd *cobra.Command, args []string) error {
 
 	// Create replay model
 	model := newReplayModel(log, replaySpeed, replayStep)
+	if cfg, err := recorder.LoadDefaultConfig(); err == nil {
+		model.mergeMoves = cfg.Display.MergeMoves
+		model.cubeStyle = cubeRenderOptions(cfg.Display)
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -131,11 +140,23 @@ type replayModel struct {
 	lastEventTime int64
 	quitting      bool
 	debugMode     bool
+	bookmarks     []int // event indexes of bookmarks
+	bookmarksSeen int   // bookmarks replayed so far
+	mergeMoves    bool  // show R R as R2
+	cubeStyle     render.Options // how the cube is drawn
 }
 
 func newReplayModel(log *SolveLog, speed float64, stepMode bool) *replayModel {
+	var bookmarks []int
+	for i, e := range log.Events {
+		if e.EventType == LogEventBookmark {
+			bookmarks = append(bookmarks, i)
+		}
+	}
+
 	return &replayModel{
 		log:          log,
+		bookmarks:    bookmarks,
 		speed:        speed,
 		stepMode:     stepMode,
 		paused:       stepMode, // Start paused in step mode
@@ -206,18 +227,32 @@ func (m *replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "r":
-			// Reset replay
-			m.eventIndex = 0
-			m.cube.Reset()
-			m.moves = nil
-			m.currentPhase = ""
-			m.highestPhase = gocube.PhaseScrambled
-			m.lastEventTime = 0
-			m.startTime = time.Now()
+			m.reset()
+
+		case "]":
+			// Jump to the next bookmark
+			for _, idx := range m.bookmarks {
+				if idx >= m.eventIndex {
+					m.seekToEvent(idx)
+					break
+				}
+			}
+
+		case "[":
+			// Jump to the previous bookmark (before the one just replayed)
+			for i := len(m.bookmarks) - 1; i >= 0; i-- {
+				if m.bookmarks[i] < m.eventIndex-1 {
+					m.seekToEvent(m.bookmarks[i])
+					break
+				}
+			}
 
 		case "d":
 			m.debugMode = !m.debugMode
 
+		case "m":
+			m.mergeMoves = !m.mergeMoves
+
 		case "+", "=":
 			m.speed *= 2
 			if m.speed > 16 {
@@ -242,6 +277,31 @@ func (m *replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// reset rewinds the replay to the first event.
+func (m *replayModel) reset() {
+	m.eventIndex = 0
+	m.cube.Reset()
+	m.moves = nil
+	m.currentPhase = ""
+	m.highestPhase = gocube.PhaseScrambled
+	m.lastEventTime = 0
+	m.bookmarksSeen = 0
+	m.startTime = time.Now()
+}
+
+// seekToEvent replays instantly up to and including event target, then
+// pauses so the moment can be inspected.
+func (m *replayModel) seekToEvent(target int) {
+	if target < m.eventIndex {
+		m.reset()
+	}
+	for m.eventIndex <= target && m.eventIndex < len(m.log.Events) {
+		m.processEvent(m.log.Events[m.eventIndex])
+		m.eventIndex++
+	}
+	m.paused = true
+}
+
 func (m *replayModel) processEvent(event LogEvent) {
 	m.lastEventTime = event.ElapsedMs
 	m.elapsed = time.Duration(event.ElapsedMs) * time.Millisecond
@@ -271,6 +331,9 @@ func (m *replayModel) processEvent(event LogEvent) {
 
 	case LogEventPhase:
 		// Phase changes are recorded but we recalculate them
+
+	case LogEventBookmark:
+		m.bookmarksSeen++
 	}
 }
 
@@ -317,30 +380,20 @@ func (m *replayModel) View() string {
 
 	// Move count
 	b.WriteString(fmt.Sprintf("Moves: %d\n", len(m.moves)))
+	if len(m.bookmarks) > 0 {
+		b.WriteString(fmt.Sprintf("Bookmarks: %d/%d\n", m.bookmarksSeen, len(m.bookmarks)))
+	}
 	b.WriteString("\n")
 
 	// Recent moves
-	if len(m.moves) > 0 {
-		b.WriteString("Moves: ")
-		start := 0
-		if len(m.moves) > 20 {
-			start = len(m.moves) - 20
-			b.WriteString("... ")
-		}
-		var notations []string
-		for i := start; i < len(m.moves); i++ {
-			notations = append(notations, m.moves[i].Notation())
-		}
-		b.WriteString(moveStyle.Render(strings.Join(notations, " ")))
-		b.WriteString("\n")
-	}
+	writeRecentMoves(&b, m.moves, m.mergeMoves)
 
 	// Debug mode: show cube state
 	if m.debugMode && m.cube != nil {
 		b.WriteString("\n")
 		b.WriteString(statusStyle.Render("DEBUG - Cube State:"))
 		b.WriteString("\n")
-		b.WriteString(m.cube.String())
+		b.WriteString(m.cube.Render(m.cubeStyle))
 	}
 
 	// Current event info
@@ -354,9 +407,9 @@ func (m *replayModel) View() string {
 	b.WriteString("\n")
 
 	// Help
-	help := "SPACE/n=next  p=pause  r=reset  d=debug  +/-=speed  q=quit"
+	help := "SPACE/n=next  p=pause  r=reset  [/]=bookmark  d=debug  m=merge  +/-=speed  q=quit"
 	if m.stepMode {
-		help = "SPACE/n=next event  r=reset  d=debug  q=quit"
+		help = "SPACE/n=next event  r=reset  [/]=bookmark  d=debug  m=merge  q=quit"
 	}
 	b.WriteString(helpStyle.Render(help))
 	b.WriteString("\n")
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/SeamusWaldron/gocube_ble_library"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 )
 
@@ -19,7 +21,14 @@ var (
 	reportSolveID   string
 	reportLast      bool
 	reportOutputDir string
+	reportByEffect  bool
+	reportBenchmark bool
+	reportLevel     string
 	trendWindow     int
+	trendContext    map[string]string
+	trendTable      tableOptions
+	trendCompare    bool
+	trendSession    string
 )
 
 var reportCmd = &cobra.Command{
@@ -39,16 +48,41 @@ Reports include:
   - moves.json: Detailed move data
   - repetition_report.json: Cancellations, merges, patterns
   - ngram_report.json: Repeated move sequences (n=4-14)
+    (with --by-effect, sequences are grouped by net cube transformation)
   - final_phase_report.json: Tool detection for bottom_orient phase
-  - phase_moves/: Per-phase move sequences`,
+  - reconstruction.txt/.json: Moves line by line with algorithms labeled
+  - mirrored_algorithms.json: OLLs and PLLs executed mirrored or inverted,
+    undone and then corrected
+  - phase_moves/: Per-phase move sequences
+
+--level picks how much is computed:
+  quick     solve_summary.json only
+  standard  everything above (default)
+  deep      also deep_report.json: the solver's solution of the scramble
+            and, per phase, the shortest line with the same effect on the
+            cube (slow)
+
+The level is recorded in report_meta.json. Generating again into the same
+directory only computes what is missing, so --level deep after a standard
+report adds the deep analysis without redoing the rest.`,
 	RunE: runReportSolve,
 }
 
 var reportTrendCmd = &cobra.Command{
-	Use:   "trend",
+	Use:   "trend [--compare CURRENT BASELINE]",
 	Short: "Generate a trend report",
-	Long:  `Generate a trend report across recent solves with improvement metrics.`,
-	RunE:  runReportTrend,
+	Long: `Generate a trend report across recent solves with improvement metrics.
+
+With --compare, diff two time periods instead: averages, ao5/ao12, phase
+splits and pauses side by side, with a significance hint for each change.
+Periods are "last 30d", "prior 30d" (the 30 days before that), weeks
+("last 4w") or date ranges (2026-01-01..2026-01-31):
+
+  gocube report trend --compare "last 30d" "prior 30d"
+
+With --session, analyze the solves of a practice session (see "gocube
+session") instead of the most recent ones; "current" is the open session.`,
+	RunE: runReportTrend,
 }
 
 func init() {
@@ -58,10 +92,18 @@ func init() {
 	reportSolveCmd.Flags().StringVar(&reportSolveID, "id", "", "Solve ID to report")
 	reportSolveCmd.Flags().BoolVar(&reportLast, "last", false, "Report on the last solve")
 	reportSolveCmd.Flags().StringVarP(&reportOutputDir, "output", "o", "", "Output directory (default: ./reports/<solve_id>)")
+	reportSolveCmd.Flags().BoolVar(&reportByEffect, "by-effect", false, "Group patterns by net cube transformation instead of literal moves")
+	reportSolveCmd.Flags().BoolVar(&reportBenchmark, "benchmarks", false, "Compare phase splits against bundled reference data")
+	reportSolveCmd.Flags().StringVar(&reportLevel, "level", string(ReportStandard), "Analysis level (quick, standard, deep)")
 
 	reportCmd.AddCommand(reportTrendCmd)
 	reportTrendCmd.Flags().IntVar(&trendWindow, "window", 50, "Number of recent solves to analyze")
+	reportTrendCmd.Flags().StringToStringVar(&trendContext, "context", nil, "Only include solves with this context (e.g. lube=fresh)")
+	reportTrendCmd.Flags().BoolVar(&reportBenchmark, "benchmarks", false, "Compare phase averages against bundled reference data")
 	reportTrendCmd.Flags().StringVarP(&reportOutputDir, "output", "o", "", "Output directory")
+	reportTrendCmd.Flags().BoolVar(&trendCompare, "compare", false, "Compare two periods given as arguments")
+	reportTrendCmd.Flags().StringVar(&trendSession, "session", "", "Analyze the solves of this session (an ID, or \"current\") instead of --window")
+	addTableFlags(reportTrendCmd, &trendTable, "")
 }
 
 // FullSolveSummary is the JSON structure for solve_summary.json
@@ -72,15 +114,21 @@ type FullSolveSummary struct {
 	SolveDurationMs     int64                  `json:"solve_duration_ms"`      // Actual solve time (excludes scramble/inspection)
 	SessionDurationMs   int64                  `json:"session_duration_ms"`    // Total session time
 	SolveMoves          int                    `json:"solve_moves"`            // Moves during solve (excludes scramble)
+	Metric              gocube.Metric          `json:"metric"`                 // Turn metric of the move counts and TPS
 	TotalMoves          int                    `json:"total_moves"`            // All moves including scramble
 	OptimizedMoves      int                    `json:"optimized_moves"`
 	Efficiency          float64                `json:"efficiency"`
 	TPSOverall          float64                `json:"tps_overall"`
 	PhaseStats          []PhaseStatsReport     `json:"phase_stats,omitempty"`
+	SuperPhaseStats     []PhaseStatsReport     `json:"super_phase_stats,omitempty"`
 	LongestPauseMs      int64                  `json:"longest_pause_ms"`
 	PauseCountOver1500  int                    `json:"pause_count_over_1500ms"`
 	AvgMoveDurationMs   float64                `json:"avg_move_duration_ms"`
 	MovementProfile     *analysis.MovementProfile `json:"movement_profile,omitempty"`
+	SetDowns            []analysis.SetDown     `json:"set_downs,omitempty"`           // Intervals the cube was put down mid-solve
+	SetDownMs           int64                  `json:"set_down_ms,omitempty"`         // Total of SetDowns
+	FocusedDurationMs   int64                  `json:"focused_duration_ms,omitempty"` // Solve time less SetDowns
+	LastLayer           *analysis.LastLayerReport `json:"last_layer,omitempty"`          // OLL and PLL cases and splits
 	Notes               string                 `json:"notes,omitempty"`
 }
 
@@ -93,17 +141,21 @@ type PhaseStatsReport struct {
 	DurationMs  int64   `json:"duration_ms"`
 	MoveCount   int     `json:"move_count"`
 	TPS         float64 `json:"tps"`
+
+	Optimal *analysis.PhaseOptimality `json:"optimal,omitempty"` // White cross only
 }
 
 // PlaybackEvent is a single event in the playback timeline
 type PlaybackEvent struct {
-	TsMs      int64  `json:"ts_ms"`                  // Milliseconds since solve start
-	Type      string `json:"type"`                   // "move" or "orientation"
-	Face      string `json:"face,omitempty"`         // For moves: R, L, U, D, F, B
-	Turn      int    `json:"turn,omitempty"`         // For moves: 1, -1, 2
-	Notation  string `json:"notation,omitempty"`     // For moves: R, R', R2, etc.
-	UpFace    string `json:"up_face,omitempty"`      // For orientation: which face is up
-	FrontFace string `json:"front_face,omitempty"`   // For orientation: which face is front
+	TsMs       int64  `json:"ts_ms"`                 // Milliseconds since solve start
+	TsUs       int64  `json:"ts_us"`                 // Microseconds since solve start
+	Type       string `json:"type"`                  // "move" or "orientation"
+	Face       string `json:"face,omitempty"`        // For moves: R, L, U, D, F, B
+	Turn       int    `json:"turn,omitempty"`        // For moves: 1, -1, 2
+	Notation   string `json:"notation,omitempty"`    // For moves: R, R', R2, etc.
+	TimeSource string `json:"time_source,omitempty"` // For moves: received, device or interpolated
+	UpFace     string `json:"up_face,omitempty"`     // For orientation: which face is up
+	FrontFace  string `json:"front_face,omitempty"`  // For orientation: which face is front
 }
 
 // PlaybackData contains all data needed for visualization playback
@@ -113,6 +165,8 @@ type PlaybackData struct {
 	TotalMoves    int                    `json:"total_moves"`
 	TotalOrients  int                    `json:"total_orientations"`
 	Phases        []PhaseStatsReport     `json:"phases,omitempty"`
+	Bookmarks     []recorder.Bookmark    `json:"bookmarks,omitempty"`
+	Anchors       []PlaybackAnchor       `json:"anchors,omitempty"` // Wall-clock anchors for video sync
 	Timeline      []PlaybackEvent        `json:"timeline"`
 }
 
@@ -126,12 +180,33 @@ type PhaseAnalysis struct {
 	Moves       string                     `json:"moves"`
 	Repetitions *analysis.RepetitionReport `json:"repetitions,omitempty"`
 	TopPatterns []analysis.NGram           `json:"top_patterns,omitempty"`
+	ShorterLine *analysis.LineSuggestion   `json:"shorter_line,omitempty"`
+}
+
+// mineNGrams mines repeated sequences, grouping by transformation
+// fingerprint when --by-effect is set.
+func mineNGrams(moves []gocube.Move, minN, maxN, topK int) *analysis.NGramReport {
+	if reportByEffect {
+		return analysis.MineEffectNGrams(moves, minN, maxN, topK)
+	}
+	return analysis.MineNGrams(moves, minN, maxN, topK)
 }
 
 func runReportSolve(cmd *cobra.Command, args []string) error {
 	if reportSolveID == "" && !reportLast {
 		return fmt.Errorf("specify --id or --last")
 	}
+	level, err := parseReportLevel(reportLevel)
+	if err != nil {
+		return err
+	}
+
+	// Include the user's algorithm library in tool detection
+	if algoPath, err := recorder.DefaultAlgorithmsPath(); err == nil {
+		if err := loadAlgorithmLibrary(algoPath); err != nil {
+			fmt.Printf("Warning: %v (using built-in algorithms)\n", err)
+		}
+	}
 
 	// Open database
 	db, err := openDB()
@@ -147,7 +222,14 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	orientRepo := storage.NewOrientationRepository(db)
 
 	var solve *storage.Solve
-	if reportLast {
+	if reportLast && reportDevice != "" {
+		var solves []storage.Solve
+		if solves, err = solveRepo.List(-1); err == nil {
+			if solves = filterSolvesByDevice(solves, reportDevice); len(solves) > 0 {
+				solve = &solves[0]
+			}
+		}
+	} else if reportLast {
 		solve, err = solveRepo.GetLast()
 	} else {
 		solve, err = solveRepo.Get(reportSolveID)
@@ -174,6 +256,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		segments = nil
 	}
+	segments = metricSegments(segments, moveRecords)
 
 	// Get phase defs for display names
 	phaseDefs, _ := phaseRepo.GetAllPhaseDefs()
@@ -185,14 +268,13 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	// Determine output directory
 	outputDir := reportOutputDir
 	if outputDir == "" {
-		// Use date-time format for directory name: YYYY-MM-DD_HHMMSS
-		dirName := solve.StartedAt.Format("2006-01-02_150405")
-		outputDir = filepath.Join("reports", dirName)
+		outputDir = solveReportDir(solve)
 	}
 
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
+	existing := existingReportLevel(outputDir, solve.SolveID)
 
 	// Run all analyses
 	fmt.Println("Analyzing solve...")
@@ -204,20 +286,13 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 
 	// 2. Optimization analysis
 	optimized := analysis.OptimizeMoves(moves)
-	efficiency := analysis.CalculateEfficiency(moves, optimized)
+	efficiency := metricEfficiency(moves, optimized)
 
 	// 3. Movement profile
 	profile := analysis.AnalyzeMovementProfile(moves)
 
 	// Calculate actual solve time (excluding scramble and inspection)
-	var solveDurationMs int64
-	var solveMoves int
-	for _, seg := range segments {
-		if seg.PhaseKey != "scramble" && seg.PhaseKey != "inspection" {
-			solveDurationMs += seg.DurationMs
-			solveMoves += seg.MoveCount
-		}
-	}
+	solveDurationMs, solveMoves := solvingTime(segments)
 
 	// Build summary
 	summary := FullSolveSummary{
@@ -225,8 +300,9 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 		StartedAt:          solve.StartedAt.Format(time.RFC3339),
 		SolveDurationMs:    solveDurationMs,
 		SolveMoves:         solveMoves,
-		TotalMoves:         len(moves),
-		OptimizedMoves:     len(optimized),
+		Metric:             turnMetric(),
+		TotalMoves:         countMoves(moves),
+		OptimizedMoves:     countMoves(optimized),
 		Efficiency:         efficiency,
 		LongestPauseMs:     longestPause,
 		PauseCountOver1500: pauseCount,
@@ -265,14 +341,35 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 			DurationMs:  seg.DurationMs,
 			MoveCount:   seg.MoveCount,
 			TPS:         seg.TPS,
+			Optimal:     phaseOptimality(seg, moveRecords),
 		})
 	}
+	summary.SuperPhaseStats = superPhaseStats(segments, loadSuperPhases())
+	summary.LastLayer = lastLayerReport(moveRecords, segments)
+	setDownCfg := loadSetDownConfig()
+	applySetDowns(&summary, detectSetDowns(storage.NewEventRepository(db), solve.SolveID, moveRecords, segments, setDownCfg), setDownCfg)
 
 	// Write solve_summary.json
 	if err := writeJSON(filepath.Join(outputDir, "solve_summary.json"), summary); err != nil {
 		return err
 	}
 
+	if !needsTier(ReportStandard, level, existing) {
+		fmt.Println()
+		fmt.Printf("Solve: %s\n", localTime(solve.StartedAt).Format("2006-01-02 15:04:05"))
+		fmt.Printf("Report generated: %s (level %s)\n", outputDir, level)
+		if existing.rank() >= ReportStandard.rank() {
+			fmt.Printf("Standard analyses already present (level %s)\n", existing)
+		}
+		fmt.Println()
+		fmt.Println("Summary:")
+		printSolveTime(summary)
+		fmt.Printf("  Moves%s: %d (optimized: %d, efficiency: %.1f%%)\n",
+			metricLabel(), solveMoves, summary.OptimizedMoves, efficiency*100)
+		fmt.Printf("  TPS: %.2f\n", summary.TPSOverall)
+		return finishSolveReport(outputDir, solve, moveRecords, segments, phaseDefMap, level, existing, true)
+	}
+
 	// Write moves.txt
 	var notations []string
 	for _, m := range moves {
@@ -291,20 +388,24 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 
 	// Write moves.json
 	type MoveJSON struct {
-		MoveIndex int    `json:"move_index"`
-		TsMs      int64  `json:"ts_ms"`
-		Face      string `json:"face"`
-		Turn      int    `json:"turn"`
-		Notation  string `json:"notation"`
+		MoveIndex  int    `json:"move_index"`
+		TsMs       int64  `json:"ts_ms"`
+		TsUs       int64  `json:"ts_us"`
+		Face       string `json:"face"`
+		Turn       int    `json:"turn"`
+		Notation   string `json:"notation"`
+		TimeSource string `json:"time_source"` // received, device or interpolated
 	}
 	var movesJSON []MoveJSON
 	for i, m := range moves {
 		movesJSON = append(movesJSON, MoveJSON{
-			MoveIndex: i,
-			TsMs:      m.Time.UnixMilli(),
-			Face:      string(m.Face),
-			Turn:      int(m.Turn),
-			Notation:  m.Notation(),
+			MoveIndex:  i,
+			TsMs:       m.Time.UnixMilli(),
+			TsUs:       m.Time.UnixMicro(),
+			Face:       string(m.Face),
+			Turn:       int(m.Turn),
+			Notation:   m.Notation(),
+			TimeSource: m.TimeSource.String(),
 		})
 	}
 	if err := writeJSON(filepath.Join(outputDir, "moves.json"), movesJSON); err != nil {
@@ -314,17 +415,21 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	// Write playback.json - combined timeline of moves and orientations for visualization
 	fmt.Println("  - Generating playback data...")
 	orientations, _ := orientRepo.GetBySolve(solve.SolveID)
+	bookmarks, _ := recorder.LoadBookmarks(storage.NewEventRepository(db), solve.SolveID)
+	audioAlign, _ := recorder.LoadAudioAlignment(storage.NewEventRepository(db), solve.SolveID)
 
 	var timeline []PlaybackEvent
 
 	// Add all moves to timeline
 	for _, m := range moveRecords {
 		timeline = append(timeline, PlaybackEvent{
-			TsMs:     m.TsMs,
-			Type:     "move",
-			Face:     m.Face,
-			Turn:     m.Turn,
-			Notation: m.Notation,
+			TsMs:       m.TsMs,
+			TsUs:       m.TsUs,
+			Type:       "move",
+			Face:       m.Face,
+			Turn:       m.Turn,
+			Notation:   m.Notation,
+			TimeSource: m.TimeSource,
 		})
 	}
 
@@ -332,6 +437,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	for _, o := range orientations {
 		timeline = append(timeline, PlaybackEvent{
 			TsMs:      o.TsMs,
+			TsUs:      o.TsUs,
 			Type:      "orientation",
 			UpFace:    o.UpFace,
 			FrontFace: o.FrontFace,
@@ -340,7 +446,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 
 	// Sort timeline by timestamp
 	sort.Slice(timeline, func(i, j int) bool {
-		return timeline[i].TsMs < timeline[j].TsMs
+		return timeline[i].TsUs < timeline[j].TsUs
 	})
 
 	// Build playback data
@@ -348,6 +454,8 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 		SolveID:      solve.SolveID,
 		TotalMoves:   len(moveRecords),
 		TotalOrients: len(orientations),
+		Bookmarks:    bookmarks,
+		Anchors:      playbackAnchors(db, solve),
 		Timeline:     timeline,
 	}
 
@@ -385,7 +493,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 
 	// 5. N-gram mining
 	fmt.Println("  - Mining n-grams...")
-	ngramReport := analysis.MineNGrams(moves, 4, 14, 50)
+	ngramReport := mineNGrams(moves, 4, 14, 50)
 	if err := writeJSON(filepath.Join(outputDir, "ngram_report.json"), ngramReport); err != nil {
 		return err
 	}
@@ -407,6 +515,19 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 		if err := writeJSON(filepath.Join(outputDir, "final_phase_report.json"), finalReport); err != nil {
 			return err
 		}
+		if err := storage.NewToolDetectionRepository(db).Replace(solve.SolveID, finalReport.ToolCounts); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("  - Annotating reconstruction...")
+	reconstruction := buildReconstruction(moveRecords, segments)
+	if err := writeReconstruction(outputDir, reconstruction); err != nil {
+		return err
+	}
+	mirrored := findMirroredAlgorithms(moveRecords, segments)
+	if err := writeJSON(filepath.Join(outputDir, "mirrored_algorithms.json"), mirrored); err != nil {
+		return err
 	}
 
 	// Write phase_moves directory and per-phase analysis
@@ -444,7 +565,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 			pa := PhaseAnalysis{
 				PhaseKey:    seg.PhaseKey,
 				DisplayName: displayName,
-				MoveCount:   len(phaseMoves),
+				MoveCount:   countMoves(phaseMoves),
 				DurationMs:  seg.DurationMs,
 				TPS:         seg.TPS,
 				Moves:       phaseText,
@@ -453,11 +574,12 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 			// Analyze repetitions in this phase
 			if len(phaseMoves) > 0 {
 				pa.Repetitions = analysis.AnalyzeRepetitions(phaseMoves)
+				pa.ShorterLine = metricLine(analysis.SuggestShorterLine(phaseMoves), phaseMoves)
 			}
 
 			// Mine n-grams for patterns (4-8 move sequences)
 			if len(phaseMoves) >= 4 {
-				phaseNgrams := analysis.MineNGrams(phaseMoves, 4, 8, 10)
+				phaseNgrams := mineNGrams(phaseMoves, 4, 8, 10)
 				// Collect top patterns across all n values
 				var topPatterns []analysis.NGram
 				for n := 4; n <= 8; n++ {
@@ -485,6 +607,7 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	fmt.Println("  - Generating diagnostics...")
 	diagnostics, err := analysis.AnalyzeDiagnostics(solve.SolveID, moveRepo, phaseRepo, orientRepo)
 	if err == nil {
+		diagnostics.MoveCounter, _ = analysis.ReconcileMoveCounter(solve.SolveID, storage.NewCounterRepository(db), moveRepo)
 		if err := writeJSON(filepath.Join(outputDir, "diagnostics.json"), diagnostics); err != nil {
 			return err
 		}
@@ -493,15 +616,16 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	// 8. Generate interactive visualizer HTML with full report data
 	fmt.Println("  - Generating visualizer...")
 	vizReport := buildVisualizerReport(
-		solveDurationMs, solveMoves, len(moves), len(optimized), efficiency, summary.TPSOverall,
+		solveDurationMs, solveMoves, summary.TotalMoves, summary.OptimizedMoves, efficiency, summary.TPSOverall,
 		longestPause, repReport, phaseAnalyses, diagnostics, phaseDefMap,
 	)
-	if err := generateVisualizerHTML(outputDir, solve, moveRecords, segments, orientations, phaseDefMap, vizReport); err != nil {
+	vizReport.Reconstruction = reconstruction.Lines
+	if err := generateVisualizerHTML(outputDir, solve, moveRecords, segments, orientations, bookmarks, audioAlign, phaseDefMap, vizReport); err != nil {
 		return fmt.Errorf("generating visualizer: %w", err)
 	}
 
 	fmt.Println()
-	fmt.Printf("Solve: %s\n", solve.StartedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Solve: %s\n", localTime(solve.StartedAt).Format("2006-01-02 15:04:05"))
 	fmt.Printf("Report generated: %s\n", outputDir)
 	fmt.Println()
 	fmt.Println("Files created:")
@@ -515,6 +639,9 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 	if len(finalPhaseMoves) > 0 {
 		fmt.Println("  - final_phase_report.json")
 	}
+	fmt.Println("  - reconstruction.txt")
+	fmt.Println("  - reconstruction.json")
+	fmt.Println("  - mirrored_algorithms.json")
 	if len(segments) > 0 {
 		fmt.Println("  - phase_moves/")
 		fmt.Println("  - phase_analysis.json")
@@ -524,13 +651,39 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 
 	// Print summary stats
 	fmt.Println("Summary:")
-	fmt.Printf("  Solve time: %.1fs\n", float64(solveDurationMs)/1000.0)
-	fmt.Printf("  Moves: %d (optimized: %d, efficiency: %.1f%%)\n",
-		solveMoves, len(optimized), efficiency*100)
+	printSolveTime(summary)
+	fmt.Printf("  Moves%s: %d (optimized: %d, efficiency: %.1f%%)\n",
+		metricLabel(), solveMoves, summary.OptimizedMoves, efficiency*100)
 	fmt.Printf("  TPS: %.2f\n", summary.TPSOverall)
 	fmt.Printf("  Longest pause: %dms\n", longestPause)
 	fmt.Printf("  Immediate cancellations: %d\n", len(repReport.ImmediateCancellations))
 	fmt.Printf("  Merge opportunities: %d\n", len(repReport.MergeOpportunities))
+	for _, m := range mirrored {
+		how := "mirrored"
+		if m.Kind == analysis.MistakeInverse {
+			how = "inverted"
+		}
+		fmt.Printf("  %s started %s (%s), undone and corrected: %d moves\n", m.Case, how, m.Attempt, m.Moves)
+	}
+	for _, ps := range summary.PhaseStats {
+		if ps.Optimal != nil {
+			fmt.Printf("  %s: %d moves, optimal %d (%.0f%%): %s\n", ps.DisplayName,
+				ps.MoveCount, ps.Optimal.OptimalMoves, ps.Optimal.Efficiency*100, ps.Optimal.OptimalLine)
+		}
+	}
+	if ll := summary.LastLayer; ll != nil {
+		printLastLayerSplit("OLL", ll.OLL)
+		printLastLayerSplit("PLL", ll.PLL)
+	}
+
+	if len(summary.SuperPhaseStats) > 0 {
+		fmt.Println()
+		fmt.Println("Super-phases:")
+		for _, sp := range summary.SuperPhaseStats {
+			fmt.Printf("  %s: %.1fs, %d moves, %.2f TPS\n",
+				sp.DisplayName, float64(sp.DurationMs)/1000.0, sp.MoveCount, sp.TPS)
+		}
+	}
 
 	// Show per-phase analysis
 	if len(phaseAnalyses) > 0 {
@@ -546,6 +699,11 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 					fmt.Printf("    Cancellations: %d\n", len(pa.Repetitions.ImmediateCancellations))
 				}
 			}
+			if pa.ShorterLine != nil {
+				fmt.Printf("    Shorter line: you did %d, this line does %d\n",
+					pa.ShorterLine.OriginalMoves, pa.ShorterLine.OptimizedMoves)
+				fmt.Printf("      %s\n", pa.ShorterLine.Moves)
+			}
 
 			if len(pa.TopPatterns) > 0 {
 				fmt.Println("    Repeated patterns:")
@@ -570,6 +728,9 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 				break
 			}
 			fmt.Printf("  %dx: %v\n", ng.Count, ng.Sequence)
+			if len(ng.Variants) > 1 {
+				fmt.Printf("       (%d variants, e.g. %s)\n", len(ng.Variants), ng.Variants[0])
+			}
 		}
 	}
 
@@ -621,6 +782,18 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		// Show cube move counter reconciliation (experimental)
+		if mc := diagnostics.MoveCounter; mc != nil {
+			fmt.Println()
+			fmt.Println("Cube Move Counter (experimental):")
+			fmt.Printf("  Counted: %d, recorded: %d\n", mc.CounterDelta, mc.RecordedMoves)
+			if mc.CounterReset {
+				fmt.Println("  Counter went backwards; cube was likely reset")
+			} else if mc.SuspectedDrops {
+				fmt.Printf("  WARNING: %d moves counted by the cube were not received (dropped frames?)\n", mc.MissingMoves)
+			}
+		}
+
 		// Show orientation diagnostics
 		if diagnostics.Orientation.TotalChanges > 0 {
 			fmt.Println()
@@ -638,59 +811,73 @@ func runReportSolve(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	return nil
-}
-
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+	// Place phase splits against static reference data
+	if reportBenchmark {
+		phaseDurations := make(map[string]int64)
+		for _, seg := range segments {
+			phaseDurations[seg.PhaseKey] += seg.DurationMs
+		}
+		avgMs := recentAverageMs(solveRepo, 12)
+		if avgMs <= 0 {
+			avgMs = solveDurationMs
+		}
+		benchmarks, err := analysis.CompareToBenchmarks(avgMs, phaseDurations)
+		if err != nil {
+			fmt.Printf("Warning: benchmarks unavailable: %v\n", err)
+		} else {
+			if err := writeJSON(filepath.Join(outputDir, "benchmark_report.json"), benchmarks); err != nil {
+				return err
+			}
+			printBenchmarks(benchmarks)
+		}
 	}
-	return s[:maxLen-3] + "..."
-}
 
-// GenerateReportForSolve generates a full report for a solve and returns the output directory.
-// This can be called from both CLI commands and the TUI.
-func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
-	solveRepo := storage.NewSolveRepository(db)
-	moveRepo := storage.NewMoveRepository(db)
-	phaseRepo := storage.NewPhaseRepository(db)
-	orientRepo := storage.NewOrientationRepository(db)
+	return finishSolveReport(outputDir, solve, moveRecords, segments, phaseDefMap, level, existing, true)
+}
 
-	solve, err := solveRepo.Get(solveID)
+// recentAverageMs returns the mean duration of the most recent completed
+// solves, or 0 if there are none.
+func recentAverageMs(solveRepo *storage.SolveRepository, limit int) int64 {
+	solves, err := solveRepo.List(limit)
 	if err != nil {
-		return "", fmt.Errorf("failed to get solve: %w", err)
+		return 0
 	}
-	if solve == nil {
-		return "", fmt.Errorf("solve not found")
+	var total int64
+	count := 0
+	for _, s := range solves {
+		if s.DurationMs != nil && *s.DurationMs > 0 {
+			total += *s.DurationMs
+			count++
+		}
 	}
-
-	// Get moves
-	moveRecords, err := moveRepo.GetBySolve(solve.SolveID)
-	if err != nil {
-		return "", fmt.Errorf("failed to get moves: %w", err)
+	if count == 0 {
+		return 0
 	}
+	return total / int64(count)
+}
 
-	moves := storage.ToMoves(moveRecords)
-
-	// Get phase segments
-	segments, err := phaseRepo.GetPhaseSegments(solve.SolveID)
-	if err != nil {
-		segments = nil
+// printBenchmarks prints a benchmark report, labeled as reference data.
+func printBenchmarks(b *analysis.BenchmarkReport) {
+	fmt.Println()
+	fmt.Printf("Benchmarks vs %s solvers (your average: %.1fs, static reference data):\n", b.BracketLabel, float64(b.AvgMs)/1000.0)
+	for _, pb := range b.Phases {
+		fmt.Printf("  %s (%.1fs, median %.1fs)\n",
+			pb.Summary, float64(pb.DurationMs)/1000.0, float64(pb.MedianMs)/1000.0)
 	}
+	fmt.Printf("  Note: %s\n", b.Note)
+}
 
-	// Get phase defs for display names
-	phaseDefs, _ := phaseRepo.GetAllPhaseDefs()
-	phaseDefMap := make(map[string]string)
-	for _, pd := range phaseDefs {
-		phaseDefMap[pd.PhaseKey] = pd.DisplayName
+func truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
 	}
+	return s[:maxLen-3] + "..."
+}
 
-	// Create output directory
-	dirName := solve.StartedAt.Format("2006-01-02_150405")
-	outputDir := filepath.Join("reports", dirName)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
-	}
+// buildFullSolveSummary computes the solve summary written to
+// solve_summary.json and passed to export templates.
+func buildFullSolveSummary(solve *storage.Solve, moveRecords []storage.MoveRecord, segments []storage.PhaseSegment, phaseDefMap map[string]string) FullSolveSummary {
+	moves := storage.ToMoves(moveRecords)
 
 	// Basic stats
 	longestPause := analysis.FindLongestPause(moves)
@@ -699,20 +886,13 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 
 	// Optimization analysis
 	optimized := analysis.OptimizeMoves(moves)
-	efficiency := analysis.CalculateEfficiency(moves, optimized)
+	efficiency := metricEfficiency(moves, optimized)
 
 	// Movement profile
 	profile := analysis.AnalyzeMovementProfile(moves)
 
 	// Calculate actual solve time
-	var solveDurationMs int64
-	var solveMoves int
-	for _, seg := range segments {
-		if seg.PhaseKey != "scramble" && seg.PhaseKey != "inspection" {
-			solveDurationMs += seg.DurationMs
-			solveMoves += seg.MoveCount
-		}
-	}
+	solveDurationMs, solveMoves := solvingTime(segments)
 
 	// Build summary
 	summary := FullSolveSummary{
@@ -720,8 +900,9 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 		StartedAt:          solve.StartedAt.Format(time.RFC3339),
 		SolveDurationMs:    solveDurationMs,
 		SolveMoves:         solveMoves,
-		TotalMoves:         len(moves),
-		OptimizedMoves:     len(optimized),
+		Metric:             turnMetric(),
+		TotalMoves:         countMoves(moves),
+		OptimizedMoves:     countMoves(optimized),
 		Efficiency:         efficiency,
 		LongestPauseMs:     longestPause,
 		PauseCountOver1500: pauseCount,
@@ -756,13 +937,132 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 			DurationMs:  seg.DurationMs,
 			MoveCount:   seg.MoveCount,
 			TPS:         seg.TPS,
+			Optimal:     phaseOptimality(seg, moveRecords),
 		})
 	}
+	summary.SuperPhaseStats = superPhaseStats(segments, loadSuperPhases())
+	summary.LastLayer = lastLayerReport(moveRecords, segments)
+
+	return summary
+}
+
+// phaseOptimality compares the moves of seg with the optimum from the
+// state left by the moves before it, for the phases that have a solver.
+func phaseOptimality(seg storage.PhaseSegment, moveRecords []storage.MoveRecord) *analysis.PhaseOptimality {
+	var before []storage.MoveRecord
+	for _, m := range moveRecords {
+		if m.TsMs < seg.StartTsMs {
+			before = append(before, m)
+		}
+	}
+	return analysis.AnalyzePhaseOptimality(seg.PhaseKey, storage.ToMoves(before),
+		storage.ToMoves(movesInSegment(moveRecords, seg)), turnMetric())
+}
+
+// lastLayerReport recognizes the OLL and PLL cases of a solve and times
+// them, or returns nil if the solve has no last layer to split.
+func lastLayerReport(moveRecords []storage.MoveRecord, segments []storage.PhaseSegment) *analysis.LastLayerReport {
+	start := int64(-1)
+	for _, seg := range segments {
+		if seg.PhaseKey != "scramble" && seg.PhaseKey != "inspection" {
+			start = seg.StartTsMs
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	var before, solveRecords []storage.MoveRecord
+	for _, m := range moveRecords {
+		if m.TsMs < start {
+			before = append(before, m)
+		} else {
+			solveRecords = append(solveRecords, m)
+		}
+	}
+	return analysis.AnalyzeLastLayer(storage.ToMoves(before), storage.ToMoves(solveRecords))
+}
+
+// printLastLayerSplit prints one step of the last layer, e.g.
+// "  PLL: T-perm (U'), 4.2s, 16 moves".
+func printLastLayerSplit(step string, split analysis.LastLayerSplit) {
+	if split.Case == nil {
+		fmt.Printf("  %s: skip\n", step)
+		return
+	}
+	name := split.Case.Name
+	if split.Case.AUF != "" {
+		name += " (" + split.Case.AUF + ")"
+	}
+	fmt.Printf("  %s: %s, %.1fs, %d moves\n", step, name, float64(split.DurationMs)/1000.0, split.Moves)
+}
+
+// GenerateReportForSolve generates a full report for a solve and returns the output directory.
+// This can be called from both CLI commands and the TUI.
+func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
+	return GenerateReportAtLevel(db, solveID, ReportStandard)
+}
+
+// GenerateReportAtLevel generates a report for a solve at the given level,
+// computing only what the report already in its directory lacks, and
+// returns the output directory.
+func GenerateReportAtLevel(db *storage.DB, solveID string, level ReportLevel) (string, error) {
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+	orientRepo := storage.NewOrientationRepository(db)
+
+	solve, err := solveRepo.Get(solveID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return "", fmt.Errorf("solve not found")
+	}
+
+	// Get moves
+	moveRecords, err := moveRepo.GetBySolve(solve.SolveID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get moves: %w", err)
+	}
+
+	moves := storage.ToMoves(moveRecords)
+
+	// Get phase segments
+	segments, err := phaseRepo.GetPhaseSegments(solve.SolveID)
+	if err != nil {
+		segments = nil
+	}
+	segments = metricSegments(segments, moveRecords)
+
+	// Get phase defs for display names
+	phaseDefs, _ := phaseRepo.GetAllPhaseDefs()
+	phaseDefMap := make(map[string]string)
+	for _, pd := range phaseDefs {
+		phaseDefMap[pd.PhaseKey] = pd.DisplayName
+	}
+
+	// Create output directory
+	outputDir := solveReportDir(solve)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	existing := existingReportLevel(outputDir, solve.SolveID)
+	summary := buildFullSolveSummary(solve, moveRecords, segments, phaseDefMap)
+	setDownCfg := loadSetDownConfig()
+	applySetDowns(&summary, detectSetDowns(storage.NewEventRepository(db), solve.SolveID, moveRecords, segments, setDownCfg), setDownCfg)
 
 	// Write solve_summary.json
 	if err := writeJSON(filepath.Join(outputDir, "solve_summary.json"), summary); err != nil {
 		return "", err
 	}
+	if !needsTier(ReportStandard, level, existing) {
+		if err := finishSolveReport(outputDir, solve, moveRecords, segments, phaseDefMap, level, existing, false); err != nil {
+			return "", err
+		}
+		return outputDir, nil
+	}
 
 	// Write moves.txt
 	var notations []string
@@ -782,20 +1082,24 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 
 	// Write moves.json
 	type MoveJSON struct {
-		MoveIndex int    `json:"move_index"`
-		TsMs      int64  `json:"ts_ms"`
-		Face      string `json:"face"`
-		Turn      int    `json:"turn"`
-		Notation  string `json:"notation"`
+		MoveIndex  int    `json:"move_index"`
+		TsMs       int64  `json:"ts_ms"`
+		TsUs       int64  `json:"ts_us"`
+		Face       string `json:"face"`
+		Turn       int    `json:"turn"`
+		Notation   string `json:"notation"`
+		TimeSource string `json:"time_source"` // received, device or interpolated
 	}
 	var movesJSON []MoveJSON
 	for i, m := range moves {
 		movesJSON = append(movesJSON, MoveJSON{
-			MoveIndex: i,
-			TsMs:      m.Time.UnixMilli(),
-			Face:      string(m.Face),
-			Turn:      int(m.Turn),
-			Notation:  m.Notation(),
+			MoveIndex:  i,
+			TsMs:       m.Time.UnixMilli(),
+			TsUs:       m.Time.UnixMicro(),
+			Face:       string(m.Face),
+			Turn:       int(m.Turn),
+			Notation:   m.Notation(),
+			TimeSource: m.TimeSource.String(),
 		})
 	}
 	if err := writeJSON(filepath.Join(outputDir, "moves.json"), movesJSON); err != nil {
@@ -804,11 +1108,14 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 
 	// Write playback.json
 	orientations, _ := orientRepo.GetBySolve(solve.SolveID)
+	bookmarks, _ := recorder.LoadBookmarks(storage.NewEventRepository(db), solve.SolveID)
+	audioAlign, _ := recorder.LoadAudioAlignment(storage.NewEventRepository(db), solve.SolveID)
 	var timeline []PlaybackEvent
 
 	for _, m := range moveRecords {
 		timeline = append(timeline, PlaybackEvent{
 			TsMs:     m.TsMs,
+			TsUs:     m.TsUs,
 			Type:     "move",
 			Face:     m.Face,
 			Turn:     m.Turn,
@@ -818,19 +1125,22 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 	for _, o := range orientations {
 		timeline = append(timeline, PlaybackEvent{
 			TsMs:      o.TsMs,
+			TsUs:      o.TsUs,
 			Type:      "orientation",
 			UpFace:    o.UpFace,
 			FrontFace: o.FrontFace,
 		})
 	}
 	sort.Slice(timeline, func(i, j int) bool {
-		return timeline[i].TsMs < timeline[j].TsMs
+		return timeline[i].TsUs < timeline[j].TsUs
 	})
 
 	playback := PlaybackData{
 		SolveID:      solve.SolveID,
 		TotalMoves:   len(moveRecords),
 		TotalOrients: len(orientations),
+		Bookmarks:    bookmarks,
+		Anchors:      playbackAnchors(db, solve),
 		Timeline:     timeline,
 	}
 	if solve.DurationMs != nil {
@@ -880,8 +1190,13 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 		finalReport := analysis.AnalyzeFinalPhase(finalPhaseMoves)
 		finalReport.FinalPhaseMoveCount = len(finalPhaseMoves)
 		writeJSON(filepath.Join(outputDir, "final_phase_report.json"), finalReport)
+		storage.NewToolDetectionRepository(db).Replace(solve.SolveID, finalReport.ToolCounts)
 	}
 
+	reconstruction := buildReconstruction(moveRecords, segments)
+	writeReconstruction(outputDir, reconstruction)
+	writeJSON(filepath.Join(outputDir, "mirrored_algorithms.json"), findMirroredAlgorithms(moveRecords, segments))
+
 	// Phase analysis
 	var phaseAnalyses []PhaseAnalysis
 	if len(segments) > 0 {
@@ -912,7 +1227,7 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 			pa := PhaseAnalysis{
 				PhaseKey:    seg.PhaseKey,
 				DisplayName: displayName,
-				MoveCount:   len(phaseMoves),
+				MoveCount:   countMoves(phaseMoves),
 				DurationMs:  seg.DurationMs,
 				TPS:         seg.TPS,
 				Moves:       phaseText,
@@ -920,6 +1235,7 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 
 			if len(phaseMoves) > 0 {
 				pa.Repetitions = analysis.AnalyzeRepetitions(phaseMoves)
+				pa.ShorterLine = metricLine(analysis.SuggestShorterLine(phaseMoves), phaseMoves)
 			}
 			if len(phaseMoves) >= 4 {
 				phaseNgrams := analysis.MineNGrams(phaseMoves, 4, 8, 10)
@@ -945,22 +1261,34 @@ func GenerateReportForSolve(db *storage.DB, solveID string) (string, error) {
 	// Diagnostics
 	diagnostics, _ := analysis.AnalyzeDiagnostics(solve.SolveID, moveRepo, phaseRepo, orientRepo)
 	if diagnostics != nil {
+		diagnostics.MoveCounter, _ = analysis.ReconcileMoveCounter(solve.SolveID, storage.NewCounterRepository(db), moveRepo)
 		writeJSON(filepath.Join(outputDir, "diagnostics.json"), diagnostics)
 	}
 
 	// Generate visualiser
 	vizReport := buildVisualizerReport(
-		solveDurationMs, solveMoves, len(moves), len(optimized), efficiency, summary.TPSOverall,
-		longestPause, repReport, phaseAnalyses, diagnostics, phaseDefMap,
+		summary.SolveDurationMs, summary.SolveMoves, summary.TotalMoves, summary.OptimizedMoves, summary.Efficiency, summary.TPSOverall,
+		summary.LongestPauseMs, repReport, phaseAnalyses, diagnostics, phaseDefMap,
 	)
-	if err := generateVisualizerHTML(outputDir, solve, moveRecords, segments, orientations, phaseDefMap, vizReport); err != nil {
+	vizReport.Reconstruction = reconstruction.Lines
+	if err := generateVisualizerHTML(outputDir, solve, moveRecords, segments, orientations, bookmarks, audioAlign, phaseDefMap, vizReport); err != nil {
 		return "", fmt.Errorf("generating visualizer: %w", err)
 	}
+	if err := finishSolveReport(outputDir, solve, moveRecords, segments, phaseDefMap, level, existing, false); err != nil {
+		return "", err
+	}
 
 	return outputDir, nil
 }
 
 func runReportTrend(cmd *cobra.Command, args []string) error {
+	if trendCompare {
+		return runTrendCompare(args)
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments %q (did you mean --compare?)", args)
+	}
+
 	// Open database
 	db, err := openDB()
 	if err != nil {
@@ -971,49 +1299,49 @@ func runReportTrend(cmd *cobra.Command, args []string) error {
 	solveRepo := storage.NewSolveRepository(db)
 	moveRepo := storage.NewMoveRepository(db)
 	phaseRepo := storage.NewPhaseRepository(db)
+	orientRepo := storage.NewOrientationRepository(db)
+
+	// Get recent solves, or a session's
+	var solves []storage.Solve
+	if trendSession != "" {
+		session, err := findSession(storage.NewSessionRepository(db), trendSession)
+		if err != nil {
+			return err
+		}
+		solves, err = solveRepo.ListBetween(session.StartedAt, session.End(time.Now().Add(time.Second)))
+		if err != nil {
+			return fmt.Errorf("failed to get solves: %w", err)
+		}
+	} else {
+		solves, err = solveRepo.List(trendWindow)
+		if err != nil {
+			return fmt.Errorf("failed to get solves: %w", err)
+		}
+	}
 
-	// Get recent solves
-	solves, err := solveRepo.List(trendWindow)
+	solves = filterSolvesByDevice(solves, reportDevice)
+	solves, err = filterSolvesByContext(db, solves, trendContext)
 	if err != nil {
-		return fmt.Errorf("failed to get solves: %w", err)
+		return err
 	}
 
 	if len(solves) == 0 {
 		return fmt.Errorf("no solves found")
 	}
 
-	fmt.Printf("Analyzing %d solves...\n", len(solves))
-
-	// Build solve data for trend analysis
-	var solveData []analysis.SolveData
-	for _, s := range solves {
-		if s.DurationMs == nil || *s.DurationMs <= 0 {
-			continue
-		}
-
-		moveCount, _ := moveRepo.Count(s.SolveID)
-		tps := float64(moveCount) / (float64(*s.DurationMs) / 1000.0)
-
-		sd := analysis.SolveData{
-			SolveID:    s.SolveID,
-			StartedAt:  s.StartedAt,
-			DurationMs: *s.DurationMs,
-			MoveCount:  moveCount,
-			TPS:        tps,
-			PhaseData:  make(map[string]analysis.PhaseData),
-		}
-
-		// Get phase data
-		segments, _ := phaseRepo.GetPhaseSegments(s.SolveID)
-		for _, seg := range segments {
-			sd.PhaseData[seg.PhaseKey] = analysis.PhaseData{
-				DurationMs: seg.DurationMs,
-				MoveCount:  seg.MoveCount,
-				TPS:        seg.TPS,
-			}
+	if !trendTable.JSON {
+		if len(trendContext) > 0 {
+			fmt.Printf("Filtering by context: %s\n", formatContext(trendContext))
 		}
+		fmt.Printf("Analyzing %d solves...\n", len(solves))
+	}
 
-		solveData = append(solveData, sd)
+	// Build solve data for trend analysis
+	ctx, stop := interruptContext()
+	defer stop()
+	solveData, err := buildSolveData(ctx, solves, moveRepo, phaseRepo, orientRepo, storage.NewEventRepository(db))
+	if err != nil {
+		return err
 	}
 
 	if len(solveData) == 0 {
@@ -1022,11 +1350,12 @@ func runReportTrend(cmd *cobra.Command, args []string) error {
 
 	// Run trend analysis
 	trendReport := analysis.AnalyzeTrends(solveData)
+	trendReport.SuperPhaseTrends = analysis.AnalyzeSuperPhaseTrends(solveData, loadSuperPhases())
 
 	// Determine output
 	outputDir := reportOutputDir
 	if outputDir == "" {
-		outputDir = "reports"
+		outputDir = reportsDir()
 	}
 
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -1038,13 +1367,27 @@ func runReportTrend(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	phaseTable := trendPhaseTable(trendReport)
+	if err := phaseTable.Apply(trendTable); err != nil {
+		return err
+	}
+	if trendTable.JSON {
+		return phaseTable.WriteJSON(os.Stdout)
+	}
+
 	fmt.Println()
 	fmt.Printf("Trend report generated: %s\n", outputFile)
 	fmt.Println()
 	fmt.Printf("Analyzed %d completed solves\n", trendReport.CompletedSolves)
+	if trendReport.ManualSolves > 0 {
+		fmt.Printf("  (%d manually timed; excluded from move and TPS averages)\n", trendReport.ManualSolves)
+	}
 	fmt.Println()
 	fmt.Println("Summary:")
 	fmt.Printf("  Average duration: %.1fs\n", trendReport.AvgDurationMs/1000.0)
+	if trendReport.AvgFocusedDurationMs > 0 {
+		fmt.Printf("  Average focused time: %.1fs (cube put down in %d of them)\n", trendReport.AvgFocusedDurationMs/1000.0, trendReport.SetDownSolves)
+	}
 	fmt.Printf("  Average moves: %.1f\n", trendReport.AvgMoves)
 	fmt.Printf("  Average TPS: %.2f\n", trendReport.AvgTPS)
 	fmt.Println()
@@ -1054,6 +1397,12 @@ func runReportTrend(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Improvement: %.1f%%\n", trendReport.ImprovementPct)
 	fmt.Printf("  Consistency: %.1f/100\n", trendReport.ConsistencyScore)
 
+	if trendReport.AvgEffort > 0 {
+		fmt.Println()
+		fmt.Printf("  Average effort: %.1f (%.2f per turn)\n", trendReport.AvgEffort, trendReport.AvgEffortPerTurn)
+		fmt.Printf("  Effort improvement: %.1f%%\n", trendReport.EffortImprovePct)
+	}
+
 	// Rolling averages
 	if len(trendReport.RollingAvgs) > 0 {
 		fmt.Println()
@@ -1065,19 +1414,337 @@ func runReportTrend(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Phase trends
-	if len(trendReport.PhaseTrends) > 0 {
+	// Phase and super-phase trends
+	if len(phaseTable.Rows) > 0 {
 		fmt.Println()
 		fmt.Println("Phase trends:")
+		phaseTable.Render(os.Stdout)
+	}
+
+	if len(trendReport.MirroredAlgorithms) > 0 {
+		fmt.Println()
+		fmt.Println("Mirrored algorithms executed then corrected:")
+		for _, c := range trendReport.MirroredAlgorithms {
+			fmt.Printf("  %-10s %dx (%d mirrored, %d inverted), %d moves wasted\n", c.Case, c.Total, c.Mirror, c.Inverse, c.WastedMoves)
+		}
+	}
+
+	if reportBenchmark {
+		phaseDurations := make(map[string]int64)
 		for key, trend := range trendReport.PhaseTrends {
-			fmt.Printf("  %s: %.1fs avg, %.1f%% improvement\n",
-				key, trend.AvgDurationMs/1000.0, trend.ImprovementPct)
+			phaseDurations[key] = int64(trend.AvgDurationMs)
+		}
+		benchmarks, err := analysis.CompareToBenchmarks(int64(trendReport.AvgDurationMs), phaseDurations)
+		if err != nil {
+			fmt.Printf("Warning: benchmarks unavailable: %v\n", err)
+		} else {
+			if err := writeJSON(filepath.Join(outputDir, "benchmark_report.json"), benchmarks); err != nil {
+				return err
+			}
+			printBenchmarks(benchmarks)
 		}
 	}
 
 	return nil
 }
 
+// trendPhaseTable builds the phase trend table, phases in solve order
+// followed by super-phases.
+func trendPhaseTable(report *analysis.TrendReport) *table {
+	t := &table{Columns: []tableColumn{
+		{Key: "phase", Title: "Phase"},
+		{Key: "kind", Title: "Kind"},
+		{Key: "avg_ms", Title: "Avg", Right: true, Format: formatSeconds},
+		{Key: "avg_moves", Title: "Moves", Right: true, Format: formatFloat(1)},
+		{Key: "avg_tps", Title: "TPS", Right: true, Format: formatFloat(2)},
+		{Key: "improvement_pct", Title: "Improvement", Right: true, Format: func(v interface{}) string {
+			return fmt.Sprintf("%.1f%%", v)
+		}},
+	}}
+
+	addRows := func(trends map[string]analysis.PhaseTrend, kind string) {
+		keys := make([]string, 0, len(trends))
+		for key := range trends {
+			keys = append(keys, key)
+		}
+		order := func(key string) int {
+			if n := storage.PhaseKeyToNumber(key); n >= 0 {
+				return n
+			}
+			return len(keys) + 8 // Unnumbered phases last
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			ni, nj := order(keys[i]), order(keys[j])
+			if ni != nj {
+				return ni < nj
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			trend := trends[key]
+			t.Rows = append(t.Rows, map[string]interface{}{
+				"phase":           key,
+				"kind":            kind,
+				"avg_ms":          trend.AvgDurationMs,
+				"avg_moves":       trend.AvgMoves,
+				"avg_tps":         trend.AvgTPS,
+				"improvement_pct": trend.ImprovementPct,
+			})
+		}
+	}
+	addRows(report.PhaseTrends, "phase")
+	addRows(report.SuperPhaseTrends, "super")
+
+	return t
+}
+
+// loadSuperPhases returns the super-phases from the user's config, or nil
+// if none are configured or the config cannot be read.
+func loadSuperPhases() []analysis.SuperPhase {
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		return nil
+	}
+	return cfg.SuperPhases
+}
+
+// superPhaseStats aggregates phase segments into super-phase statistics.
+// A super-phase spans from the earliest start to the latest end of its
+// member segments; duration and moves are summed.
+func superPhaseStats(segments []storage.PhaseSegment, supers []analysis.SuperPhase) []PhaseStatsReport {
+	var stats []PhaseStatsReport
+	for _, sp := range supers {
+		var agg PhaseStatsReport
+		found := false
+		for _, seg := range segments {
+			if !sp.Contains(seg.PhaseKey) {
+				continue
+			}
+			if !found || seg.StartTsMs < agg.StartTsMs {
+				agg.StartTsMs = seg.StartTsMs
+			}
+			if seg.EndTsMs > agg.EndTsMs {
+				agg.EndTsMs = seg.EndTsMs
+			}
+			agg.DurationMs += seg.DurationMs
+			agg.MoveCount += seg.MoveCount
+			found = true
+		}
+		if !found {
+			continue
+		}
+		agg.PhaseKey = sp.Key
+		agg.DisplayName = sp.Name()
+		if agg.DurationMs > 0 {
+			agg.TPS = float64(agg.MoveCount) / (float64(agg.DurationMs) / 1000.0)
+		}
+		stats = append(stats, agg)
+	}
+	return stats
+}
+
+// buildSolveData converts completed solves into trend analysis input,
+// showing progress on a terminal. It stops when ctx is cancelled.
+func buildSolveData(ctx context.Context, solves []storage.Solve, moveRepo *storage.MoveRepository, phaseRepo *storage.PhaseRepository, orientRepo *storage.OrientationRepository, eventRepo *storage.EventRepository) ([]analysis.SolveData, error) {
+	setDownCfg := loadSetDownConfig()
+	bar := newProgress("Analyzing", len(solves))
+	defer bar.Done()
+
+	var solveData []analysis.SolveData
+	for i, s := range solves {
+		if ctx.Err() != nil {
+			return nil, interrupted(i, len(solves), "solves")
+		}
+		bar.Step()
+		if s.DurationMs == nil || *s.DurationMs <= 0 {
+			continue
+		}
+
+		sd := analysis.SolveData{
+			SolveID:    s.SolveID,
+			StartedAt:  s.StartedAt,
+			DurationMs: *s.DurationMs,
+			PhaseData:  make(map[string]analysis.PhaseData),
+			Manual:     s.IsManual(),
+		}
+
+		// Manual solves have no move stream or phases
+		if sd.Manual {
+			solveData = append(solveData, sd)
+			continue
+		}
+
+		// Get phase data, recounted from the moves outside HTM
+		segments, _ := phaseRepo.GetPhaseSegments(s.SolveID)
+		if turnMetric() == gocube.MetricHTM {
+			sd.MoveCount, _ = moveRepo.Count(s.SolveID)
+		} else {
+			records, _ := moveRepo.GetBySolve(s.SolveID)
+			sd.MoveCount = countMoves(storage.ToMoves(records))
+			segments = metricSegments(segments, records)
+		}
+		sd.TPS = float64(sd.MoveCount) / (float64(*s.DurationMs) / 1000.0)
+		for _, seg := range segments {
+			sd.PhaseData[seg.PhaseKey] = analysis.PhaseData{
+				DurationMs: seg.DurationMs,
+				MoveCount:  seg.MoveCount,
+				TPS:        seg.TPS,
+			}
+		}
+		moves, orientations := loadSolveWindow(s.SolveID, segments, moveRepo, orientRepo)
+		sd.Effort = estimateSolveEffort(moves, orientations)
+		sd.PauseCount, sd.LongestPauseMs = movePauses(moves, 1500)
+		sd.MirroredAlgorithms = analysis.FindMirroredAlgorithms(storage.ToMoves(moves))
+		setDowns := detectSetDowns(eventRepo, s.SolveID, moves, nil, setDownCfg)
+		sd.SetDownMs = analysis.SetDownTotalMs(setDowns)
+		if setDownCfg.FocusedTime && sd.SetDownMs > 0 && sd.DurationMs > sd.SetDownMs {
+			sd.FocusedDurationMs = sd.DurationMs - sd.SetDownMs
+		}
+
+		solveData = append(solveData, sd)
+	}
+
+	return solveData, nil
+}
+
+// solveReportDir returns the report directory of a solve,
+// YYYY-MM-DD_HHMMSS_<solve ID prefix>. The ID keeps solves started in the
+// same second, e.g. simulated ones, from sharing a directory.
+func solveReportDir(solve *storage.Solve) string {
+	dirName := localTime(solve.StartedAt).Format("2006-01-02_150405") + "_" + solve.SolveID[:8]
+	return filepath.Join(reportsDir(), dirName)
+}
+
+// solvingTime returns the solve time and moves of the solving phases,
+// excluding scramble and inspection.
+func solvingTime(segments []storage.PhaseSegment) (durationMs int64, moves int) {
+	for _, seg := range segments {
+		if storage.IsSolvingPhase(seg.PhaseKey) {
+			durationMs += seg.DurationMs
+			moves += seg.MoveCount
+		}
+	}
+	return durationMs, moves
+}
+
+// loadSolveWindow returns the moves and orientations of the solving phases,
+// excluding scramble and inspection. Without phases, the whole solve is used.
+func loadSolveWindow(solveID string, segments []storage.PhaseSegment, moveRepo *storage.MoveRepository, orientRepo *storage.OrientationRepository) ([]storage.MoveRecord, []storage.OrientationRecord) {
+	startMs, endMs := int64(-1), int64(-1)
+	for _, seg := range segments {
+		if seg.PhaseKey == "scramble" || seg.PhaseKey == "inspection" {
+			continue
+		}
+		if startMs < 0 || seg.StartTsMs < startMs {
+			startMs = seg.StartTsMs
+		}
+		if seg.EndTsMs > endMs {
+			endMs = seg.EndTsMs
+		}
+	}
+
+	var moves []storage.MoveRecord
+	var orientations []storage.OrientationRecord
+	if startMs >= 0 {
+		moves, _ = moveRepo.GetBySolveRange(solveID, startMs, endMs)
+		orientations, _ = orientRepo.GetBySolveRange(solveID, startMs, endMs)
+	} else {
+		moves, _ = moveRepo.GetBySolve(solveID)
+		orientations, _ = orientRepo.GetBySolve(solveID)
+	}
+	return moves, orientations
+}
+
+// buildReconstruction annotates the solving moves, from the first move
+// after scramble and inspection. Line indexes are into all of moveRecords.
+func buildReconstruction(moveRecords []storage.MoveRecord, segments []storage.PhaseSegment) *analysis.Reconstruction {
+	offset := solvingOffset(moveRecords, segments)
+	reconstruction := analysis.AnnotateReconstruction(storage.ToMoves(moveRecords[offset:]))
+	for i := range reconstruction.Lines {
+		reconstruction.Lines[i].StartIndex += offset
+		reconstruction.Lines[i].EndIndex += offset
+	}
+	return reconstruction
+}
+
+// findMirroredAlgorithms finds mirrored or inverted algorithms that were
+// undone and corrected in the solving moves. Indexes are into all of
+// moveRecords.
+func findMirroredAlgorithms(moveRecords []storage.MoveRecord, segments []storage.PhaseSegment) []analysis.MirroredAttempt {
+	offset := solvingOffset(moveRecords, segments)
+	attempts := analysis.FindMirroredAlgorithms(storage.ToMoves(moveRecords[offset:]))
+	for i := range attempts {
+		attempts[i].StartIndex += offset
+		attempts[i].EndIndex += offset
+	}
+	return attempts
+}
+
+// solvingOffset returns the index of the first move after scramble and
+// inspection.
+func solvingOffset(moveRecords []storage.MoveRecord, segments []storage.PhaseSegment) int {
+	startMs := int64(-1)
+	for _, seg := range segments {
+		if seg.PhaseKey == "scramble" || seg.PhaseKey == "inspection" {
+			continue
+		}
+		if startMs < 0 || seg.StartTsMs < startMs {
+			startMs = seg.StartTsMs
+		}
+	}
+
+	offset := 0
+	for startMs >= 0 && offset < len(moveRecords) && moveRecords[offset].TsMs < startMs {
+		offset++
+	}
+	return offset
+}
+
+// writeReconstruction writes reconstruction.txt, the annotated moves one
+// line per algorithm, and reconstruction.json.
+func writeReconstruction(outputDir string, reconstruction *analysis.Reconstruction) error {
+	if err := os.WriteFile(filepath.Join(outputDir, "reconstruction.txt"), []byte(reconstruction.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write reconstruction.txt: %w", err)
+	}
+	return writeJSON(filepath.Join(outputDir, "reconstruction.json"), reconstruction)
+}
+
+// estimateSolveEffort estimates physical effort for a solve window.
+// Returns nil if there are no moves.
+func estimateSolveEffort(moves []storage.MoveRecord, orientations []storage.OrientationRecord) *analysis.EffortReport {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	// Each change of held orientation counts as one whole-cube rotation
+	rotations := 0
+	for i := 1; i < len(orientations); i++ {
+		if orientations[i].UpFace != orientations[i-1].UpFace || orientations[i].FrontFace != orientations[i-1].FrontFace {
+			rotations++
+		}
+	}
+
+	effort := analysis.EstimateEffort(moves, rotations)
+	return &effort
+}
+
+// movePauses counts gaps between consecutive moves longer than thresholdMs
+// and returns the count and the longest gap.
+func movePauses(moves []storage.MoveRecord, thresholdMs int64) (int, int64) {
+	count := 0
+	var longest int64
+	for i := 1; i < len(moves); i++ {
+		gap := (moves[i].TsUs - moves[i-1].TsUs) / 1000
+		if gap > thresholdMs {
+			count++
+		}
+		if gap > longest {
+			longest = gap
+		}
+	}
+	return count, longest
+}
+
 // writeJSON writes data as formatted JSON to a file.
 func writeJSON(path string, data interface{}) error {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
@@ -1139,6 +1806,7 @@ func buildVisualizerReport(
 			Moves:         pa.Moves,
 			Cancellations: cancellations,
 			TopPatterns:   topPatterns,
+			ShorterLine:   pa.ShorterLine,
 		})
 	}
 
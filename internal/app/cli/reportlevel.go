@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// ReportLevel is how much analysis a solve report runs. Each level includes
+// the ones before it.
+type ReportLevel string
+
+const (
+	ReportQuick    ReportLevel = "quick"    // solve_summary.json only
+	ReportStandard ReportLevel = "standard" // Moves, patterns, phases, diagnostics and visualizer
+	ReportDeep     ReportLevel = "deep"     // Adds solver baselines worked out from the cube state
+)
+
+// reportMetaFile records the level a report directory was generated at.
+const reportMetaFile = "report_meta.json"
+
+// rank orders levels; unknown levels rank below quick.
+func (l ReportLevel) rank() int {
+	switch l {
+	case ReportQuick:
+		return 1
+	case ReportStandard:
+		return 2
+	case ReportDeep:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func parseReportLevel(s string) (ReportLevel, error) {
+	l := ReportLevel(s)
+	if l.rank() == 0 {
+		return "", fmt.Errorf("unknown report level: %s (use quick, standard or deep)", s)
+	}
+	return l, nil
+}
+
+// reportMeta is the JSON structure for report_meta.json.
+type reportMeta struct {
+	SolveID     string      `json:"solve_id"`
+	Level       ReportLevel `json:"level"`
+	GeneratedAt string      `json:"generated_at"`
+	AppVersion  string      `json:"app_version"`
+}
+
+// existingReportLevel returns the level of the report for solveID already
+// in outputDir, or "" if there is none.
+func existingReportLevel(outputDir, solveID string) ReportLevel {
+	data, err := os.ReadFile(filepath.Join(outputDir, reportMetaFile))
+	if err != nil {
+		return ""
+	}
+	var meta reportMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.SolveID != solveID {
+		return ""
+	}
+	return meta.Level
+}
+
+// needsTier returns true if a report at level must compute tier, given the
+// level already generated.
+func needsTier(tier, level, existing ReportLevel) bool {
+	return level.rank() >= tier.rank() && existing.rank() < tier.rank()
+}
+
+// DeepReport is the JSON structure for deep_report.json: baselines from
+// the solver, which work from the cube state rather than the move sequence.
+type DeepReport struct {
+	SolveID        string          `json:"solve_id"`
+	Metric         gocube.Metric   `json:"metric"` // Turn metric of the move counts
+	SolverBaseline *SolverBaseline `json:"solver_baseline,omitempty"`
+	Phases         []PhaseBaseline `json:"phases,omitempty"`
+}
+
+// SolverBaseline compares the solve with the solver's solution of the
+// scrambled cube.
+type SolverBaseline struct {
+	Scramble    string `json:"scramble"`
+	SolverMoves int    `json:"solver_moves"`
+	Solution    string `json:"solution"`
+	YourMoves   int    `json:"your_moves"`
+}
+
+// PhaseBaseline is the shortest line found with the same effect on the
+// cube as a phase's moves.
+type PhaseBaseline struct {
+	PhaseKey       string                   `json:"phase_key"`
+	DisplayName    string                   `json:"display_name"`
+	EquivalentLine *analysis.LineSuggestion `json:"equivalent_line,omitempty"`
+}
+
+// buildDeepReport computes the deep tier. The scrambled state is taken from
+// the moves recorded during the scramble phase, or from the solve's
+// scramble text if the scramble was not marked.
+func buildDeepReport(solve *storage.Solve, moveRecords []storage.MoveRecord, segments []storage.PhaseSegment, phaseDefMap map[string]string) *DeepReport {
+	report := &DeepReport{SolveID: solve.SolveID, Metric: turnMetric()}
+
+	var scramble, solution []gocube.Move
+	marked := false
+	for _, seg := range segments {
+		phaseMoves := storage.ToMoves(movesInSegment(moveRecords, seg))
+		if seg.PhaseKey == "scramble" {
+			scramble = append(scramble, phaseMoves...)
+			marked = true
+			continue
+		}
+		if seg.PhaseKey == "inspection" {
+			continue
+		}
+		solution = append(solution, phaseMoves...)
+
+		displayName := seg.PhaseKey
+		if dn, ok := phaseDefMap[seg.PhaseKey]; ok {
+			displayName = dn
+		}
+		report.Phases = append(report.Phases, PhaseBaseline{
+			PhaseKey:       seg.PhaseKey,
+			DisplayName:    displayName,
+			EquivalentLine: metricLine(analysis.EquivalentLine(phaseMoves), phaseMoves),
+		})
+	}
+	if !marked && solve.ScrambleText != nil {
+		scramble, _ = gocube.ParseMoves(*solve.ScrambleText)
+	}
+
+	if len(scramble) > 0 {
+		baseline := gocube.SolveSequence(scramble)
+		report.SolverBaseline = &SolverBaseline{
+			Scramble:    gocube.FormatMoves(scramble),
+			SolverMoves: countMoves(baseline),
+			Solution:    gocube.FormatMoves(baseline),
+			YourMoves:   countMoves(solution),
+		}
+	}
+	return report
+}
+
+// movesInSegment returns the moves made during a phase segment, with the
+// same half-open bounds as MoveRepository.GetBySolveRange.
+func movesInSegment(moves []storage.MoveRecord, seg storage.PhaseSegment) []storage.MoveRecord {
+	var in []storage.MoveRecord
+	for _, m := range moves {
+		if m.TsMs >= seg.StartTsMs && m.TsMs < seg.EndTsMs {
+			in = append(in, m)
+		}
+	}
+	return in
+}
+
+// finishSolveReport runs the deep tier if level asks for it and it is not
+// in outputDir already, then records the level reached.
+func finishSolveReport(outputDir string, solve *storage.Solve, moveRecords []storage.MoveRecord, segments []storage.PhaseSegment, phaseDefMap map[string]string, level, existing ReportLevel, print bool) error {
+	if needsTier(ReportDeep, level, existing) {
+		if print {
+			fmt.Println("  - Computing solver baselines...")
+		}
+		deep := buildDeepReport(solve, moveRecords, segments, phaseDefMap)
+		if err := writeJSON(filepath.Join(outputDir, "deep_report.json"), deep); err != nil {
+			return err
+		}
+		if print {
+			printDeepReport(deep)
+		}
+	}
+
+	if existing.rank() > level.rank() {
+		level = existing
+	}
+	return writeJSON(filepath.Join(outputDir, reportMetaFile), reportMeta{
+		SolveID:     solve.SolveID,
+		Level:       level,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		AppVersion:  version,
+	})
+}
+
+func printDeepReport(d *DeepReport) {
+	fmt.Println()
+	fmt.Println("Solver baselines:")
+	if b := d.SolverBaseline; b != nil {
+		fmt.Printf("  Scramble solved in %d moves%s by the solver (you: %d)\n", b.SolverMoves, metricLabel(), b.YourMoves)
+		fmt.Printf("    %s\n", b.Solution)
+	}
+	for _, p := range d.Phases {
+		if p.EquivalentLine == nil {
+			continue
+		}
+		fmt.Printf("  %s: same effect in %d moves instead of %d\n",
+			p.DisplayName, p.EquivalentLine.OptimizedMoves, p.EquivalentLine.OriginalMoves)
+		fmt.Printf("    %s\n", p.EquivalentLine.Moves)
+	}
+}
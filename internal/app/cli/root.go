@@ -12,8 +12,10 @@ const version = "0.1.0"
 
 var (
 	// Global flags
-	dbPath  string
-	verbose bool
+	dbPath      string
+	verbose     bool
+	profileName string
+	metricName  string
 )
 
 // rootCmd is the base command.
@@ -26,6 +28,9 @@ using a GoCube smart cube.
 Connect to your GoCube over Bluetooth, record solves with phase marking,
 and generate detailed analysis reports to improve your solving technique.`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadProfile()
+	},
 }
 
 // Execute runs the root command.
@@ -39,12 +44,14 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "Database file path (default: ~/.gocube_recorder/gocube.db)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (default: the config's profile setting)")
+	rootCmd.PersistentFlags().StringVar(&metricName, "metric", "", "Turn metric for move counts: htm, qtm or stm (default: the profile's metric setting)")
 }
 
-// getDBPath returns the database path from flag or default.
+// getDBPath returns the database path from flag, profile or default.
 func getDBPath() string {
 	if dbPath != "" {
 		return dbPath
 	}
-	return "" // Will use default
+	return activeProfile.DBPath // Empty uses the default
 }
@@ -3,13 +3,14 @@ package cli
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
 )
 
 // ScanForGoCube scans for GoCube devices using the same logic everywhere.
-// It performs a single 5-second scan which is sufficient for macOS BLE discovery.
+// It performs a single scan, 5 seconds unless the profile sets scan_timeout,
+// which is sufficient for macOS BLE discovery. The profile's preferred cube
+// is put first when found.
 func ScanForGoCube() (*ble.Client, []ble.ScanResult, error) {
 	fmt.Println("Scanning for GoCube devices...")
 
@@ -18,10 +19,10 @@ func ScanForGoCube() (*ble.Client, []ble.ScanResult, error) {
 		return nil, nil, fmt.Errorf("BLE not available: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), scanTimeout())
 	defer cancel()
 
-	results, err := client.Scan(ctx, 5*time.Second)
+	results, err := client.Scan(ctx, scanTimeout())
 	if err != nil {
 		return client, nil, fmt.Errorf("scan failed: %w", err)
 	}
@@ -30,12 +31,13 @@ func ScanForGoCube() (*ble.Client, []ble.ScanResult, error) {
 		return client, nil, nil
 	}
 
-	fmt.Printf("Found: %s\n", results[0].Name)
+	preferDevice(results)
+	fmt.Printf("Found: %s (%s)\n", results[0].Name, results[0].Vendor)
 	return client, results, nil
 }
 
 // ScanForGoCubeWithRetry scans for GoCube devices with retries.
-// Uses the same 5-second scan as status, with up to maxAttempts retries.
+// Uses the same scan as status, with up to maxAttempts retries.
 func ScanForGoCubeWithRetry(maxAttempts int) (*ble.Client, []ble.ScanResult, error) {
 	var client *ble.Client
 	var results []ble.ScanResult
@@ -49,8 +51,8 @@ func ScanForGoCubeWithRetry(maxAttempts int) (*ble.Client, []ble.ScanResult, err
 			return nil, nil, fmt.Errorf("BLE not available: %w", err)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		results, err = client.Scan(ctx, 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), scanTimeout())
+		results, err = client.Scan(ctx, scanTimeout())
 		cancel()
 
 		if err != nil {
@@ -59,7 +61,8 @@ func ScanForGoCubeWithRetry(maxAttempts int) (*ble.Client, []ble.ScanResult, err
 		}
 
 		if len(results) > 0 {
-			fmt.Printf("Found: %s\n", results[0].Name)
+			preferDevice(results)
+			fmt.Printf("Found: %s (%s)\n", results[0].Name, results[0].Vendor)
 			return client, results, nil
 		}
 
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/api"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/stream"
+	solvestore "github.com/SeamusWaldron/gocube_ble_library/storage"
+)
+
+var (
+	serveAddr        string
+	serveOrientation bool
+	serveAPI         bool
+	serveToken       string
+	serveOrigins     []string
+	serveTLSCert     string
+	serveTLSKey      string
+)
+
+// Attitude events are smoothed and rate limited so a browser can draw each
+// one as it arrives without jitter.
+const (
+	serveAttitudeSmoothing = 0.5
+	serveAttitudeRate      = 30 // Per second
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Stream live cube events over WebSocket",
+	Long: `Connect to a cube and broadcast its events as JSON over WebSocket, for
+browser overlays and other live tools. Any number of clients can connect
+to ws://<addr>/events at the same time; each message is one event:
+
+  {"type":"move","time":"...","move":"R'"}
+  {"type":"phase","time":"...","phase":"white_cross","phase_name":"White Cross"}
+  {"type":"orientation","time":"...","up":"U","front":"F"}
+  {"type":"attitude","time":"...","quaternion":[0.02,0.71,-0.01,0.70]}
+  {"type":"battery","time":"...","battery":87}
+  {"type":"connection","time":"...","connected":true,"device":"GoCube_1234"}
+
+Attitude events carry the cube's rotation as a smoothed quaternion, at
+most 30 a second, for 3D views. A client that connects later first receives
+the latest phase, orientation, attitude, battery and connection events. The server listens on localhost only unless
+--addr names another interface. Stop it with Ctrl+C.
+
+With --api, the same server also exposes the solve database and recording
+control as a JSON REST API, for web dashboards:
+
+  GET  /api/solves?limit=N       recent solves, newest first
+  GET  /api/solves/{id}          one solve
+  GET  /api/solves/{id}/moves    its moves, scramble included
+  GET  /api/solves/{id}/phases   its phase splits
+  GET  /api/recording            whether a solve is being recorded
+  POST /api/recording/start      start recording {"scramble": "..."}
+  POST /api/recording/inspect    end the scramble; the next move starts the solve
+  POST /api/recording/stop       end the solve early
+  GET  /api/events               the live events as Server-Sent Events
+
+A solve ends by itself when the cube is solved.
+
+Tokens: once tokens are created with 'gocube token create', or one is
+given with --token, every request must send "Authorization: Bearer <token>"
+(or ?token=<token>, for EventSource and WebSocket). Each created token
+carries scopes: stats:read for the GET routes and the events,
+recording:control for the POST routes, and admin for both; --token is an
+admin token. The server refuses to listen beyond localhost without tokens.
+POST requests must send "Content-Type: application/json" or the token
+header.
+
+TLS: with --tls-cert and --tls-key (PEM files) the server speaks HTTPS and
+wss://, so tokens are not sent in the clear on a LAN.
+
+Browsers may only connect from pages of this server, so other web pages
+cannot read the stream or control recording. Let an overlay or dashboard
+served elsewhere in with --allow-origin, e.g. --allow-origin
+http://localhost:3000, or --allow-origin null for a page opened from a file.`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8765", "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveOrientation, "orientation", true, "Stream orientation changes (GoCube only)")
+	serveCmd.Flags().BoolVar(&serveAPI, "api", false, "Also serve the REST API for solves and recording control")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token with the admin scope")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "TLS certificate file (PEM); serve HTTPS with --tls-key")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "TLS private key file (PEM)")
+	serveCmd.Flags().StringSliceVar(&serveOrigins, "allow-origin", nil, "Browser origin allowed to connect besides this server's own (repeatable)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if (serveTLSCert == "") != (serveTLSKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key go together")
+	}
+
+	// Tokens created with 'gocube token create' and the --token one
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	tokens := storage.NewAPITokenRepository(db)
+	tokenCount, err := tokens.Count()
+	if err != nil {
+		return err
+	}
+	if serveToken == "" && tokenCount == 0 && !isLoopbackAddr(serveAddr) {
+		return fmt.Errorf("serving on %s needs a token from 'gocube token create' or --token (only localhost is served without one)", serveAddr)
+	}
+	if serveTLSCert == "" && !isLoopbackAddr(serveAddr) {
+		fmt.Println("Warning: without --tls-cert, tokens and events cross the network in the clear")
+	}
+
+	hub := stream.NewHub()
+	defer hub.Close()
+	hub.AllowOrigins(serveOrigins...)
+
+	var apiServer *api.Server
+	var solveDB *solvestore.DB
+	mux := http.NewServeMux()
+	mux.Handle("/events", hub)
+	if serveAPI {
+		var err error
+		if path := getDBPath(); path != "" {
+			solveDB, err = solvestore.Open(path)
+		} else {
+			solveDB, err = solvestore.OpenDefault()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer solveDB.Close()
+		apiServer = api.NewServer(solveDB, hub)
+		apiServer.AllowOrigins(serveOrigins...)
+		mux.Handle("/api/", apiServer)
+	}
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", serveAddr, err)
+	}
+	var handler http.Handler = mux
+	if serveToken != "" || tokenCount > 0 {
+		handler = api.RequireScopes(serveAuth(tokens), mux)
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		if serveTLSCert != "" {
+			serveErr <- server.ServeTLS(ln, serveTLSCert, serveTLSKey)
+			return
+		}
+		serveErr <- server.Serve(ln)
+	}()
+	defer server.Close()
+
+	fmt.Println("Scanning for a cube...")
+	connectCtx, cancel := context.WithTimeout(ctx, 20*time.Second+scanTimeout())
+	cube, err := gocube.ConnectFirst(connectCtx,
+		gocube.WithScanTimeout(scanTimeout()),
+		gocube.WithPreferredDevice(activeProfile.Device),
+		gocube.WithOrientationSmoothing(serveAttitudeSmoothing),
+		gocube.WithOrientationRate(serveAttitudeRate),
+	)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer cube.Close()
+
+	device := cube.DeviceName()
+	connected := func(ok bool) stream.Event {
+		return stream.Event{Type: stream.EventConnection, Connected: &ok, Device: device}
+	}
+	hub.Broadcast(connected(true))
+
+	var rec *solvestore.Recorder
+	if apiServer != nil {
+		rec = solveDB.NewRecorder(device, "")
+		apiServer.SetRecorder(rec)
+	}
+
+	cube.OnMove(func(m gocube.Move) {
+		hub.Broadcast(stream.Event{Type: stream.EventMove, Time: m.Time, Move: m.Notation()})
+		if rec != nil {
+			if err := rec.Move(m); err != nil {
+				fmt.Printf("Failed to record move: %v\n", err)
+			}
+		}
+	})
+	cube.OnPhaseChange(func(p gocube.Phase) {
+		hub.Broadcast(stream.Event{Type: stream.EventPhase, Phase: p.String(), PhaseName: p.DisplayName()})
+	})
+	cube.OnOrientationChange(func(o gocube.Orientation) {
+		hub.Broadcast(stream.Event{Type: stream.EventOrientation, Up: string(o.UpFace), Front: string(o.FrontFace)})
+		if rec != nil {
+			rec.Orientation(o, time.Time{})
+		}
+	})
+	cube.OnOrientationQuaternion(func(q gocube.Quaternion) {
+		hub.Broadcast(stream.Event{Type: stream.EventAttitude, Quaternion: []float64{q.X, q.Y, q.Z, q.W}})
+	})
+	cube.OnBattery(func(level int) {
+		hub.Broadcast(stream.Event{Type: stream.EventBattery, Battery: &level})
+	})
+	cube.OnDisconnect(func(err error) {
+		hub.Broadcast(connected(false))
+	})
+	cube.OnReconnect(func() {
+		hub.Broadcast(connected(true))
+	})
+
+	if serveOrientation {
+		if err := cube.EnableOrientation(); err != nil {
+			fmt.Printf("Orientation not available: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Connected to %s\n", device)
+	ws, web := "ws", "http"
+	if serveTLSCert != "" {
+		ws, web = "wss", "https"
+	}
+	fmt.Printf("Streaming events on %s://%s/events (Ctrl+C to stop)\n", ws, ln.Addr())
+	if apiServer != nil {
+		fmt.Printf("REST API on %s://%s/api/ (events: /api/events)\n", web, ln.Addr())
+	}
+
+	select {
+	case <-ctx.Done():
+		fmt.Println("\nStopping...")
+		if rec != nil && rec.Recording() {
+			rec.End()
+		}
+		return nil
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server stopped: %w", err)
+	}
+}
+
+// serveAuth authenticates the --token token, with the admin scope, and the
+// tokens created with 'gocube token create', with theirs. Tokens revoked
+// while serving stop working straight away.
+func serveAuth(tokens *storage.APITokenRepository) api.Authenticate {
+	shared := api.TokenAuth(serveToken)
+	return func(got string) ([]string, bool) {
+		if serveToken != "" {
+			if scopes, ok := shared(got); ok {
+				return scopes, true
+			}
+		}
+		t, err := tokens.Lookup(got)
+		if err != nil || t == nil {
+			return nil, false
+		}
+		return t.Scopes, true
+	}
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local
+// connections.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
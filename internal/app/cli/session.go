@@ -0,0 +1,417 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	sessionName      string
+	sessionListLimit int
+	sessionListTable tableOptions
+	sessionStatsJSON bool
+	sessionStatsOnce bool
+)
+
+// sessionRefresh is how often session stats checks for new solves while it
+// follows an open session.
+const sessionRefresh = 2 * time.Second
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Group solves into practice sessions",
+	Long: `A session groups the solves started between "gocube session start" and
+"gocube session end", for averages over one sitting rather than a rolling
+window. Only one session is open at a time.`,
+}
+
+var sessionStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start a practice session",
+	RunE:  runSessionStart,
+}
+
+var sessionEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "End the open practice session",
+	RunE:  runSessionEnd,
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List practice sessions",
+	RunE:  runSessionList,
+}
+
+var sessionStatsCmd = &cobra.Command{
+	Use:   "stats [session-id]",
+	Short: "Show a session's averages",
+	Long: `Show the solves, mean and ao5/ao12/ao100 of a session (the open one, or
+the last one if none is open), current and best.
+
+Averages follow WCA rules: the fastest and slowest 5% of the solves (one
+each for ao5 and ao12, five for ao100) are dropped and the rest averaged. A
+solve that ended without a time counts as a DNF, the slowest result, so an
+average with more DNFs than are dropped is a DNF.
+
+While the session is open and output is a terminal, the stats update as
+solves are recorded until Ctrl+C; --once prints them once.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSessionStats,
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+
+	sessionCmd.AddCommand(sessionStartCmd)
+	sessionStartCmd.Flags().StringVar(&sessionName, "name", "", "Session name (e.g. \"evening OH\")")
+
+	sessionCmd.AddCommand(sessionEndCmd)
+
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionListCmd.Flags().IntVarP(&sessionListLimit, "limit", "n", 20, "Number of sessions to list")
+	addTableFlags(sessionListCmd, &sessionListTable, "")
+
+	sessionCmd.AddCommand(sessionStatsCmd)
+	sessionStatsCmd.Flags().BoolVar(&sessionStatsJSON, "json", false, "Print the stats as JSON")
+	sessionStatsCmd.Flags().BoolVar(&sessionStatsOnce, "once", false, "Print the stats once instead of updating them")
+}
+
+func runSessionStart(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	session, err := storage.NewSessionRepository(db).Start(sessionName, time.Now())
+	if errors.Is(err, storage.ErrSessionOpen) {
+		return fmt.Errorf("%w: end it with \"gocube session end\"", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Session started: %s\n", session.SessionID)
+	fmt.Println("Solves recorded from now on belong to it; follow it with: gocube session stats")
+	return nil
+}
+
+func runSessionEnd(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	session, err := storage.NewSessionRepository(db).End(time.Now())
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("no session is open")
+	}
+
+	stats, err := computeSessionStats(storage.NewSolveRepository(db), session)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Session ended: %s\n\n", session.SessionID)
+	printSessionStats(stats)
+	return nil
+}
+
+func runSessionList(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	sessions, err := storage.NewSessionRepository(db).List(sessionListLimit)
+	if err != nil {
+		return err
+	}
+
+	t := &table{Columns: []tableColumn{
+		{Key: "id", Title: "ID"},
+		{Key: "name", Title: "Name"},
+		{Key: "started", Title: "Started", Format: func(v interface{}) string {
+			return localTime(v.(time.Time)).Format("2006-01-02 15:04")
+		}},
+		{Key: "duration_ms", Title: "Length", Right: true, Format: func(v interface{}) string {
+			return formatDuration(time.Duration(v.(int64)) * time.Millisecond)
+		}},
+		{Key: "solves", Title: "Solves", Right: true},
+		{Key: "ao5_ms", Title: "Best ao5", Right: true, Format: formatSeconds},
+		{Key: "ao12_ms", Title: "Best ao12", Right: true, Format: formatSeconds},
+		{Key: "status", Title: "Status"},
+	}}
+
+	for i := range sessions {
+		s := &sessions[i]
+		stats, err := computeSessionStats(solveRepo, s)
+		if err != nil {
+			return err
+		}
+		row := map[string]interface{}{
+			"id":          s.SessionID,
+			"started":     s.StartedAt,
+			"duration_ms": stats.DurationMs,
+			"solves":      stats.Solves,
+			"status":      "ended",
+		}
+		if s.Name != nil {
+			row["name"] = *s.Name
+		}
+		if s.IsOpen() {
+			row["status"] = "open"
+		}
+		for _, avg := range stats.Averages {
+			if avg.Best != nil && !avg.Best.DNF {
+				row[fmt.Sprintf("ao%d_ms", avg.N)] = avg.Best.Ms
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	if err := t.Apply(sessionListTable); err != nil {
+		return err
+	}
+	if sessionListTable.JSON {
+		return t.WriteJSON(os.Stdout)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No sessions yet")
+		fmt.Println("Start one with: gocube session start")
+		return nil
+	}
+	t.Render(os.Stdout)
+	return nil
+}
+
+func runSessionStats(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	sessionRepo := storage.NewSessionRepository(db)
+	solveRepo := storage.NewSolveRepository(db)
+
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	session, err := findSession(sessionRepo, id)
+	if err != nil {
+		return err
+	}
+
+	stats, err := computeSessionStats(solveRepo, session)
+	if err != nil {
+		return err
+	}
+	if sessionStatsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	printSessionStats(stats)
+	if sessionStatsOnce || !session.IsOpen() || !isTerminal(os.Stdout) {
+		return nil
+	}
+
+	// Follow the open session, reprinting when a solve is added or finishes
+	ctx, stop := interruptContext()
+	defer stop()
+	ticker := time.NewTicker(sessionRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// The session may have been ended from another terminal
+		if session, err = sessionRepo.Get(session.SessionID); err != nil || session == nil {
+			return err
+		}
+		next, err := computeSessionStats(solveRepo, session)
+		if err != nil {
+			return err
+		}
+		if !session.IsOpen() {
+			fmt.Println()
+			printSessionStats(next)
+			return nil
+		}
+		if next.Solves == stats.Solves && next.DNFs == stats.DNFs && next.MeanMs == stats.MeanMs {
+			continue
+		}
+		stats = next
+		fmt.Println()
+		printSessionStats(stats)
+	}
+}
+
+// findSession returns the session with id, or for "" or "current" the open
+// session, or the last one if none is open.
+func findSession(sessionRepo *storage.SessionRepository, id string) (*storage.Session, error) {
+	if id != "" && id != "current" {
+		session, err := sessionRepo.Get(id)
+		if err == nil && session == nil {
+			err = fmt.Errorf("session not found: %s", id)
+		}
+		return session, err
+	}
+
+	session, err := sessionRepo.GetOpen()
+	if err == nil && session == nil {
+		session, err = sessionRepo.GetLast()
+	}
+	if err == nil && session == nil {
+		err = fmt.Errorf("no sessions yet: start one with \"gocube session start\"")
+	}
+	return session, err
+}
+
+// SessionStats is the JSON structure of gocube session stats.
+type SessionStats struct {
+	SessionID  string           `json:"session_id"`
+	Name       string           `json:"name,omitempty"`
+	StartedAt  string           `json:"started_at"`
+	EndedAt    string           `json:"ended_at,omitempty"`
+	DurationMs int64            `json:"duration_ms"`
+	Solves     int              `json:"solves"` // Finished solves, DNFs included
+	DNFs       int              `json:"dnfs"`
+	MeanMs     float64          `json:"mean_ms,omitempty"` // Of the timed solves
+	BestMs     int64            `json:"best_ms,omitempty"`
+	Averages   []SessionAverage `json:"averages"`
+}
+
+// SessionAverage is one average size of a session, now and at its best.
+type SessionAverage struct {
+	N       int               `json:"n"`
+	Current *analysis.Average `json:"current,omitempty"` // nil with fewer than N solves
+	Best    *analysis.Average `json:"best,omitempty"`
+}
+
+// computeSessionStats computes the stats of the solves started in session.
+// Solves still being recorded are left out.
+func computeSessionStats(solveRepo *storage.SolveRepository, session *storage.Session) (*SessionStats, error) {
+	now := time.Now()
+	solves, err := solveRepo.ListBetween(session.StartedAt, session.End(now.Add(time.Second)))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SessionStats{
+		SessionID:  session.SessionID,
+		StartedAt:  session.StartedAt.Format(time.RFC3339),
+		DurationMs: session.End(now).Sub(session.StartedAt).Milliseconds(),
+	}
+	if session.Name != nil {
+		stats.Name = *session.Name
+	}
+	if session.EndedAt != nil {
+		stats.EndedAt = session.EndedAt.Format(time.RFC3339)
+	}
+
+	var times []int64 // Oldest first
+	var sum int64
+	for _, s := range solves {
+		if s.EndedAt == nil {
+			continue
+		}
+		if s.DurationMs == nil || *s.DurationMs <= 0 {
+			times = append(times, analysis.DNF)
+			stats.DNFs++
+			continue
+		}
+		t := *s.DurationMs
+		times = append(times, t)
+		sum += t
+		if stats.BestMs == 0 || t < stats.BestMs {
+			stats.BestMs = t
+		}
+	}
+	stats.Solves = len(times)
+	if timed := stats.Solves - stats.DNFs; timed > 0 {
+		stats.MeanMs = float64(sum) / float64(timed)
+	}
+
+	for _, n := range analysis.AverageSizes {
+		avg := SessionAverage{N: n}
+		if cur, ok := analysis.AverageOf(times, n); ok {
+			avg.Current = &cur
+		}
+		if best, ok := analysis.BestAverageOf(times, n); ok {
+			avg.Best = &best
+		}
+		stats.Averages = append(stats.Averages, avg)
+	}
+	return stats, nil
+}
+
+// printSessionStats prints session stats as a small table of averages.
+func printSessionStats(stats *SessionStats) {
+	title := "Session " + stats.SessionID
+	if stats.Name != "" {
+		title += fmt.Sprintf(" (%s)", stats.Name)
+	}
+	fmt.Println(title)
+
+	started, _ := time.Parse(time.RFC3339, stats.StartedAt)
+	status := "open"
+	if stats.EndedAt != "" {
+		status = "ended"
+	}
+	fmt.Printf("Started: %s, %s for %s\n", localTime(started).Format("2006-01-02 15:04"), status,
+		formatDuration(time.Duration(stats.DurationMs)*time.Millisecond))
+
+	solves := fmt.Sprintf("Solves:  %d", stats.Solves)
+	if stats.DNFs > 0 {
+		solves += fmt.Sprintf(" (%d DNF)", stats.DNFs)
+	}
+	fmt.Println(solves)
+	if stats.Solves == 0 {
+		return
+	}
+	if stats.BestMs > 0 {
+		fmt.Printf("Best:    %.2fs\n", float64(stats.BestMs)/1000)
+		fmt.Printf("Mean:    %.2fs\n", stats.MeanMs/1000)
+	}
+
+	fmt.Println()
+	fmt.Printf("%-6s %9s %9s\n", "", "Current", "Best")
+	for _, avg := range stats.Averages {
+		fmt.Printf("%-6s %9s %9s\n", fmt.Sprintf("ao%d", avg.N), formatAverage(avg.Current), formatAverage(avg.Best))
+	}
+}
+
+// formatAverage formats an average as seconds, "DNF", or "-" when there
+// are too few solves for it.
+func formatAverage(avg *analysis.Average) string {
+	switch {
+	case avg == nil:
+		return "-"
+	case avg.DNF:
+		return "DNF"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.2fs", avg.Ms/1000))
+}
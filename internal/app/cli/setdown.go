@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// loadSetDownConfig returns the set-down settings, or the defaults if the
+// config cannot be read.
+func loadSetDownConfig() recorder.SetDownConfig {
+	cfg, err := recorder.LoadDefaultConfig()
+	if err != nil {
+		return recorder.DefaultConfig().SetDown
+	}
+	return cfg.SetDown
+}
+
+// detectSetDowns finds where the cube was put down between the first and
+// last solving move, from the raw orientation events of the solve.
+func detectSetDowns(eventRepo *storage.EventRepository, solveID string, moveRecords []storage.MoveRecord, segments []storage.PhaseSegment, cfg recorder.SetDownConfig) []analysis.SetDown {
+	offset := solvingOffset(moveRecords, segments)
+	if cfg.MinSeconds <= 0 || offset >= len(moveRecords) {
+		return []analysis.SetDown{}
+	}
+
+	events, err := eventRepo.GetByType(solveID, protocol.TypeName(protocol.MsgTypeOrientation))
+	if err != nil {
+		return []analysis.SetDown{}
+	}
+	var samples []analysis.OrientationSample
+	for _, e := range events {
+		var orient protocol.OrientationEvent
+		if json.Unmarshal([]byte(e.PayloadJSON), &orient) != nil {
+			continue
+		}
+		samples = append(samples, analysis.OrientationSample{TsMs: e.TsMs, X: orient.X, Y: orient.Y, Z: orient.Z, W: orient.W})
+	}
+
+	solving := moveRecords[offset:]
+	moveTimes := make([]int64, len(solving))
+	for i, m := range solving {
+		moveTimes[i] = m.TsMs
+	}
+	startMs, endMs := moveTimes[0], moveTimes[len(moveTimes)-1]
+	return analysis.DetectSetDowns(samples, moveTimes, startMs, endMs, int64(cfg.MinSeconds)*1000)
+}
+
+// applySetDowns adds the set-down intervals to a solve summary and, if
+// configured, the focused solve time.
+func applySetDowns(summary *FullSolveSummary, setDowns []analysis.SetDown, cfg recorder.SetDownConfig) {
+	if len(setDowns) == 0 {
+		return
+	}
+	summary.SetDowns = setDowns
+	summary.SetDownMs = analysis.SetDownTotalMs(setDowns)
+	if cfg.FocusedTime && summary.SolveDurationMs > summary.SetDownMs {
+		summary.FocusedDurationMs = summary.SolveDurationMs - summary.SetDownMs
+	}
+}
+
+// printSolveTime prints the solve time line of a report summary, with the
+// focused time and set-downs when the cube was put down.
+func printSolveTime(summary FullSolveSummary) {
+	fmt.Printf("  Solve time: %.1fs", float64(summary.SolveDurationMs)/1000.0)
+	if len(summary.SetDowns) > 0 {
+		if summary.FocusedDurationMs > 0 {
+			fmt.Printf(" (focused: %.1fs)", float64(summary.FocusedDurationMs)/1000.0)
+		}
+		fmt.Printf("\n  Put down: %dx, %.1fs in total", len(summary.SetDowns), float64(summary.SetDownMs)/1000.0)
+	}
+	fmt.Println()
+}
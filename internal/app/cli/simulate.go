@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+var (
+	simScramble     string
+	simSolve        string
+	simTPS          float64
+	simSeed         int64
+	simInspectionMs int64
+	simRealtime     bool
+	simNoReport     bool
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Record a synthetic solve without hardware",
+	Long: `Drive the full recording pipeline (tracker, recorder, report) from a
+synthetic solve, for demos, screenshots and load testing.
+
+Moves are encoded as GoCube rotation messages and fed through the same
+session code as a real cube, with human-like timing: jittered turn gaps,
+occasional hesitations and recognition pauses when a new phase starts.
+
+--solve auto solves the scrambled cube with the bundled solver (usually
+18-20 moves for a full scramble); pass an explicit move sequence to
+simulate a specific solution.
+
+Timestamps are simulated, so a 40s solve records instantly. Use --realtime
+to play it back at human speed.`,
+	RunE: runSimulate,
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+	simulateCmd.Flags().StringVar(&simScramble, "scramble", "", "Scramble sequence (required)")
+	simulateCmd.Flags().StringVar(&simSolve, "solve", "auto", "Solution moves, or 'auto' to use the solver's")
+	simulateCmd.Flags().Float64Var(&simTPS, "tps", 2.5, "Average turns per second")
+	simulateCmd.Flags().Int64Var(&simSeed, "seed", 0, "Random seed for timing noise (default: time-based)")
+	simulateCmd.Flags().Int64Var(&simInspectionMs, "inspection", 8000, "Inspection time in milliseconds")
+	simulateCmd.Flags().BoolVar(&simRealtime, "realtime", false, "Play the solve back in real time")
+	simulateCmd.Flags().BoolVar(&simNoReport, "no-report", false, "Skip report generation")
+	simulateCmd.MarkFlagRequired("scramble")
+}
+
+// simulationMoves parses the scramble and the solution ("auto" for the
+// solver's), as the outer-face turns the cube reports, and checks that the
+// solution solves the scramble.
+func simulationMoves(scrambleText, solveText string) (scramble, solution []gocube.Move, err error) {
+	if scramble, err = analysis.ParseMovesStrict(scrambleText); err != nil {
+		return nil, nil, fmt.Errorf("invalid --scramble: %w", err)
+	}
+	if len(scramble) == 0 {
+		return nil, nil, fmt.Errorf("--scramble must contain at least one move")
+	}
+
+	// The cube only reports outer-face turns, so slice and wide moves and
+	// rotations are fed as those. An explicit solution continues from
+	// however the scramble left the cube turned; the solver's is already in
+	// outer-face turns.
+	if solveText == "auto" {
+		scramble = gocube.ExpandMoves(scramble)
+		solution = gocube.SolveSequence(scramble)
+		if len(solution) == 0 {
+			return nil, nil, fmt.Errorf("--scramble leaves the cube solved")
+		}
+	} else {
+		if solution, err = analysis.ParseMovesStrict(solveText); err != nil {
+			return nil, nil, fmt.Errorf("invalid --solve: %w", err)
+		}
+		all := gocube.ExpandMoves(append(append([]gocube.Move{}, scramble...), solution...))
+		scramble = gocube.ExpandMoves(scramble)
+		solution = all[len(scramble):]
+	}
+
+	// The solution must actually solve the scrambled cube
+	check := gocube.NewCube()
+	check.Apply(scramble...)
+	check.Apply(solution...)
+	if !check.IsSolved() {
+		return nil, nil, fmt.Errorf("--solve does not solve the scramble")
+	}
+	return scramble, solution, nil
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	scramble, solution, err := simulationMoves(simScramble, simSolve)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	seed := simSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	timing := recorder.NewSimTiming(simTPS, seed)
+
+	session := recorder.NewSession(db, nil)
+	clock := recorder.NewSimClock(time.Now())
+	wait := clock.Advance
+	if simRealtime {
+		wait = time.Sleep
+	} else {
+		session.SetClock(clock.Now)
+	}
+
+	solveID, err := session.Start("simulated", gocube.FormatMoves(scramble), "GoCube Simulator", "simulator", version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Simulating solve %s (seed %d)\n", solveID[:8], seed)
+
+	tracker := gocube.NewCube()
+	feed := func(m gocube.Move) error {
+		msg, err := recorder.RotationMessage(m)
+		if err != nil {
+			return err
+		}
+		if err := session.HandleMessage(msg); err != nil {
+			return err
+		}
+		// Apply what the cube reported, exactly as the record TUI does
+		rotations, err := protocol.DecodeRotation(msg.Payload)
+		if err != nil {
+			return err
+		}
+		tracker.Apply(rotationsToMoves(rotations, time.Now())...)
+		return nil
+	}
+
+	// Scramble at a brisk, steady pace
+	for _, m := range scramble {
+		wait(150 * time.Millisecond)
+		if err := feed(m); err != nil {
+			return err
+		}
+	}
+
+	if err := session.MarkPhase("inspection", nil); err != nil {
+		return err
+	}
+	wait(time.Duration(simInspectionMs) * time.Millisecond)
+
+	// Solve, marking phases on each new highest phase like the record TUI
+	highest := tracker.Phase()
+	newPhase := true
+	for i, m := range solution {
+		wait(timing.Gap(newPhase))
+		newPhase = false
+
+		if i == 0 {
+			ts := session.CurrentTimestamp() - 1
+			if ts < 0 {
+				ts = 0
+			}
+			if err := session.MarkPhaseAt("white_cross", ts, nil); err != nil {
+				return err
+			}
+		}
+
+		if err := feed(m); err != nil {
+			return err
+		}
+
+		phase := tracker.Phase()
+		if phase > highest && phase != gocube.PhaseScrambled && phase != gocube.PhaseWhiteCross {
+			if err := session.MarkPhase(phaseToKey(phase), nil); err != nil {
+				return err
+			}
+			highest = phase
+			newPhase = true
+		}
+	}
+
+	if err := session.End(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded %d scramble + %d solve moves\n", len(scramble), len(solution))
+	if summary, err := solveSummaryLine(db, solveID, nil); err == nil {
+		fmt.Printf("Summary: %s\n", summary)
+	}
+
+	if simNoReport {
+		return nil
+	}
+
+	reportDir, err := GenerateReportForSolve(db, solveID)
+	if err != nil {
+		return fmt.Errorf("report generation failed: %w", err)
+	}
+	fmt.Printf("Report: %s\n", reportDir)
+
+	return nil
+}
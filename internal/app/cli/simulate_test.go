@@ -0,0 +1,66 @@
+//go:build !js
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+func TestSimulationMovesAuto(t *testing.T) {
+	const scramble = "R U R' F2 D L B2 U' F R2 D' B L2"
+	moves, solution, err := simulationMoves(scramble, "auto")
+	if err != nil {
+		t.Fatalf("simulationMoves: %v", err)
+	}
+	want := gocube.SolveSequence(moves)
+	if gocube.FormatMoves(solution) != gocube.FormatMoves(want) {
+		t.Errorf("auto solution = %s, want the solver's %s", gocube.FormatMoves(solution), gocube.FormatMoves(want))
+	}
+
+	// Rotations in the scramble leave the solver's outer turns alone
+	moves, solution, err = simulationMoves("x "+scramble+" y", "auto")
+	if err != nil {
+		t.Fatalf("simulationMoves with rotations: %v", err)
+	}
+	cube := gocube.NewCube()
+	cube.Apply(moves...)
+	cube.Apply(solution...)
+	if !cube.IsSolved() {
+		t.Errorf("auto solution %s does not solve %s", gocube.FormatMoves(solution), gocube.FormatMoves(moves))
+	}
+
+	if _, _, err := simulationMoves("R R'", "auto"); err == nil {
+		t.Error("simulationMoves of a scramble that leaves the cube solved succeeded")
+	}
+	if _, _, err := simulationMoves(scramble, "R U"); err == nil {
+		t.Error("simulationMoves with a wrong solution succeeded")
+	}
+}
+
+func TestSimulateReportPerSolve(t *testing.T) {
+	dir := t.TempDir()
+	defer func(db, scramble, solve string, seed int64, reports string) {
+		dbPath, simScramble, simSolve, simSeed, activeProfile.ReportDir = db, scramble, solve, seed, reports
+	}(dbPath, simScramble, simSolve, simSeed, activeProfile.ReportDir)
+	dbPath = filepath.Join(dir, "gocube.db")
+	activeProfile.ReportDir = filepath.Join(dir, "reports")
+	simScramble, simSolve, simSeed = "R U F", "auto", 7
+
+	// Two solves started in the same second get a report each
+	for i := 0; i < 2; i++ {
+		if err := runSimulate(simulateCmd, nil); err != nil {
+			t.Fatalf("runSimulate: %v", err)
+		}
+	}
+	entries, err := os.ReadDir(activeProfile.ReportDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d report directories, want 2", len(entries))
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+)
+
+var (
+	soakEvents        int
+	soakMovesPerSolve int
+	soakSeed          int64
+	soakKeepDB        bool
+	soakMaxHeapMB     float64
+	soakMaxP99Ms      float64
+	soakMaxBacklog    int64
+	soakMinRate       float64
+	soakChaos         ble.ChaosConfig
+	soakChaosMaxDelay int64
+)
+
+var soakCmd = &cobra.Command{
+	Use:   "soak",
+	Short: "Stress the recorder and storage hot path",
+	Long: `Feed a large number of synthetic move events through the recorder and
+storage path, then report memory growth, database size, write latency and
+move callback backlog.
+
+Each threshold that is exceeded is reported and the command exits non-zero,
+so it can guard against regressions in CI.
+
+Unless --db is given, a throwaway database is created in a temp directory
+and removed afterwards (keep it with --keep-db).
+
+The --chaos-* flags send the stream as notifications of a mock cube over a
+faulty link that drops, duplicates, reorders and delays them like a
+misbehaving BLE connection, through the same BLE client a real cube uses. After each solve the state rebuilt from the stored moves and
+checkpoints is compared with a tracker fed the delivered messages; any
+disagreement fails the run.
+
+  gocube soak --events 20000 --chaos-drop 0.02 --chaos-dup 0.02 \
+    --chaos-reorder 0.05 --chaos-delay 0.05`,
+	RunE: runSoak,
+}
+
+func init() {
+	rootCmd.AddCommand(soakCmd)
+	soakCmd.Flags().IntVar(&soakEvents, "events", 100000, "Number of move events to generate")
+	soakCmd.Flags().IntVar(&soakMovesPerSolve, "moves-per-solve", 100, "Events per synthetic solve")
+	soakCmd.Flags().Int64Var(&soakSeed, "seed", 1, "Random seed for the move stream")
+	soakCmd.Flags().BoolVar(&soakKeepDB, "keep-db", false, "Keep the temporary database after the run")
+	soakCmd.Flags().Float64Var(&soakMaxHeapMB, "max-heap-growth", 64, "Fail if live heap grows by more than this many MB (0 = no limit)")
+	soakCmd.Flags().Float64Var(&soakMaxP99Ms, "max-p99", 50, "Fail if p99 write latency exceeds this many ms (0 = no limit)")
+	soakCmd.Flags().Int64Var(&soakMaxBacklog, "max-backlog", 1000, "Fail if pending move callbacks exceed this (0 = no limit)")
+	soakCmd.Flags().Float64Var(&soakMinRate, "min-rate", 0, "Fail if throughput drops below this many events/s (0 = no limit)")
+	soakCmd.Flags().Float64Var(&soakChaos.Drop, "chaos-drop", 0, "Probability of dropping a notification")
+	soakCmd.Flags().Float64Var(&soakChaos.Duplicate, "chaos-dup", 0, "Probability of duplicating a frame")
+	soakCmd.Flags().Float64Var(&soakChaos.Reorder, "chaos-reorder", 0, "Probability of swapping a frame with the next")
+	soakCmd.Flags().Float64Var(&soakChaos.Delay, "chaos-delay", 0, "Probability of delaying a frame")
+	soakCmd.Flags().Int64Var(&soakChaosMaxDelay, "chaos-max-delay", 500, "Longest delay in milliseconds")
+}
+
+func runSoak(cmd *cobra.Command, args []string) error {
+	if soakEvents <= 0 {
+		return fmt.Errorf("--events must be positive")
+	}
+	for name, p := range map[string]float64{
+		"--chaos-drop": soakChaos.Drop, "--chaos-dup": soakChaos.Duplicate,
+		"--chaos-reorder": soakChaos.Reorder, "--chaos-delay": soakChaos.Delay,
+	} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	soakChaos.MaxDelay = time.Duration(soakChaosMaxDelay) * time.Millisecond
+	soakChaos.Seed = soakSeed
+
+	path := getDBPath()
+	if path == "" {
+		dir, err := os.MkdirTemp("", "gocube-soak-")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		if !soakKeepDB {
+			defer os.RemoveAll(dir)
+		}
+		path = filepath.Join(dir, "soak.db")
+	}
+
+	db, err := storage.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	if err := db.MigrateUp(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	fmt.Printf("Soak: %d events, %d per solve, db %s\n", soakEvents, soakMovesPerSolve, path)
+
+	progressStart := time.Now()
+	result, err := recorder.RunSoak(db, recorder.SoakConfig{
+		Events:        soakEvents,
+		MovesPerSolve: soakMovesPerSolve,
+		Seed:          soakSeed,
+		ProgressEvery: progressInterval(soakEvents),
+		Chaos:         soakChaos,
+		Progress: func(done int) {
+			rate := float64(done) / time.Since(progressStart).Seconds()
+			fmt.Printf("  %d/%d events (%.0f/s)\n", done, soakEvents, rate)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("soak failed after %d events: %w", result.Events, err)
+	}
+
+	heapMB := float64(result.HeapGrowth()) / (1024 * 1024)
+	p99Ms := float64(result.LatencyP99) / float64(time.Millisecond)
+
+	fmt.Println()
+	fmt.Printf("Events:       %d in %d solves\n", result.Events, result.Solves)
+	fmt.Printf("Elapsed:      %s (%.0f events/s)\n", result.Elapsed.Round(time.Millisecond), result.EventsPerSecond())
+	fmt.Printf("Heap growth:  %.2f MB\n", heapMB)
+	fmt.Printf("DB size:      %.2f MB (%.0f bytes/event)\n",
+		float64(result.DBBytes)/(1024*1024), float64(result.DBBytes)/float64(result.Events))
+	fmt.Printf("Write p50:    %s\n", result.LatencyP50)
+	fmt.Printf("Write p99:    %s\n", result.LatencyP99)
+	fmt.Printf("Write max:    %s\n", result.LatencyMax)
+	fmt.Printf("Max backlog:  %d callbacks\n", result.MaxBacklog)
+	if soakChaos.Enabled() {
+		c := result.Chaos
+		fmt.Printf("Chaos:        %d sent, %d delivered (%d dropped, %d duplicated, %d reordered, %d delayed)\n",
+			c.Sent, c.Delivered, c.Dropped, c.Duplicated, c.Reordered, c.Delayed)
+		fmt.Printf("Consistency:  %d of %d solves diverged\n", result.Inconsistent, result.Solves)
+	}
+
+	var failures []string
+	if soakMaxHeapMB > 0 && heapMB > soakMaxHeapMB {
+		failures = append(failures, fmt.Sprintf("heap growth %.2f MB > %.2f MB", heapMB, soakMaxHeapMB))
+	}
+	if soakMaxP99Ms > 0 && p99Ms > soakMaxP99Ms {
+		failures = append(failures, fmt.Sprintf("p99 latency %.2f ms > %.2f ms", p99Ms, soakMaxP99Ms))
+	}
+	if soakMaxBacklog > 0 && result.MaxBacklog > soakMaxBacklog {
+		failures = append(failures, fmt.Sprintf("callback backlog %d > %d", result.MaxBacklog, soakMaxBacklog))
+	}
+	if soakMinRate > 0 && result.EventsPerSecond() < soakMinRate {
+		failures = append(failures, fmt.Sprintf("throughput %.0f/s < %.0f/s", result.EventsPerSecond(), soakMinRate))
+	}
+	if result.Inconsistent > 0 {
+		failures = append(failures, fmt.Sprintf("%d solve(s) recorded a different state than the tracker saw", result.Inconsistent))
+	}
+
+	fmt.Println()
+	if len(failures) > 0 {
+		for _, f := range failures {
+			fmt.Printf("FAIL: %s\n", f)
+		}
+		return fmt.Errorf("soak test failed %d threshold(s)", len(failures))
+	}
+	fmt.Println("PASS")
+
+	return nil
+}
+
+// progressInterval returns how often to print progress (about 10 updates).
+func progressInterval(total int) int {
+	if total < 10 {
+		return 0
+	}
+	return total / 10
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/SeamusWaldron/gocube_ble_library"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 )
@@ -16,7 +18,11 @@ var (
 	phaseKey      string
 	phaseNotes    string
 	listLimit     int
+	listTable     tableOptions
 	showLast      bool
+	showAt        int
+	deleteLast    bool
+	deleteSafety  safetyOptions
 )
 
 var solveCmd = &cobra.Command{
@@ -28,8 +34,13 @@ var solveCmd = &cobra.Command{
 var solveStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start a new solve recording",
-	Long:  `Start a new solve recording session. The solve will begin recording moves from the GoCube.`,
-	RunE:  runSolveStart,
+	Long: `Start a new solve recording session. The solve will begin recording moves from the GoCube.
+
+With --scramble, the moves recorded until the next phase mark are checked
+against the scramble as it is applied; 'gocube solve record --continue' shows
+each mistake as it is made ("expected F2, you did F") and how to fix it.
+The solve is marked verified if the scramble was applied exactly.`,
+	RunE: runSolveStart,
 }
 
 var solveEndCmd = &cobra.Command{
@@ -69,16 +80,29 @@ var solveShowCmd = &cobra.Command{
 - Phase breakdown with timing
 - Move sequence
 
-Use --last to show the most recent solve.`,
+Use --last to show the most recent solve. Use --at N to show the cube
+state after the first N moves instead (seeks from the nearest stored
+checkpoint, so it stays fast for long captures).`,
 	RunE: runSolveShow,
 }
 
+var solveDeleteCmd = &cobra.Command{
+	Use:   "delete [solve-id...]",
+	Short: "Delete solves and all their data",
+	Long: `Delete one or more solves together with their moves, events, phases,
+orientations, context, checkpoints and analysis rows.
+
+The rows that would be removed are listed first. Use --dry-run to stop
+there, and --yes to skip the confirmation prompt in scripts.`,
+	RunE: runSolveDelete,
+}
+
 func init() {
 	rootCmd.AddCommand(solveCmd)
 
 	solveCmd.AddCommand(solveStartCmd)
 	solveStartCmd.Flags().StringVar(&solveNotes, "notes", "", "Notes for this solve")
-	solveStartCmd.Flags().StringVar(&solveScramble, "scramble", "", "Scramble sequence used")
+	solveStartCmd.Flags().StringVar(&solveScramble, "scramble", "", "Scramble sequence to apply, checked move by move")
 
 	solveCmd.AddCommand(solveEndCmd)
 
@@ -89,9 +113,15 @@ func init() {
 
 	solveCmd.AddCommand(solveListCmd)
 	solveListCmd.Flags().IntVar(&listLimit, "limit", 20, "Maximum number of solves to display")
+	addTableFlags(solveListCmd, &listTable, "")
 
 	solveCmd.AddCommand(solveShowCmd)
 	solveShowCmd.Flags().BoolVar(&showLast, "last", false, "Show the most recent solve")
+	solveShowCmd.Flags().IntVar(&showAt, "at", -1, "Show the cube state after this many moves")
+
+	solveCmd.AddCommand(solveDeleteCmd)
+	solveDeleteCmd.Flags().BoolVar(&deleteLast, "last", false, "Delete the most recent solve")
+	addSafetyFlags(solveDeleteCmd, &deleteSafety)
 }
 
 func runSolveStart(cmd *cobra.Command, args []string) error {
@@ -115,6 +145,8 @@ func runSolveStart(cmd *cobra.Command, args []string) error {
 
 	// Create session
 	session := recorder.NewSession(db, stateFile)
+	loadConfiguredHooks(session)
+	defer session.WaitHooks()
 
 	// Get device info if available
 	state := stateFile.State()
@@ -129,6 +161,14 @@ func runSolveStart(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Started solve: %s\n", solveID)
 	fmt.Println()
+	if solveScramble != "" {
+		if _, err := gocube.ParseMoves(solveScramble); err != nil {
+			fmt.Printf("Scramble not checked: %v\n\n", err)
+		} else {
+			fmt.Println("Apply the scramble with 'gocube solve record --continue' to check it move by move")
+			fmt.Println()
+		}
+	}
 	fmt.Println("Phase marking:")
 	fmt.Println("  gocube solve phase --phase white_cross")
 	fmt.Println("  gocube solve phase --phase white_corners")
@@ -165,6 +205,8 @@ func runSolveEnd(cmd *cobra.Command, args []string) error {
 
 	// Create session and resume
 	session := recorder.NewSession(db, stateFile)
+	loadConfiguredHooks(session)
+	defer session.WaitHooks()
 	if err := session.Resume(solveID); err != nil {
 		return fmt.Errorf("failed to resume solve: %w", err)
 	}
@@ -181,19 +223,21 @@ func runSolveEnd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get solve: %w", err)
 	}
 
-	moveCount, _ := solveRepo.GetMoveCount(solveID)
+	moveCount := solveMoveCount(storage.NewMoveRepository(db), solveID)
 
 	fmt.Printf("Solve ended: %s\n", solveID)
 	fmt.Println()
 	if solve != nil && solve.DurationMs != nil {
 		duration := time.Duration(*solve.DurationMs) * time.Millisecond
 		fmt.Printf("Duration: %s\n", formatDuration(duration))
-		fmt.Printf("Moves: %d\n", moveCount)
+		fmt.Printf("Moves: %d%s\n", moveCount, metricLabel())
 		if *solve.DurationMs > 0 {
 			tps := float64(moveCount) / (float64(*solve.DurationMs) / 1000.0)
 			fmt.Printf("TPS: %.2f\n", tps)
 		}
 	}
+	printNewAchievements(db)
+	printNewPersonalBests(db)
 	fmt.Println()
 	fmt.Printf("Generate report: gocube report solve --id %s\n", solveID)
 
@@ -227,6 +271,8 @@ func runSolvePhase(cmd *cobra.Command, args []string) error {
 
 	// Create session and resume
 	session := recorder.NewSession(db, stateFile)
+	loadConfiguredHooks(session)
+	defer session.WaitHooks()
 	solveID := stateFile.ActiveSolveID()
 	if err := session.Resume(solveID); err != nil {
 		return fmt.Errorf("failed to resume solve: %w", err)
@@ -257,64 +303,78 @@ func runSolveList(cmd *cobra.Command, args []string) error {
 	defer db.Close()
 
 	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
 	solves, err := solveRepo.List(listLimit)
 	if err != nil {
 		return fmt.Errorf("failed to list solves: %w", err)
 	}
 
-	if len(solves) == 0 {
-		fmt.Println("No solves recorded yet")
-		fmt.Println("Start a new solve with: gocube solve start")
-		return nil
-	}
-
-	fmt.Printf("Recent solves (showing %d):\n", len(solves))
-	fmt.Println()
-	fmt.Printf("%-36s  %-20s  %-10s  %-6s  %-6s  %s\n", "ID", "Started", "Duration", "Moves", "TPS", "Notes")
-	fmt.Println("------------------------------------  --------------------  ----------  ------  ------  -----")
+	t := &table{Columns: []tableColumn{
+		{Key: "id", Title: "ID"},
+		{Key: "started", Title: "Started", Format: func(v interface{}) string {
+			return localTime(v.(time.Time)).Format("2006-01-02 15:04:05")
+		}},
+		{Key: "duration_ms", Title: "Duration", Right: true, Format: func(v interface{}) string {
+			return formatDuration(time.Duration(v.(int64)) * time.Millisecond)
+		}},
+		{Key: "moves", Title: "Moves", Right: true},
+		{Key: "tps", Title: "TPS", Right: true, Format: formatFloat(2)},
+		{Key: "status", Title: "Status"},
+		{Key: "notes", Title: "Notes", Format: func(v interface{}) string {
+			return truncateString(v.(string), 30)
+		}},
+	}}
 
 	for _, s := range solves {
-		duration := "-"
-		moves := "-"
-		tps := "-"
+		row := map[string]interface{}{
+			"id":      s.SolveID,
+			"started": s.StartedAt,
+			"status":  "done",
+		}
 
 		if s.DurationMs != nil {
-			d := time.Duration(*s.DurationMs) * time.Millisecond
-			duration = formatDuration(d)
+			row["duration_ms"] = *s.DurationMs
 		}
 
-		moveCount, _ := solveRepo.GetMoveCount(s.SolveID)
+		moveCount := solveMoveCount(moveRepo, s.SolveID)
 		if moveCount > 0 {
-			moves = fmt.Sprintf("%d", moveCount)
+			row["moves"] = moveCount
 			if s.DurationMs != nil && *s.DurationMs > 0 {
-				tps = fmt.Sprintf("%.2f", float64(moveCount)/(float64(*s.DurationMs)/1000.0))
+				row["tps"] = float64(moveCount) / (float64(*s.DurationMs) / 1000.0)
 			}
 		}
 
-		notes := ""
 		if s.Notes != nil {
-			notes = *s.Notes
-			if len(notes) > 30 {
-				notes = notes[:27] + "..."
-			}
+			row["notes"] = *s.Notes
 		}
 
-		status := ""
 		if s.EndedAt == nil {
-			status = " (active)"
+			row["status"] = "active"
+		} else if s.IsManual() {
+			row["status"] = "manual"
 		}
 
-		fmt.Printf("%-36s  %-20s  %-10s  %-6s  %-6s  %s%s\n",
-			s.SolveID,
-			s.StartedAt.Format("2006-01-02 15:04:05"),
-			duration,
-			moves,
-			tps,
-			notes,
-			status,
-		)
+		t.Rows = append(t.Rows, row)
 	}
 
+	if err := t.Apply(listTable); err != nil {
+		return err
+	}
+
+	if listTable.JSON {
+		return t.WriteJSON(os.Stdout)
+	}
+
+	if len(solves) == 0 {
+		fmt.Println("No solves recorded yet")
+		fmt.Println("Start a new solve with: gocube solve start")
+		return nil
+	}
+
+	fmt.Printf("Recent solves (showing %d):\n", len(solves))
+	fmt.Println()
+	t.Render(os.Stdout)
+
 	return nil
 }
 
@@ -367,6 +427,7 @@ func runSolveShow(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get phases: %w", err)
 	}
+	segments = metricSegments(segments, moves)
 
 	// Display header
 	fmt.Println("Solve Details")
@@ -375,26 +436,58 @@ func runSolveShow(cmd *cobra.Command, args []string) error {
 
 	// Basic info
 	fmt.Printf("ID:      %s\n", solve.SolveID)
-	fmt.Printf("Started: %s\n", solve.StartedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Started: %s\n", localTime(solve.StartedAt).Format("2006-01-02 15:04:05"))
 	if solve.EndedAt != nil {
-		fmt.Printf("Ended:   %s\n", solve.EndedAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("Ended:   %s\n", localTime(*solve.EndedAt).Format("2006-01-02 15:04:05"))
 	}
 	if solve.Notes != nil && *solve.Notes != "" {
 		fmt.Printf("Notes:   %s\n", *solve.Notes)
 	}
+	if solve.ScrambleText != nil && *solve.ScrambleText != "" {
+		verified := ""
+		if solve.ScrambleVerified {
+			verified = " (verified)"
+		}
+		fmt.Printf("Scramble: %s%s\n", *solve.ScrambleText, verified)
+	}
+	if solve.CubeType != nil {
+		fmt.Printf("Cube:    %s\n", *solve.CubeType)
+	}
+	if context, err := storage.NewContextRepository(db).Get(solveID); err == nil && len(context) > 0 {
+		fmt.Printf("Context: %s\n", formatContext(context))
+	}
+	if changes, err := recorder.LoadDeviceChanges(storage.NewEventRepository(db), solveID); err == nil {
+		for _, c := range changes {
+			fmt.Printf("Device:  switched to %s after %d moves (%.1fs)\n", c.ToName, c.MoveIndex, float64(c.TsMs)/1000)
+		}
+	}
 	fmt.Println()
 
-	// Calculate actual solve time (excluding scramble and inspection)
-	var solveDurationMs int64
-	var solveMoves int
-	for _, seg := range segments {
-		// Only count phases after inspection (actual solving)
-		if seg.PhaseKey != "scramble" && seg.PhaseKey != "inspection" {
-			solveDurationMs += seg.DurationMs
-			solveMoves += seg.MoveCount
+	// Manual solves only have a time
+	if solve.IsManual() {
+		fmt.Println("Manually timed solve (no move data)")
+		if solve.DurationMs != nil {
+			fmt.Printf("Solve Time: %s\n", formatDuration(time.Duration(*solve.DurationMs)*time.Millisecond))
+		}
+		return nil
+	}
+
+	if showAt >= 0 {
+		if showAt > len(moves) {
+			return fmt.Errorf("--at %d is past the last move (%d moves)", showAt, len(moves))
+		}
+		cube, err := recorder.StateAt(db, solveID, showAt)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild cube state: %w", err)
 		}
+		fmt.Printf("Cube state after %d of %d moves (%s)\n\n", showAt, len(moves), cube.Phase())
+		fmt.Print(cube.Render(defaultCubeRenderOptions()))
+		return nil
 	}
 
+	// Calculate actual solve time (excluding scramble and inspection)
+	solveDurationMs, solveMoves := solvingTime(segments)
+
 	// Stats
 	fmt.Println("Statistics")
 	fmt.Println("----------")
@@ -406,7 +499,7 @@ func runSolveShow(cmd *cobra.Command, args []string) error {
 			fmt.Printf("TPS:        %.2f\n", tps)
 		}
 	}
-	fmt.Printf("Moves:      %d\n", solveMoves)
+	fmt.Printf("Moves:      %d%s\n", solveMoves, metricLabel())
 	if solve.DurationMs != nil {
 		sessionDuration := time.Duration(*solve.DurationMs) * time.Millisecond
 		fmt.Printf("Session:    %s (includes scramble/inspection)\n", formatDuration(sessionDuration))
@@ -480,6 +573,91 @@ func runSolveShow(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runSolveDelete(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+
+	ids := args
+	if deleteLast {
+		solves, err := solveRepo.List(1)
+		if err != nil {
+			return fmt.Errorf("failed to get latest solve: %w", err)
+		}
+		if len(solves) == 0 {
+			return fmt.Errorf("no solves found")
+		}
+		ids = append(ids, solves[0].SolveID)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("please provide a solve ID or use --last")
+	}
+
+	stateFile, err := recorder.NewDefaultStateFile()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	// Resolve everything before deleting anything
+	var solves []*storage.Solve
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		solve, err := solveRepo.Get(id)
+		if err != nil {
+			return fmt.Errorf("failed to get solve: %w", err)
+		}
+		if solve == nil {
+			return fmt.Errorf("solve not found: %s", id)
+		}
+		if stateFile.HasActiveSolve() && stateFile.ActiveSolveID() == id {
+			return fmt.Errorf("solve %s is still being recorded\nUse 'gocube solve end' to finish it first", id)
+		}
+		solves = append(solves, solve)
+	}
+
+	fmt.Printf("%d solve(s) to delete:\n", len(solves))
+	for _, s := range solves {
+		duration := "-"
+		if s.DurationMs != nil {
+			duration = formatDuration(time.Duration(*s.DurationMs) * time.Millisecond)
+		}
+		fmt.Printf("  %s  %s  %s\n", s.SolveID, localTime(s.StartedAt).Format("2006-01-02 15:04:05"), duration)
+
+		related, err := solveRepo.CountRelated(s.SolveID)
+		if err != nil {
+			return err
+		}
+		for _, r := range related {
+			fmt.Printf("      %-24s %d rows\n", r.Table, r.Rows)
+		}
+	}
+
+	if deleteSafety.DryRun {
+		fmt.Println("Dry run: nothing deleted.")
+		return nil
+	}
+	if err := confirm(deleteSafety, fmt.Sprintf("Delete %d solve(s)?", len(solves))); err != nil {
+		return err
+	}
+
+	for _, s := range solves {
+		if err := solveRepo.Delete(s.SolveID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Deleted %d solve(s)\n", len(solves))
+	return nil
+}
+
 func openDB() (*storage.DB, error) {
 	path := getDBPath()
 	var db *storage.DB
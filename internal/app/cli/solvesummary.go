@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// solveSummaryLine builds the one-line summary shown when a solve ends:
+// solve time (excluding scramble and inspection, as in reports), ao5 change, slowest phase against its budget, and one tip from the
+// diagnostics. budget may be nil when no pacing budgets are configured.
+func solveSummaryLine(db *storage.DB, solveID string, budget func(string) (time.Duration, bool)) (string, error) {
+	solveRepo := storage.NewSolveRepository(db)
+	solve, err := solveRepo.Get(solveID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil || solve.DurationMs == nil {
+		return "", fmt.Errorf("solve %s has not ended", solveID[:8])
+	}
+
+	phaseRepo := storage.NewPhaseRepository(db)
+	timeMs, err := solveTimeMs(phaseRepo, *solve)
+	if err != nil {
+		return "", err
+	}
+	parts := []string{fmt.Sprintf("%.2fs", float64(timeMs)/1000)}
+
+	ao5, err := ao5Delta(solveRepo, phaseRepo)
+	if err != nil {
+		return "", err
+	}
+	if ao5 != "" {
+		parts = append(parts, ao5)
+	}
+
+	diag, err := analysis.AnalyzeDiagnostics(solveID,
+		storage.NewMoveRepository(db), phaseRepo, storage.NewOrientationRepository(db))
+	if err != nil {
+		return "", err
+	}
+	var phases []analysis.PhaseDiagnostics
+	for _, p := range diag.Phases {
+		if storage.IsSolvingPhase(p.PhaseKey) {
+			phases = append(phases, p)
+		}
+	}
+	if slowest := slowestPhase(phases, budget); slowest != "" {
+		parts = append(parts, slowest)
+	}
+	if tip := diagnosticTip(phases, diag.Orientation); tip != "" {
+		parts = append(parts, "tip: "+tip)
+	}
+
+	return strings.Join(parts, " | "), nil
+}
+
+// solveTimeMs returns the time of an ended solve from its solving phases,
+// as reports time it. Solves without phases, such as manual ones, are
+// timed by their duration.
+func solveTimeMs(phaseRepo *storage.PhaseRepository, solve storage.Solve) (int64, error) {
+	segments, err := phaseRepo.GetPhaseSegments(solve.SolveID)
+	if err != nil {
+		return 0, err
+	}
+	if ms, _ := solvingTime(segments); ms > 0 {
+		return ms, nil
+	}
+	if solve.DurationMs == nil {
+		return 0, nil
+	}
+	return *solve.DurationMs, nil
+}
+
+// ao5Delta returns the current ao5 of solve times and its change from the
+// ao5 before the latest solve, or "" with fewer than five completed solves.
+func ao5Delta(solveRepo *storage.SolveRepository, phaseRepo *storage.PhaseRepository) (string, error) {
+	solves, err := solveRepo.List(6)
+	if err != nil {
+		return "", err
+	}
+	var times []int64 // Newest first
+	for _, s := range solves {
+		if s.DurationMs == nil {
+			continue
+		}
+		ms, err := solveTimeMs(phaseRepo, s)
+		if err != nil {
+			return "", err
+		}
+		if ms > 0 {
+			times = append(times, ms)
+		}
+	}
+	if len(times) < 5 {
+		return "", nil
+	}
+
+	mean := func(ts []int64) float64 {
+		var sum int64
+		for _, t := range ts {
+			sum += t
+		}
+		return float64(sum) / float64(len(ts))
+	}
+	cur := mean(times[:5])
+	if len(times) < 6 {
+		return fmt.Sprintf("ao5 %.2fs", cur/1000), nil
+	}
+	delta := (cur - mean(times[1:6])) / 1000
+	return fmt.Sprintf("ao5 %.2fs (%+.2fs)", cur/1000, delta), nil
+}
+
+// slowestPhase describes the phase furthest over its budget, or the longest
+// phase when none has a budget.
+func slowestPhase(phases []analysis.PhaseDiagnostics, budget func(string) (time.Duration, bool)) string {
+	var worst *analysis.PhaseDiagnostics
+	var worstBudget time.Duration
+	var worstRatio float64
+	for i, p := range phases {
+		if budget == nil {
+			break
+		}
+		b, ok := budget(p.PhaseKey)
+		if !ok {
+			continue
+		}
+		if ratio := float64(p.DurationMs) / float64(b.Milliseconds()); worst == nil || ratio > worstRatio {
+			worst, worstBudget, worstRatio = &phases[i], b, ratio
+		}
+	}
+	if worst != nil {
+		over := float64(worst.DurationMs)/1000 - worstBudget.Seconds()
+		return fmt.Sprintf("slowest %s %.1fs (%+.1fs vs %.1fs budget)",
+			worst.DisplayName, float64(worst.DurationMs)/1000, over, worstBudget.Seconds())
+	}
+
+	for i, p := range phases {
+		if worst == nil || p.DurationMs > worst.DurationMs {
+			worst = &phases[i]
+		}
+	}
+	if worst == nil {
+		return ""
+	}
+	return fmt.Sprintf("slowest %s %.1fs", worst.DisplayName, float64(worst.DurationMs)/1000)
+}
+
+// diagnosticTip picks the most concrete issue in the diagnostics: undone
+// turns first, then wasted full cycles, long pauses and rotation bursts.
+func diagnosticTip(phases []analysis.PhaseDiagnostics, orient analysis.OrientationDiagnostics) string {
+	pick := func(value func(analysis.PhaseDiagnostics) int64, min int64) (analysis.PhaseDiagnostics, bool) {
+		var best analysis.PhaseDiagnostics
+		found := false
+		for _, p := range phases {
+			if v := value(p); v >= min && (!found || v > value(best)) {
+				best, found = p, true
+			}
+		}
+		return best, found
+	}
+
+	if p, ok := pick(func(p analysis.PhaseDiagnostics) int64 { return int64(p.ImmediateReversals) }, 2); ok {
+		return fmt.Sprintf("%d reversals during %s", p.ImmediateReversals, p.DisplayName)
+	}
+	if p, ok := pick(func(p analysis.PhaseDiagnostics) int64 { return int64(p.FullCycleWaste) }, 1); ok {
+		return fmt.Sprintf("%d wasted full turn cycles during %s", p.FullCycleWaste, p.DisplayName)
+	}
+	if p, ok := pick(func(p analysis.PhaseDiagnostics) int64 { return p.MaxGapMs }, 3000); ok {
+		return fmt.Sprintf("%.1fs pause during %s", float64(p.MaxGapMs)/1000, p.DisplayName)
+	}
+	if orient.RotationBursts >= 3 {
+		return fmt.Sprintf("%d rotation bursts - plan rotations ahead", orient.RotationBursts)
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+//go:build !js
+
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+func openTestDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	return db
+}
+
+// addTestSolve stores an ended 13s session whose solving phases take
+// solveMs after 8s of scrambling and 2s of inspection.
+func addTestSolve(t *testing.T, db *storage.DB, start time.Time, solveMs int64) string {
+	t.Helper()
+	solveRepo := storage.NewSolveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+	id, err := solveRepo.CreateAt(start, "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateAt: %v", err)
+	}
+	if err := solveRepo.EndAt(id, start.Add(13*time.Second)); err != nil {
+		t.Fatalf("EndAt: %v", err)
+	}
+	for _, seg := range []storage.PhaseSegment{
+		{PhaseKey: "scramble", StartTsMs: 0, EndTsMs: 8000, DurationMs: 8000},
+		{PhaseKey: "inspection", StartTsMs: 8000, EndTsMs: 10000, DurationMs: 2000},
+		{PhaseKey: "white_cross", StartTsMs: 10000, EndTsMs: 10000 + solveMs, DurationMs: solveMs},
+	} {
+		seg.SolveID = id
+		if _, err := phaseRepo.CreatePhaseSegment(seg); err != nil {
+			t.Fatalf("CreatePhaseSegment(%s): %v", seg.PhaseKey, err)
+		}
+	}
+	return id
+}
+
+func TestSolveSummaryLineTimesSolvingPhases(t *testing.T) {
+	db := openTestDB(t)
+
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	var last string
+	for i := 0; i < 6; i++ {
+		last = addTestSolve(t, db, start.Add(time.Duration(i)*time.Minute), 2000+100*int64(i))
+	}
+
+	line, err := solveSummaryLine(db, last, nil)
+	if err != nil {
+		t.Fatalf("solveSummaryLine: %v", err)
+	}
+	// 2.5s solving, not the 13s session; ao5 of 2.1-2.5s against 2.0-2.4s
+	if want := "2.50s | ao5 2.30s (+0.10s) | "; !strings.HasPrefix(line, want) {
+		t.Errorf("summary = %q, want it to start with %q", line, want)
+	}
+}
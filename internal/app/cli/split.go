@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var solveSplitCmd = &cobra.Command{
+	Use:   "split [solve-id]",
+	Short: "Split a free-play capture into one solve per attempt",
+	Long: `Find the solve attempts in a long continuous capture - each cycle from a
+solved cube through scrambled back to solved - and store each as a solve of
+its own with an inferred scramble.
+
+The solve is taken to start after the longest pause of each cycle (the
+inspection). Cycles of fewer than 8 moves are ignored as fidgeting, and
+moves after the cube was last solved belong to no attempt.
+
+The attempts are reviewed in a TUI before anything is stored:
+  up/down - Select an attempt
+  m       - Merge the selected attempt with the next one
+  s       - Split off the last attempt merged into the selected one
+  x       - Skip or include the selected attempt
+  enter   - Store the included attempts
+  q/Esc   - Quit without changes
+
+The capture is deleted once its attempts are stored, unless --keep is
+given. Use --dry-run to only list the attempts, and --yes to store them
+all without the review.`,
+	RunE: runSolveSplit,
+}
+
+var (
+	splitLast   bool
+	splitKeep   bool
+	splitSafety safetyOptions
+)
+
+func init() {
+	solveCmd.AddCommand(solveSplitCmd)
+	solveSplitCmd.Flags().BoolVar(&splitLast, "last", false, "Split the most recent solve")
+	solveSplitCmd.Flags().BoolVar(&splitKeep, "keep", false, "Keep the capture after storing its attempts")
+	addSafetyFlags(solveSplitCmd, &splitSafety)
+}
+
+func runSolveSplit(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+
+	var solveID string
+	if splitLast {
+		solves, err := solveRepo.List(1)
+		if err != nil {
+			return fmt.Errorf("failed to get latest solve: %w", err)
+		}
+		if len(solves) == 0 {
+			return fmt.Errorf("no solves found")
+		}
+		solveID = solves[0].SolveID
+	} else if len(args) > 0 {
+		solveID = args[0]
+	} else {
+		return fmt.Errorf("please provide a solve ID or use --last")
+	}
+
+	solve, err := solveRepo.Get(solveID)
+	if err != nil {
+		return fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return fmt.Errorf("solve not found: %s", solveID)
+	}
+	if solve.EndedAt == nil {
+		return fmt.Errorf("solve %s is still being recorded\nUse 'gocube solve end' to finish it first", solveID)
+	}
+
+	records, err := storage.NewMoveRepository(db).GetBySolve(solveID)
+	if err != nil {
+		return fmt.Errorf("failed to get moves: %w", err)
+	}
+	attempts := recorder.DetectAttempts(records)
+	if len(attempts) == 0 {
+		fmt.Println("No attempts found: the cube never went from solved through scrambled back to solved.")
+		return nil
+	}
+
+	model := newSplitModel(solve, records, attempts)
+	if splitSafety.DryRun || splitSafety.Yes {
+		fmt.Printf("%d attempt(s) in %s:\n", len(attempts), solveID)
+		for i := range model.groups {
+			fmt.Printf("  %s\n", model.row(i))
+			fmt.Printf("      scramble: %s\n", model.scramble(i))
+		}
+		if splitSafety.DryRun {
+			fmt.Println("Dry run: nothing stored.")
+			return nil
+		}
+	} else {
+		if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+			return fmt.Errorf("review error: %w", err)
+		}
+		if !model.committed {
+			fmt.Println("Nothing stored.")
+			return nil
+		}
+	}
+
+	var included []recorder.Attempt
+	for _, g := range model.groups {
+		if !g.skip {
+			included = append(included, g.attempt())
+		}
+	}
+	if len(included) == 0 {
+		fmt.Println("All attempts skipped: nothing stored.")
+		return nil
+	}
+
+	ids, err := recorder.SplitCapture(db, solveID, included)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored %d solve(s):\n", len(ids))
+	for _, id := range ids {
+		if summary, err := solveSummaryLine(db, id, nil); err == nil {
+			fmt.Printf("  %s  %s\n", id[:8], summary)
+		} else {
+			fmt.Printf("  %s\n", id[:8])
+		}
+	}
+
+	if splitKeep {
+		return nil
+	}
+	if err := solveRepo.Delete(solveID); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted capture %s\n", solveID[:8])
+	return nil
+}
+
+// splitGroup is an attempt under review: one or more detected attempts
+// merged into one.
+type splitGroup struct {
+	parts []recorder.Attempt
+	skip  bool
+}
+
+// attempt returns the group as a single attempt.
+func (g splitGroup) attempt() recorder.Attempt {
+	a := g.parts[0]
+	for _, p := range g.parts[1:] {
+		a = recorder.MergeAttempts(a, p)
+	}
+	return a
+}
+
+// splitModel is the review TUI for the attempts of a capture.
+type splitModel struct {
+	solve     *storage.Solve
+	records   []storage.MoveRecord
+	groups    []splitGroup
+	selected  int
+	scrambles map[[2]int]string // Inferred scrambles by scramble move range
+	committed bool
+}
+
+func newSplitModel(solve *storage.Solve, records []storage.MoveRecord, attempts []recorder.Attempt) *splitModel {
+	groups := make([]splitGroup, len(attempts))
+	for i, a := range attempts {
+		groups[i] = splitGroup{parts: []recorder.Attempt{a}}
+	}
+	return &splitModel{
+		solve:     solve,
+		records:   records,
+		groups:    groups,
+		scrambles: make(map[[2]int]string),
+	}
+}
+
+func (m *splitModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *splitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+
+	case "enter":
+		m.committed = true
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+
+	case "down", "j":
+		if m.selected < len(m.groups)-1 {
+			m.selected++
+		}
+
+	case "m":
+		// Merge the selected attempt with the next one
+		if m.selected < len(m.groups)-1 {
+			g := &m.groups[m.selected]
+			g.parts = append(g.parts, m.groups[m.selected+1].parts...)
+			m.groups = append(m.groups[:m.selected+1], m.groups[m.selected+2:]...)
+		}
+
+	case "s":
+		// Split off the last attempt merged into the selected one
+		g := &m.groups[m.selected]
+		if n := len(g.parts); n > 1 {
+			last := splitGroup{parts: []recorder.Attempt{g.parts[n-1]}, skip: g.skip}
+			g.parts = g.parts[:n-1]
+			m.groups = append(m.groups[:m.selected+1], append([]splitGroup{last}, m.groups[m.selected+1:]...)...)
+		}
+
+	case "x":
+		m.groups[m.selected].skip = !m.groups[m.selected].skip
+	}
+	return m, nil
+}
+
+// row describes attempt group i in one line.
+func (m *splitModel) row(i int) string {
+	g := m.groups[i]
+	a := g.attempt()
+	startedAt := m.solve.StartedAt.Add(time.Duration(m.records[a.Start].TsUs) * time.Microsecond)
+	solveTime := time.Duration(m.records[a.End-1].TsUs-m.records[a.SolveStart].TsUs) * time.Microsecond
+
+	line := fmt.Sprintf("%2d  %s  %3d scramble  %3d solve  %8s",
+		i+1, localTime(startedAt).Format("15:04:05"), a.ScrambleMoves(), a.SolveMoves(), formatDuration(solveTime))
+	if len(g.parts) > 1 {
+		line += fmt.Sprintf("  merged %d", len(g.parts))
+	}
+	if g.skip {
+		line += "  SKIPPED"
+	}
+	return line
+}
+
+// scramble returns the inferred scramble of attempt group i.
+func (m *splitModel) scramble(i int) string {
+	a := m.groups[i].attempt()
+	k := [2]int{a.Start, a.SolveStart}
+	if s, ok := m.scrambles[k]; ok {
+		return s
+	}
+	s := recorder.InferScramble(m.records, a)
+	m.scrambles[k] = s
+	return s
+}
+
+func (m *splitModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Split capture %s", m.solve.SolveID[:8])))
+	b.WriteString("\n")
+	b.WriteString(statusStyle.Render(fmt.Sprintf("%d moves, %d attempt(s)", len(m.records), len(m.groups))))
+	b.WriteString("\n\n")
+
+	for i := range m.groups {
+		line := m.row(i)
+		switch {
+		case i == m.selected:
+			b.WriteString(phaseStyle.Render("> " + line))
+		case m.groups[i].skip:
+			b.WriteString(statusStyle.Render("  " + line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("Scramble: %s\n", moveStyle.Render(m.scramble(m.selected))))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("up/down=select  m=merge with next  s=split  x=skip  enter=store  q=quit"))
+	b.WriteString("\n")
+
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// stateCompareInterval is how often the state comparison panel polls the cube.
+const stateCompareInterval = 2 * time.Second
+
+// stateRequestMsg triggers a periodic STATE request while the panel is open.
+type stateRequestMsg struct{}
+
+// scheduleStateRequest schedules the next STATE poll.
+func (m *recordModel) scheduleStateRequest() tea.Cmd {
+	return tea.Tick(stateCompareInterval, func(t time.Time) tea.Msg {
+		return stateRequestMsg{}
+	})
+}
+
+// resyncTracker replaces the tracked state with the device-reported state.
+// Live phase detection restarts from the corrected state; the session
+// re-derives the whole solve's phases from it when the solve ends.
+func (m *recordModel) resyncTracker() {
+	if !m.recording || m.tracker == nil || m.deviceState == nil {
+		return
+	}
+	if err := m.session.Resync(m.deviceState); err != nil {
+		m.err = err
+		return
+	}
+
+	m.tracker = m.deviceState.Clone()
+	phase := m.tracker.Phase()
+	m.detectedPhase = phase.String()
+	if m.solveStarted {
+		m.highestPhase = phase
+	}
+	m.notice = fmt.Sprintf("Tracker resynced to device state (%s)", phase)
+}
+
+// countStateDiffs returns the number of facelets that differ between two cubes.
+func countStateDiffs(a, b *gocube.Cube) int {
+	diffs := 0
+	for f := 0; f < 6; f++ {
+		for i := 0; i < 9; i++ {
+			if a.Facelets[f][i] != b.Facelets[f][i] {
+				diffs++
+			}
+		}
+	}
+	return diffs
+}
+
+// renderStateComparison renders the tracked and device-reported cubes side
+// by side, highlighting facelets where the device disagrees with the tracker.
+func renderStateComparison(tracked, device *gocube.Cube) string {
+	left := netLines(tracked, nil)
+	right := netLines(device, tracked)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-26s%s\n", "Tracked", "Device"))
+	for i := range left {
+		b.WriteString(left[i])
+		b.WriteString("  ")
+		b.WriteString(right[i])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// netLines renders a cube as an unfolded net, one string per row.
+// Facelets that differ from ref (when non-nil) are highlighted.
+func netLines(c, ref *gocube.Cube) []string {
+	cell := func(face gocube.CubeFace, idx int) string {
+		s := c.Facelets[face][idx].String() + " "
+		if ref != nil && c.Facelets[face][idx] != ref.Facelets[face][idx] {
+			return errorStyle.Render(s)
+		}
+		return s
+	}
+
+	var lines []string
+	for row := 0; row < 3; row++ {
+		line := "      "
+		for col := 0; col < 3; col++ {
+			line += cell(gocube.CubeFaceU, row*3+col)
+		}
+		lines = append(lines, line+"      ")
+	}
+	for row := 0; row < 3; row++ {
+		line := ""
+		for _, face := range []gocube.CubeFace{gocube.CubeFaceL, gocube.CubeFaceF, gocube.CubeFaceR, gocube.CubeFaceB} {
+			for col := 0; col < 3; col++ {
+				line += cell(face, row*3+col)
+			}
+		}
+		lines = append(lines, line)
+	}
+	for row := 0; row < 3; row++ {
+		line := "      "
+		for col := 0; col < 3; col++ {
+			line += cell(gocube.CubeFaceD, row*3+col)
+		}
+		lines = append(lines, line+"      ")
+	}
+	return lines
+}
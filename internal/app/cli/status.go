@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 )
@@ -50,12 +51,16 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			solveRepo := storage.NewSolveRepository(db)
 			solves, _ := solveRepo.List(1)
 			if len(solves) > 0 {
-				fmt.Printf("Last solve: %s\n", solves[0].StartedAt.Format(time.RFC3339))
+				fmt.Printf("Last solve: %s\n", localTime(solves[0].StartedAt).Format(time.RFC3339))
 			}
 
 			// Count total solves
 			allSolves, _ := solveRepo.List(10000)
 			fmt.Printf("Total solves: %d\n", len(allSolves))
+
+			if earned, err := storage.NewAchievementRepository(db).List(); err == nil {
+				fmt.Printf("Achievements: %d/%d earned (see 'gocube achievements')\n", len(earned), len(analysis.Achievements))
+			}
 		}
 	}
 
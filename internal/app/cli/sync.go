@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var (
+	syncSolveID     string
+	syncLast        bool
+	syncVideoOffset time.Duration
+	syncVideoStart  string
+	syncTableOpts   tableOptions
+)
+
+var reportSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Line a solve up with a camera recording of it",
+	Long: `List where a solve's start, phase marks and end fall in a video of it.
+
+Each is anchored to the UTC wall clock as it is recorded. Tell sync where
+the solve starts in the video, by its position:
+
+  gocube report sync --last --video-offset 12.4s
+
+or by the wall-clock time the camera started recording, if the camera's
+clock is set (e.g. from the file's metadata):
+
+  gocube report sync --last --video-start 2026-10-16T18:04:51.2Z
+
+The anchors are also exported in playback.json by 'gocube report solve'.
+Solves recorded before anchors were stored get them from their start time
+and phase marks.`,
+	RunE: runReportSync,
+}
+
+func init() {
+	reportCmd.AddCommand(reportSyncCmd)
+	reportSyncCmd.Flags().StringVar(&syncSolveID, "id", "", "Solve ID to sync")
+	reportSyncCmd.Flags().BoolVar(&syncLast, "last", false, "Sync the last solve")
+	reportSyncCmd.Flags().DurationVar(&syncVideoOffset, "video-offset", 0, "Position in the video where the solve starts")
+	reportSyncCmd.Flags().StringVar(&syncVideoStart, "video-start", "", "Wall-clock time the video starts (RFC3339)")
+	addTableFlags(reportSyncCmd, &syncTableOpts, "")
+}
+
+// PlaybackAnchor is a video anchor in playback.json.
+type PlaybackAnchor struct {
+	Kind     string `json:"kind"`                // solve_start, phase or solve_end
+	PhaseKey string `json:"phase_key,omitempty"` // For phase anchors
+	TsMs     int64  `json:"ts_ms"`               // Milliseconds since solve start
+	WallUTC  string `json:"wall_utc"`            // RFC3339 UTC
+}
+
+func runReportSync(cmd *cobra.Command, args []string) error {
+	if syncSolveID == "" && !syncLast {
+		return fmt.Errorf("specify --id or --last")
+	}
+	offsetSet := cmd.Flags().Changed("video-offset")
+	if offsetSet == (syncVideoStart != "") {
+		return fmt.Errorf("specify one of --video-offset or --video-start")
+	}
+	var videoStart time.Time
+	if syncVideoStart != "" {
+		var err error
+		if videoStart, err = time.Parse(time.RFC3339Nano, syncVideoStart); err != nil {
+			return fmt.Errorf("invalid --video-start: %w", err)
+		}
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveRepo := storage.NewSolveRepository(db)
+	var solve *storage.Solve
+	if syncLast {
+		solve, err = solveRepo.GetLast()
+	} else {
+		solve, err = solveRepo.Get(syncSolveID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return fmt.Errorf("solve not found")
+	}
+
+	anchors, err := solveAnchors(db, solve)
+	if err != nil {
+		return err
+	}
+	if len(anchors) == 0 {
+		return fmt.Errorf("solve %s has no anchors", solve.SolveID[:8])
+	}
+
+	// The solve's start, in the video
+	offset := syncVideoOffset
+	if !offsetSet {
+		offset = anchors[0].WallUTC.Sub(videoStart) - time.Duration(anchors[0].TsMs)*time.Millisecond
+	}
+
+	t := &table{Columns: []tableColumn{
+		{Key: "anchor", Title: "Anchor"},
+		{Key: "phase", Title: "Phase"},
+		{Key: "wall_utc", Title: "Wall clock (UTC)", Format: func(v interface{}) string {
+			return v.(time.Time).Format("15:04:05.000")
+		}},
+		{Key: "solve_ms", Title: "Solve", Right: true, Format: func(v interface{}) string {
+			return formatDuration(time.Duration(v.(int64)) * time.Millisecond)
+		}},
+		{Key: "video_ms", Title: "Video", Right: true, Format: func(v interface{}) string {
+			return formatVideoPosition(time.Duration(v.(int64)) * time.Millisecond)
+		}},
+	}}
+	for _, a := range anchors {
+		row := map[string]interface{}{
+			"anchor":   a.Kind,
+			"wall_utc": a.WallUTC,
+			"solve_ms": a.TsMs,
+		}
+		if a.PhaseKey != "" {
+			row["phase"] = a.PhaseKey
+		}
+		if video := offset + time.Duration(a.TsMs)*time.Millisecond; video >= 0 {
+			row["video_ms"] = video.Milliseconds()
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if err := t.Apply(syncTableOpts); err != nil {
+		return err
+	}
+	if syncTableOpts.JSON {
+		return t.WriteJSON(os.Stdout)
+	}
+
+	fmt.Printf("Solve %s in the video (starts at %s)\n\n", solve.SolveID[:8], formatVideoPosition(offset))
+	t.Render(os.Stdout)
+	return nil
+}
+
+// solveAnchors returns a solve's video anchors in order. Solves recorded
+// before anchors were stored get them from their start and end times and
+// phase marks.
+func solveAnchors(db *storage.DB, solve *storage.Solve) ([]storage.VideoAnchor, error) {
+	anchors, err := storage.NewVideoAnchorRepository(db).GetBySolve(solve.SolveID)
+	if err != nil || len(anchors) > 0 {
+		return anchors, err
+	}
+
+	marks, err := storage.NewPhaseRepository(db).GetPhaseMarks(solve.SolveID)
+	if err != nil {
+		return nil, err
+	}
+	at := func(kind, phaseKey string, tsMs int64) storage.VideoAnchor {
+		return storage.VideoAnchor{
+			SolveID:  solve.SolveID,
+			Kind:     kind,
+			PhaseKey: phaseKey,
+			TsMs:     tsMs,
+			WallUTC:  solve.StartedAt.Add(time.Duration(tsMs) * time.Millisecond).UTC(),
+		}
+	}
+	anchors = append(anchors, at(storage.AnchorSolveStart, "", 0))
+	for _, m := range marks {
+		anchors = append(anchors, at(storage.AnchorPhase, m.PhaseKey, m.TsMs))
+	}
+	if solve.EndedAt != nil {
+		anchors = append(anchors, at(storage.AnchorSolveEnd, "", solve.EndedAt.Sub(solve.StartedAt).Milliseconds()))
+	}
+	return anchors, nil
+}
+
+// playbackAnchors returns a solve's video anchors for playback.json.
+func playbackAnchors(db *storage.DB, solve *storage.Solve) []PlaybackAnchor {
+	anchors, _ := solveAnchors(db, solve)
+	var out []PlaybackAnchor
+	for _, a := range anchors {
+		out = append(out, PlaybackAnchor{
+			Kind:     a.Kind,
+			PhaseKey: a.PhaseKey,
+			TsMs:     a.TsMs,
+			WallUTC:  a.WallUTC.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return out
+}
+
+// formatVideoPosition formats a position in a video as a player shows it,
+// e.g. "1:02.35".
+func formatVideoPosition(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	mins := int(d / time.Minute)
+	secs := (d - time.Duration(mins)*time.Minute).Seconds()
+	return fmt.Sprintf("%s%d:%05.2f", sign, mins, secs)
+}
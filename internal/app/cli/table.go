@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// tableColumn describes one column of a table.
+type tableColumn struct {
+	Key    string // Name used by --columns, --sort and JSON output
+	Title  string
+	Right  bool                       // Right-align (numeric columns)
+	Format func(v interface{}) string // Display format; nil uses %v
+}
+
+// table holds typed rows so it can be sorted and emitted as JSON as well as
+// rendered for the terminal. Missing or nil values render as "-".
+type table struct {
+	Columns []tableColumn
+	Rows    []map[string]interface{}
+}
+
+// tableOptions are the output flags shared by list commands.
+type tableOptions struct {
+	Columns []string
+	Sort    string
+	JSON    bool
+}
+
+// addTableFlags registers --columns, --sort and --json on cmd.
+func addTableFlags(cmd *cobra.Command, opts *tableOptions, defaultSort string) {
+	cmd.Flags().StringSliceVar(&opts.Columns, "columns", nil, "Comma-separated columns to show (default: all)")
+	cmd.Flags().StringVar(&opts.Sort, "sort", defaultSort, "Column to sort by; prefix with - for descending (e.g. -tps)")
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print rows as JSON")
+}
+
+// Apply selects and sorts columns according to opts.
+func (t *table) Apply(opts tableOptions) error {
+	if err := t.Sort(opts.Sort); err != nil {
+		return err
+	}
+	return t.Select(opts.Columns)
+}
+
+// Select keeps only the named columns, in the given order.
+func (t *table) Select(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	selected := make([]tableColumn, 0, len(keys))
+	for _, key := range keys {
+		col, ok := t.column(key)
+		if !ok {
+			return fmt.Errorf("unknown column %q (available: %s)", key, t.columnKeys())
+		}
+		selected = append(selected, col)
+	}
+	t.Columns = selected
+	return nil
+}
+
+// Sort orders rows by the named column. A leading "-" sorts descending.
+// Rows missing the value always sort last.
+func (t *table) Sort(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	desc := strings.HasPrefix(spec, "-")
+	key := strings.TrimPrefix(spec, "-")
+	if _, ok := t.column(key); !ok {
+		return fmt.Errorf("cannot sort by unknown column %q (available: %s)", key, t.columnKeys())
+	}
+
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		a, b := t.Rows[i][key], t.Rows[j][key]
+		if a == nil || b == nil {
+			return a != nil
+		}
+		if desc {
+			return lessValue(b, a)
+		}
+		return lessValue(a, b)
+	})
+	return nil
+}
+
+// Render writes the table with columns sized to their widest cell.
+func (t *table) Render(w io.Writer) {
+	cells := make([][]string, len(t.Rows))
+	widths := make([]int, len(t.Columns))
+	for i, col := range t.Columns {
+		widths[i] = utf8.RuneCountInString(col.Title)
+	}
+	for r, row := range t.Rows {
+		cells[r] = make([]string, len(t.Columns))
+		for i, col := range t.Columns {
+			cells[r][i] = col.format(row[col.Key])
+			if n := utf8.RuneCountInString(cells[r][i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	titles := make([]string, len(t.Columns))
+	rules := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		titles[i] = col.Title
+		rules[i] = strings.Repeat("-", widths[i])
+	}
+	t.writeLine(w, titles, widths)
+	t.writeLine(w, rules, widths)
+	for _, row := range cells {
+		t.writeLine(w, row, widths)
+	}
+}
+
+// WriteJSON writes the rows as a JSON array, limited to the selected columns.
+func (t *table) WriteJSON(w io.Writer) error {
+	out := make([]map[string]interface{}, len(t.Rows))
+	for r, row := range t.Rows {
+		out[r] = make(map[string]interface{}, len(t.Columns))
+		for _, col := range t.Columns {
+			out[r][col.Key] = row[col.Key]
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func (t *table) writeLine(w io.Writer, cells []string, widths []int) {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if t.Columns[i].Right {
+			parts[i] = pad + cell
+		} else if i < len(cells)-1 {
+			parts[i] = cell + pad
+		} else {
+			parts[i] = cell // no trailing spaces on the last column
+		}
+	}
+	fmt.Fprintln(w, strings.Join(parts, "  "))
+}
+
+func (t *table) column(key string) (tableColumn, bool) {
+	for _, col := range t.Columns {
+		if col.Key == key {
+			return col, true
+		}
+	}
+	return tableColumn{}, false
+}
+
+func (t *table) columnKeys() string {
+	keys := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		keys[i] = col.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
+func (c tableColumn) format(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	if c.Format != nil {
+		return c.Format(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// lessValue compares two cell values of the same column.
+func lessValue(a, b interface{}) bool {
+	switch av := a.(type) {
+	case int:
+		if bv, ok := b.(int); ok {
+			return av < bv
+		}
+	case int64:
+		if bv, ok := b.(int64); ok {
+			return av < bv
+		}
+	case float64:
+		if bv, ok := b.(float64); ok {
+			return av < bv
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Before(bv)
+		}
+	}
+	return fmt.Sprintf("%v", a) < fmt.Sprintf("%v", b)
+}
+
+// formatFloat returns a column formatter with the given precision.
+func formatFloat(decimals int) func(v interface{}) string {
+	return func(v interface{}) string {
+		return fmt.Sprintf("%.*f", decimals, v)
+	}
+}
+
+// formatSeconds formats a millisecond value as seconds.
+func formatSeconds(v interface{}) string {
+	switch ms := v.(type) {
+	case int64:
+		return fmt.Sprintf("%.2fs", float64(ms)/1000.0)
+	case float64:
+		return fmt.Sprintf("%.2fs", ms/1000.0)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/telemetry"
+)
+
+var telemetryEndpoint string
+
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Manage opt-in anonymized telemetry",
+	Long: `Manage the opt-in anonymized telemetry upload.
+
+Telemetry is disabled by default. When enabled, only aggregate counts and
+rates (solve counts, phase detection rates, protocol error rates) are sent.
+Use 'gocube telemetry preview' to see the exact payload before enabling.`,
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show telemetry settings",
+	RunE:  runTelemetryStatus,
+}
+
+var telemetryPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Print exactly what would be sent",
+	RunE:  runTelemetryPreview,
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Opt in to telemetry",
+	RunE:  runTelemetryEnable,
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Opt out of telemetry",
+	RunE:  runTelemetryDisable,
+}
+
+var telemetrySendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Upload the telemetry report now (only if enabled)",
+	RunE:  runTelemetrySend,
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	telemetryCmd.AddCommand(telemetryPreviewCmd)
+	telemetryCmd.AddCommand(telemetryEnableCmd)
+	telemetryCmd.AddCommand(telemetryDisableCmd)
+	telemetryCmd.AddCommand(telemetrySendCmd)
+
+	telemetryEnableCmd.Flags().StringVar(&telemetryEndpoint, "endpoint", "", "URL to POST reports to (required)")
+}
+
+// loadConfigForEdit loads the config file and returns it with its path.
+func loadConfigForEdit() (recorder.Config, string, error) {
+	path, err := recorder.DefaultConfigPath()
+	if err != nil {
+		return recorder.Config{}, "", err
+	}
+	cfg, err := recorder.LoadConfig(path)
+	if err != nil {
+		return recorder.Config{}, "", err
+	}
+	return cfg, path, nil
+}
+
+// buildTelemetryReport collects the report that would be sent.
+func buildTelemetryReport(installID string) (*telemetry.Report, error) {
+	db, err := openDB()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if installID == "" {
+		installID = "(assigned on enable)"
+	}
+	return telemetry.Collect(db, installID, version)
+}
+
+func runTelemetryStatus(cmd *cobra.Command, args []string) error {
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	t := cfg.Telemetry
+	fmt.Printf("Config: %s\n", path)
+	if t.Enabled {
+		fmt.Println("Telemetry: enabled")
+	} else {
+		fmt.Println("Telemetry: disabled")
+	}
+	if t.Endpoint != "" {
+		fmt.Printf("Endpoint: %s\n", t.Endpoint)
+	}
+	if t.InstallID != "" {
+		fmt.Printf("Install ID: %s\n", t.InstallID)
+	}
+	if t.LastSent != "" {
+		fmt.Printf("Last sent: %s\n", t.LastSent)
+	}
+	return nil
+}
+
+func runTelemetryPreview(cmd *cobra.Command, args []string) error {
+	cfg, _, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	report, err := buildTelemetryReport(cfg.Telemetry.InstallID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	fmt.Println(string(data))
+	if !cfg.Telemetry.Enabled {
+		fmt.Println()
+		fmt.Println("Telemetry is disabled; nothing will be sent.")
+	}
+	return nil
+}
+
+func runTelemetryEnable(cmd *cobra.Command, args []string) error {
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	if telemetryEndpoint != "" {
+		cfg.Telemetry.Endpoint = telemetryEndpoint
+	}
+	if cfg.Telemetry.Endpoint == "" {
+		return fmt.Errorf("specify --endpoint")
+	}
+	if cfg.Telemetry.InstallID == "" {
+		cfg.Telemetry.InstallID = uuid.New().String()
+	}
+	cfg.Telemetry.Enabled = true
+
+	if err := recorder.SaveConfig(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("Telemetry enabled.")
+	fmt.Println("Run 'gocube telemetry preview' to see exactly what is sent.")
+	return nil
+}
+
+func runTelemetryDisable(cmd *cobra.Command, args []string) error {
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	cfg.Telemetry.Enabled = false
+	if err := recorder.SaveConfig(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("Telemetry disabled.")
+	return nil
+}
+
+func runTelemetrySend(cmd *cobra.Command, args []string) error {
+	cfg, path, err := loadConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Telemetry.Enabled {
+		fmt.Println("Telemetry is disabled; nothing sent.")
+		fmt.Println("Run 'gocube telemetry enable --endpoint <url>' to opt in.")
+		return nil
+	}
+
+	report, err := buildTelemetryReport(cfg.Telemetry.InstallID)
+	if err != nil {
+		return err
+	}
+
+	if err := telemetry.Send(context.Background(), cfg.Telemetry.Endpoint, report); err != nil {
+		return err
+	}
+
+	cfg.Telemetry.LastSent = time.Now().UTC().Format(time.RFC3339)
+	if err := recorder.SaveConfig(path, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Telemetry sent to %s\n", cfg.Telemetry.Endpoint)
+	return nil
+}
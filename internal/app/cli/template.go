@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/audio"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var exportTemplate string
+
+// TemplateData is the data model passed to export templates.
+type TemplateData struct {
+	Solve       *storage.Solve
+	Summary     FullSolveSummary
+	Moves       []storage.MoveRecord
+	Diagnostics *analysis.SolveDiagnostics
+	Context     map[string]string
+	Bookmarks   []recorder.Bookmark
+	Audio       *audio.Alignment // nil unless audio was aligned
+	GeneratedAt time.Time
+}
+
+// templateFuncs are the helpers available to export templates.
+var templateFuncs = template.FuncMap{
+	"seconds": func(ms any) string {
+		switch v := ms.(type) {
+		case int64:
+			return fmt.Sprintf("%.2f", float64(v)/1000)
+		case int:
+			return fmt.Sprintf("%.2f", float64(v)/1000)
+		case float64:
+			return fmt.Sprintf("%.2f", v/1000)
+		}
+		return fmt.Sprint(ms)
+	},
+	"notation": func(moves []storage.MoveRecord) string {
+		notations := make([]string, len(moves))
+		for i, m := range moves {
+			notations[i] = m.Notation
+		}
+		return strings.Join(notations, " ")
+	},
+	"json": func(v any) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// resolveTemplate finds a template by path, falling back to the templates
+// directory for bare names.
+func resolveTemplate(name string) (string, error) {
+	if _, err := os.Stat(name); err == nil {
+		return name, nil
+	}
+	dir, err := recorder.DefaultTemplatesDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("template %q not found (looked in the current directory and %s)", name, dir)
+	}
+	return path, nil
+}
+
+// listTemplates prints the templates in the templates directory.
+func listTemplates() error {
+	dir, err := recorder.DefaultTemplatesDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		fmt.Printf("No templates in %s\n", dir)
+		fmt.Println("Add a text/template file there and run 'gocube export --template NAME --last'.")
+		return nil
+	}
+	fmt.Printf("Templates in %s:\n", dir)
+	for _, n := range names {
+		fmt.Printf("  %s\n", n)
+	}
+	return nil
+}
+
+// buildTemplateData loads everything a template can reference for a solve.
+func buildTemplateData(db *storage.DB, solveID string) (*TemplateData, error) {
+	solveRepo := storage.NewSolveRepository(db)
+	moveRepo := storage.NewMoveRepository(db)
+	phaseRepo := storage.NewPhaseRepository(db)
+	orientRepo := storage.NewOrientationRepository(db)
+	eventRepo := storage.NewEventRepository(db)
+
+	solve, err := solveRepo.Get(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return nil, fmt.Errorf("solve not found")
+	}
+
+	moveRecords, err := moveRepo.GetBySolve(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moves: %w", err)
+	}
+	segments, err := phaseRepo.GetPhaseSegments(solveID)
+	if err != nil {
+		segments = nil
+	}
+	segments = metricSegments(segments, moveRecords)
+	phaseDefs, _ := phaseRepo.GetAllPhaseDefs()
+	phaseDefMap := make(map[string]string)
+	for _, pd := range phaseDefs {
+		phaseDefMap[pd.PhaseKey] = pd.DisplayName
+	}
+
+	data := &TemplateData{
+		Solve:       solve,
+		Summary:     buildFullSolveSummary(solve, moveRecords, segments, phaseDefMap),
+		Moves:       moveRecords,
+		GeneratedAt: time.Now(),
+	}
+
+	if data.Diagnostics, err = analysis.AnalyzeDiagnostics(solveID, moveRepo, phaseRepo, orientRepo); err != nil {
+		return nil, err
+	}
+	if data.Context, err = storage.NewContextRepository(db).Get(solveID); err != nil {
+		return nil, err
+	}
+	if data.Bookmarks, err = recorder.LoadBookmarks(eventRepo, solveID); err != nil {
+		return nil, err
+	}
+	if data.Audio, err = recorder.LoadAudioAlignment(eventRepo, solveID); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func runExportTemplate(cmd *cobra.Command, args []string) error {
+	if exportTemplate == "" {
+		return listTemplates()
+	}
+	if exportSolveID == "" && !exportLast {
+		return fmt.Errorf("specify --id or --last")
+	}
+
+	path, err := resolveTemplate(exportTemplate)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	solveID := exportSolveID
+	if exportLast {
+		solve, err := storage.NewSolveRepository(db).GetLast()
+		if err != nil {
+			return fmt.Errorf("failed to get last solve: %w", err)
+		}
+		if solve == nil {
+			return fmt.Errorf("no solves found")
+		}
+		solveID = solve.SolveID
+	}
+
+	data, err := buildTemplateData(db, solveID)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+
+	if exportOutput != "" {
+		if err := os.WriteFile(exportOutput, []byte(b.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		fmt.Printf("Exported to %s\n", exportOutput)
+	} else {
+		fmt.Print(b.String())
+	}
+	return nil
+}
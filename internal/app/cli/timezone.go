@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+)
+
+var (
+	displayLocationOnce sync.Once
+	displayLocationZone *time.Location
+)
+
+// displayLocation returns the time zone solve times are shown in and days
+// are bucketed by: the config's timezone, or the system zone when unset.
+func displayLocation() *time.Location {
+	displayLocationOnce.Do(func() {
+		displayLocationZone = time.Local
+		if cfg, err := recorder.LoadDefaultConfig(); err == nil {
+			displayLocationZone = cfg.Location()
+		}
+	})
+	return displayLocationZone
+}
+
+// localTime converts a stored UTC timestamp to the display time zone.
+func localTime(t time.Time) time.Time {
+	return t.In(displayLocation())
+}
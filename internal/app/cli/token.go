@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/api"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the tokens 'gocube serve' accepts",
+	Long: `Create, list and revoke the bearer tokens of 'gocube serve'. Once any
+token exists, every request to the server must send one, and the server
+may listen beyond localhost. Each token carries scopes:
+
+  stats:read         read solves (GET /api/...) and the live events
+  recording:control  start, inspect and stop recording (POST /api/recording/...)
+  admin              everything
+
+Only a hash of each token is stored; it is shown once, when created.`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a token",
+	Long: `Create a token and print it. It is not shown again.
+
+Examples:
+  gocube token create overlay
+  gocube token create dashboard --scope stats:read --scope recording:control`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTokenCreate,
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tokens and their scopes",
+	RunE:  runTokenList,
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <name>",
+	Short: "Revoke a token",
+	Long:  `Revoke a token. A server already running stops accepting it at once.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTokenRevoke,
+}
+
+var tokenScopes []string
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+	tokenCreateCmd.Flags().StringSliceVar(&tokenScopes, "scope", []string{api.ScopeStatsRead},
+		"Scope of the token: "+strings.Join(api.Scopes, ", ")+" (repeatable)")
+}
+
+func runTokenCreate(cmd *cobra.Command, args []string) error {
+	for _, s := range tokenScopes {
+		if !api.ValidScope(s) {
+			return fmt.Errorf("unknown scope %q (want %s)", s, strings.Join(api.Scopes, ", "))
+		}
+	}
+
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	secret, err := storage.NewAPITokenRepository(db).Create(args[0], tokenScopes)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created token %s (%s):\n\n  %s\n\n", args[0], strings.Join(tokenScopes, " "), secret)
+	fmt.Println("Keep it now; it cannot be shown again.")
+	return nil
+}
+
+func runTokenList(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tokens, err := storage.NewAPITokenRepository(db).List()
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Println("No tokens. 'gocube token create <name>' creates one.")
+		return nil
+	}
+	for _, t := range tokens {
+		fmt.Printf("  %-20s %-40s created %s\n", t.Name, strings.Join(t.Scopes, " "),
+			localTime(t.CreatedAt).Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+func runTokenRevoke(cmd *cobra.Command, args []string) error {
+	db, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	found, err := storage.NewAPITokenRepository(db).Delete(args[0])
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no token named %q", args[0])
+	}
+	fmt.Printf("Revoked token %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/stream"
+)
+
+var (
+	visualizeLive bool
+	visualizeAddr string
+)
+
+var visualizeCmd = &cobra.Command{
+	Use:   "visualize --live",
+	Short: "Watch the cube in the 3D visualizer while solving",
+	Long: `Connect to a cube and serve the 3D visualizer on http://<addr>/, following
+the cube as it turns: each move is animated as it happens, and the view
+turns with the cube's orientation (GoCube only). Open the page in a
+browser and solve.
+
+The view starts from a solved cube. If the cube is scrambled when it
+connects, it catches up the next time the cube is solved. A page opened or
+reloaded later is sent the moves since then, so it shows the same state.
+
+Moves and orientation reach the page over WebSocket at ws://<addr>/events,
+as the events of 'gocube serve'. The server listens on localhost only
+unless --addr names another interface. Stop it with Ctrl+C.
+
+Saved solves are visualized with 'gocube report solve', which writes
+visualizer.html next to the report.`,
+	RunE: runVisualize,
+}
+
+func init() {
+	rootCmd.AddCommand(visualizeCmd)
+	visualizeCmd.Flags().BoolVar(&visualizeLive, "live", false, "Follow a connected cube")
+	visualizeCmd.Flags().StringVar(&visualizeAddr, "addr", "localhost:8766", "Address to listen on")
+}
+
+func runVisualize(cmd *cobra.Command, args []string) error {
+	if !visualizeLive {
+		return fmt.Errorf("only --live is supported; 'gocube report solve' writes the visualizer of a saved solve")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The page is the report visualizer with no solve, switched to live mode
+	var page bytes.Buffer
+	err := writeVisualizerHTML(&page, VisualizerData{
+		SchemaVersion: visualizerSchemaVersion,
+		SolveID:       "live",
+		Live:          &VisualizerLive{EventsPath: "/events"},
+	})
+	if err != nil {
+		return err
+	}
+
+	hub := stream.NewHub()
+	defer hub.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/events", hub)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(page.Bytes())
+	})
+	ln, err := net.Listen("tcp", visualizeAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", visualizeAddr, err)
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(ln) }()
+	defer server.Close()
+
+	fmt.Println("Scanning for a cube...")
+	connectCtx, cancel := context.WithTimeout(ctx, 20*time.Second+scanTimeout())
+	cube, err := gocube.ConnectFirst(connectCtx,
+		gocube.WithScanTimeout(scanTimeout()),
+		gocube.WithPreferredDevice(activeProfile.Device),
+	)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer cube.Close()
+
+	device := cube.DeviceName()
+	connected := func(ok bool) stream.Event {
+		return stream.Event{Type: stream.EventConnection, Connected: &ok, Device: device}
+	}
+	hub.Broadcast(connected(true))
+	hub.Broadcast(stream.Event{Type: stream.EventState})
+
+	// The moves since the cube was last solved, for pages opened later
+	var mu sync.Mutex
+	var sinceSolved []string
+	cube.OnMove(func(m gocube.Move) {
+		mu.Lock()
+		defer mu.Unlock()
+		move := stream.Event{Type: stream.EventMove, Time: m.Time, Move: m.Notation()}
+		if cube.IsSolved() {
+			// Pages that started from a scrambled cube catch up here
+			sinceSolved = nil
+			hub.Broadcast(move)
+			hub.Broadcast(stream.Event{Type: stream.EventState})
+			return
+		}
+		sinceSolved = append(sinceSolved, m.Notation())
+		hub.BroadcastWithState(move, stream.Event{Moves: append([]string(nil), sinceSolved...)})
+	})
+	cube.OnPhaseChange(func(p gocube.Phase) {
+		hub.Broadcast(stream.Event{Type: stream.EventPhase, Phase: p.String(), PhaseName: p.DisplayName()})
+	})
+	cube.OnOrientationChange(func(o gocube.Orientation) {
+		hub.Broadcast(stream.Event{Type: stream.EventOrientation, Up: string(o.UpFace), Front: string(o.FrontFace)})
+	})
+	cube.OnBattery(func(level int) {
+		hub.Broadcast(stream.Event{Type: stream.EventBattery, Battery: &level})
+	})
+	cube.OnDisconnect(func(err error) {
+		hub.Broadcast(connected(false))
+	})
+	cube.OnReconnect(func() {
+		hub.Broadcast(connected(true))
+	})
+
+	if err := cube.EnableOrientation(); err != nil {
+		fmt.Printf("Orientation not available: %v\n", err)
+	}
+
+	fmt.Printf("Connected to %s\n", device)
+	if !cube.IsSolved() {
+		fmt.Println("The cube is not solved; the view catches up the next time it is.")
+	}
+	fmt.Printf("Open http://%s/ to watch it (Ctrl+C to stop)\n", ln.Addr())
+
+	select {
+	case <-ctx.Done():
+		fmt.Println("\nStopping...")
+		return nil
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("server stopped: %w", err)
+	}
+}
@@ -5,24 +5,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/audio"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 )
 
 //go:embed visualizer_template.html
 var visualizerTemplate string
 
+// visualizerSchemaVersion is the version of the VisualizerData contract the
+// visualizer template validates against (SCHEMA_VERSION there). Bump both
+// when a field is added, removed or changes meaning.
+const visualizerSchemaVersion = 2
+
 // VisualizerData contains all data needed for the 3D solve visualization.
 type VisualizerData struct {
-	SolveID         string             `json:"solve_id"`
-	TotalDurationMs int64              `json:"total_duration_ms"`
-	SolveDurationMs int64              `json:"solve_duration_ms"`
-	Phases          []VisualizerPhase  `json:"phases"`
-	Moves           []VisualizerMove   `json:"moves"`
-	Orientations    []VisualizerOrient `json:"orientations"`
-	Report          *VisualizerReport  `json:"report,omitempty"`
+	SchemaVersion   int                 `json:"schema_version"`
+	SolveID         string              `json:"solve_id"`
+	TotalDurationMs int64               `json:"total_duration_ms"`
+	SolveDurationMs int64               `json:"solve_duration_ms"`
+	Phases          []VisualizerPhase   `json:"phases"`
+	Moves           []VisualizerMove    `json:"moves"`
+	Orientations    []VisualizerOrient  `json:"orientations"`
+	Bookmarks       []recorder.Bookmark `json:"bookmarks"`
+	AudioMarkers    []audio.Marker      `json:"audio_markers,omitempty"`
+	Report          *VisualizerReport   `json:"report,omitempty"`
+	Live            *VisualizerLive     `json:"live,omitempty"`
+}
+
+// VisualizerLive switches the visualizer from playing back a solve to
+// following a connected cube, as served by 'gocube visualize --live'.
+type VisualizerLive struct {
+	EventsPath string `json:"events_path"` // WebSocket path of the stream.Hub, e.g. "/events"
 }
 
 // VisualizerReport contains the analysis report data.
@@ -41,6 +60,9 @@ type VisualizerReport struct {
 	// Phase analysis
 	PhaseAnalysis []VisualizerPhaseAnalysis `json:"phase_analysis"`
 
+	// Solving moves with recognized algorithms labeled
+	Reconstruction []analysis.ReconstructionLine `json:"reconstruction,omitempty"`
+
 	// Diagnostics
 	Diagnostics *VisualizerDiagnostics `json:"diagnostics,omitempty"`
 }
@@ -55,6 +77,7 @@ type VisualizerPhaseAnalysis struct {
 	Moves          string   `json:"moves"`
 	Cancellations  int      `json:"cancellations"`
 	TopPatterns    []string `json:"top_patterns,omitempty"`
+	ShorterLine    *analysis.LineSuggestion `json:"shorter_line,omitempty"`
 }
 
 // VisualizerDiagnostics contains diagnostic metrics.
@@ -112,6 +135,7 @@ type VisualizerPhase struct {
 // VisualizerMove represents a single move with its actual timestamp.
 type VisualizerMove struct {
 	TsMs     int64  `json:"ts_ms"`
+	TsUs     int64  `json:"ts_us"`
 	Face     string `json:"face"`
 	Turn     int    `json:"turn"`
 	Notation string `json:"notation"`
@@ -130,6 +154,8 @@ func buildVisualizerData(
 	moves []storage.MoveRecord,
 	phases []storage.PhaseSegment,
 	orientations []storage.OrientationRecord,
+	bookmarks []recorder.Bookmark,
+	audioAlign *audio.Alignment,
 	phaseDefMap map[string]string,
 	report *VisualizerReport,
 ) VisualizerData {
@@ -138,6 +164,7 @@ func buildVisualizerData(
 	for i, m := range moves {
 		vizMoves[i] = VisualizerMove{
 			TsMs:     m.TsMs,
+			TsUs:     m.TsUs,
 			Face:     m.Face,
 			Turn:     m.Turn,
 			Notation: m.Notation,
@@ -193,13 +220,26 @@ func buildVisualizerData(
 		totalDurationMs = moves[len(moves)-1].TsMs + 1000 // Add 1 second buffer
 	}
 
+	// Audio clacks within the timeline
+	var audioMarkers []audio.Marker
+	if audioAlign != nil {
+		for _, m := range audioAlign.Markers {
+			if m.TsMs <= totalDurationMs {
+				audioMarkers = append(audioMarkers, m)
+			}
+		}
+	}
+
 	return VisualizerData{
+		SchemaVersion:   visualizerSchemaVersion,
 		SolveID:         solve.SolveID,
 		TotalDurationMs: totalDurationMs,
 		SolveDurationMs: solveDurationMs,
 		Phases:          vizPhases,
 		Moves:           vizMoves,
 		Orientations:    vizOrients,
+		Bookmarks:       bookmarks,
+		AudioMarkers:    audioMarkers,
 		Report:          report,
 	}
 }
@@ -211,12 +251,27 @@ func generateVisualizerHTML(
 	moves []storage.MoveRecord,
 	phases []storage.PhaseSegment,
 	orientations []storage.OrientationRecord,
+	bookmarks []recorder.Bookmark,
+	audioAlign *audio.Alignment,
 	phaseDefMap map[string]string,
 	report *VisualizerReport,
 ) error {
 	// Build the data structure
-	data := buildVisualizerData(solve, moves, phases, orientations, phaseDefMap, report)
+	data := buildVisualizerData(solve, moves, phases, orientations, bookmarks, audioAlign, phaseDefMap, report)
+
+	// Create output file
+	outputPath := filepath.Join(reportDir, "visualizer.html")
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("creating visualizer file: %w", err)
+	}
+	defer f.Close()
 
+	return writeVisualizerHTML(f, data)
+}
+
+// writeVisualizerHTML executes the visualizer template with data.
+func writeVisualizerHTML(w io.Writer, data VisualizerData) error {
 	// Convert to JSON
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -229,20 +284,12 @@ func generateVisualizerHTML(
 		return fmt.Errorf("parsing visualizer template: %w", err)
 	}
 
-	// Create output file
-	outputPath := filepath.Join(reportDir, "visualizer.html")
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("creating visualizer file: %w", err)
-	}
-	defer f.Close()
-
 	// Execute template with JSON data
 	templateData := map[string]template.JS{
 		"SolveDataJSON": template.JS(jsonData),
 	}
 
-	if err := tmpl.Execute(f, templateData); err != nil {
+	if err := tmpl.Execute(w, templateData); err != nil {
 		return fmt.Errorf("executing visualizer template: %w", err)
 	}
 
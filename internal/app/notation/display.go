@@ -0,0 +1,90 @@
+package notation
+
+import (
+	"sort"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// DisplayMove is one move as shown to the user. When merging, it may stand
+// for several stored moves; storage always keeps the raw moves, so First
+// and Count map it back to them.
+type DisplayMove struct {
+	Move  gocube.Move
+	First int // Index of the first stored move it shows
+	Count int // Number of stored moves it shows
+}
+
+// Notation returns the move as displayed, e.g. "R2".
+func (d DisplayMove) Notation() string {
+	return d.Move.Notation()
+}
+
+// DisplayMoves maps stored moves to the moves shown to the user. Without
+// merge every stored move is shown as is. With merge, consecutive turns of
+// the same face are shown as their net turn (R R as R2, R2 R as R'). A turn
+// that would cancel the run starts a new displayed move instead, so no
+// stored move disappears from view.
+func DisplayMoves(moves []gocube.Move, merge bool) []DisplayMove {
+	display := make([]DisplayMove, 0, len(moves))
+	for i, m := range moves {
+		if merge && len(display) > 0 {
+			last := &display[len(display)-1]
+			if last.Move.Face == m.Face {
+				if net := (quarterTurns(last.Move.Turn) + quarterTurns(m.Turn)) % 4; net != 0 {
+					last.Move.Turn = turnFromQuarters(net)
+					last.Move.Time = m.Time
+					last.Move.Center, last.Move.HasCenter = m.Center, m.HasCenter
+					last.Count++
+					continue
+				}
+			}
+		}
+		display = append(display, DisplayMove{Move: m, First: i, Count: 1})
+	}
+	return display
+}
+
+// DisplayIndex returns the index of the displayed move that shows stored
+// move i, or -1 if i is out of range.
+func DisplayIndex(display []DisplayMove, i int) int {
+	j := sort.Search(len(display), func(j int) bool {
+		return display[j].First+display[j].Count > i
+	})
+	if i < 0 || j == len(display) {
+		return -1
+	}
+	return j
+}
+
+// DisplayCount returns how many displayed moves show the first n stored
+// moves. Positions stored as move counts, such as a bookmark's MoveIndex,
+// convert to display positions with it.
+func DisplayCount(display []DisplayMove, n int) int {
+	return sort.Search(len(display), func(j int) bool {
+		return display[j].First >= n
+	})
+}
+
+// quarterTurns returns a turn as clockwise quarter turns (0-3).
+func quarterTurns(t gocube.Turn) int {
+	switch t {
+	case gocube.CCW:
+		return 3
+	case gocube.Double:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func turnFromQuarters(q int) gocube.Turn {
+	switch q {
+	case 2:
+		return gocube.Double
+	case 3:
+		return gocube.CCW
+	default:
+		return gocube.CW
+	}
+}
@@ -0,0 +1,231 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// MinAttemptMoves is the fewest moves a solved-to-solved cycle needs to count
+// as an attempt. Shorter cycles are fidgeting (a face turned and turned back)
+// and are left out of every attempt.
+const MinAttemptMoves = 8
+
+// Attempt is one solve attempt in a continuous capture: the moves that
+// scramble a solved cube, then the moves that solve it again. Indexes are
+// move indexes in the capture.
+type Attempt struct {
+	Start      int // First scramble move
+	SolveStart int // First solving move, after the longest pause (inspection)
+	End        int // One past the move that solved the cube
+}
+
+// ScrambleMoves returns the number of scramble moves.
+func (a Attempt) ScrambleMoves() int {
+	return a.SolveStart - a.Start
+}
+
+// SolveMoves returns the number of solving moves.
+func (a Attempt) SolveMoves() int {
+	return a.End - a.SolveStart
+}
+
+// DetectAttempts finds the solve attempts in a capture that starts from a
+// solved cube: each cycle from solved through scrambled back to solved is
+// one attempt. The solve is taken to start after the longest pause between
+// moves of the cycle, where the solver inspected the scramble. Moves after
+// the last return to solved belong to no attempt.
+func DetectAttempts(records []storage.MoveRecord) []Attempt {
+	var attempts []Attempt
+	cube := gocube.NewCube()
+	start := 0
+	for i, m := range storage.ToMoves(records) {
+		cube.Apply(m)
+		if !cube.IsSolved() {
+			continue
+		}
+		if i+1-start >= MinAttemptMoves {
+			attempts = append(attempts, Attempt{
+				Start:      start,
+				SolveStart: longestPauseAfter(records, start, i+1),
+				End:        i + 1,
+			})
+		}
+		start = i + 1
+	}
+	return attempts
+}
+
+// longestPauseAfter returns the index of the move in records[from:to] that
+// follows the longest pause, keeping at least one move on either side.
+func longestPauseAfter(records []storage.MoveRecord, from, to int) int {
+	best := from + 1
+	for i := from + 2; i < to; i++ {
+		if records[i].TsUs-records[i-1].TsUs > records[best].TsUs-records[best-1].TsUs {
+			best = i
+		}
+	}
+	return best
+}
+
+// MergeAttempts joins attempt a with the attempt b that follows it: the
+// merged attempt keeps a's scramble and solves until b's end, passing
+// through solved on the way.
+func MergeAttempts(a, b Attempt) Attempt {
+	return Attempt{Start: a.Start, SolveStart: a.SolveStart, End: b.End}
+}
+
+// InferScramble returns a short scramble reaching the state the attempt's
+// scramble moves left the cube in, as a random-state scramble would be
+// written.
+func InferScramble(records []storage.MoveRecord, a Attempt) string {
+	solution := gocube.SolveSequence(storage.ToMoves(records[a.Start:a.SolveStart]))
+	scramble := make([]gocube.Move, len(solution))
+	for i, m := range solution {
+		scramble[len(solution)-1-i] = m.Inverse()
+	}
+	return gocube.FormatMoves(scramble)
+}
+
+// SplitCapture stores each attempt of the capture solveID as a solve of its
+// own, in order, and returns their IDs. A new solve starts at its first
+// scramble move and ends with the move that solved the cube; it gets the
+// attempt's moves and orientations, an inferred scramble, scramble,
+// inspection and white_cross marks at the attempt's boundaries, and the
+// phase marks its moves reach. Derived data is rebuilt as for a recorded
+// solve. The capture itself is left unchanged. If storing any attempt
+// fails, the solves already created are deleted again.
+func SplitCapture(db *storage.DB, solveID string, attempts []Attempt) ([]string, error) {
+	solveRepo := storage.NewSolveRepository(db)
+	capture, err := solveRepo.Get(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solve: %w", err)
+	}
+	if capture == nil {
+		return nil, fmt.Errorf("solve not found: %s", solveID)
+	}
+	if capture.EndedAt == nil {
+		return nil, fmt.Errorf("solve %s has not ended", solveID)
+	}
+
+	records, err := storage.NewMoveRepository(db).GetBySolve(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moves: %w", err)
+	}
+	orientations, err := storage.NewOrientationRepository(db).GetBySolve(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orientations: %w", err)
+	}
+
+	var ids []string
+	for n, a := range attempts {
+		if a.Start < 0 || a.Start >= a.SolveStart || a.SolveStart >= a.End || a.End > len(records) {
+			deleteSolves(solveRepo, ids)
+			return nil, fmt.Errorf("attempt %d: invalid move range %d-%d", n+1, a.Start, a.End)
+		}
+		id, err := storeAttempt(db, capture, records, orientations, a)
+		if id != "" {
+			ids = append(ids, id)
+		}
+		if err != nil {
+			deleteSolves(solveRepo, ids)
+			return nil, fmt.Errorf("attempt %d: %w", n+1, err)
+		}
+	}
+	return ids, nil
+}
+
+// storeAttempt stores one attempt of capture as a new solve. The ID is
+// returned once the solve exists, even if filling it in fails.
+func storeAttempt(db *storage.DB, capture *storage.Solve, records []storage.MoveRecord, orientations []storage.OrientationRecord, a Attempt) (string, error) {
+	s := NewSession(db, nil)
+
+	// Solve start times are stored to the second, so the solve starts at
+	// the second of its first move and the timestamps are rebased on that
+	first := capture.StartedAt.Add(time.Duration(records[a.Start].TsUs) * time.Microsecond)
+	startedAt := first.Truncate(time.Second)
+	offsetUs := startedAt.Sub(capture.StartedAt).Microseconds()
+
+	deref := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	notes := fmt.Sprintf("attempt split from %s (moves %d-%d)", capture.SolveID[:8], a.Start+1, a.End)
+	id, err := s.solveRepo.CreateAt(startedAt, notes, InferScramble(records, a),
+		deref(capture.DeviceName), deref(capture.DeviceID), deref(capture.AppVersion))
+	if err != nil {
+		return "", err
+	}
+	if capture.CubeType != nil {
+		if err := s.solveRepo.SetCubeType(id, *capture.CubeType); err != nil {
+			return id, err
+		}
+	}
+
+	// Moves, marking each new highest phase of the solve as the record TUI does
+	cube := gocube.NewCube()
+	highest := gocube.PhaseScrambled
+	mark := func(tsUs int64, key string) error {
+		_, err := s.phaseRepo.CreatePhaseMark(id, tsUs/1000, key, nil)
+		return err
+	}
+	if err := mark(0, "scramble"); err != nil {
+		return id, err
+	}
+	for i := a.Start; i < a.End; i++ {
+		r := records[i]
+		tsUs := r.TsUs - offsetUs
+		if i == a.SolveStart {
+			// Inspection ran from the last scramble move to the first solving move
+			if err := mark(records[i-1].TsUs-offsetUs+1000, "inspection"); err != nil {
+				return id, err
+			}
+			if err := mark(tsUs-1000, "white_cross"); err != nil {
+				return id, err
+			}
+		}
+		m := storage.ToMoves(records[i : i+1])[0]
+		if _, err := s.moveRepo.Create(id, i-a.Start, tsUs, m, nil); err != nil {
+			return id, err
+		}
+		cube.Apply(m)
+		if i < a.SolveStart {
+			continue
+		}
+		if key, ok := DerivedPhaseKey(cube.Phase()); ok && cube.Phase() > highest {
+			highest = cube.Phase()
+			if err := mark(tsUs+1000, key); err != nil {
+				return id, err
+			}
+		}
+	}
+
+	for _, o := range orientations {
+		if o.TsUs >= records[a.Start].TsUs && o.TsUs <= records[a.End-1].TsUs {
+			if _, err := s.orientationRepo.Create(id, o.TsUs-offsetUs, o.UpFace, o.FrontFace, nil); err != nil {
+				return id, err
+			}
+		}
+	}
+
+	last := capture.StartedAt.Add(time.Duration(records[a.End-1].TsUs) * time.Microsecond)
+	if err := s.solveRepo.EndAt(id, last); err != nil {
+		return id, err
+	}
+	if _, err := RebuildDerived(context.Background(), db, id); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// deleteSolves deletes solves created before a failure, best effort.
+func deleteSolves(repo *storage.SolveRepository, ids []string) {
+	for _, id := range ids {
+		repo.Delete(id)
+	}
+}
@@ -0,0 +1,43 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/audio"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// EventTypeAudioSync is the event type stored when external audio is
+// aligned with a solve. The payload is the audio.Alignment, including the
+// amplitude markers shown in the visualizer.
+const EventTypeAudioSync = "audio_sync"
+
+// SaveAudioAlignment stores an alignment for a finished solve. The latest
+// stored alignment replaces earlier ones when loaded.
+func SaveAudioAlignment(eventRepo *storage.EventRepository, solveID string, a *audio.Alignment) error {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audio alignment: %w", err)
+	}
+	if _, err := eventRepo.Create(solveID, 0, EventTypeAudioSync, string(payload), nil); err != nil {
+		return fmt.Errorf("failed to store audio alignment: %w", err)
+	}
+	return nil
+}
+
+// LoadAudioAlignment returns the latest audio alignment of a solve, or nil
+// if audio was never aligned.
+func LoadAudioAlignment(eventRepo *storage.EventRepository, solveID string) (*audio.Alignment, error) {
+	events, err := eventRepo.GetByType(solveID, EventTypeAudioSync)
+	if err != nil || len(events) == 0 {
+		return nil, err
+	}
+
+	var a audio.Alignment
+	last := events[len(events)-1]
+	if err := json.Unmarshal([]byte(last.PayloadJSON), &a); err != nil {
+		return nil, fmt.Errorf("failed to decode audio alignment %d: %w", last.EventID, err)
+	}
+	return &a, nil
+}
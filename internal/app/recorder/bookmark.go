@@ -0,0 +1,66 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// EventTypeBookmark is the event type stored when the user marks a moment
+// in a solve (e.g. a lockup) to find it again in replay or the visualizer.
+const EventTypeBookmark = "bookmark"
+
+// Bookmark is a user-marked moment in a solve.
+type Bookmark struct {
+	TsMs      int64  `json:"ts_ms"`
+	MoveIndex int    `json:"move_index"` // moves recorded before the bookmark
+	Note      string `json:"note,omitempty"`
+}
+
+// bookmarkPayload is the stored payload of a bookmark event.
+type bookmarkPayload struct {
+	MoveIndex int    `json:"move_index"`
+	Note      string `json:"note,omitempty"`
+}
+
+// AddBookmark stores a bookmark at the current solve time.
+func (s *Session) AddBookmark(note string) (Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateRecording {
+		return Bookmark{}, fmt.Errorf("no solve in progress")
+	}
+
+	payload, err := json.Marshal(bookmarkPayload{MoveIndex: s.moveIndex, Note: note})
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("failed to marshal bookmark: %w", err)
+	}
+
+	tsUs := s.now().Sub(s.startTime).Microseconds()
+	if _, err := s.eventRepo.Create(s.solveID, tsUs, EventTypeBookmark, string(payload), nil); err != nil {
+		return Bookmark{}, fmt.Errorf("failed to store bookmark: %w", err)
+	}
+
+	return Bookmark{TsMs: tsUs / 1000, MoveIndex: s.moveIndex, Note: note}, nil
+}
+
+// LoadBookmarks returns the bookmarks of a solve in time order.
+func LoadBookmarks(eventRepo *storage.EventRepository, solveID string) ([]Bookmark, error) {
+	events, err := eventRepo.GetByType(solveID, EventTypeBookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := make([]Bookmark, 0, len(events))
+	for _, e := range events {
+		var p bookmarkPayload
+		if err := json.Unmarshal([]byte(e.PayloadJSON), &p); err != nil {
+			return nil, fmt.Errorf("failed to decode bookmark %d: %w", e.EventID, err)
+		}
+		bookmarks = append(bookmarks, Bookmark{TsMs: e.TsMs, MoveIndex: p.MoveIndex, Note: p.Note})
+	}
+
+	return bookmarks, nil
+}
@@ -0,0 +1,56 @@
+//go:build !js
+
+package recorder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+)
+
+func TestSoakWithChaosStaysConsistent(t *testing.T) {
+	db, err := storage.Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+
+	result, err := RunSoak(db, SoakConfig{
+		Events:        600,
+		MovesPerSolve: 120, // Past CheckpointInterval, so checkpoints are checked too
+		Seed:          3,
+		Chaos: ble.ChaosConfig{
+			Drop: 0.05, Duplicate: 0.05, Reorder: 0.05, Delay: 0.05,
+			MaxDelay: time.Second, Seed: 3,
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunSoak: %v", err)
+	}
+
+	c := result.Chaos
+	if c.Sent != 600 || c.Dropped == 0 || c.Duplicated == 0 || c.Reordered == 0 || c.Delayed == 0 {
+		t.Fatalf("chaos = %+v, want every fault over 600 events", c)
+	}
+	if c.Delivered != c.Sent-c.Dropped+c.Duplicated {
+		t.Errorf("chaos delivered %d of %+v; a delayed or held frame was lost", c.Delivered, c)
+	}
+	if result.Solves != 5 || result.Inconsistent != 0 {
+		t.Errorf("%d of %d solves inconsistent, want 0 of 5", result.Inconsistent, result.Solves)
+	}
+
+	// Every delivered turn was recorded
+	var moves int
+	if err := db.QueryRow("SELECT COUNT(*) FROM events WHERE event_type = 'rotation'").Scan(&moves); err != nil {
+		t.Fatal(err)
+	}
+	if moves != c.Delivered {
+		t.Errorf("%d rotation events stored, want the %d delivered", moves, c.Delivered)
+	}
+}
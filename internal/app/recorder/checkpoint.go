@@ -0,0 +1,56 @@
+package recorder
+
+import (
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// CheckpointInterval is how many moves apart cube state checkpoints are
+// stored while recording. A checkpoint is also stored at each resync.
+const CheckpointInterval = 100
+
+// StateAt returns the cube state after the first moveIndex moves of a solve.
+// It starts from the nearest checkpoint at or before moveIndex and replays
+// only the moves after it. Solves without checkpoints (recorded before
+// they existed) are replayed from a solved cube.
+func StateAt(db *storage.DB, solveID string, moveIndex int) (*gocube.Cube, error) {
+	return stateAt(storage.NewCheckpointRepository(db), storage.NewMoveRepository(db), solveID, moveIndex)
+}
+
+func stateAt(checkpointRepo *storage.CheckpointRepository, moveRepo *storage.MoveRepository, solveID string, moveIndex int) (*gocube.Cube, error) {
+	cube := gocube.NewCube()
+	from := 0
+
+	cp, err := checkpointRepo.GetAtOrBefore(solveID, moveIndex)
+	if err != nil {
+		return nil, err
+	}
+	if cp != nil {
+		cube = &gocube.Cube{Facelets: cp.Facelets}
+		from = cp.MoveIndex
+	}
+
+	records, err := moveRepo.GetBySolveIndexRange(solveID, from, moveIndex)
+	if err != nil {
+		return nil, err
+	}
+	cube.Apply(storage.ToMoves(records)...)
+	return cube, nil
+}
+
+// checkpoint stores the tracked cube state at the current move index.
+// Callers must hold s.mu.
+func (s *Session) checkpoint(tsUs int64) error {
+	err := s.checkpointRepo.Create(storage.Checkpoint{
+		SolveID:   s.solveID,
+		MoveIndex: s.moveIndex,
+		TsUs:      tsUs,
+		Facelets:  s.cube.Facelets,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+	return nil
+}
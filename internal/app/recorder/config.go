@@ -0,0 +1,281 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/render"
+)
+
+// Config holds user-editable recorder settings.
+type Config struct {
+	Pacing    PacingConfig    `json:"pacing"`
+	Telemetry TelemetryConfig `json:"telemetry"`
+
+	// SuperPhases group raw phases for reports and pacing budgets.
+	SuperPhases []analysis.SuperPhase `json:"super_phases,omitempty"`
+
+	// PhaseSchemes are user-defined phase splits for methods other than
+	// layer-by-layer, chosen with a profile's phase_scheme.
+	PhaseSchemes []PhaseScheme `json:"phase_schemes,omitempty"`
+
+	// Context holds default key/value metadata attached to each new solve
+	// (e.g. "cube": "gan12", "lube": "fresh").
+	Context map[string]string `json:"context,omitempty"`
+
+	// KeepAliveSeconds is how long the cube may be quiet before the record
+	// TUI pings it to prevent sleep and detect that it has slept. 0 disables.
+	KeepAliveSeconds int `json:"keep_alive_seconds"`
+
+	// IdleStopMinutes ends a solve automatically when no moves arrive for
+	// this long, so forgotten sessions don't skew trends. 0 disables.
+	IdleStopMinutes int `json:"idle_stop_minutes"`
+
+	// Timezone is the IANA zone (e.g. "Europe/Dublin") solve times are shown
+	// in and days are bucketed by. Empty uses the system zone. Timestamps
+	// are always stored in UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	Display DisplayConfig `json:"display"`
+
+	SetDown SetDownConfig `json:"set_down"`
+
+	// Hooks are commands run on solve start, each phase and solve end,
+	// e.g. to post results to a webhook.
+	Hooks []HookConfig `json:"hooks,omitempty"`
+
+	// Profile names the profile used when --profile is not given.
+	Profile  string             `json:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// DisplayConfig controls how moves are shown in the record and replay
+// TUIs. It never changes what is stored.
+type DisplayConfig struct {
+	// MergeMoves shows consecutive turns of a face as one move (R R as
+	// R2). Stored moves, bookmarks and reports keep the raw moves.
+	MergeMoves bool `json:"merge_moves"`
+
+	// HintAfterSeconds shows a next-move hint in the record TUI when a
+	// solve pauses this long. 0 disables; 'h' shows a hint at any time.
+	HintAfterSeconds int `json:"hint_after_seconds"`
+
+	// CubeStyle is how cube states are drawn: "letters" (default) or
+	// "blocks". Both are in color unless NO_COLOR is set.
+	CubeStyle string `json:"cube_style,omitempty"`
+}
+
+// SetDownConfig controls how reports treat the cube being put down mid-solve
+// (a phone call, a distraction). Detection needs the orientation stream, so
+// it works on GoCubes only.
+type SetDownConfig struct {
+	// MinSeconds is how long the cube must lie still, without moves, to
+	// count as put down. 0 disables detection.
+	MinSeconds int `json:"min_seconds"`
+
+	// FocusedTime reports a focused solve time, the solve time less the
+	// intervals the cube was put down, alongside the raw time.
+	FocusedTime bool `json:"focused_time"`
+}
+
+// PacingConfig configures per-phase pacing budgets and cues.
+type PacingConfig struct {
+	Enabled   bool             `json:"enabled"`
+	BudgetsMs map[string]int64 `json:"budgets_ms,omitempty"` // Keyed by phase or super-phase key
+	WarnRatio float64          `json:"warn_ratio,omitempty"` // Fraction of budget where warning cues begin
+	Bell      bool             `json:"bell,omitempty"`       // Ring the terminal bell when a cue escalates
+
+	WarnIntervalMs int64 `json:"warn_interval_ms,omitempty"` // Time between slow flashes
+	OverIntervalMs int64 `json:"over_interval_ms,omitempty"` // Time between fast flashes
+}
+
+// TelemetryConfig controls the opt-in anonymized telemetry upload.
+// Nothing is sent unless Enabled is true and Endpoint is set.
+type TelemetryConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	InstallID string `json:"install_id,omitempty"` // Random ID, not derived from any device
+	LastSent  string `json:"last_sent,omitempty"`  // RFC3339
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() Config {
+	return Config{
+		Pacing: PacingConfig{
+			WarnRatio:      0.75,
+			WarnIntervalMs: 3000,
+			OverIntervalMs: 1000,
+		},
+		KeepAliveSeconds: 30,
+		IdleStopMinutes:  10,
+		SetDown: SetDownConfig{
+			MinSeconds:  3,
+			FocusedTime: true,
+		},
+	}
+}
+
+// DefaultConfigPath returns the default config file path.
+func DefaultConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gocube_recorder", "config.json"), nil
+}
+
+// DefaultAlgorithmsPath returns the default algorithm library file path.
+func DefaultAlgorithmsPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gocube_recorder", "algorithms.json"), nil
+}
+
+// DefaultTemplatesDir returns the directory searched for export templates.
+func DefaultTemplatesDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".gocube_recorder", "templates"), nil
+}
+
+// LoadConfig loads the config from path, falling back to defaults for
+// missing files and unset fields.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return DefaultConfig(), fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return DefaultConfig(), fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the config for out-of-range values.
+func (c Config) Validate() error {
+	p := c.Pacing
+	if p.WarnRatio <= 0 || p.WarnRatio >= 1 {
+		return fmt.Errorf("pacing.warn_ratio must be between 0 and 1, got %v", p.WarnRatio)
+	}
+	if p.WarnIntervalMs < 0 || p.OverIntervalMs < 0 {
+		return fmt.Errorf("pacing intervals must not be negative")
+	}
+	for key, ms := range p.BudgetsMs {
+		if ms <= 0 {
+			return fmt.Errorf("pacing.budgets_ms.%s must be positive, got %d", key, ms)
+		}
+	}
+	if err := analysis.ValidateSuperPhases(c.SuperPhases); err != nil {
+		return fmt.Errorf("super_phases: %w", err)
+	}
+	schemes := make(map[string]bool)
+	for i, s := range c.PhaseSchemes {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("phase_schemes[%d]: %w", i, err)
+		}
+		if schemes[s.Name] {
+			return fmt.Errorf("phase_schemes[%d]: duplicate name %q", i, s.Name)
+		}
+		schemes[s.Name] = true
+	}
+	if c.KeepAliveSeconds < 0 {
+		return fmt.Errorf("keep_alive_seconds must not be negative, got %d", c.KeepAliveSeconds)
+	}
+	if c.IdleStopMinutes < 0 {
+		return fmt.Errorf("idle_stop_minutes must not be negative, got %d", c.IdleStopMinutes)
+	}
+	if _, err := render.ParseStyle(c.Display.CubeStyle); err != nil {
+		return fmt.Errorf("display.cube_style: %w", err)
+	}
+	if c.SetDown.MinSeconds < 0 {
+		return fmt.Errorf("set_down.min_seconds must not be negative, got %d", c.SetDown.MinSeconds)
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("timezone: unknown time zone %q", c.Timezone)
+		}
+	}
+	for key := range c.Context {
+		if key == "" {
+			return fmt.Errorf("context keys must not be empty")
+		}
+	}
+	for i, h := range c.Hooks {
+		if err := h.Validate(); err != nil {
+			return fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+	}
+	for name, p := range c.Profiles {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("profiles.%s: %w", name, err)
+		}
+		if _, err := c.PhaseScheme(p.PhaseScheme); err != nil {
+			return fmt.Errorf("profiles.%s: phase_scheme: %w", name, err)
+		}
+	}
+	if c.Profile != "" {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			return fmt.Errorf("profile: no profile named %q", c.Profile)
+		}
+	}
+	return nil
+}
+
+// LoadDefaultConfig loads the config from the default path.
+func LoadDefaultConfig() (Config, error) {
+	path, err := DefaultConfigPath()
+	if err != nil {
+		return DefaultConfig(), err
+	}
+	return LoadConfig(path)
+}
+
+// Location returns the configured time zone, or the system zone if none
+// is set or it cannot be loaded.
+func (c Config) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// SaveConfig writes the config to path.
+func SaveConfig(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// EventTypeDeviceChange is the event type stored when a solve continues on
+// a different cube connection, e.g. after the first cube's battery died.
+const EventTypeDeviceChange = "device_change"
+
+// DeviceChange is a point in a solve where recording moved to another cube.
+type DeviceChange struct {
+	TsMs      int64  `json:"ts_ms"`
+	MoveIndex int    `json:"move_index"` // moves recorded before the change
+	FromName  string `json:"from_name,omitempty"`
+	FromID    string `json:"from_id,omitempty"`
+	ToName    string `json:"to_name"`
+	ToID      string `json:"to_id"`
+}
+
+// deviceChangePayload is the stored payload of a device change event.
+type deviceChangePayload struct {
+	MoveIndex int    `json:"move_index"`
+	FromName  string `json:"from_name,omitempty"`
+	FromID    string `json:"from_id,omitempty"`
+	ToName    string `json:"to_name"`
+	ToID      string `json:"to_id"`
+}
+
+// ChangeDevice records that the solve in progress continues on the device
+// toID. The previous device is the solve's original one or the target of
+// the last change. It is a no-op if the device is unchanged.
+func (s *Session) ChangeDevice(toName, toID string) (*DeviceChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateRecording {
+		return nil, fmt.Errorf("no solve in progress")
+	}
+
+	fromName, fromID, err := s.deviceOf(s.solveID)
+	if err != nil {
+		return nil, err
+	}
+	if fromID == toID {
+		return nil, nil
+	}
+	correction, err := s.correctionFor(toID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(deviceChangePayload{
+		MoveIndex: s.moveIndex,
+		FromName:  fromName,
+		FromID:    fromID,
+		ToName:    toName,
+		ToID:      toID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal device change: %w", err)
+	}
+
+	tsUs := s.now().Sub(s.startTime).Microseconds()
+	if _, err := s.eventRepo.Create(s.solveID, tsUs, EventTypeDeviceChange, string(payload), nil); err != nil {
+		return nil, fmt.Errorf("failed to store device change: %w", err)
+	}
+	s.correction = correction
+
+	return &DeviceChange{
+		TsMs:      tsUs / 1000,
+		MoveIndex: s.moveIndex,
+		FromName:  fromName,
+		FromID:    fromID,
+		ToName:    toName,
+		ToID:      toID,
+	}, nil
+}
+
+// deviceOf returns the device a solve is currently recorded on: its
+// original device or the target of the last change.
+func (s *Session) deviceOf(solveID string) (name, id string, err error) {
+	changes, err := LoadDeviceChanges(s.eventRepo, solveID)
+	if err != nil {
+		return "", "", err
+	}
+	if len(changes) > 0 {
+		last := changes[len(changes)-1]
+		return last.ToName, last.ToID, nil
+	}
+
+	solve, err := s.solveRepo.Get(solveID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve != nil && solve.DeviceID != nil {
+		id = *solve.DeviceID
+	}
+	if solve != nil && solve.DeviceName != nil {
+		name = *solve.DeviceName
+	}
+	return name, id, nil
+}
+
+// LoadDeviceChanges returns the device changes of a solve in time order.
+func LoadDeviceChanges(eventRepo *storage.EventRepository, solveID string) ([]DeviceChange, error) {
+	events, err := eventRepo.GetByType(solveID, EventTypeDeviceChange)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]DeviceChange, 0, len(events))
+	for _, e := range events {
+		var p deviceChangePayload
+		if err := json.Unmarshal([]byte(e.PayloadJSON), &p); err != nil {
+			return nil, fmt.Errorf("failed to decode device change %d: %w", e.EventID, err)
+		}
+		changes = append(changes, DeviceChange{
+			TsMs:      e.TsMs,
+			MoveIndex: p.MoveIndex,
+			FromName:  p.FromName,
+			FromID:    p.FromID,
+			ToName:    p.ToName,
+			ToID:      p.ToID,
+		})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,311 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Solve lifecycle events passed to hooks.
+const (
+	HookSolveStart  = "solve_start"
+	HookPhaseChange = "phase_change"
+	HookSolveEnd    = "solve_end"
+)
+
+// HookEvents lists the hook events in the order a solve fires them.
+var HookEvents = []string{HookSolveStart, HookPhaseChange, HookSolveEnd}
+
+// defaultHookTimeout is how long a hook command may run when its config
+// sets no timeout.
+const defaultHookTimeout = 30 * time.Second
+
+// HookEvent is a solve lifecycle event, as passed to hooks. Its JSON is
+// what hook commands read on stdin.
+type HookEvent struct {
+	Event   string                 `json:"event"`
+	At      string                 `json:"at"` // RFC3339 UTC
+	SolveID string                 `json:"solve_id"`
+	Phase   string                 `json:"phase,omitempty"` // The phase reached, for phase_change
+	Solve   *storage.Solve         `json:"solve"`
+	Moves   []storage.MoveRecord   `json:"moves,omitempty"`  // For solve_end
+	Phases  []storage.PhaseSegment `json:"phases,omitempty"` // For solve_end
+}
+
+// Hook receives the lifecycle events of the solves a session records.
+type Hook interface {
+	HandleSolveEvent(e *HookEvent) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(e *HookEvent) error
+
+// HandleSolveEvent calls f(e).
+func (f HookFunc) HandleSolveEvent(e *HookEvent) error {
+	return f(e)
+}
+
+// HookConfig configures an external command run on solve events.
+type HookConfig struct {
+	// Command is the program and its arguments, run without a shell; use
+	// ["sh", "-c", "..."] for one.
+	Command []string `json:"command"`
+
+	// Events limits the hook to these events; empty means all.
+	Events []string `json:"events,omitempty"`
+
+	// TimeoutSeconds stops the command if it runs this long. 0 means 30.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Validate checks the hook command and events.
+func (c HookConfig) Validate() error {
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return fmt.Errorf("command must not be empty")
+	}
+	for _, e := range c.Events {
+		if !validHookEvent(e) {
+			return fmt.Errorf("unknown event %q (use %s)", e, strings.Join(HookEvents, ", "))
+		}
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative, got %d", c.TimeoutSeconds)
+	}
+	return nil
+}
+
+func validHookEvent(e string) bool {
+	for _, known := range HookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandHook runs an external command for each event, with the event's
+// JSON on stdin and GOCUBE_EVENT, GOCUBE_SOLVE_ID and GOCUBE_PHASE set in
+// its environment.
+type CommandHook struct {
+	cfg HookConfig
+}
+
+// NewCommandHook creates a hook that runs the configured command.
+func NewCommandHook(cfg HookConfig) *CommandHook {
+	return &CommandHook{cfg: cfg}
+}
+
+// HandleSolveEvent runs the command if it wants the event. A command that
+// exits non-zero or times out fails with its stderr.
+func (h *CommandHook) HandleSolveEvent(e *HookEvent) error {
+	if len(h.cfg.Events) > 0 && !containsString(h.cfg.Events, e.Event) {
+		return nil
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", e.Event, err)
+	}
+
+	timeout := defaultHookTimeout
+	if h.cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(h.cfg.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.cfg.Command[0], h.cfg.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"GOCUBE_EVENT="+e.Event,
+		"GOCUBE_SOLVE_ID="+e.SolveID,
+		"GOCUBE_PHASE="+e.Phase,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return fmt.Errorf("hook %s failed on %s: %w", h.cfg.Command[0], e.Event, err)
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// hookQueue delivers events to the hooks in order on its own goroutine, so
+// a slow hook never holds up recording.
+type hookQueue struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	pending []*HookEvent
+	running bool
+	onError func(error)
+	idle    *sync.Cond
+
+	// fill completes an event with the solve from the database before
+	// delivery
+	fill func(e *HookEvent) error
+}
+
+func newHookQueue(fill func(e *HookEvent) error) *hookQueue {
+	q := &hookQueue{fill: fill}
+	q.idle = sync.NewCond(&q.mu)
+	return q
+}
+
+// add registers a hook.
+func (q *hookQueue) add(h Hook) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.hooks = append(q.hooks, h)
+}
+
+// setErrorCallback sets the callback for hooks that fail.
+func (q *hookQueue) setErrorCallback(cb func(error)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onError = cb
+}
+
+// push queues an event, if there are hooks to deliver it to.
+func (q *hookQueue) push(e *HookEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.hooks) == 0 {
+		return
+	}
+	q.pending = append(q.pending, e)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+// run delivers queued events until the queue is empty.
+func (q *hookQueue) run() {
+	q.mu.Lock()
+	for len(q.pending) > 0 {
+		e := q.pending[0]
+		q.pending = q.pending[1:]
+		hooks := append([]Hook(nil), q.hooks...)
+		onError := q.onError
+		q.mu.Unlock()
+
+		var errs []error
+		if err := q.fill(e); err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, h := range hooks {
+				if err := h.HandleSolveEvent(e); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		if onError != nil {
+			for _, err := range errs {
+				onError(err)
+			}
+		}
+
+		q.mu.Lock()
+	}
+	q.running = false
+	q.idle.Broadcast()
+	q.mu.Unlock()
+}
+
+// wait blocks until every queued event has been delivered.
+func (q *hookQueue) wait() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.running {
+		q.idle.Wait()
+	}
+}
+
+// AddHook registers a hook for the lifecycle events of the solves this
+// session records: solve_start, phase_change for each phase marked, and
+// solve_end once the phases are worked out. Hooks run in order on their
+// own goroutine.
+func (s *Session) AddHook(h Hook) {
+	s.hooks.add(h)
+}
+
+// SetHookErrorCallback sets the callback for hooks that fail. It is called
+// on the hooks' goroutine.
+func (s *Session) SetHookErrorCallback(cb func(error)) {
+	s.hooks.setErrorCallback(cb)
+}
+
+// WaitHooks blocks until the hooks have handled every event fired so far.
+// Call it before exiting so hook commands are not cut off.
+func (s *Session) WaitHooks() {
+	s.hooks.wait()
+}
+
+// fireHook queues a lifecycle event for the current solve. Callers must
+// hold s.mu.
+func (s *Session) fireHook(event, phase string) {
+	s.hooks.push(&HookEvent{
+		Event:   event,
+		At:      s.now().UTC().Format(time.RFC3339Nano),
+		SolveID: s.solveID,
+		Phase:   phase,
+	})
+}
+
+// fillHookEvent adds the solve, and for solve_end its moves and phases, to
+// a hook event.
+func (s *Session) fillHookEvent(e *HookEvent) error {
+	solve, err := s.solveRepo.Get(e.SolveID)
+	if err != nil {
+		return fmt.Errorf("hook %s: %w", e.Event, err)
+	}
+	e.Solve = solve
+	if e.Event != HookSolveEnd {
+		return nil
+	}
+	if e.Moves, err = s.moveRepo.GetBySolve(e.SolveID); err != nil {
+		return fmt.Errorf("hook %s: %w", e.Event, err)
+	}
+	if e.Phases, err = s.phaseRepo.GetPhaseSegments(e.SolveID); err != nil {
+		return fmt.Errorf("hook %s: %w", e.Event, err)
+	}
+	return nil
+}
+
+// SolveEndEvent builds the solve_end event of a stored solve, e.g. to try
+// out a hook on a solve recorded earlier.
+func SolveEndEvent(db *storage.DB, solveID string) (*HookEvent, error) {
+	s := NewSession(db, nil)
+	e := &HookEvent{Event: HookSolveEnd, SolveID: solveID}
+	if err := s.fillHookEvent(e); err != nil {
+		return nil, err
+	}
+	if e.Solve == nil {
+		return nil, fmt.Errorf("solve %s not found", solveID)
+	}
+	if e.Solve.EndedAt != nil {
+		e.At = e.Solve.EndedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return e, nil
+}
@@ -0,0 +1,58 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EventTypeIdleStop is the event type stored when a solve is ended
+// automatically because no moves arrived for too long.
+const EventTypeIdleStop = "idle_stop"
+
+// idleStopPayload is the stored payload of an idle stop event.
+type idleStopPayload struct {
+	IdleMs int64 `json:"idle_ms"` // idle time trimmed from the end of the solve
+}
+
+// IdleFor returns how long the current solve has gone without a move, or 0
+// if no solve is being recorded.
+func (s *Session) IdleFor() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.state != StateRecording {
+		return 0
+	}
+	return s.now().Sub(s.lastMove)
+}
+
+// EndIdle ends a forgotten solve. The solve is ended at its last move rather
+// than now, so the idle time does not inflate its duration, and it is
+// annotated in the notes and with an idle_stop event.
+func (s *Session) EndIdle() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateRecording {
+		return fmt.Errorf("no solve in progress")
+	}
+
+	now := s.now()
+	idle := now.Sub(s.lastMove)
+
+	payload, err := json.Marshal(idleStopPayload{IdleMs: idle.Milliseconds()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal idle stop: %w", err)
+	}
+	tsUs := s.lastMove.Sub(s.startTime).Microseconds()
+	if _, err := s.eventRepo.Create(s.solveID, tsUs, EventTypeIdleStop, string(payload), nil); err != nil {
+		return fmt.Errorf("failed to store idle stop: %w", err)
+	}
+
+	note := fmt.Sprintf("auto-stopped after %s without moves", idle.Round(time.Second))
+	if err := s.solveRepo.AppendNotes(s.solveID, note); err != nil {
+		return err
+	}
+
+	return s.endAt(s.lastMove)
+}
@@ -0,0 +1,207 @@
+package recorder
+
+import (
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+)
+
+// CueLevel is the escalation level of a pacing cue.
+type CueLevel int
+
+const (
+	CueNone       CueLevel = iota // Within budget
+	CueWarning                    // Approaching budget
+	CueOverBudget                 // Budget exceeded
+)
+
+// String returns the string representation of the cue level.
+func (l CueLevel) String() string {
+	switch l {
+	case CueNone:
+		return "none"
+	case CueWarning:
+		return "warning"
+	case CueOverBudget:
+		return "over_budget"
+	default:
+		return "unknown"
+	}
+}
+
+// PacingEngine tracks time spent in the current phase against the
+// configured budget and decides when escalating cues should fire.
+// It is driven by the caller's clock so it can run alongside the tracker.
+type PacingEngine struct {
+	cfg    PacingConfig
+	supers []analysis.SuperPhase
+
+	phase      string
+	phaseStart time.Time
+	super      string
+	superStart time.Time
+	active     bool
+	level      CueLevel
+	lastCue    time.Time
+}
+
+// NewPacingEngine creates a pacing engine from config.
+func NewPacingEngine(cfg PacingConfig) *PacingEngine {
+	p := &PacingEngine{}
+	p.SetConfig(cfg)
+	return p
+}
+
+// SetConfig replaces the pacing config without resetting the phase
+// currently being timed, so budgets can be reloaded mid-solve.
+func (p *PacingEngine) SetConfig(cfg PacingConfig) {
+	defaults := DefaultConfig().Pacing
+	if cfg.WarnRatio <= 0 || cfg.WarnRatio >= 1 {
+		cfg.WarnRatio = defaults.WarnRatio
+	}
+	if cfg.WarnIntervalMs <= 0 {
+		cfg.WarnIntervalMs = defaults.WarnIntervalMs
+	}
+	if cfg.OverIntervalMs <= 0 {
+		cfg.OverIntervalMs = defaults.OverIntervalMs
+	}
+	p.cfg = cfg
+}
+
+// SetSuperPhases sets the super-phase definitions. Budgets keyed by a
+// super-phase key cover the time spent across all of its member phases.
+func (p *PacingEngine) SetSuperPhases(supers []analysis.SuperPhase) {
+	p.supers = supers
+}
+
+// Enabled returns true if pacing cues are enabled.
+func (p *PacingEngine) Enabled() bool {
+	return p.cfg.Enabled
+}
+
+// Bell returns true if escalations should ring the terminal bell.
+func (p *PacingEngine) Bell() bool {
+	return p.cfg.Bell
+}
+
+// Budget returns the budget for a phase, if one is configured.
+func (p *PacingEngine) Budget(phaseKey string) (time.Duration, bool) {
+	ms, ok := p.cfg.BudgetsMs[phaseKey]
+	if !ok || ms <= 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// EnterPhase starts timing a new phase.
+func (p *PacingEngine) EnterPhase(phaseKey string, at time.Time) {
+	sp, inSuper := analysis.FindSuperPhase(p.supers, phaseKey)
+	if !inSuper {
+		p.super = ""
+	} else if !p.active || sp.Key != p.super {
+		p.super = sp.Key
+		p.superStart = at
+	}
+
+	p.phase = phaseKey
+	p.phaseStart = at
+	p.active = true
+	p.level = CueNone
+	p.lastCue = time.Time{}
+}
+
+// Stop stops timing; no further cues fire until the next EnterPhase.
+func (p *PacingEngine) Stop() {
+	p.active = false
+	p.super = ""
+	p.level = CueNone
+}
+
+// Phase returns the phase currently being timed.
+func (p *PacingEngine) Phase() string {
+	return p.phase
+}
+
+// SuperPhase returns the super-phase currently being timed, if any.
+func (p *PacingEngine) SuperPhase() string {
+	return p.super
+}
+
+// SuperElapsed returns the time spent in the current super-phase.
+func (p *PacingEngine) SuperElapsed(now time.Time) time.Duration {
+	if !p.active || p.super == "" {
+		return 0
+	}
+	return now.Sub(p.superStart)
+}
+
+// Level returns the current cue level.
+func (p *PacingEngine) Level() CueLevel {
+	return p.level
+}
+
+// Elapsed returns the time spent in the current phase.
+func (p *PacingEngine) Elapsed(now time.Time) time.Duration {
+	if !p.active {
+		return 0
+	}
+	return now.Sub(p.phaseStart)
+}
+
+// Update advances the engine to now. It returns the current cue level and
+// whether a cue should be emitted now. Cues repeat at the configured
+// interval for the level; an escalation always fires immediately.
+func (p *PacingEngine) Update(now time.Time) (CueLevel, bool) {
+	if !p.cfg.Enabled || !p.active {
+		return CueNone, false
+	}
+
+	phaseBudget, hasPhase := p.Budget(p.phase)
+	superBudget, hasSuper := p.Budget(p.super)
+	if !hasPhase && !hasSuper {
+		return CueNone, false
+	}
+
+	level := CueNone
+	if hasPhase {
+		level = p.levelFor(now.Sub(p.phaseStart), phaseBudget)
+	}
+	if hasSuper && p.super != "" {
+		if l := p.levelFor(now.Sub(p.superStart), superBudget); l > level {
+			level = l
+		}
+	}
+
+	if level == CueNone {
+		p.level = level
+		return level, false
+	}
+
+	if level > p.level {
+		p.level = level
+		p.lastCue = now
+		return level, true
+	}
+
+	interval := time.Duration(p.cfg.WarnIntervalMs) * time.Millisecond
+	if level == CueOverBudget {
+		interval = time.Duration(p.cfg.OverIntervalMs) * time.Millisecond
+	}
+	if now.Sub(p.lastCue) >= interval {
+		p.lastCue = now
+		return level, true
+	}
+
+	return level, false
+}
+
+// levelFor returns the cue level for elapsed time against a budget.
+func (p *PacingEngine) levelFor(elapsed, budget time.Duration) CueLevel {
+	switch {
+	case elapsed >= budget:
+		return CueOverBudget
+	case float64(elapsed) >= float64(budget)*p.cfg.WarnRatio:
+		return CueWarning
+	}
+	return CueNone
+}
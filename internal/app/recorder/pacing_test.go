@@ -0,0 +1,116 @@
+package recorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+)
+
+// pacingStep is an Update at an offset from the start and what it returns.
+type pacingStep struct {
+	at    time.Duration
+	level CueLevel
+	fire  bool
+}
+
+func checkPacing(t *testing.T, p *PacingEngine, start time.Time, steps []pacingStep) {
+	t.Helper()
+	for _, s := range steps {
+		level, fire := p.Update(start.Add(s.at))
+		if level != s.level || fire != s.fire {
+			t.Errorf("Update at %v = %s, %v; want %s, %v", s.at, level, fire, s.level, s.fire)
+		}
+	}
+}
+
+func TestPacingEscalates(t *testing.T) {
+	// Default warn ratio 0.75 and cue intervals of 3s and 1s
+	p := NewPacingEngine(PacingConfig{Enabled: true, BudgetsMs: map[string]int64{"cross": 10000}})
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	p.EnterPhase("cross", start)
+
+	checkPacing(t, p, start, []pacingStep{
+		{5 * time.Second, CueNone, false},
+		{7500 * time.Millisecond, CueWarning, true}, // Escalations fire at once
+		{9 * time.Second, CueWarning, false},
+		{10 * time.Second, CueOverBudget, true},
+		{10500 * time.Millisecond, CueOverBudget, false},
+		{11 * time.Second, CueOverBudget, true}, // Repeats at the over interval
+		{11500 * time.Millisecond, CueOverBudget, false},
+	})
+	if p.Level() != CueOverBudget || p.Elapsed(start.Add(11*time.Second)) != 11*time.Second {
+		t.Errorf("Level = %s, Elapsed = %v", p.Level(), p.Elapsed(start.Add(11*time.Second)))
+	}
+
+	// A phase without a budget never cues, and entering it resets the level
+	p.EnterPhase("f2l_1", start.Add(12*time.Second))
+	checkPacing(t, p, start, []pacingStep{{60 * time.Second, CueNone, false}})
+
+	p.Stop()
+	if level, fire := p.Update(start.Add(90 * time.Second)); level != CueNone || fire {
+		t.Errorf("Update after Stop = %s, %v", level, fire)
+	}
+}
+
+func TestPacingWarningRepeats(t *testing.T) {
+	p := NewPacingEngine(PacingConfig{
+		Enabled:        true,
+		BudgetsMs:      map[string]int64{"cross": 10000},
+		WarnRatio:      0.5,
+		WarnIntervalMs: 1000,
+	})
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	p.EnterPhase("cross", start)
+
+	checkPacing(t, p, start, []pacingStep{
+		{4 * time.Second, CueNone, false},
+		{5 * time.Second, CueWarning, true},
+		{5500 * time.Millisecond, CueWarning, false},
+		{6 * time.Second, CueWarning, true},
+	})
+}
+
+func TestPacingSuperPhaseBudget(t *testing.T) {
+	p := NewPacingEngine(PacingConfig{Enabled: true, BudgetsMs: map[string]int64{"f2l": 4000}})
+	p.SetSuperPhases([]analysis.SuperPhase{{Key: "f2l", Phases: []string{"f2l_1", "f2l_2"}}})
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	// The super-phase clock keeps running across its member phases
+	p.EnterPhase("f2l_1", start)
+	p.EnterPhase("f2l_2", start.Add(2*time.Second))
+	if p.SuperPhase() != "f2l" || p.SuperElapsed(start.Add(3*time.Second)) != 3*time.Second {
+		t.Fatalf("SuperPhase = %q, SuperElapsed = %v; want f2l, 3s", p.SuperPhase(), p.SuperElapsed(start.Add(3*time.Second)))
+	}
+	checkPacing(t, p, start, []pacingStep{
+		{2500 * time.Millisecond, CueNone, false},
+		{3 * time.Second, CueWarning, true},
+		{4 * time.Second, CueOverBudget, true},
+	})
+
+	// Leaving the super-phase stops its clock
+	p.EnterPhase("oll", start.Add(5*time.Second))
+	if p.SuperPhase() != "" {
+		t.Errorf("SuperPhase after leaving = %q", p.SuperPhase())
+	}
+	checkPacing(t, p, start, []pacingStep{{30 * time.Second, CueNone, false}})
+}
+
+func TestPacingSetConfigKeepsPhase(t *testing.T) {
+	p := NewPacingEngine(PacingConfig{})
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	p.EnterPhase("cross", start)
+
+	// Disabled pacing never cues
+	checkPacing(t, p, start, []pacingStep{{time.Minute, CueNone, false}})
+
+	// A reload mid-solve times the phase already under way
+	p.SetConfig(PacingConfig{Enabled: true, BudgetsMs: map[string]int64{"cross": 30000}, WarnRatio: 2})
+	if p.Phase() != "cross" {
+		t.Fatalf("Phase after SetConfig = %q", p.Phase())
+	}
+	checkPacing(t, p, start, []pacingStep{
+		{20 * time.Second, CueNone, false},
+		{23 * time.Second, CueWarning, true}, // The invalid ratio fell back to 0.75
+	})
+}
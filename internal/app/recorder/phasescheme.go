@@ -0,0 +1,292 @@
+package recorder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// LBLScheme is the name of the built-in layer-by-layer phases, detected
+// from gocube.Phase. It is used when no phase scheme is set.
+const LBLScheme = "lbl"
+
+// PhaseScheme is an ordered list of phases to split solves into, for
+// methods other than layer-by-layer (e.g. Roux or ZZ). The recorder marks
+// the first phase when the solve starts, and each following phase when
+// the one before it is done: when its Done pattern matches the tracked
+// cube, or by hand with the number keys.
+//
+// A scheme with a Method has one phase per step of that gocube method
+// (gocube.MethodRoux or gocube.MethodZZ), which detects when each is done
+// instead of Done patterns.
+type PhaseScheme struct {
+	Name   string        `json:"name"`
+	Method string        `json:"method,omitempty"`
+	Phases []SchemePhase `json:"phases"`
+}
+
+// SchemePhase is one phase of a PhaseScheme.
+type SchemePhase struct {
+	Key  string `json:"key"`            // Storage key, e.g. "first_block"
+	Name string `json:"name,omitempty"` // Display name; defaults from the key
+	Done string `json:"done,omitempty"` // gocube.ParsePattern pattern; empty marks by hand only
+}
+
+// reservedPhaseKeys are marked by the recorder itself around every scheme.
+var reservedPhaseKeys = map[string]bool{
+	"scramble":   true,
+	"inspection": true,
+	"complete":   true,
+}
+
+// builtinSchemes can be chosen without defining them. Like the layer-by-layer
+// phases they build on the white (U) face first. A scheme of the same name
+// in the config replaces them.
+var builtinSchemes = []PhaseScheme{
+	{
+		Name:   "roux",
+		Method: gocube.MethodRoux,
+		Phases: []SchemePhase{
+			{Key: "first_block", Name: "First Block"},
+			{Key: "second_block", Name: "Second Block"},
+			{Key: "cmll", Name: "CMLL"},
+			{Key: "lse", Name: "LSE"},
+		},
+	},
+	{
+		Name:   "zz",
+		Method: gocube.MethodZZ,
+		Phases: []SchemePhase{
+			{Key: "eoline", Name: "EOLine"},
+			{Key: "zz_f2l", Name: "ZZ F2L"},
+			{Key: "zz_ll", Name: "ZZ LL"},
+		},
+	},
+}
+
+// DisplayName returns the phase's name, or one made from its key.
+func (p SchemePhase) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return storage.PhaseDisplayName(p.Key)
+}
+
+// RegisterPhaseDefs stores the scheme's phases as phase definitions, so
+// reports show their names.
+func (s PhaseScheme) RegisterPhaseDefs(db *storage.DB) error {
+	defs := make([]storage.PhaseDef, len(s.Phases))
+	for i, p := range s.Phases {
+		defs[i] = storage.PhaseDef{PhaseKey: p.Key, DisplayName: p.DisplayName(), OrderIndex: i + 1, IsActive: true}
+	}
+	return storage.NewPhaseRepository(db).RegisterPhaseDefs(s.Name, defs)
+}
+
+// Validate checks the scheme's name, keys and patterns.
+func (s PhaseScheme) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("name must not be empty")
+	}
+	if s.Name == LBLScheme {
+		return fmt.Errorf("name %q is the built-in layer-by-layer scheme", LBLScheme)
+	}
+	if len(s.Phases) == 0 {
+		return fmt.Errorf("%s: needs at least one phase", s.Name)
+	}
+	if s.Method != "" {
+		steps, err := gocube.MethodPhases(s.Method)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if len(s.Phases) != len(steps)-1 {
+			return fmt.Errorf("%s: method %s needs %d phases, got %d", s.Name, s.Method, len(steps)-1, len(s.Phases))
+		}
+	}
+	seen := make(map[string]bool)
+	for i, p := range s.Phases {
+		if !validPhaseKey(p.Key) {
+			return fmt.Errorf("%s: phases[%d]: key must be lowercase letters, digits and underscores, got %q", s.Name, i, p.Key)
+		}
+		if reservedPhaseKeys[p.Key] {
+			return fmt.Errorf("%s: phases[%d]: key %q is marked by the recorder itself", s.Name, i, p.Key)
+		}
+		if seen[p.Key] {
+			return fmt.Errorf("%s: phases[%d]: duplicate key %q", s.Name, i, p.Key)
+		}
+		seen[p.Key] = true
+		if p.Done != "" && s.Method != "" {
+			return fmt.Errorf("%s: phases[%d]: done patterns are not used with a method", s.Name, i)
+		}
+		if p.Done != "" {
+			if _, err := gocube.ParsePattern(p.Done); err != nil {
+				return fmt.Errorf("%s: phases[%d]: done: %w", s.Name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validPhaseKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// PhaseScheme returns the scheme called name, from the config or built in,
+// or nil for the layer-by-layer phases (name empty or "lbl").
+func (c Config) PhaseScheme(name string) (*PhaseScheme, error) {
+	if name == "" || name == LBLScheme {
+		return nil, nil
+	}
+	for _, s := range c.PhaseSchemes {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	for _, s := range builtinSchemes {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("no phase scheme named %q (available: %s)", name, strings.Join(c.PhaseSchemeNames(), ", "))
+}
+
+// PhaseSchemeNames returns the names of the schemes that can be chosen, the
+// layer-by-layer one first.
+func (c Config) PhaseSchemeNames() []string {
+	names := []string{LBLScheme}
+	seen := map[string]bool{LBLScheme: true}
+	for _, list := range [][]PhaseScheme{c.PhaseSchemes, builtinSchemes} {
+		for _, s := range list {
+			if !seen[s.Name] {
+				seen[s.Name] = true
+				names = append(names, s.Name)
+			}
+		}
+	}
+	return names
+}
+
+// SchemeTracker follows a solve through the phases of a PhaseScheme. It only
+// moves forward: a phase done and then undone does not take it back.
+type SchemeTracker struct {
+	scheme  PhaseScheme
+	done    []*gocube.Pattern // Per phase; nil for phases marked by hand
+	steps   []gocube.Phase    // The method's phases, if the scheme has one
+	current int               // Index of the phase being solved; len(phases) when complete
+}
+
+// NewSchemeTracker returns a tracker for a valid scheme, at its first phase.
+func NewSchemeTracker(s PhaseScheme) (*SchemeTracker, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	t := &SchemeTracker{scheme: s, done: make([]*gocube.Pattern, len(s.Phases))}
+	if s.Method != "" {
+		t.steps, _ = gocube.MethodPhases(s.Method)
+	}
+	for i, p := range s.Phases {
+		if p.Done != "" {
+			pattern := gocube.MustParsePattern(p.Done)
+			t.done[i] = &pattern
+		}
+	}
+	return t, nil
+}
+
+// Scheme returns the scheme being tracked.
+func (t *SchemeTracker) Scheme() PhaseScheme {
+	return t.scheme
+}
+
+// Reset goes back to the first phase and returns its key, to be marked as
+// the solve starts.
+func (t *SchemeTracker) Reset() string {
+	t.current = 0
+	return t.scheme.Phases[0].Key
+}
+
+// Update checks the cube against the phases not yet done. If the current
+// phase, or any after it, is now done it returns the key of the phase that
+// begins, "complete" after the last one, and true. A solved cube completes
+// every phase.
+func (t *SchemeTracker) Update(c *gocube.Cube) (string, bool) {
+	next := -1
+	if t.steps != nil {
+		// Phase i is done once the method has completed step i+1
+		reached, _ := c.PhaseFor(t.scheme.Method)
+		for i, p := range t.steps {
+			if p == reached {
+				next = i
+			}
+		}
+	}
+	for i := t.current; i < len(t.done); i++ {
+		if t.done[i] != nil && c.Matches(*t.done[i]) {
+			next = i + 1
+		}
+	}
+	if c.IsSolved() {
+		next = len(t.done)
+	}
+	if next <= t.current {
+		return "", false
+	}
+	t.current = next
+	return t.Current(), true
+}
+
+// Mark moves the tracker to the phase with key, after it was marked by
+// hand. It reports false for keys not in the scheme.
+func (t *SchemeTracker) Mark(key string) bool {
+	if key == "complete" {
+		t.current = len(t.scheme.Phases)
+		return true
+	}
+	for i, p := range t.scheme.Phases {
+		if p.Key == key {
+			t.current = i
+			return true
+		}
+	}
+	return false
+}
+
+// Current returns the key of the phase being solved, or "complete".
+func (t *SchemeTracker) Current() string {
+	if t.current >= len(t.scheme.Phases) {
+		return "complete"
+	}
+	return t.scheme.Phases[t.current].Key
+}
+
+// Phase returns the phase with key, and false if it is not in the scheme.
+func (t *SchemeTracker) Phase(key string) (SchemePhase, bool) {
+	for _, p := range t.scheme.Phases {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return SchemePhase{}, false
+}
+
+// NumberToPhaseKey returns the key of phase num (1 for the first phase) for
+// the number keys, 0 for inspection, or "" past the last phase.
+func (t *SchemeTracker) NumberToPhaseKey(num int) string {
+	switch {
+	case num == 0:
+		return "inspection"
+	case num >= 1 && num <= len(t.scheme.Phases):
+		return t.scheme.Phases[num-1].Key
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,164 @@
+package recorder
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// DefaultProfileName is the profile config set creates when none exists.
+const DefaultProfileName = "default"
+
+// LED settings for Profile.LED.
+const (
+	LEDOn  = "on"  // Flash on connect, during inspection, for pacing and on solve (default)
+	LEDOff = "off" // Never touch the backlight
+)
+
+// Profile is a named set of preferences, e.g. one per cube or per solving
+// method, chosen with --profile or the config's profile setting. Empty
+// fields keep the built-in defaults.
+type Profile struct {
+	Device             string `json:"device,omitempty"`               // UUID of the preferred cube, used when it is in range
+	Method             string `json:"method,omitempty"`               // Solving method, recorded as the "method" context of new solves
+	ScanTimeoutSeconds int    `json:"scan_timeout_seconds,omitempty"` // How long to scan for cubes
+	DBPath             string `json:"db_path,omitempty"`              // Database file, unless --db is given
+	ReportDir          string `json:"report_dir,omitempty"`           // Base directory for generated reports
+	LED                string `json:"led,omitempty"`                  // LEDOn or LEDOff
+	Metric             string `json:"metric,omitempty"`               // Turn metric move counts are shown in: htm (default), qtm or stm
+	PhaseScheme        string `json:"phase_scheme,omitempty"`         // Phases solves are split into: lbl (default), roux, zz or one of the config's phase_schemes
+}
+
+// profileKeys maps the keys used by config get/set to profile fields.
+var profileKeys = map[string]struct {
+	get func(p *Profile) string
+	set func(p *Profile, v string) error
+}{
+	"device": {
+		func(p *Profile) string { return p.Device },
+		func(p *Profile, v string) error { p.Device = v; return nil },
+	},
+	"method": {
+		func(p *Profile) string { return p.Method },
+		func(p *Profile, v string) error { p.Method = v; return nil },
+	},
+	"scan_timeout": {
+		func(p *Profile) string {
+			if p.ScanTimeoutSeconds == 0 {
+				return ""
+			}
+			return strconv.Itoa(p.ScanTimeoutSeconds)
+		},
+		func(p *Profile, v string) error {
+			if v == "" {
+				p.ScanTimeoutSeconds = 0
+				return nil
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(v, "s"))
+			if err != nil || n < 0 {
+				return fmt.Errorf("scan_timeout must be a whole number of seconds, got %q", v)
+			}
+			p.ScanTimeoutSeconds = n
+			return nil
+		},
+	},
+	"db": {
+		func(p *Profile) string { return p.DBPath },
+		func(p *Profile, v string) error { p.DBPath = v; return nil },
+	},
+	"report_dir": {
+		func(p *Profile) string { return p.ReportDir },
+		func(p *Profile, v string) error { p.ReportDir = v; return nil },
+	},
+	"led": {
+		func(p *Profile) string { return p.LED },
+		func(p *Profile, v string) error { p.LED = v; return p.Validate() },
+	},
+	"metric": {
+		func(p *Profile) string { return p.Metric },
+		func(p *Profile, v string) error { p.Metric = strings.ToLower(v); return p.Validate() },
+	},
+	"phase_scheme": {
+		func(p *Profile) string { return p.PhaseScheme },
+		func(p *Profile, v string) error { p.PhaseScheme = v; return nil },
+	},
+}
+
+// ProfileKeys returns the keys config get and set accept, sorted.
+func ProfileKeys() []string {
+	keys := make([]string, 0, len(profileKeys))
+	for key := range profileKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of a profile key, "" when unset.
+func (p Profile) Get(key string) (string, error) {
+	k, ok := profileKeys[key]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q (available: %s)", key, strings.Join(ProfileKeys(), ", "))
+	}
+	return k.get(&p), nil
+}
+
+// Set sets a profile key from its string form. An empty value unsets it.
+func (p *Profile) Set(key, value string) error {
+	k, ok := profileKeys[key]
+	if !ok {
+		return fmt.Errorf("unknown key %q (available: %s)", key, strings.Join(ProfileKeys(), ", "))
+	}
+	return k.set(p, value)
+}
+
+// Validate checks the profile for out-of-range values.
+func (p Profile) Validate() error {
+	if p.ScanTimeoutSeconds < 0 {
+		return fmt.Errorf("scan_timeout_seconds must not be negative, got %d", p.ScanTimeoutSeconds)
+	}
+	switch p.LED {
+	case "", LEDOn, LEDOff:
+	default:
+		return fmt.Errorf("led must be %q or %q, got %q", LEDOn, LEDOff, p.LED)
+	}
+	if _, err := gocube.ParseMetric(p.Metric); err != nil {
+		return fmt.Errorf("metric must be htm, qtm or stm, got %q", p.Metric)
+	}
+	return nil
+}
+
+// TurnMetric returns the metric move counts are shown in, MetricHTM unless
+// set.
+func (p Profile) TurnMetric() gocube.Metric {
+	m, err := gocube.ParseMetric(p.Metric)
+	if err != nil {
+		return gocube.MetricHTM
+	}
+	return m
+}
+
+// LEDsOn reports whether the recorder may drive the cube's backlight.
+func (p Profile) LEDsOn() bool {
+	return p.LED != LEDOff
+}
+
+// ActiveProfile returns the profile called name, or the config's default
+// profile when name is empty. With neither set it returns the empty
+// profile, which keeps every default.
+func (c Config) ActiveProfile(name string) (Profile, error) {
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("no profile named %q", name)
+	}
+	return p, nil
+}
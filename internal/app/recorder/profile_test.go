@@ -0,0 +1,90 @@
+package recorder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+func TestProfileSetGet(t *testing.T) {
+	var p Profile
+	tests := []struct {
+		key, value, want string
+	}{
+		{"device", "3F2A-11", "3F2A-11"},
+		{"method", "roux", "roux"},
+		{"scan_timeout", "8s", "8"},
+		{"db", "/tmp/oh.db", "/tmp/oh.db"},
+		{"report_dir", "oh-reports", "oh-reports"},
+		{"led", "off", "off"},
+		{"metric", "STM", "stm"},
+		{"phase_scheme", "roux", "roux"},
+	}
+	for _, tt := range tests {
+		if err := p.Set(tt.key, tt.value); err != nil {
+			t.Fatalf("Set(%s, %q): %v", tt.key, tt.value, err)
+		}
+		if got, err := p.Get(tt.key); err != nil || got != tt.want {
+			t.Errorf("Get(%s) = %q, %v; want %q", tt.key, got, err, tt.want)
+		}
+	}
+	if p.LEDsOn() || p.TurnMetric() != gocube.MetricSTM {
+		t.Errorf("LEDsOn = %v, TurnMetric = %v; want false, STM", p.LEDsOn(), p.TurnMetric())
+	}
+
+	// An empty value unsets a key
+	if err := p.Set("scan_timeout", ""); err != nil || p.ScanTimeoutSeconds != 0 {
+		t.Errorf("unsetting scan_timeout = %d, %v", p.ScanTimeoutSeconds, err)
+	}
+
+	for _, bad := range [][2]string{
+		{"scan_timeout", "soon"},
+		{"scan_timeout", "-3"},
+		{"led", "blink"},
+		{"metric", "etm"},
+		{"colour", "red"},
+	} {
+		if err := p.Set(bad[0], bad[1]); err == nil {
+			t.Errorf("Set(%s, %q) succeeded", bad[0], bad[1])
+		}
+	}
+	if _, err := p.Get("colour"); err == nil {
+		t.Error("Get of an unknown key succeeded")
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	cfg := DefaultConfig()
+	if p, err := cfg.ActiveProfile(""); err != nil || p != (Profile{}) {
+		t.Errorf("ActiveProfile with none set = %+v, %v; want the empty profile", p, err)
+	}
+
+	cfg.Profiles = map[string]Profile{
+		"oh":   {Method: "cfop", LED: LEDOff},
+		"roux": {Method: "roux", PhaseScheme: "roux"},
+	}
+	cfg.Profile = "oh"
+	if p, err := cfg.ActiveProfile(""); err != nil || p.Method != "cfop" {
+		t.Errorf("ActiveProfile default = %+v, %v; want oh", p, err)
+	}
+	if p, err := cfg.ActiveProfile("roux"); err != nil || p.PhaseScheme != "roux" {
+		t.Errorf("ActiveProfile(roux) = %+v, %v", p, err)
+	}
+	if _, err := cfg.ActiveProfile("big"); err == nil {
+		t.Error("ActiveProfile of a missing profile succeeded")
+	}
+
+	// Profiles are saved with the rest of the config
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := SaveConfig(path, cfg); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if loaded.Profile != "oh" || loaded.Profiles["oh"] != cfg.Profiles["oh"] || loaded.Profiles["roux"] != cfg.Profiles["roux"] {
+		t.Errorf("loaded profiles %q %+v, want %q %+v", loaded.Profile, loaded.Profiles, cfg.Profile, cfg.Profiles)
+	}
+}
@@ -0,0 +1,155 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// RebuildStats counts what RebuildDerived regenerated for a solve.
+type RebuildStats struct {
+	Segments    int
+	Checkpoints int
+	CacheRows   int // Cached analyses dropped; they are recomputed on demand
+	PLLCase     string
+}
+
+// RebuildDerived regenerates the derived data of an ended solve from its
+// source records: phase segments from the phase marks and moves (re-deriving
+// marks after a resync, as at solve end), checkpoints and the PLL case by
+// replaying the moves with the resync states applied, and cached analyses by
+// dropping them. It runs in one transaction, so an error or ctx being
+// cancelled leaves the solve's derived data as it was.
+func RebuildDerived(ctx context.Context, db *storage.DB, solveID string) (*RebuildStats, error) {
+	var stats *RebuildStats
+	err := db.InTx(ctx, func(tx *storage.DB) error {
+		var err error
+		stats, err = rebuildDerived(tx, solveID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func rebuildDerived(db *storage.DB, solveID string) (*RebuildStats, error) {
+	s := NewSession(db, nil)
+	s.solveID = solveID
+
+	solve, err := s.solveRepo.Get(solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get solve: %w", err)
+	}
+	if solve == nil {
+		return nil, fmt.Errorf("solve not found: %s", solveID)
+	}
+	if solve.EndedAt == nil {
+		return nil, fmt.Errorf("solve %s has not ended", solveID)
+	}
+
+	stats := &RebuildStats{}
+
+	if err := s.phaseRepo.DeletePhaseSegments(solveID); err != nil {
+		return nil, err
+	}
+	if err := s.computePhaseSegments(); err != nil {
+		return nil, fmt.Errorf("failed to compute phase segments: %w", err)
+	}
+	segments, err := s.phaseRepo.GetPhaseSegments(solveID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Segments = len(segments)
+
+	if stats.Checkpoints, err = s.rebuildCheckpoints(); err != nil {
+		return nil, err
+	}
+	stats.PLLCase = s.pllCase
+
+	if stats.CacheRows, err = storage.NewAnalysisCacheRepository(db).Clear(solveID); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// RebuildPersonalBests replaces the personal best history with the one the
+// stored solves and phase segments give now, dropping bests recorded from
+// segments since rebuilt, and returns its size. Nothing is announced: the
+// history is refilled, not extended.
+func RebuildPersonalBests(db *storage.DB) (int, error) {
+	pbRepo := storage.NewPersonalBestRepository(db)
+	if _, err := pbRepo.Clear(); err != nil {
+		return 0, err
+	}
+	if _, err := analysis.UpdatePersonalBests(storage.NewSolveRepository(db), storage.NewPhaseRepository(db), pbRepo); err != nil {
+		return 0, fmt.Errorf("failed to rebuild personal bests: %w", err)
+	}
+	return pbRepo.Count()
+}
+
+// rebuildCheckpoints replaces the solve's checkpoints with ones replayed from
+// its moves, matching those stored while recording: one every
+// CheckpointInterval moves and one at each resync, whose state replaces the
+// replayed one. The PLL case is recognized again along the way.
+func (s *Session) rebuildCheckpoints() (int, error) {
+	records, err := s.moveRepo.GetBySolve(s.solveID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get moves: %w", err)
+	}
+	events, err := s.eventRepo.GetByType(s.solveID, EventTypeResync)
+	if err != nil {
+		return 0, err
+	}
+
+	// Resync states by the number of moves recorded before them
+	type resync struct {
+		tsUs     int64
+		facelets [6][9]gocube.Color
+	}
+	resyncs := make(map[int][]resync)
+	for _, e := range events {
+		var p resyncPayload
+		if err := json.Unmarshal([]byte(e.PayloadJSON), &p); err != nil {
+			return 0, fmt.Errorf("failed to decode resync: %w", err)
+		}
+		resyncs[p.MoveIndex] = append(resyncs[p.MoveIndex], resync{tsUs: e.TsUs, facelets: p.Facelets})
+	}
+
+	if err := s.checkpointRepo.DeleteBySolve(s.solveID); err != nil {
+		return 0, err
+	}
+	if err := s.pllRepo.DeleteBySolve(s.solveID); err != nil {
+		return 0, err
+	}
+
+	s.cube = gocube.NewCube()
+	for i := 0; i <= len(records); i++ {
+		s.moveIndex = i
+		if i > 0 && i%CheckpointInterval == 0 {
+			if err := s.checkpoint(records[i-1].TsUs); err != nil {
+				return 0, err
+			}
+		}
+		// A resync at the same index replaces the interval checkpoint
+		for _, r := range resyncs[i] {
+			s.cube = &gocube.Cube{Facelets: r.facelets}
+			if err := s.checkpoint(r.tsUs); err != nil {
+				return 0, err
+			}
+		}
+		if i < len(records) {
+			s.cube.Apply(storage.ToMoves(records[i : i+1])...)
+			if err := s.recognizePLL(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return s.checkpointRepo.Count(s.solveID)
+}
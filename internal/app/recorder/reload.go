@@ -0,0 +1,67 @@
+package recorder
+
+import (
+	"os"
+	"time"
+)
+
+// FileWatcher polls a file for changes and hands it to a load function.
+//
+// The load function is responsible for parsing and validating the file and
+// must only apply the new contents if they are fully valid. When it returns
+// an error the previously loaded contents stay in effect, so a half-edited
+// file never replaces a working configuration.
+type FileWatcher struct {
+	path    string
+	load    func(path string) error
+	modTime time.Time
+	size    int64
+	exists  bool
+	lastErr error
+}
+
+// NewFileWatcher creates a watcher for path. The file's current state is
+// taken as the baseline; callers load it themselves before watching.
+func NewFileWatcher(path string, load func(path string) error) *FileWatcher {
+	w := &FileWatcher{path: path, load: load}
+	w.modTime, w.size, w.exists = w.stat()
+	return w
+}
+
+// Path returns the watched file path.
+func (w *FileWatcher) Path() string {
+	return w.path
+}
+
+// LastError returns the error from the most recent failed reload, if any.
+func (w *FileWatcher) LastError() error {
+	return w.lastErr
+}
+
+// stat returns the file's modification time, size, and existence.
+func (w *FileWatcher) stat() (time.Time, int64, bool) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	return info.ModTime(), info.Size(), true
+}
+
+// Poll checks the file once. It returns true if the file changed and was
+// reloaded successfully, or the load error if the change was rejected.
+func (w *FileWatcher) Poll() (bool, error) {
+	modTime, size, exists := w.stat()
+	if exists == w.exists && modTime.Equal(w.modTime) && size == w.size {
+		return false, nil
+	}
+
+	w.modTime, w.size, w.exists = modTime, size, exists
+
+	if err := w.load(w.path); err != nil {
+		w.lastErr = err
+		return false, err
+	}
+
+	w.lastErr = nil
+	return true, nil
+}
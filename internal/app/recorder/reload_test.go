@@ -0,0 +1,97 @@
+package recorder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWatcherKeepsPreviousOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	mtime := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	write := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Each write gets a later time, however coarse the filesystem's clock
+		mtime = mtime.Add(time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Load only applies a config that parses and validates, as the TUI does
+	pacing := NewPacingEngine(DefaultConfig().Pacing)
+	w := NewFileWatcher(path, func(path string) error {
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			return err
+		}
+		pacing.SetConfig(cfg.Pacing)
+		return nil
+	})
+	budget := func() time.Duration {
+		d, _ := pacing.Budget("cross")
+		return d
+	}
+
+	if reloaded, err := w.Poll(); reloaded || err != nil {
+		t.Fatalf("Poll of a missing file = %v, %v; want no change", reloaded, err)
+	}
+
+	write(`{"pacing": {"enabled": true, "budgets_ms": {"cross": 8000}}}`)
+	if reloaded, err := w.Poll(); !reloaded || err != nil {
+		t.Fatalf("Poll after a valid write = %v, %v; want reloaded", reloaded, err)
+	}
+	if budget() != 8*time.Second {
+		t.Fatalf("cross budget = %v, want 8s", budget())
+	}
+
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"half-edited", `{"pacing": {"enabled": true, "budgets_ms": {"cross": 50`},
+		{"invalid", `{"pacing": {"enabled": true, "budgets_ms": {"cross": -1}}}`},
+		{"bad ratio", `{"pacing": {"enabled": true, "warn_ratio": 1.5}}`},
+	}
+	for _, tt := range tests {
+		write(tt.contents)
+		reloaded, err := w.Poll()
+		if reloaded || err == nil {
+			t.Errorf("%s: Poll = %v, %v; want the change rejected", tt.name, reloaded, err)
+		}
+		if w.LastError() == nil {
+			t.Errorf("%s: LastError is nil after a rejected change", tt.name)
+		}
+		if budget() != 8*time.Second || !pacing.Enabled() {
+			t.Errorf("%s: cross budget = %v, enabled %v; want the previous 8s, enabled", tt.name, budget(), pacing.Enabled())
+		}
+	}
+
+	// A rejected file is not retried until it changes again
+	if reloaded, err := w.Poll(); reloaded || err != nil {
+		t.Errorf("Poll of an unchanged rejected file = %v, %v; want no change", reloaded, err)
+	}
+
+	write(`{"pacing": {"enabled": true, "budgets_ms": {"cross": 6000}}}`)
+	if reloaded, err := w.Poll(); !reloaded || err != nil {
+		t.Fatalf("Poll after fixing the file = %v, %v; want reloaded", reloaded, err)
+	}
+	if budget() != 6*time.Second || w.LastError() != nil {
+		t.Errorf("cross budget = %v, LastError = %v; want 6s, nil", budget(), w.LastError())
+	}
+
+	// Deleting the file goes back to the defaults
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := w.Poll(); !reloaded || err != nil {
+		t.Fatalf("Poll after removing the file = %v, %v; want reloaded", reloaded, err)
+	}
+	if _, ok := pacing.Budget("cross"); ok || pacing.Enabled() {
+		t.Errorf("pacing after removing the config kept its budget or stayed enabled")
+	}
+}
@@ -0,0 +1,175 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// EventTypeResync is the event type stored when the tracker is corrected
+// from a device-reported state mid-solve.
+const EventTypeResync = "resync"
+
+// derivedPhaseKeys maps the phases that are auto-marked from cube state to
+// their storage keys. Marks with these keys are re-derived at solve end if
+// the solve was resynced; all other marks (scramble, inspection,
+// white_cross) are timing-based and kept as recorded.
+var derivedPhaseKeys = map[gocube.Phase]string{
+	gocube.PhaseFirstLayer:     "top_corners",
+	gocube.PhaseSecondLayer:    "middle_layer",
+	gocube.PhaseYellowCross:    "bottom_cross",
+	gocube.PhaseYellowCorners:  "position_corners",
+	gocube.PhaseYellowOriented: "orient_corners",
+	gocube.PhaseSolved:         "complete",
+}
+
+// DerivedPhaseKey returns the storage key under which reaching phase p is
+// auto-marked, or false for phases that are not (scrambled, white cross).
+func DerivedPhaseKey(p gocube.Phase) (string, bool) {
+	key, ok := derivedPhaseKeys[p]
+	return key, ok
+}
+
+// resyncPayload is the stored payload of a resync event.
+type resyncPayload struct {
+	MoveIndex int                `json:"move_index"` // moves recorded before the resync
+	Facelets  [6][9]gocube.Color `json:"facelets"`   // corrected cube state
+}
+
+// Resync records that the tracked state was replaced by cube, the state
+// reported by the device, after the moves recorded so far.
+func (s *Session) Resync(cube *gocube.Cube) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateRecording {
+		return fmt.Errorf("no solve in progress")
+	}
+
+	payload, err := json.Marshal(resyncPayload{MoveIndex: s.moveIndex, Facelets: cube.Facelets})
+	if err != nil {
+		return fmt.Errorf("failed to marshal resync: %w", err)
+	}
+
+	tsUs := s.now().Sub(s.startTime).Microseconds()
+	if _, err := s.eventRepo.Create(s.solveID, tsUs, EventTypeResync, string(payload), nil); err != nil {
+		return fmt.Errorf("failed to store resync: %w", err)
+	}
+
+	// The corrected state replaces the tracked one from here on
+	s.cube = cube.Clone()
+	return s.checkpoint(tsUs)
+}
+
+// correctedPhaseMarks replaces state-derived phase marks with marks
+// re-derived from the corrected cube history if the solve was resynced.
+// Without a resync the live marks are returned unchanged.
+func (s *Session) correctedPhaseMarks(marks []storage.PhaseMark) ([]storage.PhaseMark, error) {
+	events, err := s.eventRepo.GetByType(s.solveID, EventTypeResync)
+	if err != nil || len(events) == 0 {
+		return marks, err
+	}
+
+	// The solve starts at white_cross; without it there is nothing to re-derive
+	solveStartMs := int64(-1)
+	for _, m := range marks {
+		if m.PhaseKey == "white_cross" {
+			solveStartMs = m.TsMs
+			break
+		}
+	}
+	if solveStartMs < 0 {
+		return marks, nil
+	}
+
+	records, err := s.moveRepo.GetBySolve(s.solveID)
+	if err != nil {
+		return marks, err
+	}
+	moves := storage.ToMoves(records)
+
+	anchors := make([]resyncPayload, 0, len(events))
+	for _, e := range events {
+		var p resyncPayload
+		if err := json.Unmarshal([]byte(e.PayloadJSON), &p); err != nil {
+			return marks, fmt.Errorf("failed to decode resync: %w", err)
+		}
+		if p.MoveIndex > len(moves) {
+			p.MoveIndex = len(moves)
+		}
+		anchors = append(anchors, p)
+	}
+
+	phases := reconstructPhases(moves, anchors)
+
+	// Keep timing-based marks, then add marks for each new highest phase
+	corrected := make([]storage.PhaseMark, 0, len(marks))
+	for _, m := range marks {
+		if !isDerivedPhaseKey(m.PhaseKey) {
+			corrected = append(corrected, m)
+		}
+	}
+
+	highest := gocube.PhaseScrambled
+	for i, r := range records {
+		if r.TsMs < solveStartMs {
+			highest = phases[i]
+			continue
+		}
+		key, ok := derivedPhaseKeys[phases[i]]
+		if !ok || phases[i] <= highest {
+			continue
+		}
+		// +1ms so the completing move stays in the phase it completes
+		corrected = append(corrected, storage.PhaseMark{
+			SolveID:  s.solveID,
+			TsMs:     r.TsMs + 1,
+			PhaseKey: key,
+			MarkType: "start",
+		})
+		highest = phases[i]
+	}
+
+	sort.SliceStable(corrected, func(i, j int) bool { return corrected[i].TsMs < corrected[j].TsMs })
+	return corrected, nil
+}
+
+// reconstructPhases returns the cube phase after each move, anchored on the
+// resync states. Moves before an anchor are unwound backwards from it; moves
+// after the last anchor are replayed forwards.
+func reconstructPhases(moves []gocube.Move, anchors []resyncPayload) []gocube.Phase {
+	sort.SliceStable(anchors, func(i, j int) bool { return anchors[i].MoveIndex < anchors[j].MoveIndex })
+
+	phases := make([]gocube.Phase, len(moves))
+	prev := 0
+	var cube *gocube.Cube
+	for _, a := range anchors {
+		cube = &gocube.Cube{Facelets: a.Facelets}
+		back := cube.Clone()
+		for i := a.MoveIndex - 1; i >= prev; i-- {
+			phases[i] = back.Phase()
+			back.Apply(moves[i].Inverse())
+		}
+		prev = a.MoveIndex
+	}
+
+	for i := prev; i < len(moves); i++ {
+		cube.Apply(moves[i])
+		phases[i] = cube.Phase()
+	}
+
+	return phases
+}
+
+// isDerivedPhaseKey reports whether key is auto-marked from cube state.
+func isDerivedPhaseKey(key string) bool {
+	for _, k := range derivedPhaseKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
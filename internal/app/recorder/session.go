@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/analysis"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
 )
@@ -40,10 +41,26 @@ type Session struct {
 	stateFile *StateFile
 
 	mu        sync.RWMutex
+	now       func() time.Time // Clock; replaceable for simulation
 	state     SessionState
 	solveID   string
 	startTime time.Time
 	moveIndex int
+	lastMove  time.Time    // Time of the last move (or start); used for idle detection
+	cube      *gocube.Cube // Tracked cube state; snapshotted into checkpoints
+	pllCase   string       // PLL case met in this solve, once recognized
+	cubeType  string       // Cube model reported by the device, once known
+
+	// scrambleCheck checks the moves against the solve's scramble until the
+	// first phase after it is marked; nil with no scramble
+	scrambleCheck *gocube.ScrambleTracker
+
+	// batchSpread spreads the turns of one rotation notification over at
+	// most this long since the previous one; zero gives them all its time
+	batchSpread time.Duration
+
+	// Orientation correction of the recording device, if calibrated
+	correction *protocol.OrientationCorrection
 
 	// Current orientation state (tracked to detect changes)
 	lastUpFace    string
@@ -55,25 +72,59 @@ type Session struct {
 	moveRepo        *storage.MoveRepository
 	phaseRepo       *storage.PhaseRepository
 	orientationRepo *storage.OrientationRepository
+	checkpointRepo  *storage.CheckpointRepository
+	calibrationRepo *storage.CalibrationRepository
+	pllRepo         *storage.PLLCaseRepository
+	anchorRepo      *storage.VideoAnchorRepository
 
 	// Callbacks
 	onMove        func(gocube.Move)
 	onPhase       func(string)
 	onOrientation func(upFace, frontFace string)
+	onAnchor      func(storage.VideoAnchor)
+	onScramble    func(gocube.ScrambleProgress)
+
+	hooks *hookQueue // Solve lifecycle hooks
 }
 
 // NewSession creates a new session manager.
 func NewSession(db *storage.DB, stateFile *StateFile) *Session {
-	return &Session{
+	s := &Session{
 		db:              db,
 		stateFile:       stateFile,
+		now:             time.Now,
 		state:           StateIdle,
 		solveRepo:       storage.NewSolveRepository(db),
 		eventRepo:       storage.NewEventRepository(db),
 		moveRepo:        storage.NewMoveRepository(db),
 		phaseRepo:       storage.NewPhaseRepository(db),
 		orientationRepo: storage.NewOrientationRepository(db),
+		checkpointRepo:  storage.NewCheckpointRepository(db),
+		calibrationRepo: storage.NewCalibrationRepository(db),
+		pllRepo:         storage.NewPLLCaseRepository(db),
+		anchorRepo:      storage.NewVideoAnchorRepository(db),
 	}
+	s.hooks = newHookQueue(s.fillHookEvent)
+	return s
+}
+
+// SetClock replaces the session clock. Simulations use this to record
+// synthetic solves with realistic timestamps without waiting in real time.
+func (s *Session) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetBatchSpread makes the session spread the timestamps of turns reported
+// together in one rotation notification over the time since the previous
+// notification, at most maxSpan (see gocube.SpreadBatchTimes). Zero, the
+// default, stores them all at the notification's time. Turns the cube timed
+// itself are placed by its clock either way (see gocube.TimeBatch).
+func (s *Session) SetBatchSpread(maxSpan time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchSpread = maxSpan
 }
 
 // SetMoveCallback sets the callback for new moves.
@@ -90,6 +141,22 @@ func (s *Session) SetPhaseCallback(cb func(string)) {
 	s.onPhase = cb
 }
 
+// SetAnchorCallback sets the callback for video anchors, emitted with the
+// UTC wall-clock time of the solve's start, each phase mark and its end.
+func (s *Session) SetAnchorCallback(cb func(storage.VideoAnchor)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAnchor = cb
+}
+
+// SetScrambleCallback sets the callback for scramble progress, fired after
+// each move made while the solve's scramble is being checked.
+func (s *Session) SetScrambleCallback(cb func(gocube.ScrambleProgress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onScramble = cb
+}
+
 // SetOrientationCallback sets the callback for orientation changes.
 func (s *Session) SetOrientationCallback(cb func(upFace, frontFace string)) {
 	s.mu.Lock()
@@ -125,14 +192,33 @@ func (s *Session) ElapsedMs() int64 {
 	if s.state != StateRecording {
 		return 0
 	}
-	return time.Since(s.startTime).Milliseconds()
+	return s.now().Sub(s.startTime).Milliseconds()
 }
 
 // CurrentTimestamp returns the current timestamp relative to solve start (thread-safe).
 func (s *Session) CurrentTimestamp() int64 {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return time.Since(s.startTime).Milliseconds()
+	return s.now().Sub(s.startTime).Milliseconds()
+}
+
+// CurrentTimestampUs is CurrentTimestamp in microseconds, the resolution
+// moves and orientations are stored at (thread-safe).
+func (s *Session) CurrentTimestampUs() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.now().Sub(s.startTime).Microseconds()
+}
+
+// ScrambleProgress returns how far the solve's scramble has been applied,
+// and false once it is no longer being checked (or the solve has none).
+func (s *Session) ScrambleProgress() (gocube.ScrambleProgress, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.scrambleCheck == nil {
+		return gocube.ScrambleProgress{}, false
+	}
+	return s.scrambleCheck.Progress(), true
 }
 
 // MoveCount returns the current move count.
@@ -151,17 +237,33 @@ func (s *Session) Start(notes, scramble, deviceName, deviceID, appVersion string
 		return "", fmt.Errorf("solve already in progress")
 	}
 
+	correction, err := s.correctionFor(deviceID)
+	if err != nil {
+		return "", err
+	}
+
 	solveID, err := s.solveRepo.Create(notes, scramble, deviceName, deviceID, appVersion)
 	if err != nil {
 		return "", fmt.Errorf("failed to create solve: %w", err)
 	}
 
+	if s.cubeType != "" {
+		if err := s.solveRepo.SetCubeType(solveID, s.cubeType); err != nil {
+			return "", err
+		}
+	}
+
 	s.solveID = solveID
-	s.startTime = time.Now()
+	s.startTime = s.now()
+	s.lastMove = s.startTime
 	s.moveIndex = 0
+	s.cube = gocube.NewCube()
+	s.pllCase = ""
 	s.lastUpFace = ""
 	s.lastFrontFace = ""
 	s.state = StateRecording
+	s.correction = correction
+	s.scrambleCheck = newScrambleCheck(scramble)
 
 	// Update state file
 	if s.stateFile != nil {
@@ -170,6 +272,8 @@ func (s *Session) Start(notes, scramble, deviceName, deviceID, appVersion string
 		}
 	}
 
+	s.anchor(storage.AnchorSolveStart, "", 0)
+	s.fireHook(HookSolveStart, "")
 	return solveID, nil
 }
 
@@ -182,11 +286,18 @@ func (s *Session) End() error {
 		return fmt.Errorf("no solve in progress")
 	}
 
-	if err := s.solveRepo.End(s.solveID); err != nil {
+	return s.endAt(s.now())
+}
+
+// endAt ends the solve at endedAt. Callers must hold s.mu.
+func (s *Session) endAt(endedAt time.Time) error {
+	if err := s.solveRepo.EndAt(s.solveID, endedAt); err != nil {
 		return fmt.Errorf("failed to end solve: %w", err)
 	}
 
+	s.endScramble()
 	s.state = StateEnded
+	s.anchor(storage.AnchorSolveEnd, "", endedAt.Sub(s.startTime).Milliseconds())
 
 	// Clear state file
 	if s.stateFile != nil {
@@ -200,6 +311,7 @@ func (s *Session) End() error {
 		// Log error but don't fail
 	}
 
+	s.fireHook(HookSolveEnd, "")
 	return nil
 }
 
@@ -212,17 +324,22 @@ func (s *Session) MarkPhase(phaseKey string, notes *string) error {
 		return fmt.Errorf("no solve in progress")
 	}
 
-	tsMs := time.Since(s.startTime).Milliseconds()
+	tsMs := s.now().Sub(s.startTime).Milliseconds()
 
 	_, err := s.phaseRepo.CreatePhaseMark(s.solveID, tsMs, phaseKey, notes)
 	if err != nil {
 		return fmt.Errorf("failed to mark phase: %w", err)
 	}
+	s.anchor(storage.AnchorPhase, phaseKey, tsMs)
+	if phaseKey != "scramble" {
+		s.endScramble()
+	}
 
 	// Notify callback
 	if s.onPhase != nil {
 		go s.onPhase(phaseKey)
 	}
+	s.fireHook(HookPhaseChange, phaseKey)
 
 	return nil
 }
@@ -242,25 +359,105 @@ func (s *Session) MarkPhaseAt(phaseKey string, tsMs int64, notes *string) error
 	if err != nil {
 		return fmt.Errorf("failed to mark phase: %w", err)
 	}
+	s.anchor(storage.AnchorPhase, phaseKey, tsMs)
+	if phaseKey != "scramble" {
+		s.endScramble()
+	}
 
 	// Notify callback
 	if s.onPhase != nil {
 		go s.onPhase(phaseKey)
 	}
+	s.fireHook(HookPhaseChange, phaseKey)
 
 	return nil
 }
 
+// anchor records a video anchor tsMs into the solve, at the session
+// clock's wall time, and notifies the callback. Callers must hold s.mu.
+func (s *Session) anchor(kind, phaseKey string, tsMs int64) {
+	a := storage.VideoAnchor{
+		SolveID:  s.solveID,
+		Kind:     kind,
+		PhaseKey: phaseKey,
+		TsMs:     tsMs,
+		WallUTC:  s.startTime.Add(time.Duration(tsMs) * time.Millisecond).UTC(),
+	}
+	id, err := s.anchorRepo.Create(a)
+	if err != nil {
+		// Log error but don't fail
+		return
+	}
+	a.AnchorID = id
+
+	if s.onAnchor != nil {
+		go s.onAnchor(a)
+	}
+}
+
+// newScrambleCheck returns a tracker for a scramble's notation, or nil if
+// it is empty or not valid notation.
+func newScrambleCheck(scramble string) *gocube.ScrambleTracker {
+	moves, err := gocube.ParseMoves(scramble)
+	if err != nil || len(moves) == 0 {
+		return nil
+	}
+	return gocube.NewScrambleTracker(moves)
+}
+
+// endScramble stops checking the scramble, marking the solve's scramble
+// verified if it was applied exactly. Callers must hold s.mu.
+func (s *Session) endScramble() {
+	if s.scrambleCheck == nil {
+		return
+	}
+	if s.scrambleCheck.Progress().Complete {
+		if err := s.solveRepo.SetScrambleVerified(s.solveID); err != nil {
+			// Log error but don't fail
+		}
+	}
+	s.scrambleCheck = nil
+}
+
+// CubeType returns the cube model reported by the device ("standard" or
+// "edge"), or "" if it has not reported one.
+func (s *Session) CubeType() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cubeType
+}
+
+// setCubeType remembers the cube model and records it on the solve in
+// progress. The caller must hold s.mu.
+func (s *Session) setCubeType(cubeType string) error {
+	s.cubeType = cubeType
+	if s.state != StateRecording {
+		return nil
+	}
+	return s.solveRepo.SetCubeType(s.solveID, cubeType)
+}
+
 // HandleMessage processes an incoming BLE message.
 func (s *Session) HandleMessage(msg *protocol.Message) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// The cube reports its type once after connecting, usually before any
+	// solve starts, so it is remembered for the solves that follow
+	if msg.Type == protocol.MsgTypeCubeType {
+		if event, err := protocol.DecodeCubeType(msg.Payload); err == nil {
+			if err := s.setCubeType(event.TypeName); err != nil {
+				return err
+			}
+		}
+	}
+
 	if s.state != StateRecording {
 		return nil // Not recording, ignore
 	}
 
-	tsMs := time.Since(s.startTime).Milliseconds()
+	now := s.now()
+	tsUs := now.Sub(s.startTime).Microseconds()
 
 	// Decode and store event
 	eventType, payloadJSON, err := decodeMessage(msg)
@@ -269,7 +466,7 @@ func (s *Session) HandleMessage(msg *protocol.Message) error {
 	}
 
 	rawBase64 := msg.RawBase64
-	eventID, err := s.eventRepo.Create(s.solveID, tsMs, eventType, payloadJSON, &rawBase64)
+	eventID, err := s.eventRepo.Create(s.solveID, tsUs, eventType, payloadJSON, &rawBase64)
 	if err != nil {
 		return fmt.Errorf("failed to store event: %w", err)
 	}
@@ -281,15 +478,34 @@ func (s *Session) HandleMessage(msg *protocol.Message) error {
 			return fmt.Errorf("failed to decode rotations: %w", err)
 		}
 
-		moves := rotationsToMoves(rotations, time.Now())
+		moves := rotationsToMoves(rotations, now)
+		gocube.TimeBatch(moves, s.lastMove, s.batchSpread)
+		s.lastMove = now
 
 		for _, move := range moves {
-			_, err := s.moveRepo.Create(s.solveID, s.moveIndex, tsMs, move, &eventID)
+			tsUs := move.Time.Sub(s.startTime).Microseconds()
+			_, err := s.moveRepo.Create(s.solveID, s.moveIndex, tsUs, move, &eventID)
 			if err != nil {
 				return fmt.Errorf("failed to store move: %w", err)
 			}
 			s.moveIndex++
 
+			s.cube.Apply(move)
+			if s.moveIndex%CheckpointInterval == 0 {
+				if err := s.checkpoint(tsUs); err != nil {
+					return err
+				}
+			}
+			if err := s.recognizePLL(); err != nil {
+				return err
+			}
+			if s.scrambleCheck != nil {
+				progress := s.scrambleCheck.Apply(move)
+				if s.onScramble != nil {
+					go s.onScramble(progress)
+				}
+			}
+
 			// Notify callback
 			if s.onMove != nil {
 				go s.onMove(move)
@@ -303,11 +519,14 @@ func (s *Session) HandleMessage(msg *protocol.Message) error {
 		if err != nil {
 			return fmt.Errorf("failed to decode orientation: %w", err)
 		}
+		if s.correction != nil {
+			s.correction.Correct(orient)
+		}
 
 		// Check if orientation has changed
 		if orient.UpFace != s.lastUpFace || orient.FrontFace != s.lastFrontFace {
 			// Record orientation change
-			_, err := s.orientationRepo.Create(s.solveID, tsMs, orient.UpFace, orient.FrontFace, &eventID)
+			_, err := s.orientationRepo.Create(s.solveID, tsUs, orient.UpFace, orient.FrontFace, &eventID)
 			if err != nil {
 				return fmt.Errorf("failed to store orientation: %w", err)
 			}
@@ -402,7 +621,17 @@ func rotationsToMoves(rotations []protocol.RotationEvent, t time.Time) []gocube.
 		} else {
 			turn = gocube.CCW
 		}
-		moves[i] = gocube.Move{Face: face, Turn: turn, Time: t}
+		moves[i] = gocube.Move{
+			Face:       face,
+			Turn:       turn,
+			Time:       t,
+			Center:     protocol.CenterQuarterTurns(rot.CenterOrientation),
+			HasCenter:  rot.CenterOrientation != protocol.CenterUnknown,
+			BatchIndex: i,
+			BatchSize:  len(rotations),
+			Elapsed:    rot.Elapsed,
+			HasElapsed: rot.HasElapsed,
+		}
 	}
 	return moves
 }
@@ -419,6 +648,12 @@ func (s *Session) computePhaseSegments() error {
 		return nil
 	}
 
+	// After a resync the live marks may be wrong; re-derive them from the
+	// corrected state history
+	if corrected, err := s.correctedPhaseMarks(marks); err == nil {
+		marks = corrected
+	}
+
 	// Get solve end time
 	solve, err := s.solveRepo.Get(s.solveID)
 	if err != nil {
@@ -510,10 +745,38 @@ func (s *Session) Resume(solveID string) error {
 		return fmt.Errorf("failed to get next move index: %w", err)
 	}
 
+	// Rebuild the tracked state from the nearest checkpoint
+	cube, err := stateAt(s.checkpointRepo, s.moveRepo, solveID, nextIndex)
+	if err != nil {
+		return fmt.Errorf("failed to restore cube state: %w", err)
+	}
+	pllCase, err := s.pllRepo.Get(solveID)
+	if err != nil {
+		return err
+	}
+
+	_, deviceID, err := s.deviceOf(solveID)
+	if err != nil {
+		return err
+	}
+	correction, err := s.correctionFor(deviceID)
+	if err != nil {
+		return err
+	}
+	scrambleCheck, err := s.resumeScrambleCheck(solve)
+	if err != nil {
+		return err
+	}
+
 	s.solveID = solveID
 	s.startTime = solve.StartedAt
+	s.lastMove = s.now() // Idle time counts from the resume
 	s.moveIndex = nextIndex
+	s.cube = cube
+	s.pllCase = pllCase
 	s.state = StateRecording
+	s.correction = correction
+	s.scrambleCheck = scrambleCheck
 
 	// Restore last orientation state
 	lastOrient, err := s.orientationRepo.GetLast(solveID)
@@ -527,3 +790,65 @@ func (s *Session) Resume(solveID string) error {
 
 	return nil
 }
+
+// resumeScrambleCheck returns the scramble tracker of a solve being resumed
+// while still scrambling, with its moves so far applied, or nil if there is
+// no scramble to check.
+func (s *Session) resumeScrambleCheck(solve *storage.Solve) (*gocube.ScrambleTracker, error) {
+	if solve.ScrambleText == nil || solve.ScrambleVerified {
+		return nil, nil
+	}
+	check := newScrambleCheck(*solve.ScrambleText)
+	if check == nil {
+		return nil, nil
+	}
+
+	marks, err := s.phaseRepo.GetPhaseMarks(solve.SolveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phase marks: %w", err)
+	}
+	for _, mark := range marks {
+		if mark.PhaseKey != "scramble" {
+			return nil, nil // Past the scramble
+		}
+	}
+
+	records, err := s.moveRepo.GetBySolve(solve.SolveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moves: %w", err)
+	}
+	for _, move := range storage.ToMoves(records) {
+		check.Apply(move)
+	}
+	return check, nil
+}
+
+// recognizePLL stores the PLL case of the solve the first time the tracked
+// cube reaches one. Callers must hold s.mu.
+func (s *Session) recognizePLL() error {
+	if s.pllCase != "" {
+		return nil
+	}
+	name, ok := analysis.RecognizePLL(s.cube)
+	if !ok {
+		return nil
+	}
+	if err := s.pllRepo.Set(s.solveID, name); err != nil {
+		return err
+	}
+	s.pllCase = name
+	return nil
+}
+
+// correctionFor returns the orientation correction of a device, or nil if
+// it was never calibrated.
+func (s *Session) correctionFor(deviceID string) (*protocol.OrientationCorrection, error) {
+	if deviceID == "" {
+		return nil, nil
+	}
+	cal, err := s.calibrationRepo.Get(deviceID)
+	if err != nil || cal == nil {
+		return nil, err
+	}
+	return &cal.Correction, nil
+}
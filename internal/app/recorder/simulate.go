@@ -0,0 +1,115 @@
+package recorder
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// faceToColorIndex maps cube faces to protocol color indices
+// (0 blue, 1 green, 2 white, 3 yellow, 4 red, 5 orange).
+var faceToColorIndex = map[gocube.Face]byte{
+	gocube.FaceB: 0,
+	gocube.FaceF: 1,
+	gocube.FaceU: 2,
+	gocube.FaceD: 3,
+	gocube.FaceR: 4,
+	gocube.FaceL: 5,
+}
+
+// RotationMessage builds the rotation message the cube would send for a
+// move. Half turns are sent as two quarter turns, as the cube does.
+func RotationMessage(m gocube.Move) (*protocol.Message, error) {
+	colorIdx, ok := faceToColorIndex[m.Face]
+	if !ok {
+		return nil, fmt.Errorf("unsupported face %q", m.Face)
+	}
+
+	code := colorIdx * 2 // clockwise
+	if m.Turn == gocube.CCW {
+		code++
+	}
+
+	payload := []byte{code, 0}
+	if m.Turn == gocube.Double {
+		payload = append(payload, code, 0)
+	}
+
+	return protocol.Parse(protocol.BuildMessage(protocol.MsgTypeRotation, payload))
+}
+
+// SimClock is a manually advanced clock for simulated sessions.
+type SimClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewSimClock creates a clock starting at start.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now returns the current simulated time.
+func (c *SimClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *SimClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SimTiming generates human-like gaps between moves: a base rate with
+// log-normal jitter, occasional hesitations, and longer recognition pauses
+// when a new phase starts.
+type SimTiming struct {
+	TPS            float64 // Average turns per second while executing
+	Jitter         float64 // Log-normal sigma applied to each gap
+	HesitationRate float64 // Probability of a mid-phase hesitation
+	HesitationMs   int64   // Mean hesitation length
+	RecognitionMs  int64   // Mean pause before a new phase
+
+	rng *rand.Rand
+}
+
+// NewSimTiming creates a timing model with typical values for tps.
+func NewSimTiming(tps float64, seed int64) *SimTiming {
+	if tps <= 0 {
+		tps = 2.5
+	}
+	return &SimTiming{
+		TPS:            tps,
+		Jitter:         0.35,
+		HesitationRate: 0.05,
+		HesitationMs:   900,
+		RecognitionMs:  1200,
+		rng:            rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Gap returns the delay before the next move. newPhase adds a recognition
+// pause, as solvers stop to look before starting the next step.
+func (t *SimTiming) Gap(newPhase bool) time.Duration {
+	base := 1000.0 / t.TPS
+	ms := base * math.Exp(t.rng.NormFloat64()*t.Jitter)
+
+	if newPhase {
+		ms += float64(t.RecognitionMs) * (0.5 + t.rng.Float64())
+	} else if t.rng.Float64() < t.HesitationRate {
+		ms += float64(t.HesitationMs) * (0.5 + t.rng.Float64())
+	}
+
+	if ms < 40 {
+		ms = 40 // Fastest physically plausible turn
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
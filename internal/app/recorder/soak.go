@@ -0,0 +1,264 @@
+package recorder
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// SoakConfig configures a soak run.
+type SoakConfig struct {
+	Events        int            // Total rotation messages to feed
+	MovesPerSolve int            // Messages per synthetic solve before it is ended
+	Seed          int64          // Random seed for the move stream
+	Progress      func(done int) // Optional; called every ProgressEvery events
+	ProgressEvery int
+	Chaos         ble.ChaosConfig // Optional BLE failure injection
+}
+
+// SoakResult holds the measurements from a soak run.
+type SoakResult struct {
+	Events   int
+	Solves   int
+	Elapsed  time.Duration
+	HeapPre  uint64 // Live heap after GC before the run
+	HeapPost uint64 // Live heap after GC after the run
+	DBBytes  int64  // Database file size including WAL
+
+	LatencyP50 time.Duration // HandleMessage latency
+	LatencyP99 time.Duration
+	LatencyMax time.Duration
+
+	MaxBacklog int64 // Peak move callbacks dispatched but not yet run
+
+	Chaos        ble.ChaosStats // What the chaos transport did, if enabled
+	Inconsistent int            // Solves where recorder and tracker state disagreed
+}
+
+// HeapGrowth returns the live heap growth over the run in bytes.
+func (r SoakResult) HeapGrowth() int64 {
+	return int64(r.HeapPost) - int64(r.HeapPre)
+}
+
+// EventsPerSecond returns the sustained write throughput.
+func (r SoakResult) EventsPerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Events) / r.Elapsed.Seconds()
+}
+
+// soakReservoirSize bounds latency samples so the harness itself does not
+// show up as heap growth.
+const soakReservoirSize = 10000
+
+// RunSoak feeds synthetic rotation messages through a Session into db,
+// exercising the same decode, event and move storage path as a live cube.
+func RunSoak(db *storage.DB, cfg SoakConfig) (SoakResult, error) {
+	if cfg.MovesPerSolve <= 0 {
+		cfg.MovesPerSolve = 100
+	}
+
+	faces := []gocube.Face{gocube.FaceR, gocube.FaceL, gocube.FaceU, gocube.FaceD, gocube.FaceF, gocube.FaceB}
+	turns := []gocube.Turn{gocube.CW, gocube.CCW}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	session := NewSession(db, nil)
+	clock := NewSimClock(time.Now())
+	session.SetClock(clock.Now)
+
+	// Callbacks run on their own goroutines; the backlog is the number
+	// dispatched but not yet finished.
+	var dispatched, handled, maxBacklog int64
+	session.SetMoveCallback(func(gocube.Move) {
+		atomic.AddInt64(&handled, 1)
+	})
+
+	// With chaos enabled, messages reach the session as notifications of a
+	// mock cube over a faulty link and a BLE client, and a tracker follows
+	// what was actually delivered, as the record TUI does. Whatever arrives,
+	// the recorded moves and checkpoints must reproduce the tracker's state.
+	handle := func(msg *protocol.Message) error {
+		if err := session.HandleMessage(msg); err != nil {
+			return err
+		}
+		dispatched++
+		return nil
+	}
+	send := handle
+	var transport *ble.MockTransport
+	var deliverErr error
+	tracker := gocube.NewCube()
+	if cfg.Chaos.Enabled() {
+		cube := ble.Advertisement{Name: "GoCube_Soak", Address: "00:00:00:00:50:4b"}
+		transport = ble.NewMockTransport(cube)
+		transport.SetChaos(cfg.Chaos, clock.Now)
+		client, err := ble.NewClient(ble.WithTransport(transport))
+		if err != nil {
+			return SoakResult{}, err
+		}
+		if err := client.Connect(context.Background(), cube.Address); err != nil {
+			return SoakResult{}, err
+		}
+		defer client.Disconnect()
+
+		client.SetMessageCallback(func(msg *protocol.Message) {
+			if deliverErr != nil {
+				return
+			}
+			if deliverErr = handle(msg); deliverErr != nil {
+				return
+			}
+			rotations, err := protocol.DecodeRotation(msg.Payload)
+			if err != nil {
+				deliverErr = err
+				return
+			}
+			tracker.Apply(rotationsToMoves(rotations, clock.Now())...)
+		})
+		send = func(msg *protocol.Message) error {
+			transport.Notify(protocol.TxCharUUID, protocol.BuildMessage(msg.Type, msg.Payload))
+			return deliverErr
+		}
+	}
+
+	var result SoakResult
+	result.HeapPre = liveHeap()
+
+	samples := make([]time.Duration, 0, soakReservoirSize)
+	start := time.Now()
+
+	for result.Events < cfg.Events {
+		if _, err := session.Start("soak", "", "Soak", "soak", ""); err != nil {
+			return result, err
+		}
+		result.Solves++
+		tracker.Reset()
+
+		for i := 0; i < cfg.MovesPerSolve && result.Events < cfg.Events; i++ {
+			m := gocube.Move{Face: faces[rng.Intn(len(faces))], Turn: turns[rng.Intn(len(turns))]}
+			msg, err := RotationMessage(m)
+			if err != nil {
+				return result, err
+			}
+			clock.Advance(time.Duration(100+rng.Intn(400)) * time.Millisecond)
+
+			t0 := time.Now()
+			if err := send(msg); err != nil {
+				return result, err
+			}
+			lat := time.Since(t0)
+
+			// Reservoir sample latencies
+			if len(samples) < soakReservoirSize {
+				samples = append(samples, lat)
+			} else if j := rng.Intn(result.Events + 1); j < soakReservoirSize {
+				samples[j] = lat
+			}
+			if lat > result.LatencyMax {
+				result.LatencyMax = lat
+			}
+
+			if backlog := dispatched - atomic.LoadInt64(&handled); backlog > maxBacklog {
+				maxBacklog = backlog
+			}
+
+			result.Events++
+			if cfg.Progress != nil && cfg.ProgressEvery > 0 && result.Events%cfg.ProgressEvery == 0 {
+				cfg.Progress(result.Events)
+			}
+		}
+
+		if transport != nil {
+			transport.FlushChaos()
+			if deliverErr != nil {
+				return result, deliverErr
+			}
+			consistent, err := checkConsistency(db, session, tracker)
+			if err != nil {
+				return result, err
+			}
+			if !consistent {
+				result.Inconsistent++
+			}
+		}
+
+		if err := session.End(); err != nil {
+			return result, err
+		}
+	}
+	if transport != nil {
+		result.Chaos = transport.ChaosStats()
+	}
+
+	result.Elapsed = time.Since(start)
+	result.MaxBacklog = maxBacklog
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	result.LatencyP50 = percentileDuration(samples, 0.50)
+	result.LatencyP99 = percentileDuration(samples, 0.99)
+
+	// Let in-flight callbacks drain before measuring the heap
+	for atomic.LoadInt64(&handled) < dispatched {
+		time.Sleep(time.Millisecond)
+	}
+	result.HeapPost = liveHeap()
+	result.DBBytes = fileSize(db.Path()) + fileSize(db.Path()+"-wal")
+
+	return result, nil
+}
+
+// checkConsistency compares the tracker with the state rebuilt from the
+// stored moves, both by full replay and from the latest checkpoint.
+func checkConsistency(db *storage.DB, session *Session, tracker *gocube.Cube) (bool, error) {
+	solveID := session.SolveID()
+
+	records, err := storage.NewMoveRepository(db).GetBySolve(solveID)
+	if err != nil {
+		return false, err
+	}
+	replayed := gocube.NewCube()
+	replayed.Apply(storage.ToMoves(records)...)
+
+	fromCheckpoint, err := StateAt(db, solveID, session.MoveCount())
+	if err != nil {
+		return false, err
+	}
+
+	return replayed.Facelets == tracker.Facelets && fromCheckpoint.Facelets == tracker.Facelets, nil
+}
+
+// liveHeap returns the heap in use after a full collection.
+func liveHeap() uint64 {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.HeapAlloc
+}
+
+// percentileDuration returns the p-th percentile of sorted durations.
+func percentileDuration(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// fileSize returns the size of path, or 0 if it does not exist.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// EarnedAchievement is an achievement and when it was earned.
+type EarnedAchievement struct {
+	Key      string
+	EarnedAt time.Time
+	SolveID  *string // nil if the solve was deleted
+}
+
+// AchievementRepository provides operations for earned achievements.
+type AchievementRepository struct {
+	db *DB
+}
+
+// NewAchievementRepository creates a new achievement repository.
+func NewAchievementRepository(db *DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// Earn records an achievement as earned by a solve. It returns false if the
+// achievement was already earned, which keeps the first time it was.
+func (r *AchievementRepository) Earn(key, solveID string, earnedAt time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		INSERT OR IGNORE INTO achievements (achievement_key, earned_at, solve_id)
+		VALUES (?, ?, ?)
+	`, key, earnedAt.UTC().Format(time.RFC3339), solveID)
+	if err != nil {
+		return false, fmt.Errorf("failed to store achievement: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to store achievement: %w", err)
+	}
+	return n > 0, nil
+}
+
+// List returns the earned achievements keyed by achievement key.
+func (r *AchievementRepository) List() (map[string]EarnedAchievement, error) {
+	rows, err := r.db.Query("SELECT achievement_key, earned_at, solve_id FROM achievements")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list achievements: %w", err)
+	}
+	defer rows.Close()
+
+	earned := make(map[string]EarnedAchievement)
+	for rows.Next() {
+		var a EarnedAchievement
+		var earnedAt string
+		var solveID sql.NullString
+		if err := rows.Scan(&a.Key, &earnedAt, &solveID); err != nil {
+			return nil, fmt.Errorf("failed to scan achievement: %w", err)
+		}
+		a.EarnedAt, _ = time.Parse(time.RFC3339, earnedAt)
+		if solveID.Valid {
+			a.SolveID = &solveID.String
+		}
+		earned[a.Key] = a
+	}
+	return earned, rows.Err()
+}
@@ -0,0 +1,24 @@
+package storage
+
+import "fmt"
+
+// AnalysisCacheRepository provides operations for cached analysis results.
+type AnalysisCacheRepository struct {
+	db *DB
+}
+
+// NewAnalysisCacheRepository creates a new analysis cache repository.
+func NewAnalysisCacheRepository(db *DB) *AnalysisCacheRepository {
+	return &AnalysisCacheRepository{db: db}
+}
+
+// Clear deletes all cached analyses for a solve, returning how many were
+// removed.
+func (r *AnalysisCacheRepository) Clear(solveID string) (int, error) {
+	res, err := r.db.Exec("DELETE FROM analysis_cache WHERE solve_id = ?", solveID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear analysis cache: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Video anchor kinds.
+const (
+	AnchorSolveStart = "solve_start"
+	AnchorPhase      = "phase"
+	AnchorSolveEnd   = "solve_end"
+)
+
+// VideoAnchor ties a moment of a solve to the UTC wall clock, so the solve
+// can be lined up with a camera recording of it.
+type VideoAnchor struct {
+	AnchorID int64
+	SolveID  string
+	Kind     string // AnchorSolveStart, AnchorPhase or AnchorSolveEnd
+	PhaseKey string // For AnchorPhase
+	TsMs     int64  // Time within the solve
+	WallUTC  time.Time
+}
+
+// VideoAnchorRepository stores video anchors.
+type VideoAnchorRepository struct {
+	db *DB
+}
+
+// NewVideoAnchorRepository creates a new video anchor repository.
+func NewVideoAnchorRepository(db *DB) *VideoAnchorRepository {
+	return &VideoAnchorRepository{db: db}
+}
+
+// Create stores an anchor and returns its ID.
+func (r *VideoAnchorRepository) Create(a VideoAnchor) (int64, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO video_anchors (solve_id, kind, phase_key, ts_ms, wall_utc)
+		VALUES (?, ?, ?, ?, ?)
+	`, a.SolveID, a.Kind, a.PhaseKey, a.TsMs, a.WallUTC.UTC().Format(time.RFC3339Nano))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create video anchor: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// GetBySolve returns a solve's anchors in order.
+func (r *VideoAnchorRepository) GetBySolve(solveID string) ([]VideoAnchor, error) {
+	rows, err := r.db.Query(`
+		SELECT anchor_id, solve_id, kind, phase_key, ts_ms, wall_utc
+		FROM video_anchors
+		WHERE solve_id = ?
+		ORDER BY ts_ms, anchor_id
+	`, solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query video anchors: %w", err)
+	}
+	defer rows.Close()
+
+	var anchors []VideoAnchor
+	for rows.Next() {
+		var a VideoAnchor
+		var wall string
+		if err := rows.Scan(&a.AnchorID, &a.SolveID, &a.Kind, &a.PhaseKey, &a.TsMs, &wall); err != nil {
+			return nil, fmt.Errorf("failed to scan video anchor: %w", err)
+		}
+		a.WallUTC, _ = time.Parse(time.RFC3339Nano, wall)
+		anchors = append(anchors, a)
+	}
+	return anchors, rows.Err()
+}
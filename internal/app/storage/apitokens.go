@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// apiTokenPrefix starts every API token, so a leaked one is recognizable.
+const apiTokenPrefix = "gct_"
+
+// APIToken is a named bearer token for the serve API. The token itself is
+// only shown when it is created.
+type APIToken struct {
+	Name      string
+	Scopes    []string
+	CreatedAt time.Time
+}
+
+// APITokenRepository stores API tokens by the hash of their secret.
+type APITokenRepository struct {
+	db *DB
+}
+
+// NewAPITokenRepository creates a new API token repository.
+func NewAPITokenRepository(db *DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create stores a new token with the given name and scopes and returns its
+// secret, which cannot be read back later.
+func (r *APITokenRepository) Create(name string, scopes []string) (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	secret := apiTokenPrefix + hex.EncodeToString(b[:])
+
+	_, err := r.db.Exec(`
+		INSERT INTO api_tokens (name, token_hash, scopes, created_at)
+		VALUES (?, ?, ?, ?)
+	`, name, hashAPIToken(secret), strings.Join(scopes, " "), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token %q: %w", name, err)
+	}
+	return secret, nil
+}
+
+// Lookup returns the token whose secret is given, or nil if there is none.
+func (r *APITokenRepository) Lookup(secret string) (*APIToken, error) {
+	rows, err := r.query("WHERE token_hash = ?", hashAPIToken(secret))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0], nil
+}
+
+// List returns every token by name.
+func (r *APITokenRepository) List() ([]APIToken, error) {
+	return r.query("ORDER BY name")
+}
+
+// Count returns the number of tokens.
+func (r *APITokenRepository) Count() (int, error) {
+	var n int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM api_tokens").Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return n, nil
+}
+
+// Delete revokes the token with the given name and reports whether there
+// was one.
+func (r *APITokenRepository) Delete(name string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM api_tokens WHERE name = ?", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete token %q: %w", name, err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *APITokenRepository) query(where string, args ...interface{}) ([]APIToken, error) {
+	rows, err := r.db.Query("SELECT name, scopes, created_at FROM api_tokens "+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		var scopes, created string
+		if err := rows.Scan(&t.Name, &scopes, &created); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+		t.Scopes = strings.Fields(scopes)
+		t.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+// hashAPIToken returns the stored form of a token secret.
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+//go:build !js
+
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.MigrateUp(); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	return db
+}
+
+func TestAPITokens(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAPITokenRepository(db)
+
+	secret, err := repo.Create("overlay", []string{"stats:read"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(secret, apiTokenPrefix) {
+		t.Errorf("secret %q lacks the %s prefix", secret, apiTokenPrefix)
+	}
+	if _, err := repo.Create("overlay", []string{"admin"}); err == nil {
+		t.Error("Create with a taken name succeeded")
+	}
+
+	// Only the hash is stored
+	var stored string
+	if err := db.QueryRow("SELECT token_hash FROM api_tokens WHERE name = 'overlay'").Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored == secret || strings.Contains(stored, secret[len(apiTokenPrefix):]) {
+		t.Errorf("stored %q, the secret itself", stored)
+	}
+
+	tok, err := repo.Lookup(secret)
+	if err != nil || tok == nil || tok.Name != "overlay" || !reflect.DeepEqual(tok.Scopes, []string{"stats:read"}) {
+		t.Fatalf("Lookup = %+v, %v; want overlay with stats:read", tok, err)
+	}
+	if tok, err := repo.Lookup(secret + "x"); err != nil || tok != nil {
+		t.Errorf("Lookup(wrong) = %+v, %v; want nil", tok, err)
+	}
+
+	if _, err := repo.Create("dashboard", []string{"stats:read", "recording:control"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if n, err := repo.Count(); err != nil || n != 2 {
+		t.Errorf("Count = %d, %v; want 2", n, err)
+	}
+	list, err := repo.List()
+	if err != nil || len(list) != 2 || list[0].Name != "dashboard" || len(list[0].Scopes) != 2 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+
+	if found, err := repo.Delete("overlay"); err != nil || !found {
+		t.Fatalf("Delete = %v, %v", found, err)
+	}
+	if tok, err := repo.Lookup(secret); err != nil || tok != nil {
+		t.Errorf("Lookup after revoking = %+v, %v; want nil", tok, err)
+	}
+	if found, _ := repo.Delete("overlay"); found {
+		t.Error("Delete of a revoked token found it")
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// DeviceCalibration is the stored orientation correction for a cube.
+type DeviceCalibration struct {
+	DeviceID     string
+	Correction   protocol.OrientationCorrection
+	CalibratedAt time.Time
+}
+
+// CalibrationRepository provides operations for device calibrations.
+type CalibrationRepository struct {
+	db *DB
+}
+
+// NewCalibrationRepository creates a new calibration repository.
+func NewCalibrationRepository(db *DB) *CalibrationRepository {
+	return &CalibrationRepository{db: db}
+}
+
+// Set stores the correction for a device, replacing any earlier one.
+func (r *CalibrationRepository) Set(deviceID string, c protocol.OrientationCorrection) error {
+	_, err := r.db.Exec(`
+		INSERT OR REPLACE INTO device_calibrations (device_id, ref_x, ref_y, ref_z, ref_w, calibrated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, deviceID, c.X, c.Y, c.Z, c.W, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to store calibration: %w", err)
+	}
+	return nil
+}
+
+// Get returns the calibration of a device, or nil if it was never calibrated.
+func (r *CalibrationRepository) Get(deviceID string) (*DeviceCalibration, error) {
+	var cal DeviceCalibration
+	var calibratedAt string
+	err := r.db.QueryRow(`
+		SELECT device_id, ref_x, ref_y, ref_z, ref_w, calibrated_at
+		FROM device_calibrations
+		WHERE device_id = ?
+	`, deviceID).Scan(&cal.DeviceID, &cal.Correction.X, &cal.Correction.Y, &cal.Correction.Z, &cal.Correction.W, &calibratedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calibration: %w", err)
+	}
+
+	cal.CalibratedAt, _ = time.Parse(time.RFC3339, calibratedAt)
+	return &cal, nil
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+// Checkpoint is a snapshot of the cube state after MoveIndex moves of a solve.
+type Checkpoint struct {
+	SolveID   string
+	MoveIndex int
+	TsUs      int64
+	Facelets  [6][9]gocube.Color
+}
+
+// CheckpointRepository provides operations for cube state checkpoints.
+type CheckpointRepository struct {
+	db *DB
+}
+
+// NewCheckpointRepository creates a new checkpoint repository.
+func NewCheckpointRepository(db *DB) *CheckpointRepository {
+	return &CheckpointRepository{db: db}
+}
+
+// Create stores a checkpoint, replacing any existing one at the same move index.
+func (r *CheckpointRepository) Create(cp Checkpoint) error {
+	facelets, err := json.Marshal(cp.Facelets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	_, err = r.db.Exec(`
+		INSERT OR REPLACE INTO checkpoints (solve_id, move_index, ts_us, facelets_json)
+		VALUES (?, ?, ?, ?)
+	`, cp.SolveID, cp.MoveIndex, cp.TsUs, string(facelets))
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GetAtOrBefore returns the latest checkpoint taken at or before moveIndex,
+// or nil if there is none.
+func (r *CheckpointRepository) GetAtOrBefore(solveID string, moveIndex int) (*Checkpoint, error) {
+	var cp Checkpoint
+	var facelets string
+	err := r.db.QueryRow(`
+		SELECT solve_id, move_index, ts_us, facelets_json
+		FROM checkpoints
+		WHERE solve_id = ? AND move_index <= ?
+		ORDER BY move_index DESC
+		LIMIT 1
+	`, solveID, moveIndex).Scan(&cp.SolveID, &cp.MoveIndex, &cp.TsUs, &facelets)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+	if err := json.Unmarshal([]byte(facelets), &cp.Facelets); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// Count returns the number of checkpoints for a solve.
+func (r *CheckpointRepository) Count(solveID string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM checkpoints WHERE solve_id = ?", solveID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count checkpoints: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteBySolve deletes all checkpoints for a solve.
+func (r *CheckpointRepository) DeleteBySolve(solveID string) error {
+	_, err := r.db.Exec("DELETE FROM checkpoints WHERE solve_id = ?", solveID)
+	if err != nil {
+		return fmt.Errorf("failed to delete checkpoints: %w", err)
+	}
+	return nil
+}
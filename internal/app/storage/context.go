@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ContextRepository provides CRUD operations for solve context metadata.
+type ContextRepository struct {
+	db *DB
+}
+
+// NewContextRepository creates a new context repository.
+func NewContextRepository(db *DB) *ContextRepository {
+	return &ContextRepository{db: db}
+}
+
+// Set sets a single context value for a solve, replacing any existing value.
+func (r *ContextRepository) Set(solveID, key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT OR REPLACE INTO solve_context (solve_id, key, value)
+		VALUES (?, ?, ?)
+	`, solveID, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set context: %w", err)
+	}
+	return nil
+}
+
+// SetAll sets several context values for a solve in one transaction.
+func (r *ContextRepository) SetAll(solveID string, context map[string]string) error {
+	if len(context) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *sql.Tx) error {
+		for key, value := range context {
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO solve_context (solve_id, key, value)
+				VALUES (?, ?, ?)
+			`, solveID, key, value)
+			if err != nil {
+				return fmt.Errorf("failed to set context: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns all context values for a solve.
+func (r *ContextRepository) Get(solveID string) (map[string]string, error) {
+	rows, err := r.db.Query(`
+		SELECT key, value
+		FROM solve_context
+		WHERE solve_id = ?
+	`, solveID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context: %w", err)
+	}
+	defer rows.Close()
+
+	context := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan context: %w", err)
+		}
+		context[key] = value
+	}
+
+	return context, rows.Err()
+}
+
+// Delete removes a context value from a solve.
+func (r *ContextRepository) Delete(solveID, key string) error {
+	_, err := r.db.Exec("DELETE FROM solve_context WHERE solve_id = ? AND key = ?", solveID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete context: %w", err)
+	}
+	return nil
+}
+
+// Matches returns true if the solve has every key/value pair in filter.
+func (r *ContextRepository) Matches(solveID string, filter map[string]string) (bool, error) {
+	if len(filter) == 0 {
+		return true, nil
+	}
+	context, err := r.Get(solveID)
+	if err != nil {
+		return false, err
+	}
+	for key, value := range filter {
+		if context[key] != value {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// CounterReading is a reading of the cube's cumulative offline counters.
+type CounterReading struct {
+	ReadingID int64
+	DeviceID  string
+	SolveID   *string
+	ReadAt    time.Time
+	Moves     int
+	TimeS     int
+	Solves    int
+}
+
+// CounterRepository provides CRUD operations for device counter readings.
+type CounterRepository struct {
+	db *DB
+}
+
+// NewCounterRepository creates a new counter repository.
+func NewCounterRepository(db *DB) *CounterRepository {
+	return &CounterRepository{db: db}
+}
+
+// Create stores a counter reading. solveID may be empty for readings taken
+// outside a solve.
+func (r *CounterRepository) Create(deviceID, solveID string, moves, timeS, solves int) (int64, error) {
+	var solveIDPtr *string
+	if solveID != "" {
+		solveIDPtr = &solveID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO device_counters (device_id, solve_id, read_at, counter_moves, counter_time_s, counter_solves)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, deviceID, solveIDPtr, time.Now().UTC().Format(time.RFC3339Nano), moves, timeS, solves)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create counter reading: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get counter reading ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetBySolve retrieves the counter readings taken for a solve, oldest first.
+func (r *CounterRepository) GetBySolve(solveID string) ([]CounterReading, error) {
+	return r.query(`
+		SELECT reading_id, device_id, solve_id, read_at, counter_moves, counter_time_s, counter_solves
+		FROM device_counters
+		WHERE solve_id = ?
+		ORDER BY reading_id
+	`, solveID)
+}
+
+// GetByDevice retrieves all counter readings for a device, oldest first.
+func (r *CounterRepository) GetByDevice(deviceID string) ([]CounterReading, error) {
+	return r.query(`
+		SELECT reading_id, device_id, solve_id, read_at, counter_moves, counter_time_s, counter_solves
+		FROM device_counters
+		WHERE device_id = ?
+		ORDER BY reading_id
+	`, deviceID)
+}
+
+func (r *CounterRepository) query(query string, args ...interface{}) ([]CounterReading, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get counter readings: %w", err)
+	}
+	defer rows.Close()
+
+	var readings []CounterReading
+	for rows.Next() {
+		var c CounterReading
+		var readAt string
+		if err := rows.Scan(&c.ReadingID, &c.DeviceID, &c.SolveID, &readAt, &c.Moves, &c.TimeS, &c.Solves); err != nil {
+			return nil, fmt.Errorf("failed to scan counter reading: %w", err)
+		}
+		c.ReadAt, _ = time.Parse(time.RFC3339Nano, readAt)
+		readings = append(readings, c)
+	}
+
+	return readings, rows.Err()
+}
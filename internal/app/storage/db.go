@@ -2,18 +2,21 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
-
-	_ "modernc.org/sqlite"
+	"strings"
 )
 
-// DB wraps the SQLite database connection.
+// DB wraps the SQLite database connection. The DB InTx passes to its
+// function runs every statement in one transaction instead, so
+// repositories created on it write all or nothing.
 type DB struct {
 	*sql.DB
 	path string
+	tx   *sql.Tx // Set within InTx
 }
 
 // DefaultDBPath returns the default database path in the user's home directory.
@@ -103,8 +106,61 @@ func (db *DB) CurrentVersion() (int, error) {
 	return version, nil
 }
 
-// Transaction executes a function within a database transaction.
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// reports, or nil if the database is intact.
+func (db *DB) IntegrityCheck() ([]string, error) {
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity result: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	return problems, rows.Err()
+}
+
+// WriteLocked reports whether another connection holds the write lock,
+// e.g. a recording still running in another terminal. It does not wait
+// for the lock.
+func (db *DB) WriteLocked() (bool, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA busy_timeout = 0"); err != nil {
+		return false, fmt.Errorf("failed to set busy timeout: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		if strings.Contains(err.Error(), "SQLITE_BUSY") || strings.Contains(err.Error(), "database is locked") {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ROLLBACK"); err != nil {
+		return false, fmt.Errorf("failed to roll back: %w", err)
+	}
+	return false, nil
+}
+
+// Transaction executes a function within a database transaction. Within
+// InTx it joins the transaction already open.
 func (db *DB) Transaction(fn func(*sql.Tx) error) error {
+	if db.tx != nil {
+		return fn(db.tx)
+	}
+
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -123,3 +179,61 @@ func (db *DB) Transaction(fn func(*sql.Tx) error) error {
 
 	return nil
 }
+
+// InTx runs fn with a DB whose statements all run in one transaction,
+// committed if fn returns nil. It is rolled back if fn fails or ctx is
+// cancelled first, in which case InTx returns ctx.Err(). Within InTx, fn
+// runs in the transaction already open.
+func (db *DB) InTx(ctx context.Context, fn func(tx *DB) error) error {
+	if db.tx != nil {
+		return fn(db)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op once committed
+
+	if err := fn(&DB{DB: db.DB, path: db.path, tx: tx}); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Exec executes a statement, in the transaction within InTx.
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.tx != nil {
+		return db.tx.Exec(query, args...)
+	}
+	return db.DB.Exec(query, args...)
+}
+
+// Query runs a query, in the transaction within InTx.
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if db.tx != nil {
+		return db.tx.Query(query, args...)
+	}
+	return db.DB.Query(query, args...)
+}
+
+// QueryRow runs a query returning at most one row, in the transaction
+// within InTx.
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	if db.tx != nil {
+		return db.tx.QueryRow(query, args...)
+	}
+	return db.DB.QueryRow(query, args...)
+}
@@ -0,0 +1,68 @@
+//go:build !js
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInTx(t *testing.T) {
+	db := openTestDB(t)
+	mustExec(t, db, `INSERT INTO solves (solve_id, started_at) VALUES ('s1', '2026-01-02T10:00:00Z')`)
+	detections := NewToolDetectionRepository(db)
+	if err := detections.Replace("s1", map[string]int{"T-perm": 1}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"T-perm": 1}
+	check := func(name string) {
+		t.Helper()
+		got, err := detections.SolvesByTool()
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: detections = %v, %v; want %v", name, got, err, want)
+		}
+	}
+
+	// Repositories on the transaction, and their own transactions, join it
+	failed := errors.New("failed")
+	err := db.InTx(context.Background(), func(tx *DB) error {
+		if err := NewToolDetectionRepository(tx).Replace("s1", map[string]int{"Y-perm": 2}); err != nil {
+			return err
+		}
+		if got, _ := NewToolDetectionRepository(tx).SolvesByTool(); got["Y-perm"] != 1 {
+			t.Errorf("within the transaction, detections = %v", got)
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("InTx = %v, want the function's error", err)
+	}
+	check("after an error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = db.InTx(ctx, func(tx *DB) error {
+		if err := NewToolDetectionRepository(tx).Replace("s1", nil); err != nil {
+			return err
+		}
+		cancel() // Ctrl+C before the commit
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("InTx cancelled = %v, want context.Canceled", err)
+	}
+	check("after cancelling")
+
+	// Nested calls run in the outer transaction
+	err = db.InTx(context.Background(), func(tx *DB) error {
+		return tx.InTx(context.Background(), func(inner *DB) error {
+			return NewToolDetectionRepository(inner).Replace("s1", map[string]int{"Y-perm": 1})
+		})
+	})
+	if err != nil {
+		t.Fatalf("InTx: %v", err)
+	}
+	want = map[string]int{"Y-perm": 1}
+	check("after committing")
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DeviceStats summarizes the recorded use of one cube over its lifetime.
+type DeviceStats struct {
+	DeviceID   string
+	DeviceName *string   // Most recent name the cube advertised
+	Solves     int       // Solves recorded with the cube
+	Moves      int       // Moves recorded across those solves
+	LastSeen   time.Time // Start of the most recent solve
+
+	// Average battery percentage used per solve, over the solves with at
+	// least two battery readings in which the level did not rise (charging);
+	// nil when there are none
+	BatteryDrainPerSolve *float64
+	BatterySolves        int // Solves the drain average is taken over
+}
+
+// DeviceRepository provides per-device statistics derived from the solves
+// recorded with each cube.
+type DeviceRepository struct {
+	db *DB
+}
+
+// NewDeviceRepository creates a new device repository.
+func NewDeviceRepository(db *DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// List returns the lifetime stats of every cube that has recorded a solve,
+// most recently seen first.
+func (r *DeviceRepository) List() ([]DeviceStats, error) {
+	rows, err := r.db.Query(`
+		SELECT s.device_id,
+		       (SELECT n.device_name FROM solves n
+		        WHERE n.device_id = s.device_id AND n.device_name IS NOT NULL
+		        ORDER BY n.started_at DESC LIMIT 1),
+		       COUNT(*),
+		       (SELECT COUNT(*) FROM moves m JOIN solves ms ON ms.solve_id = m.solve_id
+		        WHERE ms.device_id = s.device_id),
+		       MAX(s.started_at)
+		FROM solves s
+		WHERE s.device_id IS NOT NULL AND s.device_id != ''
+		GROUP BY s.device_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []DeviceStats
+	index := make(map[string]int)
+	for rows.Next() {
+		var d DeviceStats
+		var lastSeen string
+		if err := rows.Scan(&d.DeviceID, &d.DeviceName, &d.Solves, &d.Moves, &lastSeen); err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		d.LastSeen, _ = time.Parse(time.RFC3339, lastSeen)
+		index[d.DeviceID] = len(devices)
+		devices = append(devices, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	drains, err := r.batteryDrains()
+	if err != nil {
+		return nil, err
+	}
+	for id, solveDrains := range drains {
+		i, ok := index[id]
+		if !ok || len(solveDrains) == 0 {
+			continue
+		}
+		var total float64
+		for _, d := range solveDrains {
+			total += float64(d)
+		}
+		avg := total / float64(len(solveDrains))
+		devices[i].BatteryDrainPerSolve = &avg
+		devices[i].BatterySolves = len(solveDrains)
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].LastSeen.After(devices[j].LastSeen)
+	})
+	return devices, nil
+}
+
+// batteryDrains returns, per device, the battery percentage used in each
+// solve with at least two battery readings: first level minus last.
+// Solves in which the level rose are skipped, as the cube was charging.
+func (r *DeviceRepository) batteryDrains() (map[string][]int, error) {
+	rows, err := r.db.Query(`
+		SELECT s.device_id, e.solve_id, e.payload_json
+		FROM events e JOIN solves s ON s.solve_id = e.solve_id
+		WHERE e.event_type = 'battery' AND s.device_id IS NOT NULL
+		ORDER BY e.solve_id, e.ts_ms, e.event_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get battery readings: %w", err)
+	}
+	defer rows.Close()
+
+	type span struct {
+		deviceID    string
+		first, last int
+		readings    int
+	}
+	var spans []*span
+	current := ""
+	for rows.Next() {
+		var deviceID, solveID, payload string
+		if err := rows.Scan(&deviceID, &solveID, &payload); err != nil {
+			return nil, fmt.Errorf("failed to scan battery reading: %w", err)
+		}
+		var reading struct{ Level int }
+		if err := json.Unmarshal([]byte(payload), &reading); err != nil {
+			continue
+		}
+		if solveID != current {
+			current = solveID
+			spans = append(spans, &span{deviceID: deviceID, first: reading.Level})
+		}
+		sp := spans[len(spans)-1]
+		sp.last = reading.Level
+		sp.readings++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	drains := make(map[string][]int)
+	for _, sp := range spans {
+		if sp.readings < 2 || sp.last > sp.first {
+			continue
+		}
+		drains[sp.deviceID] = append(drains[sp.deviceID], sp.first-sp.last)
+	}
+	return drains, nil
+}
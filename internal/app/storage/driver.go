@@ -0,0 +1,8 @@
+//go:build !js
+
+package storage
+
+// The SQLite driver does not build for WebAssembly. Under js/wasm the
+// package still provides its record types to the analysis code, but
+// Open fails.
+import _ "modernc.org/sqlite"
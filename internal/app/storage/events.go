@@ -9,6 +9,7 @@ type Event struct {
 	EventID         int64
 	SolveID         string
 	TsMs            int64
+	TsUs            int64
 	EventType       string
 	PayloadJSON     string
 	RawPayloadBase64 *string
@@ -24,12 +25,12 @@ func NewEventRepository(db *DB) *EventRepository {
 	return &EventRepository{db: db}
 }
 
-// Create creates a new event and returns its ID.
-func (r *EventRepository) Create(solveID string, tsMs int64, eventType, payloadJSON string, rawBase64 *string) (int64, error) {
+// Create creates a new event at tsUs microseconds and returns its ID.
+func (r *EventRepository) Create(solveID string, tsUs int64, eventType, payloadJSON string, rawBase64 *string) (int64, error) {
 	result, err := r.db.Exec(`
-		INSERT INTO events (solve_id, ts_ms, event_type, payload_json, raw_payload_base64)
-		VALUES (?, ?, ?, ?, ?)
-	`, solveID, tsMs, eventType, payloadJSON, rawBase64)
+		INSERT INTO events (solve_id, ts_ms, ts_us, event_type, payload_json, raw_payload_base64)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, solveID, tsUs/1000, tsUs, eventType, payloadJSON, rawBase64)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create event: %w", err)
@@ -46,7 +47,7 @@ func (r *EventRepository) Create(solveID string, tsMs int64, eventType, payloadJ
 // GetBySolve retrieves all events for a solve.
 func (r *EventRepository) GetBySolve(solveID string) ([]Event, error) {
 	rows, err := r.db.Query(`
-		SELECT event_id, solve_id, ts_ms, event_type, payload_json, raw_payload_base64
+		SELECT event_id, solve_id, ts_ms, COALESCE(ts_us, ts_ms * 1000), event_type, payload_json, raw_payload_base64
 		FROM events
 		WHERE solve_id = ?
 		ORDER BY ts_ms
@@ -60,7 +61,7 @@ func (r *EventRepository) GetBySolve(solveID string) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
 		var e Event
-		err := rows.Scan(&e.EventID, &e.SolveID, &e.TsMs, &e.EventType, &e.PayloadJSON, &e.RawPayloadBase64)
+		err := rows.Scan(&e.EventID, &e.SolveID, &e.TsMs, &e.TsUs, &e.EventType, &e.PayloadJSON, &e.RawPayloadBase64)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
 		}
@@ -73,10 +74,10 @@ func (r *EventRepository) GetBySolve(solveID string) ([]Event, error) {
 // GetByType retrieves all events of a specific type for a solve.
 func (r *EventRepository) GetByType(solveID, eventType string) ([]Event, error) {
 	rows, err := r.db.Query(`
-		SELECT event_id, solve_id, ts_ms, event_type, payload_json, raw_payload_base64
+		SELECT event_id, solve_id, ts_ms, COALESCE(ts_us, ts_ms * 1000), event_type, payload_json, raw_payload_base64
 		FROM events
 		WHERE solve_id = ? AND event_type = ?
-		ORDER BY ts_ms
+		ORDER BY ts_ms, event_id
 	`, solveID, eventType)
 
 	if err != nil {
@@ -87,7 +88,7 @@ func (r *EventRepository) GetByType(solveID, eventType string) ([]Event, error)
 	var events []Event
 	for rows.Next() {
 		var e Event
-		err := rows.Scan(&e.EventID, &e.SolveID, &e.TsMs, &e.EventType, &e.PayloadJSON, &e.RawPayloadBase64)
+		err := rows.Scan(&e.EventID, &e.SolveID, &e.TsMs, &e.TsUs, &e.EventType, &e.PayloadJSON, &e.RawPayloadBase64)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan event: %w", err)
 		}
@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportedSolve is a completed solve as other timer tools see it: the
+// scramble, the solve time and the moves of each phase, without the
+// scramble and inspection.
+type ExportedSolve struct {
+	Solve  Solve
+	TimeMs int64 // Solve time, excluding scramble and inspection
+	Phases []ExportedPhase
+}
+
+// ExportedPhase is the moves made during one phase of a solve.
+type ExportedPhase struct {
+	PhaseKey string // "" when the solve has no phase segments
+	Moves    []string
+}
+
+// Solution returns the solve's moves in standard notation.
+func (s *ExportedSolve) Solution() string {
+	var moves []string
+	for _, p := range s.Phases {
+		moves = append(moves, p.Moves...)
+	}
+	return strings.Join(moves, " ")
+}
+
+// Exporter loads completed solves for export.
+type Exporter struct {
+	moveRepo  *MoveRepository
+	phaseRepo *PhaseRepository
+}
+
+// NewExporter creates a new exporter.
+func NewExporter(db *DB) *Exporter {
+	return &Exporter{
+		moveRepo:  NewMoveRepository(db),
+		phaseRepo: NewPhaseRepository(db),
+	}
+}
+
+// Load builds the exported form of each completed solve, skipping solves
+// that have not ended.
+func (e *Exporter) Load(solves []Solve) ([]ExportedSolve, error) {
+	exported := make([]ExportedSolve, 0, len(solves))
+	for _, s := range solves {
+		if s.DurationMs == nil {
+			continue
+		}
+
+		moves, err := e.moveRepo.GetBySolve(s.SolveID)
+		if err != nil {
+			return nil, err
+		}
+		segments, err := e.phaseRepo.GetPhaseSegments(s.SolveID)
+		if err != nil {
+			return nil, err
+		}
+
+		ex := ExportedSolve{Solve: s}
+		for i, seg := range segments {
+			if seg.PhaseKey == "scramble" || seg.PhaseKey == "inspection" {
+				continue
+			}
+			ex.TimeMs += seg.DurationMs
+
+			phase := ExportedPhase{PhaseKey: seg.PhaseKey}
+			last := i == len(segments)-1
+			for _, m := range moves {
+				if m.TsMs >= seg.StartTsMs && (m.TsMs < seg.EndTsMs || last && m.TsMs == seg.EndTsMs) {
+					phase.Moves = append(phase.Moves, m.Notation)
+				}
+			}
+			ex.Phases = append(ex.Phases, phase)
+		}
+
+		// Without phase marks the whole recording is the solve
+		if len(ex.Phases) == 0 {
+			ex.TimeMs = *s.DurationMs
+			phase := ExportedPhase{}
+			for _, m := range moves {
+				phase.Moves = append(phase.Moves, m.Notation)
+			}
+			ex.Phases = append(ex.Phases, phase)
+		}
+
+		exported = append(exported, ex)
+	}
+	return exported, nil
+}
+
+// WriteCSTimer writes solves as a csTimer export file (Options > Export),
+// all in one session named name. csTimer imports the times, scrambles and
+// dates; notes become the solve comments.
+func WriteCSTimer(w io.Writer, solves []ExportedSolve, name string) error {
+	times := make([]interface{}, 0, len(solves))
+	var total int64
+	for _, s := range solves {
+		scramble, comment := "", ""
+		if s.Solve.ScrambleText != nil {
+			scramble = *s.Solve.ScrambleText
+		}
+		if s.Solve.Notes != nil {
+			comment = *s.Solve.Notes
+		}
+		// [[penalty, time ms], scramble, comment, unix seconds]
+		times = append(times, []interface{}{
+			[]int64{0, s.TimeMs}, scramble, comment, s.Solve.StartedAt.Unix(),
+		})
+		total += s.TimeMs
+	}
+
+	session := map[string]interface{}{
+		"name": name,
+		"opt":  map[string]interface{}{},
+		"rank": 1,
+	}
+	if len(solves) > 0 {
+		session["stat"] = []int64{int64(len(solves)), 0, total / int64(len(solves))}
+		session["date"] = []int64{solves[0].Solve.StartedAt.Unix(), solves[len(solves)-1].Solve.StartedAt.Unix()}
+	}
+	// csTimer keeps the session metadata as a JSON string
+	sessionData, err := json.Marshal(map[string]interface{}{"1": session})
+	if err != nil {
+		return fmt.Errorf("failed to marshal csTimer session: %w", err)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"session1":   times,
+		"properties": map[string]string{"sessionData": string(sessionData)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal csTimer export: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write csTimer export: %w", err)
+	}
+	return nil
+}
+
+// WriteReconstructions writes each solve as a plain text reconstruction:
+// the time and scramble, the solution one phase per line with the phase
+// name as a comment, and a link that plays it back on alg.cubing.net.
+func WriteReconstructions(w io.Writer, solves []ExportedSolve) error {
+	var b strings.Builder
+	for i, s := range solves {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		scramble := ""
+		if s.Solve.ScrambleText != nil {
+			scramble = *s.Solve.ScrambleText
+		}
+
+		fmt.Fprintf(&b, "%.2f  %s\n", float64(s.TimeMs)/1000, s.Solve.StartedAt.UTC().Format(time.RFC3339))
+		fmt.Fprintf(&b, "Scramble: %s\n\n", scramble)
+
+		var lines []string
+		for _, p := range s.Phases {
+			line := strings.Join(p.Moves, " ")
+			if p.PhaseKey != "" {
+				line += " // " + PhaseDisplayName(p.PhaseKey)
+			}
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+		b.WriteString("\n\n")
+		fmt.Fprintf(&b, "%s\n", algCubingURL(scramble, strings.Join(lines, "\n"), s.TimeMs))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write reconstructions: %w", err)
+	}
+	return nil
+}
+
+// algCubingURL returns an alg.cubing.net link for a reconstruction, which
+// writes spaces as _ and primes as -.
+func algCubingURL(setup, alg string, timeMs int64) string {
+	enc := func(s string) string {
+		s = strings.NewReplacer(" ", "_", "'", "-").Replace(s)
+		return url.QueryEscape(s)
+	}
+	return fmt.Sprintf("https://alg.cubing.net/?setup=%s&alg=%s&title=%s",
+		enc(setup), enc(alg), enc(fmt.Sprintf("%.2f", float64(timeMs)/1000)))
+}
+
+// MoveRow is one move as the moves export writes it, for data analysis.
+type MoveRow struct {
+	SolveID   string
+	MoveIndex int
+	TsMs      int64 // Since the solve's recording started
+	Face      string
+	Turn      int
+	Notation  string
+	PhaseKey  string // Phase the move was made in; "" outside the marked phases
+	GapMs     int64  // Since the solve's previous move; 0 for its first
+}
+
+// moveRowColumns are the column names of a moves export, in order.
+var moveRowColumns = []string{"solve_id", "move_index", "ts_ms", "face", "turn", "notation", "phase_key", "gap_ms"}
+
+// MoveRows returns every move of solves, scramble included, in order, with
+// the phase it was made in.
+func (e *Exporter) MoveRows(solves []Solve) ([]MoveRow, error) {
+	var rows []MoveRow
+	for _, s := range solves {
+		moves, err := e.moveRepo.GetBySolve(s.SolveID)
+		if err != nil {
+			return nil, err
+		}
+		segments, err := e.phaseRepo.GetPhaseSegments(s.SolveID)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, m := range moves {
+			row := MoveRow{
+				SolveID:   s.SolveID,
+				MoveIndex: m.MoveIndex,
+				TsMs:      m.TsMs,
+				Face:      m.Face,
+				Turn:      m.Turn,
+				Notation:  m.Notation,
+			}
+			if i > 0 {
+				row.GapMs = m.TsMs - moves[i-1].TsMs
+			}
+			for j, seg := range segments {
+				last := j == len(segments)-1
+				if m.TsMs >= seg.StartTsMs && (m.TsMs < seg.EndTsMs || last && m.TsMs == seg.EndTsMs) {
+					row.PhaseKey = seg.PhaseKey
+					break
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// WriteMovesCSV writes rows as CSV with a header line.
+func WriteMovesCSV(w io.Writer, rows []MoveRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(moveRowColumns); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		record := []string{
+			r.SolveID,
+			strconv.Itoa(r.MoveIndex),
+			strconv.FormatInt(r.TsMs, 10),
+			r.Face,
+			strconv.Itoa(r.Turn),
+			r.Notation,
+			r.PhaseKey,
+			strconv.FormatInt(r.GapMs, 10),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMovesParquet writes rows as a Parquet file with the columns of
+// WriteMovesCSV; counts are INT32, times INT64 and the rest UTF-8 strings.
+func WriteMovesParquet(w io.Writer, rows []MoveRow) error {
+	n := len(rows)
+	solveIDs, faces, notations, phases := make([]string, n), make([]string, n), make([]string, n), make([]string, n)
+	indexes, turns := make([]int32, n), make([]int32, n)
+	times, gaps := make([]int64, n), make([]int64, n)
+	for i, r := range rows {
+		solveIDs[i], faces[i], notations[i], phases[i] = r.SolveID, r.Face, r.Notation, r.PhaseKey
+		indexes[i], turns[i] = int32(r.MoveIndex), int32(r.Turn)
+		times[i], gaps[i] = r.TsMs, r.GapMs
+	}
+	values := []interface{}{solveIDs, indexes, times, faces, turns, notations, phases, gaps}
+	columns := make([]parquetColumn, len(values))
+	for i, v := range values {
+		columns[i] = parquetColumn{Name: moveRowColumns[i], Values: v}
+	}
+	return writeParquet(w, columns, n)
+}
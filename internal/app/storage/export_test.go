@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testMoveRows are two solves' moves, with a move outside the phases and
+// a value that needs quoting in CSV.
+func testMoveRows() []MoveRow {
+	return []MoveRow{
+		{SolveID: "s1", MoveIndex: 0, TsMs: 0, Face: "R", Turn: 1, Notation: "R", PhaseKey: "", GapMs: 0},
+		{SolveID: "s1", MoveIndex: 1, TsMs: 412, Face: "U", Turn: -1, Notation: "U'", PhaseKey: "cross", GapMs: 412},
+		{SolveID: "s1", MoveIndex: 2, TsMs: 530, Face: "F", Turn: 2, Notation: "F2", PhaseKey: "f2l,1", GapMs: 118},
+		{SolveID: "s2", MoveIndex: 0, TsMs: 9000000000, Face: "D", Turn: 1, Notation: "D", PhaseKey: "oll", GapMs: 0},
+	}
+}
+
+func TestWriteMovesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMovesCSV(&buf, testMoveRows()); err != nil {
+		t.Fatalf("WriteMovesCSV: %v", err)
+	}
+	want := `solve_id,move_index,ts_ms,face,turn,notation,phase_key,gap_ms
+s1,0,0,R,1,R,,0
+s1,1,412,U,-1,U',cross,412
+s1,2,530,F,2,F2,"f2l,1",118
+s2,0,9000000000,D,1,D,oll,0
+`
+	if buf.String() != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("reading the CSV back: %v", err)
+	}
+	if !reflect.DeepEqual(records[0], moveRowColumns) || records[3][6] != "f2l,1" {
+		t.Errorf("read back header %v and phase %q", records[0], records[3][6])
+	}
+
+	// No rows still gives the header
+	buf.Reset()
+	if err := WriteMovesCSV(&buf, nil); err != nil || buf.String() != strings.Join(moveRowColumns, ",")+"\n" {
+		t.Errorf("empty CSV = %q, %v", buf.String(), err)
+	}
+}
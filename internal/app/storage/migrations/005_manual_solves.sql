@@ -0,0 +1,11 @@
+-- GoCube Solve Recorder Schema v5
+-- Migration: 005_manual_solves
+-- Adds solve source so manually timed solves (no move stream) can be
+-- stored alongside smart-cube solves
+
+ALTER TABLE solves ADD COLUMN source TEXT NOT NULL DEFAULT 'smart'
+  CHECK (source IN ('smart', 'manual'));
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (5, datetime('now'));
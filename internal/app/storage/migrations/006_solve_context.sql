@@ -0,0 +1,19 @@
+-- GoCube Solve Recorder Schema v6
+-- Migration: 006_solve_context
+-- Adds free-form key/value context attached at solve start
+-- (cube used, lube state, mood, location, ...)
+
+CREATE TABLE IF NOT EXISTS solve_context (
+  solve_id  TEXT NOT NULL,
+  key       TEXT NOT NULL,
+  value     TEXT NOT NULL,
+  PRIMARY KEY (solve_id, key),
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_solve_context_key_value
+  ON solve_context(key, value);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (6, datetime('now'));
@@ -0,0 +1,25 @@
+-- GoCube Solve Recorder Schema v7
+-- Migration: 007_device_counters
+-- Experimental: readings of the cube's cumulative offline move counter,
+-- taken at solve start and end to detect dropped rotation frames
+
+CREATE TABLE IF NOT EXISTS device_counters (
+  reading_id      INTEGER PRIMARY KEY AUTOINCREMENT,
+  device_id       TEXT NOT NULL,
+  solve_id        TEXT,                           -- solve the reading was taken for, if any
+  read_at         TEXT NOT NULL,                  -- ISO8601 UTC
+  counter_moves   INTEGER NOT NULL,               -- cumulative moves reported by the cube
+  counter_time_s  INTEGER NOT NULL,
+  counter_solves  INTEGER NOT NULL,
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_counters_device
+  ON device_counters(device_id, read_at);
+
+CREATE INDEX IF NOT EXISTS idx_device_counters_solve
+  ON device_counters(solve_id);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (7, datetime('now'));
@@ -0,0 +1,18 @@
+-- GoCube Solve Recorder Schema v8
+-- Migration: 008_microsecond_timestamps
+-- Microsecond timestamps for events, moves and orientations. At high TPS,
+-- millisecond timestamps quantize inter-move gaps too coarsely. ts_ms is
+-- kept (and still written) for range queries and older readers.
+
+ALTER TABLE events ADD COLUMN ts_us INTEGER;
+ALTER TABLE moves ADD COLUMN ts_us INTEGER;
+ALTER TABLE orientations ADD COLUMN ts_us INTEGER;
+
+-- Backfill existing rows from their millisecond timestamps
+UPDATE events SET ts_us = ts_ms * 1000 WHERE ts_us IS NULL;
+UPDATE moves SET ts_us = ts_ms * 1000 WHERE ts_us IS NULL;
+UPDATE orientations SET ts_us = ts_ms * 1000 WHERE ts_us IS NULL;
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (8, datetime('now'));
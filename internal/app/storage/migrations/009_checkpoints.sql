@@ -0,0 +1,18 @@
+-- GoCube Solve Recorder Schema v9
+-- Migration: 009_checkpoints
+-- Cube state snapshots taken every N moves while recording (and at each
+-- resync), so the state at any move can be rebuilt from the nearest
+-- checkpoint instead of replaying the whole solve
+
+CREATE TABLE IF NOT EXISTS checkpoints (
+  solve_id        TEXT NOT NULL,
+  move_index      INTEGER NOT NULL,               -- moves applied before the snapshot
+  ts_us           INTEGER NOT NULL,               -- microseconds since solve start
+  facelets_json   TEXT NOT NULL,                  -- [6][9] facelet colors
+  PRIMARY KEY (solve_id, move_index),
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (9, datetime('now'));
@@ -0,0 +1,17 @@
+-- GoCube Solve Recorder Schema v10
+-- Migration: 010_device_calibrations
+-- Per-device orientation correction captured by `gocube device calibrate`:
+-- the quaternion the cube reports when held white-up, green-front
+
+CREATE TABLE IF NOT EXISTS device_calibrations (
+  device_id       TEXT PRIMARY KEY,
+  ref_x           REAL NOT NULL,
+  ref_y           REAL NOT NULL,
+  ref_z           REAL NOT NULL,
+  ref_w           REAL NOT NULL,
+  calibrated_at   TEXT NOT NULL                   -- ISO8601 UTC
+);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (10, datetime('now'));
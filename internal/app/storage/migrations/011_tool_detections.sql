@@ -0,0 +1,18 @@
+-- GoCube Solve Recorder Schema v11
+-- Migration: 011_tool_detections
+-- Algorithms detected in each solve's final phase, so case statistics can be
+-- compared and kept in sync when the algorithm library changes
+
+CREATE TABLE IF NOT EXISTS tool_detections (
+  solve_id        TEXT NOT NULL,
+  tool_name       TEXT NOT NULL,
+  count           INTEGER NOT NULL,               -- times detected in the solve
+  PRIMARY KEY (solve_id, tool_name),
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_tool_detections_tool ON tool_detections(tool_name);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (11, datetime('now'));
@@ -0,0 +1,17 @@
+-- GoCube Solve Recorder Schema v12
+-- Migration: 012_utc_timestamps
+-- Normalize solve timestamps written with a local UTC offset to UTC
+-- ("...Z"), so range queries compare like with like; times are converted
+-- to the configured time zone only when displayed
+
+UPDATE solves
+SET started_at = strftime('%Y-%m-%dT%H:%M:%SZ', started_at)
+WHERE started_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', started_at) IS NOT NULL;
+
+UPDATE solves
+SET ended_at = strftime('%Y-%m-%dT%H:%M:%SZ', ended_at)
+WHERE ended_at IS NOT NULL AND ended_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', ended_at) IS NOT NULL;
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (12, datetime('now'));
@@ -0,0 +1,21 @@
+-- GoCube Solve Recorder Schema v13
+-- Migration: 013_achievements
+-- PLL case met in each solve and the achievements earned, updated as
+-- solves are stored
+
+CREATE TABLE IF NOT EXISTS pll_cases (
+  solve_id        TEXT PRIMARY KEY,
+  case_name       TEXT NOT NULL,                  -- e.g. "T", "Ua"
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS achievements (
+  achievement_key TEXT PRIMARY KEY,
+  earned_at       TEXT NOT NULL,                  -- RFC3339 UTC
+  solve_id        TEXT,                           -- solve that earned it
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE SET NULL
+);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (13, datetime('now'));
@@ -0,0 +1,10 @@
+-- GoCube Solve Recorder Schema v14
+-- Migration: 014_cube_type
+-- Adds the cube model reported by the device (standard or edge) to each
+-- solve; NULL when the cube did not report one
+
+ALTER TABLE solves ADD COLUMN cube_type TEXT;
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (14, datetime('now'));
@@ -0,0 +1,13 @@
+-- GoCube Solve Recorder Schema v15
+-- Migration: 015_move_batches
+-- Records where each move sits in the notification that reported it. A cube
+-- reports turns made at nearly the same moment in one notification, so
+-- batch_size > 1 marks moves whose order among each other is uncertain.
+-- Existing moves are treated as reported alone.
+
+ALTER TABLE moves ADD COLUMN batch_index INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE moves ADD COLUMN batch_size INTEGER NOT NULL DEFAULT 1;
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (15, datetime('now'));
@@ -0,0 +1,18 @@
+-- GoCube Solve Recorder Schema v16
+-- Migration: 016_sessions
+-- Practice sessions: named spans of time, each grouping the solves started
+-- in it. At most one session is open (ended_at NULL) at a time.
+
+CREATE TABLE IF NOT EXISTS sessions (
+  session_id      TEXT PRIMARY KEY,
+  name            TEXT,
+  started_at      TEXT NOT NULL,                  -- RFC3339 UTC
+  ended_at        TEXT                            -- RFC3339 UTC; NULL while open
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_started_at
+  ON sessions(started_at);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (16, datetime('now'));
@@ -0,0 +1,23 @@
+-- GoCube Solve Recorder Schema v17
+-- Migration: 017_personal_bests
+-- Personal best history: every time a solve set a new best single, ao5,
+-- ao12 or phase split, with the best it beat, so progress and regressions
+-- can be followed over time.
+
+CREATE TABLE IF NOT EXISTS personal_bests (
+  kind            TEXT NOT NULL,                  -- "single", "ao5", "ao12" or "phase"
+  phase_key       TEXT NOT NULL DEFAULT '',       -- for "phase"; '' otherwise
+  value_ms        REAL NOT NULL,
+  previous_ms     REAL,                           -- NULL for the first best
+  solve_id        TEXT NOT NULL,                  -- solve that set it (last of an average)
+  achieved_at     TEXT NOT NULL,                  -- RFC3339 UTC
+  PRIMARY KEY (kind, phase_key, solve_id),
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_bests_achieved_at
+  ON personal_bests(achieved_at);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (17, datetime('now'));
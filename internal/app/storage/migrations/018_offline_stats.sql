@@ -0,0 +1,28 @@
+-- GoCube Solve Recorder Schema v18
+-- Migration: 018_offline_stats
+-- Offline stats syncs: each reading of the cube's cumulative move, time and
+-- solve counters with what it added since the previous sync of the cube.
+-- Summing the deltas gives lifetime totals that include turns made away
+-- from the app and survive the cube resetting its counters.
+
+CREATE TABLE IF NOT EXISTS offline_stats (
+  sync_id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  device_id       TEXT NOT NULL,
+  device_name     TEXT,
+  synced_at       TEXT NOT NULL,                  -- RFC3339 UTC
+  counter_moves   INTEGER NOT NULL,               -- counters as the cube reported them
+  counter_time_s  INTEGER NOT NULL,
+  counter_solves  INTEGER NOT NULL,
+  delta_moves     INTEGER NOT NULL,               -- counted since the previous sync; the whole
+  delta_time_s    INTEGER NOT NULL,               -- counter on the first sync or after a reset
+  delta_solves    INTEGER NOT NULL,
+  recorded_moves  INTEGER NOT NULL,               -- moves recorded with the cube in the app so far
+  counter_reset   INTEGER NOT NULL DEFAULT 0      -- 1 if a counter went backwards
+);
+
+CREATE INDEX IF NOT EXISTS idx_offline_stats_device
+  ON offline_stats(device_id, sync_id);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (18, datetime('now'));
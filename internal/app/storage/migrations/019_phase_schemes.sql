@@ -0,0 +1,11 @@
+-- GoCube Solve Recorder Schema v19
+-- Migration: 019_phase_schemes
+-- Phase definitions of user-defined phase schemes (Roux, ZZ, ...) live
+-- alongside the built-in layer-by-layer ones, tagged with their scheme.
+-- Each solve's scheme is kept in its "phase_scheme" context.
+
+ALTER TABLE phase_defs ADD COLUMN scheme TEXT NOT NULL DEFAULT 'lbl';
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (19, datetime('now'));
@@ -0,0 +1,12 @@
+-- GoCube Solve Recorder Schema v20
+-- Migration: 020_move_time_source
+-- Records how each move's timestamp was obtained: "received" (when its
+-- notification arrived), "device" (placed by the cube's own clock) or
+-- "interpolated" (spread over its notification's interval), so analysis
+-- can tell measured timing from inferred. Existing moves were received.
+
+ALTER TABLE moves ADD COLUMN time_source TEXT NOT NULL DEFAULT 'received';
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (20, datetime('now'));
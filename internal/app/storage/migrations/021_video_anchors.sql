@@ -0,0 +1,21 @@
+-- GoCube Solve Recorder Schema v21
+-- Migration: 021_video_anchors
+-- UTC wall-clock anchors at solve start, each phase mark and solve end, to
+-- line recorded solves up with a camera recording of them.
+
+CREATE TABLE IF NOT EXISTS video_anchors (
+  anchor_id       INTEGER PRIMARY KEY AUTOINCREMENT,
+  solve_id        TEXT NOT NULL,
+  kind            TEXT NOT NULL,                  -- "solve_start", "phase" or "solve_end"
+  phase_key       TEXT NOT NULL DEFAULT '',       -- for "phase"; '' otherwise
+  ts_ms           INTEGER NOT NULL,               -- time within the solve
+  wall_utc        TEXT NOT NULL,                  -- RFC3339 UTC, by the recording computer's clock
+  FOREIGN KEY (solve_id) REFERENCES solves(solve_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_anchors_solve
+  ON video_anchors(solve_id, ts_ms);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (21, datetime('now'));
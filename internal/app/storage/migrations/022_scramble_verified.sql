@@ -0,0 +1,10 @@
+-- GoCube Solve Recorder Schema v22
+-- Migration: 022_scramble_verified
+-- Marks solves whose moves were checked against their scramble and matched
+-- it exactly before the solve began
+
+ALTER TABLE solves ADD COLUMN scramble_verified INTEGER NOT NULL DEFAULT 0;
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (22, datetime('now'));
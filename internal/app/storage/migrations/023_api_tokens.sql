@@ -0,0 +1,15 @@
+-- GoCube Solve Recorder Schema v23
+-- Migration: 023_api_tokens
+-- Named bearer tokens for 'gocube serve', each with its scopes. Only a hash
+-- of each token is stored.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+  name            TEXT PRIMARY KEY,
+  token_hash      TEXT NOT NULL UNIQUE,           -- hex SHA-256 of the token
+  scopes          TEXT NOT NULL,                  -- space-separated, e.g. "stats:read recording:control"
+  created_at      TEXT NOT NULL                   -- RFC3339 UTC
+);
+
+-- Record migration version
+INSERT OR REPLACE INTO schema_version(version, applied_at)
+VALUES (23, datetime('now'));
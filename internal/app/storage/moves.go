@@ -14,10 +14,21 @@ type MoveRecord struct {
 	SolveID       string
 	MoveIndex     int
 	TsMs          int64
+	TsUs          int64 // Microseconds since solve start; TsMs = TsUs / 1000
 	Face          string
 	Turn          int
 	Notation      string
 	SourceEventID *int64
+
+	// BatchIndex and BatchSize place the move within the notification that
+	// reported it (see gocube.Move); BatchSize is 0 or 1 for a move reported
+	// alone.
+	BatchIndex int
+	BatchSize  int
+
+	// TimeSource tells how TsUs was obtained: "received", "device" or
+	// "interpolated" (see gocube.TimeSource).
+	TimeSource string
 }
 
 // MoveRepository provides CRUD operations for moves.
@@ -30,12 +41,12 @@ func NewMoveRepository(db *DB) *MoveRepository {
 	return &MoveRepository{db: db}
 }
 
-// Create creates a new move and returns its ID.
-func (r *MoveRepository) Create(solveID string, moveIndex int, tsMs int64, move gocube.Move, sourceEventID *int64) (int64, error) {
+// Create creates a new move at tsUs microseconds and returns its ID.
+func (r *MoveRepository) Create(solveID string, moveIndex int, tsUs int64, move gocube.Move, sourceEventID *int64) (int64, error) {
 	result, err := r.db.Exec(`
-		INSERT INTO moves (solve_id, move_index, ts_ms, face, turn, notation, source_event_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, solveID, moveIndex, tsMs, string(move.Face), int(move.Turn), move.Notation(), sourceEventID)
+		INSERT INTO moves (solve_id, move_index, ts_ms, ts_us, face, turn, notation, source_event_id, batch_index, batch_size, time_source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, solveID, moveIndex, tsUs/1000, tsUs, string(move.Face), int(move.Turn), move.Notation(), sourceEventID, move.BatchIndex, batchSize(move), move.TimeSource.String())
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create move: %w", err)
@@ -53,11 +64,11 @@ func (r *MoveRepository) Create(solveID string, moveIndex int, tsMs int64, move
 func (r *MoveRepository) CreateBatch(solveID string, moves []gocube.Move, startIndex int, sourceEventID *int64) error {
 	return r.db.Transaction(func(tx *sql.Tx) error {
 		for i, move := range moves {
-			tsMs := move.Time.UnixMilli()
+			tsUs := move.Time.UnixMicro()
 			_, err := tx.Exec(`
-				INSERT INTO moves (solve_id, move_index, ts_ms, face, turn, notation, source_event_id)
-				VALUES (?, ?, ?, ?, ?, ?, ?)
-			`, solveID, startIndex+i, tsMs, string(move.Face), int(move.Turn), move.Notation(), sourceEventID)
+				INSERT INTO moves (solve_id, move_index, ts_ms, ts_us, face, turn, notation, source_event_id, batch_index, batch_size, time_source)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, solveID, startIndex+i, tsUs/1000, tsUs, string(move.Face), int(move.Turn), move.Notation(), sourceEventID, move.BatchIndex, batchSize(move), move.TimeSource.String())
 			if err != nil {
 				return fmt.Errorf("failed to create move %d: %w", startIndex+i, err)
 			}
@@ -69,7 +80,7 @@ func (r *MoveRepository) CreateBatch(solveID string, moves []gocube.Move, startI
 // GetBySolve retrieves all moves for a solve in order.
 func (r *MoveRepository) GetBySolve(solveID string) ([]MoveRecord, error) {
 	rows, err := r.db.Query(`
-		SELECT move_id, solve_id, move_index, ts_ms, face, turn, notation, source_event_id
+		SELECT move_id, solve_id, move_index, ts_ms, COALESCE(ts_us, ts_ms * 1000), face, turn, notation, source_event_id, batch_index, batch_size, time_source
 		FROM moves
 		WHERE solve_id = ?
 		ORDER BY move_index
@@ -83,7 +94,7 @@ func (r *MoveRepository) GetBySolve(solveID string) ([]MoveRecord, error) {
 	var moves []MoveRecord
 	for rows.Next() {
 		var m MoveRecord
-		err := rows.Scan(&m.MoveID, &m.SolveID, &m.MoveIndex, &m.TsMs, &m.Face, &m.Turn, &m.Notation, &m.SourceEventID)
+		err := rows.Scan(&m.MoveID, &m.SolveID, &m.MoveIndex, &m.TsMs, &m.TsUs, &m.Face, &m.Turn, &m.Notation, &m.SourceEventID, &m.BatchIndex, &m.BatchSize, &m.TimeSource)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan move: %w", err)
 		}
@@ -98,7 +109,7 @@ func (r *MoveRepository) GetBySolve(solveID string) ([]MoveRecord, error) {
 // boundaries from being counted in both phases.
 func (r *MoveRepository) GetBySolveRange(solveID string, startTsMs, endTsMs int64) ([]MoveRecord, error) {
 	rows, err := r.db.Query(`
-		SELECT move_id, solve_id, move_index, ts_ms, face, turn, notation, source_event_id
+		SELECT move_id, solve_id, move_index, ts_ms, COALESCE(ts_us, ts_ms * 1000), face, turn, notation, source_event_id, batch_index, batch_size, time_source
 		FROM moves
 		WHERE solve_id = ? AND ts_ms >= ? AND ts_ms < ?
 		ORDER BY move_index
@@ -112,7 +123,35 @@ func (r *MoveRepository) GetBySolveRange(solveID string, startTsMs, endTsMs int6
 	var moves []MoveRecord
 	for rows.Next() {
 		var m MoveRecord
-		err := rows.Scan(&m.MoveID, &m.SolveID, &m.MoveIndex, &m.TsMs, &m.Face, &m.Turn, &m.Notation, &m.SourceEventID)
+		err := rows.Scan(&m.MoveID, &m.SolveID, &m.MoveIndex, &m.TsMs, &m.TsUs, &m.Face, &m.Turn, &m.Notation, &m.SourceEventID, &m.BatchIndex, &m.BatchSize, &m.TimeSource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan move: %w", err)
+		}
+		moves = append(moves, m)
+	}
+
+	return moves, nil
+}
+
+// GetBySolveIndexRange retrieves the moves of a solve with fromIndex <=
+// move_index < toIndex, in order.
+func (r *MoveRepository) GetBySolveIndexRange(solveID string, fromIndex, toIndex int) ([]MoveRecord, error) {
+	rows, err := r.db.Query(`
+		SELECT move_id, solve_id, move_index, ts_ms, COALESCE(ts_us, ts_ms * 1000), face, turn, notation, source_event_id, batch_index, batch_size, time_source
+		FROM moves
+		WHERE solve_id = ? AND move_index >= ? AND move_index < ?
+		ORDER BY move_index
+	`, solveID, fromIndex, toIndex)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get moves in index range: %w", err)
+	}
+	defer rows.Close()
+
+	var moves []MoveRecord
+	for rows.Next() {
+		var m MoveRecord
+		err := rows.Scan(&m.MoveID, &m.SolveID, &m.MoveIndex, &m.TsMs, &m.TsUs, &m.Face, &m.Turn, &m.Notation, &m.SourceEventID, &m.BatchIndex, &m.BatchSize, &m.TimeSource)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan move: %w", err)
 		}
@@ -149,10 +188,22 @@ func ToMoves(records []MoveRecord) []gocube.Move {
 	moves := make([]gocube.Move, len(records))
 	for i, r := range records {
 		moves[i] = gocube.Move{
-			Face: gocube.Face(r.Face),
-			Turn: gocube.Turn(r.Turn),
-			Time: time.UnixMilli(r.TsMs),
+			Face:       gocube.Face(r.Face),
+			Turn:       gocube.Turn(r.Turn),
+			Time:       time.UnixMicro(r.TsUs),
+			BatchIndex: r.BatchIndex,
+			BatchSize:  r.BatchSize,
+			TimeSource: gocube.ParseTimeSource(r.TimeSource),
 		}
 	}
 	return moves
 }
+
+// batchSize returns the batch size to store for move: 1 for a move not
+// reported in a notification.
+func batchSize(move gocube.Move) int {
+	if move.BatchSize < 1 {
+		return 1
+	}
+	return move.BatchSize
+}
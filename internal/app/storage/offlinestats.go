@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// OfflineSync is one reading of a cube's offline stats counters, with what
+// the cube counted since the previous sync.
+type OfflineSync struct {
+	SyncID     int64
+	DeviceID   string
+	DeviceName *string
+	SyncedAt   time.Time
+
+	// Counters as the cube reported them
+	Moves  int
+	TimeS  int
+	Solves int
+
+	// Counted since the previous sync: the whole counter on the first sync
+	// of a cube, or after it reset its counters
+	DeltaMoves  int
+	DeltaTimeS  int
+	DeltaSolves int
+
+	RecordedMoves int  // Moves recorded with the cube in the app as of the sync
+	CounterReset  bool // A counter went backwards (battery pull, firmware reset)
+
+	// AwayMoves is the part of DeltaMoves not recorded in the app, i.e.
+	// turned with the app closed. Computed, not stored.
+	AwayMoves int
+}
+
+// OfflineLifetime is the lifetime use of a cube from its offline stats
+// syncs: everything its counters have counted, app or not.
+type OfflineLifetime struct {
+	DeviceID   string
+	DeviceName *string
+	Moves      int
+	TimeS      int
+	Solves     int
+	AwayMoves  int // Moves not recorded in the app
+	Syncs      int
+	FirstSync  time.Time
+	LastSync   time.Time
+}
+
+// OfflineStatsRepository stores offline stats syncs.
+type OfflineStatsRepository struct {
+	db *DB
+}
+
+// NewOfflineStatsRepository creates a new offline stats repository.
+func NewOfflineStatsRepository(db *DB) *OfflineStatsRepository {
+	return &OfflineStatsRepository{db: db}
+}
+
+// Record stores a reading of a cube's offline stats counters and returns
+// the sync with its deltas from the cube's previous sync.
+func (r *OfflineStatsRepository) Record(deviceID, deviceName string, moves, timeS, solves int) (*OfflineSync, error) {
+	if deviceID == "" {
+		return nil, fmt.Errorf("offline stats need a device ID")
+	}
+	prev, err := r.last(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded int
+	err = r.db.QueryRow(`
+		SELECT COUNT(*) FROM moves m JOIN solves s ON s.solve_id = m.solve_id
+		WHERE s.device_id = ?
+	`, deviceID).Scan(&recorded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recorded moves: %w", err)
+	}
+
+	s := &OfflineSync{
+		DeviceID:      deviceID,
+		SyncedAt:      time.Now().UTC(),
+		Moves:         moves,
+		TimeS:         timeS,
+		Solves:        solves,
+		DeltaMoves:    moves,
+		DeltaTimeS:    timeS,
+		DeltaSolves:   solves,
+		RecordedMoves: recorded,
+	}
+	if deviceName != "" {
+		s.DeviceName = &deviceName
+	}
+	recordedSince := recorded
+	if prev != nil {
+		s.CounterReset = moves < prev.Moves || timeS < prev.TimeS || solves < prev.Solves
+		if !s.CounterReset {
+			s.DeltaMoves = moves - prev.Moves
+			s.DeltaTimeS = timeS - prev.TimeS
+			s.DeltaSolves = solves - prev.Solves
+		}
+		recordedSince = max(recorded-prev.RecordedMoves, 0)
+	}
+	s.AwayMoves = max(s.DeltaMoves-recordedSince, 0)
+
+	result, err := r.db.Exec(`
+		INSERT INTO offline_stats (device_id, device_name, synced_at, counter_moves, counter_time_s, counter_solves,
+		                           delta_moves, delta_time_s, delta_solves, recorded_moves, counter_reset)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.DeviceID, s.DeviceName, s.SyncedAt.Format(time.RFC3339Nano), s.Moves, s.TimeS, s.Solves,
+		s.DeltaMoves, s.DeltaTimeS, s.DeltaSolves, s.RecordedMoves, s.CounterReset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store offline stats: %w", err)
+	}
+	if s.SyncID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("failed to get offline stats ID: %w", err)
+	}
+	return s, nil
+}
+
+const offlineSyncColumns = `sync_id, device_id, device_name, synced_at, counter_moves, counter_time_s, counter_solves,
+	delta_moves, delta_time_s, delta_solves, recorded_moves, counter_reset`
+
+// last returns the latest sync of a cube, or nil if it has none.
+func (r *OfflineStatsRepository) last(deviceID string) (*OfflineSync, error) {
+	syncs, err := r.query(`SELECT `+offlineSyncColumns+` FROM offline_stats
+		WHERE device_id = ? ORDER BY sync_id DESC LIMIT 1`, deviceID)
+	if err != nil || len(syncs) == 0 {
+		return nil, err
+	}
+	return &syncs[0], nil
+}
+
+// List returns the syncs of a cube, or of every cube if deviceID is empty,
+// oldest first. AwayMoves is filled in from each cube's previous sync.
+func (r *OfflineStatsRepository) List(deviceID string) ([]OfflineSync, error) {
+	syncs, err := r.query(`SELECT `+offlineSyncColumns+` FROM offline_stats
+		WHERE ? = '' OR device_id = ? ORDER BY sync_id`, deviceID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[string]int{}
+	for i := range syncs {
+		s := &syncs[i]
+		since := s.RecordedMoves
+		if prev, ok := recorded[s.DeviceID]; ok {
+			since = max(s.RecordedMoves-prev, 0)
+		}
+		recorded[s.DeviceID] = s.RecordedMoves
+		s.AwayMoves = max(s.DeltaMoves-since, 0)
+	}
+	return syncs, nil
+}
+
+// Lifetimes returns the lifetime use of every synced cube, most recently
+// synced first.
+func (r *OfflineStatsRepository) Lifetimes() ([]OfflineLifetime, error) {
+	syncs, err := r.List("")
+	if err != nil {
+		return nil, err
+	}
+	lifetimes := sumLifetimes(syncs)
+	sort.SliceStable(lifetimes, func(i, j int) bool {
+		return lifetimes[i].LastSync.After(lifetimes[j].LastSync)
+	})
+	return lifetimes, nil
+}
+
+// Lifetime returns the lifetime use of a cube, or nil if it was never
+// synced.
+func (r *OfflineStatsRepository) Lifetime(deviceID string) (*OfflineLifetime, error) {
+	syncs, err := r.List(deviceID)
+	if err != nil || len(syncs) == 0 {
+		return nil, err
+	}
+	return &sumLifetimes(syncs)[0], nil
+}
+
+// sumLifetimes totals syncs per cube, in the order the cubes first synced.
+func sumLifetimes(syncs []OfflineSync) []OfflineLifetime {
+	index := map[string]int{}
+	var lifetimes []OfflineLifetime
+	for _, s := range syncs {
+		i, ok := index[s.DeviceID]
+		if !ok {
+			i = len(lifetimes)
+			index[s.DeviceID] = i
+			lifetimes = append(lifetimes, OfflineLifetime{DeviceID: s.DeviceID, FirstSync: s.SyncedAt})
+		}
+		l := &lifetimes[i]
+		if s.DeviceName != nil {
+			l.DeviceName = s.DeviceName
+		}
+		l.Moves += s.DeltaMoves
+		l.TimeS += s.DeltaTimeS
+		l.Solves += s.DeltaSolves
+		l.AwayMoves += s.AwayMoves
+		l.Syncs++
+		l.LastSync = s.SyncedAt
+	}
+	return lifetimes
+}
+
+func (r *OfflineStatsRepository) query(query string, args ...interface{}) ([]OfflineSync, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get offline stats: %w", err)
+	}
+	defer rows.Close()
+
+	var syncs []OfflineSync
+	for rows.Next() {
+		var s OfflineSync
+		var syncedAt string
+		if err := rows.Scan(&s.SyncID, &s.DeviceID, &s.DeviceName, &syncedAt, &s.Moves, &s.TimeS, &s.Solves,
+			&s.DeltaMoves, &s.DeltaTimeS, &s.DeltaSolves, &s.RecordedMoves, &s.CounterReset); err != nil {
+			return nil, fmt.Errorf("failed to scan offline stats: %w", err)
+		}
+		s.SyncedAt, _ = time.Parse(time.RFC3339Nano, syncedAt)
+		syncs = append(syncs, s)
+	}
+	return syncs, rows.Err()
+}
@@ -9,6 +9,7 @@ type OrientationRecord struct {
 	OrientationID int64
 	SolveID       string
 	TsMs          int64
+	TsUs          int64
 	UpFace        string
 	FrontFace     string
 	SourceEventID *int64
@@ -24,12 +25,12 @@ func NewOrientationRepository(db *DB) *OrientationRepository {
 	return &OrientationRepository{db: db}
 }
 
-// Create creates a new orientation record and returns its ID.
-func (r *OrientationRepository) Create(solveID string, tsMs int64, upFace, frontFace string, sourceEventID *int64) (int64, error) {
+// Create creates a new orientation record at tsUs microseconds and returns its ID.
+func (r *OrientationRepository) Create(solveID string, tsUs int64, upFace, frontFace string, sourceEventID *int64) (int64, error) {
 	result, err := r.db.Exec(`
-		INSERT INTO orientations (solve_id, ts_ms, up_face, front_face, source_event_id)
-		VALUES (?, ?, ?, ?, ?)
-	`, solveID, tsMs, upFace, frontFace, sourceEventID)
+		INSERT INTO orientations (solve_id, ts_ms, ts_us, up_face, front_face, source_event_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, solveID, tsUs/1000, tsUs, upFace, frontFace, sourceEventID)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create orientation: %w", err)
@@ -46,7 +47,7 @@ func (r *OrientationRepository) Create(solveID string, tsMs int64, upFace, front
 // GetBySolve retrieves all orientation records for a solve.
 func (r *OrientationRepository) GetBySolve(solveID string) ([]OrientationRecord, error) {
 	rows, err := r.db.Query(`
-		SELECT orientation_id, solve_id, ts_ms, up_face, front_face, source_event_id
+		SELECT orientation_id, solve_id, ts_ms, COALESCE(ts_us, ts_ms * 1000), up_face, front_face, source_event_id
 		FROM orientations
 		WHERE solve_id = ?
 		ORDER BY ts_ms
@@ -60,7 +61,7 @@ func (r *OrientationRepository) GetBySolve(solveID string) ([]OrientationRecord,
 	var orientations []OrientationRecord
 	for rows.Next() {
 		var o OrientationRecord
-		err := rows.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
+		err := rows.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.TsUs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan orientation: %w", err)
 		}
@@ -73,7 +74,7 @@ func (r *OrientationRepository) GetBySolve(solveID string) ([]OrientationRecord,
 // GetBySolveRange retrieves orientation records in a timestamp range.
 func (r *OrientationRepository) GetBySolveRange(solveID string, startMs, endMs int64) ([]OrientationRecord, error) {
 	rows, err := r.db.Query(`
-		SELECT orientation_id, solve_id, ts_ms, up_face, front_face, source_event_id
+		SELECT orientation_id, solve_id, ts_ms, COALESCE(ts_us, ts_ms * 1000), up_face, front_face, source_event_id
 		FROM orientations
 		WHERE solve_id = ? AND ts_ms >= ? AND ts_ms < ?
 		ORDER BY ts_ms
@@ -87,7 +88,7 @@ func (r *OrientationRepository) GetBySolveRange(solveID string, startMs, endMs i
 	var orientations []OrientationRecord
 	for rows.Next() {
 		var o OrientationRecord
-		err := rows.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
+		err := rows.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.TsUs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan orientation: %w", err)
 		}
@@ -110,7 +111,7 @@ func (r *OrientationRepository) Count(solveID string) (int, error) {
 // GetLast returns the most recent orientation for a solve.
 func (r *OrientationRepository) GetLast(solveID string) (*OrientationRecord, error) {
 	row := r.db.QueryRow(`
-		SELECT orientation_id, solve_id, ts_ms, up_face, front_face, source_event_id
+		SELECT orientation_id, solve_id, ts_ms, COALESCE(ts_us, ts_ms * 1000), up_face, front_face, source_event_id
 		FROM orientations
 		WHERE solve_id = ?
 		ORDER BY ts_ms DESC
@@ -118,7 +119,7 @@ func (r *OrientationRepository) GetLast(solveID string) (*OrientationRecord, err
 	`, solveID)
 
 	var o OrientationRecord
-	err := row.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
+	err := row.Scan(&o.OrientationID, &o.SolveID, &o.TsMs, &o.TsUs, &o.UpFace, &o.FrontFace, &o.SourceEventID)
 	if err != nil {
 		return nil, nil // No orientation found
 	}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A minimal Parquet writer: one row group, one uncompressed PLAIN data
+// page per column, required (non-null) INT32, INT64 and UTF-8 columns. That
+// is all the moves export needs, and every Parquet reader accepts it.
+// Metadata is Thrift compact protocol, written by hand below.
+
+// parquetColumn is one column of a Parquet file. Values is []int32,
+// []int64 or []string.
+type parquetColumn struct {
+	Name   string
+	Values interface{}
+}
+
+// Parquet physical types, encodings and page types used here.
+const (
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetDataPage = 0
+	parquetRequired = 0
+	parquetUTF8     = 0 // Converted type
+)
+
+var parquetMagic = []byte("PAR1")
+
+// writeParquet writes columns, all of numRows values, as a Parquet file.
+func writeParquet(w io.Writer, columns []parquetColumn, numRows int) error {
+	var file bytes.Buffer
+	file.Write(parquetMagic)
+
+	type chunk struct {
+		physical int
+		offset   int64
+		size     int64
+	}
+	chunks := make([]chunk, len(columns))
+
+	for i, col := range columns {
+		var page bytes.Buffer
+		physical, n := 0, 0
+		switch v := col.Values.(type) {
+		case []int32:
+			physical, n = parquetInt32, len(v)
+			binary.Write(&page, binary.LittleEndian, v)
+		case []int64:
+			physical, n = parquetInt64, len(v)
+			binary.Write(&page, binary.LittleEndian, v)
+		case []string:
+			physical, n = parquetByteArray, len(v)
+			for _, s := range v {
+				binary.Write(&page, binary.LittleEndian, uint32(len(s)))
+				page.WriteString(s)
+			}
+		default:
+			return fmt.Errorf("parquet: column %s has unsupported type %T", col.Name, col.Values)
+		}
+		if n != numRows {
+			return fmt.Errorf("parquet: column %s has %d values, want %d", col.Name, n, numRows)
+		}
+
+		var header thriftWriter
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(page.Len()))
+		header.i32(3, int32(page.Len()))
+		header.beginStruct(5) // DataPageHeader
+		header.i32(1, int32(numRows))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.stop()
+
+		chunks[i] = chunk{physical: physical, offset: int64(file.Len()), size: int64(header.buf.Len() + page.Len())}
+		file.Write(header.buf.Bytes())
+		file.Write(page.Bytes())
+	}
+
+	var meta thriftWriter
+	meta.i32(1, 1) // Version
+	meta.beginList(2, thriftStruct, len(columns)+1)
+	meta.listStruct() // Root of the schema
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(columns)))
+	meta.endListStruct()
+	for i, col := range columns {
+		meta.listStruct()
+		meta.i32(1, int32(chunks[i].physical))
+		meta.i32(3, parquetRequired)
+		meta.binary(4, col.Name)
+		if chunks[i].physical == parquetByteArray {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.endListStruct()
+	}
+	meta.i64(3, int64(numRows))
+
+	var total int64
+	for _, c := range chunks {
+		total += c.size
+	}
+	meta.beginList(4, thriftStruct, 1)
+	meta.listStruct() // RowGroup
+	meta.beginList(1, thriftStruct, len(columns))
+	for i, col := range columns {
+		meta.listStruct() // ColumnChunk
+		meta.i64(2, chunks[i].offset)
+		meta.beginStruct(3) // ColumnMetaData
+		meta.i32(1, int32(chunks[i].physical))
+		meta.beginList(2, thriftI32, 2)
+		meta.listI32(parquetPlain)
+		meta.listI32(parquetRLE)
+		meta.beginList(3, thriftBinary, 1)
+		meta.listBinary(col.Name)
+		meta.i32(4, 0) // Uncompressed
+		meta.i64(5, int64(numRows))
+		meta.i64(6, chunks[i].size)
+		meta.i64(7, chunks[i].size)
+		meta.i64(9, chunks[i].offset)
+		meta.endStruct()
+		meta.endListStruct()
+	}
+	meta.i64(2, total)
+	meta.i64(3, int64(numRows))
+	meta.endListStruct()
+	meta.binary(6, "gocube")
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.Write(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// Thrift compact protocol type codes.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter writes a Thrift compact protocol struct. Fields must be
+// written in increasing ID order within each struct.
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // Last field ID of each open struct, innermost last
+	id   int16   // Last field ID of the current struct
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.id; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(uint64(zigzag(int64(id))))
+	}
+	t.id = id
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	t.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.listBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.listStruct()
+}
+
+func (t *thriftWriter) endStruct() {
+	t.endListStruct()
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf.WriteByte(byte(n)<<4 | elem)
+	} else {
+		t.buf.WriteByte(0xF0 | elem)
+		t.varint(uint64(n))
+	}
+}
+
+// listStruct starts a struct that is a list element (or, via beginStruct,
+// a field value).
+func (t *thriftWriter) listStruct() {
+	t.last = append(t.last, t.id)
+	t.id = 0
+}
+
+func (t *thriftWriter) endListStruct() {
+	t.stop()
+	t.id = t.last[len(t.last)-1]
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) listBinary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// stop ends the current struct.
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes the Thrift compact protocol structs writeParquet
+// writes. Integers decode to int64, binaries to string, lists to
+// []interface{} and structs to map[int16]interface{} by field ID.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("bad varint at %d", r.pos))
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.varint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.varint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.value(h & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d at %d", typ, r.pos))
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return fields
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(h & 0x0F)
+	}
+}
+
+// parquetFile is what readParquet found in a file.
+type parquetFile struct {
+	meta    map[int16]interface{} // FileMetaData
+	schema  []map[int16]interface{}
+	columns map[string]interface{} // Decoded PLAIN values by column name
+}
+
+// readParquet checks the framing of a file writeParquet wrote and decodes
+// its footer and pages.
+func readParquet(t *testing.T, data []byte) parquetFile {
+	t.Helper()
+	if len(data) < 12 || !bytes.Equal(data[:4], parquetMagic) || !bytes.Equal(data[len(data)-4:], parquetMagic) {
+		t.Fatalf("file of %d bytes lacks the PAR1 magic at both ends", len(data))
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart < 4 {
+		t.Fatalf("footer length %d overruns a %d byte file", footerLen, len(data))
+	}
+	footer := &thriftReader{buf: data[:len(data)-8], pos: footerStart}
+	f := parquetFile{meta: footer.structure(), columns: make(map[string]interface{})}
+	if footer.pos != len(data)-8 {
+		t.Fatalf("footer decoded to %d, want %d", footer.pos, len(data)-8)
+	}
+	for _, e := range f.meta[2].([]interface{}) {
+		f.schema = append(f.schema, e.(map[int16]interface{}))
+	}
+
+	numRows := f.meta[3].(int64)
+	rowGroups := f.meta[4].([]interface{})
+	if len(rowGroups) != 1 {
+		t.Fatalf("%d row groups, want 1", len(rowGroups))
+	}
+	rg := rowGroups[0].(map[int16]interface{})
+	var total int64
+	for _, c := range rg[1].([]interface{}) {
+		cm := c.(map[int16]interface{})[3].(map[int16]interface{})
+		name := cm[3].([]interface{})[0].(string)
+		offset, size := cm[9].(int64), cm[7].(int64)
+		total += size
+		if cm[5].(int64) != numRows {
+			t.Errorf("column %s has %d values, want %d", name, cm[5], numRows)
+		}
+
+		page := &thriftReader{buf: data, pos: int(offset)}
+		header := page.structure()
+		pageLen := int(header[3].(int64))
+		if header[1].(int64) != parquetDataPage || header[2].(int64) != int64(pageLen) {
+			t.Errorf("column %s page header %v, want an uncompressed data page", name, header)
+		}
+		if dp := header[5].(map[int16]interface{}); dp[1].(int64) != numRows || dp[2].(int64) != parquetPlain {
+			t.Errorf("column %s data page header %v, want %d PLAIN values", name, dp, numRows)
+		}
+		if got := int64(page.pos-int(offset)) + int64(pageLen); got != size {
+			t.Errorf("column %s chunk is %d bytes, metadata says %d", name, got, size)
+		}
+		values := bytes.NewReader(data[page.pos : page.pos+pageLen])
+		switch cm[1].(int64) {
+		case parquetInt32:
+			v := make([]int32, numRows)
+			binary.Read(values, binary.LittleEndian, v)
+			f.columns[name] = v
+		case parquetInt64:
+			v := make([]int64, numRows)
+			binary.Read(values, binary.LittleEndian, v)
+			f.columns[name] = v
+		case parquetByteArray:
+			v := make([]string, numRows)
+			for i := range v {
+				var n uint32
+				binary.Read(values, binary.LittleEndian, &n)
+				s := make([]byte, n)
+				values.Read(s)
+				v[i] = string(s)
+			}
+			f.columns[name] = v
+		}
+		if values.Len() != 0 {
+			t.Errorf("column %s page has %d bytes left over", name, values.Len())
+		}
+	}
+	if rg[2].(int64) != total || rg[3].(int64) != numRows {
+		t.Errorf("row group of %d bytes and %d rows, want %d and %d", rg[2], rg[3], total, numRows)
+	}
+	return f
+}
+
+func TestWriteParquet(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		columns []parquetColumn
+		rows    int
+	}{
+		{"rows", []parquetColumn{
+			{Name: "id", Values: []string{"a", "", "ünï"}},
+			{Name: "n", Values: []int32{1, -2, 1 << 30}},
+			{Name: "t", Values: []int64{0, -1, 1 << 40}},
+		}, 3},
+		{"empty", []parquetColumn{
+			{Name: "id", Values: []string{}},
+			{Name: "n", Values: []int32{}},
+			{Name: "t", Values: []int64{}},
+		}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeParquet(&buf, tt.columns, tt.rows); err != nil {
+				t.Fatalf("writeParquet: %v", err)
+			}
+			f := readParquet(t, buf.Bytes())
+
+			if f.meta[1].(int64) != 1 || f.meta[3].(int64) != int64(tt.rows) {
+				t.Errorf("version %v with %v rows, want 1 with %d", f.meta[1], f.meta[3], tt.rows)
+			}
+			if len(f.schema) != len(tt.columns)+1 || f.schema[0][4] != "schema" || f.schema[0][5].(int64) != int64(len(tt.columns)) {
+				t.Fatalf("schema = %v, want a root with %d children", f.schema, len(tt.columns))
+			}
+			want := []struct {
+				physical  int64
+				converted interface{}
+			}{{parquetByteArray, int64(parquetUTF8)}, {parquetInt32, nil}, {parquetInt64, nil}}
+			for i, col := range tt.columns {
+				e := f.schema[i+1]
+				if e[4] != col.Name || e[1].(int64) != want[i].physical || e[3].(int64) != parquetRequired || e[6] != want[i].converted {
+					t.Errorf("schema element %d = %v, want required %s of type %d", i+1, e, col.Name, want[i].physical)
+				}
+				if !reflect.DeepEqual(f.columns[col.Name], col.Values) {
+					t.Errorf("column %s read back %v, want %v", col.Name, f.columns[col.Name], col.Values)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteParquetErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, []parquetColumn{{Name: "n", Values: []int32{1, 2}}}, 3); err == nil {
+		t.Error("short column accepted")
+	}
+	if err := writeParquet(&buf, []parquetColumn{{Name: "f", Values: []float64{1}}}, 1); err == nil {
+		t.Error("float64 column accepted")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes written on error", buf.Len())
+	}
+}
+
+func TestWriteMovesParquet(t *testing.T) {
+	rows := testMoveRows()
+	var buf bytes.Buffer
+	if err := WriteMovesParquet(&buf, rows); err != nil {
+		t.Fatalf("WriteMovesParquet: %v", err)
+	}
+	f := readParquet(t, buf.Bytes())
+
+	var names []string
+	for _, e := range f.schema[1:] {
+		names = append(names, e[4].(string))
+	}
+	if !reflect.DeepEqual(names, moveRowColumns) {
+		t.Fatalf("columns %v, want %v", names, moveRowColumns)
+	}
+	for i, r := range rows {
+		got := MoveRow{
+			SolveID:   f.columns["solve_id"].([]string)[i],
+			MoveIndex: int(f.columns["move_index"].([]int32)[i]),
+			TsMs:      f.columns["ts_ms"].([]int64)[i],
+			Face:      f.columns["face"].([]string)[i],
+			Turn:      int(f.columns["turn"].([]int32)[i]),
+			Notation:  f.columns["notation"].([]string)[i],
+			PhaseKey:  f.columns["phase_key"].([]string)[i],
+			GapMs:     f.columns["gap_ms"].([]int64)[i],
+		}
+		if got != r {
+			t.Errorf("row %d read back %+v, want %+v", i, got, r)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PersonalBest is a new best set by a solve.
+type PersonalBest struct {
+	Kind       string   // "single", "ao5", "ao12" or "phase"
+	PhaseKey   string   // For "phase"; "" otherwise
+	ValueMs    float64  // The new best
+	PreviousMs *float64 // The best it beat; nil for the first
+	SolveID    string   // Solve that set it; the last of an average
+	AchievedAt time.Time
+}
+
+// PersonalBestRepository provides operations for the personal best history.
+type PersonalBestRepository struct {
+	db *DB
+}
+
+// NewPersonalBestRepository creates a new personal best repository.
+func NewPersonalBestRepository(db *DB) *PersonalBestRepository {
+	return &PersonalBestRepository{db: db}
+}
+
+// Record stores a personal best. It returns false if the solve already
+// holds a best of that kind, which keeps the one stored first.
+func (r *PersonalBestRepository) Record(pb PersonalBest) (bool, error) {
+	var previous sql.NullFloat64
+	if pb.PreviousMs != nil {
+		previous = sql.NullFloat64{Float64: *pb.PreviousMs, Valid: true}
+	}
+	res, err := r.db.Exec(`
+		INSERT OR IGNORE INTO personal_bests (kind, phase_key, value_ms, previous_ms, solve_id, achieved_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, pb.Kind, pb.PhaseKey, pb.ValueMs, previous, pb.SolveID, pb.AchievedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, fmt.Errorf("failed to store personal best: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to store personal best: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Clear deletes the whole personal best history and returns how many
+// bests it held.
+func (r *PersonalBestRepository) Clear() (int, error) {
+	res, err := r.db.Exec("DELETE FROM personal_bests")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear personal bests: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// Count returns the number of personal bests stored.
+func (r *PersonalBestRepository) Count() (int, error) {
+	var n int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM personal_bests").Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count personal bests: %w", err)
+	}
+	return n, nil
+}
+
+// List returns the personal best history, oldest first.
+func (r *PersonalBestRepository) List() ([]PersonalBest, error) {
+	rows, err := r.db.Query(`
+		SELECT kind, phase_key, value_ms, previous_ms, solve_id, achieved_at
+		FROM personal_bests
+		ORDER BY achieved_at, kind, phase_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list personal bests: %w", err)
+	}
+	defer rows.Close()
+
+	var pbs []PersonalBest
+	for rows.Next() {
+		var pb PersonalBest
+		var previous sql.NullFloat64
+		var achievedAt string
+		if err := rows.Scan(&pb.Kind, &pb.PhaseKey, &pb.ValueMs, &previous, &pb.SolveID, &achievedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan personal best: %w", err)
+		}
+		if previous.Valid {
+			pb.PreviousMs = &previous.Float64
+		}
+		pb.AchievedAt, _ = time.Parse(time.RFC3339, achievedAt)
+		pbs = append(pbs, pb)
+	}
+	return pbs, rows.Err()
+}
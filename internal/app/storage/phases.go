@@ -1,7 +1,9 @@
 package storage
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 )
 
 // PhaseDef represents a phase definition.
@@ -11,6 +13,7 @@ type PhaseDef struct {
 	OrderIndex  int
 	Description *string
 	IsActive    bool
+	Scheme      string // Phase scheme the phase belongs to; "lbl" for the built-in ones
 }
 
 // PhaseMark represents a phase mark during a solve.
@@ -48,7 +51,7 @@ func NewPhaseRepository(db *DB) *PhaseRepository {
 // GetAllPhaseDefs retrieves all active phase definitions in order.
 func (r *PhaseRepository) GetAllPhaseDefs() ([]PhaseDef, error) {
 	rows, err := r.db.Query(`
-		SELECT phase_key, display_name, order_index, description, is_active
+		SELECT phase_key, display_name, order_index, description, is_active, scheme
 		FROM phase_defs
 		WHERE is_active = 1
 		ORDER BY order_index
@@ -63,7 +66,7 @@ func (r *PhaseRepository) GetAllPhaseDefs() ([]PhaseDef, error) {
 	for rows.Next() {
 		var d PhaseDef
 		var isActive int
-		err := rows.Scan(&d.PhaseKey, &d.DisplayName, &d.OrderIndex, &d.Description, &isActive)
+		err := rows.Scan(&d.PhaseKey, &d.DisplayName, &d.OrderIndex, &d.Description, &isActive, &d.Scheme)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan phase def: %w", err)
 		}
@@ -79,10 +82,10 @@ func (r *PhaseRepository) GetPhaseDef(phaseKey string) (*PhaseDef, error) {
 	var d PhaseDef
 	var isActive int
 	err := r.db.QueryRow(`
-		SELECT phase_key, display_name, order_index, description, is_active
+		SELECT phase_key, display_name, order_index, description, is_active, scheme
 		FROM phase_defs
 		WHERE phase_key = ?
-	`, phaseKey).Scan(&d.PhaseKey, &d.DisplayName, &d.OrderIndex, &d.Description, &isActive)
+	`, phaseKey).Scan(&d.PhaseKey, &d.DisplayName, &d.OrderIndex, &d.Description, &isActive, &d.Scheme)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get phase def: %w", err)
@@ -92,6 +95,30 @@ func (r *PhaseRepository) GetPhaseDef(phaseKey string) (*PhaseDef, error) {
 	return &d, nil
 }
 
+// RegisterPhaseDefs adds the phases of a phase scheme, or updates their
+// names and order if the scheme registered them before. Keys already
+// defined by another scheme keep that scheme's definition.
+func (r *PhaseRepository) RegisterPhaseDefs(scheme string, defs []PhaseDef) error {
+	return r.db.Transaction(func(tx *sql.Tx) error {
+		for _, d := range defs {
+			_, err := tx.Exec(`
+				INSERT INTO phase_defs (phase_key, display_name, order_index, description, scheme)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT(phase_key) DO UPDATE SET
+					display_name = excluded.display_name,
+					order_index = excluded.order_index,
+					description = excluded.description,
+					is_active = 1
+				WHERE phase_defs.scheme = excluded.scheme
+			`, d.PhaseKey, d.DisplayName, d.OrderIndex, d.Description, scheme)
+			if err != nil {
+				return fmt.Errorf("failed to register phase %s: %w", d.PhaseKey, err)
+			}
+		}
+		return nil
+	})
+}
+
 // CreatePhaseMark creates a new phase mark.
 func (r *PhaseRepository) CreatePhaseMark(solveID string, tsMs int64, phaseKey string, notes *string) (int64, error) {
 	result, err := r.db.Exec(`
@@ -184,6 +211,31 @@ func (r *PhaseRepository) GetPhaseSegments(solveID string) ([]PhaseSegment, erro
 	return segments, nil
 }
 
+// AllPhaseSegments retrieves the phase segments of every solve, keyed by
+// solve ID.
+func (r *PhaseRepository) AllPhaseSegments() (map[string][]PhaseSegment, error) {
+	rows, err := r.db.Query(`
+		SELECT segment_id, solve_id, phase_key, start_ts_ms, end_ts_ms, duration_ms, move_count, tps
+		FROM derived_phase_segments
+		ORDER BY solve_id, start_ts_ms
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phase segments: %w", err)
+	}
+	defer rows.Close()
+
+	segments := make(map[string][]PhaseSegment)
+	for rows.Next() {
+		var s PhaseSegment
+		err := rows.Scan(&s.SegmentID, &s.SolveID, &s.PhaseKey, &s.StartTsMs, &s.EndTsMs, &s.DurationMs, &s.MoveCount, &s.TPS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan segment: %w", err)
+		}
+		segments[s.SolveID] = append(segments[s.SolveID], s)
+	}
+	return segments, rows.Err()
+}
+
 // DeletePhaseSegments deletes all phase segments for a solve.
 func (r *PhaseRepository) DeletePhaseSegments(solveID string) error {
 	_, err := r.db.Exec("DELETE FROM derived_phase_segments WHERE solve_id = ?", solveID)
@@ -253,7 +305,22 @@ func AlgoKeyToPhaseKey(key string) string {
 	}
 }
 
-// PhaseDisplayName returns a short display name for a phase key.
+// IsSolvingPhase reports whether the segment of phaseKey counts towards
+// the solve time: every phase but scramble and inspection.
+func IsSolvingPhase(phaseKey string) bool {
+	return phaseKey != "scramble" && phaseKey != "inspection"
+}
+
+// IsTimedPhase reports whether phaseKey times a solving phase. The
+// complete mark only marks where the solve ended, so its segment is not
+// a phase.
+func IsTimedPhase(phaseKey string) bool {
+	return IsSolvingPhase(phaseKey) && phaseKey != "complete"
+}
+
+// PhaseDisplayName returns a short display name for a phase key. Keys of
+// phase schemes, which are not known here, are title-cased ("first_block"
+// is "First Block").
 func PhaseDisplayName(phaseKey string) string {
 	switch phaseKey {
 	case "scramble":
@@ -279,6 +346,12 @@ func PhaseDisplayName(phaseKey string) string {
 	case "complete":
 		return "Complete"
 	default:
-		return phaseKey
+		words := strings.Split(phaseKey, "_")
+		for i, w := range words {
+			if w != "" {
+				words[i] = strings.ToUpper(w[:1]) + w[1:]
+			}
+		}
+		return strings.Join(words, " ")
 	}
 }
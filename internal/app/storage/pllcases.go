@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PLLCaseRepository provides operations for the PLL case met in each solve.
+type PLLCaseRepository struct {
+	db *DB
+}
+
+// NewPLLCaseRepository creates a new PLL case repository.
+func NewPLLCaseRepository(db *DB) *PLLCaseRepository {
+	return &PLLCaseRepository{db: db}
+}
+
+// Set stores the PLL case of a solve, replacing any earlier one.
+func (r *PLLCaseRepository) Set(solveID, caseName string) error {
+	_, err := r.db.Exec(`
+		INSERT OR REPLACE INTO pll_cases (solve_id, case_name)
+		VALUES (?, ?)
+	`, solveID, caseName)
+	if err != nil {
+		return fmt.Errorf("failed to store PLL case: %w", err)
+	}
+	return nil
+}
+
+// Get returns the PLL case of a solve, or "" if none was recognized.
+func (r *PLLCaseRepository) Get(solveID string) (string, error) {
+	var name string
+	err := r.db.QueryRow("SELECT case_name FROM pll_cases WHERE solve_id = ?", solveID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get PLL case: %w", err)
+	}
+	return name, nil
+}
+
+// DeleteBySolve deletes the PLL case of a solve.
+func (r *PLLCaseRepository) DeleteBySolve(solveID string) error {
+	_, err := r.db.Exec("DELETE FROM pll_cases WHERE solve_id = ?", solveID)
+	if err != nil {
+		return fmt.Errorf("failed to delete PLL case: %w", err)
+	}
+	return nil
+}
+
+// All returns the PLL case of every solve that has one, keyed by solve ID.
+func (r *PLLCaseRepository) All() (map[string]string, error) {
+	rows, err := r.db.Query("SELECT solve_id, case_name FROM pll_cases")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PLL cases: %w", err)
+	}
+	defer rows.Close()
+
+	cases := make(map[string]string)
+	for rows.Next() {
+		var solveID, name string
+		if err := rows.Scan(&solveID, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan PLL case: %w", err)
+		}
+		cases[solveID] = name
+	}
+	return cases, rows.Err()
+}
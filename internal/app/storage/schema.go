@@ -18,6 +18,63 @@ var migration003 string
 //go:embed migrations/004_orientations.sql
 var migration004 string
 
+//go:embed migrations/005_manual_solves.sql
+var migration005 string
+
+//go:embed migrations/006_solve_context.sql
+var migration006 string
+
+//go:embed migrations/007_device_counters.sql
+var migration007 string
+
+//go:embed migrations/008_microsecond_timestamps.sql
+var migration008 string
+
+//go:embed migrations/009_checkpoints.sql
+var migration009 string
+
+//go:embed migrations/010_device_calibrations.sql
+var migration010 string
+
+//go:embed migrations/011_tool_detections.sql
+var migration011 string
+
+//go:embed migrations/012_utc_timestamps.sql
+var migration012 string
+
+//go:embed migrations/013_achievements.sql
+var migration013 string
+
+//go:embed migrations/014_cube_type.sql
+var migration014 string
+
+//go:embed migrations/015_move_batches.sql
+var migration015 string
+
+//go:embed migrations/016_sessions.sql
+var migration016 string
+
+//go:embed migrations/017_personal_bests.sql
+var migration017 string
+
+//go:embed migrations/018_offline_stats.sql
+var migration018 string
+
+//go:embed migrations/019_phase_schemes.sql
+var migration019 string
+
+//go:embed migrations/020_move_time_source.sql
+var migration020 string
+
+//go:embed migrations/021_video_anchors.sql
+var migration021 string
+
+//go:embed migrations/022_scramble_verified.sql
+var migration022 string
+
+//go:embed migrations/023_api_tokens.sql
+var migration023 string
+
 // migrations is an ordered list of migration SQL statements.
 var migrations = []struct {
 	version int
@@ -27,6 +84,25 @@ var migrations = []struct {
 	{2, migration002},
 	{3, migration003},
 	{4, migration004},
+	{5, migration005},
+	{6, migration006},
+	{7, migration007},
+	{8, migration008},
+	{9, migration009},
+	{10, migration010},
+	{11, migration011},
+	{12, migration012},
+	{13, migration013},
+	{14, migration014},
+	{15, migration015},
+	{16, migration016},
+	{17, migration017},
+	{18, migration018},
+	{19, migration019},
+	{20, migration020},
+	{21, migration021},
+	{22, migration022},
+	{23, migration023},
 }
 
 // applyMigrations applies all pending migrations.
@@ -57,8 +133,7 @@ func applyMigrations(db *sql.DB) error {
 			continue
 		}
 
-		_, err := db.Exec(m.sql)
-		if err != nil {
+		if err := applyMigration(db, m.sql); err != nil {
 			return fmt.Errorf("failed to apply migration %d: %w", m.version, err)
 		}
 	}
@@ -66,6 +141,21 @@ func applyMigrations(db *sql.DB) error {
 	return nil
 }
 
+// applyMigration runs one migration in a transaction, so a migration that
+// fails or is interrupted leaves the schema at the previous version rather
+// than half applied.
+func applyMigration(db *sql.DB, migration string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(migration); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
 // InitSchema initializes the database schema (alias for MigrateUp).
 func InitSchema(db *sql.DB) error {
 	return applyMigrations(db)
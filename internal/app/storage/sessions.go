@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionOpen is returned when starting a session while another is open.
+var ErrSessionOpen = errors.New("a session is already open")
+
+// Session is a practice session: a span of time grouping the solves started
+// in it.
+type Session struct {
+	SessionID string
+	Name      *string
+	StartedAt time.Time
+	EndedAt   *time.Time // nil while open
+}
+
+// IsOpen returns true if the session has not ended.
+func (s *Session) IsOpen() bool {
+	return s.EndedAt == nil
+}
+
+// End returns when the session ended, or now while it is open.
+func (s *Session) End(now time.Time) time.Time {
+	if s.EndedAt != nil {
+		return *s.EndedAt
+	}
+	return now
+}
+
+// SessionRepository provides operations for practice sessions.
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new session repository.
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Start opens a new session at startedAt. It fails with ErrSessionOpen if a
+// session is already open.
+func (r *SessionRepository) Start(name string, startedAt time.Time) (*Session, error) {
+	open, err := r.GetOpen()
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, ErrSessionOpen
+	}
+
+	s := &Session{SessionID: uuid.New().String(), StartedAt: startedAt.UTC().Truncate(time.Second)}
+	if name != "" {
+		s.Name = &name
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO sessions (session_id, name, started_at)
+		VALUES (?, ?, ?)
+	`, s.SessionID, s.Name, s.StartedAt.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return s, nil
+}
+
+// End closes the open session at endedAt and returns it, or nil if no
+// session is open.
+func (r *SessionRepository) End(endedAt time.Time) (*Session, error) {
+	s, err := r.GetOpen()
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	// Solves are matched by start second, so the end rounds up to keep a
+	// solve started in the last second
+	end := endedAt.UTC().Truncate(time.Second).Add(time.Second)
+	_, err = r.db.Exec(`
+		UPDATE sessions SET ended_at = ? WHERE session_id = ?
+	`, end.Format(time.RFC3339), s.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to end session: %w", err)
+	}
+	s.EndedAt = &end
+	return s, nil
+}
+
+// Get retrieves a session by ID, or nil if there is none.
+func (r *SessionRepository) Get(sessionID string) (*Session, error) {
+	return r.getOne(`
+		SELECT session_id, name, started_at, ended_at FROM sessions
+		WHERE session_id = ?
+	`, sessionID)
+}
+
+// GetOpen retrieves the open session, or nil if none is open.
+func (r *SessionRepository) GetOpen() (*Session, error) {
+	return r.getOne(`
+		SELECT session_id, name, started_at, ended_at FROM sessions
+		WHERE ended_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`)
+}
+
+// GetLast retrieves the most recently started session, or nil if there are
+// none.
+func (r *SessionRepository) GetLast() (*Session, error) {
+	return r.getOne(`
+		SELECT session_id, name, started_at, ended_at FROM sessions
+		ORDER BY started_at DESC
+		LIMIT 1
+	`)
+}
+
+// List retrieves the most recent sessions, newest first.
+func (r *SessionRepository) List(limit int) ([]Session, error) {
+	rows, err := r.db.Query(`
+		SELECT session_id, name, started_at, ended_at FROM sessions
+		ORDER BY started_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *SessionRepository) getOne(query string, args ...interface{}) (*Session, error) {
+	s, err := scanSession(r.db.QueryRow(query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+// scanSession scans a session_id, name, started_at, ended_at row.
+func scanSession(row interface{ Scan(...interface{}) error }) (*Session, error) {
+	var s Session
+	var startedAt string
+	var endedAt sql.NullString
+	if err := row.Scan(&s.SessionID, &s.Name, &startedAt, &endedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan session: %w", err)
+	}
+	s.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+	if endedAt.Valid {
+		t, _ := time.Parse(time.RFC3339, endedAt.String)
+		s.EndedAt = &t
+	}
+	return &s, nil
+}
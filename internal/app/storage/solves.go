@@ -19,6 +19,23 @@ type Solve struct {
 	DeviceName  *string
 	DeviceID    *string
 	AppVersion  *string
+	Source      string  // SourceSmart or SourceManual
+	CubeType    *string // Cube model reported by the device: "standard" or "edge"
+
+	// ScrambleVerified is set when the moves made before the solve matched
+	// its scramble exactly
+	ScrambleVerified bool
+}
+
+// Solve sources.
+const (
+	SourceSmart  = "smart"  // Recorded from a smart cube move stream
+	SourceManual = "manual" // Timed manually with no move stream
+)
+
+// IsManual returns true if the solve was timed manually.
+func (s *Solve) IsManual() bool {
+	return s.Source == SourceManual
 }
 
 // SolveRepository provides CRUD operations for solves.
@@ -33,8 +50,13 @@ func NewSolveRepository(db *DB) *SolveRepository {
 
 // Create creates a new solve and returns its ID.
 func (r *SolveRepository) Create(notes, scramble, deviceName, deviceID, appVersion string) (string, error) {
+	return r.CreateAt(time.Now(), notes, scramble, deviceName, deviceID, appVersion)
+}
+
+// CreateAt creates a new solve that started at startedAt and returns its ID.
+func (r *SolveRepository) CreateAt(startedAt time.Time, notes, scramble, deviceName, deviceID, appVersion string) (string, error) {
 	id := uuid.New().String()
-	startedAt := time.Now().UTC()
+	startedAt = startedAt.UTC()
 
 	var notesPtr, scramblePtr, deviceNamePtr, deviceIDPtr, appVersionPtr *string
 	if notes != "" {
@@ -65,9 +87,49 @@ func (r *SolveRepository) Create(notes, scramble, deviceName, deviceID, appVersi
 	return id, nil
 }
 
+// CreateManual stores a manually timed solve that ended at endedAt and
+// returns its ID. Manual solves have no moves, events, or phase marks.
+func (r *SolveRepository) CreateManual(notes, scramble, appVersion string, durationMs int64, endedAt time.Time) (string, error) {
+	if durationMs <= 0 {
+		return "", fmt.Errorf("manual solve duration must be positive")
+	}
+
+	id := uuid.New().String()
+	endedAt = endedAt.UTC()
+	startedAt := endedAt.Add(-time.Duration(durationMs) * time.Millisecond)
+
+	var notesPtr, scramblePtr, appVersionPtr *string
+	if notes != "" {
+		notesPtr = &notes
+	}
+	if scramble != "" {
+		scramblePtr = &scramble
+	}
+	if appVersion != "" {
+		appVersionPtr = &appVersion
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO solves (solve_id, started_at, ended_at, duration_ms, notes, scramble_text, app_version, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, startedAt.Format(time.RFC3339), endedAt.Format(time.RFC3339), durationMs,
+		notesPtr, scramblePtr, appVersionPtr, SourceManual)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to create manual solve: %w", err)
+	}
+
+	return id, nil
+}
+
 // End marks a solve as complete.
 func (r *SolveRepository) End(solveID string) error {
-	endedAt := time.Now().UTC()
+	return r.EndAt(solveID, time.Now())
+}
+
+// EndAt marks a solve as complete at the given time.
+func (r *SolveRepository) EndAt(solveID string, endedAt time.Time) error {
+	endedAt = endedAt.UTC()
 
 	// Get start time to calculate duration
 	var startedAtStr string
@@ -103,13 +165,13 @@ func (r *SolveRepository) Get(solveID string) (*Solve, error) {
 	var endedAtStr sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT solve_id, started_at, ended_at, duration_ms, scramble_text, notes, device_name, device_id, app_version
+		SELECT solve_id, started_at, ended_at, duration_ms, scramble_text, notes, device_name, device_id, app_version, source, cube_type, scramble_verified
 		FROM solves
 		WHERE solve_id = ?
 	`, solveID).Scan(
 		&s.SolveID, &startedAtStr, &endedAtStr,
 		&s.DurationMs, &s.ScrambleText, &s.Notes,
-		&s.DeviceName, &s.DeviceID, &s.AppVersion,
+		&s.DeviceName, &s.DeviceID, &s.AppVersion, &s.Source, &s.CubeType, &s.ScrambleVerified,
 	)
 
 	if err == sql.ErrNoRows {
@@ -150,7 +212,7 @@ func (r *SolveRepository) GetLast() (*Solve, error) {
 // List retrieves recent solves.
 func (r *SolveRepository) List(limit int) ([]Solve, error) {
 	rows, err := r.db.Query(`
-		SELECT solve_id, started_at, ended_at, duration_ms, scramble_text, notes, device_name, device_id, app_version
+		SELECT solve_id, started_at, ended_at, duration_ms, scramble_text, notes, device_name, device_id, app_version, source, cube_type, scramble_verified
 		FROM solves
 		ORDER BY started_at DESC
 		LIMIT ?
@@ -170,7 +232,7 @@ func (r *SolveRepository) List(limit int) ([]Solve, error) {
 		err := rows.Scan(
 			&s.SolveID, &startedAtStr, &endedAtStr,
 			&s.DurationMs, &s.ScrambleText, &s.Notes,
-			&s.DeviceName, &s.DeviceID, &s.AppVersion,
+			&s.DeviceName, &s.DeviceID, &s.AppVersion, &s.Source, &s.CubeType, &s.ScrambleVerified,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan solve: %w", err)
@@ -188,6 +250,83 @@ func (r *SolveRepository) List(limit int) ([]Solve, error) {
 	return solves, nil
 }
 
+// ListBetween retrieves solves started in [start, end), oldest first.
+func (r *SolveRepository) ListBetween(start, end time.Time) ([]Solve, error) {
+	rows, err := r.db.Query(`
+		SELECT solve_id, started_at, ended_at, duration_ms, scramble_text, notes, device_name, device_id, app_version, source, cube_type, scramble_verified
+		FROM solves
+		WHERE started_at >= ? AND started_at < ?
+		ORDER BY started_at ASC
+	`, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list solves: %w", err)
+	}
+	defer rows.Close()
+
+	var solves []Solve
+	for rows.Next() {
+		var s Solve
+		var startedAtStr string
+		var endedAtStr sql.NullString
+
+		err := rows.Scan(
+			&s.SolveID, &startedAtStr, &endedAtStr,
+			&s.DurationMs, &s.ScrambleText, &s.Notes,
+			&s.DeviceName, &s.DeviceID, &s.AppVersion, &s.Source, &s.CubeType, &s.ScrambleVerified,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan solve: %w", err)
+		}
+
+		s.StartedAt, _ = time.Parse(time.RFC3339, startedAtStr)
+		if endedAtStr.Valid {
+			t, _ := time.Parse(time.RFC3339, endedAtStr.String)
+			s.EndedAt = &t
+		}
+
+		solves = append(solves, s)
+	}
+
+	return solves, nil
+}
+
+// SetCubeType records the cube model the solve was recorded on.
+func (r *SolveRepository) SetCubeType(solveID, cubeType string) error {
+	_, err := r.db.Exec("UPDATE solves SET cube_type = ? WHERE solve_id = ?", cubeType, solveID)
+	if err != nil {
+		return fmt.Errorf("failed to set cube type: %w", err)
+	}
+
+	return nil
+}
+
+// SetScrambleVerified records that the moves made before the solve matched
+// its scramble.
+func (r *SolveRepository) SetScrambleVerified(solveID string) error {
+	_, err := r.db.Exec("UPDATE solves SET scramble_verified = 1 WHERE solve_id = ?", solveID)
+	if err != nil {
+		return fmt.Errorf("failed to set scramble verified: %w", err)
+	}
+
+	return nil
+}
+
+// AppendNotes appends a line to a solve's notes.
+func (r *SolveRepository) AppendNotes(solveID, note string) error {
+	_, err := r.db.Exec(`
+		UPDATE solves
+		SET notes = CASE WHEN notes IS NULL OR notes = '' THEN ? ELSE notes || char(10) || ? END
+		WHERE solve_id = ?
+	`, note, note, solveID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update notes: %w", err)
+	}
+
+	return nil
+}
+
 // Delete deletes a solve and all related data (cascading).
 func (r *SolveRepository) Delete(solveID string) error {
 	_, err := r.db.Exec("DELETE FROM solves WHERE solve_id = ?", solveID)
@@ -197,6 +336,45 @@ func (r *SolveRepository) Delete(solveID string) error {
 	return nil
 }
 
+// solveTables are the tables whose rows for a solve are deleted with it.
+var solveTables = []string{
+	"moves",
+	"events",
+	"phase_marks",
+	"derived_phase_segments",
+	"orientations",
+	"solve_context",
+	"checkpoints",
+	"tool_detections",
+	"pll_cases",
+	"personal_bests",
+	"analysis_cache",
+}
+
+// TableRows is the number of rows a table holds for a solve.
+type TableRows struct {
+	Table string
+	Rows  int
+}
+
+// CountRelated returns how many rows each table holds for a solve, i.e.
+// what Delete would remove besides the solve itself. Tables without rows
+// for the solve are omitted.
+func (r *SolveRepository) CountRelated(solveID string) ([]TableRows, error) {
+	var counts []TableRows
+	for _, table := range solveTables {
+		var n int
+		err := r.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE solve_id = ?", solveID).Scan(&n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		if n > 0 {
+			counts = append(counts, TableRows{Table: table, Rows: n})
+		}
+	}
+	return counts, nil
+}
+
 // GetMoveCount returns the number of moves in a solve.
 func (r *SolveRepository) GetMoveCount(solveID string) (int, error) {
 	var count int
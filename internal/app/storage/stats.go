@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// StatsRepository provides aggregate queries across tables.
+type StatsRepository struct {
+	db *DB
+}
+
+// NewStatsRepository creates a new stats repository.
+func NewStatsRepository(db *DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// SolveCounts returns the total number of solves and the number that ended.
+func (r *StatsRepository) SolveCounts() (total, completed int, err error) {
+	err = r.db.QueryRow(`
+		SELECT COUNT(*), COUNT(ended_at)
+		FROM solves
+	`).Scan(&total, &completed)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count solves: %w", err)
+	}
+	return total, completed, nil
+}
+
+// EmptySolveCount returns the number of ended solves with no moves.
+func (r *StatsRepository) EmptySolveCount() (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*)
+		FROM solves s
+		WHERE s.ended_at IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM moves m WHERE m.solve_id = s.solve_id)
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count empty solves: %w", err)
+	}
+	return count, nil
+}
+
+// MoveCount returns the total number of recorded moves.
+func (r *StatsRepository) MoveCount() (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM moves").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count moves: %w", err)
+	}
+	return count, nil
+}
+
+// PhaseMarkCoverage returns, per phase key, the number of ended solves
+// that contain at least one mark for that phase.
+func (r *StatsRepository) PhaseMarkCoverage() (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT pm.phase_key, COUNT(DISTINCT pm.solve_id)
+		FROM phase_marks pm
+		JOIN solves s ON s.solve_id = pm.solve_id
+		WHERE s.ended_at IS NOT NULL
+		GROUP BY pm.phase_key
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phase mark coverage: %w", err)
+	}
+	defer rows.Close()
+
+	coverage := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan phase mark coverage: %w", err)
+		}
+		coverage[key] = count
+	}
+
+	return coverage, nil
+}
+
+// EventTypeCounts returns the number of stored events per event type.
+func (r *StatsRepository) EventTypeCounts() (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT event_type, COUNT(*)
+		FROM events
+		GROUP BY event_type
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count events: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var eventType string
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan event count: %w", err)
+		}
+		counts[eventType] = count
+	}
+
+	return counts, nil
+}
+
+// TableSize is the storage one table uses.
+type TableSize struct {
+	Name  string
+	Rows  int
+	Bytes int64 // Pages of the table and its indexes
+}
+
+// TableSizes returns the row count and size of every table, largest first.
+func (r *StatsRepository) TableSizes() ([]TableSize, error) {
+	rows, err := r.db.Query(`
+		SELECT m.tbl_name, SUM(s.pgsize)
+		FROM dbstat s
+		JOIN sqlite_master m ON m.name = s.name
+		WHERE m.tbl_name NOT LIKE 'sqlite_%'
+		GROUP BY m.tbl_name
+		ORDER BY SUM(s.pgsize) DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table sizes: %w", err)
+	}
+	var sizes []TableSize
+	for rows.Next() {
+		var ts TableSize
+		if err := rows.Scan(&ts.Name, &ts.Bytes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		sizes = append(sizes, ts)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get table sizes: %w", err)
+	}
+
+	for i := range sizes {
+		// Names come from sqlite_master, not user input.
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, sizes[i].Name)
+		if err := r.db.QueryRow(query).Scan(&sizes[i].Rows); err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", sizes[i].Name, err)
+		}
+	}
+	return sizes, nil
+}
+
+// FreeBytes returns the size of the database file's unused pages, which
+// VACUUM would give back.
+func (r *StatsRepository) FreeBytes() (int64, error) {
+	var pageSize, freePages int64
+	if err := r.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("failed to get page size: %w", err)
+	}
+	if err := r.db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return 0, fmt.Errorf("failed to get free pages: %w", err)
+	}
+	return pageSize * freePages, nil
+}
+
+// DailyRowCounts returns, for solves and every table that belongs to a
+// solve, the rows added per UTC day (YYYY-MM-DD) the solves started on.
+// Rows are not timestamped themselves, so a solve's start dates them.
+func (r *StatsRepository) DailyRowCounts() (map[string]map[string]int, error) {
+	tables, err := r.solveTables()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, table := range append([]string{"solves"}, tables...) {
+		query := `SELECT substr(started_at, 1, 10), COUNT(*) FROM solves GROUP BY 1`
+		if table != "solves" {
+			query = fmt.Sprintf(`
+				SELECT substr(s.started_at, 1, 10), COUNT(*)
+				FROM "%s" t
+				JOIN solves s ON s.solve_id = t.solve_id
+				GROUP BY 1
+			`, table)
+		}
+		rows, err := r.db.Query(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s by day: %w", table, err)
+		}
+		days := make(map[string]int)
+		for rows.Next() {
+			var day string
+			var count int
+			if err := rows.Scan(&day, &count); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s day count: %w", table, err)
+			}
+			days[day] = count
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to count %s by day: %w", table, err)
+		}
+		counts[table] = days
+	}
+	return counts, nil
+}
+
+// solveTables returns the tables other than solves with a solve_id column.
+func (r *StatsRepository) solveTables() ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT m.name
+		FROM sqlite_master m
+		JOIN pragma_table_info(m.name) c ON c.name = 'solve_id'
+		WHERE m.type = 'table' AND m.name != 'solves'
+		ORDER BY m.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list solve tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
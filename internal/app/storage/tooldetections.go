@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ToolDetectionRepository provides operations for per-solve algorithm
+// detections.
+type ToolDetectionRepository struct {
+	db *DB
+}
+
+// NewToolDetectionRepository creates a new tool detection repository.
+func NewToolDetectionRepository(db *DB) *ToolDetectionRepository {
+	return &ToolDetectionRepository{db: db}
+}
+
+// Replace stores the detections of a solve, replacing any earlier ones.
+// counts maps tool name to the number of times it was detected.
+func (r *ToolDetectionRepository) Replace(solveID string, counts map[string]int) error {
+	return r.db.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM tool_detections WHERE solve_id = ?", solveID); err != nil {
+			return fmt.Errorf("failed to clear tool detections: %w", err)
+		}
+		for name, count := range counts {
+			if count <= 0 {
+				continue
+			}
+			_, err := tx.Exec(`
+				INSERT INTO tool_detections (solve_id, tool_name, count)
+				VALUES (?, ?, ?)
+			`, solveID, name, count)
+			if err != nil {
+				return fmt.Errorf("failed to store tool detection: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// SolvesByTool returns, for each tool, the number of solves it was
+// detected in.
+func (r *ToolDetectionRepository) SolvesByTool() (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT tool_name, COUNT(*)
+		FROM tool_detections
+		GROUP BY tool_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tool detections: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var n int
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan tool detection: %w", err)
+		}
+		counts[name] = n
+	}
+	return counts, rows.Err()
+}
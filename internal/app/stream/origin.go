@@ -0,0 +1,29 @@
+package stream
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OriginAllowed reports whether a browser request may be served: it has no
+// Origin header (curl and other non-browser clients), comes from a page of
+// the server itself, or from one of allowed, e.g. "http://localhost:3000"
+// or "null" for pages opened from local files. Without this check any web
+// page the user visits could read the live stream or control recording.
+func OriginAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
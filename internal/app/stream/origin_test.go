@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	allowed := []string{"http://localhost:3000/", "null"}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},                       // Not a browser
+		{"http://localhost:8765", true},  // The server's own pages
+		{"https://LOCALHOST:8765", true}, // Hosts compare without case
+		{"http://localhost:3000", true},
+		{"null", true},
+		{"http://localhost:8766", false},
+		{"https://evil.example", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://localhost:8765/events", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := OriginAllowed(r, allowed); got != tt.want {
+			t.Errorf("OriginAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestHubRejectsOtherOrigins(t *testing.T) {
+	hub := NewHub()
+	defer hub.Close()
+
+	// A cross-site WebSocket handshake is refused before the upgrade
+	r := httptest.NewRequest("GET", "http://localhost:8765/events", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	w := httptest.NewRecorder()
+	hub.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("WebSocket from another origin = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = httptest.NewRecorder()
+	hub.ServeSSE(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("SSE from another origin = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if n := hub.ClientCount(); n != 0 {
+		t.Errorf("ClientCount = %d, want 0", n)
+	}
+}
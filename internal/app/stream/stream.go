@@ -0,0 +1,305 @@
+// Package stream broadcasts live cube events as JSON over WebSocket or
+// Server-Sent Events, e.g. to a browser overlay while streaming.
+//
+// Each WebSocket message or SSE data line is one Event. A client that connects mid-session
+// first receives the latest phase, orientation, attitude, battery,
+// connection and state events so it can draw the current state straight
+// away.
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	EventMove        = "move"
+	EventPhase       = "phase"
+	EventOrientation = "orientation"
+	EventAttitude    = "attitude"
+	EventBattery     = "battery"
+	EventConnection  = "connection"
+	EventState       = "state"
+)
+
+// Event is the JSON message sent to clients. Only the fields for its Type
+// are set.
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+
+	// move
+	Move string `json:"move,omitempty"` // Standard notation, e.g. "R'"
+
+	// phase
+	Phase     string `json:"phase,omitempty"`      // e.g. "white_cross"
+	PhaseName string `json:"phase_name,omitempty"` // e.g. "White Cross"
+
+	// orientation
+	Up    string `json:"up,omitempty"`    // Face pointing up
+	Front string `json:"front,omitempty"` // Face pointing at the user
+
+	// attitude
+	Quaternion []float64 `json:"quaternion,omitempty"` // [x, y, z, w], see gocube.Quaternion
+
+	// battery
+	Battery *int `json:"battery,omitempty"` // Percent
+
+	// connection
+	Connected *bool  `json:"connected,omitempty"`
+	Device    string `json:"device,omitempty"`
+
+	// state
+	Moves []string `json:"moves,omitempty"` // Take a solved cube to the current state
+}
+
+// clientBuffer is how many events may queue for a client before it is
+// considered too slow and dropped.
+const clientBuffer = 256
+
+type client struct {
+	send chan []byte
+}
+
+// Hub fans events out to any number of WebSocket and SSE clients. It
+// implements http.Handler for WebSocket; mount it, or ServeSSE, at the path
+// clients connect to. Browser clients must be pages of the same server or
+// of an origin added with AllowOrigins.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	latest  map[string][]byte // Last event of each replayed type
+	origins []string          // Origins allowed besides the server's own
+	closed  bool
+}
+
+// NewHub creates a hub with no clients.
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[*client]struct{}),
+		latest:  make(map[string][]byte),
+	}
+}
+
+// Broadcast sends an event to every connected client. It never blocks on a
+// slow client; clients whose buffer is full are disconnected.
+func (h *Hub) Broadcast(e Event) {
+	data, ok := marshalEvent(&e)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if e.Type != EventMove {
+		h.latest[e.Type] = data
+	}
+	h.sendLocked(data)
+}
+
+// BroadcastWithState sends an event like Broadcast and, in the same step,
+// makes state the state event replayed to clients that connect later, e.g.
+// a move and the moves that now lead to the cube's state. Connected clients
+// are not sent state, having seen the events it sums up.
+func (h *Hub) BroadcastWithState(e, state Event) {
+	state.Type = EventState
+	data, ok := marshalEvent(&e)
+	if !ok {
+		return
+	}
+	stateData, ok := marshalEvent(&state)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if e.Type != EventMove {
+		h.latest[e.Type] = data
+	}
+	h.latest[EventState] = stateData
+	h.sendLocked(data)
+}
+
+// marshalEvent stamps an event with the current time if it has none and
+// encodes it.
+func marshalEvent(e *Event) ([]byte, bool) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("stream: failed to marshal %s event: %v", e.Type, err)
+		return nil, false
+	}
+	return data, true
+}
+
+// sendLocked queues data for every client, disconnecting those whose
+// buffer is full. The caller must hold mu.
+func (h *Hub) sendLocked(data []byte) {
+	for c := range h.clients {
+		select {
+		case c.send <- data:
+		default:
+			h.removeLocked(c)
+		}
+	}
+}
+
+// AllowOrigins lets browser pages of other origins connect, e.g. an overlay
+// served elsewhere ("http://localhost:3000") or opened from a file
+// ("null"). See OriginAllowed.
+func (h *Hub) AllowOrigins(origins ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.origins = append(h.origins, origins...)
+}
+
+// originAllowed reports whether the request's origin may connect.
+func (h *Hub) originAllowed(r *http.Request) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return OriginAllowed(r, h.origins)
+}
+
+// ClientCount returns the number of connected clients.
+func (h *Hub) ClientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams events to it
+// until either side closes.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	conn, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	c := h.addClient()
+	if c == nil {
+		conn.close()
+		return
+	}
+
+	go h.writeLoop(c, conn)
+	conn.readLoop()
+
+	h.mu.Lock()
+	h.removeLocked(c)
+	h.mu.Unlock()
+}
+
+// ServeSSE streams events to the request as Server-Sent Events, one
+// "data:" line per event, until the client goes away or the hub closes.
+// Browsers read it with EventSource.
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request) {
+	if !h.originAllowed(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	c := h.addClient()
+	if c == nil {
+		http.Error(w, "server closing", http.StatusServiceUnavailable)
+		return
+	}
+	defer func() {
+		h.mu.Lock()
+		h.removeLocked(c)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			if !ok {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// addClient registers a new client, queueing the latest state events for
+// it. It returns nil once the hub is closed.
+func (h *Hub) addClient() *client {
+	c := &client{send: make(chan []byte, clientBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	for _, t := range []string{EventConnection, EventBattery, EventOrientation, EventAttitude, EventPhase, EventState} {
+		if data, ok := h.latest[t]; ok {
+			c.send <- data
+		}
+	}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+// writeLoop sends queued events to a WebSocket client until its channel is
+// closed.
+func (h *Hub) writeLoop(c *client, conn *wsConn) {
+	for data := range c.send {
+		if err := conn.writeText(data); err != nil {
+			h.mu.Lock()
+			h.removeLocked(c)
+			h.mu.Unlock()
+			break
+		}
+	}
+	conn.close()
+}
+
+// removeLocked disconnects a client. The caller must hold mu.
+func (h *Hub) removeLocked(c *client) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	close(c.send)
+}
+
+// Close disconnects all clients and stops accepting new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		h.removeLocked(c)
+	}
+}
@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A minimal RFC 6455 server: enough to push text frames to browsers and
+// answer their pings and close frames. Clients never need to send data.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// maxClientFrame is the largest frame accepted from a client. Anything
+// bigger is a protocol violation for this server and closes the connection.
+const maxClientFrame = 64 * 1024
+
+const writeTimeout = 5 * time.Second
+
+var errBadHandshake = errors.New("not a websocket handshake")
+
+// wsConn is a server-side WebSocket connection.
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	mu sync.Mutex // Serializes frame writes
+}
+
+// upgrade performs the WebSocket handshake and takes over the connection.
+func upgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "expected a WebSocket upgrade", http.StatusBadRequest)
+		return nil, errBadHandshake
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errBadHandshake
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errBadHandshake
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be upgraded", http.StatusInternalServerError)
+		return nil, errors.New("response writer does not support hijacking")
+	}
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack connection: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to write handshake: %w", err)
+	}
+	return &wsConn{conn: conn, br: brw.Reader}, nil
+}
+
+// headerContains reports whether a comma-separated header has token,
+// ignoring case.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeFrame writes one unfragmented, unmasked frame.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode // FIN
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeText sends a text message.
+func (c *wsConn) writeText(data []byte) error {
+	return c.writeFrame(opText, data)
+}
+
+// readLoop reads client frames until the connection closes, answering pings
+// and close frames and discarding everything else.
+func (c *wsConn) readLoop() error {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			// Echo the status code, which completes the closing handshake
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			c.writeFrame(opClose, payload)
+			return io.EOF
+		}
+	}
+}
+
+// readFrame reads one frame from the client, unmasking its payload.
+func (c *wsConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if !masked {
+		return 0, nil, errors.New("client frame is not masked")
+	}
+	if length > maxClientFrame {
+		return 0, nil, fmt.Errorf("client frame too large: %d bytes", length)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+// close sends a close frame and closes the connection.
+func (c *wsConn) close() error {
+	c.writeFrame(opClose, []byte{0x03, 0xE9}) // 1001 going away
+	return c.conn.Close()
+}
@@ -0,0 +1,157 @@
+// Package telemetry builds and uploads opt-in anonymized usage aggregates.
+//
+// Reports contain only counts and ratios computed from the local database.
+// They never include solve IDs, device names or identifiers, timestamps,
+// scrambles, notes, or move sequences.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// SchemaVersion is the version of the Report format.
+const SchemaVersion = 1
+
+// Report is the exact payload that is uploaded.
+type Report struct {
+	SchemaVersion int    `json:"schema_version"`
+	InstallID     string `json:"install_id"`
+	AppVersion    string `json:"app_version"`
+	OS            string `json:"os"`
+	Arch          string `json:"arch"`
+
+	Solves         SolveMetrics     `json:"solves"`
+	PhaseDetection []PhaseDetection `json:"phase_detection"`
+	Errors         ErrorMetrics     `json:"errors"`
+}
+
+// SolveMetrics contains solve counts.
+type SolveMetrics struct {
+	Total            int     `json:"total"`
+	Completed        int     `json:"completed"`
+	Empty            int     `json:"empty"` // Ended with no moves
+	AvgMovesPerSolve float64 `json:"avg_moves_per_solve"`
+}
+
+// PhaseDetection reports how often a phase was marked in completed solves.
+type PhaseDetection struct {
+	PhaseKey string  `json:"phase_key"`
+	Solves   int     `json:"solves"`
+	Rate     float64 `json:"rate"` // Fraction of completed solves
+}
+
+// ErrorMetrics contains protocol error rates.
+type ErrorMetrics struct {
+	TotalEvents   int     `json:"total_events"`
+	UnknownEvents int     `json:"unknown_events"` // Unrecognized message types
+	UnknownRate   float64 `json:"unknown_rate"`
+}
+
+// Collect builds a report from the local database.
+func Collect(db *storage.DB, installID, appVersion string) (*Report, error) {
+	stats := storage.NewStatsRepository(db)
+
+	report := &Report{
+		SchemaVersion:  SchemaVersion,
+		InstallID:      installID,
+		AppVersion:     appVersion,
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		PhaseDetection: []PhaseDetection{},
+	}
+
+	total, completed, err := stats.SolveCounts()
+	if err != nil {
+		return nil, err
+	}
+	report.Solves.Total = total
+	report.Solves.Completed = completed
+
+	empty, err := stats.EmptySolveCount()
+	if err != nil {
+		return nil, err
+	}
+	report.Solves.Empty = empty
+
+	moves, err := stats.MoveCount()
+	if err != nil {
+		return nil, err
+	}
+	if total > 0 {
+		report.Solves.AvgMovesPerSolve = float64(moves) / float64(total)
+	}
+
+	coverage, err := stats.PhaseMarkCoverage()
+	if err != nil {
+		return nil, err
+	}
+	for key, count := range coverage {
+		pd := PhaseDetection{PhaseKey: key, Solves: count}
+		if completed > 0 {
+			pd.Rate = float64(count) / float64(completed)
+		}
+		report.PhaseDetection = append(report.PhaseDetection, pd)
+	}
+	sort.Slice(report.PhaseDetection, func(i, j int) bool {
+		return report.PhaseDetection[i].PhaseKey < report.PhaseDetection[j].PhaseKey
+	})
+
+	eventCounts, err := stats.EventTypeCounts()
+	if err != nil {
+		return nil, err
+	}
+	for eventType, count := range eventCounts {
+		report.Errors.TotalEvents += count
+		if strings.HasPrefix(eventType, "unknown_") {
+			report.Errors.UnknownEvents += count
+		}
+	}
+	if report.Errors.TotalEvents > 0 {
+		report.Errors.UnknownRate = float64(report.Errors.UnknownEvents) / float64(report.Errors.TotalEvents)
+	}
+
+	return report, nil
+}
+
+// Send uploads a report to the endpoint as JSON.
+func Send(ctx context.Context, endpoint string, report *Report) error {
+	if endpoint == "" {
+		return fmt.Errorf("no telemetry endpoint configured")
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+
+	return nil
+}
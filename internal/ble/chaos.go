@@ -0,0 +1,137 @@
+package ble
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// ChaosConfig sets how often a MockTransport with chaos misbehaves. Each
+// probability is applied per notification, independently.
+type ChaosConfig struct {
+	Drop      float64       // Notification lost
+	Duplicate float64       // Frame delivered twice
+	Reorder   float64       // Frame held back and delivered after the next one
+	Delay     float64       // Frame delivered late
+	MaxDelay  time.Duration // Upper bound for a delayed frame (default 500ms)
+	Seed      int64
+}
+
+// Enabled reports whether any misbehaviour is configured.
+func (c ChaosConfig) Enabled() bool {
+	return c.Drop > 0 || c.Duplicate > 0 || c.Reorder > 0 || c.Delay > 0
+}
+
+// ChaosStats counts what chaos did to the notification stream.
+type ChaosStats struct {
+	Sent       int // Notifications handed to the transport
+	Delivered  int // Frames passed on, including duplicates
+	Dropped    int
+	Duplicated int
+	Reordered  int
+	Delayed    int
+}
+
+// chaosFrame is a notification in flight on a faulty link.
+type chaosFrame struct {
+	char string
+	data []byte
+	due  time.Time // When a delayed frame may be delivered
+}
+
+// chaos injects the failures seen on real links into a notification
+// stream: dropped notifications, duplicated frames, reordered packets and
+// delayed delivery. Delays are measured on the supplied clock, so they
+// work with a simulated one. It only decides what is delivered and in
+// which order; the transport delivers.
+type chaos struct {
+	cfg ChaosConfig
+	rng *rand.Rand
+	now func() time.Time
+
+	held    *chaosFrame // Frame waiting to be swapped with the next
+	delayed []chaosFrame
+	stats   ChaosStats
+}
+
+func newChaos(cfg ChaosConfig, now func() time.Time) *chaos {
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 500 * time.Millisecond
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &chaos{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), now: now}
+}
+
+// send takes a notification and returns the frames to deliver now, in
+// order. Delayed frames that are due come first.
+func (c *chaos) send(f chaosFrame) []chaosFrame {
+	c.stats.Sent++
+	out := c.due()
+
+	if c.rng.Float64() < c.cfg.Drop {
+		c.stats.Dropped++
+		return c.count(out)
+	}
+	if c.rng.Float64() < c.cfg.Delay {
+		c.stats.Delayed++
+		f.due = c.now().Add(time.Duration(c.rng.Int63n(int64(c.cfg.MaxDelay))) + 1)
+		c.delayed = append(c.delayed, f)
+		return c.count(out)
+	}
+
+	if c.held == nil && c.rng.Float64() < c.cfg.Reorder {
+		c.stats.Reordered++
+		c.held = &f
+		return c.count(out)
+	}
+	out = append(out, f)
+	if c.rng.Float64() < c.cfg.Duplicate {
+		c.stats.Duplicated++
+		out = append(out, f)
+	}
+
+	// A held frame goes out after the one that overtook it
+	if c.held != nil {
+		out = append(out, *c.held)
+		c.held = nil
+	}
+	return c.count(out)
+}
+
+// flush returns everything still in flight, as when the link settles.
+func (c *chaos) flush() []chaosFrame {
+	var out []chaosFrame
+	if c.held != nil {
+		out = append(out, *c.held)
+		c.held = nil
+	}
+	sort.SliceStable(c.delayed, func(i, j int) bool { return c.delayed[i].due.Before(c.delayed[j].due) })
+	out = append(out, c.delayed...)
+	c.delayed = nil
+	return c.count(out)
+}
+
+// due removes and returns the delayed frames whose time has come, in due
+// order.
+func (c *chaos) due() []chaosFrame {
+	now := c.now()
+	var out, waiting []chaosFrame
+	for _, f := range c.delayed {
+		if f.due.After(now) {
+			waiting = append(waiting, f)
+		} else {
+			out = append(out, f)
+		}
+	}
+	c.delayed = waiting
+	sort.SliceStable(out, func(i, j int) bool { return out[i].due.Before(out[j].due) })
+	return out
+}
+
+// count records frames as delivered.
+func (c *chaos) count(frames []chaosFrame) []chaosFrame {
+	c.stats.Delivered += len(frames)
+	return frames
+}
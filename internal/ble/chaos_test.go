@@ -0,0 +1,110 @@
+package ble
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// chaosTransport returns a connected mock with chaos whose notifications of
+// "notify" are collected, by their first byte.
+func chaosTransport(t *testing.T, cfg ChaosConfig, now func() time.Time) (*MockTransport, *[]byte) {
+	t.Helper()
+	cube := Advertisement{Name: "GoCube_1234", Address: "00:11:22:33:44:55"}
+	transport := NewMockTransport(cube)
+	if err := transport.Connect(context.Background(), cube.Address, "service", []string{"notify"}); err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	if err := transport.Subscribe(context.Background(), "notify", func(data []byte) { got = append(got, data[0]) }); err != nil {
+		t.Fatal(err)
+	}
+	transport.SetChaos(cfg, now)
+	return transport, &got
+}
+
+func notifyAll(transport *MockTransport, n int) {
+	for i := 0; i < n; i++ {
+		transport.Notify("notify", []byte{byte(i)})
+	}
+}
+
+func TestChaosFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   ChaosConfig
+		want  []byte
+		stats ChaosStats
+	}{
+		{"none", ChaosConfig{}, []byte{0, 1, 2, 3}, ChaosStats{Sent: 4, Delivered: 4}},
+		{"drop", ChaosConfig{Drop: 1}, nil, ChaosStats{Sent: 4, Dropped: 4}},
+		{"duplicate", ChaosConfig{Duplicate: 1}, []byte{0, 0, 1, 1, 2, 2, 3, 3}, ChaosStats{Sent: 4, Delivered: 8, Duplicated: 4}},
+		// Each held frame goes out after the one that overtakes it
+		{"reorder", ChaosConfig{Reorder: 1}, []byte{1, 0, 3, 2}, ChaosStats{Sent: 4, Delivered: 4, Reordered: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, got := chaosTransport(t, tt.cfg, nil)
+			notifyAll(transport, 4)
+			transport.FlushChaos()
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("delivered %v, want %v", *got, tt.want)
+			}
+			if s := transport.ChaosStats(); s != tt.stats {
+				t.Errorf("stats = %+v, want %+v", s, tt.stats)
+			}
+		})
+	}
+}
+
+func TestChaosDelay(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	transport, got := chaosTransport(t, ChaosConfig{Delay: 1, MaxDelay: 100 * time.Millisecond}, clock)
+
+	notifyAll(transport, 3)
+	if len(*got) != 0 {
+		t.Fatalf("delivered %v before any delay passed", *got)
+	}
+
+	// Frames that are due go out, oldest due first, before the next is sent
+	now = now.Add(100 * time.Millisecond)
+	transport.Notify("notify", []byte{3})
+	if len(*got) != 3 {
+		t.Fatalf("delivered %v after the delays passed, want the first 3", *got)
+	}
+	transport.FlushChaos()
+	if len(*got) != 4 || (*got)[3] != 3 {
+		t.Errorf("delivered %v after flushing, want frame 3 last", *got)
+	}
+	if s := transport.ChaosStats(); s != (ChaosStats{Sent: 4, Delivered: 4, Delayed: 4}) {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestChaosSeed(t *testing.T) {
+	cfg := ChaosConfig{Drop: 0.1, Duplicate: 0.1, Reorder: 0.1, Delay: 0.1, Seed: 42}
+	run := func() ([]byte, ChaosStats) {
+		now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+		transport, got := chaosTransport(t, cfg, func() time.Time { return now })
+		for i := 0; i < 200; i++ {
+			now = now.Add(50 * time.Millisecond)
+			transport.Notify("notify", []byte{byte(i)})
+		}
+		transport.FlushChaos()
+		return *got, transport.ChaosStats()
+	}
+
+	got, stats := run()
+	if stats.Sent != 200 || stats.Dropped == 0 || stats.Duplicated == 0 || stats.Reordered == 0 || stats.Delayed == 0 {
+		t.Fatalf("stats = %+v, want every fault at these rates", stats)
+	}
+	if stats.Delivered != len(got) || stats.Delivered != stats.Sent-stats.Dropped+stats.Duplicated {
+		t.Errorf("delivered %d frames, stats %+v", len(got), stats)
+	}
+	again, againStats := run()
+	if !reflect.DeepEqual(got, again) || againStats != stats {
+		t.Error("the same seed gave a different stream")
+	}
+}
@@ -1,16 +1,15 @@
-// Package ble provides low-level BLE communication with GoCube devices.
+// Package ble provides low-level BLE communication with smart cubes. GoCube,
+// GAN and MoYu cubes are supported through per-vendor SmartCube drivers.
 package ble
 
 import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
-	"tinygo.org/x/bluetooth"
 )
 
 // Errors
@@ -19,45 +18,30 @@ var (
 	ErrAlreadyConnected = errors.New("ble: already connected to a device")
 	ErrDeviceNotFound   = errors.New("ble: device not found")
 	ErrTimeout          = errors.New("ble: connection timeout")
+	ErrRSSIUnsupported  = errors.New("ble: transport cannot read the signal strength")
 )
 
-// BLE UUIDs
-var (
-	serviceUUID = bluetooth.NewUUID(mustParseUUID(protocol.ServiceUUID))
-	txCharUUID  = bluetooth.NewUUID(mustParseUUID(protocol.TxCharUUID))
-	rxCharUUID  = bluetooth.NewUUID(mustParseUUID(protocol.RxCharUUID))
-)
+// DefaultConnectScanTimeout bounds Connect's search for the device when
+// its context has no deadline.
+const DefaultConnectScanTimeout = 10 * time.Second
 
-func mustParseUUID(s string) [16]byte {
-	var uuid [16]byte
-	clean := ""
-	for _, c := range s {
-		if c != '-' {
-			clean += string(c)
-		}
-	}
-	for i := 0; i < 16; i++ {
-		var b byte
-		fmt.Sscanf(clean[i*2:i*2+2], "%02x", &b)
-		uuid[i] = b
-	}
-	return uuid
-}
+// DefaultCommandTimeout bounds the writes of SendCommand and the Request
+// methods. See WithCommandTimeout.
+const DefaultCommandTimeout = 5 * time.Second
 
-// ScanResult represents a discovered GoCube device.
+// ScanResult represents a discovered smart cube.
 type ScanResult struct {
-	Name    string
-	UUID    string
-	RSSI    int16
-	Address bluetooth.Address
+	Name   string
+	UUID   string // Transport address
+	RSSI   int16
+	Vendor string // VendorGoCube, VendorGAN or VendorMoyu
+	MAC    string // Advertised MAC address, if known; GAN drivers need it
 }
 
-// Client manages BLE connection to a GoCube device.
+// Client manages BLE connection to a smart cube.
 type Client struct {
-	adapter *bluetooth.Adapter
-	device  bluetooth.Device
-	txChar  bluetooth.DeviceCharacteristic
-	rxChar  bluetooth.DeviceCharacteristic
+	transport Transport
+	driver    SmartCube
 
 	mu         sync.RWMutex
 	connected  bool
@@ -65,21 +49,52 @@ type Client struct {
 	deviceUUID string
 	battery    int
 
+	commandTimeout time.Duration
+
+	// Keep-alive and sleep detection
+	lastRx        time.Time
+	asleep        bool
+	keepAliveStop chan struct{}
+
+	// Auto-reconnect
+	target        ScanResult // Device of the current connection
+	reconnect     ReconnectPolicy
+	reconnectStop chan struct{} // Non-nil while reconnecting
+
 	onMessage    func(*protocol.Message)
 	onDisconnect func()
+	onReconnect  func(error)
+	onSleep      func()
+	onWake       func()
 }
 
-// NewClient creates a new BLE client for GoCube communication.
-func NewClient() (*Client, error) {
-	adapter := bluetooth.DefaultAdapter
-	if err := adapter.Enable(); err != nil {
-		return nil, fmt.Errorf("failed to enable BLE adapter: %w", err)
+// NewClient creates a new BLE client for smart cube communication. It uses
+// TinyGoTransport unless WithTransport gives another backend.
+func NewClient(opts ...Option) (*Client, error) {
+	c := &Client{
+		battery:        -1,
+		commandTimeout: DefaultCommandTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.transport == nil {
+		transport, err := NewTinyGoTransport()
+		if err != nil {
+			return nil, err
+		}
+		c.transport = transport
 	}
+	return c, nil
+}
 
-	return &Client{
-		adapter: adapter,
-		battery: -1,
-	}, nil
+// WithCommandTimeout bounds the writes of SendCommand and the Request
+// methods (DefaultCommandTimeout unless set). A write that takes longer is
+// treated as a lost connection. 0 lets writes block.
+func WithCommandTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.commandTimeout = d
+	}
 }
 
 // SetMessageCallback sets the callback for incoming messages.
@@ -89,14 +104,17 @@ func (c *Client) SetMessageCallback(cb func(*protocol.Message)) {
 	c.onMessage = cb
 }
 
-// SetDisconnectCallback sets the callback for disconnection events.
+// SetDisconnectCallback sets the callback fired when the link to the cube
+// drops. It does not fire for Disconnect.
 func (c *Client) SetDisconnectCallback(cb func()) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.onDisconnect = cb
 }
 
-// Scan scans for GoCube devices.
+// Scan scans for supported smart cubes until timeout elapses or ctx's
+// deadline passes, and returns those found. A timeout of 0 scans until ctx
+// is done. If ctx is cancelled, Scan returns ctx.Err().
 func (c *Client) Scan(ctx context.Context, timeout time.Duration) ([]ScanResult, error) {
 	c.mu.RLock()
 	if c.connected {
@@ -109,47 +127,47 @@ func (c *Client) Scan(ctx context.Context, timeout time.Duration) ([]ScanResult,
 	var mu sync.Mutex
 	seen := make(map[string]bool)
 
-	done := make(chan struct{})
-
-	go func() {
-		c.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-			name := result.LocalName()
-			addr := result.Address.String()
-
-			mu.Lock()
-			if seen[addr] {
-				mu.Unlock()
-				return
-			}
-			seen[addr] = true
-			mu.Unlock()
-
-			if strings.HasPrefix(strings.ToLower(name), "gocube") {
-				mu.Lock()
-				results = append(results, ScanResult{
-					Name:    name,
-					UUID:    addr,
-					RSSI:    result.RSSI,
-					Address: result.Address,
-				})
-				mu.Unlock()
-			}
-		})
-		close(done)
-	}()
-
-	select {
-	case <-time.After(timeout):
-	case <-ctx.Done():
+	scanCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	c.adapter.StopScan()
-	<-done
+	err := c.transport.Scan(scanCtx, func(adv Advertisement) {
+		mu.Lock()
+		defer mu.Unlock()
+		if seen[adv.Address] {
+			return
+		}
+		seen[adv.Address] = true
+
+		if v := vendorFor(adv.Name); v != nil {
+			results = append(results, ScanResult{
+				Name:   adv.Name,
+				UUID:   adv.Address,
+				RSSI:   adv.RSSI,
+				Vendor: v.name,
+				MAC:    advertisedMAC(adv),
+			})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	}
 
+	mu.Lock()
+	defer mu.Unlock()
 	return results, nil
 }
 
-// Connect connects to a GoCube device by UUID.
+// Connect scans for the smart cube with the given UUID and connects to it.
+// It searches until ctx is done, returning ctx.Err(), or if ctx has no
+// deadline for DefaultConnectScanTimeout, returning ErrDeviceNotFound. ctx
+// also bounds the connection itself; see ConnectToResult.
 func (c *Client) Connect(ctx context.Context, deviceUUID string) error {
 	c.mu.Lock()
 	if c.connected {
@@ -158,17 +176,34 @@ func (c *Client) Connect(ctx context.Context, deviceUUID string) error {
 	}
 	c.mu.Unlock()
 
-	var targetAddr bluetooth.Address
-	var targetName string
+	var target ScanResult
 	found := make(chan struct{})
 	var foundOnce sync.Once
 
+	var scanCtx context.Context
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); ok {
+		scanCtx, cancel = context.WithCancel(ctx)
+	} else {
+		scanCtx, cancel = context.WithTimeout(ctx, DefaultConnectScanTimeout)
+	}
+	defer cancel()
+
+	scanned := make(chan struct{})
 	go func() {
-		c.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-			if result.Address.String() == deviceUUID {
-				targetAddr = result.Address
-				targetName = result.LocalName()
+		defer close(scanned)
+		c.transport.Scan(scanCtx, func(adv Advertisement) {
+			if adv.Address == deviceUUID {
 				foundOnce.Do(func() {
+					target = ScanResult{
+						Name: adv.Name,
+						UUID: deviceUUID,
+						RSSI: adv.RSSI,
+						MAC:  advertisedMAC(adv),
+					}
+					if v := vendorFor(adv.Name); v != nil {
+						target.Vendor = v.name
+					}
 					close(found)
 				})
 			}
@@ -177,67 +212,23 @@ func (c *Client) Connect(ctx context.Context, deviceUUID string) error {
 
 	select {
 	case <-found:
-		c.adapter.StopScan()
-	case <-time.After(10 * time.Second):
-		c.adapter.StopScan()
-		return ErrDeviceNotFound
-	case <-ctx.Done():
-		c.adapter.StopScan()
-		return ctx.Err()
-	}
-
-	device, err := c.adapter.Connect(targetAddr, bluetooth.ConnectionParams{})
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-
-	services, err := device.DiscoverServices([]bluetooth.UUID{serviceUUID})
-	if err != nil {
-		device.Disconnect()
-		return fmt.Errorf("failed to discover services: %w", err)
-	}
-
-	if len(services) == 0 {
-		device.Disconnect()
-		return fmt.Errorf("GoCube service not found")
-	}
-
-	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{txCharUUID, rxCharUUID})
-	if err != nil {
-		device.Disconnect()
-		return fmt.Errorf("failed to discover characteristics: %w", err)
-	}
-
-	var txChar, rxChar bluetooth.DeviceCharacteristic
-	for _, ch := range chars {
-		if ch.UUID() == txCharUUID {
-			txChar = ch
-		} else if ch.UUID() == rxCharUUID {
-			rxChar = ch
+		cancel()
+		<-scanned
+	case <-scanCtx.Done():
+		<-scanned
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		return ErrDeviceNotFound
 	}
 
-	err = txChar.EnableNotifications(c.handleNotification)
-	if err != nil {
-		device.Disconnect()
-		return fmt.Errorf("failed to enable notifications: %w", err)
-	}
-
-	c.mu.Lock()
-	c.device = device
-	c.txChar = txChar
-	c.rxChar = rxChar
-	c.connected = true
-	c.deviceName = targetName
-	c.deviceUUID = deviceUUID
-	c.mu.Unlock()
-
-	c.RequestBattery()
-
-	return nil
+	return c.ConnectToResult(ctx, target)
 }
 
-// ConnectToResult connects directly to a device from a scan result.
+// ConnectToResult connects directly to a device from a scan result. ctx
+// bounds every stage: the connection, service and characteristic discovery,
+// subscribing to notifications and the first battery request. If ctx is
+// done first, the link is dropped and ctx.Err() is returned.
 func (c *Client) ConnectToResult(ctx context.Context, result ScanResult) error {
 	c.mu.Lock()
 	if c.connected {
@@ -246,73 +237,74 @@ func (c *Client) ConnectToResult(ctx context.Context, result ScanResult) error {
 	}
 	c.mu.Unlock()
 
-	device, err := c.adapter.Connect(result.Address, bluetooth.ConnectionParams{})
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	services, err := device.DiscoverServices([]bluetooth.UUID{serviceUUID})
+	driver, err := openSmartCube(result)
 	if err != nil {
-		device.Disconnect()
-		return fmt.Errorf("failed to discover services: %w", err)
+		return err
 	}
 
-	if len(services) == 0 {
-		device.Disconnect()
-		return fmt.Errorf("GoCube service not found")
-	}
-
-	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{txCharUUID, rxCharUUID})
+	c.watchConnection(result.UUID)
+	err = c.transport.Connect(ctx, result.UUID, driver.Service(), []string{driver.NotifyChar(), driver.WriteChar()})
 	if err != nil {
-		device.Disconnect()
-		return fmt.Errorf("failed to discover characteristics: %w", err)
-	}
-
-	var txChar, rxChar bluetooth.DeviceCharacteristic
-	for _, ch := range chars {
-		if ch.UUID() == txCharUUID {
-			txChar = ch
-		} else if ch.UUID() == rxCharUUID {
-			rxChar = ch
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
+		return fmt.Errorf("%s: %w", driver.Vendor(), err)
 	}
 
-	err = txChar.EnableNotifications(c.handleNotification)
+	err = c.transport.Subscribe(ctx, driver.NotifyChar(), func(data []byte) {
+		c.handleNotification(driver, data)
+	})
 	if err != nil {
-		device.Disconnect()
+		c.transport.Disconnect()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		return fmt.Errorf("failed to enable notifications: %w", err)
 	}
 
 	c.mu.Lock()
-	c.device = device
-	c.txChar = txChar
-	c.rxChar = rxChar
+	c.driver = driver
 	c.connected = true
+	c.lastRx = time.Now()
+	c.asleep = false
 	c.deviceName = result.Name
 	c.deviceUUID = result.UUID
+	c.target = result
 	c.mu.Unlock()
 
-	c.RequestBattery()
+	if err := c.SendCommandContext(ctx, protocol.CmdRequestBattery); err != nil && ctx.Err() != nil {
+		c.Disconnect()
+		return ctx.Err()
+	}
 
 	return nil
 }
 
-// Disconnect disconnects from the current device.
+// Disconnect disconnects from the current device, stopping any
+// auto-reconnect in progress.
 func (c *Client) Disconnect() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.StopKeepAlive()
 
+	c.mu.Lock()
+	c.stopReconnect()
 	if !c.connected {
+		c.mu.Unlock()
 		return nil
 	}
 
-	err := c.device.Disconnect()
 	c.connected = false
+	c.driver = nil
 	c.deviceName = ""
 	c.deviceUUID = ""
 	c.battery = -1
+	c.mu.Unlock()
 
-	return err
+	// Unlocked: the transport may report the disconnect synchronously
+	return c.transport.Disconnect()
 }
 
 // IsConnected returns true if connected to a device.
@@ -329,6 +321,16 @@ func (c *Client) DeviceName() string {
 	return c.deviceName
 }
 
+// Vendor returns the connected cube's vendor, e.g. VendorGAN.
+func (c *Client) Vendor() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.driver == nil {
+		return ""
+	}
+	return c.driver.Vendor()
+}
+
 // DeviceUUID returns the connected device UUID.
 func (c *Client) DeviceUUID() string {
 	c.mu.RLock()
@@ -343,21 +345,81 @@ func (c *Client) Battery() int {
 	return c.battery
 }
 
-// SendCommand sends a command to the cube.
-func (c *Client) SendCommand(cmd byte) error {
+// ReadRSSI reads the signal strength of the connection in dBm. It returns
+// ErrRSSIUnsupported if the transport is not an RSSIReader; TinyGoTransport
+// is not, as the bluetooth package only reports RSSI while scanning.
+func (c *Client) ReadRSSI() (int16, error) {
+	if !c.IsConnected() {
+		return 0, ErrNotConnected
+	}
+	reader, ok := c.transport.(RSSIReader)
+	if !ok {
+		return 0, ErrRSSIUnsupported
+	}
+	return reader.ReadRSSI()
+}
+
+// ScanRSSI returns the signal strength the connected device was advertising
+// with when it was found, in dBm.
+func (c *Client) ScanRSSI() int16 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.target.RSSI
+}
 
-	if !c.connected {
+// SendCommand sends a GoCube command to the cube, translated by its driver.
+// It returns protocol.ErrUnsupportedCommand if the cube has no equivalent,
+// and ErrConnectionLost if the write fails or does not finish within the
+// command timeout, which also starts auto-reconnect.
+func (c *Client) SendCommand(cmd byte) error {
+	c.mu.RLock()
+	timeout := c.commandTimeout
+	c.mu.RUnlock()
+
+	if timeout <= 0 {
+		return c.SendCommandContext(context.Background(), cmd)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := c.SendCommandContext(ctx, cmd)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		c.connectionLost()
+	}
+	return err
+}
+
+// SendCommandContext is SendCommand with the write bounded by ctx. If ctx
+// is done first it returns ctx.Err() and leaves the connection alone.
+func (c *Client) SendCommandContext(ctx context.Context, cmd byte) error {
+	err := c.sendCommand(ctx, cmd)
+	if errors.Is(err, ErrConnectionLost) {
+		c.connectionLost()
+	}
+	return err
+}
+
+func (c *Client) sendCommand(ctx context.Context, cmd byte) error {
+	c.mu.RLock()
+	connected, driver := c.connected, c.driver
+	c.mu.RUnlock()
+
+	if !connected {
 		return ErrNotConnected
 	}
 
-	data := protocol.BuildCommand(cmd)
-	_, err := c.rxChar.WriteWithoutResponse(data)
+	data, err := driver.Encode(cmd)
 	if err != nil {
-		_, err = c.rxChar.Write(data)
+		return err
 	}
-	return err
+	// Unlocked: a write that hangs must not block the client
+	if err := c.transport.WriteCharacteristic(ctx, driver.WriteChar(), data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: %v", ErrConnectionLost, err)
+	}
+	return nil
 }
 
 // RequestBattery requests the battery level from the cube.
@@ -365,6 +427,16 @@ func (c *Client) RequestBattery() error {
 	return c.SendCommand(protocol.CmdRequestBattery)
 }
 
+// RequestOfflineStats requests the cube's cumulative move, time and solve counters.
+func (c *Client) RequestOfflineStats() error {
+	return c.SendCommand(protocol.CmdRequestOfflineStats)
+}
+
+// RequestCubeType requests the cube model (standard or edge).
+func (c *Client) RequestCubeType() error {
+	return c.SendCommand(protocol.CmdRequestCubeType)
+}
+
 // RequestState requests the current cube state.
 func (c *Client) RequestState() error {
 	return c.SendCommand(protocol.CmdRequestState)
@@ -405,27 +477,32 @@ func (c *Client) CalibrateOrientation() error {
 	return c.SendCommand(protocol.CmdCalibrateOrientation)
 }
 
-// handleNotification handles incoming BLE notifications.
-func (c *Client) handleNotification(data []byte) {
-	msg, err := protocol.Parse(data)
+// handleNotification handles incoming BLE notifications, decoded by the
+// driver of the connection they arrived on.
+func (c *Client) handleNotification(driver SmartCube, data []byte) {
+	msgs, err := driver.Decode(data)
 	if err != nil {
 		return
 	}
 
-	// Handle battery updates internally
-	if msg.Type == protocol.MsgTypeBattery {
-		if battery, err := protocol.DecodeBattery(msg.Payload); err == nil {
-			c.mu.Lock()
-			c.battery = battery.Level
-			c.mu.Unlock()
+	c.markActive()
+
+	for _, msg := range msgs {
+		// Handle battery updates internally
+		if msg.Type == protocol.MsgTypeBattery {
+			if battery, err := protocol.DecodeBattery(msg.Payload); err == nil {
+				c.mu.Lock()
+				c.battery = battery.Level
+				c.mu.Unlock()
+			}
 		}
-	}
 
-	c.mu.RLock()
-	cb := c.onMessage
-	c.mu.RUnlock()
+		c.mu.RLock()
+		cb := c.onMessage
+		c.mu.RUnlock()
 
-	if cb != nil {
-		cb(msg)
+		if cb != nil {
+			cb(msg)
+		}
 	}
 }
@@ -0,0 +1,145 @@
+package ble
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+func TestClientWithMockTransport(t *testing.T) {
+	cube := Advertisement{Name: "GoCube_1234", Address: "00:11:22:33:44:55", RSSI: -50}
+	transport := NewMockTransport(cube, Advertisement{Name: "Headphones", Address: "aa:bb:cc:dd:ee:ff"})
+	c, err := NewClient(WithTransport(transport), WithAutoReconnect(ReconnectPolicy{Retries: 2, Backoff: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	results, err := c.Scan(context.Background(), 20*time.Millisecond)
+	if err != nil || len(results) != 1 || results[0].Vendor != VendorGoCube || results[0].UUID != cube.Address {
+		t.Fatalf("Scan = %+v, %v; want only the GoCube", results, err)
+	}
+
+	if err := c.Connect(context.Background(), cube.Address); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if !c.IsConnected() || c.DeviceName() != cube.Name || transport.Connected() != cube.Address {
+		t.Fatalf("connected = %v to %q, transport at %q", c.IsConnected(), c.DeviceName(), transport.Connected())
+	}
+
+	// Connecting requests the battery level on the write characteristic
+	writes := transport.Writes()
+	if len(writes) != 1 || writes[0].Char != protocol.RxCharUUID || !bytes.Equal(writes[0].Data, protocol.BuildCommand(protocol.CmdRequestBattery)) {
+		t.Fatalf("writes after connect = %+v, want a battery request", writes)
+	}
+
+	// The mock can read the signal strength; the scan's is kept
+	transport.SetRSSI(-63)
+	if rssi, err := c.ReadRSSI(); err != nil || rssi != -63 || c.ScanRSSI() != cube.RSSI {
+		t.Fatalf("ReadRSSI = %d, %v, ScanRSSI = %d; want -63, nil, %d", rssi, err, c.ScanRSSI(), cube.RSSI)
+	}
+
+	// Notifications are decoded into messages; battery levels are kept
+	var got []*protocol.Message
+	c.SetMessageCallback(func(m *protocol.Message) { got = append(got, m) })
+	if !transport.Notify(protocol.TxCharUUID, protocol.BuildMessage(protocol.MsgTypeBattery, []byte{87})) {
+		t.Fatal("client did not subscribe to the notify characteristic")
+	}
+	transport.Notify(protocol.TxCharUUID, []byte{0x00, 0x01}) // Garbage is dropped
+	if len(got) != 1 || got[0].Type != protocol.MsgTypeBattery || c.Battery() != 87 {
+		t.Fatalf("messages %v, battery %d; want one battery message, 87", got, c.Battery())
+	}
+
+	// A dropped link fires the disconnect callback, then reconnects and
+	// requests the state
+	disconnected := make(chan struct{}, 1)
+	reconnected := make(chan error, 1)
+	c.SetDisconnectCallback(func() { disconnected <- struct{}{} })
+	c.SetReconnectCallback(func(err error) { reconnected <- err })
+	transport.RefuseConnects(1)
+	transport.Drop()
+
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("disconnect callback not fired")
+	}
+	select {
+	case err := <-reconnected:
+		if err != nil {
+			t.Fatalf("reconnect failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reconnect callback not fired")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !bytes.Equal(transport.Writes()[len(transport.Writes())-1].Data, protocol.BuildCommand(protocol.CmdRequestState)) {
+		if time.Now().After(deadline) {
+			t.Fatalf("no state request after reconnecting: %+v", transport.Writes())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := c.Disconnect(); err != nil || c.IsConnected() || transport.Connected() != "" {
+		t.Fatalf("Disconnect = %v, connected %v", err, c.IsConnected())
+	}
+	if err := c.RequestBattery(); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("command after Disconnect = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestClientConnectUnknownDevice(t *testing.T) {
+	c, err := NewClient(WithTransport(NewMockTransport()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Connect(ctx, "00:11:22:33:44:55"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Connect to a missing device = %v, want the context error", err)
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	cube := Advertisement{Name: "GoCube_1234", Address: "00:11:22:33:44:55"}
+	transport := NewMockTransport(cube)
+	c, err := NewClient(WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// A cancelled scan reports the cancellation, not the cubes seen so far
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if results, err := c.Scan(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Scan = %+v, %v; want context.Canceled", results, err)
+	}
+
+	// A connection that hangs is abandoned at the deadline
+	transport.StallConnects(1)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.ConnectToResult(ctx, ScanResult{Name: cube.Name, UUID: cube.Address, Vendor: VendorGoCube}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stalled ConnectToResult = %v, want context.DeadlineExceeded", err)
+	}
+	if c.IsConnected() || transport.Connected() != "" {
+		t.Fatal("stalled connection was kept")
+	}
+
+	// Commands fail with the context's error, without dropping the link
+	if err := c.Connect(context.Background(), cube.Address); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := c.SendCommandContext(ctx, protocol.CmdRequestState); !errors.Is(err, context.Canceled) {
+		t.Errorf("SendCommandContext with a cancelled context = %v, want context.Canceled", err)
+	}
+	if !c.IsConnected() {
+		t.Error("a cancelled command dropped the connection")
+	}
+	c.Disconnect()
+}
@@ -0,0 +1,142 @@
+package ble
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// SleepProbeTimeout is how long the cube has to answer a keep-alive probe
+// before it is considered asleep.
+const SleepProbeTimeout = 5 * time.Second
+
+// keepAliveTick is how often the keep-alive loop checks for inactivity.
+const keepAliveTick = time.Second
+
+// SetSleepCallback sets the callback fired when the cube stops answering
+// keep-alive probes (usually because it went to sleep).
+func (c *Client) SetSleepCallback(cb func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSleep = cb
+}
+
+// SetWakeCallback sets the callback fired when a sleeping cube sends a
+// message again.
+func (c *Client) SetWakeCallback(cb func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onWake = cb
+}
+
+// IsAsleep returns true if the cube stopped answering keep-alive probes.
+func (c *Client) IsAsleep() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.asleep
+}
+
+// LastActivity returns when the last message was received from the cube.
+func (c *Client) LastActivity() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastRx
+}
+
+// StartKeepAlive sends a benign battery request whenever the cube has been
+// quiet for interval, which keeps it from sleeping and detects when it has.
+// If a probe goes unanswered for SleepProbeTimeout, the sleep callback fires.
+// Calling it again replaces the running keep-alive.
+func (c *Client) StartKeepAlive(interval time.Duration) {
+	c.StopKeepAlive()
+	if interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	c.mu.Lock()
+	c.keepAliveStop = stop
+	c.mu.Unlock()
+
+	go c.keepAliveLoop(interval, stop)
+}
+
+// StopKeepAlive stops the keep-alive started by StartKeepAlive.
+func (c *Client) StopKeepAlive() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.keepAliveStop != nil {
+		close(c.keepAliveStop)
+		c.keepAliveStop = nil
+	}
+}
+
+func (c *Client) keepAliveLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(keepAliveTick)
+	defer ticker.Stop()
+
+	var probeAt time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if !c.IsConnected() {
+				continue
+			}
+			lastRx := c.LastActivity()
+
+			// Unanswered probe: the cube is asleep or out of range
+			if !probeAt.IsZero() && lastRx.Before(probeAt) && now.Sub(probeAt) >= SleepProbeTimeout {
+				c.markAsleep()
+			}
+
+			// Probe when quiet for a full interval (and keep probing while asleep)
+			if now.Sub(lastRx) >= interval && (probeAt.IsZero() || now.Sub(probeAt) >= interval) {
+				err := c.RequestBattery()
+				if errors.Is(err, protocol.ErrUnsupportedCommand) {
+					continue // The cube has nothing to probe with
+				}
+				if errors.Is(err, ErrConnectionLost) {
+					probeAt = time.Time{}
+					continue // Not asleep: the link dropped
+				}
+				probeAt = now
+				if err != nil {
+					c.markAsleep()
+				}
+			}
+		}
+	}
+}
+
+// markAsleep records that the cube is asleep, firing the callback once.
+func (c *Client) markAsleep() {
+	c.mu.Lock()
+	if c.asleep {
+		c.mu.Unlock()
+		return
+	}
+	c.asleep = true
+	cb := c.onSleep
+	c.mu.Unlock()
+
+	if cb != nil {
+		cb()
+	}
+}
+
+// markActive records a received message, waking the cube if it was asleep.
+func (c *Client) markActive() {
+	c.mu.Lock()
+	c.lastRx = time.Now()
+	wasAsleep := c.asleep
+	c.asleep = false
+	cb := c.onWake
+	c.mu.Unlock()
+
+	if wasAsleep && cb != nil {
+		cb()
+	}
+}
@@ -0,0 +1,254 @@
+package ble
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MockTransport is an in-memory Transport for tests. It advertises the
+// devices it was created with, records writes, and lets the test deliver
+// notifications and drop the link. With SetChaos, notifications go through
+// a faulty link first.
+type MockTransport struct {
+	mu           sync.Mutex
+	devices      []Advertisement
+	connected    string // Address of the connected device, "" if none
+	subs         map[string]func([]byte)
+	writes       []MockWrite
+	refuse       int // Connects to fail before one succeeds
+	stall        int // Connects to hang until their ctx is done
+	rssi         int16
+	onDisconnect func(string)
+	handlers     sync.WaitGroup // Notify calls still running their handler
+	chaos        *chaos         // Faulty link, if set
+}
+
+// MockWrite is a write recorded by MockTransport.
+type MockWrite struct {
+	Char string
+	Data []byte
+}
+
+// NewMockTransport creates a transport that advertises devices.
+func NewMockTransport(devices ...Advertisement) *MockTransport {
+	return &MockTransport{devices: devices}
+}
+
+// Scan reports each device once, then waits for ctx.
+func (t *MockTransport) Scan(ctx context.Context, found func(Advertisement)) error {
+	t.mu.Lock()
+	devices := append([]Advertisement(nil), t.devices...)
+	t.mu.Unlock()
+
+	for _, d := range devices {
+		found(d)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// Connect connects to an advertised device, unless RefuseConnects or
+// StallConnects asked for it to fail.
+func (t *MockTransport) Connect(ctx context.Context, address, service string, chars []string) error {
+	t.mu.Lock()
+	if t.stall > 0 {
+		t.stall--
+		t.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	defer t.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.refuse > 0 {
+		t.refuse--
+		return fmt.Errorf("failed to connect: %s refused", address)
+	}
+	for _, d := range t.devices {
+		if d.Address == address {
+			t.connected = address
+			t.subs = make(map[string]func([]byte))
+			return nil
+		}
+	}
+	return ErrDeviceNotFound
+}
+
+// WriteCharacteristic records the write.
+func (t *MockTransport) WriteCharacteristic(ctx context.Context, char string, data []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.connected == "" {
+		return ErrNotConnected
+	}
+	t.writes = append(t.writes, MockWrite{Char: char, Data: append([]byte(nil), data...)})
+	return nil
+}
+
+// Subscribe registers fn for notifications sent with Notify.
+func (t *MockTransport) Subscribe(ctx context.Context, char string, fn func(data []byte)) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if t.connected == "" {
+		return ErrNotConnected
+	}
+	t.subs[char] = fn
+	return nil
+}
+
+// Disconnect drops the connection. Like a BLE stack, it waits for the
+// notification handlers already running to return.
+func (t *MockTransport) Disconnect() error {
+	t.mu.Lock()
+	t.connected = ""
+	t.subs = nil
+	t.mu.Unlock()
+
+	t.handlers.Wait()
+	return nil
+}
+
+// SetDisconnectHandler sets the handler Drop calls.
+func (t *MockTransport) SetDisconnectHandler(fn func(address string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onDisconnect = fn
+}
+
+// Notify delivers data as a notification of char, as the connected cube
+// would. It reports whether anything was subscribed to char. With chaos
+// set, the notification may be lost, duplicated, reordered or delayed.
+func (t *MockTransport) Notify(char string, data []byte) bool {
+	t.mu.Lock()
+	if t.subs[char] == nil {
+		t.mu.Unlock()
+		return false
+	}
+	frames := []chaosFrame{{char: char, data: data}}
+	if t.chaos != nil {
+		frames = t.chaos.send(chaosFrame{char: char, data: append([]byte(nil), data...)})
+	}
+	t.handlers.Add(1)
+	t.mu.Unlock()
+
+	defer t.handlers.Done()
+	t.deliver(frames)
+	return true
+}
+
+// deliver calls the handlers subscribed to each frame's characteristic.
+func (t *MockTransport) deliver(frames []chaosFrame) {
+	for _, f := range frames {
+		t.mu.Lock()
+		fn := t.subs[f.char]
+		t.mu.Unlock()
+		if fn != nil {
+			fn(f.data)
+		}
+	}
+}
+
+// SetChaos sends later notifications through a faulty link configured by
+// cfg. Delays are measured on now, time.Now if nil.
+func (t *MockTransport) SetChaos(cfg ChaosConfig, now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.chaos = newChaos(cfg, now)
+}
+
+// FlushChaos delivers the notifications chaos still holds back, as when
+// the link settles.
+func (t *MockTransport) FlushChaos() {
+	t.mu.Lock()
+	if t.chaos == nil {
+		t.mu.Unlock()
+		return
+	}
+	frames := t.chaos.flush()
+	t.handlers.Add(1)
+	t.mu.Unlock()
+
+	defer t.handlers.Done()
+	t.deliver(frames)
+}
+
+// ChaosStats returns what chaos has done to the notifications so far.
+func (t *MockTransport) ChaosStats() ChaosStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.chaos == nil {
+		return ChaosStats{}
+	}
+	return t.chaos.stats
+}
+
+// Drop drops the link as if the cube went out of range, calling the
+// disconnect handler.
+func (t *MockTransport) Drop() {
+	t.mu.Lock()
+	address := t.connected
+	t.connected = ""
+	t.subs = nil
+	fn := t.onDisconnect
+	t.mu.Unlock()
+
+	if address != "" && fn != nil {
+		fn(address)
+	}
+}
+
+// RefuseConnects makes the next n connects fail.
+func (t *MockTransport) RefuseConnects(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refuse = n
+}
+
+// StallConnects makes the next n connects hang until their context is
+// done, as a cube that stopped answering would.
+func (t *MockTransport) StallConnects(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stall = n
+}
+
+// SetRSSI sets the signal strength ReadRSSI reports.
+func (t *MockTransport) SetRSSI(rssi int16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rssi = rssi
+}
+
+// ReadRSSI returns the signal strength set with SetRSSI.
+func (t *MockTransport) ReadRSSI() (int16, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.connected == "" {
+		return 0, ErrNotConnected
+	}
+	return t.rssi, nil
+}
+
+// Connected returns the address of the connected device, or "".
+func (t *MockTransport) Connected() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected
+}
+
+// Writes returns the writes recorded so far.
+func (t *MockTransport) Writes() []MockWrite {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]MockWrite(nil), t.writes...)
+}
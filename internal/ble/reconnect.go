@@ -0,0 +1,182 @@
+package ble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrConnectionLost is returned for commands that fail because the link to
+// the cube dropped.
+var ErrConnectionLost = errors.New("ble: connection lost")
+
+// reconnectTimeout bounds a single reconnection attempt unless the policy
+// sets AttemptTimeout.
+const reconnectTimeout = 10 * time.Second
+
+// ReconnectPolicy controls automatic reconnection after the link drops.
+type ReconnectPolicy struct {
+	Retries        int           // Attempts before giving up (0 disables auto-reconnect)
+	Backoff        time.Duration // Wait before the first attempt; doubles after each failure
+	MaxBackoff     time.Duration // Upper bound for the wait (0 = no bound)
+	AttemptTimeout time.Duration // Bound for each attempt (0 = 10s)
+}
+
+// DefaultReconnectPolicy retries five times, waiting 1s, 2s, 4s, 8s and 16s.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	Retries:        5,
+	Backoff:        time.Second,
+	MaxBackoff:     30 * time.Second,
+	AttemptTimeout: reconnectTimeout,
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAutoReconnect makes the client reconnect to the cube when the link
+// drops, following policy. See SetReconnectPolicy.
+func WithAutoReconnect(policy ReconnectPolicy) Option {
+	return func(c *Client) {
+		c.reconnect = policy
+	}
+}
+
+// SetReconnectPolicy sets how the client reconnects when the link drops.
+// On reconnection it re-subscribes to notifications, fires the reconnect
+// callback and then requests the cube state, so the state arrives after the
+// callback has run. A zero policy disables auto-reconnect.
+//
+// Drops are reported by the OS on macOS; elsewhere they are noticed when a
+// command fails, so use StartKeepAlive to notice them while the cube is idle.
+func (c *Client) SetReconnectPolicy(policy ReconnectPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconnect = policy
+}
+
+// SetReconnectCallback sets the callback fired when auto-reconnect finishes:
+// with nil once the link is back, or with an error after the last attempt
+// failed.
+func (c *Client) SetReconnectCallback(cb func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReconnect = cb
+}
+
+// IsReconnecting returns true while auto-reconnect is in progress.
+func (c *Client) IsReconnecting() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reconnectStop != nil
+}
+
+// watchConnection reports an unexpected disconnect of the device at uuid.
+// It must be registered before connecting.
+func (c *Client) watchConnection(uuid string) {
+	c.transport.SetDisconnectHandler(func(address string) {
+		if address == uuid {
+			c.connectionLost()
+		}
+	})
+}
+
+// connectionLost handles a dropped link: it fires the disconnect callback
+// and starts reconnecting if a policy is set. Drops after Disconnect, or
+// while already reconnecting, are ignored.
+func (c *Client) connectionLost() {
+	c.mu.Lock()
+	if !c.connected {
+		c.mu.Unlock()
+		return
+	}
+	c.connected = false
+	policy := c.reconnect
+	target := c.target
+	var stop chan struct{}
+	if policy.Retries > 0 {
+		stop = make(chan struct{})
+		c.reconnectStop = stop
+	}
+	cb := c.onDisconnect
+	c.mu.Unlock()
+
+	// Release whatever the OS still holds for the old link
+	c.transport.Disconnect()
+
+	if cb != nil {
+		cb()
+	}
+	if stop != nil {
+		go c.reconnectLoop(target, policy, stop)
+	}
+}
+
+func (c *Client) reconnectLoop(target ScanResult, policy ReconnectPolicy, stop chan struct{}) {
+	wait := policy.Backoff
+	timeout := policy.AttemptTimeout
+	if timeout <= 0 {
+		timeout = reconnectTimeout
+	}
+	var err error
+	for attempt := 1; attempt <= policy.Retries; attempt++ {
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+
+		// Disconnect abandons the attempt in flight
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		go func() {
+			select {
+			case <-stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		err = c.ConnectToResult(ctx, target)
+		cancel()
+		if err == nil {
+			break
+		}
+
+		wait *= 2
+		if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+			wait = policy.MaxBackoff
+		}
+	}
+
+	c.mu.Lock()
+	select {
+	case <-stop:
+		// Disconnect was called while the last attempt was connecting
+		c.mu.Unlock()
+		if err == nil {
+			c.Disconnect()
+		}
+		return
+	default:
+	}
+	c.reconnectStop = nil
+	cb := c.onReconnect
+	c.mu.Unlock()
+
+	if err != nil {
+		err = fmt.Errorf("%w: gave up after %d attempts: %v", ErrConnectionLost, policy.Retries, err)
+	}
+	if cb != nil {
+		cb(err)
+	}
+	if err == nil {
+		c.RequestState()
+	}
+}
+
+// stopReconnect stops a running auto-reconnect. The caller must hold c.mu.
+func (c *Client) stopReconnect() {
+	if c.reconnectStop != nil {
+		close(c.reconnectStop)
+		c.reconnectStop = nil
+	}
+}
@@ -0,0 +1,175 @@
+package ble
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// SmartCube is a per-vendor protocol driver. It names the GATT service and
+// characteristics of a cube model by UUID and translates the vendor's notifications
+// and commands to and from GoCube protocol messages, so everything above
+// the Client works the same for every supported cube.
+type SmartCube interface {
+	Vendor() string
+	Service() string
+	NotifyChar() string
+	WriteChar() string
+
+	// Decode translates one notification into zero or more messages.
+	Decode(data []byte) ([]*protocol.Message, error)
+
+	// Encode translates a GoCube command code into the vendor's bytes, or
+	// returns protocol.ErrUnsupportedCommand.
+	Encode(cmd byte) ([]byte, error)
+}
+
+// Vendor names reported in ScanResult.Vendor
+const (
+	VendorGoCube = "GoCube"
+	VendorGAN    = "GAN"
+	VendorMoyu   = "MoYu"
+)
+
+// vendor describes how to recognize a cube model and create its driver.
+type vendor struct {
+	name   string
+	prefix []string // Advertised name prefixes, lower case
+	open   func(result ScanResult) (SmartCube, error)
+}
+
+// vendors lists the supported cubes. Drivers may keep per-connection state,
+// so open creates a fresh one for every connection.
+var vendors = []vendor{
+	{
+		name:   VendorGoCube,
+		prefix: []string{"gocube"},
+		open: func(ScanResult) (SmartCube, error) {
+			return &goCubeDriver{}, nil
+		},
+	},
+	{
+		name:   VendorGAN,
+		prefix: []string{"gan", "mg3"},
+		open: func(r ScanResult) (SmartCube, error) {
+			return newGANDriver(VendorGAN, r, false)
+		},
+	},
+	{
+		name:   VendorMoyu,
+		prefix: []string{"aicube", "mhc"},
+		open: func(r ScanResult) (SmartCube, error) {
+			if strings.HasPrefix(strings.ToLower(r.Name), "aicube") {
+				return newGANDriver(VendorMoyu, r, true) // MoYu AI 2023
+			}
+			return &moyuDriver{codec: protocol.NewMoyuCodec()}, nil
+		},
+	},
+}
+
+// vendorFor returns the vendor whose cubes advertise name, or nil.
+func vendorFor(name string) *vendor {
+	lower := strings.ToLower(name)
+	for i := range vendors {
+		for _, p := range vendors[i].prefix {
+			if strings.HasPrefix(lower, p) {
+				return &vendors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// openSmartCube creates the driver for a scanned cube.
+func openSmartCube(result ScanResult) (SmartCube, error) {
+	v := vendorFor(result.Name)
+	if v == nil {
+		return nil, fmt.Errorf("unsupported cube %q", result.Name)
+	}
+	return v.open(result)
+}
+
+// goCubeDriver speaks the native GoCube protocol.
+type goCubeDriver struct{}
+
+func (d *goCubeDriver) Vendor() string     { return VendorGoCube }
+func (d *goCubeDriver) Service() string    { return protocol.ServiceUUID }
+func (d *goCubeDriver) NotifyChar() string { return protocol.TxCharUUID }
+func (d *goCubeDriver) WriteChar() string  { return protocol.RxCharUUID }
+
+func (d *goCubeDriver) Decode(data []byte) ([]*protocol.Message, error) {
+	msg, err := protocol.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*protocol.Message{msg}, nil
+}
+
+func (d *goCubeDriver) Encode(cmd byte) ([]byte, error) {
+	return protocol.BuildCommand(cmd), nil
+}
+
+// ganDriver speaks the encrypted GAN Gen2 protocol.
+type ganDriver struct {
+	vendor string
+	codec  *protocol.GANCodec
+}
+
+func newGANDriver(vendor string, r ScanResult, moyu bool) (*ganDriver, error) {
+	if r.MAC == "" {
+		return nil, fmt.Errorf("%s: MAC address not advertised, cannot derive the encryption key", r.Name)
+	}
+	codec, err := protocol.NewGANCodec(r.MAC, moyu)
+	if err != nil {
+		return nil, err
+	}
+	return &ganDriver{vendor: vendor, codec: codec}, nil
+}
+
+func (d *ganDriver) Vendor() string     { return d.vendor }
+func (d *ganDriver) Service() string    { return protocol.GANServiceUUID }
+func (d *ganDriver) NotifyChar() string { return protocol.GANStateCharUUID }
+func (d *ganDriver) WriteChar() string  { return protocol.GANCommandCharUUID }
+
+func (d *ganDriver) Decode(data []byte) ([]*protocol.Message, error) {
+	return d.codec.Decode(data)
+}
+
+func (d *ganDriver) Encode(cmd byte) ([]byte, error) {
+	return d.codec.Encode(cmd)
+}
+
+// moyuDriver speaks the original MoYu AI protocol.
+type moyuDriver struct {
+	codec *protocol.MoyuCodec
+}
+
+func (d *moyuDriver) Vendor() string     { return VendorMoyu }
+func (d *moyuDriver) Service() string    { return protocol.MoyuServiceUUID }
+func (d *moyuDriver) NotifyChar() string { return protocol.MoyuTurnCharUUID }
+func (d *moyuDriver) WriteChar() string  { return protocol.MoyuWriteCharUUID }
+
+func (d *moyuDriver) Decode(data []byte) ([]*protocol.Message, error) {
+	return d.codec.Decode(data)
+}
+
+func (d *moyuDriver) Encode(cmd byte) ([]byte, error) {
+	return d.codec.Encode(cmd)
+}
+
+// advertisedMAC returns the MAC address of a scanned cube. GAN cubes carry
+// it at the end of their manufacturer data, which matters on macOS where
+// the address is an opaque UUID; elsewhere the address is the MAC.
+func advertisedMAC(adv Advertisement) string {
+	for _, md := range adv.ManufacturerData {
+		if md.CompanyID&0xFF == 0x01 && len(md.Data) >= 6 {
+			d := md.Data[len(md.Data)-6:]
+			return fmt.Sprintf("%02X:%02X:%02X:%02X:%02X:%02X", d[5], d[4], d[3], d[2], d[1], d[0])
+		}
+	}
+	if addr := adv.Address; len(addr) == 17 && strings.Count(addr, ":") == 5 {
+		return strings.ToUpper(addr)
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package ble
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// TinyGoTransport is the Transport backed by tinygo.org/x/bluetooth, which
+// drives CoreBluetooth on macOS, BlueZ on Linux and WinRT on Windows.
+type TinyGoTransport struct {
+	adapter *bluetooth.Adapter
+
+	mu        sync.Mutex
+	addresses map[string]bluetooth.Address // Scanned devices by address string
+	device    *bluetooth.Device
+	chars     map[string]bluetooth.DeviceCharacteristic // By requested UUID
+}
+
+// NewTinyGoTransport enables the default Bluetooth adapter.
+func NewTinyGoTransport() (*TinyGoTransport, error) {
+	adapter := bluetooth.DefaultAdapter
+	if err := adapter.Enable(); err != nil {
+		return nil, fmt.Errorf("failed to enable BLE adapter: %w", err)
+	}
+	return &TinyGoTransport{
+		adapter:   adapter,
+		addresses: make(map[string]bluetooth.Address),
+	}, nil
+}
+
+// Scan reports advertisements until ctx is done.
+func (t *TinyGoTransport) Scan(ctx context.Context, found func(Advertisement)) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- t.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
+			addr := result.Address.String()
+			t.mu.Lock()
+			t.addresses[addr] = result.Address
+			t.mu.Unlock()
+
+			adv := Advertisement{
+				Name:    result.LocalName(),
+				Address: addr,
+				RSSI:    result.RSSI,
+			}
+			for _, md := range result.ManufacturerData() {
+				adv.ManufacturerData = append(adv.ManufacturerData, ManufacturerData{CompanyID: md.CompanyID, Data: md.Data})
+			}
+			found(adv)
+		})
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	t.adapter.StopScan()
+	<-done
+	return nil
+}
+
+// Connect connects to a scanned device and discovers service and chars.
+func (t *TinyGoTransport) Connect(ctx context.Context, address, service string, chars []string) error {
+	t.mu.Lock()
+	addr, ok := t.addresses[address]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s has not been scanned", ErrDeviceNotFound, address)
+	}
+
+	serviceUUID, err := bluetooth.ParseUUID(service)
+	if err != nil {
+		return err
+	}
+	charUUIDs := make([]bluetooth.UUID, len(chars))
+	for i, ch := range chars {
+		if charUUIDs[i], err = bluetooth.ParseUUID(ch); err != nil {
+			return err
+		}
+	}
+
+	device, err := t.adapter.Connect(addr, bluetooth.ConnectionParams{})
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+
+	services, err := device.DiscoverServices([]bluetooth.UUID{serviceUUID})
+	if err != nil {
+		device.Disconnect()
+		return fmt.Errorf("failed to discover services: %w", err)
+	}
+	if len(services) == 0 {
+		device.Disconnect()
+		return fmt.Errorf("service %s not found", service)
+	}
+
+	discovered, err := services[0].DiscoverCharacteristics(charUUIDs)
+	if err != nil {
+		device.Disconnect()
+		return fmt.Errorf("failed to discover characteristics: %w", err)
+	}
+
+	found := make(map[string]bluetooth.DeviceCharacteristic)
+	for _, ch := range discovered {
+		for i, u := range charUUIDs {
+			if ch.UUID() == u {
+				found[chars[i]] = ch
+			}
+		}
+	}
+
+	t.mu.Lock()
+	t.device = &device
+	t.chars = found
+	t.mu.Unlock()
+	return nil
+}
+
+// characteristic returns the discovered characteristic char.
+func (t *TinyGoTransport) characteristic(char string) (bluetooth.DeviceCharacteristic, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.device == nil {
+		return bluetooth.DeviceCharacteristic{}, ErrNotConnected
+	}
+	ch, ok := t.chars[char]
+	if !ok {
+		return bluetooth.DeviceCharacteristic{}, fmt.Errorf("characteristic %s not found", char)
+	}
+	return ch, nil
+}
+
+// WriteCharacteristic writes data without response, falling back to a
+// write with response.
+func (t *TinyGoTransport) WriteCharacteristic(char string, data []byte) error {
+	ch, err := t.characteristic(char)
+	if err != nil {
+		return err
+	}
+	_, err = ch.WriteWithoutResponse(data)
+	if err != nil {
+		_, err = ch.Write(data)
+	}
+	return err
+}
+
+// Subscribe enables notifications of char.
+func (t *TinyGoTransport) Subscribe(char string, fn func(data []byte)) error {
+	ch, err := t.characteristic(char)
+	if err != nil {
+		return err
+	}
+	return ch.EnableNotifications(fn)
+}
+
+// Disconnect drops the connection.
+func (t *TinyGoTransport) Disconnect() error {
+	t.mu.Lock()
+	device := t.device
+	t.device = nil
+	t.chars = nil
+	t.mu.Unlock()
+
+	if device == nil {
+		return nil
+	}
+	// Unlocked: the adapter may report the disconnect synchronously
+	return device.Disconnect()
+}
+
+// SetDisconnectHandler reports dropped links. The handler is adapter wide,
+// and only macOS reports drops.
+func (t *TinyGoTransport) SetDisconnectHandler(fn func(address string)) {
+	t.adapter.SetConnectHandler(func(device bluetooth.Device, connected bool) {
+		if !connected {
+			fn(device.Address.String())
+		}
+	})
+}
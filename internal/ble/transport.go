@@ -0,0 +1,93 @@
+package ble
+
+import "context"
+
+// Transport is the Bluetooth backend a Client reaches cubes through. The
+// default, TinyGoTransport, uses tinygo.org/x/bluetooth; other backends
+// (BlueZ over D-Bus, WinRT) or MockTransport in tests are plugged in with
+// WithTransport. A transport holds at most one connection, as a Client
+// does. Services and characteristics are named by their UUID strings.
+//
+// Blocking calls take a context and return ctx.Err() once it is done, even
+// if the backend cannot cancel the operation itself (see awaitContext).
+type Transport interface {
+	// Scan reports advertisements to found until ctx is done. A device may
+	// be reported more than once, and found may be called concurrently.
+	Scan(ctx context.Context, found func(Advertisement)) error
+
+	// Connect connects to the device at address, found by an earlier
+	// Scan, and discovers service and its characteristics chars. If ctx
+	// is done first, the device is left disconnected.
+	Connect(ctx context.Context, address, service string, chars []string) error
+
+	// WriteCharacteristic writes data to characteristic char of the
+	// connected device.
+	WriteCharacteristic(ctx context.Context, char string, data []byte) error
+
+	// Subscribe calls fn with each notification of characteristic char.
+	Subscribe(ctx context.Context, char string, fn func(data []byte)) error
+
+	// Disconnect drops the connection, if any.
+	Disconnect() error
+
+	// SetDisconnectHandler sets fn to be called with the address of a
+	// device whose link dropped without Disconnect. Backends that cannot
+	// tell never call it; the Client then notices on the next failed write.
+	SetDisconnectHandler(fn func(address string))
+}
+
+// RSSIReader is implemented by transports that can read the signal
+// strength of the connection. Client.ReadRSSI uses it when available.
+type RSSIReader interface {
+	// ReadRSSI returns the signal strength of the connected device in dBm.
+	ReadRSSI() (int16, error)
+}
+
+// Advertisement is a device seen while scanning.
+type Advertisement struct {
+	Name             string
+	Address          string // Opaque UUID on macOS, MAC address elsewhere
+	RSSI             int16
+	ManufacturerData []ManufacturerData
+}
+
+// ManufacturerData is one manufacturer specific element of an advertisement.
+type ManufacturerData struct {
+	CompanyID uint16
+	Data      []byte
+}
+
+// awaitContext runs fn, a call the backend cannot cancel, and waits for it
+// or ctx. If ctx is done first it returns ctx.Err(), and once fn finishes
+// without error undo (if not nil) is called to reverse it, e.g. to drop a
+// connection that came too late.
+func awaitContext(ctx context.Context, fn func() error, undo func()) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if undo != nil {
+			go func() {
+				if <-done == nil {
+					undo()
+				}
+			}()
+		}
+		return ctx.Err()
+	}
+}
+
+// WithTransport makes the client use transport instead of the default
+// TinyGoTransport.
+func WithTransport(transport Transport) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
@@ -5,14 +5,21 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // RotationEvent represents a single face rotation from the cube.
 type RotationEvent struct {
 	FaceCode          byte   // Raw face+direction code (0x00-0x0B)
-	CenterOrientation byte   // Center piece orientation
+	CenterOrientation byte   // Center piece orientation, or CenterUnknown
 	Clockwise         bool   // Direction of rotation
 	Color             string // Color name (blue, green, white, yellow, red, orange)
+
+	// Elapsed is the time the cube measured since its previous turn, for
+	// cubes that report it (GAN Gen2 and MoYu AI); HasElapsed is false for
+	// the rest.
+	Elapsed    time.Duration
+	HasElapsed bool
 }
 
 // BatteryEvent represents a battery level notification.
@@ -38,6 +45,17 @@ type OrientationEvent struct {
 	FrontFace string // Which face is facing the solver
 }
 
+// StateEvent represents a full cube state notification.
+type StateEvent struct {
+	// Facelets[face][position] holds protocol color indices (see colorNames),
+	// with faces in protocol color order and positions in the 3x3 row-major
+	// layout used by gocube.Cube.
+	Facelets [6][9]byte
+
+	// CenterOrientation holds the per-face center rotation, if reported.
+	CenterOrientation [6]byte
+}
+
 // OfflineStatsEvent represents offline statistics.
 type OfflineStatsEvent struct {
 	Moves  int
@@ -56,7 +74,10 @@ var colorNames = map[byte]string{
 }
 
 // DecodeRotation decodes a rotation message payload into rotation events.
-// Rotation payloads contain pairs of bytes: [face_dir] [center_orientation]
+// Rotation payloads contain pairs of bytes: [face_dir] [center_orientation].
+// Payloads translated from cubes with their own clock end with the time
+// since each turn's previous one: [RotationTimesMarker] [count] then count
+// big-endian uint16 milliseconds, NoRotationTime where unknown.
 func DecodeRotation(payload []byte) ([]RotationEvent, error) {
 	if len(payload)%2 != 0 {
 		return nil, fmt.Errorf("rotation payload must have even length, got %d", len(payload))
@@ -67,6 +88,13 @@ func DecodeRotation(payload []byte) ([]RotationEvent, error) {
 		faceCode := payload[i]
 		centerOrient := payload[i+1]
 
+		if faceCode == RotationTimesMarker {
+			if err := decodeRotationTimes(events, payload[i+2:], int(centerOrient)); err != nil {
+				return nil, err
+			}
+			break
+		}
+
 		// Face codes: 0x00-0x0B
 		// Even codes (0,2,4,6,8,10) = clockwise
 		// Odd codes (1,3,5,7,9,11) = counter-clockwise
@@ -89,6 +117,77 @@ func DecodeRotation(payload []byte) ([]RotationEvent, error) {
 	return events, nil
 }
 
+// RotationTimesMarker starts the turn times at the end of a rotation
+// payload, in place of a face code.
+const RotationTimesMarker byte = 0xFF
+
+// NoRotationTime is the turn time of a turn the cube did not time.
+const NoRotationTime = 0xFFFF
+
+// decodeRotationTimes sets the elapsed times of events from the count
+// times in data.
+func decodeRotationTimes(events []RotationEvent, data []byte, count int) error {
+	if count != len(events) || len(data) != 2*count {
+		return fmt.Errorf("rotation times: %d for %d rotations in %d bytes", count, len(events), len(data))
+	}
+	for i := range events {
+		ms := int(data[2*i])<<8 | int(data[2*i+1])
+		if ms != NoRotationTime {
+			events[i].Elapsed = time.Duration(ms) * time.Millisecond
+			events[i].HasElapsed = true
+		}
+	}
+	return nil
+}
+
+// stateFaceletOrder maps the order facelets appear in a state payload face
+// block (center first, then the ring clockwise from top-left) to 3x3
+// row-major positions.
+var stateFaceletOrder = [9]int{4, 0, 1, 2, 5, 8, 7, 6, 3}
+
+// DecodeState decodes a state message payload.
+// Format: 6 face blocks of 9 color bytes in protocol color order (blue,
+// green, white, yellow, red, orange), optionally followed by 6 center
+// orientation bytes.
+func DecodeState(payload []byte) (*StateEvent, error) {
+	if len(payload) < 54 {
+		return nil, fmt.Errorf("state payload too short: expected at least 54 bytes, got %d", len(payload))
+	}
+
+	event := &StateEvent{}
+	for face := 0; face < 6; face++ {
+		for i := 0; i < 9; i++ {
+			color := payload[face*9+i]
+			if _, ok := colorNames[color]; !ok {
+				return nil, fmt.Errorf("unknown color index %d at face %d position %d", color, face, i)
+			}
+			event.Facelets[face][stateFaceletOrder[i]] = color
+		}
+	}
+
+	if len(payload) >= 60 {
+		copy(event.CenterOrientation[:], payload[54:60])
+	}
+
+	return event, nil
+}
+
+// CenterUnknown is the center orientation of rotations from cubes that do
+// not report it (GAN and MoYu).
+const CenterUnknown byte = 0xFF
+
+// CenterQuarterTurns converts a reported center orientation (0x00, 0x03,
+// 0x06 or 0x09: three steps per clockwise quarter turn) to quarter turns
+// from solved (0-3).
+func CenterQuarterTurns(orientation byte) int {
+	return int(orientation/3) % 4
+}
+
+// ColorName returns the color name for a protocol color index.
+func ColorName(index byte) string {
+	return colorNames[index]
+}
+
 // DecodeBattery decodes a battery message payload.
 func DecodeBattery(payload []byte) (*BatteryEvent, error) {
 	if len(payload) < 1 {
@@ -155,6 +254,25 @@ func DecodeOrientation(payload []byte) (*OrientationEvent, error) {
 	return event, nil
 }
 
+// OrientationCorrection is the quaternion a cube reports when held in the
+// home pose (white up, green front). Sensors drift between cubes and power
+// cycles; faces derived relative to this reference match the home pose.
+type OrientationCorrection struct {
+	X, Y, Z, W float64
+}
+
+// Correct re-derives the faces of ev relative to the reference pose. The
+// raw quaternion in ev is left unchanged.
+func (c OrientationCorrection) Correct(ev *OrientationEvent) {
+	// Relative rotation: conjugate(reference) * reported
+	rx, ry, rz, rw := -c.X, -c.Y, -c.Z, c.W
+	x := rw*ev.X + rx*ev.W + ry*ev.Z - rz*ev.Y
+	y := rw*ev.Y - rx*ev.Z + ry*ev.W + rz*ev.X
+	z := rw*ev.Z + rx*ev.Y - ry*ev.X + rz*ev.W
+	w := rw*ev.W - rx*ev.X - ry*ev.Y - rz*ev.Z
+	ev.UpFace, ev.FrontFace = quaternionToFaces(x, y, z, w)
+}
+
 // extractNumeric extracts the leading numeric portion (including optional minus sign) from a string.
 func extractNumeric(s string) string {
 	var result strings.Builder
@@ -55,6 +55,10 @@ var (
 	ErrInvalidChecksum = errors.New("protocol: invalid checksum")
 	ErrMessageTooShort = errors.New("protocol: message too short")
 	ErrInvalidLength   = errors.New("protocol: invalid message length")
+
+	// ErrUnsupportedCommand is returned when a cube has no equivalent of a
+	// GoCube command.
+	ErrUnsupportedCommand = errors.New("protocol: command not supported by this cube")
 )
 
 // Message represents a parsed GoCube BLE message.
@@ -126,6 +130,67 @@ func BuildCommand(cmdCode byte) []byte {
 	return []byte{FramePrefix, length, cmdCode, checksum, FrameSuffix1, FrameSuffix2}
 }
 
+// BuildMessage frames a message as the cube would send it.
+// Format: [0x2A] [length] [type] [payload...] [checksum] [0x0D] [0x0A]
+func BuildMessage(msgType byte, payload []byte) []byte {
+	length := byte(len(payload) + 4) // type + payload + checksum + suffix
+
+	data := make([]byte, 0, len(payload)+6)
+	data = append(data, FramePrefix, length, msgType)
+	data = append(data, payload...)
+
+	var checksum byte
+	for _, b := range data {
+		checksum += b
+	}
+
+	return append(data, checksum, FrameSuffix1, FrameSuffix2)
+}
+
+// newMessage builds a Message as if the cube had sent it. Drivers for other
+// vendors use it so their events are stored and replayed as GoCube frames.
+func newMessage(msgType byte, payload []byte) *Message {
+	return &Message{
+		Type:      msgType,
+		Payload:   payload,
+		RawBase64: base64.StdEncoding.EncodeToString(BuildMessage(msgType, payload)),
+	}
+}
+
+// faceColors maps face letters to protocol color indices.
+var faceColors = map[byte]byte{'B': 0, 'F': 1, 'U': 2, 'D': 3, 'R': 4, 'L': 5}
+
+// faceRotationCode returns the rotation face code for the face at index in
+// order (e.g. "URFDLB").
+func faceRotationCode(order string, index int, ccw bool) (byte, bool) {
+	if index < 0 || index >= len(order) {
+		return 0, false
+	}
+	color, ok := faceColors[order[index]]
+	if !ok {
+		return 0, false
+	}
+	code := color * 2
+	if ccw {
+		code++
+	}
+	return code, true
+}
+
+// appendRotationTimes ends a rotation payload with the time since each
+// turn's previous one, in milliseconds or NoRotationTime (see
+// DecodeRotation).
+func appendRotationTimes(rotations []byte, elapsedMs []int) []byte {
+	rotations = append(rotations, RotationTimesMarker, byte(len(elapsedMs)))
+	for _, ms := range elapsedMs {
+		if ms < 0 || ms > NoRotationTime {
+			ms = NoRotationTime
+		}
+		rotations = append(rotations, byte(ms>>8), byte(ms))
+	}
+	return rotations
+}
+
 // TypeName returns a human-readable name for the message type.
 func TypeName(msgType byte) string {
 	switch msgType {
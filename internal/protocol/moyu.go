@@ -0,0 +1,97 @@
+package protocol
+
+// MoYu AI BLE Service and Characteristic UUIDs (the original MoYu AI cube;
+// the MoYu AI 2023 speaks the GAN Gen2 protocol)
+const (
+	MoyuServiceUUID   = "00001000-0000-1000-8000-00805f9b34fb"
+	MoyuWriteCharUUID = "00001001-0000-1000-8000-00805f9b34fb" // Write
+	MoyuTurnCharUUID  = "00001003-0000-1000-8000-00805f9b34fb" // Notify
+)
+
+// moyuFaces maps MoYu face indices to faces in "URFDLB" order.
+var moyuFaces = [6]int{3, 4, 5, 1, 2, 0}
+
+// MoyuCodec translates MoYu AI turn notifications to GoCube messages. The
+// cube reports face angles in steps, nine to a quarter turn, so a codec
+// tracks face angles and must be used for one connection only.
+type MoyuCodec struct {
+	faceSteps [6]int // 0-8; a quarter turn crosses the 4/5 boundary
+
+	lastTurn    uint32 // Cube clock at the previous quarter turn
+	hasLastTurn bool
+}
+
+// NewMoyuCodec creates a codec with all faces at rest.
+func NewMoyuCodec() *MoyuCodec {
+	return &MoyuCodec{}
+}
+
+// Decode translates a turn notification into a rotation message. Format:
+// [count] then count 6-byte records [timestamp x4] [face] [signed angle],
+// the timestamp in 1/65536 s with its 16-bit halves little-endian and the
+// angle in units of 36 (one step).
+func (c *MoyuCodec) Decode(data []byte) ([]*Message, error) {
+	if len(data) < 1 {
+		return nil, ErrMessageTooShort
+	}
+	count := int(data[0])
+	if len(data) < 1+count*6 {
+		return nil, ErrInvalidLength
+	}
+
+	var rotations []byte
+	var elapsedMs []int
+	for i := 0; i < count; i++ {
+		rec := data[1+i*6:]
+		face := int(rec[4])
+		if face >= len(c.faceSteps) {
+			continue
+		}
+
+		steps := int(int8(rec[5]))
+		steps = (steps + sign(steps)*18) / 36 // Round to the nearest step
+
+		prev := c.faceSteps[face]
+		cur := prev + steps
+		c.faceSteps[face] = ((cur % 9) + 9) % 9
+
+		var ccw bool
+		switch {
+		case prev >= 5 && cur <= 4:
+			ccw = true
+		case prev <= 4 && cur >= 5:
+			ccw = false
+		default:
+			continue // Still within the same quarter
+		}
+		code, _ := faceRotationCode("URFDLB", moyuFaces[face], ccw)
+		rotations = append(rotations, code, CenterUnknown)
+
+		ts := uint32(rec[1])<<24 | uint32(rec[0])<<16 | uint32(rec[3])<<8 | uint32(rec[2])
+		ms := NoRotationTime
+		if c.hasLastTurn {
+			ms = int(uint64(ts-c.lastTurn) * 1000 / 65536)
+		}
+		elapsedMs = append(elapsedMs, ms)
+		c.lastTurn, c.hasLastTurn = ts, true
+	}
+
+	if len(rotations) == 0 {
+		return nil, nil
+	}
+	rotations = appendRotationTimes(rotations, elapsedMs)
+	return []*Message{newMessage(MsgTypeRotation, rotations)}, nil
+}
+
+// Encode returns the MoYu command for a GoCube command code. The MoYu AI
+// only streams turns, so every command is unsupported.
+func (c *MoyuCodec) Encode(cmd byte) ([]byte, error) {
+	return nil, ErrUnsupportedCommand
+}
+
+func sign(n int) int {
+	if n < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,39 @@
+package gocube
+
+import "time"
+
+// LightPattern is a backlight effect a GoCube can play.
+type LightPattern int
+
+const (
+	LightNone      LightPattern = iota // No effect
+	LightFlash                         // Three quick flashes
+	LightSlowFlash                     // Three slow flashes
+	LightCelebrate                     // The animated backlight for CelebrateDuration
+)
+
+// CelebrateDuration is how long LightCelebrate runs the animated backlight.
+const CelebrateDuration = 3 * time.Second
+
+// String returns the pattern's name.
+func (p LightPattern) String() string {
+	switch p {
+	case LightNone:
+		return "none"
+	case LightFlash:
+		return "flash"
+	case LightSlowFlash:
+		return "slow_flash"
+	case LightCelebrate:
+		return "celebrate"
+	default:
+		return "unknown"
+	}
+}
+
+// LEDPolicy declares backlight effects a GoCube plays by itself as a solve
+// progresses (see WithLEDPolicy). The zero value plays nothing.
+type LEDPolicy struct {
+	PhaseComplete LightPattern // When a phase other than solved is completed
+	Solved        LightPattern // When the cube is solved
+}
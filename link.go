@@ -0,0 +1,269 @@
+package gocube
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// LinkStats describes the health of the BLE link to a cube, as returned by
+// GoCube.LinkStats. Timing comes from the orientation stream, which the
+// cube sends at a steady rate while orientation is enabled; with it off,
+// Interval, Jitter, MaxGap and Delay stay zero.
+type LinkStats struct {
+	Since         time.Time // When the link was established
+	Notifications int       // Messages received
+	LastMessage   time.Time // When the last message arrived
+	Reconnects    int       // Times the link was re-established
+
+	Interval time.Duration // Typical interval of the orientation stream
+	Jitter   time.Duration // Mean deviation of the intervals from Interval
+	MaxGap   time.Duration // Longest stall of the orientation stream
+	Delay    time.Duration // Current delivery delay above the lowest seen recently
+
+	// Duplicates counts rotation notifications delivered twice, which are
+	// discarded. MissedTurns counts turns the centers the cube reports
+	// show were never received. Both need center reports, so they stay
+	// zero on GAN and MoYu cubes; GAN cubes recover missed turns from
+	// their move counter instead.
+	Duplicates  int
+	MissedTurns int
+
+	RSSI        int16        // Latest signal strength in dBm; 0 if never read
+	RSSIHistory []RSSISample // Oldest first, at most the last 120
+}
+
+// RSSISample is a signal strength reading.
+type RSSISample struct {
+	Time time.Time
+	RSSI int16 // dBm
+}
+
+const (
+	// rssiHistorySize bounds LinkStats.RSSIHistory.
+	rssiHistorySize = 120
+
+	// streamRestart is the orientation interval past which the stream is
+	// taken to have stopped (orientation disabled, cube asleep) rather
+	// than stalled, and timing starts over.
+	streamRestart = 2 * time.Second
+
+	// delayWindow is how many orientation samples the lowest delivery
+	// delay is taken over.
+	delayWindow = 64
+)
+
+// linkTracker follows the link quality from the messages a cube sends.
+//
+// Delivery delay is measured against the orientation stream: the cube
+// sends it at a steady interval, so a sample arriving late by some amount
+// was held up by the link by that much more than the one before. Summing
+// those differences gives each sample's delay relative to the others; the
+// delay above the lowest of the recent samples is the backlog the link is
+// currently adding, which timestamp smoothing takes off the moves.
+type linkTracker struct {
+	mu sync.Mutex
+
+	since         time.Time
+	notifications int
+	lastMessage   time.Time
+	reconnects    int
+
+	lastSample time.Time     // Arrival of the last orientation sample
+	interval   float64       // Smoothed orientation interval, ns; 0 until measured
+	jitter     float64       // Smoothed deviation from interval, ns
+	maxGap     time.Duration // Longest interval below streamRestart
+	delay      float64       // Relative delay of the last sample, ns
+	delays     [delayWindow]float64
+	delayCount int // Samples in delays
+
+	lastRotation []byte    // Payload of the last rotation notification
+	centers      [6]int    // Last reported center of each face
+	centerSeen   [6]bool   // Whether centers[face] has been reported
+	lastMoveTime time.Time // Latest smoothed move time handed out
+	duplicates   int
+	missedTurns  int
+
+	rssi []RSSISample
+}
+
+func newLinkTracker(now time.Time) *linkTracker {
+	return &linkTracker{since: now}
+}
+
+// message records the arrival of any message.
+func (t *linkTracker) message(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notifications++
+	t.lastMessage = at
+}
+
+// orientation records the arrival of an orientation sample.
+func (t *linkTracker) orientation(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.lastSample
+	t.lastSample = at
+	if prev.IsZero() {
+		return
+	}
+	gap := at.Sub(prev)
+	if gap <= 0 {
+		return
+	}
+	if gap > streamRestart {
+		t.restartStreamLocked()
+		return
+	}
+	if gap > t.maxGap {
+		t.maxGap = gap
+	}
+
+	d := float64(gap)
+	if t.interval == 0 {
+		t.interval = d
+		return
+	}
+	dev := d - t.interval
+	if dev < 0 {
+		dev = -dev
+	}
+	// The RFC 3550 jitter estimator, with the stream's interval standing in
+	// for the send times
+	t.jitter += (dev - t.jitter) / 16
+	// Stalls longer than a few intervals are not the stream's rate
+	if d < 4*t.interval {
+		t.interval += (d - t.interval) / 16
+	}
+
+	t.delay += d - t.interval
+	t.delays[t.delayCount%delayWindow] = t.delay
+	t.delayCount++
+}
+
+// restartStreamLocked forgets the orientation stream's delay history, e.g.
+// after it stopped. The caller must hold mu.
+func (t *linkTracker) restartStreamLocked() {
+	t.delay = 0
+	t.delayCount = 0
+}
+
+// excessDelayLocked returns the delay of the last orientation sample above
+// the lowest recent one, or 0 if the stream is not running. The caller
+// must hold mu.
+func (t *linkTracker) excessDelayLocked(now time.Time) time.Duration {
+	if t.delayCount == 0 || now.Sub(t.lastSample) > streamRestart {
+		return 0
+	}
+	lowest := t.delay
+	for i := 0; i < min(t.delayCount, delayWindow); i++ {
+		lowest = min(lowest, t.delays[i])
+	}
+	return time.Duration(t.delay - lowest)
+}
+
+// rotation checks a rotation notification's moves against the centers the
+// cube reported before. It reports whether the notification repeats the
+// previous one and should be discarded, and the time to give its moves:
+// at, less the link's current delay if smooth is set, but never before
+// the moves handed out earlier.
+func (t *linkTracker) rotation(payload []byte, moves []Move, at time.Time, smooth bool) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.repeatsLocked(payload, moves) {
+		t.duplicates++
+		return at, true
+	}
+	t.lastRotation = append(t.lastRotation[:0], payload...)
+
+	for _, m := range moves {
+		if !m.HasCenter {
+			continue
+		}
+		face := moveFaceToCubeFace(m.Face)
+		if t.centerSeen[face] {
+			expected := ((t.centers[face]+int(m.Turn))%4 + 4) % 4
+			switch (m.Center - expected + 4) % 4 {
+			case 0:
+			case 2:
+				t.missedTurns += 2
+			default:
+				t.missedTurns++
+			}
+		}
+		t.centers[face], t.centerSeen[face] = m.Center, true
+	}
+
+	if smooth {
+		at = at.Add(-t.excessDelayLocked(at))
+		if at.Before(t.lastMoveTime) {
+			at = t.lastMoveTime
+		}
+	}
+	t.lastMoveTime = at
+	return at, false
+}
+
+// repeatsLocked reports whether a rotation notification is the previous
+// one delivered again. Only cubes that report centers can tell: a repeated
+// turn changes the center, so an identical payload is the same turn. The
+// caller must hold mu.
+func (t *linkTracker) repeatsLocked(payload []byte, moves []Move) bool {
+	if len(moves) == 0 || !bytes.Equal(payload, t.lastRotation) {
+		return false
+	}
+	for _, m := range moves {
+		if !m.HasCenter {
+			return false
+		}
+	}
+	return true
+}
+
+// reconnected records a new link. Turns made while it was down are not
+// missed turns, so center tracking starts over, as does the stream timing.
+func (t *linkTracker) reconnected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reconnects++
+	t.centerSeen = [6]bool{}
+	t.lastRotation = nil
+	t.lastSample = time.Time{}
+	t.restartStreamLocked()
+}
+
+// addRSSI records a signal strength reading.
+func (t *linkTracker) addRSSI(at time.Time, rssi int16) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rssi = append(t.rssi, RSSISample{Time: at, RSSI: rssi})
+	if len(t.rssi) > rssiHistorySize {
+		t.rssi = append(t.rssi[:0], t.rssi[len(t.rssi)-rssiHistorySize:]...)
+	}
+}
+
+// stats returns the link stats as of now.
+func (t *linkTracker) stats(now time.Time) LinkStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := LinkStats{
+		Since:         t.since,
+		Notifications: t.notifications,
+		LastMessage:   t.lastMessage,
+		Reconnects:    t.reconnects,
+		Interval:      time.Duration(t.interval),
+		Jitter:        time.Duration(t.jitter),
+		MaxGap:        t.maxGap,
+		Delay:         t.excessDelayLocked(now),
+		Duplicates:    t.duplicates,
+		MissedTurns:   t.missedTurns,
+		RSSIHistory:   append([]RSSISample(nil), t.rssi...),
+	}
+	if n := len(t.rssi); n > 0 {
+		s.RSSI = t.rssi[n-1].RSSI
+	}
+	return s
+}
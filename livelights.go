@@ -0,0 +1,130 @@
+//go:build !js
+
+package gocube
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// Lights controls a GoCube's backlight. Get it with GoCube.Lights.
+//
+// The cube only offers toggles and cannot report whether its backlight is
+// on, so On, Off and Animated track what they have sent, starting from the
+// backlight on and not animated as after connecting. Commands sent with
+// SendRawCommand are not tracked. GAN and MoYu cubes have no backlight and
+// return ErrNotSupported.
+type Lights struct {
+	g *GoCube
+
+	mu       sync.Mutex
+	off      bool // Turned off through Lights
+	animated bool
+}
+
+// Lights returns the backlight controls.
+func (g *GoCube) Lights() *Lights {
+	return &g.lights
+}
+
+// On turns the backlight on.
+func (l *Lights) On() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.off {
+		return nil
+	}
+	if err := l.send(protocol.CmdToggleBacklight); err != nil {
+		return err
+	}
+	l.off = false
+	return nil
+}
+
+// Off turns the backlight off.
+func (l *Lights) Off() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.off {
+		return nil
+	}
+	if err := l.send(protocol.CmdToggleBacklight); err != nil {
+		return err
+	}
+	l.off = true
+	return nil
+}
+
+// IsOn reports whether the backlight is on, as far as Lights knows.
+func (l *Lights) IsOn() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.off
+}
+
+// Flash flashes the backlight three times.
+func (l *Lights) Flash() error {
+	return l.send(protocol.CmdFlashBacklight)
+}
+
+// SlowFlash slowly flashes the backlight three times.
+func (l *Lights) SlowFlash() error {
+	return l.send(protocol.CmdSlowFlashBacklight)
+}
+
+// Animated turns the animated backlight on or off.
+func (l *Lights) Animated(enabled bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.animated == enabled {
+		return nil
+	}
+	if err := l.send(protocol.CmdToggleAnimatedBL); err != nil {
+		return err
+	}
+	l.animated = enabled
+	return nil
+}
+
+// Play plays pattern. LightCelebrate returns once the animation has run
+// for CelebrateDuration and been turned off again.
+func (l *Lights) Play(pattern LightPattern) error {
+	switch pattern {
+	case LightFlash:
+		return l.Flash()
+	case LightSlowFlash:
+		return l.SlowFlash()
+	case LightCelebrate:
+		if err := l.Animated(true); err != nil {
+			return err
+		}
+		time.Sleep(CelebrateDuration)
+		return l.Animated(false)
+	}
+	return nil
+}
+
+func (l *Lights) send(cmd byte) error {
+	if err := l.g.client.SendCommand(cmd); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return ErrNotSupported
+		}
+		return err
+	}
+	return nil
+}
+
+// playLEDPolicy plays the WithLEDPolicy effect for completing phase, in
+// the background so the ingestion goroutine is not held up.
+func (g *GoCube) playLEDPolicy(phase Phase) {
+	pattern := g.config.ledPolicy.PhaseComplete
+	if phase == PhaseSolved {
+		pattern = g.config.ledPolicy.Solved
+	}
+	if pattern != LightNone {
+		go g.lights.Play(pattern)
+	}
+}
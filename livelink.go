@@ -0,0 +1,54 @@
+//go:build !js
+
+package gocube
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/ble"
+)
+
+// LinkStats returns the health of the BLE link since connecting: message
+// timing, repeated and missed turns, and signal strength. Timing needs
+// orientation enabled (see EnableOrientation), and signal strength beyond
+// the scan's needs WithRSSIPolling or ReadRSSI.
+func (g *GoCube) LinkStats() LinkStats {
+	return g.link.stats(time.Now())
+}
+
+// ReadRSSI reads the signal strength of the link in dBm and adds it to
+// LinkStats. Returns ErrNotSupported if the BLE backend cannot read it for
+// a connection, as is the case for the default one.
+func (g *GoCube) ReadRSSI() (int16, error) {
+	rssi, err := g.client.ReadRSSI()
+	if err != nil {
+		if errors.Is(err, ble.ErrRSSIUnsupported) {
+			return 0, ErrNotSupported
+		}
+		return 0, err
+	}
+	g.link.addRSSI(time.Now(), rssi)
+	return rssi, nil
+}
+
+// pollRSSI reads the signal strength every interval until Close, or until
+// it turns out the backend cannot. Reads made while the link is down fail
+// and are retried next tick.
+func (g *GoCube) pollRSSI(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-ticker.C:
+			if !g.client.IsConnected() {
+				continue
+			}
+			if _, err := g.ReadRSSI(); errors.Is(err, ErrNotSupported) {
+				return
+			}
+		}
+	}
+}
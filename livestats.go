@@ -0,0 +1,65 @@
+//go:build !js
+
+package gocube
+
+import "time"
+
+// Stats returns statistics of the moves made since connecting or the last
+// ClearHistory: move count, duration, overall and rolling TPS, and how
+// often each face was turned. They are kept with move history disabled.
+func (g *GoCube) Stats() Stats {
+	g.writeMu.Lock()
+	defer g.writeMu.Unlock()
+	return g.stats.stats(time.Now())
+}
+
+// OnStats sets a callback that receives Stats every second (see
+// WithStatsInterval), once the first move has been made, for live TPS
+// displays. It keeps firing while the cube is idle, so RollingTPS falls
+// back to zero. Close stops it.
+func (g *GoCube) OnStats(cb func(Stats)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onStats = cb
+	if cb != nil && g.statsDone == nil {
+		g.statsDone = make(chan struct{})
+		go g.sampleStats(g.config.statsInterval, g.statsDone)
+	}
+}
+
+// sampleStats calls the OnStats callback every interval until done closes.
+func (g *GoCube) sampleStats(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			g.mu.RLock()
+			cb := g.onStats
+			g.mu.RUnlock()
+
+			var s Stats
+			g.writeMu.Lock()
+			if g.stats.moves > 0 {
+				s = g.stats.stats(now)
+			}
+			g.writeMu.Unlock()
+
+			if cb != nil && s.Moves > 0 {
+				cb(s)
+			}
+		}
+	}
+}
+
+// stopStats stops the OnStats sampler, if running.
+func (g *GoCube) stopStats() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.statsDone != nil {
+		close(g.statsDone)
+		g.statsDone = nil
+	}
+}
@@ -0,0 +1,229 @@
+package gocube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Solving methods whose phases can be detected besides MethodLayerByLayer,
+// with Cube.PhaseFor, WithMethod or Replayer.SetMethod. Like the
+// layer-by-layer phases they build on the white (U) face first: the Roux
+// blocks stand on it and the ZZ line lies on it, and yellow (D) is the last
+// layer.
+const (
+	MethodRoux = "roux"
+	MethodZZ   = "zz"
+)
+
+// methodPhases are the phases of each method in the order they are
+// completed.
+var methodPhases = map[string][]Phase{
+	MethodLayerByLayer: {PhaseScrambled, PhaseWhiteCross, PhaseFirstLayer, PhaseSecondLayer,
+		PhaseYellowCross, PhaseYellowCorners, PhaseYellowOriented, PhaseSolved},
+	MethodRoux: {PhaseScrambled, PhaseFirstBlock, PhaseSecondBlock, PhaseCMLL, PhaseSolved},
+	MethodZZ:   {PhaseScrambled, PhaseEOLine, PhaseZZF2L, PhaseSolved},
+}
+
+// MethodPhases returns the phases of method in the order they are
+// completed, from PhaseScrambled to PhaseSolved. Method is
+// MethodLayerByLayer (also "beginner", "lbl" or ""), MethodRoux or
+// MethodZZ; others return ErrUnsupportedMethod.
+func MethodPhases(method string) ([]Phase, error) {
+	key, err := methodKey(method)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Phase(nil), methodPhases[key]...), nil
+}
+
+// methodKey returns the key of method in methodPhases.
+func methodKey(method string) (string, error) {
+	switch m := strings.ToLower(method); m {
+	case MethodLayerByLayer, "beginner", "lbl", "":
+		return MethodLayerByLayer, nil
+	case MethodRoux, MethodZZ:
+		return m, nil
+	}
+	return "", fmt.Errorf("%w: no phases for %q", ErrUnsupportedMethod, method)
+}
+
+// PhaseFor returns the last phase of method the cube has completed, as
+// Phase does for the layer-by-layer method. See MethodPhases for the
+// methods.
+func (c *Cube) PhaseFor(method string) (Phase, error) {
+	key, err := methodKey(method)
+	if err != nil {
+		return PhaseScrambled, err
+	}
+	return c.methodPhase(key), nil
+}
+
+// methodPhase detects the phase of method, a key of methodPhases.
+func (c *Cube) methodPhase(method string) Phase {
+	switch method {
+	case MethodRoux:
+		if c.IsSolved() {
+			return PhaseSolved
+		}
+		switch first, second, cmll := c.rouxProgress(); {
+		case cmll:
+			return PhaseCMLL
+		case second:
+			return PhaseSecondBlock
+		case first:
+			return PhaseFirstBlock
+		}
+		return PhaseScrambled
+	case MethodZZ:
+		if c.IsSolved() {
+			return PhaseSolved
+		}
+		switch eoLine, f2l := c.zzProgress(); {
+		case f2l:
+			return PhaseZZF2L
+		case eoLine:
+			return PhaseEOLine
+		}
+		return PhaseScrambled
+	}
+	return c.detectPhase()
+}
+
+// sideFaces are the faces around the white and yellow ones.
+var sideFaces = []CubeFace{CubeFaceF, CubeFaceR, CubeFaceB, CubeFaceL}
+
+// faceTurns are the turns of a face, none first.
+var faceTurns = []int{0, 1, 2, -1}
+
+// rouxBlocks are the pieces of the Roux block at each side: the side's
+// white edge and corners and its middle layer edges.
+var rouxBlocks = func() map[CubeFace][]hintPiece {
+	blocks := make(map[CubeFace][]hintPiece)
+	for _, side := range sideFaces {
+		s := string(patternFaces[side])
+		a, b := "R", "L"
+		if side == CubeFaceR || side == CubeFaceL {
+			a, b = "F", "B"
+		}
+		blocks[side] = piecesNamed("U"+s, s+a, s+b, "U"+s+a, "U"+s+b)
+	}
+	return blocks
+}()
+
+// zzLines are the line edges along each axis, by its first face.
+var zzLines = map[CubeFace][]hintPiece{
+	CubeFaceF: piecesNamed("UF", "UB"),
+	CubeFaceR: piecesNamed("UR", "UL"),
+}
+
+// piecesNamed returns the pieces with the given names, e.g. "UF".
+func piecesNamed(names ...string) []hintPiece {
+	pieces := make([]hintPiece, len(names))
+	for i, name := range names {
+		letters := []byte(name)
+		sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+		pieces[i] = hintPiece{patternPieces[string(letters)]}
+	}
+	return pieces
+}
+
+func piecesSolved(c *Cube, pieces []hintPiece) bool {
+	for _, p := range pieces {
+		if !p.solvedAt(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// rouxProgress reports which Roux steps the cube has completed. The blocks
+// may be turned against the white and yellow centers by the M slice, and
+// the corners solved by CMLL may be a D turn from their places.
+func (c *Cube) rouxProgress() (firstBlock, secondBlock, cmll bool) {
+	for _, side := range sideFaces {
+		opposite := side ^ 1
+		for _, k := range faceTurns {
+			// Turn the block's layer by k, as if the M slice were turned
+			// back into place
+			cube := Cube{Facelets: c.Facelets}
+			cube.moveFace(side, k)
+			if !piecesSolved(&cube, rouxBlocks[side]) {
+				continue
+			}
+			firstBlock = true
+
+			back := -k
+			if k == 2 {
+				back = 2
+			}
+			cube.moveFace(opposite, back)
+			if !piecesSolved(&cube, rouxBlocks[opposite]) {
+				continue
+			}
+			secondBlock = true
+
+			for _, t := range faceTurns {
+				last := cube
+				last.moveFace(CubeFaceD, t)
+				if piecesSolved(&last, bottomCorners) {
+					return true, true, true
+				}
+			}
+		}
+	}
+	return firstBlock, secondBlock, false
+}
+
+// zzProgress reports which ZZ steps the cube has completed. The line is
+// on the white face along either axis, and edges are oriented for that
+// axis: solvable without quarter turns of the faces at its ends.
+func (c *Cube) zzProgress() (eoLine, f2l bool) {
+	if c.isMiddleLayerComplete() {
+		oriented := true
+		for _, p := range bottomEdges {
+			f := p.facelets[0]
+			oriented = oriented && c.Facelets[f[0]][f[1]] == c.Facelets[f[0]][4]
+		}
+		if oriented {
+			return true, true
+		}
+	}
+	for axis, line := range zzLines {
+		if piecesSolved(c, line) && c.edgesOriented(axis) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// edgesOriented reports whether every edge is oriented for the axis
+// through face axis (CubeFaceF or CubeFaceR). An edge is oriented if the
+// sticker on the white or yellow face, or for middle layer edges the one
+// on the axis, shows white or yellow, or shows an axis color with the
+// other sticker showing neither white nor yellow.
+func (c *Cube) edgesOriented(axis CubeFace) bool {
+	// The axis of the face whose center shows color, by its first face
+	colorAxis := func(color Color) CubeFace {
+		for face := CubeFace(0); face < 6; face++ {
+			if c.Facelets[face][4] == color {
+				return face &^ 1
+			}
+		}
+		return -1
+	}
+	for _, group := range [][]hintPiece{crossEdges, middleEdges, bottomEdges} {
+		for _, p := range group {
+			ref, other := p.facelets[0], p.facelets[1]
+			if ref[0] != fU && ref[0] != fD && CubeFace(ref[0])&^1 != axis {
+				ref, other = other, ref
+			}
+			a := colorAxis(c.Facelets[ref[0]][ref[1]])
+			b := colorAxis(c.Facelets[other[0]][other[1]])
+			if a != CubeFaceU && (a != axis || b == CubeFaceU) {
+				return false
+			}
+		}
+	}
+	return true
+}
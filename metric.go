@@ -0,0 +1,94 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metric is a way of counting moves.
+type Metric string
+
+const (
+	// MetricHTM (half turn metric) counts every face turn as one move,
+	// whatever its angle. It is the default.
+	MetricHTM Metric = "htm"
+
+	// MetricQTM (quarter turn metric) counts a half turn as two moves.
+	MetricQTM Metric = "qtm"
+
+	// MetricSTM (slice turn metric) counts a turn of any layer, including
+	// a slice, as one move. Smart cubes report a slice turn as turns of the
+	// two faces beside it (M' as R L'), so such pairs count as one.
+	MetricSTM Metric = "stm"
+)
+
+// Metrics lists the supported metrics.
+var Metrics = []Metric{MetricHTM, MetricQTM, MetricSTM}
+
+// ParseMetric parses a metric name (htm, qtm or stm, in any case). The
+// empty string is MetricHTM.
+func ParseMetric(s string) (Metric, error) {
+	switch m := Metric(strings.ToLower(s)); m {
+	case "":
+		return MetricHTM, nil
+	case MetricHTM, MetricQTM, MetricSTM:
+		return m, nil
+	}
+	return "", fmt.Errorf("gocube: unknown turn metric %q (want htm, qtm or stm)", s)
+}
+
+// String returns the metric's usual abbreviation, e.g. "QTM".
+func (m Metric) String() string {
+	switch m {
+	case MetricQTM:
+		return "QTM"
+	case MetricSTM:
+		return "STM"
+	}
+	return "HTM"
+}
+
+// oppositeFaces pairs each outer face with the face across from it.
+var oppositeFaces = map[Face]Face{
+	FaceR: FaceL, FaceL: FaceR,
+	FaceU: FaceD, FaceD: FaceU,
+	FaceF: FaceB, FaceB: FaceF,
+}
+
+// CountMoves counts moves in metric. Rotations (x, y, z) are free in every
+// metric. In HTM and QTM a slice turn counts as the two outer turns it
+// stands for.
+func CountMoves(moves []Move, metric Metric) int {
+	count := 0
+	for i := 0; i < len(moves); i++ {
+		m := moves[i]
+		switch m.Face {
+		case FaceX, FaceY, FaceZ:
+			continue
+		}
+
+		n := 1
+		if metric == MetricQTM && m.Turn == Double {
+			n = 2
+		}
+		switch {
+		case m.Face == FaceM || m.Face == FaceE || m.Face == FaceS:
+			if metric != MetricSTM {
+				n *= 2
+			}
+		case metric == MetricSTM && i+1 < len(moves) && isSlicePair(m, moves[i+1]):
+			i++ // The pair is one slice turn
+		}
+		count += n
+	}
+	return count
+}
+
+// isSlicePair reports whether a then b turn opposite faces in the same
+// direction in space, which is a slice turn (R L' is M' with a rotation).
+func isSlicePair(a, b Move) bool {
+	if !a.Face.IsOuter() || oppositeFaces[a.Face] != b.Face {
+		return false
+	}
+	return a.Turn == -b.Turn || (a.Turn == Double && b.Turn == Double)
+}
@@ -17,6 +17,41 @@ const (
 	FaceB Face = "B" // Back
 )
 
+// Slice moves turn the middle layer between two opposite faces.
+const (
+	FaceM Face = "M" // Between R and L, turning like L
+	FaceE Face = "E" // Between U and D, turning like D
+	FaceS Face = "S" // Between F and B, turning like F
+)
+
+// Wide moves turn a face together with the middle layer next to it. They
+// are written Rw or r.
+const (
+	FaceRw Face = "Rw"
+	FaceLw Face = "Lw"
+	FaceUw Face = "Uw"
+	FaceDw Face = "Dw"
+	FaceFw Face = "Fw"
+	FaceBw Face = "Bw"
+)
+
+// Rotations turn the whole cube.
+const (
+	FaceX Face = "x" // Turning like R
+	FaceY Face = "y" // Turning like U
+	FaceZ Face = "z" // Turning like F
+)
+
+// IsOuter returns true for the six outer faces, the only turns a smart cube
+// reports.
+func (f Face) IsOuter() bool {
+	switch f {
+	case FaceR, FaceL, FaceU, FaceD, FaceF, FaceB:
+		return true
+	}
+	return false
+}
+
 // Turn represents the direction and magnitude of a face turn.
 type Turn int
 
@@ -31,6 +66,63 @@ type Move struct {
 	Face Face      // Which face to turn
 	Turn Turn      // Direction and amount
 	Time time.Time // When the move occurred (optional)
+
+	// Center orientation of Face after the move as reported by the cube, in
+	// clockwise quarter turns from solved (0-3). Only set, with HasCenter,
+	// for moves from a cube that reports it.
+	Center    int
+	HasCenter bool
+
+	// BatchIndex and BatchSize place the move within the notification that
+	// reported it. A cube reports turns made at nearly the same moment in
+	// one notification, in an order that may not be the order they were
+	// made in. BatchSize is 0 or 1 for a move reported on its own.
+	BatchIndex int
+	BatchSize  int
+
+	// Elapsed is the time the cube measured since its previous turn, set
+	// with HasElapsed for moves from a cube with its own clock (GAN Gen2
+	// and MoYu AI).
+	Elapsed    time.Duration
+	HasElapsed bool
+
+	// TimeSource tells how Time was obtained, e.g. whether it was inferred
+	// by TimeBatch rather than taken from the notification.
+	TimeSource TimeSource
+}
+
+// TimeSource tells how a move's time was obtained.
+type TimeSource int
+
+const (
+	TimeReceived     TimeSource = iota // When the notification reporting it arrived
+	TimeDevice                         // Back from the notification by the cube's own clock
+	TimeInterpolated                   // Spread evenly over the notification's interval
+)
+
+// String returns "received", "device" or "interpolated".
+func (s TimeSource) String() string {
+	switch s {
+	case TimeDevice:
+		return "device"
+	case TimeInterpolated:
+		return "interpolated"
+	default:
+		return "received"
+	}
+}
+
+// ParseTimeSource returns the TimeSource named s by String, and
+// TimeReceived for any other name.
+func ParseTimeSource(s string) TimeSource {
+	switch s {
+	case "device":
+		return TimeDevice
+	case "interpolated":
+		return TimeInterpolated
+	default:
+		return TimeReceived
+	}
 }
 
 // Notation returns the standard cube notation string for this move.
@@ -57,9 +149,71 @@ func (m Move) Inverse() Move {
 		inv.Turn = CW
 	// Double is its own inverse
 	}
+	inv.Center, inv.HasCenter = 0, false // Reported for the original move only
+	inv.BatchIndex, inv.BatchSize = 0, 0
+	inv.Elapsed, inv.HasElapsed = 0, false
 	return inv
 }
 
+// Simultaneous reports whether the move was reported together with other
+// turns, so it happened at nearly the same moment as them and its order
+// among them is uncertain.
+func (m Move) Simultaneous() bool {
+	return m.BatchSize > 1
+}
+
+// SpreadBatchTimes spreads the times of moves reported in one notification,
+// which all carry the notification's time, evenly over the interval since
+// the previous notification at prev, capped at maxSpan. The last move keeps the
+// notification's time; the others are marked TimeInterpolated. A zero prev
+// spreads over maxSpan. Single moves are left alone.
+func SpreadBatchTimes(moves []Move, prev time.Time, maxSpan time.Duration) {
+	n := len(moves)
+	if n < 2 || maxSpan <= 0 {
+		return
+	}
+	end := moves[n-1].Time
+	span := maxSpan
+	if !prev.IsZero() && end.Sub(prev) < span {
+		span = end.Sub(prev)
+	}
+	step := span / time.Duration(n)
+	for i := range moves {
+		moves[i].Time = end.Add(-step * time.Duration(n-1-i))
+		if i < n-1 {
+			moves[i].TimeSource = TimeInterpolated
+		}
+	}
+}
+
+// TimeBatch times the moves reported in one notification, which all carry
+// the notification's time. If the cube timed the turns (see Move.Elapsed),
+// each move but the last is placed back from the one after it by that
+// one's Elapsed, no earlier than prev, and marked TimeDevice. Otherwise the
+// moves are spread as SpreadBatchTimes does, if maxSpan is positive.
+func TimeBatch(moves []Move, prev time.Time, maxSpan time.Duration) {
+	n := len(moves)
+	if n < 2 {
+		return
+	}
+	timed := true
+	for _, m := range moves[1:] {
+		timed = timed && m.HasElapsed
+	}
+	if !timed {
+		SpreadBatchTimes(moves, prev, maxSpan)
+		return
+	}
+	for i := n - 2; i >= 0; i-- {
+		t := moves[i+1].Time.Add(-moves[i+1].Elapsed)
+		if !prev.IsZero() && t.Before(prev) {
+			t = prev
+		}
+		moves[i].Time = t
+		moves[i].TimeSource = TimeDevice
+	}
+}
+
 // WithTime returns a copy of the move with the specified timestamp.
 func (m Move) WithTime(t time.Time) Move {
 	m.Time = t
@@ -72,7 +226,7 @@ func (m Move) String() string {
 }
 
 // ParseMove parses a standard notation string into a Move.
-// Examples: R, R', R2, U, U', U2
+// Examples: R, R', R2, M, E', S2, Rw, r', x, y2, z'
 // Returns an error if the notation is invalid.
 func ParseMove(s string) (Move, error) {
 	s = strings.TrimSpace(s)
@@ -81,39 +235,37 @@ func ParseMove(s string) (Move, error) {
 	}
 
 	// Extract face
-	faceChar := s[0]
 	var face Face
-	switch faceChar {
-	case 'R', 'r':
-		face = FaceR
-	case 'L', 'l':
-		face = FaceL
-	case 'U', 'u':
-		face = FaceU
-	case 'D', 'd':
-		face = FaceD
-	case 'F', 'f':
-		face = FaceF
-	case 'B', 'b':
-		face = FaceB
+	suffix := s[1:]
+	switch c := s[0]; c {
+	case 'R', 'L', 'U', 'D', 'F', 'B':
+		face = Face(s[:1])
+		if strings.HasPrefix(suffix, "w") {
+			face += "w"
+			suffix = suffix[1:]
+		}
+	case 'r', 'l', 'u', 'd', 'f', 'b':
+		face = Face(strings.ToUpper(s[:1])) + "w"
+	case 'M', 'E', 'S':
+		face = Face(s[:1])
+	case 'x', 'y', 'z', 'X', 'Y', 'Z':
+		face = Face(strings.ToLower(s[:1]))
 	default:
 		return Move{}, ErrInvalidNotation
 	}
 
 	// Extract turn
 	turn := CW // Default is clockwise
-	if len(s) > 1 {
-		suffix := s[1:]
-		switch suffix {
-		case "'", "`":
-			turn = CCW
-		case "2":
-			turn = Double
-		case "2'", "2`":
-			turn = Double // Same as 180
-		default:
-			return Move{}, ErrInvalidNotation
-		}
+	switch suffix {
+	case "":
+	case "'", "`":
+		turn = CCW
+	case "2":
+		turn = Double
+	case "2'", "2`":
+		turn = Double // Same as 180
+	default:
+		return Move{}, ErrInvalidNotation
 	}
 
 	return Move{Face: face, Turn: turn}, nil
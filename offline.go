@@ -0,0 +1,122 @@
+//go:build !js
+
+package gocube
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// OfflineStats are the cube's own cumulative counters. The cube counts
+// every turn and solve, whether or not an app is connected, so they
+// include use away from the app. They restart from zero if the cube loses
+// power or its firmware resets.
+type OfflineStats struct {
+	Moves  int
+	Time   time.Duration // Time spent turning
+	Solves int
+}
+
+// offlineStatsTimeout bounds how long SyncOfflineStats waits for the cube
+// to answer.
+const offlineStatsTimeout = 5 * time.Second
+
+// OnOfflineStats sets a callback for the cube's offline stats, which
+// arrive after RequestOfflineStats, SyncOfflineStats, and on connecting
+// with WithOfflineStatsOnConnect.
+func (g *GoCube) OnOfflineStats(cb func(OfflineStats)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onOffline = cb
+}
+
+// LastOfflineStats returns the offline stats the cube last reported, and
+// false if it has not reported any since connecting.
+func (g *GoCube) LastOfflineStats() (OfflineStats, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.offline == nil {
+		return OfflineStats{}, false
+	}
+	return *g.offline, true
+}
+
+// RequestOfflineStats asks the cube for its offline stats. The answer
+// fires OnOfflineStats. Returns ErrNotSupported for cubes that cannot
+// report them.
+func (g *GoCube) RequestOfflineStats() error {
+	if err := g.client.RequestOfflineStats(); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedCommand) {
+			return ErrNotSupported
+		}
+		return err
+	}
+	return nil
+}
+
+// SyncOfflineStats requests the cube's offline stats and waits for them.
+// Returns ErrTimeout if the cube does not answer within 5 seconds or
+// before ctx is done, and ErrNotSupported for cubes that cannot report
+// them. To keep them, see storage.DB.SaveOfflineStats.
+func (g *GoCube) SyncOfflineStats(ctx context.Context) (OfflineStats, error) {
+	ch := make(chan OfflineStats, 1)
+	g.mu.Lock()
+	g.offlineWaits = append(g.offlineWaits, ch)
+	g.mu.Unlock()
+	defer g.removeOfflineWaiter(ch)
+
+	if err := g.RequestOfflineStats(); err != nil {
+		return OfflineStats{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, offlineStatsTimeout)
+	defer cancel()
+
+	select {
+	case stats := <-ch:
+		return stats, nil
+	case <-ctx.Done():
+		return OfflineStats{}, ErrTimeout
+	}
+}
+
+func (g *GoCube) removeOfflineWaiter(ch chan OfflineStats) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, w := range g.offlineWaits {
+		if w == ch {
+			g.offlineWaits = append(g.offlineWaits[:i], g.offlineWaits[i+1:]...)
+			return
+		}
+	}
+}
+
+func (g *GoCube) handleOfflineStats(msg *protocol.Message) {
+	event, err := protocol.DecodeOfflineStats(msg.Payload)
+	if err != nil {
+		return
+	}
+	stats := OfflineStats{
+		Moves:  event.Moves,
+		Time:   time.Duration(event.Time) * time.Second,
+		Solves: event.Solves,
+	}
+
+	g.mu.Lock()
+	g.offline = &stats
+	cb := g.onOffline
+	for _, ch := range g.offlineWaits {
+		select {
+		case ch <- stats:
+		default:
+		}
+	}
+	g.mu.Unlock()
+
+	if cb != nil {
+		cb(stats)
+	}
+}
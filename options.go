@@ -1,30 +1,75 @@
 package gocube
 
+import (
+	"math"
+	"time"
+)
+
 // Option configures GoCube behavior.
 type Option func(*config)
 
 type config struct {
 	autoReconnect  bool
+	reconnectTries int
+	reconnectWait  time.Duration
 	moveHistory    bool
 	phaseDetection bool
+	method         string
+	keepAlive      time.Duration
+
+	centerOrientation bool
+	timer             *Timer
+	statsInterval     time.Duration
+	batchSpread       time.Duration
+	lowBattery        int
+	batteryPoll       time.Duration
+	rssiPoll          time.Duration
+	smoothTimes       bool
+	offlineStats      bool
+	ledPolicy         LEDPolicy
+
+	attitudeSmoothing float64
+	attitudeInterval  time.Duration
+
+	scanTimeout     time.Duration
+	preferredDevice string
 }
 
 func defaultConfig() *config {
 	return &config{
 		autoReconnect:  false,
+		reconnectTries: 5,
+		reconnectWait:  time.Second,
 		moveHistory:    true,
 		phaseDetection: true,
+		scanTimeout:    10 * time.Second,
+		statsInterval:  defaultStatsInterval,
+		lowBattery:     defaultLowBattery,
 	}
 }
 
 // WithAutoReconnect enables automatic reconnection on disconnect.
 // When enabled, the GoCube will attempt to reconnect if the connection drops.
+// Move history and the tracked cube state are kept across the reconnect, and
+// OnReconnect fires once the link is back. OnDisconnect fires only if every
+// attempt fails.
 func WithAutoReconnect(enabled bool) Option {
 	return func(c *config) {
 		c.autoReconnect = enabled
 	}
 }
 
+// WithReconnectPolicy sets how many reconnection attempts are made and how
+// long to wait before the first; the wait doubles after each failed attempt,
+// up to 30 seconds. The default is 5 attempts starting at 1 second. Only
+// used with WithAutoReconnect(true).
+func WithReconnectPolicy(retries int, backoff time.Duration) Option {
+	return func(c *config) {
+		c.reconnectTries = retries
+		c.reconnectWait = backoff
+	}
+}
+
 // WithMoveHistory enables or disables move history tracking.
 // When enabled (default), all moves are stored and accessible via Moves().
 // Disable this for long sessions to reduce memory usage.
@@ -41,3 +86,171 @@ func WithPhaseDetection(enabled bool) Option {
 		c.phaseDetection = enabled
 	}
 }
+
+// WithMethod sets the solving method whose phases Phase, HighestPhase and
+// OnPhaseChange report: MethodLayerByLayer (default), MethodRoux or
+// MethodZZ. Connect returns ErrUnsupportedMethod for other methods.
+func WithMethod(method string) Option {
+	return func(c *config) {
+		c.method = method
+	}
+}
+
+// WithCenterOrientation makes the solved check require every center in its
+// solved orientation, for picture and logo cubes where a twisted center
+// shows. Phase, IsSolved, OnPhaseChange and OnSolved then treat a cube with
+// a twisted center as not yet solved. Center orientation is tracked from
+// the moves and from what the cube reports (GoCube only). Disabled by
+// default.
+func WithCenterOrientation(enabled bool) Option {
+	return func(c *config) {
+		c.centerOrientation = enabled
+	}
+}
+
+// WithKeepAlive sends a benign battery request whenever the cube has been
+// quiet for interval. This stops the cube from sleeping during long pauses,
+// and an unanswered request fires the OnSleep callback. Zero (default)
+// disables keep-alive.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(c *config) {
+		c.keepAlive = interval
+	}
+}
+
+// defaultLowBattery is the battery level at or below which OnBatteryLow
+// fires unless WithLowBattery says otherwise.
+const defaultLowBattery = 20
+
+// WithLowBattery sets the battery level (percent) at or below which
+// OnBatteryLow fires. The default is 20. Zero disables the callback.
+func WithLowBattery(threshold int) Option {
+	return func(c *config) {
+		c.lowBattery = threshold
+	}
+}
+
+// WithBatteryPolling requests the battery level every interval, so Battery
+// stays current and OnBatteryLow fires during long sessions; cubes only
+// report it now and then otherwise. Zero (default) disables polling.
+func WithBatteryPolling(interval time.Duration) Option {
+	return func(c *config) {
+		c.batteryPoll = interval
+	}
+}
+
+// WithRSSIPolling reads the signal strength of the link every interval
+// into LinkStats. Zero (default) disables polling. Polling stops by itself
+// if the BLE backend cannot read the signal strength of a connection; the
+// default backend cannot, so LinkStats then only has the strength seen
+// when scanning.
+func WithRSSIPolling(interval time.Duration) Option {
+	return func(c *config) {
+		c.rssiPoll = interval
+	}
+}
+
+// WithTimestampSmoothing times each move at its arrival less the delay the
+// link is currently adding (see LinkStats.Delay), so turns held up by a
+// busy link keep their spacing in TPS and pause analysis. The delay is
+// measured on the orientation stream, so moves keep their arrival times
+// while orientation is disabled. Smoothed times never go backwards.
+func WithTimestampSmoothing(enabled bool) Option {
+	return func(c *config) {
+		c.smoothTimes = enabled
+	}
+}
+
+// WithOfflineStatsOnConnect requests the cube's offline stats as soon as
+// it is connected. The answer may arrive before Connect returns, so read
+// it with LastOfflineStats; OnOfflineStats fires too if set by then.
+func WithOfflineStatsOnConnect(enabled bool) Option {
+	return func(c *config) {
+		c.offlineStats = enabled
+	}
+}
+
+// WithLEDPolicy makes the cube play policy's backlight effects as phases
+// are completed and when it is solved, e.g.
+//
+//	gocube.WithLEDPolicy(gocube.LEDPolicy{PhaseComplete: gocube.LightFlash, Solved: gocube.LightCelebrate})
+//
+// By default no effects are played.
+func WithLEDPolicy(policy LEDPolicy) Option {
+	return func(c *config) {
+		c.ledPolicy = policy
+	}
+}
+
+// WithOrientationSmoothing low-pass filters the quaternions passed to
+// OnOrientationQuaternion: each sample moves the reported attitude only
+// 1-smoothing of the way towards it, so values nearer 1 are smoother but
+// lag more. Zero (default) passes the raw attitude; around 0.5 hides sensor
+// jitter in a 3D view.
+func WithOrientationSmoothing(smoothing float64) Option {
+	return func(c *config) {
+		c.attitudeSmoothing = math.Min(math.Max(smoothing, 0), 0.99)
+	}
+}
+
+// WithOrientationRate limits OnOrientationQuaternion to at most hz calls
+// a second, e.g. the display's frame rate. Samples in between still feed
+// the smoothing. Zero (default) passes on every sample the cube sends.
+func WithOrientationRate(hz float64) Option {
+	return func(c *config) {
+		c.attitudeInterval = 0
+		if hz > 0 {
+			c.attitudeInterval = time.Duration(float64(time.Second) / hz)
+		}
+	}
+}
+
+// WithTimer feeds every move to timer along with whether it solved the
+// cube, so the timer starts on the first move after StartInspection and
+// stops when the cube is solved. The timer sees each move before the
+// OnPhaseChange, OnSolved and OnMove callbacks fire.
+func WithTimer(timer *Timer) Option {
+	return func(c *config) {
+		c.timer = timer
+	}
+}
+
+// WithStatsInterval sets how often the OnStats callback fires. The default
+// is 1 second.
+func WithStatsInterval(interval time.Duration) Option {
+	return func(c *config) {
+		if interval > 0 {
+			c.statsInterval = interval
+		}
+	}
+}
+
+// WithBatchSpread spreads the times of turns the cube reports together in
+// one notification over the interval since the previous notification, at
+// most maxSpan, instead of giving them all the notification's time (see
+// SpreadBatchTimes). Zero (default) disables spreading. Turns the cube
+// timed itself are placed by its clock whatever maxSpan (see TimeBatch).
+// Moves are tagged with their place in the notification either way (see
+// Move.BatchSize), and with how their time was obtained (Move.TimeSource).
+func WithBatchSpread(maxSpan time.Duration) Option {
+	return func(c *config) {
+		c.batchSpread = maxSpan
+	}
+}
+
+// WithScanTimeout sets how long ConnectFirst scans for cubes. The default
+// is 10 seconds. Connect ignores it.
+func WithScanTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.scanTimeout = timeout
+	}
+}
+
+// WithPreferredDevice makes ConnectFirst connect to the cube with this
+// UUID (see Device.UUID) when the scan finds it, rather than to the first
+// cube found. Connect ignores it.
+func WithPreferredDevice(uuid string) Option {
+	return func(c *config) {
+		c.preferredDevice = uuid
+	}
+}
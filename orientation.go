@@ -0,0 +1,69 @@
+package gocube
+
+// Orientation represents the cube's physical orientation in space.
+type Orientation struct {
+	UpFace    Face // Which face is pointing up
+	FrontFace Face // Which face is facing the user
+}
+
+// faceVectors gives the direction each face points in the home pose
+// (white up, green front), x to the right, y up and z towards the solver.
+var faceVectors = map[Face][3]int{
+	FaceR: {1, 0, 0},
+	FaceL: {-1, 0, 0},
+	FaceU: {0, 1, 0},
+	FaceD: {0, -1, 0},
+	FaceF: {0, 0, 1},
+	FaceB: {0, 0, -1},
+}
+
+// Position returns the position, relative to the solver, of cube face f:
+// with green up and white in front, Position(FaceF) is FaceU. It returns
+// false if the orientation is unknown or f is not an outer face.
+func (o Orientation) Position(f Face) (Face, bool) {
+	up, ok := faceVectors[o.UpFace]
+	if !ok {
+		return "", false
+	}
+	front, ok := faceVectors[o.FrontFace]
+	if !ok || dot(up, front) != 0 {
+		return "", false
+	}
+	v, ok := faceVectors[f]
+	if !ok {
+		return "", false
+	}
+
+	// Express v in the solver's axes: the faces up, in front and, by the
+	// right-hand rule, to the right.
+	right := [3]int{
+		up[1]*front[2] - up[2]*front[1],
+		up[2]*front[0] - up[0]*front[2],
+		up[0]*front[1] - up[1]*front[0],
+	}
+	pos := [3]int{dot(v, right), dot(v, up), dot(v, front)}
+	for face, fv := range faceVectors {
+		if fv == pos {
+			return face, true
+		}
+	}
+	return "", false
+}
+
+func dot(a, b [3]int) int {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+// NormalizeMove remaps a move as reported by the cube, which names faces
+// by their center colors, into the solver's frame of reference: R is
+// whichever face is on the right. Turn direction is unchanged, as is any
+// move with an unknown orientation or a face that is not an outer face.
+//
+// A cube held white up and green front reports moves as the solver sees
+// them, so NormalizeMove returns them unchanged.
+func NormalizeMove(m Move, o Orientation) Move {
+	if pos, ok := o.Position(m.Face); ok {
+		m.Face = pos
+	}
+	return m
+}
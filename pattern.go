@@ -0,0 +1,231 @@
+package gocube
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Pattern is a partial cube state: pieces that must or must not be
+// solved, and stickers that must show a color. Parse one with
+// ParsePattern and test it with Cube.Matches.
+//
+// A pattern is a list of terms separated by spaces or commas, all of which
+// must hold. A term is one or more alternatives separated by '|', any of
+// which may hold, and an alternative is an atom, negated by a leading '!':
+//
+//	UF, UFR, ...  A piece (any letter order): in its place and turned right
+//	cross         The white cross: UF, UR, UB and UL
+//	first-layer   The cross and the white corners
+//	slot-FR       First-layer corner and middle edge of a slot: UFR and FR
+//	              (also slot-FL, slot-BR, slot-BL)
+//	f2l           The first layer and all four middle edges
+//	solved        Every piece
+//	U4=W          Sticker 4 of the U face is white (indices as in Cube)
+//	D=Y           Every sticker of the D face is yellow
+//
+// Colors are W, Y, G, B, R and O. Faces are named as the cube is held with
+// white up and green front, as in the rest of the package, so the yellow
+// last layer is D. For example,
+//
+//	cross !slot-FR         White cross solved, front-right slot empty
+//	f2l D=Y                Last layer oriented
+//	f2l !UF|!UR            First two layers with a cross edge out of place
+func ParsePattern(s string) (Pattern, error) {
+	p := Pattern{src: strings.Join(strings.FieldsFunc(s, isPatternSeparator), " ")}
+	for _, field := range strings.FieldsFunc(s, isPatternSeparator) {
+		var t patternTerm
+		for _, alt := range strings.Split(field, "|") {
+			a := patternAlt{}
+			if rest, ok := strings.CutPrefix(alt, "!"); ok {
+				a.not = true
+				alt = rest
+			}
+			checks, err := patternAtom(alt)
+			if err != nil {
+				return Pattern{}, fmt.Errorf("%w: %q in %q: %v", ErrInvalidPattern, alt, s, err)
+			}
+			a.checks = checks
+			t.alts = append(t.alts, a)
+		}
+		p.terms = append(p.terms, t)
+	}
+	if len(p.terms) == 0 {
+		return Pattern{}, fmt.Errorf("%w: empty", ErrInvalidPattern)
+	}
+	return p, nil
+}
+
+// MustParsePattern is like ParsePattern but panics on an invalid pattern,
+// for patterns fixed in the source.
+func MustParsePattern(s string) Pattern {
+	p, err := ParsePattern(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Pattern is a parsed pattern; see ParsePattern. The zero Pattern matches
+// every cube.
+type Pattern struct {
+	src   string
+	terms []patternTerm
+}
+
+type patternTerm struct {
+	alts []patternAlt // Any may hold
+}
+
+type patternAlt struct {
+	not    bool
+	checks []stickerCheck // All must hold, unless not
+}
+
+// stickerCheck is one sticker's required color; center means the color of
+// the center of its face.
+type stickerCheck struct {
+	face, index int
+	color       Color
+	center      bool
+}
+
+// String returns the pattern as parsed, terms separated by single spaces.
+func (p Pattern) String() string {
+	return p.src
+}
+
+// Matches reports whether c is in the pattern.
+func (c *Cube) Matches(p Pattern) bool {
+	for _, t := range p.terms {
+		if !t.matches(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t patternTerm) matches(c *Cube) bool {
+	for _, a := range t.alts {
+		if a.matches(c) != a.not {
+			return true
+		}
+	}
+	return false
+}
+
+func (a patternAlt) matches(c *Cube) bool {
+	for _, s := range a.checks {
+		want := s.color
+		if s.center {
+			want = c.Facelets[s.face][4]
+		}
+		if c.Facelets[s.face][s.index] != want {
+			return false
+		}
+	}
+	return true
+}
+
+func isPatternSeparator(r rune) bool {
+	return r == ' ' || r == ',' || r == '\t' || r == '\n'
+}
+
+// patternGroups are the named groups of pieces.
+var patternGroups = map[string][]string{
+	"cross":       {"UF", "UR", "UB", "UL"},
+	"first-layer": {"UF", "UR", "UB", "UL", "UFR", "URB", "UBL", "ULF"},
+	"slot-fr":     {"UFR", "FR"},
+	"slot-fl":     {"ULF", "FL"},
+	"slot-br":     {"URB", "BR"},
+	"slot-bl":     {"UBL", "BL"},
+	"f2l":         {"UF", "UR", "UB", "UL", "UFR", "URB", "UBL", "ULF", "FR", "FL", "BR", "BL"},
+	"solved": {"UF", "UR", "UB", "UL", "UFR", "URB", "UBL", "ULF", "FR", "FL", "BR", "BL",
+		"DF", "DR", "DB", "DL", "DFR", "DRB", "DBL", "DLF"},
+}
+
+// patternPieces maps each piece, its letters sorted, to its stickers.
+var patternPieces = func() map[string][][2]int {
+	pieces := make(map[string][][2]int)
+	for _, group := range [][]hintPiece{crossEdges, topCorners, middleEdges, bottomEdges, bottomCorners} {
+		for _, p := range group {
+			pieces[pieceKey(p.facelets)] = p.facelets
+		}
+	}
+	return pieces
+}()
+
+const patternFaces = "UDFBRL" // In CubeFace order
+
+func pieceKey(facelets [][2]int) string {
+	letters := make([]byte, len(facelets))
+	for i, f := range facelets {
+		letters[i] = patternFaces[f[0]]
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	return string(letters)
+}
+
+// patternAtom returns the sticker checks an atom stands for.
+func patternAtom(atom string) ([]stickerCheck, error) {
+	if names, ok := patternGroups[strings.ToLower(atom)]; ok {
+		var checks []stickerCheck
+		for _, name := range names {
+			piece, _ := patternPiece(name)
+			checks = append(checks, piece...)
+		}
+		return checks, nil
+	}
+
+	if lhs, rhs, ok := strings.Cut(atom, "="); ok {
+		color, ok := parsePatternColor(rhs)
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", rhs)
+		}
+		if len(lhs) == 0 || strings.IndexByte(patternFaces, lhs[0]) < 0 {
+			return nil, fmt.Errorf("unknown face %q", lhs)
+		}
+		face := strings.IndexByte(patternFaces, lhs[0])
+		switch {
+		case len(lhs) == 1:
+			checks := make([]stickerCheck, 9)
+			for i := range checks {
+				checks[i] = stickerCheck{face: face, index: i, color: color}
+			}
+			return checks, nil
+		case len(lhs) == 2 && lhs[1] >= '0' && lhs[1] <= '8':
+			return []stickerCheck{{face: face, index: int(lhs[1] - '0'), color: color}}, nil
+		default:
+			return nil, fmt.Errorf("sticker %q is not a face and index 0-8", lhs)
+		}
+	}
+
+	if checks, ok := patternPiece(atom); ok {
+		return checks, nil
+	}
+	return nil, fmt.Errorf("not a piece, group or sticker")
+}
+
+// patternPiece returns the checks for a piece being solved.
+func patternPiece(name string) ([]stickerCheck, bool) {
+	letters := []byte(strings.ToUpper(name))
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+	facelets, ok := patternPieces[string(letters)]
+	if !ok {
+		return nil, false
+	}
+	checks := make([]stickerCheck, len(facelets))
+	for i, f := range facelets {
+		checks[i] = stickerCheck{face: f[0], index: f[1], center: true}
+	}
+	return checks, true
+}
+
+func parsePatternColor(s string) (Color, bool) {
+	for c := White; c <= Orange; c++ {
+		if strings.EqualFold(s, c.String()) {
+			return c, true
+		}
+	}
+	return 0, false
+}
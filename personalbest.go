@@ -0,0 +1,158 @@
+package gocube
+
+import (
+	"sort"
+	"time"
+)
+
+// PersonalBestKind is what a personal best is the best of.
+type PersonalBestKind int
+
+const (
+	PBSingle PersonalBestKind = iota // One solve
+	PBAo5                            // Average of 5
+	PBAo12                           // Average of 12
+)
+
+// String returns "single", "ao5" or "ao12".
+func (k PersonalBestKind) String() string {
+	switch k {
+	case PBSingle:
+		return "single"
+	case PBAo5:
+		return "ao5"
+	case PBAo12:
+		return "ao12"
+	default:
+		return "unknown"
+	}
+}
+
+// size returns the number of solves averaged, 1 for a single.
+func (k PersonalBestKind) size() int {
+	switch k {
+	case PBAo5:
+		return 5
+	case PBAo12:
+		return 12
+	default:
+		return 1
+	}
+}
+
+// personalBestKinds lists the kinds checked after each solve.
+var personalBestKinds = []PersonalBestKind{PBSingle, PBAo5, PBAo12}
+
+// PersonalBest is a new best set by a solve.
+type PersonalBest struct {
+	Kind     PersonalBestKind
+	Time     time.Duration // The new best, penalties included
+	Previous time.Duration // The best it beat
+}
+
+// Improvement returns how much faster the new best is.
+func (pb PersonalBest) Improvement() time.Duration {
+	return pb.Previous - pb.Time
+}
+
+// maxTimerResults is how many results a Timer keeps for its averages.
+const maxTimerResults = 12
+
+// OnPersonalBest sets a callback for when a solve sets a new best single,
+// ao5 or ao12. It fires after OnTimerStop, once for each kind improved.
+// The first result of each kind sets the best without firing; carry bests
+// over from earlier sessions with SetPersonalBest.
+func (t *Timer) OnPersonalBest(cb func(PersonalBest)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onPersonalBest = cb
+}
+
+// SetPersonalBest sets the best of kind to beat, e.g. one loaded from
+// storage. Zero clears it.
+func (t *Timer) SetPersonalBest(kind PersonalBestKind, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if kind >= 0 && int(kind) < len(t.bests) {
+		t.bests[kind] = d
+	}
+}
+
+// PersonalBests returns the best single, ao5 and ao12 so far, keyed by
+// kind. Kinds without a result are left out.
+func (t *Timer) PersonalBests() map[PersonalBestKind]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	bests := make(map[PersonalBestKind]time.Duration)
+	for _, kind := range personalBestKinds {
+		if t.bests[kind] > 0 {
+			bests[kind] = t.bests[kind]
+		}
+	}
+	return bests
+}
+
+// recordResultLocked adds a finished solve to the results and returns the
+// bests it improved. The caller must hold mu.
+func (t *Timer) recordResultLocked(r TimerResult) []PersonalBest {
+	t.results = append(t.results, r)
+	if len(t.results) > maxTimerResults {
+		t.results = t.results[len(t.results)-maxTimerResults:]
+	}
+
+	var improved []PersonalBest
+	for _, kind := range personalBestKinds {
+		d, ok := averageOfResults(t.results, kind.size())
+		if !ok {
+			continue
+		}
+		prev := t.bests[kind]
+		if prev == 0 || d < prev {
+			t.bests[kind] = d
+			if prev > 0 {
+				improved = append(improved, PersonalBest{Kind: kind, Time: d, Previous: prev})
+			}
+		}
+	}
+	return improved
+}
+
+// averageOfResults returns the WCA average of the last n results, or the
+// last result for n = 1: the fastest and slowest are dropped and the rest
+// averaged, with a DNF counting as the slowest. It returns false with
+// fewer than n results or for a DNF.
+func averageOfResults(results []TimerResult, n int) (time.Duration, bool) {
+	if len(results) < n {
+		return 0, false
+	}
+	window := results[len(results)-n:]
+	if n == 1 {
+		if window[0].Penalty == PenaltyDNF {
+			return 0, false
+		}
+		return window[0].Final(), true
+	}
+
+	times := make([]time.Duration, 0, n)
+	dnfs := 0
+	for _, r := range window {
+		if r.Penalty == PenaltyDNF {
+			dnfs++
+			continue
+		}
+		times = append(times, r.Final())
+	}
+	if dnfs > 1 {
+		return 0, false
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	kept := times[1:]
+	if dnfs == 0 {
+		kept = times[1 : n-1]
+	}
+	var sum time.Duration
+	for _, d := range kept {
+		sum += d
+	}
+	return sum / time.Duration(len(kept)), true
+}
@@ -1,8 +1,10 @@
 package gocube
 
-// Phase represents the current solving phase in the layer-by-layer method.
-// Phases progress from Scrambled (0) to Solved (7), allowing comparison
-// with < and > operators.
+// Phase represents the current solving phase in the layer-by-layer method,
+// or in another method chosen with WithMethod. The layer-by-layer phases
+// progress from Scrambled (0) to Solved (7), allowing comparison with < and
+// > operators. The Roux and ZZ phases follow PhaseSolved in value, so
+// compare them with After.
 type Phase int
 
 const (
@@ -36,6 +38,27 @@ const (
 
 	// PhaseSolved indicates the cube is completely solved.
 	PhaseSolved
+
+	// PhaseFirstBlock indicates the first Roux block is complete: a 1x2x3
+	// block on the white face, at the left or right of any side.
+	PhaseFirstBlock
+
+	// PhaseSecondBlock indicates the second Roux block is complete,
+	// opposite the first. The M slice between them may be turned.
+	PhaseSecondBlock
+
+	// PhaseCMLL indicates the yellow corners are solved with the blocks,
+	// up to a turn of the last layer. Last six edges (LSE) remain.
+	PhaseCMLL
+
+	// PhaseEOLine indicates the ZZ EOLine is complete: every edge is
+	// oriented and two opposite white edges (UF and UB, or UL and UR)
+	// are solved.
+	PhaseEOLine
+
+	// PhaseZZF2L indicates the first two layers are complete with the
+	// yellow edges oriented. The last layer (ZZ LL) remains.
+	PhaseZZF2L
 )
 
 // String returns a short identifier for the phase.
@@ -57,6 +80,16 @@ func (p Phase) String() string {
 		return "yellow_oriented"
 	case PhaseSolved:
 		return "solved"
+	case PhaseFirstBlock:
+		return "first_block"
+	case PhaseSecondBlock:
+		return "second_block"
+	case PhaseCMLL:
+		return "cmll"
+	case PhaseEOLine:
+		return "eoline"
+	case PhaseZZF2L:
+		return "zz_f2l"
 	default:
 		return "unknown"
 	}
@@ -81,6 +114,16 @@ func (p Phase) DisplayName() string {
 		return "Yellow Corners Oriented"
 	case PhaseSolved:
 		return "Solved"
+	case PhaseFirstBlock:
+		return "First Block"
+	case PhaseSecondBlock:
+		return "Second Block"
+	case PhaseCMLL:
+		return "CMLL"
+	case PhaseEOLine:
+		return "EOLine"
+	case PhaseZZF2L:
+		return "ZZ F2L"
 	default:
 		return "Unknown"
 	}
@@ -91,7 +134,16 @@ func (p Phase) IsComplete() bool {
 	return p == PhaseSolved
 }
 
-// Progress represents which phases have been completed.
+// After reports whether p comes after q in a solve by one method: p > q,
+// except that PhaseSolved comes after every other phase.
+func (p Phase) After(q Phase) bool {
+	if p == PhaseSolved || q == PhaseSolved {
+		return p == PhaseSolved && q != PhaseSolved
+	}
+	return p > q
+}
+
+// Progress represents which phases have been completed, in each method.
 type Progress struct {
 	WhiteCross     bool
 	FirstLayer     bool
@@ -100,4 +152,13 @@ type Progress struct {
 	YellowCorners  bool
 	YellowOriented bool
 	Solved         bool
+
+	// Roux; LSE is done when the cube is solved
+	FirstBlock  bool
+	SecondBlock bool
+	CMLL        bool
+
+	// ZZ; the last layer is done when the cube is solved
+	EOLine bool
+	ZZF2L  bool
 }
@@ -0,0 +1,91 @@
+package gocube
+
+import (
+	"math"
+	"time"
+)
+
+// Quaternion is a rotation as a unit quaternion. For a cube's attitude it
+// rotates the cube from the pose its sensor takes as home to the pose it
+// is held in, in axes with x to the right, y up and z towards the solver
+// (the convention of WebGL libraries such as three.js).
+type Quaternion struct {
+	X, Y, Z, W float64
+}
+
+// IdentityQuaternion is the rotation that leaves everything in place.
+var IdentityQuaternion = Quaternion{W: 1}
+
+// Normalize returns q scaled to unit length, or the identity if q is zero.
+// GoCube reports raw sensor values that are not unit length.
+func (q Quaternion) Normalize() Quaternion {
+	n := math.Sqrt(q.X*q.X + q.Y*q.Y + q.Z*q.Z + q.W*q.W)
+	if n == 0 {
+		return IdentityQuaternion
+	}
+	return Quaternion{q.X / n, q.Y / n, q.Z / n, q.W / n}
+}
+
+// Dot returns the dot product of q and r; for unit quaternions its
+// absolute value is the cosine of half the angle between them.
+func (q Quaternion) Dot(r Quaternion) float64 {
+	return q.X*r.X + q.Y*r.Y + q.Z*r.Z + q.W*r.W
+}
+
+// Slerp interpolates along the shortest arc from q (t = 0) to r (t = 1).
+// Both must be unit quaternions.
+func (q Quaternion) Slerp(r Quaternion, t float64) Quaternion {
+	d := q.Dot(r)
+	if d < 0 {
+		// r and -r are the same rotation; take the shorter way round
+		r = Quaternion{-r.X, -r.Y, -r.Z, -r.W}
+		d = -d
+	}
+	if d > 0.9995 {
+		// Nearly equal: linear interpolation avoids dividing by ~0
+		return Quaternion{
+			q.X + t*(r.X-q.X),
+			q.Y + t*(r.Y-q.Y),
+			q.Z + t*(r.Z-q.Z),
+			q.W + t*(r.W-q.W),
+		}.Normalize()
+	}
+	theta := math.Acos(d)
+	sin := math.Sin(theta)
+	a := math.Sin((1-t)*theta) / sin
+	b := math.Sin(t*theta) / sin
+	return Quaternion{
+		a*q.X + b*r.X,
+		a*q.Y + b*r.Y,
+		a*q.Z + b*r.Z,
+		a*q.W + b*r.W,
+	}
+}
+
+// attitudeFilter low-pass filters a stream of attitude samples and limits
+// how often they are passed on.
+type attitudeFilter struct {
+	smoothing float64       // Weight of the previous estimate, 0 for none
+	interval  time.Duration // Minimum time between outputs, 0 for none
+
+	estimate Quaternion
+	started  bool
+	lastOut  time.Time
+}
+
+// update folds sample, taken at now, into the estimate and returns it, and
+// whether it is due to be passed on.
+func (f *attitudeFilter) update(sample Quaternion, now time.Time) (Quaternion, bool) {
+	sample = sample.Normalize()
+	if !f.started || f.smoothing <= 0 {
+		f.estimate = sample
+		f.started = true
+	} else {
+		f.estimate = f.estimate.Slerp(sample, 1-f.smoothing)
+	}
+	if f.interval > 0 && !f.lastOut.IsZero() && now.Sub(f.lastOut) < f.interval {
+		return f.estimate, false
+	}
+	f.lastOut = now
+	return f.estimate, true
+}
@@ -0,0 +1,65 @@
+package gocube
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SeamusWaldron/gocube_ble_library/render"
+)
+
+// Render draws the cube's net for a terminal, in color and with Unicode
+// blocks if opts ask for them. The zero Options draw it as String does.
+func (c *Cube) Render(opts render.Options) string {
+	return render.Net(c.renderFacelets(), opts)
+}
+
+// Animate returns the frames of moves being applied to c, for render.Play
+// or a TUI to show one after another: one per move with the face about to
+// turn highlighted, then the result. The caption shows the moves with the
+// next one in brackets. c is not changed.
+func (c *Cube) Animate(moves []Move) []render.Frame {
+	cube := c.Clone()
+	frames := make([]render.Frame, 0, len(moves)+1)
+	for i, m := range moves {
+		turning := ""
+		if m.Face.IsOuter() {
+			turning = string(m.Face)
+		}
+		frames = append(frames, render.Frame{
+			Facelets: cube.renderFacelets(),
+			Turning:  turning,
+			Caption:  animationCaption(moves, i),
+		})
+		cube.Apply(m)
+	}
+	return append(frames, render.Frame{
+		Facelets: cube.renderFacelets(),
+		Caption:  animationCaption(moves, len(moves)),
+	})
+}
+
+// animationCaption lists moves with moves[next] in brackets and the
+// progress, e.g. "R U [R'] U'  (3/4)".
+func animationCaption(moves []Move, next int) string {
+	parts := make([]string, len(moves))
+	for i, m := range moves {
+		parts[i] = m.Notation()
+		if i == next {
+			parts[i] = "[" + parts[i] + "]"
+		}
+	}
+	if next >= len(moves) {
+		return fmt.Sprintf("%s  (done)", strings.Join(parts, " "))
+	}
+	return fmt.Sprintf("%s  (%d/%d)", strings.Join(parts, " "), next+1, len(moves))
+}
+
+func (c *Cube) renderFacelets() render.Facelets {
+	var f render.Facelets
+	for face := range c.Facelets {
+		for i, color := range c.Facelets[face] {
+			f[face][i] = uint8(color)
+		}
+	}
+	return f
+}
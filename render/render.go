@@ -0,0 +1,186 @@
+// Package render draws cube states in a terminal: the unfolded net of a
+// cube with ANSI-colored facelets, as letters or Unicode blocks, and
+// animations of an algorithm being applied one move at a time.
+//
+// Most callers go through the gocube package:
+//
+//	fmt.Print(cube.Render(render.Options{Color: true, Style: render.Blocks}))
+//
+//	moves, _ := gocube.ParseMoves("R U R' U'")
+//	frames := cube.Animate(moves)
+//	render.Play(ctx, os.Stdout, frames, 500*time.Millisecond, render.Options{Color: true})
+//
+// The package does not depend on gocube, so it can draw states from any
+// source laid out as Facelets.
+package render
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Facelets is a cube state: faces in the order U, D, F, B, R, L, each with
+// its facelets indexed
+//
+//	0 1 2
+//	3 4 5
+//	6 7 8
+//
+// and colors 0-5 for white, yellow, green, blue, red and orange, as in
+// gocube.Cube.
+type Facelets [6][9]uint8
+
+// faceOrder names the faces of Facelets in order.
+const faceOrder = "UDFBRL"
+
+// Style is how a facelet is drawn.
+type Style int
+
+const (
+	Letters Style = iota // The color's letter, as Cube.String draws it
+	Blocks               // A block of the color; needs Color, else letters are drawn
+)
+
+// ParseStyle returns the style called "letters" or "blocks".
+func ParseStyle(s string) (Style, error) {
+	switch strings.ToLower(s) {
+	case "letters", "":
+		return Letters, nil
+	case "blocks":
+		return Blocks, nil
+	default:
+		return Letters, fmt.Errorf("unknown style %q (letters or blocks)", s)
+	}
+}
+
+// Options control how a net is drawn. The zero Options draw it as
+// gocube.Cube.String does.
+type Options struct {
+	Color bool  // Color facelets with ANSI escapes (256-color)
+	Style Style // Letters or Blocks
+
+	// Highlight is a face (U, D, F, B, R or L) to pick out, e.g. the face
+	// about to turn: in color it is drawn bold and underlined, or with
+	// shaded blocks, and without color in lower case.
+	Highlight string
+}
+
+// colorLetters are the letters of the colors 0-5.
+const colorLetters = "WYGBRO"
+
+// colorCodes are the 256-color palette entries of the colors 0-5.
+var colorCodes = [6]int{231, 226, 34, 21, 196, 208}
+
+const reset = "\x1b[0m"
+
+// Net draws the cube unfolded: U above, L F R B in a row, D below.
+func Net(f Facelets, opts Options) string {
+	width := 2 // A facelet and a space
+	if opts.Style == Blocks && opts.Color {
+		width = 3
+	}
+	indent := strings.Repeat(" ", 3*width)
+
+	var b strings.Builder
+	row := func(face, r int) {
+		for col := 0; col < 3; col++ {
+			b.WriteString(facelet(f[face][r*3+col], opts, opts.Highlight == faceOrder[face:face+1]))
+			b.WriteByte(' ')
+		}
+	}
+	for r := 0; r < 3; r++ {
+		b.WriteString(indent)
+		row(0, r)
+		b.WriteByte('\n')
+	}
+	for r := 0; r < 3; r++ {
+		for _, face := range []int{5, 2, 4, 3} {
+			row(face, r)
+		}
+		b.WriteByte('\n')
+	}
+	for r := 0; r < 3; r++ {
+		b.WriteString(indent)
+		row(1, r)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// facelet draws one facelet.
+func facelet(color uint8, opts Options, highlight bool) string {
+	letter := "?"
+	if int(color) < len(colorLetters) {
+		letter = colorLetters[color : color+1]
+	}
+	if !opts.Color || int(color) >= len(colorCodes) {
+		if highlight {
+			return strings.ToLower(letter)
+		}
+		return letter
+	}
+
+	fg := fmt.Sprintf("\x1b[38;5;%dm", colorCodes[color])
+	switch {
+	case opts.Style == Blocks && highlight:
+		return fg + "▓▓" + reset
+	case opts.Style == Blocks:
+		return fg + "██" + reset
+	case highlight:
+		return "\x1b[1;4m" + fg + letter + reset
+	default:
+		return "\x1b[1m" + fg + letter + reset
+	}
+}
+
+// Frame is one step of an animation: a cube state, the face about to turn
+// and a caption, e.g. the algorithm with the next move picked out.
+type Frame struct {
+	Facelets Facelets
+	Turning  string // Face about to turn, highlighted; "" for none
+	Caption  string
+}
+
+// Render draws the frame's net, highlighting the turning face, and its
+// caption below it.
+func (fr Frame) Render(opts Options) string {
+	opts.Highlight = fr.Turning
+	s := Net(fr.Facelets, opts)
+	if fr.Caption != "" {
+		s += fr.Caption + "\n"
+	}
+	return s
+}
+
+// Play draws frames on w one after another, every interval, each over the
+// one before, and returns when the last has been shown or ctx is done. w
+// should be a terminal that understands ANSI cursor movement.
+func Play(ctx context.Context, w io.Writer, frames []Frame, interval time.Duration, opts Options) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lines := 0
+	for i, fr := range frames {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		s := fr.Render(opts)
+		out := s
+		if lines > 0 {
+			// Back to the top of the previous frame, clearing it
+			out = fmt.Sprintf("\x1b[%dA\x1b[J", lines) + s
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+		lines = strings.Count(s, "\n")
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+//go:build !js
+
+package gocube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+)
+
+// ReplayEvent is one event of a recorded session: a move or an orientation
+// change.
+type ReplayEvent struct {
+	Time        time.Time
+	Move        *Move        // Set for a move
+	Orientation *Orientation // Set for an orientation change
+}
+
+// Replayer plays back a recorded session through the same callbacks as a
+// connected GoCube, so code written against a live cube can be run and
+// tested without hardware.
+//
+// Create a Replayer from a session log with LoadReplayLog, from recorded
+// events with NewReplayer, or from a stored solve with the storage
+// package. The cube model starts solved, like a newly connected cube.
+//
+//	r, err := gocube.LoadReplayLog("solve_20240101_120000.jsonl")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	r.OnPhaseChange(func(p gocube.Phase) { fmt.Println("Completed:", p) })
+//	r.SetSpeed(4) // 4x; 0 plays without waiting
+//	r.Run(context.Background())
+//
+// Callbacks run on the goroutine calling Run or Step.
+type Replayer struct {
+	events []ReplayEvent
+
+	mu           sync.RWMutex
+	next         int
+	speed        float64
+	cube         *Cube
+	method       string // Key of methodPhases
+	highestPhase Phase
+	orientation  Orientation
+
+	// Callbacks
+	onMove        func(Move)
+	onNormalized  func(Move)
+	onPhaseChange func(Phase)
+	onOrientation func(Orientation)
+	onSolved      func()
+}
+
+// NewReplayer creates a replayer for events, which are played in time
+// order.
+func NewReplayer(events []ReplayEvent) *Replayer {
+	sorted := append([]ReplayEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	return &Replayer{
+		events: sorted,
+		speed:  1,
+		cube:   NewCube(),
+		method: MethodLayerByLayer,
+	}
+}
+
+// replayLogEvent is the part of a session log event the replayer uses.
+type replayLogEvent struct {
+	Timestamp  time.Time `json:"timestamp"`
+	EventType  string    `json:"event_type"`
+	BLEType    byte      `json:"ble_type"`
+	BLEPayload []byte    `json:"ble_payload"`
+}
+
+// LoadReplayLog creates a replayer for a session log written by the CLI's
+// record command (~/.gocube_recorder/logs/*.jsonl). Its moves and
+// orientation changes are replayed; key presses and marked phases are not.
+func LoadReplayLog(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	var events []ReplayEvent
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if lineNum == 1 {
+			continue // Header
+		}
+
+		var e replayLogEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse log line %d: %w", lineNum, err)
+		}
+		if e.EventType != "ble_message" {
+			continue
+		}
+
+		switch e.BLEType {
+		case protocol.MsgTypeRotation:
+			rotations, err := protocol.DecodeRotation(e.BLEPayload)
+			if err != nil {
+				continue
+			}
+			for i, rot := range rotations {
+				move := rotationToMove(rot, e.Timestamp)
+				move.BatchIndex, move.BatchSize = i, len(rotations)
+				events = append(events, ReplayEvent{Time: e.Timestamp, Move: &move})
+			}
+		case protocol.MsgTypeOrientation:
+			orient, err := protocol.DecodeOrientation(e.BLEPayload)
+			if err != nil {
+				continue
+			}
+			o := Orientation{UpFace: Face(orient.UpFace), FrontFace: Face(orient.FrontFace)}
+			events = append(events, ReplayEvent{Time: e.Timestamp, Orientation: &o})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	return NewReplayer(events), nil
+}
+
+// Event callbacks
+
+// OnMove sets a callback that fires for each replayed move.
+func (r *Replayer) OnMove(cb func(Move)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onMove = cb
+}
+
+// OnNormalizedMove sets a callback that fires for each replayed move, with
+// its face remapped by the last replayed orientation (see
+// GoCube.OnNormalizedMove).
+func (r *Replayer) OnNormalizedMove(cb func(Move)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onNormalized = cb
+}
+
+// OnPhaseChange sets a callback that fires when a solving phase is completed.
+func (r *Replayer) OnPhaseChange(cb func(Phase)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onPhaseChange = cb
+}
+
+// OnOrientationChange sets a callback for replayed orientation changes.
+func (r *Replayer) OnOrientationChange(cb func(Orientation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onOrientation = cb
+}
+
+// OnSolved sets a callback that fires when the cube reaches the solved state.
+func (r *Replayer) OnSolved(cb func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onSolved = cb
+}
+
+// Playback
+
+// SetSpeed sets the playback speed as a multiple of the recorded pace. A
+// speed of 0 or less plays every event without waiting. The default is 1.
+func (r *Replayer) SetSpeed(speed float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speed = speed
+}
+
+// SetMethod sets the solving method whose phases OnPhaseChange and
+// HighestPhase report, as WithMethod does for a live cube. Set it before
+// playing; see MethodPhases for the methods.
+func (r *Replayer) SetMethod(method string) error {
+	key, err := methodKey(method)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.method = key
+	return nil
+}
+
+// Run plays the remaining events, waiting between them at the set speed,
+// and returns when they have all been played or ctx is done.
+func (r *Replayer) Run(ctx context.Context) error {
+	for {
+		r.mu.RLock()
+		if r.next >= len(r.events) {
+			r.mu.RUnlock()
+			return nil
+		}
+		var wait time.Duration
+		if r.speed > 0 && r.next > 0 {
+			gap := r.events[r.next].Time.Sub(r.events[r.next-1].Time)
+			wait = time.Duration(float64(gap) / r.speed)
+		}
+		r.mu.RUnlock()
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		r.Step()
+	}
+}
+
+// Step plays the next event at once. It returns false if every event has
+// been played.
+func (r *Replayer) Step() bool {
+	r.mu.Lock()
+	if r.next >= len(r.events) {
+		r.mu.Unlock()
+		return false
+	}
+	event := r.events[r.next]
+	r.next++
+
+	if event.Orientation != nil {
+		r.orientation = *event.Orientation
+		cb := r.onOrientation
+		r.mu.Unlock()
+
+		if cb != nil {
+			cb(*event.Orientation)
+		}
+		return true
+	}
+	if event.Move == nil {
+		r.mu.Unlock()
+		return true
+	}
+
+	move := *event.Move
+	r.cube.Apply(move)
+	currentPhase := r.cube.methodPhase(r.method)
+	phaseChanged := currentPhase.After(r.highestPhase)
+	if phaseChanged {
+		r.highestPhase = currentPhase
+	}
+	phaseCallback, solvedCallback := r.onPhaseChange, r.onSolved
+	moveCallback, normalizedCallback := r.onMove, r.onNormalized
+	orientation := r.orientation
+	r.mu.Unlock()
+
+	// Same order as a live cube
+	if phaseChanged && phaseCallback != nil {
+		phaseCallback(currentPhase)
+	}
+	if currentPhase == PhaseSolved && phaseChanged && solvedCallback != nil {
+		solvedCallback()
+	}
+	if moveCallback != nil {
+		moveCallback(move)
+	}
+	if normalizedCallback != nil {
+		normalizedCallback(NormalizeMove(move, orientation))
+	}
+	return true
+}
+
+// Reset rewinds to the first event with the cube model solved.
+func (r *Replayer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next = 0
+	r.cube.Reset()
+	r.highestPhase = PhaseScrambled
+	r.orientation = Orientation{}
+}
+
+// State access
+
+// Cube returns the cube state after the events played so far.
+func (r *Replayer) Cube() *Cube {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cube.Clone()
+}
+
+// HighestPhase returns the highest phase reached so far.
+func (r *Replayer) HighestPhase() Phase {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.highestPhase
+}
+
+// Position returns how many events have been played.
+func (r *Replayer) Position() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.next
+}
+
+// Len returns the number of events.
+func (r *Replayer) Len() int {
+	return len(r.events)
+}
+
+// Duration returns the recorded time from the first event to the last.
+func (r *Replayer) Duration() time.Duration {
+	if len(r.events) == 0 {
+		return 0
+	}
+	return r.events[len(r.events)-1].Time.Sub(r.events[0].Time)
+}
@@ -0,0 +1,123 @@
+package gocube
+
+// The cube model's centers never move, as on a smart cube, whose sensors
+// measure every turn against the centers. A slice or wide move is therefore
+// the outer-face turns it makes relative to the centers plus a rotation of
+// the whole cube, and moves after a rotation turn whichever model face is
+// now in the named position.
+
+// sliceMoves gives each slice and wide move as outer-face turns followed by
+// a rotation, with turns as multiples of the move's own turn: M is R L' x'.
+var sliceMoves = map[Face]struct {
+	turns    []Move
+	rotation Move
+}{
+	FaceM:  {[]Move{R, LPrime}, Move{Face: FaceX, Turn: CCW}},
+	FaceE:  {[]Move{U, DPrime}, Move{Face: FaceY, Turn: CCW}},
+	FaceS:  {[]Move{FPrime, B}, Move{Face: FaceZ, Turn: CW}},
+	FaceRw: {[]Move{L}, Move{Face: FaceX, Turn: CW}},
+	FaceLw: {[]Move{R}, Move{Face: FaceX, Turn: CCW}},
+	FaceUw: {[]Move{D}, Move{Face: FaceY, Turn: CW}},
+	FaceDw: {[]Move{U}, Move{Face: FaceY, Turn: CCW}},
+	FaceFw: {[]Move{B}, Move{Face: FaceZ, Turn: CW}},
+	FaceBw: {[]Move{F}, Move{Face: FaceZ, Turn: CCW}},
+}
+
+// rotationCycles lists, for each rotation, the faces that take the place of
+// the next one: after x the face that was in front is on top.
+var rotationCycles = map[Face][4]CubeFace{
+	FaceX: {CubeFaceU, CubeFaceF, CubeFaceD, CubeFaceB},
+	FaceY: {CubeFaceF, CubeFaceR, CubeFaceB, CubeFaceL},
+	FaceZ: {CubeFaceU, CubeFaceL, CubeFaceD, CubeFaceR},
+}
+
+// moveFrame maps the face in each position to the model face there after
+// rotations. Entries are stored XORed with their position, so the zero
+// frame is the starting orientation.
+type moveFrame [6]CubeFace
+
+// face returns the model face in position pos.
+func (f *moveFrame) face(pos CubeFace) CubeFace {
+	return pos ^ f[pos]
+}
+
+// rotate applies a whole-cube rotation.
+func (f *moveFrame) rotate(m Move) {
+	cycle := rotationCycles[m.Face]
+	quarters := (int(m.Turn) + 4) % 4
+	for q := 0; q < quarters; q++ {
+		var next [4]CubeFace
+		for i := range cycle {
+			next[i] = f.face(cycle[(i+1)%4])
+		}
+		for i, pos := range cycle {
+			f[pos] = next[i] ^ pos
+		}
+	}
+}
+
+// expand appends the outer-face turns m makes on the model to out, mapping
+// faces through the frame and updating it for rotations.
+func (f *moveFrame) expand(m Move, out []Move) []Move {
+	if m.Face.IsOuter() {
+		m.Face = cubeFaceToMoveFace(f.face(moveFaceToCubeFace(m.Face)))
+		return append(out, m)
+	}
+	if _, ok := rotationCycles[m.Face]; ok {
+		f.rotate(m)
+		return out
+	}
+
+	slice, ok := sliceMoves[m.Face]
+	if !ok {
+		return out
+	}
+	for _, t := range slice.turns {
+		out = append(out, Move{
+			Face: cubeFaceToMoveFace(f.face(moveFaceToCubeFace(t.Face))),
+			Turn: scaleTurn(t.Turn, m.Turn),
+			Time: m.Time,
+		})
+	}
+	f.rotate(Move{Face: slice.rotation.Face, Turn: scaleTurn(slice.rotation.Turn, m.Turn)})
+	return out
+}
+
+// scaleTurn returns the turn t repeated as many times as by.
+func scaleTurn(t, by Turn) Turn {
+	if by == Double {
+		return Double
+	}
+	return t * by
+}
+
+// cubeFaceToMoveFace converts CubeFace to Face.
+func cubeFaceToMoveFace(f CubeFace) Face {
+	switch f {
+	case CubeFaceU:
+		return FaceU
+	case CubeFaceD:
+		return FaceD
+	case CubeFaceF:
+		return FaceF
+	case CubeFaceB:
+		return FaceB
+	case CubeFaceR:
+		return FaceR
+	default:
+		return FaceL
+	}
+}
+
+// ExpandMoves rewrites slice moves, wide moves and rotations as the
+// outer-face turns a smart cube reports for them: M2 U becomes R2 L2 D, as
+// after M2 the face that was down is on top. Use it to match algorithms
+// written with them against recorded moves.
+func ExpandMoves(moves []Move) []Move {
+	var frame moveFrame
+	out := make([]Move, 0, len(moves))
+	for _, m := range moves {
+		out = frame.expand(m, out)
+	}
+	return out
+}
@@ -0,0 +1,277 @@
+package gocube
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ScrambleOption configures GenerateScramble.
+type ScrambleOption func(*scrambleConfig)
+
+type scrambleConfig struct {
+	seed    int64
+	hasSeed bool
+	length  int
+}
+
+// WithScrambleSeed makes GenerateScramble deterministic: the same seed
+// always yields the same scramble. By default the seed is time-based.
+func WithScrambleSeed(seed int64) ScrambleOption {
+	return func(c *scrambleConfig) {
+		c.seed = seed
+		c.hasSeed = true
+	}
+}
+
+// WithScrambleLength generates a random-move scramble of n moves instead
+// of a random-state one. Short scrambles suit beginners practising the
+// last steps of a solve; they are not WCA-legal. Zero (default) keeps the
+// random-state scramble.
+func WithScrambleLength(n int) ScrambleOption {
+	return func(c *scrambleConfig) {
+		c.length = n
+	}
+}
+
+// randomStateMaxLen is the length random-state scrambles are searched at
+// first; almost every state has a solution this short that is found quickly.
+const randomStateMaxLen = 21
+
+// GenerateScramble returns a WCA-style random-state scramble: a cube state
+// is drawn uniformly from all reachable states and the returned moves
+// (usually 19-21) turn a solved cube, white on top and green in front, into
+// it. States solvable in fewer than two moves are drawn again, as the WCA
+// requires.
+//
+// The first call builds the solver's lookup tables, which takes a moment;
+// later calls are fast.
+//
+//	scramble := gocube.GenerateScramble()
+//	fmt.Println(gocube.FormatMoves(scramble))
+func GenerateScramble(opts ...ScrambleOption) []Move {
+	cfg := &scrambleConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if !cfg.hasSeed {
+		cfg.seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(cfg.seed))
+
+	if cfg.length > 0 {
+		return randomMoveScramble(rng, cfg.length)
+	}
+
+	for {
+		solution := solveRandomState(rng)
+		if len(solution) < 2 {
+			continue
+		}
+
+		// The scramble is the inverse of the solution
+		scramble := make([]Move, len(solution))
+		for i, m := range solution {
+			scramble[len(solution)-1-i] = toMove(m).Inverse()
+		}
+		return scramble
+	}
+}
+
+// solveRandomState draws a uniformly random cube state and solves it.
+func solveRandomState(rng *rand.Rand) []int {
+	var c cubieCube
+	setPermIndex(c.cp[:], rng.Intn(nCornerPerm))
+	setPermIndex(c.ep[:], rng.Intn(479001600)) // 12!
+	if permParity(c.cp[:]) != permParity(c.ep[:]) {
+		// Swapping two fixed edges pairs odd and even permutations one to
+		// one, so the state stays uniform
+		c.ep[10], c.ep[11] = c.ep[11], c.ep[10]
+	}
+	c.setTwist(rng.Intn(nTwist))
+	c.setFlip(rng.Intn(nFlip))
+
+	for maxLen := randomStateMaxLen; ; maxLen++ {
+		if solution, ok := solveCubie(c, maxLen); ok {
+			return solution
+		}
+	}
+}
+
+// randomMoveScramble returns n random moves, never turning the same face
+// twice in a row or a face between two turns of its opposite.
+func randomMoveScramble(rng *rand.Rand, n int) []Move {
+	moves := make([]Move, 0, n)
+	prev := -1
+	for len(moves) < n {
+		m := rng.Intn(solverMoves)
+		if prev >= 0 && !followsCanonically(prev, m) {
+			continue
+		}
+		moves = append(moves, toMove(m))
+		prev = m
+	}
+	return moves
+}
+
+// ScrambleProgress is how far a scramble has been applied to a cube, as
+// ScrambleTracker reports it after each move.
+type ScrambleProgress struct {
+	Done     int  // Scramble moves applied
+	Total    int  // Scramble moves in all
+	Next     Move // What is left of the next scramble move, while on track
+	OnTrack  bool // Every turn so far was part of the scramble
+	Complete bool // The whole scramble was applied, and nothing else
+
+	// Off track, the first turn that left the scramble: Expected is the
+	// scramble move that was due (zero if the scramble was complete) and
+	// Got what was turned instead. Fix are the turns that get back on track.
+	Expected Move
+	Got      Move
+	Fix      []Move
+}
+
+// String describes the progress, e.g. "3/20, next U2", "expected F2, you
+// did F (fix: R' F)" or "scrambled".
+func (p ScrambleProgress) String() string {
+	switch {
+	case p.Complete:
+		return "scrambled"
+	case p.OnTrack:
+		return fmt.Sprintf("%d/%d, next %s", p.Done, p.Total, p.Next.Notation())
+	case p.Expected.Face == "":
+		return fmt.Sprintf("scramble done, you did %s (fix: %s)", p.Got.Notation(), FormatMoves(p.Fix))
+	default:
+		return fmt.Sprintf("expected %s, you did %s (fix: %s)", p.Expected.Notation(), p.Got.Notation(), FormatMoves(p.Fix))
+	}
+}
+
+// ScrambleTracker checks the moves applied to a cube against a scramble,
+// so a mistake can be pointed out as it is made. Half turns may be made as
+// two quarter turns either way, as smart cubes report them. Turns off the
+// scramble must be undone (see ScrambleProgress.Fix) before it continues.
+//
+// A ScrambleTracker is safe for concurrent use.
+type ScrambleTracker struct {
+	mu       sync.Mutex
+	scramble []Move
+	done     int
+	quarters int    // Quarter turns made of scramble[done], clockwise, 0-3
+	wrong    []Move // Turns off the scramble, oldest first, same faces merged
+	expected Move   // The scramble move due when the first wrong turn was made
+	got      Move
+}
+
+// NewScrambleTracker returns a tracker for scramble, applied to a solved
+// cube from now on.
+func NewScrambleTracker(scramble []Move) *ScrambleTracker {
+	return &ScrambleTracker{scramble: append([]Move(nil), scramble...)}
+}
+
+// Apply checks the next move made and returns the progress after it.
+func (t *ScrambleTracker) Apply(m Move) ScrambleProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.wrong) == 0 && t.done < len(t.scramble) && m.Face == t.scramble[t.done].Face {
+		t.quarters = (t.quarters + quarterTurns(m.Turn)) % 4
+		if t.quarters == quarterTurns(t.scramble[t.done].Turn) {
+			t.done++
+			t.quarters = 0
+		}
+		return t.progressLocked()
+	}
+
+	if len(t.wrong) == 0 {
+		t.expected, t.got = Move{}, m
+		if t.done < len(t.scramble) {
+			t.expected = t.scramble[t.done]
+			if t.quarters != 0 {
+				// The move was started, then left for another face
+				t.got = Move{Face: t.expected.Face, Turn: turnOf(t.quarters)}
+			}
+		}
+	}
+	t.wrong = mergeTurn(t.wrong, m)
+	return t.progressLocked()
+}
+
+// Progress returns the progress so far.
+func (t *ScrambleTracker) Progress() ScrambleProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progressLocked()
+}
+
+// Reset starts checking the scramble again from a solved cube.
+func (t *ScrambleTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done, t.quarters, t.wrong = 0, 0, nil
+}
+
+func (t *ScrambleTracker) progressLocked() ScrambleProgress {
+	p := ScrambleProgress{Done: t.done, Total: len(t.scramble)}
+
+	// What is left of the scramble move in progress
+	var rest []Move
+	if t.quarters != 0 {
+		due := t.scramble[t.done]
+		rest = []Move{{Face: due.Face, Turn: turnOf((quarterTurns(due.Turn) - t.quarters + 4) % 4)}}
+	}
+
+	switch {
+	case len(t.wrong) > 0:
+		p.Expected, p.Got = t.expected, t.got
+		for i := len(t.wrong) - 1; i >= 0; i-- {
+			p.Fix = append(p.Fix, t.wrong[i].Inverse())
+		}
+		p.Fix = append(p.Fix, rest...)
+	case t.quarters != 0 && quarterTurns(t.scramble[t.done].Turn) != 2:
+		// A quarter turn made the wrong way, or twice
+		p.Expected = t.scramble[t.done]
+		p.Got = Move{Face: p.Expected.Face, Turn: turnOf(t.quarters)}
+		p.Fix = rest
+	default:
+		p.OnTrack = true
+		p.Complete = t.done == len(t.scramble)
+		if len(rest) > 0 {
+			p.Next = rest[0]
+		} else if !p.Complete {
+			p.Next = t.scramble[t.done]
+		}
+	}
+	return p
+}
+
+// mergeTurn appends m to turns, merging it into the last turn if both turn
+// the same face and dropping them if they cancel out.
+func mergeTurn(turns []Move, m Move) []Move {
+	if n := len(turns); n > 0 && turns[n-1].Face == m.Face {
+		q := (quarterTurns(turns[n-1].Turn) + quarterTurns(m.Turn)) % 4
+		if q == 0 {
+			return turns[:n-1]
+		}
+		turns[n-1] = Move{Face: m.Face, Turn: turnOf(q)}
+		return turns
+	}
+	return append(turns, Move{Face: m.Face, Turn: m.Turn})
+}
+
+// quarterTurns returns a turn as clockwise quarter turns, 1-3.
+func quarterTurns(t Turn) int {
+	return (int(t) + 4) % 4
+}
+
+// turnOf returns the turn of q clockwise quarter turns, 1-3.
+func turnOf(q int) Turn {
+	switch q {
+	case 2:
+		return Double
+	case 3:
+		return CCW
+	default:
+		return CW
+	}
+}
@@ -0,0 +1,562 @@
+package gocube
+
+import "sync"
+
+// This file implements Kociemba's two-phase algorithm on a cubie-level
+// model of the cube. It is used to find short move sequences that reach a
+// given state, which is how random-state scrambles are generated.
+//
+// Phase 1 brings the cube into the subgroup <U, D, R2, L2, F2, B2>
+// (corners and edges oriented, middle-layer edges in the middle layer);
+// phase 2 solves it using only those moves. Both phases are iterative
+// deepening searches over coordinates, pruned by breadth-first tables.
+
+// Corner cubies, in Kociemba's order
+const (
+	cornerURF = iota
+	cornerUFL
+	cornerULB
+	cornerUBR
+	cornerDFR
+	cornerDLF
+	cornerDBL
+	cornerDRB
+)
+
+// Edge cubies, in Kociemba's order
+const (
+	edgeUR = iota
+	edgeUF
+	edgeUL
+	edgeUB
+	edgeDR
+	edgeDF
+	edgeDL
+	edgeDB
+	edgeFR
+	edgeFL
+	edgeBL
+	edgeBR
+)
+
+// cubieCube describes a cube by which cubie sits in each position and how
+// it is twisted or flipped there.
+type cubieCube struct {
+	cp [8]int8  // Corner permutation
+	co [8]int8  // Corner orientation (0-2)
+	ep [12]int8 // Edge permutation
+	eo [12]int8 // Edge orientation (0-1)
+}
+
+var solvedCubieCube = cubieCube{
+	cp: [8]int8{0, 1, 2, 3, 4, 5, 6, 7},
+	ep: [12]int8{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+}
+
+// faceCubes are the clockwise quarter turns of U, R, F, D, L and B.
+var faceCubes = [6]cubieCube{
+	{ // U
+		cp: [8]int8{cornerUBR, cornerURF, cornerUFL, cornerULB, cornerDFR, cornerDLF, cornerDBL, cornerDRB},
+		ep: [12]int8{edgeUB, edgeUR, edgeUF, edgeUL, edgeDR, edgeDF, edgeDL, edgeDB, edgeFR, edgeFL, edgeBL, edgeBR},
+	},
+	{ // R
+		cp: [8]int8{cornerDFR, cornerUFL, cornerULB, cornerURF, cornerDRB, cornerDLF, cornerDBL, cornerUBR},
+		co: [8]int8{2, 0, 0, 1, 1, 0, 0, 2},
+		ep: [12]int8{edgeFR, edgeUF, edgeUL, edgeUB, edgeBR, edgeDF, edgeDL, edgeDB, edgeDR, edgeFL, edgeBL, edgeUR},
+	},
+	{ // F
+		cp: [8]int8{cornerUFL, cornerDLF, cornerULB, cornerUBR, cornerURF, cornerDFR, cornerDBL, cornerDRB},
+		co: [8]int8{1, 2, 0, 0, 2, 1, 0, 0},
+		ep: [12]int8{edgeUR, edgeFL, edgeUL, edgeUB, edgeDR, edgeFR, edgeDL, edgeDB, edgeUF, edgeDF, edgeBL, edgeBR},
+		eo: [12]int8{0, 1, 0, 0, 0, 1, 0, 0, 1, 1, 0, 0},
+	},
+	{ // D
+		cp: [8]int8{cornerURF, cornerUFL, cornerULB, cornerUBR, cornerDLF, cornerDBL, cornerDRB, cornerDFR},
+		ep: [12]int8{edgeUR, edgeUF, edgeUL, edgeUB, edgeDF, edgeDL, edgeDB, edgeDR, edgeFR, edgeFL, edgeBL, edgeBR},
+	},
+	{ // L
+		cp: [8]int8{cornerURF, cornerULB, cornerDBL, cornerUBR, cornerDFR, cornerUFL, cornerDLF, cornerDRB},
+		co: [8]int8{0, 1, 2, 0, 0, 2, 1, 0},
+		ep: [12]int8{edgeUR, edgeUF, edgeBL, edgeUB, edgeDR, edgeDF, edgeFL, edgeDB, edgeFR, edgeUL, edgeDL, edgeBR},
+	},
+	{ // B
+		cp: [8]int8{cornerURF, cornerUFL, cornerUBR, cornerDRB, cornerDFR, cornerDLF, cornerULB, cornerDBL},
+		co: [8]int8{0, 0, 1, 2, 0, 0, 2, 1},
+		ep: [12]int8{edgeUR, edgeUF, edgeUL, edgeBR, edgeDR, edgeDF, edgeDL, edgeBL, edgeFR, edgeFL, edgeUB, edgeDB},
+		eo: [12]int8{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 1, 1},
+	},
+}
+
+// Moves are indexed 3*face + power, faces in U R F D L B order and powers
+// clockwise, half and counter-clockwise.
+const solverMoves = 18
+
+var (
+	solverFaces = [6]Face{FaceU, FaceR, FaceF, FaceD, FaceL, FaceB}
+	solverTurns = [3]Turn{CW, Double, CCW}
+)
+
+// phase2Moves are the moves that keep a cube in the phase 2 subgroup:
+// U, U2, U', R2, F2, D, D2, D', L2, B2.
+var phase2Moves = []int{0, 1, 2, 4, 7, 9, 10, 11, 13, 16}
+
+// moveCubes[m] is move m applied to a solved cube.
+var moveCubes [solverMoves]cubieCube
+
+func init() {
+	for f := range faceCubes {
+		c := solvedCubieCube
+		for p := 0; p < 3; p++ {
+			c.multiply(&faceCubes[f])
+			moveCubes[3*f+p] = c
+		}
+	}
+}
+
+// toMove converts a solver move index to a Move.
+func toMove(m int) Move {
+	return Move{Face: solverFaces[m/3], Turn: solverTurns[m%3]}
+}
+
+// followsCanonically reports whether move m may follow move prev: never
+// the same face twice, and opposite faces only in U-D, R-L, F-B order.
+func followsCanonically(prev, m int) bool {
+	f, pf := m/3, prev/3
+	return f != pf && f != pf-3
+}
+
+// multiply applies b after c.
+func (c *cubieCube) multiply(b *cubieCube) {
+	var r cubieCube
+	for i := range r.cp {
+		r.cp[i] = c.cp[b.cp[i]]
+		r.co[i] = (c.co[b.cp[i]] + b.co[i]) % 3
+	}
+	for i := range r.ep {
+		r.ep[i] = c.ep[b.ep[i]]
+		r.eo[i] = (c.eo[b.ep[i]] + b.eo[i]) % 2
+	}
+	*c = r
+}
+
+// Coordinate sizes
+const (
+	nTwist      = 2187  // 3^7 corner orientations
+	nFlip       = 2048  // 2^11 edge orientations
+	nSlice      = 495   // 12 choose 4 positions of the middle-layer edges
+	nCornerPerm = 40320 // 8! corner permutations
+	nUDEdgePerm = 40320 // 8! U and D layer edge permutations (phase 2)
+	nSlicePerm  = 24    // 4! middle-layer edge permutations (phase 2)
+)
+
+func (c *cubieCube) twist() int {
+	t := 0
+	for i := 0; i < 7; i++ {
+		t = 3*t + int(c.co[i])
+	}
+	return t
+}
+
+func (c *cubieCube) setTwist(t int) {
+	sum := 0
+	for i := 6; i >= 0; i-- {
+		c.co[i] = int8(t % 3)
+		sum += t % 3
+		t /= 3
+	}
+	c.co[7] = int8((3 - sum%3) % 3)
+}
+
+func (c *cubieCube) flip() int {
+	f := 0
+	for i := 0; i < 11; i++ {
+		f = 2*f + int(c.eo[i])
+	}
+	return f
+}
+
+func (c *cubieCube) setFlip(f int) {
+	sum := 0
+	for i := 10; i >= 0; i-- {
+		c.eo[i] = int8(f % 2)
+		sum += f % 2
+		f /= 2
+	}
+	c.eo[11] = int8(sum % 2)
+}
+
+// slice is the coordinate of which positions hold middle-layer edges,
+// ignoring their order. It is 0 when they are all in the middle layer.
+func (c *cubieCube) slice() int {
+	s, found := 0, 0
+	for j := 11; j >= 0; j-- {
+		if c.ep[j] >= edgeFR {
+			s += binomial(11-j, found+1)
+			found++
+		}
+	}
+	return s
+}
+
+func (c *cubieCube) setSlice(s int) {
+	for j := range c.ep {
+		c.ep[j] = -1
+	}
+	left := 4
+	for j := 0; j < 12 && left > 0; j++ {
+		if b := binomial(11-j, left); s >= b {
+			c.ep[j] = int8(edgeFR + 4 - left)
+			s -= b
+			left--
+		}
+	}
+	other := int8(edgeUR)
+	for j := range c.ep {
+		if c.ep[j] < 0 {
+			c.ep[j] = other
+			other++
+		}
+	}
+}
+
+func (c *cubieCube) cornerPerm() int { return permIndex(c.cp[:]) }
+
+func (c *cubieCube) setCornerPerm(i int) { setPermIndex(c.cp[:], i) }
+
+// udEdgePerm is the order of the U and D layer edges; phase 2 only.
+func (c *cubieCube) udEdgePerm() int { return permIndex(c.ep[:8]) }
+
+func (c *cubieCube) setUDEdgePerm(i int) {
+	setPermIndex(c.ep[:8], i)
+	for j := 8; j < 12; j++ {
+		c.ep[j] = int8(j)
+	}
+}
+
+// slicePerm is the order of the middle-layer edges; phase 2 only.
+func (c *cubieCube) slicePerm() int {
+	var p [4]int8
+	for i := range p {
+		p[i] = c.ep[8+i] - edgeFR
+	}
+	return permIndex(p[:])
+}
+
+func (c *cubieCube) setSlicePerm(i int) {
+	var p [4]int8
+	setPermIndex(p[:], i)
+	for j := 0; j < 8; j++ {
+		c.ep[j] = int8(j)
+	}
+	for j := range p {
+		c.ep[8+j] = p[j] + edgeFR
+	}
+}
+
+// permIndex returns the rank of a permutation of 0..len(p)-1 (Lehmer code).
+func permIndex(p []int8) int {
+	idx := 0
+	for i := range p {
+		idx *= len(p) - i
+		for j := i + 1; j < len(p); j++ {
+			if p[j] < p[i] {
+				idx++
+			}
+		}
+	}
+	return idx
+}
+
+// setPermIndex sets p to the permutation of 0..len(p)-1 with rank idx.
+func setPermIndex(p []int8, idx int) {
+	n := len(p)
+	digits := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		digits[i] = idx % (n - i)
+		idx /= n - i
+	}
+	used := make([]bool, n)
+	for i := range p {
+		k := digits[i]
+		for v := 0; v < n; v++ {
+			if used[v] {
+				continue
+			}
+			if k == 0 {
+				p[i] = int8(v)
+				used[v] = true
+				break
+			}
+			k--
+		}
+	}
+}
+
+// permParity returns 1 for an odd permutation, 0 for an even one.
+func permParity(p []int8) int {
+	inversions := 0
+	for i := range p {
+		for j := i + 1; j < len(p); j++ {
+			if p[j] < p[i] {
+				inversions++
+			}
+		}
+	}
+	return inversions % 2
+}
+
+func binomial(n, k int) int {
+	if k > n {
+		return 0
+	}
+	r := 1
+	for i := 0; i < k; i++ {
+		r = r * (n - i) / (i + 1)
+	}
+	return r
+}
+
+// moveTable[coord][m] is the coordinate after applying move m.
+type moveTable [][solverMoves]uint16
+
+// solverTableSet holds the move and pruning tables.
+type solverTableSet struct {
+	twistMove, flipMove, sliceMove                moveTable
+	cornerPermMove, udEdgePermMove, slicePermMove moveTable
+
+	// Minimum moves to reach phase 1's goal, or phase 2's goal within the
+	// subgroup, from a pair of coordinates
+	twistSlicePrune, flipSlicePrune    []int8
+	cornerSlicePrune, udEdgeSlicePrune []int8
+}
+
+// The tables take a moment to build, so they are built on first use.
+var (
+	solverTablesOnce sync.Once
+	solverTables     solverTableSet
+)
+
+// buildMoveTable computes a move table for the given moves by setting each
+// coordinate on a cube, applying the move and reading the coordinate back.
+func buildMoveTable(n int, moves []int, set func(*cubieCube, int), get func(*cubieCube) int) moveTable {
+	table := make(moveTable, n)
+	for i := 0; i < n; i++ {
+		c := solvedCubieCube
+		set(&c, i)
+		for _, m := range moves {
+			d := c
+			d.multiply(&moveCubes[m])
+			table[i][m] = uint16(get(&d))
+		}
+	}
+	return table
+}
+
+// buildPruneTable computes, breadth first from the solved state, the
+// distance of every pair of coordinates (a, b) under the given moves.
+func buildPruneTable(a, b moveTable, moves []int) []int8 {
+	nB := len(b)
+	table := make([]int8, len(a)*nB)
+	for i := range table {
+		table[i] = -1
+	}
+	table[0] = 0
+	queue := []int32{0}
+	for len(queue) > 0 {
+		i := int(queue[0])
+		queue = queue[1:]
+		ca, cb := i/nB, i%nB
+		for _, m := range moves {
+			j := int(a[ca][m])*nB + int(b[cb][m])
+			if table[j] < 0 {
+				table[j] = table[i] + 1
+				queue = append(queue, int32(j))
+			}
+		}
+	}
+	return table
+}
+
+func buildSolverTables() {
+	t := &solverTables
+	all := make([]int, solverMoves)
+	for m := range all {
+		all[m] = m
+	}
+
+	t.twistMove = buildMoveTable(nTwist, all, (*cubieCube).setTwist, (*cubieCube).twist)
+	t.flipMove = buildMoveTable(nFlip, all, (*cubieCube).setFlip, (*cubieCube).flip)
+	t.sliceMove = buildMoveTable(nSlice, all, (*cubieCube).setSlice, (*cubieCube).slice)
+	t.cornerPermMove = buildMoveTable(nCornerPerm, all, (*cubieCube).setCornerPerm, (*cubieCube).cornerPerm)
+	t.udEdgePermMove = buildMoveTable(nUDEdgePerm, phase2Moves, (*cubieCube).setUDEdgePerm, (*cubieCube).udEdgePerm)
+	t.slicePermMove = buildMoveTable(nSlicePerm, phase2Moves, (*cubieCube).setSlicePerm, (*cubieCube).slicePerm)
+
+	t.twistSlicePrune = buildPruneTable(t.twistMove, t.sliceMove, all)
+	t.flipSlicePrune = buildPruneTable(t.flipMove, t.sliceMove, all)
+	t.cornerSlicePrune = buildPruneTable(t.cornerPermMove, t.slicePermMove, phase2Moves)
+	t.udEdgeSlicePrune = buildPruneTable(t.udEdgePermMove, t.slicePermMove, phase2Moves)
+}
+
+// twoPhaseSearch is the state of one solve.
+type twoPhaseSearch struct {
+	t      *solverTableSet
+	cube   cubieCube
+	maxLen int
+	moves  []int
+	nodes  int // Nodes visited; the search gives up past nodeLimit
+}
+
+// nodeLimit bounds a search at one maximum length, so a hard state moves on
+// to a longer length rather than searching exhaustively.
+const nodeLimit = 2000000
+
+// solveCubie returns a sequence of at most maxLen moves that solves c, or
+// false if none was found within the node limit.
+func solveCubie(c cubieCube, maxLen int) ([]int, bool) {
+	solverTablesOnce.Do(buildSolverTables)
+
+	s := &twoPhaseSearch{t: &solverTables, cube: c, maxLen: maxLen}
+	twist, flip, slice := c.twist(), c.flip(), c.slice()
+	for depth := 0; depth <= maxLen && s.nodes <= nodeLimit; depth++ {
+		if s.phase1(twist, flip, slice, depth) {
+			return s.moves, true
+		}
+	}
+	return nil, false
+}
+
+// phase1 searches for togo more moves reaching the phase 2 subgroup, then
+// hands over to phase 2.
+func (s *twoPhaseSearch) phase1(twist, flip, slice, togo int) bool {
+	s.nodes++
+	if s.nodes > nodeLimit {
+		return false
+	}
+	depth := len(s.moves)
+	if togo == 0 {
+		if twist != 0 || flip != 0 || slice != 0 {
+			return false
+		}
+		// Ending on a phase 2 move means a shorter phase 1 was already tried
+		if depth > 0 && isPhase2Move(s.moves[depth-1]) {
+			return false
+		}
+		return s.startPhase2()
+	}
+	t := s.t
+	if int(t.twistSlicePrune[twist*nSlice+slice]) > togo || int(t.flipSlicePrune[flip*nSlice+slice]) > togo {
+		return false
+	}
+	for m := 0; m < solverMoves; m++ {
+		if depth > 0 && !followsCanonically(s.moves[depth-1], m) {
+			continue
+		}
+		s.moves = append(s.moves, m)
+		if s.phase1(int(t.twistMove[twist][m]), int(t.flipMove[flip][m]), int(t.sliceMove[slice][m]), togo-1) {
+			return true
+		}
+		s.moves = s.moves[:depth]
+	}
+	return false
+}
+
+// startPhase2 solves the cube reached by the phase 1 moves within the
+// remaining length.
+func (s *twoPhaseSearch) startPhase2() bool {
+	c := s.cube
+	for _, m := range s.moves {
+		c.multiply(&moveCubes[m])
+	}
+	cp, ep, sp := c.cornerPerm(), c.udEdgePerm(), c.slicePerm()
+	for togo := 0; togo <= s.maxLen-len(s.moves); togo++ {
+		if s.phase2(cp, ep, sp, togo) {
+			return true
+		}
+	}
+	return false
+}
+
+// phase2 searches for togo more phase 2 moves solving the cube.
+func (s *twoPhaseSearch) phase2(cp, ep, sp, togo int) bool {
+	s.nodes++
+	if s.nodes > nodeLimit {
+		return false
+	}
+	if togo == 0 {
+		return cp == 0 && ep == 0 && sp == 0
+	}
+	t := s.t
+	if int(t.cornerSlicePrune[cp*nSlicePerm+sp]) > togo || int(t.udEdgeSlicePrune[ep*nSlicePerm+sp]) > togo {
+		return false
+	}
+	depth := len(s.moves)
+	for _, m := range phase2Moves {
+		if depth > 0 && !followsCanonically(s.moves[depth-1], m) {
+			continue
+		}
+		s.moves = append(s.moves, m)
+		if s.phase2(int(t.cornerPermMove[cp][m]), int(t.udEdgePermMove[ep][m]), int(t.slicePermMove[sp][m]), togo-1) {
+			return true
+		}
+		s.moves = s.moves[:depth]
+	}
+	return false
+}
+
+// isPhase2Move reports whether m is one of phase2Moves.
+func isPhase2Move(m int) bool {
+	for _, p := range phase2Moves {
+		if p == m {
+			return true
+		}
+	}
+	return false
+}
+
+// SolveSequence returns a short sequence of outer-face turns that solves a
+// solved cube scrambled by moves. Slice and wide moves and rotations are
+// expanded first. Shorter solutions are searched for until one is not found
+// within the search budget, so the result is usually optimal for the short
+// sequences of a single phase but not guaranteed to be; a full scramble
+// typically takes 18-20 moves. The first call builds the solver's lookup
+// tables.
+func SolveSequence(moves []Move) []Move {
+	c := solvedCubieCube
+	for _, m := range ExpandMoves(moves) {
+		c.multiply(&moveCubes[solverMoveIndex(m)])
+	}
+
+	var best []int
+	for maxLen, found := randomStateMaxLen, false; !found; maxLen++ {
+		best, found = solveCubie(c, maxLen)
+	}
+	for len(best) > 0 {
+		solution, ok := solveCubie(c, len(best)-1)
+		if !ok {
+			break
+		}
+		best = solution
+	}
+
+	solution := make([]Move, len(best))
+	for i, m := range best {
+		solution[i] = toMove(m)
+	}
+	return solution
+}
+
+// solverMoveIndex converts an outer-face Move to a solver move index.
+func solverMoveIndex(m Move) int {
+	idx := 0
+	for i, f := range solverFaces {
+		if f == m.Face {
+			idx = 3 * i
+		}
+	}
+	for p, t := range solverTurns {
+		if t == m.Turn {
+			idx += p
+		}
+	}
+	return idx
+}
@@ -0,0 +1,31 @@
+package gocube
+
+import "github.com/SeamusWaldron/gocube_ble_library/internal/protocol"
+
+// protocolColors maps protocol color indices to cube colors. Each color's
+// face in the cube model has the same index as the color.
+var protocolColors = [6]Color{Blue, Green, White, Yellow, Red, Orange}
+
+// CubeFromState builds a cube model from the payload of a GoCube STATE
+// message, the answer to CommandRequestState: its facelets and, where the
+// cube reports them, its center turns.
+func CubeFromState(payload []byte) (*Cube, error) {
+	state, err := protocol.DecodeState(payload)
+	if err != nil {
+		return nil, err
+	}
+	return cubeFromState(state), nil
+}
+
+// cubeFromState builds a cube model from a decoded STATE message.
+func cubeFromState(state *protocol.StateEvent) *Cube {
+	cube := &Cube{}
+	for face := 0; face < 6; face++ {
+		target := CubeFace(protocolColors[face])
+		for i := 0; i < 9; i++ {
+			cube.Facelets[target][i] = protocolColors[state.Facelets[face][i]]
+		}
+		cube.Centers[target] = protocol.CenterQuarterTurns(state.CenterOrientation[face])
+	}
+	return cube
+}
@@ -0,0 +1,90 @@
+package gocube
+
+import "time"
+
+// RollingTPSWindow is the span Stats.RollingTPS is measured over.
+const RollingTPSWindow = 5 * time.Second
+
+// defaultStatsInterval is how often OnStats fires unless WithStatsInterval
+// says otherwise.
+const defaultStatsInterval = time.Second
+
+// Stats summarizes the moves made since connecting or the last
+// ClearHistory.
+type Stats struct {
+	Moves    int
+	Duration time.Duration // First move to last move
+
+	// TPS is turns per second over Duration.
+	TPS float64
+
+	// RollingTPS is turns per second over the last RollingTPSWindow (or
+	// since the first move, if that is shorter). It falls to zero when the
+	// solver stops turning.
+	RollingTPS float64
+
+	// FaceCounts is how often each face was turned.
+	FaceCounts map[Face]int
+}
+
+// statsTracker accumulates Stats move by move. It keeps only the moves in
+// the rolling window, so it works with move history disabled.
+type statsTracker struct {
+	moves      int
+	first      time.Time
+	last       time.Time
+	recent     []time.Time // Move times within RollingTPSWindow of the last move
+	faceCounts map[Face]int
+}
+
+func newStatsTracker() *statsTracker {
+	return &statsTracker{faceCounts: make(map[Face]int)}
+}
+
+func (t *statsTracker) add(m Move) {
+	if t.moves == 0 {
+		t.first = m.Time
+	}
+	t.moves++
+	t.last = m.Time
+	t.faceCounts[m.Face]++
+
+	t.recent = append(t.recent, m.Time)
+	cutoff := m.Time.Add(-RollingTPSWindow)
+	drop := 0
+	for drop < len(t.recent) && !t.recent[drop].After(cutoff) {
+		drop++
+	}
+	t.recent = t.recent[drop:]
+}
+
+// stats returns the statistics as of now.
+func (t *statsTracker) stats(now time.Time) Stats {
+	s := Stats{
+		Moves:      t.moves,
+		Duration:   t.last.Sub(t.first),
+		FaceCounts: make(map[Face]int, len(t.faceCounts)),
+	}
+	for face, n := range t.faceCounts {
+		s.FaceCounts[face] = n
+	}
+	if s.Duration > 0 {
+		s.TPS = float64(s.Moves) / s.Duration.Seconds()
+	}
+
+	window := RollingTPSWindow
+	if since := now.Sub(t.first); since < window {
+		window = since
+	}
+	if t.moves > 0 && window > 0 {
+		cutoff := now.Add(-window)
+		n := 0
+		for _, at := range t.recent {
+			if !at.Before(cutoff) {
+				n++
+			}
+		}
+		s.RollingTPS = float64(n) / window.Seconds()
+	}
+	return s
+}
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	"github.com/SeamusWaldron/gocube_ble_library/internal/app/recorder"
+	appstorage "github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// Recorder records solves to a DB the way the CLI's record command does:
+// moves are stored as the cube reports them, and phases are marked as the
+// cube reaches them, so recorded solves get the same reports.
+//
+// A solve starts solved with Start, then moves scramble the cube until
+// StartInspection. The first move after that starts the solve, which ends
+// by itself when the cube is solved, or with End.
+//
+// Recorder methods may be called from the cube's callbacks.
+type Recorder struct {
+	mu         sync.Mutex
+	db         *appstorage.DB
+	session    *recorder.Session
+	orients    *appstorage.OrientationRepository
+	deviceName string
+	deviceID   string
+	scheme     *recorder.SchemeTracker // nil for layer-by-layer phases
+
+	now        time.Time
+	cube       *gocube.Cube
+	highest    gocube.Phase
+	inspecting bool
+	solving    bool
+	lastOrient gocube.Orientation
+}
+
+// NewRecorder creates a recorder for a cube. deviceID picks the
+// orientation calibration the CLI stored for the cube, if any, and may be
+// empty.
+func (d *DB) NewRecorder(deviceName, deviceID string) *Recorder {
+	r := &Recorder{
+		db:         d.db,
+		session:    recorder.NewSession(d.db, nil),
+		orients:    appstorage.NewOrientationRepository(d.db),
+		deviceName: deviceName,
+		deviceID:   deviceID,
+	}
+	r.session.SetClock(func() time.Time { return r.now })
+	return r
+}
+
+// Start starts recording a solve and returns its ID. The cube must be
+// solved; its moves from here on are the scramble until StartInspection.
+// scramble is the scramble's notation and may be empty. The moves made
+// before StartInspection are checked against it, and the solve is marked
+// ScrambleVerified if they match.
+func (r *Recorder) Start(scramble string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tick(time.Time{})
+	solveID, err := r.session.Start("", scramble, r.deviceName, r.deviceID, "")
+	if err != nil {
+		return "", err
+	}
+	if err := r.session.MarkPhase("scramble", nil); err != nil {
+		return "", err
+	}
+	if r.scheme != nil {
+		context := map[string]string{"phase_scheme": r.scheme.Scheme().Name}
+		if err := appstorage.NewContextRepository(r.db).SetAll(solveID, context); err != nil {
+			return "", err
+		}
+	}
+
+	r.cube = gocube.NewCube()
+	r.highest = gocube.PhaseSolved
+	r.inspecting = false
+	r.solving = false
+	r.lastOrient = gocube.Orientation{}
+	return solveID, nil
+}
+
+// SetMethod splits solves into the phases of method, gocube.MethodRoux or
+// gocube.MethodZZ, as the CLI's built-in phase schemes of the same name do,
+// instead of the default layer-by-layer ones. Solves record the scheme as
+// their "phase_scheme" context. Call it between solves.
+func (r *Recorder) SetMethod(method string) error {
+	if _, err := gocube.MethodPhases(method); err != nil {
+		return err
+	}
+	var tracker *recorder.SchemeTracker
+	if m := strings.ToLower(method); m == gocube.MethodRoux || m == gocube.MethodZZ {
+		scheme, err := recorder.Config{}.PhaseScheme(m)
+		if err != nil {
+			return err
+		}
+		if tracker, err = recorder.NewSchemeTracker(*scheme); err != nil {
+			return err
+		}
+		if err := scheme.RegisterPhaseDefs(r.db); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scheme = tracker
+	return nil
+}
+
+// StartInspection ends the scramble. The next move starts the solve.
+func (r *Recorder) StartInspection() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.inspecting || r.solving {
+		return fmt.Errorf("inspection already started")
+	}
+	r.tick(time.Time{})
+	if err := r.session.MarkPhase("inspection", nil); err != nil {
+		return err
+	}
+	r.inspecting = true
+	return nil
+}
+
+// Move records a move, at m.Time if set. Moves outside a solve are
+// ignored.
+func (r *Recorder) Move(m gocube.Move) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.session.State() != recorder.StateRecording {
+		return nil
+	}
+	r.tick(m.Time)
+
+	// The solve starts just before its first move
+	if r.inspecting {
+		ts := r.session.CurrentTimestamp() - 1
+		if ts < 0 {
+			ts = 0
+		}
+		firstPhase := "white_cross"
+		if r.scheme != nil {
+			firstPhase = r.scheme.Reset()
+		}
+		if err := r.session.MarkPhaseAt(firstPhase, ts, nil); err != nil {
+			return err
+		}
+		r.inspecting = false
+		r.solving = true
+		r.highest = r.cube.Phase()
+	}
+
+	msg, err := recorder.RotationMessage(m)
+	if err != nil {
+		return err
+	}
+	if err := r.session.HandleMessage(msg); err != nil {
+		return err
+	}
+	r.cube.Apply(m)
+
+	if !r.solving {
+		return nil
+	}
+	if r.scheme != nil {
+		if key, ok := r.scheme.Update(r.cube); ok {
+			if err := r.session.MarkPhase(key, nil); err != nil {
+				return err
+			}
+		}
+	} else if phase := r.cube.Phase(); phase > r.highest {
+		r.highest = phase
+		if key, ok := recorder.DerivedPhaseKey(phase); ok {
+			if err := r.session.MarkPhase(key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	if r.cube.IsSolved() {
+		r.solving = false
+		return r.session.End()
+	}
+	return nil
+}
+
+// Orientation records how the cube is held, at t or now if t is zero.
+// Only changes are stored. Orientations outside a solve are ignored.
+func (r *Recorder) Orientation(o gocube.Orientation, t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.session.State() != recorder.StateRecording || o == r.lastOrient {
+		return nil
+	}
+	r.tick(t)
+	tsUs := r.session.CurrentTimestampUs()
+	if _, err := r.orients.Create(r.session.SolveID(), tsUs, string(o.UpFace), string(o.FrontFace), nil); err != nil {
+		return fmt.Errorf("failed to store orientation: %w", err)
+	}
+	r.lastOrient = o
+	return nil
+}
+
+// End ends the solve early, as when it is abandoned.
+func (r *Recorder) End() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tick(time.Time{})
+	r.inspecting = false
+	r.solving = false
+	return r.session.End()
+}
+
+// Recording reports whether a solve is being recorded.
+func (r *Recorder) Recording() bool {
+	return r.session.State() == recorder.StateRecording
+}
+
+// SolveEvent is a solve lifecycle event passed to hooks.
+type SolveEvent struct {
+	Type  string // "solve_start", "phase_change" or "solve_end"
+	At    time.Time
+	Solve Solve
+	Phase string // The phase reached, for phase_change
+
+	// JSON is the event as the CLI passes it to hook commands: the solve
+	// record and, for solve_end, its moves and phases
+	JSON []byte
+}
+
+// Hook receives the lifecycle events of the solves a Recorder records,
+// e.g. to post results to a webhook.
+type Hook interface {
+	HandleSolveEvent(e SolveEvent) error
+}
+
+// AddHook registers a hook for solve_start, phase_change as each phase is
+// reached, and solve_end once the solve's phases are worked out. Hooks
+// run in order on their own goroutine, so a slow hook does not hold up
+// recording; errors go to the OnHookError callback.
+func (r *Recorder) AddHook(h Hook) {
+	r.session.AddHook(recorder.HookFunc(func(e *recorder.HookEvent) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		ev := SolveEvent{Type: e.Event, Phase: e.Phase, JSON: data}
+		ev.At, _ = time.Parse(time.RFC3339Nano, e.At)
+		if e.Solve != nil {
+			ev.Solve = toSolve(e.Solve)
+		}
+		return h.HandleSolveEvent(ev)
+	}))
+}
+
+// OnHookError sets a callback for hooks that fail. It is called on the
+// hooks' goroutine.
+func (r *Recorder) OnHookError(cb func(error)) {
+	r.session.SetHookErrorCallback(cb)
+}
+
+// WaitHooks blocks until the hooks have handled every event so far. Call
+// it before exiting.
+func (r *Recorder) WaitHooks() {
+	r.session.WaitHooks()
+}
+
+// tick sets the session clock to t, or now if t is zero. Callers must hold
+// r.mu.
+func (r *Recorder) tick(t time.Time) {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	r.now = t
+}
@@ -0,0 +1,44 @@
+//go:build !js
+
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+)
+
+func TestRecorderOrientationMicroseconds(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "gocube.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	r := db.NewRecorder("GoCube_Test", "")
+	solveID, err := r.Start("")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	at := time.Now().Add(time.Second)
+	held := []gocube.Orientation{
+		{UpFace: gocube.FaceU, FrontFace: gocube.FaceF},
+		{UpFace: gocube.FaceF, FrontFace: gocube.FaceD},
+	}
+	if err := r.Orientation(held[0], at); err != nil {
+		t.Fatalf("Orientation: %v", err)
+	}
+	if err := r.Orientation(held[1], at.Add(1750*time.Microsecond)); err != nil {
+		t.Fatalf("Orientation: %v", err)
+	}
+
+	changes, err := db.Orientations(solveID)
+	if err != nil || len(changes) != 2 {
+		t.Fatalf("Orientations = %+v, %v; want 2", changes, err)
+	}
+	if d := changes[1].At - changes[0].At; d != 1750*time.Microsecond {
+		t.Errorf("orientations %v apart, want 1.75ms", d)
+	}
+}
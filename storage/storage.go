@@ -0,0 +1,268 @@
+// Package storage records solves from the gocube library to SQLite, in the
+// database format of the gocube CLI. Solves recorded by an application
+// show up in the CLI's reports, trends and exports, and solves recorded
+// with the CLI can be read back here.
+//
+// Record a solve from a connected cube:
+//
+//	db, err := storage.OpenDefault() // ~/.gocube_recorder/gocube.db, shared with the CLI
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer db.Close()
+//
+//	rec := db.NewRecorder("GoCube", "")
+//	cube.OnMove(func(m gocube.Move) { rec.Move(m) })
+//	cube.OnOrientationChange(func(o gocube.Orientation) { rec.Orientation(o, time.Time{}) })
+//
+//	rec.Start("R U F' ...") // With the cube solved, then scramble it
+//	// ...
+//	rec.StartInspection() // The next move starts the solve
+//	// ... the solve ends by itself when the cube is solved
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SeamusWaldron/gocube_ble_library"
+	appstorage "github.com/SeamusWaldron/gocube_ble_library/internal/app/storage"
+)
+
+// DB is a solve database.
+type DB struct {
+	db *appstorage.DB
+}
+
+// Open opens the database at path, creating it and its schema as needed.
+func Open(path string) (*DB, error) {
+	db, err := appstorage.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.MigrateUp(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// OpenDefault opens the gocube CLI's database,
+// ~/.gocube_recorder/gocube.db.
+func OpenDefault() (*DB, error) {
+	path, err := appstorage.DefaultDBPath()
+	if err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Path returns the database file path.
+func (d *DB) Path() string {
+	return d.db.Path()
+}
+
+// Solve is a recorded solve.
+type Solve struct {
+	ID         string
+	StartedAt  time.Time
+	Duration   time.Duration // From start to end, scramble included; 0 while recording
+	Scramble   string
+	DeviceName string
+	Notes      string
+	Manual     bool // Timed manually with no moves
+
+	// ScrambleVerified is set when the moves before inspection matched
+	// Scramble exactly
+	ScrambleVerified bool
+}
+
+// Phase is a solving phase of a recorded solve, with times from the start
+// of the solve. Keys are those of the CLI: "scramble", "inspection",
+// "white_cross", "top_corners", "middle_layer", "bottom_cross",
+// "position_corners", "orient_corners" and "complete", or with a phase
+// scheme (e.g. Roux), the scheme's keys between "inspection" and "complete".
+type Phase struct {
+	Key   string
+	Start time.Duration
+	End   time.Duration
+	Moves int
+}
+
+// OrientationChange is a change of the way the cube was held.
+type OrientationChange struct {
+	At          time.Duration // From the start of the solve
+	Orientation gocube.Orientation
+}
+
+// Solves returns the most recent solves, newest first.
+func (d *DB) Solves(limit int) ([]Solve, error) {
+	records, err := appstorage.NewSolveRepository(d.db).List(limit)
+	if err != nil {
+		return nil, err
+	}
+	solves := make([]Solve, len(records))
+	for i := range records {
+		solves[i] = toSolve(&records[i])
+	}
+	return solves, nil
+}
+
+// Solve returns the solve with the given ID, or nil if there is none.
+func (d *DB) Solve(id string) (*Solve, error) {
+	record, err := appstorage.NewSolveRepository(d.db).Get(id)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	s := toSolve(record)
+	return &s, nil
+}
+
+// Moves returns the moves of a solve, scramble included, with their times.
+func (d *DB) Moves(solveID string) ([]gocube.Move, error) {
+	solve, err := appstorage.NewSolveRepository(d.db).Get(solveID)
+	if err != nil {
+		return nil, err
+	}
+	if solve == nil {
+		return nil, fmt.Errorf("solve %s not found", solveID)
+	}
+	records, err := appstorage.NewMoveRepository(d.db).GetBySolve(solveID)
+	if err != nil {
+		return nil, err
+	}
+	moves := appstorage.ToMoves(records)
+	for i, r := range records {
+		moves[i].Time = solve.StartedAt.Add(time.Duration(r.TsUs) * time.Microsecond)
+	}
+	return moves, nil
+}
+
+// Phases returns the phases of a solve, in order. Phases are worked out
+// when a solve ends, so a solve being recorded has none.
+func (d *DB) Phases(solveID string) ([]Phase, error) {
+	segments, err := appstorage.NewPhaseRepository(d.db).GetPhaseSegments(solveID)
+	if err != nil {
+		return nil, err
+	}
+	phases := make([]Phase, len(segments))
+	for i, seg := range segments {
+		phases[i] = Phase{
+			Key:   seg.PhaseKey,
+			Start: time.Duration(seg.StartTsMs) * time.Millisecond,
+			End:   time.Duration(seg.EndTsMs) * time.Millisecond,
+			Moves: seg.MoveCount,
+		}
+	}
+	return phases, nil
+}
+
+// Orientations returns the orientation changes of a solve.
+func (d *DB) Orientations(solveID string) ([]OrientationChange, error) {
+	records, err := appstorage.NewOrientationRepository(d.db).GetBySolve(solveID)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]OrientationChange, len(records))
+	for i, r := range records {
+		changes[i] = OrientationChange{
+			At:          time.Duration(r.TsUs) * time.Microsecond,
+			Orientation: gocube.Orientation{UpFace: gocube.Face(r.UpFace), FrontFace: gocube.Face(r.FrontFace)},
+		}
+	}
+	return changes, nil
+}
+
+// Replayer returns a replayer for a solve's moves and orientation changes,
+// scramble included, at their recorded times.
+func (d *DB) Replayer(solveID string) (*gocube.Replayer, error) {
+	moves, err := d.Moves(solveID)
+	if err != nil {
+		return nil, err
+	}
+	solve, err := d.Solve(solveID)
+	if err != nil {
+		return nil, err
+	}
+	orientations, err := d.Orientations(solveID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]gocube.ReplayEvent, 0, len(moves)+len(orientations))
+	for i := range moves {
+		events = append(events, gocube.ReplayEvent{Time: moves[i].Time, Move: &moves[i]})
+	}
+	for i := range orientations {
+		events = append(events, gocube.ReplayEvent{
+			Time:        solve.StartedAt.Add(orientations[i].At),
+			Orientation: &orientations[i].Orientation,
+		})
+	}
+	return gocube.NewReplayer(events), nil
+}
+
+// OfflineSync is a stored reading of a cube's offline stats.
+type OfflineSync struct {
+	SyncedAt time.Time
+	Stats    gocube.OfflineStats // As the cube reported them
+
+	// Moves the cube counted since its previous sync, all of Stats.Moves on
+	// the first sync or after the cube reset its counters, and how many of
+	// them were not recorded in the app
+	Moves     int
+	AwayMoves int
+	Reset     bool // The cube's counters restarted since the previous sync
+
+	// LifetimeMoves is every move counted across the cube's syncs, app or
+	// not, and LifetimeAwayMoves those not recorded in the app.
+	LifetimeMoves     int
+	LifetimeAwayMoves int
+}
+
+// SaveOfflineStats stores a cube's offline stats, e.g. from
+// GoCube.SyncOfflineStats, adding what the cube counted since its previous
+// sync to its lifetime totals. deviceID identifies the cube as in
+// NewRecorder (GoCube.DeviceUUID).
+func (d *DB) SaveOfflineStats(deviceID, deviceName string, stats gocube.OfflineStats) (*OfflineSync, error) {
+	repo := appstorage.NewOfflineStatsRepository(d.db)
+	sync, err := repo.Record(deviceID, deviceName, stats.Moves, int(stats.Time/time.Second), stats.Solves)
+	if err != nil {
+		return nil, err
+	}
+	lifetime, err := repo.Lifetime(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return &OfflineSync{
+		SyncedAt:          sync.SyncedAt,
+		Stats:             stats,
+		Moves:             sync.DeltaMoves,
+		AwayMoves:         sync.AwayMoves,
+		Reset:             sync.CounterReset,
+		LifetimeMoves:     lifetime.Moves,
+		LifetimeAwayMoves: lifetime.AwayMoves,
+	}, nil
+}
+
+func toSolve(r *appstorage.Solve) Solve {
+	s := Solve{ID: r.SolveID, StartedAt: r.StartedAt, Manual: r.IsManual(), ScrambleVerified: r.ScrambleVerified}
+	if r.DurationMs != nil {
+		s.Duration = time.Duration(*r.DurationMs) * time.Millisecond
+	}
+	if r.ScrambleText != nil {
+		s.Scramble = *r.ScrambleText
+	}
+	if r.DeviceName != nil {
+		s.DeviceName = *r.DeviceName
+	}
+	if r.Notes != nil {
+		s.Notes = *r.Notes
+	}
+	return s
+}
@@ -0,0 +1,322 @@
+package gocube
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// InspectionTime is the WCA inspection allowance. Starting up to 2 seconds
+// after it costs +2; starting later is a DNF.
+const InspectionTime = 15 * time.Second
+
+// inspectionGrace is how long after the inspection allowance a start is
+// +2 rather than DNF.
+const inspectionGrace = 2 * time.Second
+
+// TimerState is where a Timer is in a solve.
+type TimerState int
+
+const (
+	TimerIdle       TimerState = iota // Not armed; moves are ignored (e.g. scrambling)
+	TimerInspecting                   // Inspection running; the first move starts the timer
+	TimerRunning                      // Solving; stops when the cube is solved
+	TimerStopped                      // Result available
+)
+
+// String returns a short identifier for the state.
+func (s TimerState) String() string {
+	switch s {
+	case TimerIdle:
+		return "idle"
+	case TimerInspecting:
+		return "inspecting"
+	case TimerRunning:
+		return "running"
+	case TimerStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Penalty is a WCA time penalty.
+type Penalty int
+
+const (
+	PenaltyNone  Penalty = iota
+	PenaltyPlus2         // Two seconds added
+	PenaltyDNF           // Did not finish
+)
+
+// String returns "", "+2" or "DNF".
+func (p Penalty) String() string {
+	switch p {
+	case PenaltyPlus2:
+		return "+2"
+	case PenaltyDNF:
+		return "DNF"
+	default:
+		return ""
+	}
+}
+
+// TimerResult is the outcome of a timed solve.
+type TimerResult struct {
+	Time       time.Duration // From the first move to solved, without penalty
+	Inspection time.Duration // Inspection used before the first move
+	Penalty    Penalty
+}
+
+// Final returns the time with a +2 penalty added. For a DNF it returns
+// Time; check Penalty before ranking results.
+func (r TimerResult) Final() time.Duration {
+	if r.Penalty == PenaltyPlus2 {
+		return r.Time + 2*time.Second
+	}
+	return r.Time
+}
+
+// String formats the result as "12.34", "14.34+" or "DNF(12.34)".
+func (r TimerResult) String() string {
+	switch r.Penalty {
+	case PenaltyPlus2:
+		return fmt.Sprintf("%.2f+", r.Final().Seconds())
+	case PenaltyDNF:
+		return fmt.Sprintf("DNF(%.2f)", r.Time.Seconds())
+	default:
+		return fmt.Sprintf("%.2f", r.Time.Seconds())
+	}
+}
+
+// Timer is a smart-cube timer with WCA-style inspection. It is driven by
+// moves rather than a keypress: StartInspection arms it, the first move
+// ends inspection and starts the clock, and the move that solves the cube
+// stops it. Starting late in inspection earns +2 or DNF automatically.
+//
+// Feed it moves with HandleMove, or pass it to Connect with WithTimer to
+// have a GoCube feed it. Timer works standalone, e.g. with a Cube:
+//
+//	timer := gocube.NewTimer()
+//	timer.OnTimerStop(func(r gocube.TimerResult) {
+//	    fmt.Println("Time:", r)
+//	})
+//	timer.StartInspection()
+//	for _, m := range moves {
+//	    cube.Apply(m)
+//	    timer.HandleMove(m, cube.IsSolved())
+//	}
+type Timer struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	limit time.Duration // Inspection allowance; 0 disables inspection penalties
+
+	state        TimerState
+	inspectStart time.Time
+	start        time.Time
+	stop         time.Time
+	penalty      Penalty
+
+	results []TimerResult    // Recent finished solves, oldest first
+	bests   [3]time.Duration // Indexed by PersonalBestKind; 0 if none
+
+	onInspectionStart func()
+	onTimerStart      func()
+	onTimerStop       func(TimerResult)
+	onPersonalBest    func(PersonalBest)
+}
+
+// NewTimer creates an idle timer with the WCA 15 second inspection.
+func NewTimer() *Timer {
+	return &Timer{now: time.Now, limit: InspectionTime}
+}
+
+// SetInspection sets the inspection allowance. The +2 window is the 2
+// seconds after it. 0 disables inspection penalties.
+func (t *Timer) SetInspection(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limit = d
+}
+
+// OnInspectionStart sets a callback for when inspection starts.
+func (t *Timer) OnInspectionStart(cb func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onInspectionStart = cb
+}
+
+// OnTimerStart sets a callback for when the first move starts the clock.
+func (t *Timer) OnTimerStart(cb func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onTimerStart = cb
+}
+
+// OnTimerStop sets a callback for when the solve ends.
+func (t *Timer) OnTimerStop(cb func(TimerResult)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onTimerStop = cb
+}
+
+// StartInspection arms the timer and starts inspection. It restarts
+// inspection if already inspecting and discards a running solve.
+func (t *Timer) StartInspection() {
+	t.mu.Lock()
+	t.state = TimerInspecting
+	t.inspectStart = t.now()
+	t.start, t.stop = time.Time{}, time.Time{}
+	t.penalty = PenaltyNone
+	cb := t.onInspectionStart
+	t.mu.Unlock()
+
+	if cb != nil {
+		cb()
+	}
+}
+
+// HandleMove advances the timer for a move. solved reports whether the
+// cube is solved after the move. The move's Time is used if set, so
+// timestamps from the cube are not skewed by callback delays.
+func (t *Timer) HandleMove(m Move, solved bool) {
+	at := m.Time
+	t.mu.Lock()
+	if at.IsZero() {
+		at = t.now()
+	}
+
+	var started func()
+	var stopped func(TimerResult)
+	var result TimerResult
+	var bests []PersonalBest
+	var onBest func(PersonalBest)
+
+	if t.state == TimerInspecting {
+		t.state = TimerRunning
+		t.start = at
+		t.penalty = t.inspectionPenalty(at.Sub(t.inspectStart))
+		started = t.onTimerStart
+	}
+	if t.state == TimerRunning && solved {
+		t.state = TimerStopped
+		t.stop = at
+		result = t.resultLocked()
+		stopped = t.onTimerStop
+		bests = t.recordResultLocked(result)
+		onBest = t.onPersonalBest
+	}
+	t.mu.Unlock()
+
+	if started != nil {
+		started()
+	}
+	if stopped != nil {
+		stopped(result)
+	}
+	if onBest != nil {
+		for _, pb := range bests {
+			onBest(pb)
+		}
+	}
+}
+
+// inspectionPenalty returns the penalty for starting after used inspection.
+func (t *Timer) inspectionPenalty(used time.Duration) Penalty {
+	switch {
+	case t.limit <= 0 || used <= t.limit:
+		return PenaltyNone
+	case used <= t.limit+inspectionGrace:
+		return PenaltyPlus2
+	default:
+		return PenaltyDNF
+	}
+}
+
+// SetPenalty overrides the penalty of a running or stopped solve, e.g. for
+// a +2 the cube cannot detect.
+func (t *Timer) SetPenalty(p Penalty) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state == TimerRunning || t.state == TimerStopped {
+		t.penalty = p
+	}
+}
+
+// Reset returns the timer to idle, discarding any result. Finished solves
+// still count towards the averages behind OnPersonalBest.
+func (t *Timer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state = TimerIdle
+	t.inspectStart, t.start, t.stop = time.Time{}, time.Time{}, time.Time{}
+	t.penalty = PenaltyNone
+}
+
+// State returns the timer state.
+func (t *Timer) State() TimerState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// Elapsed returns the solve time so far: running time while solving, the
+// final time once stopped, and 0 before the first move.
+func (t *Timer) Elapsed() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch t.state {
+	case TimerRunning:
+		return t.now().Sub(t.start)
+	case TimerStopped:
+		return t.stop.Sub(t.start)
+	default:
+		return 0
+	}
+}
+
+// InspectionRemaining returns the inspection time left, negative once the
+// allowance is used up. It is 0 when not inspecting.
+func (t *Timer) InspectionRemaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != TimerInspecting {
+		return 0
+	}
+	return t.limit - t.now().Sub(t.inspectStart)
+}
+
+// Penalty returns the penalty so far: the one a start now would earn while
+// inspecting, and the solve's penalty afterwards.
+func (t *Timer) Penalty() Penalty {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state == TimerInspecting {
+		return t.inspectionPenalty(t.now().Sub(t.inspectStart))
+	}
+	return t.penalty
+}
+
+// Result returns the result of the last solve, and false if the timer has
+// not stopped.
+func (t *Timer) Result() (TimerResult, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != TimerStopped {
+		return TimerResult{}, false
+	}
+	return t.resultLocked(), true
+}
+
+// resultLocked builds the result. The caller must hold mu.
+func (t *Timer) resultLocked() TimerResult {
+	var inspection time.Duration
+	if !t.inspectStart.IsZero() {
+		inspection = t.start.Sub(t.inspectStart)
+	}
+	return TimerResult{
+		Time:       t.stop.Sub(t.start),
+		Inspection: inspection,
+		Penalty:    t.penalty,
+	}
+}